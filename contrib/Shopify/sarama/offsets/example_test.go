@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package offsets_test
+
+import (
+	"context"
+	"log"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/Shopify/sarama/offsets"
+)
+
+type handler struct{}
+
+func (handler) Setup(sarama.ConsumerGroupSession) error { return nil }
+
+func (handler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+func (handler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		session.MarkMessage(msg, "")
+		// the commit of the marked offset is traced
+		session.Commit()
+	}
+	return nil
+}
+
+func Example() {
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V0_11_0_0
+	cfg.Consumer.Offsets.AutoCommit.Enable = false
+	group, err := sarama.NewConsumerGroup([]string{"localhost:9092"}, "some-group", cfg)
+	if err != nil {
+		panic(err)
+	}
+	defer group.Close()
+
+	err = group.Consume(context.Background(), []string{"some-topic"}, offsets.WrapConsumerGroupHandler(handler{}))
+	if err != nil {
+		log.Printf("Consumer group error: %v\n", err)
+	}
+}
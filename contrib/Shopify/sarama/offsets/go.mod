@@ -0,0 +1,12 @@
+module github.com/codebrick-corp/dd-trace-go/contrib/Shopify/sarama/offsets
+
+go 1.16
+
+require (
+	github.com/Shopify/sarama v1.27.2
+	github.com/codebrick-corp/dd-trace-go v1.39.0
+	github.com/stretchr/testify v1.7.2
+)
+
+// The commits of the offsets require a more recent version of sarama than the main module.
+replace github.com/codebrick-corp/dd-trace-go => ../../../..
@@ -0,0 +1,125 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package offsets provides functions to trace the commits of the offsets marked by the
+// consumers of the Shopify/sarama package (https://github.com/Shopify/sarama), as
+// kafka.commit spans:
+//
+//	err := group.Consume(ctx, topics, offsets.WrapConsumerGroupHandler(handler))
+package offsets // import "github.com/codebrick-corp/dd-trace-go/contrib/Shopify/sarama/offsets"
+
+import (
+	"context"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/Shopify/sarama"
+)
+
+const componentName = "Shopify/sarama"
+
+type offsetManager struct {
+	sarama.OffsetManager
+	cfg *config
+}
+
+// Commit invokes OffsetManager.Commit and traces the commit of the marked offsets.
+func (om *offsetManager) Commit() {
+	span := startCommitSpan(context.Background(), om.cfg)
+	om.OffsetManager.Commit()
+	span.Finish()
+}
+
+// WrapOffsetManager wraps a sarama.OffsetManager so that the commits of the marked offsets
+// requested with OffsetManager.Commit are traced. The commits made automatically in the
+// background, when Consumer.Offsets.AutoCommit is enabled, are not traced.
+func WrapOffsetManager(om sarama.OffsetManager, opts ...Option) sarama.OffsetManager {
+	cfg := new(config)
+	defaults(cfg)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	log.Debug("contrib/Shopify/sarama/offsets: Wrapping Offset Manager: %#v", cfg)
+	return &offsetManager{
+		OffsetManager: om,
+		cfg:           cfg,
+	}
+}
+
+type consumerGroupSession struct {
+	sarama.ConsumerGroupSession
+	cfg *config
+}
+
+// Commit invokes ConsumerGroupSession.Commit and traces the commit of the marked offsets.
+func (s *consumerGroupSession) Commit() {
+	span := startCommitSpan(s.Context(), s.cfg,
+		tracer.Tag("kafka.member_id", s.MemberID()),
+		tracer.Tag("kafka.generation_id", s.GenerationID()),
+	)
+	s.ConsumerGroupSession.Commit()
+	span.Finish()
+}
+
+type consumerGroupHandler struct {
+	sarama.ConsumerGroupHandler
+	cfg *config
+}
+
+// Setup invokes ConsumerGroupHandler.Setup with the traced session.
+func (h *consumerGroupHandler) Setup(s sarama.ConsumerGroupSession) error {
+	return h.ConsumerGroupHandler.Setup(h.wrap(s))
+}
+
+// Cleanup invokes ConsumerGroupHandler.Cleanup with the traced session.
+func (h *consumerGroupHandler) Cleanup(s sarama.ConsumerGroupSession) error {
+	return h.ConsumerGroupHandler.Cleanup(h.wrap(s))
+}
+
+// ConsumeClaim invokes ConsumerGroupHandler.ConsumeClaim with the traced session.
+func (h *consumerGroupHandler) ConsumeClaim(s sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	return h.ConsumerGroupHandler.ConsumeClaim(h.wrap(s), claim)
+}
+
+func (h *consumerGroupHandler) wrap(s sarama.ConsumerGroupSession) sarama.ConsumerGroupSession {
+	return &consumerGroupSession{
+		ConsumerGroupSession: s,
+		cfg:                  h.cfg,
+	}
+}
+
+// WrapConsumerGroupHandler wraps a sarama.ConsumerGroupHandler so that the commits of the
+// marked offsets requested with ConsumerGroupSession.Commit by the handler are traced. The
+// commits made automatically by the consumer group are not traced.
+func WrapConsumerGroupHandler(h sarama.ConsumerGroupHandler, opts ...Option) sarama.ConsumerGroupHandler {
+	cfg := new(config)
+	defaults(cfg)
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	log.Debug("contrib/Shopify/sarama/offsets: Wrapping Consumer Group Handler: %#v", cfg)
+	return &consumerGroupHandler{
+		ConsumerGroupHandler: h,
+		cfg:                  cfg,
+	}
+}
+
+// startCommitSpan starts the span of a commit of the marked offsets, as a child of the span
+// found in ctx, if any.
+func startCommitSpan(ctx context.Context, cfg *config, extra ...tracer.StartSpanOption) ddtrace.Span {
+	opts := []tracer.StartSpanOption{
+		tracer.ServiceName(cfg.serviceName),
+		tracer.ResourceName("Commit Offsets"),
+		tracer.SpanType(ext.SpanTypeMessageConsumer),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.Tag(ext.Component, componentName),
+	}
+	opts = append(opts, extra...)
+	span, _ := tracer.StartSpanFromContext(ctx, "kafka.commit", opts...)
+	return span
+}
@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package offsets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func TestOffsetManager(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	broker := sarama.NewMockBroker(t, 0)
+	defer broker.Close()
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader("test-topic", 0, broker.BrokerID()),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, "test-group", broker),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset("test-group", "test-topic", 0, 5, "", sarama.ErrNoError),
+		"OffsetCommitRequest": sarama.NewMockOffsetCommitResponse(t),
+	})
+	cfg := sarama.NewConfig()
+	cfg.Version = sarama.V0_10_2_0
+	cfg.Consumer.Offsets.AutoCommit.Enable = false
+	client, err := sarama.NewClient([]string{broker.Addr()}, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	om, err := sarama.NewOffsetManagerFromClient("test-group", client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	om = WrapOffsetManager(om, WithServiceName("test-consumer"))
+	pom, err := om.ManagePartition("test-topic", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pom.MarkOffset(6, "")
+	om.Commit()
+	pom.AsyncClose()
+	if err := om.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var commits int
+	for _, rr := range broker.History() {
+		if _, ok := rr.Request.(*sarama.OffsetCommitRequest); ok {
+			commits++
+		}
+	}
+	assert.Equal(t, 1, commits)
+
+	spans := mt.FinishedSpans()
+	assert.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal(t, "kafka.commit", s.OperationName())
+	assert.Equal(t, "Commit Offsets", s.Tag(ext.ResourceName))
+	assert.Equal(t, "test-consumer", s.Tag(ext.ServiceName))
+	assert.Equal(t, ext.SpanTypeMessageConsumer, s.Tag(ext.SpanType))
+	assert.Equal(t, ext.SpanKindClient, s.Tag(ext.SpanKind))
+	assert.Equal(t, componentName, s.Tag(ext.Component))
+}
+
+// testSession is the session of a consumer group, handing its claims to a handler.
+type testSession struct {
+	sarama.ConsumerGroupSession
+	ctx     context.Context
+	commits int
+}
+
+func (s *testSession) Context() context.Context { return s.ctx }
+
+func (s *testSession) MemberID() string { return "member-1" }
+
+func (s *testSession) GenerationID() int32 { return 3 }
+
+func (s *testSession) Commit() { s.commits++ }
+
+// testHandler commits the offsets of its session in every step.
+type testHandler struct{}
+
+func (testHandler) Setup(s sarama.ConsumerGroupSession) error {
+	s.Commit()
+	return nil
+}
+
+func (testHandler) Cleanup(s sarama.ConsumerGroupSession) error {
+	s.Commit()
+	return nil
+}
+
+func (testHandler) ConsumeClaim(s sarama.ConsumerGroupSession, _ sarama.ConsumerGroupClaim) error {
+	s.Commit()
+	return nil
+}
+
+func TestConsumerGroupHandler(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	root, ctx := tracer.StartSpanFromContext(context.Background(), "consumer.run")
+	session := &testSession{ctx: ctx}
+	h := WrapConsumerGroupHandler(testHandler{})
+	assert.NoError(t, h.Setup(session))
+	assert.NoError(t, h.ConsumeClaim(session, nil))
+	assert.NoError(t, h.Cleanup(session))
+	root.Finish()
+	assert.Equal(t, 3, session.commits)
+
+	spans := mt.FinishedSpans()
+	assert.Len(t, spans, 4)
+	for _, s := range spans[:3] {
+		assert.Equal(t, "kafka.commit", s.OperationName())
+		assert.Equal(t, "Commit Offsets", s.Tag(ext.ResourceName))
+		assert.Equal(t, "kafka", s.Tag(ext.ServiceName))
+		assert.Equal(t, "member-1", s.Tag("kafka.member_id"))
+		assert.Equal(t, int32(3), s.Tag("kafka.generation_id"))
+		assert.Equal(t, root.Context().SpanID(), s.ParentID())
+	}
+}
@@ -0,0 +1,29 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package offsets
+
+import "github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+
+type config struct {
+	serviceName string
+}
+
+func defaults(cfg *config) {
+	cfg.serviceName = "kafka"
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+}
+
+// An Option is used to customize the config for the traced commits.
+type Option func(cfg *config)
+
+// WithServiceName sets the given service name for the commits.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
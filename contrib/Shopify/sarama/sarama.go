@@ -12,11 +12,18 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/Shopify/sarama"
 )
 
+const componentName = "Shopify/sarama"
+
+func init() {
+	integrations.Register(componentName, "github.com/Shopify/sarama")
+}
+
 type partitionConsumer struct {
 	sarama.PartitionConsumer
 	messages chan *sarama.ConsumerMessage
@@ -50,9 +57,17 @@ func WrapPartitionConsumer(pc sarama.PartitionConsumer, opts ...Option) sarama.P
 				tracer.ServiceName(cfg.consumerServiceName),
 				tracer.ResourceName("Consume Topic " + msg.Topic),
 				tracer.SpanType(ext.SpanTypeMessageConsumer),
+				tracer.Tag(ext.SpanKind, ext.SpanKindConsumer),
 				tracer.Tag("partition", msg.Partition),
 				tracer.Tag("offset", msg.Offset),
 				tracer.Measured(),
+				tracer.Tag(ext.Component, componentName),
+			}
+			if hwm := pc.HighWaterMarkOffset(); hwm > 0 {
+				opts = append(opts,
+					tracer.Tag("kafka.high_watermark", hwm),
+					tracer.Tag("kafka.lag", consumerLag(hwm, msg.Offset)),
+				)
 			}
 			if !math.IsNaN(cfg.analyticsRate) {
 				opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
@@ -83,6 +98,16 @@ func WrapPartitionConsumer(pc sarama.PartitionConsumer, opts ...Option) sarama.P
 	return wrapped
 }
 
+// consumerLag returns the number of messages remaining in the partition after the
+// message at offset, given the partition's high watermark hwm (the offset that will
+// be assigned to the next produced message).
+func consumerLag(hwm, offset int64) int64 {
+	if lag := hwm - offset - 1; lag > 0 {
+		return lag
+	}
+	return 0
+}
+
 type consumer struct {
 	sarama.Consumer
 	opts []Option
@@ -258,6 +283,8 @@ func startProducerSpan(cfg *config, version sarama.KafkaVersion, msg *sarama.Pro
 		tracer.ServiceName(cfg.producerServiceName),
 		tracer.ResourceName("Produce Topic " + msg.Topic),
 		tracer.SpanType(ext.SpanTypeMessageProducer),
+		tracer.Tag(ext.SpanKind, ext.SpanKindProducer),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
@@ -34,7 +34,8 @@ func TestConsumer(t *testing.T) {
 			SetOffset("test-topic", 0, sarama.OffsetNewest, 1),
 		"FetchRequest": sarama.NewMockFetchResponse(t, 1).
 			SetMessage("test-topic", 0, 0, sarama.StringEncoder("hello")).
-			SetMessage("test-topic", 0, 1, sarama.StringEncoder("world")),
+			SetMessage("test-topic", 0, 1, sarama.StringEncoder("world")).
+			SetHighWaterMark("test-topic", 0, 2),
 	})
 	cfg := sarama.NewConfig()
 	cfg.Version = sarama.MinVersion
@@ -73,6 +74,8 @@ func TestConsumer(t *testing.T) {
 
 		assert.Equal(t, int32(0), s.Tag("partition"))
 		assert.Equal(t, int64(0), s.Tag("offset"))
+		assert.Equal(t, int64(2), s.Tag("kafka.high_watermark"))
+		assert.Equal(t, int64(1), s.Tag("kafka.lag"))
 		assert.Equal(t, "kafka", s.Tag(ext.ServiceName))
 		assert.Equal(t, "Consume Topic test-topic", s.Tag(ext.ResourceName))
 		assert.Equal(t, "queue", s.Tag(ext.SpanType))
@@ -87,6 +90,8 @@ func TestConsumer(t *testing.T) {
 
 		assert.Equal(t, int32(0), s.Tag("partition"))
 		assert.Equal(t, int64(1), s.Tag("offset"))
+		assert.Equal(t, int64(2), s.Tag("kafka.high_watermark"))
+		assert.Equal(t, int64(0), s.Tag("kafka.lag"))
 		assert.Equal(t, "kafka", s.Tag(ext.ServiceName))
 		assert.Equal(t, "Consume Topic test-topic", s.Tag(ext.ResourceName))
 		assert.Equal(t, "queue", s.Tag(ext.SpanType))
@@ -0,0 +1,139 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package hystrix provides functions to trace the afex/hystrix-go package (https://github.com/afex/hystrix-go).
+//
+// Commands run through Do and DoC result in spans tagged with the state of their circuit.
+// Commands rejected without being run are tagged as short-circuited. Since hystrix-go only
+// evaluates the health of a circuit when running a command, state transitions are recorded
+// on the span of the first command observing them.
+package hystrix // import "github.com/codebrick-corp/dd-trace-go/contrib/afex/hystrix-go/hystrix"
+
+import (
+	"context"
+	"sync"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+
+	"github.com/afex/hystrix-go/hystrix"
+)
+
+const componentName = "afex/hystrix-go/hystrix"
+
+func init() {
+	integrations.Register(componentName, "github.com/afex/hystrix-go/hystrix")
+}
+
+const (
+	// tagName holds the name of the command's circuit.
+	tagName = "circuit_breaker.name"
+	// tagState holds the state of the circuit when the command was run.
+	tagState = "circuit_breaker.state"
+	// tagShortCircuited is set when the command was rejected by the circuit.
+	tagShortCircuited = "circuit_breaker.short_circuited"
+	// tagTransition holds the state transition observed by the command, e.g. "closed->open".
+	tagTransition = "circuit_breaker.transition"
+	// tagTripped is set when the command observed the circuit opening.
+	tagTripped = "circuit_breaker.tripped"
+	// tagFallback is set when the fallback of the command was called.
+	tagFallback = "hystrix.fallback"
+)
+
+// Do calls hystrix.Do and traces the command.
+func Do(name string, run func() error, fallback func(error) error, opts ...Option) error {
+	var fallbackC func(context.Context, error) error
+	if fallback != nil {
+		fallbackC = func(_ context.Context, err error) error {
+			return fallback(err)
+		}
+	}
+	return DoC(context.Background(), name, func(context.Context) error {
+		return run()
+	}, fallbackC, opts...)
+}
+
+// DoC calls hystrix.DoC and traces the command as a child of any span found in ctx.
+// The context passed to run and fallback holds the started span.
+func DoC(ctx context.Context, name string, run func(context.Context) error, fallback func(context.Context, error) error, opts ...Option) error {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	circuit, _, err := hystrix.GetCircuit(name)
+	if err != nil {
+		return hystrix.DoC(ctx, name, run, fallback)
+	}
+	wasOpen := circuit.IsOpen()
+	spanOpts := []ddtrace.StartSpanOption{
+		tracer.ResourceName(name),
+		tracer.Tag(tagName, name),
+		tracer.Tag(tagState, circuitState(wasOpen)),
+		tracer.Tag(ext.SpanKind, ext.SpanKindInternal),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if cfg.serviceName != "" {
+		spanOpts = append(spanOpts, tracer.ServiceName(cfg.serviceName))
+	}
+	span, ctx := tracer.StartSpanFromContext(ctx, "hystrix.command", spanOpts...)
+
+	// cause holds the error which triggered the fallback, if any. It is only read
+	// once hystrix.DoC has returned, which happens after the fallback was called.
+	var cause error
+	var fallbackC func(context.Context, error) error
+	if fallback != nil {
+		fallbackC = func(ctx context.Context, err error) error {
+			cause = err
+			span.SetTag(tagFallback, true)
+			return fallback(ctx, err)
+		}
+	}
+	err = hystrix.DoC(ctx, name, run, fallbackC)
+	if cause == nil {
+		cause = err
+	}
+	if cause == hystrix.ErrCircuitOpen || cause == hystrix.ErrMaxConcurrency {
+		span.SetTag(tagShortCircuited, true)
+	}
+	isOpen := circuit.IsOpen()
+	if prev, changed := circuits.observe(name, isOpen); changed {
+		span.SetTag(tagTransition, circuitState(prev)+"->"+circuitState(isOpen))
+		if isOpen {
+			span.SetTag(tagTripped, true)
+		}
+	}
+	span.Finish(tracer.WithError(err))
+	return err
+}
+
+// circuits holds the last observed state of every circuit.
+var circuits = observedStates{open: make(map[string]bool)}
+
+// observedStates keeps track of the states of circuits in order to detect transitions.
+type observedStates struct {
+	mu   sync.Mutex
+	open map[string]bool // circuit name -> open
+}
+
+// observe records the state of the circuit with the given name and returns its previously
+// observed state, along with whether it changed. Circuits are initially closed.
+func (o *observedStates) observe(name string, open bool) (prev bool, changed bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	prev = o.open[name]
+	o.open[name] = open
+	return prev, prev != open
+}
+
+// circuitState returns the name of the state of a circuit.
+func circuitState(open bool) string {
+	if open {
+		return "open"
+	}
+	return "closed"
+}
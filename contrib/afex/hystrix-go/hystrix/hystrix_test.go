@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package hystrix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/afex/hystrix-go/hystrix"
+)
+
+func TestDo(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	err := Do("do", func() error { return nil }, nil, WithServiceName("commands"))
+	assert.NoError(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal(t, "hystrix.command", s.OperationName())
+	assert.Equal(t, "do", s.Tag(ext.ResourceName))
+	assert.Equal(t, "do", s.Tag(tagName))
+	assert.Equal(t, "commands", s.Tag(ext.ServiceName))
+	assert.Equal(t, "closed", s.Tag(tagState))
+	assert.Nil(t, s.Tag(ext.Error))
+	assert.Nil(t, s.Tag(tagFallback))
+}
+
+func TestDoCFallback(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "parent")
+	errFailed := errors.New("failed")
+	err := DoC(ctx, "fallback", func(ctx context.Context) error {
+		child, _ := tracer.StartSpanFromContext(ctx, "child")
+		child.Finish()
+		return errFailed
+	}, func(_ context.Context, err error) error {
+		return nil
+	})
+	assert.NoError(t, err)
+	parent.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 3)
+	child, command := spans[0], spans[1]
+	assert.Equal(t, command.SpanID(), child.ParentID())
+	assert.Equal(t, parent.Context().SpanID(), command.ParentID())
+	assert.Equal(t, true, command.Tag(tagFallback))
+	assert.Nil(t, command.Tag(tagShortCircuited))
+	assert.Nil(t, command.Tag(ext.Error))
+}
+
+func TestCircuitOpen(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	// circuits are global, use a new one on every run
+	name := fmt.Sprintf("trip-%d", time.Now().UnixNano())
+	hystrix.ConfigureCommand(name, hystrix.CommandConfig{
+		RequestVolumeThreshold: 2,
+		ErrorPercentThreshold:  1,
+		SleepWindow:            int(time.Hour / time.Millisecond),
+	})
+	errFailed := errors.New("failed")
+	fallback := func(error) error { return nil }
+	// metrics are collected asynchronously, so the circuit opens after an undefined
+	// number of failed commands
+	var spans []mocktracer.Span
+	for i := 0; i < 100; i++ {
+		Do(name, func() error { return errFailed }, fallback)
+		spans = mt.FinishedSpans()
+		if spans[len(spans)-1].Tag(tagTripped) == true {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	// the first command observing the open circuit is short-circuited
+	s := spans[len(spans)-1]
+	require.Equal(t, true, s.Tag(tagTripped), "circuit should have opened")
+	assert.Equal(t, "closed->open", s.Tag(tagTransition))
+	assert.Equal(t, "open", s.Tag(tagState))
+	assert.Equal(t, true, s.Tag(tagShortCircuited))
+	assert.Equal(t, true, s.Tag(tagFallback))
+	for _, s := range spans[:len(spans)-1] {
+		assert.Equal(t, "closed", s.Tag(tagState))
+		assert.Nil(t, s.Tag(tagShortCircuited))
+	}
+
+	mt.Reset()
+	err := Do(name, func() error { return nil }, fallback)
+	assert.NoError(t, err)
+	spans = mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "open", spans[0].Tag(tagState))
+	assert.Equal(t, true, spans[0].Tag(tagShortCircuited))
+	assert.Nil(t, spans[0].Tag(tagTransition))
+}
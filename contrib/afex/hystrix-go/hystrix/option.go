@@ -0,0 +1,25 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package hystrix
+
+type config struct {
+	serviceName string
+}
+
+// Option represents an option that can be passed to Do, DoC, Go and GoC.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	// by default, spans inherit the service name of their parent
+	cfg.serviceName = ""
+}
+
+// WithServiceName sets the given service name for the spans of the commands.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
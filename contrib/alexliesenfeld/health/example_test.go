@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package health_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	healthtrace "github.com/codebrick-corp/dd-trace-go/contrib/alexliesenfeld/health"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/alexliesenfeld/health"
+)
+
+func Example() {
+	// Start the tracer
+	tracer.Start()
+	defer tracer.Stop()
+
+	checker := health.NewChecker(
+		health.WithCheck(health.Check{
+			Name:  "database",
+			Check: func(ctx context.Context) error { return nil },
+		}),
+		health.WithPeriodicCheck(15*time.Second, 0, health.Check{
+			Name:  "search",
+			Check: func(ctx context.Context) error { return nil },
+		}),
+		// Trace each check.
+		health.WithInterceptors(healthtrace.Interceptor()),
+	)
+	// Trace the requests of the health endpoint.
+	http.Handle("/health", health.NewHandler(checker, health.WithMiddleware(healthtrace.Middleware())))
+	http.ListenAndServe(":8080", nil)
+}
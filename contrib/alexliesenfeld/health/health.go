@@ -0,0 +1,119 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package health provides functions to trace the health checks of the alexliesenfeld/health
+// package (https://github.com/alexliesenfeld/health).
+//
+// Interceptor traces each check of a dependency, and Middleware traces the requests of the
+// health endpoint along with their aggregate result, the checks run by the requests being
+// children of their span:
+//
+//	checker := health.NewChecker(
+//		health.WithCheck(health.Check{Name: "database", Check: db.PingContext}),
+//		health.WithInterceptors(healthtrace.Interceptor()),
+//	)
+//	http.Handle("/health", health.NewHandler(checker, health.WithMiddleware(healthtrace.Middleware())))
+//
+// The status of each check, along with its number of contiguous failures and whether it changed,
+// make flapping dependencies visible. The periodic checks are traced as root spans.
+package health // import "github.com/codebrick-corp/dd-trace-go/contrib/alexliesenfeld/health"
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/alexliesenfeld/health"
+)
+
+const componentName = "alexliesenfeld/health"
+
+func init() {
+	integrations.Register(componentName, "github.com/alexliesenfeld/health")
+}
+
+const (
+	// tagStatus holds the aggregate status of the checks: "up", "down" or "unknown".
+	tagStatus = "health.status"
+	// tagCheckName holds the name of the check.
+	tagCheckName = "health.check.name"
+	// tagCheckStatus holds the status of the check: "up", "down" or "unknown".
+	tagCheckStatus = "health.check.status"
+	// tagCheckStatusChanged is set when the status of the check changed.
+	tagCheckStatusChanged = "health.check.status_changed"
+	// tagCheckContiguousFails holds the number of contiguous failures of the check.
+	tagCheckContiguousFails = "health.check.contiguous_fails"
+)
+
+// Interceptor returns a checker interceptor tracing each check of a dependency, to be given to
+// the WithInterceptors option of a checker. The spans are named after the checks, and are errors
+// when their checks fail.
+func Interceptor(opts ...Option) health.Interceptor {
+	cfg := newConfig(opts...)
+	log.Debug("contrib/alexliesenfeld/health: Configuring Interceptor: %#v", cfg)
+	return func(next health.InterceptorFunc) health.InterceptorFunc {
+		return func(ctx context.Context, name string, state health.CheckState) health.CheckState {
+			opts := append([]ddtrace.StartSpanOption{
+				tracer.ServiceName(cfg.serviceName),
+				tracer.ResourceName(name),
+				tracer.Tag(tagCheckName, name),
+				tracer.Tag(ext.Component, componentName),
+			}, cfg.spanOpts...)
+			span, ctx := tracer.StartSpanFromContext(ctx, "health.check", opts...)
+			res := next(ctx, name, state)
+			span.SetTag(tagCheckStatus, string(res.Status))
+			span.SetTag(tagCheckContiguousFails, res.ContiguousFails)
+			if res.Status != state.Status && state.Status != "" {
+				span.SetTag(tagCheckStatusChanged, true)
+			}
+			span.Finish(tracer.WithError(res.Result))
+			return res
+		}
+	}
+}
+
+// Middleware returns a handler middleware tracing the requests of the health endpoint, to be given
+// to the WithMiddleware option of the handler. The spans hold the aggregate status of the checks,
+// and are errors when it is down.
+func Middleware(opts ...Option) health.Middleware {
+	cfg := newConfig(opts...)
+	log.Debug("contrib/alexliesenfeld/health: Configuring Middleware: %#v", cfg)
+	return func(next health.MiddlewareFunc) health.MiddlewareFunc {
+		return func(r *http.Request) health.CheckerResult {
+			opts := []ddtrace.StartSpanOption{
+				tracer.ServiceName(cfg.serviceName),
+				tracer.ResourceName(r.Method + " " + r.URL.Path),
+				tracer.SpanType(ext.SpanTypeWeb),
+				tracer.Tag(ext.HTTPMethod, r.Method),
+				tracer.Tag(ext.HTTPURL, httptrace.QuantizePath(r.URL.Path)),
+				tracer.Tag(ext.Component, componentName),
+			}
+			span, ctx := tracer.StartSpanFromContext(r.Context(), "health.request", append(opts, cfg.spanOpts...)...)
+			res := next(r.WithContext(ctx))
+			span.SetTag(tagStatus, string(res.Status))
+			var err error
+			if res.Status == health.StatusDown {
+				var failed []string
+				for name, check := range res.Details {
+					if check.Status == health.StatusDown {
+						failed = append(failed, name)
+					}
+				}
+				sort.Strings(failed)
+				err = fmt.Errorf("checks failed: %v", failed)
+			}
+			span.Finish(tracer.WithError(err))
+			return res
+		}
+	}
+}
@@ -0,0 +1,96 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/alexliesenfeld/health"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterceptor(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	fail := false
+	checker := health.NewChecker(
+		health.WithDisabledCache(),
+		health.WithDisabledAutostart(),
+		health.WithCheck(health.Check{
+			Name: "database",
+			Check: func(ctx context.Context) error {
+				_, ok := tracer.SpanFromContext(ctx)
+				assert.True(ok)
+				if fail {
+					return errors.New("unreachable")
+				}
+				return nil
+			},
+		}),
+		health.WithInterceptors(Interceptor(WithServiceName("my-service"))),
+	)
+	checker.Check(context.Background())
+	fail = true
+	checker.Check(context.Background())
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 2)
+	up, down := spans[0], spans[1]
+	assert.Equal("health.check", up.OperationName())
+	assert.Equal("database", up.Tag(ext.ResourceName))
+	assert.Equal("my-service", up.Tag(ext.ServiceName))
+	assert.Equal("up", up.Tag(tagCheckStatus))
+	assert.Nil(up.Tag(ext.Error))
+	assert.Equal("down", down.Tag(tagCheckStatus))
+	assert.Equal(true, down.Tag(tagCheckStatusChanged))
+	assert.Equal(uint(1), down.Tag(tagCheckContiguousFails))
+	assert.NotNil(down.Tag(ext.Error))
+}
+
+func TestMiddleware(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	checker := health.NewChecker(
+		health.WithDisabledCache(),
+		health.WithDisabledAutostart(),
+		health.WithCheck(health.Check{
+			Name:  "cache",
+			Check: func(ctx context.Context) error { return nil },
+		}),
+		health.WithCheck(health.Check{
+			Name:  "database",
+			Check: func(ctx context.Context) error { return errors.New("unreachable") },
+		}),
+		health.WithInterceptors(Interceptor()),
+	)
+	h := health.NewHandler(checker, health.WithMiddleware(Middleware()))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/health", nil))
+	assert.Equal(http.StatusServiceUnavailable, w.Code)
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 3)
+	req := spans[2]
+	assert.Equal("health.request", req.OperationName())
+	assert.Equal("GET /health", req.Tag(ext.ResourceName))
+	assert.Equal("down", req.Tag(tagStatus))
+	assert.Equal("checks failed: [database]", req.Tag(ext.Error).(error).Error())
+	for _, check := range spans[:2] {
+		assert.Equal(req.SpanID(), check.ParentID())
+	}
+}
@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package health
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+const defaultServiceName = "health"
+
+type config struct {
+	serviceName string
+	spanOpts    []ddtrace.StartSpanOption
+}
+
+// Option represents an option that can be passed to Interceptor and Middleware.
+type Option func(*config)
+
+func newConfig(opts ...Option) *config {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	return cfg
+}
+
+func defaults(cfg *config) {
+	cfg.serviceName = defaultServiceName
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+}
+
+// WithServiceName sets the given service name for the health checks.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithSpanOptions applies the given set of options to the started spans.
+func WithSpanOptions(opts ...ddtrace.StartSpanOption) Option {
+	return func(cfg *config) {
+		cfg.spanOpts = opts
+	}
+}
@@ -0,0 +1,27 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package http traces the requests sent with http.DefaultClient, which includes the ones
+// sent by http.Get, http.Head and http.Post, when imported:
+//
+//	import _ "github.com/codebrick-corp/dd-trace-go/contrib/autoload/http"
+//
+// The transport of http.DefaultClient is only replaced when it wasn't set by the
+// application. http.DefaultTransport is left untouched so that it can still be converted
+// to a *http.Transport. Clients other than http.DefaultClient can be traced with
+// the WrapClient function of the contrib/net/http package.
+package http // import "github.com/codebrick-corp/dd-trace-go/contrib/autoload/http"
+
+import (
+	"net/http"
+
+	httptrace "github.com/codebrick-corp/dd-trace-go/contrib/net/http"
+)
+
+func init() {
+	if http.DefaultClient.Transport == nil {
+		http.DefaultClient.Transport = httptrace.WrapRoundTripper(http.DefaultTransport)
+	}
+}
@@ -0,0 +1,23 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package sql traces the drivers registered with database/sql without having to register
+// them with Register of the contrib/database/sql package, when imported:
+//
+//	import _ "github.com/codebrick-corp/dd-trace-go/contrib/autoload/sql"
+//
+// database/sql provides no way of wrapping the handles returned by sql.Open, so the
+// handles still need to be opened with the Open and OpenDB functions of the
+// contrib/database/sql package, which register the drivers with the default options
+// on their first use. Drivers registered with Register keep their own options.
+package sql // import "github.com/codebrick-corp/dd-trace-go/contrib/autoload/sql"
+
+import (
+	sqltrace "github.com/codebrick-corp/dd-trace-go/contrib/database/sql"
+)
+
+func init() {
+	sqltrace.AutoRegister()
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package sql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	sqltrace "github.com/codebrick-corp/dd-trace-go/contrib/database/sql"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func init() {
+	sql.Register("autoload-fake", fakeDriver{})
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+
+func (fakeConn) Close() error { return nil }
+
+func (fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+
+func (fakeConnector) Driver() driver.Driver { return fakeConnDriver{} }
+
+// fakeConnDriver is only known through fakeConnector.
+type fakeConnDriver struct{ fakeDriver }
+
+func TestOpen(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	db, err := sqltrace.Open("autoload-fake", "dsn")
+	assert.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("DELETE FROM t")
+	assert.NoError(t, err)
+
+	var exec mocktracer.Span
+	for _, s := range mt.FinishedSpans() {
+		if s.Tag(ext.ResourceName) == "DELETE FROM t" {
+			exec = s
+		}
+	}
+	if assert.NotNil(t, exec) {
+		assert.Equal(t, "autoload-fake.query", exec.OperationName())
+		assert.Equal(t, "Exec", exec.Tag("sql.query_type"))
+	}
+
+	_, err = sqltrace.Open("autoload-unknown", "dsn")
+	assert.Error(t, err)
+}
+
+func TestOpenDB(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	db := sqltrace.OpenDB(fakeConnector{})
+	defer db.Close()
+	_, err := db.Exec("DELETE FROM t")
+	assert.NoError(t, err)
+
+	var exec mocktracer.Span
+	for _, s := range mt.FinishedSpans() {
+		if s.Tag(ext.ResourceName) == "DELETE FROM t" {
+			exec = s
+		}
+	}
+	if assert.NotNil(t, exec) {
+		assert.Equal(t, "sql.query", exec.OperationName())
+	}
+}
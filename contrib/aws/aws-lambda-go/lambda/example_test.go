@@ -0,0 +1,32 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package lambda_test
+
+import (
+	"context"
+
+	lambdatrace "github.com/codebrick-corp/dd-trace-go/contrib/aws/aws-lambda-go/lambda"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/aws/aws-lambda-go/events"
+	"github.com/aws/aws-lambda-go/lambda"
+)
+
+func handle(ctx context.Context, ev events.SQSEvent) error {
+	for _, msg := range ev.Records {
+		span, _ := tracer.StartSpanFromContext(ctx, "process.message", tracer.ResourceName(msg.MessageId))
+		// process the message...
+		span.Finish()
+	}
+	return nil
+}
+
+func Example() {
+	tracer.Start()
+	defer tracer.Stop()
+
+	lambda.StartHandler(lambdatrace.WrapHandler(lambda.NewHandler(handle)))
+}
@@ -0,0 +1,97 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package lambda provides functions to trace the invocations of AWS Lambda functions
+// written with aws/aws-lambda-go (https://github.com/aws/aws-lambda-go).
+//
+// The invocations triggered by SQS queues, SNS topics and EventBridge buses continue the
+// trace of the code which sent the event, when it holds a span context: in the "_datadog"
+// attribute of SQS and SNS messages, including SNS messages delivered through SQS, or in
+// the "_datadog" field of the detail of EventBridge events. The span context is a JSON
+// object holding the propagation headers. The span of an invocation processing a single
+// record is a child of the span context of its record; the span of an invocation
+// processing a batch of records is the root of a new trace, linked to the span context of
+// each of its records.
+package lambda // import "github.com/codebrick-corp/dd-trace-go/contrib/aws/aws-lambda-go/lambda"
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+const componentName = "aws/aws-lambda-go/lambda"
+
+func init() {
+	integrations.Register(componentName, "github.com/aws/aws-lambda-go")
+}
+
+const (
+	tagFunctionName   = "function_name"
+	tagFunctionARN    = "function_arn"
+	tagRequestID      = "request_id"
+	tagColdStart      = "cold_start"
+	tagEventSource    = "function_trigger.event_source"
+	tagEventSourceARN = "function_trigger.event_source_arn"
+	tagBatchSize      = "function_trigger.batch_size"
+)
+
+// WrapHandler wraps h so that its invocations are traced, continuing the traces of the
+// events triggering them. The tracer is flushed after every invocation, as the execution
+// environment of the function may be frozen once it returns:
+//
+//	lambda.StartHandler(lambdatrace.WrapHandler(lambda.NewHandler(handle)))
+func WrapHandler(h lambda.Handler, opts ...Option) lambda.Handler {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/aws/aws-lambda-go/lambda: Wrapping Handler: %#v", cfg)
+	return &handler{Handler: h, cfg: cfg}
+}
+
+type handler struct {
+	lambda.Handler
+	cfg *config
+
+	invoked int32 // set to 1 after the first invocation
+}
+
+// Invoke invokes and traces the wrapped handler.
+func (h *handler) Invoke(ctx context.Context, payload []byte) ([]byte, error) {
+	opts := []ddtrace.StartSpanOption{
+		tracer.SpanType(ext.SpanTypeServerless),
+		tracer.Tag(ext.SpanKind, ext.SpanKindServer),
+		tracer.ServiceName(h.cfg.serviceName),
+		tracer.ResourceName(h.cfg.functionName),
+		tracer.Tag(tagFunctionName, h.cfg.functionName),
+		tracer.Tag(tagColdStart, atomic.CompareAndSwapInt32(&h.invoked, 0, 1)),
+		tracer.Measured(),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if lc, ok := lambdacontext.FromContext(ctx); ok {
+		opts = append(opts,
+			tracer.Tag(tagRequestID, lc.AwsRequestID),
+			tracer.Tag(tagFunctionARN, lc.InvokedFunctionArn),
+		)
+	}
+	if t, ok := parseTrigger(payload); ok {
+		opts = append(opts, t.startSpanOptions()...)
+	}
+	span, ctx := tracer.StartSpanFromContext(ctx, operationName, opts...)
+	out, err := h.Handler.Invoke(ctx, payload)
+	span.Finish(tracer.WithError(err))
+	tracer.Flush()
+	return out, err
+}
@@ -0,0 +1,222 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package lambda
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-lambda-go/lambda"
+	"github.com/aws/aws-lambda-go/lambdacontext"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+// spanContext returns the "_datadog" JSON object holding the context of a new span,
+// along with that span.
+func spanContext(t *testing.T) (string, tracer.Span) {
+	span := tracer.StartSpan("sqs.send")
+	defer span.Finish()
+	carrier := tracer.TextMapCarrier{}
+	require.NoError(t, tracer.Inject(span.Context(), carrier))
+	b, err := json.Marshal(carrier)
+	require.NoError(t, err)
+	return string(b), span
+}
+
+func sqsRecord(attrs string) string {
+	return fmt.Sprintf(`{"messageId":"1","body":"hello","eventSource":"aws:sqs",`+
+		`"eventSourceARN":"arn:aws:sqs:us-east-1:123456789012:queue","messageAttributes":{%s}}`, attrs)
+}
+
+func sqsAttr(value string) string {
+	v, _ := json.Marshal(value)
+	return fmt.Sprintf(`"_datadog":{"stringValue":%s,"dataType":"String"}`, v)
+}
+
+func snsMessageJSON(attr string) string {
+	return fmt.Sprintf(`{"Type":"Notification","TopicArn":"arn:aws:sns:us-east-1:123456789012:topic",`+
+		`"Message":"hello","MessageAttributes":{%s}}`, attr)
+}
+
+func snsAttr(value string) string {
+	v, _ := json.Marshal(base64.StdEncoding.EncodeToString([]byte(value)))
+	return fmt.Sprintf(`"_datadog":{"Type":"Binary","Value":%s}`, v)
+}
+
+func invoke(t *testing.T, payload string, opts ...Option) mocktracer.Span {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	return invokeWith(t, mt, payload, opts...)
+}
+
+func invokeWith(t *testing.T, mt mocktracer.Tracer, payload string, opts ...Option) mocktracer.Span {
+	h := WrapHandler(lambda.NewHandler(func(ctx context.Context) (string, error) {
+		_, ok := tracer.SpanFromContext(ctx)
+		assert.True(t, ok)
+		return "ok", nil
+	}), opts...)
+	ctx := lambdacontext.NewContext(context.Background(), &lambdacontext.LambdaContext{
+		AwsRequestID:       "req-1",
+		InvokedFunctionArn: "arn:aws:lambda:us-east-1:123456789012:function:fn",
+	})
+	out, err := h.Invoke(ctx, []byte(payload))
+	require.NoError(t, err)
+	assert.Equal(t, `"ok"`, string(out))
+
+	var invocation mocktracer.Span
+	for _, s := range mt.FinishedSpans() {
+		if s.OperationName() == "aws.lambda" {
+			invocation = s
+		}
+	}
+	require.NotNil(t, invocation)
+	return invocation
+}
+
+func TestInvoke(t *testing.T) {
+	assert := assert.New(t)
+	s := invoke(t, `{"name":"direct"}`)
+	assert.Equal(ext.SpanTypeServerless, s.Tag(ext.SpanType))
+	assert.Equal(ext.SpanKindServer, s.Tag(ext.SpanKind))
+	assert.Equal("req-1", s.Tag(tagRequestID))
+	assert.Equal("arn:aws:lambda:us-east-1:123456789012:function:fn", s.Tag(tagFunctionARN))
+	assert.Equal(true, s.Tag(tagColdStart))
+	assert.Nil(s.Tag(tagEventSource))
+	assert.Zero(s.ParentID())
+}
+
+func TestColdStart(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	h := WrapHandler(lambda.NewHandler(func() error { return nil }))
+	for i := 0; i < 2; i++ {
+		_, err := h.Invoke(context.Background(), []byte(`{}`))
+		require.NoError(t, err)
+	}
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, true, spans[0].Tag(tagColdStart))
+	assert.Equal(t, false, spans[1].Tag(tagColdStart))
+}
+
+func TestError(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	fail := errors.New("oops")
+	h := WrapHandler(lambda.NewHandler(func() error { return fail }))
+	_, err := h.Invoke(context.Background(), []byte(`{}`))
+	require.Error(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.NotNil(t, spans[0].Tag(ext.Error))
+}
+
+func TestSQS(t *testing.T) {
+	t.Run("single", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		ctx, parent := spanContext(t)
+		s := invokeWith(t, mt, `{"Records":[`+sqsRecord(sqsAttr(ctx))+`]}`)
+		assert.Equal(t, "sqs", s.Tag(tagEventSource))
+		assert.Equal(t, "arn:aws:sqs:us-east-1:123456789012:queue", s.Tag(tagEventSourceARN))
+		assert.Equal(t, parent.Context().TraceID(), s.TraceID())
+		assert.Equal(t, parent.Context().SpanID(), s.ParentID())
+		assert.Empty(t, s.Links())
+	})
+
+	t.Run("sns", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		ctx, parent := spanContext(t)
+		body, _ := json.Marshal(snsMessageJSON(snsAttr(ctx)))
+		payload := fmt.Sprintf(`{"Records":[{"eventSource":"aws:sqs","eventSourceARN":"arn:aws:sqs:us-east-1:123456789012:queue","body":%s}]}`, body)
+		s := invokeWith(t, mt, payload)
+		assert.Equal(t, "sqs", s.Tag(tagEventSource))
+		assert.Equal(t, parent.Context().SpanID(), s.ParentID())
+	})
+
+	t.Run("batch", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		ctx1, parent1 := spanContext(t)
+		ctx2, parent2 := spanContext(t)
+		payload := `{"Records":[` + sqsRecord(sqsAttr(ctx1)) + `,` + sqsRecord(sqsAttr(ctx2)) + `,` + sqsRecord("") + `]}`
+		s := invokeWith(t, mt, payload)
+		assert.Zero(t, s.ParentID())
+		assert.Equal(t, 3, s.Tag(tagBatchSize))
+		links := s.Links()
+		require.Len(t, links, 2)
+		assert.Equal(t, parent1.Context().SpanID(), links[0].SpanID)
+		assert.Equal(t, parent2.Context().SpanID(), links[1].SpanID)
+	})
+
+	t.Run("no-context", func(t *testing.T) {
+		s := invoke(t, `{"Records":[`+sqsRecord("")+`]}`)
+		assert.Equal(t, "sqs", s.Tag(tagEventSource))
+		assert.Zero(t, s.ParentID())
+		assert.Empty(t, s.Links())
+	})
+}
+
+func TestSNS(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	ctx, parent := spanContext(t)
+	payload := fmt.Sprintf(`{"Records":[{"EventSource":"aws:sns","Sns":%s}]}`, snsMessageJSON(snsAttr(ctx)))
+	s := invokeWith(t, mt, payload)
+	assert.Equal(t, "sns", s.Tag(tagEventSource))
+	assert.Equal(t, "arn:aws:sns:us-east-1:123456789012:topic", s.Tag(tagEventSourceARN))
+	assert.Equal(t, parent.Context().SpanID(), s.ParentID())
+}
+
+func TestEventBridge(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	ctx, parent := spanContext(t)
+	payload := fmt.Sprintf(`{"detail-type":"OrderPlaced","source":"com.example.orders","detail":{"id":1,"_datadog":%s}}`, ctx)
+	s := invokeWith(t, mt, payload)
+	assert.Equal(t, "eventbridge", s.Tag(tagEventSource))
+	assert.Equal(t, "com.example.orders", s.Tag(tagEventSourceARN))
+	assert.Equal(t, parent.Context().SpanID(), s.ParentID())
+}
+
+func TestServiceName(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		s := invoke(t, `{}`)
+		assert.Equal(t, "aws.lambda", s.Tag(ext.ServiceName))
+	})
+
+	t.Run("global", func(t *testing.T) {
+		globalconfig.SetServiceName("global-service")
+		defer globalconfig.SetServiceName("")
+
+		s := invoke(t, `{}`)
+		assert.Equal(t, "global-service", s.Tag(ext.ServiceName))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		s := invoke(t, `{}`, WithServiceName("my-function"))
+		assert.Equal(t, "my-function", s.Tag(ext.ServiceName))
+	})
+}
@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package lambda
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+
+	"github.com/aws/aws-lambda-go/lambdacontext"
+)
+
+const (
+	defaultServiceName = "aws.lambda"
+	operationName      = "aws.lambda"
+)
+
+type config struct {
+	serviceName  string
+	functionName string
+}
+
+// Option represents an option that can be passed to WrapHandler.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	cfg.functionName = lambdacontext.FunctionName
+	cfg.serviceName = defaultServiceName
+	if cfg.functionName != "" {
+		cfg.serviceName = cfg.functionName
+	}
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+}
+
+// WithServiceName sets the given service name for the function. It defaults to the
+// name of the function.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
@@ -0,0 +1,176 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package lambda
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+// contextAttribute is the name of the message attribute, or of the field of the event
+// detail, holding the span context of a record as a JSON object.
+const contextAttribute = "_datadog"
+
+// trigger describes the event source of an invocation.
+type trigger struct {
+	source    string                // "sqs", "sns" or "eventbridge"
+	sourceARN string                // the ARN of the queue, topic or the source of the event
+	records   int                   // the number of records of the event
+	contexts  []ddtrace.SpanContext // the span contexts found in the records
+}
+
+// event holds the fields of the SQS, SNS and EventBridge events which identify them and
+// hold span contexts. The names of the fields are matched case-insensitively, which
+// covers both "eventSource" (SQS) and "EventSource" (SNS).
+type event struct {
+	Records []struct {
+		EventSource       string                  `json:"eventSource"`
+		EventSourceARN    string                  `json:"eventSourceARN"`
+		MessageAttributes map[string]sqsAttribute `json:"messageAttributes"`
+		Body              string                  `json:"body"`
+		SNS               *snsMessage             `json:"Sns"`
+	} `json:"Records"`
+
+	DetailType string                     `json:"detail-type"`
+	Source     string                     `json:"source"`
+	Detail     map[string]json.RawMessage `json:"detail"`
+}
+
+type sqsAttribute struct {
+	StringValue *string `json:"stringValue"`
+	BinaryValue []byte  `json:"binaryValue"`
+}
+
+// snsMessage is an SNS message, as held by the records of SNS events, or as the body of
+// SQS messages for SNS topics delivering to SQS queues without raw message delivery.
+type snsMessage struct {
+	Type              string                  `json:"Type"`
+	TopicArn          string                  `json:"TopicArn"`
+	MessageAttributes map[string]snsAttribute `json:"MessageAttributes"`
+}
+
+type snsAttribute struct {
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
+}
+
+// parseTrigger returns the trigger of the invocation receiving payload, reporting false if
+// the payload isn't an SQS, SNS or EventBridge event.
+func parseTrigger(payload []byte) (trigger, bool) {
+	var ev event
+	if err := json.Unmarshal(payload, &ev); err != nil {
+		return trigger{}, false
+	}
+	var t trigger
+	switch {
+	case len(ev.Records) > 0 && ev.Records[0].EventSource == "aws:sqs":
+		t.source, t.sourceARN = "sqs", ev.Records[0].EventSourceARN
+		for _, r := range ev.Records {
+			carrier, ok := sqsCarrier(r.MessageAttributes)
+			if !ok && strings.HasPrefix(r.Body, "{") {
+				var msg snsMessage
+				if json.Unmarshal([]byte(r.Body), &msg) == nil && msg.Type == "Notification" {
+					carrier, ok = snsCarrier(msg.MessageAttributes)
+				}
+			}
+			t.add(carrier, ok)
+		}
+	case len(ev.Records) > 0 && ev.Records[0].EventSource == "aws:sns" && ev.Records[0].SNS != nil:
+		t.source, t.sourceARN = "sns", ev.Records[0].SNS.TopicArn
+		for _, r := range ev.Records {
+			if r.SNS == nil {
+				t.add(nil, false)
+				continue
+			}
+			t.add(snsCarrier(r.SNS.MessageAttributes))
+		}
+	case ev.DetailType != "" && ev.Source != "":
+		t.source, t.sourceARN = "eventbridge", ev.Source
+		var carrier tracer.TextMapCarrier
+		raw, ok := ev.Detail[contextAttribute]
+		t.add(carrier, ok && json.Unmarshal(raw, &carrier) == nil)
+	default:
+		return trigger{}, false
+	}
+	return t, true
+}
+
+// add records a record of the event, and the span context of carrier if ok.
+func (t *trigger) add(carrier tracer.TextMapCarrier, ok bool) {
+	t.records++
+	if !ok {
+		return
+	}
+	if sctx, err := tracer.Extract(carrier); err == nil {
+		t.contexts = append(t.contexts, sctx)
+	}
+}
+
+// sqsCarrier returns the carrier held by the attributes of an SQS message.
+func sqsCarrier(attrs map[string]sqsAttribute) (tracer.TextMapCarrier, bool) {
+	attr, ok := attrs[contextAttribute]
+	if !ok {
+		return nil, false
+	}
+	data := attr.BinaryValue
+	if attr.StringValue != nil {
+		data = []byte(*attr.StringValue)
+	}
+	var carrier tracer.TextMapCarrier
+	if err := json.Unmarshal(data, &carrier); err != nil {
+		return nil, false
+	}
+	return carrier, true
+}
+
+// snsCarrier returns the carrier held by the attributes of an SNS message, whose
+// binary values are base64-encoded.
+func snsCarrier(attrs map[string]snsAttribute) (tracer.TextMapCarrier, bool) {
+	attr, ok := attrs[contextAttribute]
+	if !ok {
+		return nil, false
+	}
+	data := []byte(attr.Value)
+	if attr.Type == "Binary" {
+		var err error
+		if data, err = base64.StdEncoding.DecodeString(attr.Value); err != nil {
+			return nil, false
+		}
+	}
+	var carrier tracer.TextMapCarrier
+	if err := json.Unmarshal(data, &carrier); err != nil {
+		return nil, false
+	}
+	return carrier, true
+}
+
+// startSpanOptions returns the options of the span of the invocation triggered by t: it
+// is the child of the span context of its record if it has a single one, or is linked to
+// the span contexts of its records otherwise.
+func (t trigger) startSpanOptions() []ddtrace.StartSpanOption {
+	opts := []ddtrace.StartSpanOption{
+		tracer.Tag(tagEventSource, t.source),
+		tracer.Tag(tagEventSourceARN, t.sourceARN),
+	}
+	if t.records > 1 {
+		opts = append(opts, tracer.Tag(tagBatchSize, t.records))
+	}
+	switch {
+	case t.records == 1 && len(t.contexts) == 1:
+		opts = append(opts, tracer.ChildOf(t.contexts[0]))
+	case len(t.contexts) > 0:
+		links := make([]ddtrace.SpanLink, len(t.contexts))
+		for i, sctx := range t.contexts {
+			links[i] = ddtrace.SpanLink{TraceID: sctx.TraceID(), SpanID: sctx.SpanID()}
+		}
+		opts = append(opts, tracer.WithSpanLinks(links))
+	}
+	return opts
+}
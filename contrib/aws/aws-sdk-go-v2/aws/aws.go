@@ -11,9 +11,11 @@ import (
 	"math"
 	"time"
 
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/spanpointer"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
@@ -21,6 +23,12 @@ import (
 	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
+const componentName = "aws/aws-sdk-go-v2/aws"
+
+func init() {
+	integrations.Register(componentName, "github.com/aws/aws-sdk-go-v2")
+}
+
 const (
 	tagAWSAgent     = "aws.agent"
 	tagAWSService   = "aws.service"
@@ -72,12 +80,14 @@ func (mw *traceMiddleware) startTraceMiddleware(stack *middleware.Stack) error {
 
 		opts := []ddtrace.StartSpanOption{
 			tracer.SpanType(ext.SpanTypeHTTP),
+			tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 			tracer.ServiceName(serviceName(mw.cfg, serviceID)),
 			tracer.ResourceName(fmt.Sprintf("%s.%s", serviceID, operation)),
 			tracer.Tag(tagAWSRegion, awsmiddleware.GetRegion(ctx)),
 			tracer.Tag(tagAWSOperation, operation),
 			tracer.Tag(tagAWSService, serviceID),
 			tracer.StartTime(ctx.Value(spanTimestampKey{}).(time.Time)),
+			tracer.Tag(ext.Component, componentName),
 		}
 		if !math.IsNaN(mw.cfg.analyticsRate) {
 			opts = append(opts, tracer.Tag(ext.EventSampleRate, mw.cfg.analyticsRate))
@@ -86,6 +96,9 @@ func (mw *traceMiddleware) startTraceMiddleware(stack *middleware.Stack) error {
 
 		// Handle initialize and continue through the middleware chain.
 		out, metadata, err = next.HandleInitialize(spanctx, in)
+		if err == nil {
+			spanpointer.AddAWS(span, serviceID, operation, in.Parameters, out.Result)
+		}
 		span.Finish(tracer.WithError(err))
 
 		return out, metadata, err
@@ -4,21 +4,33 @@
 // Copyright 2016 Datadog, Inc.
 
 // Package aws provides functions to trace aws/aws-sdk-go (https://github.com/aws/aws-sdk-go).
+//
+// The spans of the requests writing or reading S3 objects and DynamoDB items hold span
+// pointers to them: span links identifying the objects by a hash, which connect the
+// spans of the services writing and reading the same object.
 package aws // import "github.com/codebrick-corp/dd-trace-go/contrib/aws/aws-sdk-go/aws"
 
 import (
 	"math"
 	"strconv"
 
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/spanpointer"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 )
 
+const componentName = "aws/aws-sdk-go/aws"
+
+func init() {
+	integrations.Register(componentName, "github.com/aws/aws-sdk-go")
+}
+
 const (
 	tagAWSAgent      = "aws.agent"
 	tagAWSOperation  = "aws.operation"
@@ -58,6 +70,7 @@ func (h *handlers) Send(req *request.Request) {
 	}
 	opts := []ddtrace.StartSpanOption{
 		tracer.SpanType(ext.SpanTypeHTTP),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(h.serviceName(req)),
 		tracer.ResourceName(h.resourceName(req)),
 		tracer.Tag(tagAWSAgent, h.awsAgent(req)),
@@ -65,6 +78,7 @@ func (h *handlers) Send(req *request.Request) {
 		tracer.Tag(tagAWSRegion, h.awsRegion(req)),
 		tracer.Tag(ext.HTTPMethod, req.Operation.HTTPMethod),
 		tracer.Tag(ext.HTTPURL, req.HTTPRequest.URL.String()),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(h.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, h.cfg.analyticsRate))
@@ -83,6 +97,9 @@ func (h *handlers) Complete(req *request.Request) {
 	if req.HTTPResponse != nil {
 		span.SetTag(ext.HTTPCode, strconv.Itoa(req.HTTPResponse.StatusCode))
 	}
+	if req.Error == nil {
+		spanpointer.AddAWS(span, h.awsService(req), h.awsOperation(req), req.Params, req.Data)
+	}
 	span.Finish(tracer.WithError(req.Error))
 }
 
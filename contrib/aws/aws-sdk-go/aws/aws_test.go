@@ -8,15 +8,20 @@ package aws
 import (
 	"context"
 	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/ec2"
 	"github.com/aws/aws-sdk-go/service/s3"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
@@ -86,6 +91,71 @@ func TestAWS(t *testing.T) {
 	})
 }
 
+func TestSpanPointers(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"ab12ef34"`)
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+	cfg := aws.NewConfig().
+		WithRegion("us-west-2").
+		WithEndpoint(srv.URL).
+		WithS3ForcePathStyle(true).
+		WithCredentials(credentials.AnonymousCredentials)
+	session := WrapSession(session.Must(session.NewSession(cfg)))
+
+	t.Run("s3", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		_, err := s3.New(session).PutObject(&s3.PutObjectInput{
+			Bucket: aws.String("some-bucket"),
+			Key:    aws.String("some-key.data"),
+			Body:   strings.NewReader("data"),
+		})
+		require.NoError(t, err)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		links := spans[0].Links()
+		require.Len(t, links, 1)
+		assert.Equal(t, "aws.s3.object", links[0].Attributes["ptr.kind"])
+		assert.Equal(t, "d", links[0].Attributes["ptr.dir"])
+		assert.Equal(t, "e721375466d4116ab551213fdea08413", links[0].Attributes["ptr.hash"])
+	})
+
+	t.Run("dynamodb", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		_, err := dynamodb.New(session).UpdateItem(&dynamodb.UpdateItemInput{
+			TableName: aws.String("some-table"),
+			Key: map[string]*dynamodb.AttributeValue{
+				"some-key": {S: aws.String("some-value")},
+			},
+		})
+		require.NoError(t, err)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		links := spans[0].Links()
+		require.Len(t, links, 1)
+		assert.Equal(t, "aws.dynamodb.item", links[0].Attributes["ptr.kind"])
+		assert.Equal(t, "7f1aee721472bcb48701d45c7c7f7821", links[0].Attributes["ptr.hash"])
+	})
+
+	t.Run("other", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		s3.New(session).ListObjects(&s3.ListObjectsInput{Bucket: aws.String("some-bucket")})
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Empty(t, spans[0].Links())
+	})
+}
+
 func TestAnalyticsSettings(t *testing.T) {
 	cfg := aws.NewConfig().
 		WithRegion("us-west-2").
@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package beego
+
+import (
+	"net"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/appsec/dyngo/instrumentation"
+	"github.com/codebrick-corp/dd-trace-go/internal/appsec/dyngo/instrumentation/httpsec"
+
+	beecontext "github.com/beego/beego/v2/server/web/context"
+)
+
+// useAppSec starts the AppSec operation of the request of ctx, which path parameters aren't
+// known before the request goes through the router, and returns the function finishing it.
+func useAppSec(ctx *beecontext.Context, span tracer.Span) func() {
+	req := ctx.Request
+	httpsec.SetAppSecTags(span)
+	args := httpsec.MakeHandlerOperationArgs(req, nil)
+	c, op := httpsec.StartOperation(req.Context(), args)
+	ctx.Request = req.WithContext(c)
+	return func() {
+		events := op.Finish(httpsec.HandlerOperationRes{Status: ctx.ResponseWriter.Status})
+		if len(events) > 0 {
+			remoteIP, _, err := net.SplitHostPort(req.RemoteAddr)
+			if err != nil {
+				remoteIP = req.RemoteAddr
+			}
+			httpsec.SetSecurityEventTags(span, events, remoteIP, args.Headers, ctx.ResponseWriter.Header())
+		}
+		instrumentation.SetTags(span, op.Tags())
+	}
+}
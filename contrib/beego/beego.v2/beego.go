@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package beego provides functions to trace the beego/beego/v2 package (https://github.com/beego/beego).
+package beego // import "github.com/codebrick-corp/dd-trace-go/contrib/beego/beego.v2"
+
+import (
+	"fmt"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/appsec"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/beego/beego/v2/server/web"
+	beecontext "github.com/beego/beego/v2/server/web/context"
+)
+
+const componentName = "beego/beego.v2"
+
+func init() {
+	integrations.Register(componentName, "github.com/beego/beego/v2")
+}
+
+// FilterChain returns a filter chain tracing the incoming requests, to be inserted in front
+// of the routes of the application with:
+//
+//	web.InsertFilterChain("*", beegotrace.FilterChain())
+//
+// The requests are named after the pattern of the route which matched them. The panics recovered
+// by beego are reported as errors when they are responded with a 5xx status code, like the
+// responses of aborted requests.
+func FilterChain(opts ...Option) web.FilterChain {
+	appsecEnabled := appsec.Enabled()
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/beego/beego.v2: Configuring FilterChain: %#v", cfg)
+	spanOpts := []ddtrace.StartSpanOption{tracer.ServiceName(cfg.serviceName), tracer.Tag(ext.Component, componentName)}
+	mw := httptrace.NewMiddleware()
+	return func(next web.FilterFunc) web.FilterFunc {
+		return func(ctx *beecontext.Context) {
+			if cfg.ignoreRequest(ctx) {
+				next(ctx)
+				return
+			}
+			req := ctx.Request
+			// the route is only known once the request went through the router
+			opts := append([]ddtrace.StartSpanOption{tracer.ResourceName(req.Method)}, spanOpts...)
+			span, c := mw.StartRequestSpan(req, opts...)
+			ctx.Request = req.WithContext(c)
+			defer func() {
+				if r := recover(); r != nil {
+					if r != web.ErrAbort {
+						// beego was configured not to recover the panics
+						span.SetTag(ext.HTTPCode, "500")
+						span.Finish(tracer.WithError(fmt.Errorf("%v", r)))
+						panic(r)
+					}
+					// the requests aborted by ctx.Abort have their status set
+					defer panic(r)
+				}
+				if route, ok := ctx.Input.GetData("RouterPattern").(string); ok && route != "" {
+					span.SetTag(ext.ResourceName, req.Method+" "+route)
+					span.SetTag(ext.HTTPRoute, route)
+				}
+				httptrace.FinishRequestSpan(span, ctx.ResponseWriter.Status, &httptrace.Response{Header: ctx.ResponseWriter.Header()}, cfg.finishOpts...)
+			}()
+			if appsecEnabled {
+				afterFilter := useAppSec(ctx, span)
+				defer afterFilter()
+			}
+			next(ctx)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package beego
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/beego/beego/v2/server/web"
+	beecontext "github.com/beego/beego/v2/server/web/context"
+	"github.com/stretchr/testify/assert"
+)
+
+func newHandler(opts ...Option) *web.ControllerRegister {
+	h := web.NewControllerRegister()
+	h.InsertFilterChain("*", FilterChain(opts...))
+	h.Get("/user/:id", func(ctx *beecontext.Context) {
+		if _, ok := tracer.SpanFromContext(ctx.Request.Context()); !ok {
+			ctx.Abort(http.StatusExpectationFailed, "not traced")
+		}
+		ctx.Output.Body([]byte(ctx.Input.Param(":id")))
+	})
+	h.Get("/abort", func(ctx *beecontext.Context) {
+		ctx.Abort(http.StatusServiceUnavailable, "unavailable")
+	})
+	// the filter chains are applied when the server starts
+	h.Init()
+	return h
+}
+
+func TestFilterChain(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	h := newHandler(WithServiceName("foobar"))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/user/123", nil))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("123", w.Body.String())
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 1)
+	span := spans[0]
+	assert.Equal("http.request", span.OperationName())
+	assert.Equal("foobar", span.Tag(ext.ServiceName))
+	assert.Equal("GET /user/:id", span.Tag(ext.ResourceName))
+	assert.Equal("/user/:id", span.Tag(ext.HTTPRoute))
+	assert.Equal("/user/123", span.Tag(ext.HTTPURL))
+	assert.Equal("200", span.Tag(ext.HTTPCode))
+}
+
+func TestErrors(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	h := newHandler()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/abort", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/unknown", nil))
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 2)
+	assert.Equal("503", spans[0].Tag(ext.HTTPCode))
+	assert.NotNil(spans[0].Tag(ext.Error))
+	assert.Equal("404", spans[1].Tag(ext.HTTPCode))
+	assert.Equal("GET", spans[1].Tag(ext.ResourceName))
+	assert.Nil(spans[1].Tag(ext.Error))
+}
+
+func TestIgnoreRequest(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	h := newHandler(WithIgnoreRequest(func(ctx *beecontext.Context) bool {
+		return ctx.Request.URL.Path == "/user/health"
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/user/health", nil))
+	assert.Len(t, mt.FinishedSpans(), 0)
+}
@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package beego_test
+
+import (
+	beegotrace "github.com/codebrick-corp/dd-trace-go/contrib/beego/beego.v2"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/beego/beego/v2/server/web"
+	beecontext "github.com/beego/beego/v2/server/web/context"
+)
+
+func Example() {
+	// Start the tracer
+	tracer.Start()
+	defer tracer.Stop()
+
+	// Trace all the requests served by the application.
+	web.InsertFilterChain("*", beegotrace.FilterChain(beegotrace.WithServiceName("my-web-app")))
+
+	// Set up some endpoints.
+	web.Get("/", func(ctx *beecontext.Context) {
+		ctx.Output.Body([]byte("test"))
+	})
+
+	// And start gathering request traces
+	web.Run()
+}
@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package beego
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+
+	beecontext "github.com/beego/beego/v2/server/web/context"
+)
+
+const defaultServiceName = "beego"
+
+type config struct {
+	serviceName   string
+	finishOpts    []ddtrace.FinishOption
+	ignoreRequest func(*beecontext.Context) bool
+}
+
+// Option represents an option that can be passed to FilterChain.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	cfg.serviceName = defaultServiceName
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+	cfg.ignoreRequest = func(*beecontext.Context) bool { return false }
+}
+
+// WithServiceName sets the given service name for the application.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithIgnoreRequest sets a function deciding whether the request of the given context
+// is served without being traced.
+func WithIgnoreRequest(fn func(*beecontext.Context) bool) Option {
+	return func(cfg *config) {
+		cfg.ignoreRequest = fn
+	}
+}
+
+// NoDebugStack prevents stack traces from being attached to spans finishing
+// with an error. This is useful in situations where errors are frequent and
+// performance is critical.
+func NoDebugStack() Option {
+	return func(cfg *config) {
+		cfg.finishOpts = append(cfg.finishOpts, tracer.NoDebugStack())
+	}
+}
@@ -20,9 +20,16 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 )
 
+const componentName = "bradfitz/gomemcache/memcache"
+
+func init() {
+	integrations.Register(componentName, "github.com/bradfitz/gomemcache/memcache")
+}
+
 // WrapClient wraps a memcache.Client so that all requests are traced using the
 // default tracer with the service name "memcached".
 func WrapClient(client *memcache.Client, opts ...ClientOption) *Client {
@@ -67,8 +74,10 @@ func (c *Client) WithContext(ctx context.Context) *Client {
 func (c *Client) startSpan(resourceName string) ddtrace.Span {
 	opts := []ddtrace.StartSpanOption{
 		tracer.SpanType(ext.SpanTypeMemcached),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(c.cfg.serviceName),
 		tracer.ResourceName(resourceName),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(c.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, c.cfg.analyticsRate))
@@ -0,0 +1,224 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package caddy provides a Caddy HTTP handler module (https://caddyserver.com) tracing the
+// requests served by Caddy, such as the requests forwarded by its reverse proxy. The module,
+// "http.handlers.datadog", is compiled into Caddy by importing this package in a custom
+// build, e.g. with xcaddy:
+//
+//	xcaddy build --with github.com/codebrick-corp/dd-trace-go/contrib/caddyserver/caddy.v2
+//
+// The tracer is started with the configuration of the DD_* env variables when the first
+// handler is provisioned, and stopped when the last handler is cleaned up. The handler traces the requests served by the handlers following it in
+// the route, and is enabled
+// in a Caddyfile with the datadog directive, which must be ordered first:
+//
+//	{
+//		order datadog first
+//	}
+//
+//	example.com {
+//		datadog {
+//			service_name my-proxy
+//			route /api/*
+//		}
+//		reverse_proxy localhost:8080
+//	}
+package caddy // import "github.com/codebrick-corp/dd-trace-go/contrib/caddyserver/caddy.v2"
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/caddyserver/caddy/v2"
+	"github.com/caddyserver/caddy/v2/caddyconfig/caddyfile"
+	"github.com/caddyserver/caddy/v2/caddyconfig/httpcaddyfile"
+	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
+)
+
+const componentName = "caddyserver/caddy.v2"
+
+// tagUpstream holds the address of the upstream a request was forwarded to by the
+// reverse proxy of Caddy.
+const tagUpstream = "caddy.upstream"
+
+func init() {
+	integrations.Register(componentName, "github.com/caddyserver/caddy/v2")
+	caddy.RegisterModule(Handler{})
+	httpcaddyfile.RegisterHandlerDirective("datadog", parseCaddyfile)
+}
+
+// Handler is the "http.handlers.datadog" Caddy module, starting the span of the requests
+// it serves before handing them over to the next handler of the route. The context of the
+// span is injected into the headers of the requests, so that the upstreams they are
+// forwarded to continue the trace.
+type Handler struct {
+	// ServiceName is the service name of the spans. It defaults to the global service
+	// name of the tracer, or else "caddy".
+	ServiceName string `json:"service_name,omitempty"`
+
+	// Route is the route of the requests, recorded in the http.route tag and in the
+	// resource name of their spans, which is otherwise the request method. It may hold
+	// placeholders.
+	Route string `json:"route,omitempty"`
+
+	mw *httptrace.Middleware
+}
+
+var (
+	_ caddy.Provisioner           = (*Handler)(nil)
+	_ caddy.CleanerUpper          = (*Handler)(nil)
+	_ caddyhttp.MiddlewareHandler = (*Handler)(nil)
+	_ caddyfile.Unmarshaler       = (*Handler)(nil)
+)
+
+// CaddyModule returns the Caddy module information.
+func (Handler) CaddyModule() caddy.ModuleInfo {
+	return caddy.ModuleInfo{
+		ID:  "http.handlers.datadog",
+		New: func() caddy.Module { return new(Handler) },
+	}
+}
+
+// Provision implements caddy.Provisioner.
+func (h *Handler) Provision(_ caddy.Context) error {
+	if h.ServiceName == "" {
+		h.ServiceName = "caddy"
+		if svc := globalconfig.ServiceName(); svc != "" {
+			h.ServiceName = svc
+		}
+	}
+	h.mw = httptrace.NewMiddleware()
+	log.Debug("contrib/caddyserver/caddy.v2: Provisioning Handler: %#v", h)
+	startTracer()
+	return nil
+}
+
+// Cleanup implements caddy.CleanerUpper.
+func (h *Handler) Cleanup() error {
+	stopTracer()
+	return nil
+}
+
+var (
+	mu       sync.Mutex // guards handlers
+	handlers int        // number of provisioned handlers
+)
+
+// startTracer starts the tracer when the first handler is provisioned. The configurations
+// of Caddy being provisioned before the previous ones are cleaned up, the tracer keeps
+// running across configuration reloads.
+func startTracer() {
+	mu.Lock()
+	defer mu.Unlock()
+	if handlers == 0 {
+		tracer.Start()
+	}
+	handlers++
+}
+
+// stopTracer stops the tracer once the last handler is cleaned up.
+func stopTracer() {
+	mu.Lock()
+	defer mu.Unlock()
+	handlers--
+	if handlers == 0 {
+		tracer.Stop()
+	}
+}
+
+// ServeHTTP implements caddyhttp.MiddlewareHandler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request, next caddyhttp.Handler) error {
+	repl, _ := r.Context().Value(caddy.ReplacerCtxKey).(*caddy.Replacer)
+	resource := r.Method
+	opts := []ddtrace.StartSpanOption{
+		tracer.ServiceName(h.ServiceName),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if route := h.Route; route != "" {
+		if repl != nil {
+			route = repl.ReplaceAll(route, "")
+		}
+		resource += " " + route
+		opts = append(opts, tracer.Tag(ext.HTTPRoute, route))
+	}
+	opts = append(opts, tracer.ResourceName(resource))
+	span, ctx := h.mw.StartRequestSpan(r, opts...)
+	r = r.Clone(ctx)
+	if err := tracer.Inject(span.Context(), tracer.HTTPHeadersCarrier(r.Header)); err != nil {
+		log.Debug("contrib/caddyserver/caddy.v2: Failed to inject the span context: %v", err)
+	}
+	rec := caddyhttp.NewResponseRecorder(w, nil, nil)
+	err := next.ServeHTTP(rec, r)
+	if repl != nil {
+		if upstream, _ := repl.GetString("http.reverse_proxy.upstream.hostport"); upstream != "" {
+			span.SetTag(tagUpstream, upstream)
+		}
+	}
+	status := rec.Status()
+	if status == 0 && err != nil {
+		// the response is written by the error routes of the server once the error
+		// is returned
+		status = http.StatusInternalServerError
+		var herr caddyhttp.HandlerError
+		if errors.As(err, &herr) && herr.StatusCode != 0 {
+			status = herr.StatusCode
+		}
+	}
+	if status == http.StatusTooManyRequests {
+		httptrace.SetRateLimitTags(span, w.Header())
+	}
+	var finishOpts []ddtrace.FinishOption
+	if err != nil && status >= 500 {
+		finishOpts = append(finishOpts, tracer.WithError(err))
+	}
+	httptrace.FinishRequestSpan(span, status, &httptrace.Response{Header: w.Header()}, finishOpts...)
+	return err
+}
+
+// UnmarshalCaddyfile sets up the handler from the Caddyfile tokens of the datadog directive:
+//
+//	datadog {
+//		service_name <name>
+//		route <route>
+//	}
+func (h *Handler) UnmarshalCaddyfile(d *caddyfile.Dispenser) error {
+	for d.Next() {
+		if d.NextArg() {
+			return d.ArgErr()
+		}
+		for d.NextBlock(0) {
+			name := d.Val()
+			var v string
+			if !d.Args(&v) {
+				return d.ArgErr()
+			}
+			switch name {
+			case "service_name":
+				h.ServiceName = v
+			case "route":
+				h.Route = v
+			default:
+				return d.Errf("unrecognized subdirective %q", name)
+			}
+		}
+	}
+	return nil
+}
+
+func parseCaddyfile(h httpcaddyfile.Helper) (caddyhttp.MiddlewareHandler, error) {
+	m := new(Handler)
+	err := m.UnmarshalCaddyfile(h.Dispenser)
+	return m, err
+}
@@ -0,0 +1,153 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package caddy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/caddyserver/caddy/v2"
+	"github.com/caddyserver/caddy/v2/caddyconfig/caddyfile"
+	"github.com/caddyserver/caddy/v2/modules/caddyhttp"
+	_ "github.com/caddyserver/caddy/v2/modules/caddyhttp/reverseproxy"
+)
+
+// freeAddr returns a local address which can be listened to.
+func freeAddr(t *testing.T) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer l.Close()
+	return l.Addr().String()
+}
+
+func TestReverseProxy(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	var upstreamHeaders http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstreamHeaders = r.Header.Clone()
+		w.Write([]byte("OK"))
+	}))
+	defer upstream.Close()
+	u, err := url.Parse(upstream.URL)
+	require.NoError(t, err)
+
+	addr := freeAddr(t)
+	config := fmt.Sprintf(`{
+		"admin": {"disabled": true},
+		"apps": {"http": {"servers": {"srv0": {
+			"listen": [%q],
+			"automatic_https": {"disable": true},
+			"routes": [{"handle": [
+				{"handler": "datadog", "service_name": "my-proxy", "route": "/api/*"},
+				{"handler": "reverse_proxy", "upstreams": [{"dial": %q}]}
+			]}]
+		}}}}
+	}`, addr, u.Host)
+	require.NoError(t, caddy.Load([]byte(config), true))
+	defer caddy.Stop()
+
+	resp, err := http.Get("http://" + addr + "/api/users")
+	require.NoError(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "OK", string(body))
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal(t, "http.request", s.OperationName())
+	assert.Equal(t, "my-proxy", s.Tag(ext.ServiceName))
+	assert.Equal(t, "GET /api/*", s.Tag(ext.ResourceName))
+	assert.Equal(t, "/api/*", s.Tag(ext.HTTPRoute))
+	assert.Equal(t, "200", s.Tag(ext.HTTPCode))
+	assert.Equal(t, u.Host, s.Tag(tagUpstream))
+	assert.Equal(t, componentName, s.Tag(ext.Component))
+
+	// the upstream continues the trace of the proxy
+	sctx, err := tracer.Extract(tracer.HTTPHeadersCarrier(upstreamHeaders))
+	require.NoError(t, err)
+	assert.Equal(t, s.TraceID(), sctx.TraceID())
+	assert.Equal(t, s.SpanID(), sctx.SpanID())
+}
+
+func TestHandlerError(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	h := &Handler{}
+	require.NoError(t, h.Provision(caddy.Context{}))
+	defer h.Cleanup()
+
+	for _, tt := range []struct {
+		name   string
+		err    error
+		status string
+		error  bool
+	}{
+		{"not-found", caddyhttp.Error(http.StatusNotFound, errors.New("no such file")), "404", false},
+		{"bad-gateway", caddyhttp.Error(http.StatusBadGateway, errors.New("dial failed")), "502", true},
+		{"unknown", errors.New("oops"), "500", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			defer mt.Reset()
+
+			r := httptest.NewRequest("GET", "/", nil)
+			r = r.WithContext(context.WithValue(r.Context(), caddy.ReplacerCtxKey, caddy.NewReplacer()))
+			err := h.ServeHTTP(httptest.NewRecorder(), r, caddyhttp.HandlerFunc(func(http.ResponseWriter, *http.Request) error {
+				return tt.err
+			}))
+			assert.Equal(t, tt.err, err)
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			s := spans[0]
+			assert.Equal(t, "caddy", s.Tag(ext.ServiceName))
+			assert.Equal(t, "GET", s.Tag(ext.ResourceName))
+			assert.Equal(t, tt.status, s.Tag(ext.HTTPCode))
+			if tt.error {
+				assert.Equal(t, tt.err, s.Tag(ext.Error))
+			} else {
+				assert.Nil(t, s.Tag(ext.Error))
+			}
+		})
+	}
+}
+
+func TestUnmarshalCaddyfile(t *testing.T) {
+	var h Handler
+	d := caddyfile.NewTestDispenser(`datadog {
+		service_name my-proxy
+		route {http.request.host}
+	}`)
+	require.NoError(t, h.UnmarshalCaddyfile(d))
+	assert.Equal(t, "my-proxy", h.ServiceName)
+	assert.Equal(t, "{http.request.host}", h.Route)
+
+	for _, input := range []string{
+		`datadog arg`,
+		`datadog {
+			unknown value
+		}`,
+	} {
+		assert.Error(t, new(Handler).UnmarshalCaddyfile(caddyfile.NewTestDispenser(input)), input)
+	}
+}
@@ -0,0 +1,21 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package caddy_test
+
+import (
+	caddycmd "github.com/caddyserver/caddy/v2/cmd"
+
+	// plug in the standard Caddy modules and the datadog handler
+	_ "github.com/caddyserver/caddy/v2/modules/standard"
+	_ "github.com/codebrick-corp/dd-trace-go/contrib/caddyserver/caddy.v2"
+)
+
+func Example() {
+	// Build a Caddy binary holding the "http.handlers.datadog" module, to be used in the
+	// Caddyfile with the datadog directive. The tracer is started by the module, and
+	// configured with the DD_* env variables.
+	caddycmd.Main()
+}
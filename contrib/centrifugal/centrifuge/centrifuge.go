@@ -0,0 +1,140 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package centrifuge provides functions to trace the commands handled by the real-time messaging
+// servers built with the centrifugal/centrifuge package (https://github.com/centrifugal/centrifuge).
+//
+// The handlers of the subscribe, publish and RPC commands of a client are traced by wrapping them
+// when the client connects:
+//
+//	node.OnConnect(func(client *centrifuge.Client) {
+//		client.OnSubscribe(centrifugetrace.WrapSubscribeHandler(client, onSubscribe))
+//	})
+//
+// Each command is traced by a span of its own, finished when the handler calls its callback,
+// and tagged with the identifiers of the client and of its transport. The commands aren't
+// children of the span of the request which opened the connection, if any, since it only finishes
+// along with the connection.
+package centrifuge // import "github.com/codebrick-corp/dd-trace-go/contrib/centrifugal/centrifuge"
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/centrifugal/centrifuge"
+)
+
+const componentName = "centrifugal/centrifuge"
+
+func init() {
+	integrations.Register(componentName, "github.com/centrifugal/centrifuge")
+}
+
+const (
+	tagClientID   = "centrifuge.client.id"
+	tagUserID     = "centrifuge.user.id"
+	tagTransport  = "centrifuge.transport"
+	tagChannel    = "centrifuge.channel"
+	tagRPCMethod  = "centrifuge.rpc.method"
+	tagErrorCode  = "centrifuge.error.code"
+	tagDataLength = "centrifuge.data.length"
+)
+
+// WrapSubscribeHandler returns a handler tracing the subscriptions of the client with h.
+// The spans are named after the pattern of the channel (see WithChannelNamer).
+func WrapSubscribeHandler(client *centrifuge.Client, h centrifuge.SubscribeHandler, opts ...Option) centrifuge.SubscribeHandler {
+	cfg := newConfig(opts...)
+	log.Debug("contrib/centrifugal/centrifuge: Wrapping SubscribeHandler: %#v", cfg)
+	return func(e centrifuge.SubscribeEvent, cb centrifuge.SubscribeCallback) {
+		span := startSpan(cfg, client, "centrifuge.subscribe", cfg.channelNamer(e.Channel),
+			tracer.Tag(tagChannel, e.Channel))
+		h(e, func(reply centrifuge.SubscribeReply, err error) {
+			finish(span, err)
+			cb(reply, err)
+		})
+	}
+}
+
+// WrapPublishHandler returns a handler tracing the publications of the client with h.
+// The spans are named after the pattern of the channel (see WithChannelNamer).
+func WrapPublishHandler(client *centrifuge.Client, h centrifuge.PublishHandler, opts ...Option) centrifuge.PublishHandler {
+	cfg := newConfig(opts...)
+	log.Debug("contrib/centrifugal/centrifuge: Wrapping PublishHandler: %#v", cfg)
+	return func(e centrifuge.PublishEvent, cb centrifuge.PublishCallback) {
+		span := startSpan(cfg, client, "centrifuge.publish", cfg.channelNamer(e.Channel),
+			tracer.Tag(tagChannel, e.Channel),
+			tracer.Tag(tagDataLength, len(e.Data)))
+		h(e, func(reply centrifuge.PublishReply, err error) {
+			finish(span, err)
+			cb(reply, err)
+		})
+	}
+}
+
+// WrapRPCHandler returns a handler tracing the RPCs of the client with h.
+// The spans are named after the called method.
+func WrapRPCHandler(client *centrifuge.Client, h centrifuge.RPCHandler, opts ...Option) centrifuge.RPCHandler {
+	cfg := newConfig(opts...)
+	log.Debug("contrib/centrifugal/centrifuge: Wrapping RPCHandler: %#v", cfg)
+	return func(e centrifuge.RPCEvent, cb centrifuge.RPCCallback) {
+		span := startSpan(cfg, client, "centrifuge.rpc", e.Method,
+			tracer.Tag(tagRPCMethod, e.Method),
+			tracer.Tag(tagDataLength, len(e.Data)))
+		h(e, func(reply centrifuge.RPCReply, err error) {
+			finish(span, err)
+			cb(reply, err)
+		})
+	}
+}
+
+// startSpan starts the span of a command of client.
+func startSpan(cfg *config, client *centrifuge.Client, operation, resource string, opts ...ddtrace.StartSpanOption) ddtrace.Span {
+	opts = append(opts,
+		tracer.ServiceName(cfg.serviceName),
+		tracer.ResourceName(resource),
+		tracer.SpanType(ext.AppTypeRPC),
+		tracer.Tag(ext.SpanKind, ext.SpanKindServer),
+		tracer.Tag(tagClientID, client.ID()),
+		tracer.Tag(tagUserID, client.UserID()),
+		tracer.Tag(tagTransport, client.Transport().Name()),
+		tracer.Tag(ext.Component, componentName),
+		tracer.Measured(),
+	)
+	return tracer.StartSpan(operation, append(opts, cfg.spanOpts...)...)
+}
+
+// finish finishes span with the error returned to the client, if any. The centrifuge errors
+// other than internal ones are replies to the client rather than failures of the server.
+func finish(span ddtrace.Span, err error) {
+	var cerr *centrifuge.Error
+	if errors.As(err, &cerr) {
+		span.SetTag(tagErrorCode, strconv.FormatUint(uint64(cerr.Code), 10))
+		if cerr.Code != centrifuge.ErrorInternal.Code {
+			span.Finish()
+			return
+		}
+	}
+	span.Finish(tracer.WithError(err))
+}
+
+// channelPattern returns the pattern of channel, made of its namespace, or of the part before
+// its user boundary, so that the channels of each namespace share the same resource name,
+// e.g. "chat:*" for "chat:index" and "dialog#*" for "dialog#42,43".
+func channelPattern(channel string) string {
+	if i := strings.IndexByte(channel, ':'); i >= 0 {
+		return channel[:i+1] + "*"
+	}
+	if i := strings.IndexByte(channel, '#'); i >= 0 {
+		return channel[:i+1] + "*"
+	}
+	return channel
+}
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package centrifuge
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/centrifugal/centrifuge"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChannelPattern(t *testing.T) {
+	for channel, want := range map[string]string{
+		"chat:index":      "chat:*",
+		"chat:room#42,43": "chat:*",
+		"dialog#42,43":    "dialog#*",
+		"$private:42":     "$private:*",
+		"news":            "news",
+	} {
+		assert.Equal(t, want, channelPattern(channel), channel)
+	}
+}
+
+func TestFinish(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	for _, err := range []error{
+		nil,
+		centrifuge.ErrorPermissionDenied,
+		centrifuge.ErrorInternal,
+		errors.New("oh no"),
+	} {
+		finish(tracer.StartSpan("centrifuge.subscribe"), err)
+	}
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 4)
+	assert.Nil(spans[0].Tag(ext.Error))
+	assert.Nil(spans[0].Tag(tagErrorCode))
+	assert.Nil(spans[1].Tag(ext.Error))
+	assert.Equal("103", spans[1].Tag(tagErrorCode))
+	assert.Equal(centrifuge.ErrorInternal, spans[2].Tag(ext.Error))
+	assert.Equal("100", spans[2].Tag(tagErrorCode))
+	assert.NotNil(spans[3].Tag(ext.Error))
+}
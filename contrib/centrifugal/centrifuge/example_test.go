@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package centrifuge_test
+
+import (
+	centrifugetrace "github.com/codebrick-corp/dd-trace-go/contrib/centrifugal/centrifuge"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/centrifugal/centrifuge"
+)
+
+func Example() {
+	// Start the tracer
+	tracer.Start()
+	defer tracer.Stop()
+
+	node, err := centrifuge.New(centrifuge.Config{})
+	if err != nil {
+		return
+	}
+	node.OnConnect(func(client *centrifuge.Client) {
+		// Trace the commands of each client.
+		client.OnSubscribe(centrifugetrace.WrapSubscribeHandler(client,
+			func(e centrifuge.SubscribeEvent, cb centrifuge.SubscribeCallback) {
+				cb(centrifuge.SubscribeReply{}, nil)
+			}))
+		client.OnPublish(centrifugetrace.WrapPublishHandler(client,
+			func(e centrifuge.PublishEvent, cb centrifuge.PublishCallback) {
+				cb(centrifuge.PublishReply{}, nil)
+			}))
+		client.OnRPC(centrifugetrace.WrapRPCHandler(client,
+			func(e centrifuge.RPCEvent, cb centrifuge.RPCCallback) {
+				cb(centrifuge.RPCReply{Data: []byte(`{}`)}, nil)
+			}))
+	})
+	node.Run()
+}
@@ -0,0 +1,62 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package centrifuge
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+const defaultServiceName = "centrifuge"
+
+type config struct {
+	serviceName  string
+	spanOpts     []ddtrace.StartSpanOption
+	channelNamer func(channel string) string
+}
+
+// Option represents an option that can be passed to the handler wrappers.
+type Option func(*config)
+
+func newConfig(opts ...Option) *config {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	return cfg
+}
+
+func defaults(cfg *config) {
+	cfg.serviceName = defaultServiceName
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+	cfg.channelNamer = channelPattern
+}
+
+// WithServiceName sets the given service name for the traced commands.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithSpanOptions applies the given set of options to the started spans.
+func WithSpanOptions(opts ...ddtrace.StartSpanOption) Option {
+	return func(cfg *config) {
+		cfg.spanOpts = opts
+	}
+}
+
+// WithChannelNamer sets the function naming the subscribe and publish commands after their
+// channel. It defaults to the namespace of the channel followed by "*", e.g. "chat:*" for
+// "chat:index", or to the part of the channel before its user boundary, e.g. "dialog#*".
+func WithChannelNamer(fn func(channel string) string) Option {
+	return func(cfg *config) {
+		cfg.channelNamer = fn
+	}
+}
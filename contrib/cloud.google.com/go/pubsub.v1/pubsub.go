@@ -13,11 +13,18 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"cloud.google.com/go/pubsub"
 )
 
+const componentName = "cloud.google.com/go/pubsub.v1"
+
+func init() {
+	integrations.Register(componentName, "cloud.google.com/go/pubsub")
+}
+
 // Publish publishes a message on the specified topic and returns a PublishResult.
 // This function is functionally equivalent to t.Publish(ctx, msg), but it also starts a publish
 // span and it ensures that the tracing metadata is propagated as attributes attached to
@@ -30,8 +37,10 @@ func Publish(ctx context.Context, t *pubsub.Topic, msg *pubsub.Message) *Publish
 		"pubsub.publish",
 		tracer.ResourceName(t.String()),
 		tracer.SpanType(ext.SpanTypeMessageProducer),
+		tracer.Tag(ext.SpanKind, ext.SpanKindProducer),
 		tracer.Tag("message_size", len(msg.Data)),
 		tracer.Tag("ordering_key", msg.OrderingKey),
+		tracer.Tag(ext.Component, componentName),
 	)
 	if msg.Attributes == nil {
 		msg.Attributes = make(map[string]string)
@@ -92,12 +101,14 @@ func WrapReceiveHandler(s *pubsub.Subscription, f func(context.Context, *pubsub.
 		opts := []ddtrace.StartSpanOption{
 			tracer.ResourceName(s.String()),
 			tracer.SpanType(ext.SpanTypeMessageConsumer),
+			tracer.Tag(ext.SpanKind, ext.SpanKindConsumer),
 			tracer.Tag("message_size", len(msg.Data)),
 			tracer.Tag("num_attributes", len(msg.Attributes)),
 			tracer.Tag("ordering_key", msg.OrderingKey),
 			tracer.Tag("message_id", msg.ID),
 			tracer.Tag("publish_time", msg.PublishTime.String()),
 			tracer.ChildOf(parentSpanCtx),
+			tracer.Tag(ext.Component, componentName),
 		}
 		if cfg.serviceName != "" {
 			opts = append(opts, tracer.ServiceName(cfg.serviceName))
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package hertz
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+// ClientMiddleware returns a client middleware tracing the outgoing requests and
+// propagating their trace to the servers. It is meant to be given to the Use method
+// of a hertz client.
+func ClientMiddleware(opts ...Option) client.Middleware {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/cloudwego/hertz: Configuring ClientMiddleware: %#v", cfg)
+	return func(next client.Endpoint) client.Endpoint {
+		return func(ctx context.Context, req *protocol.Request, resp *protocol.Response) error {
+			method := string(req.Method())
+			opts := []ddtrace.StartSpanOption{
+				tracer.SpanType(ext.SpanTypeHTTP),
+				tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+				tracer.ServiceName(cfg.clientServiceName()),
+				tracer.ResourceName(method),
+				tracer.Tag(ext.HTTPMethod, method),
+				tracer.Tag(ext.HTTPURL, httptrace.QuantizePath(string(req.URI().Path()))),
+				tracer.Tag(ext.TargetHost, string(req.URI().Host())),
+				tracer.Tag(ext.Component, componentName),
+			}
+			opts = append(opts, cfg.spanOpts...)
+			span, ctx := tracer.StartSpanFromContext(ctx, "http.request", opts...)
+			if err := tracer.Inject(span.Context(), requestHeaderCarrier{&req.Header}); err != nil {
+				log.Debug("contrib/cloudwego/hertz: failed to inject headers: %v", err)
+			}
+			err := next(ctx, req, resp)
+			if err != nil {
+				span.Finish(tracer.WithError(err))
+				return err
+			}
+			status := resp.StatusCode()
+			span.SetTag(ext.HTTPCode, strconv.Itoa(status))
+			if cfg.isStatusError(status) {
+				span.SetTag(ext.Error, fmt.Errorf("%d: %s", status, http.StatusText(status)))
+			}
+			span.Finish()
+			return nil
+		}
+	}
+}
@@ -0,0 +1,57 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package hertz
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClientMiddleware(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	var propagated bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := tracer.Extract(tracer.HTTPHeadersCarrier(r.Header))
+		propagated = err == nil
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c, err := client.NewClient()
+	assert.NoError(err)
+	c.Use(ClientMiddleware(WithServiceName("caller")))
+
+	root, ctx := tracer.StartSpanFromContext(context.Background(), "root")
+	status, _, err := c.Get(ctx, nil, srv.URL+"/users")
+	root.Finish()
+	assert.NoError(err)
+	assert.Equal(http.StatusServiceUnavailable, status)
+	assert.True(propagated)
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 2)
+	span := spans[0]
+	assert.Equal("http.request", span.OperationName())
+	assert.Equal(ext.SpanKindClient, span.Tag(ext.SpanKind))
+	assert.Equal("caller", span.Tag(ext.ServiceName))
+	assert.Equal("GET", span.Tag(ext.ResourceName))
+	assert.Equal("/users", span.Tag(ext.HTTPURL))
+	assert.Equal("503", span.Tag(ext.HTTPCode))
+	assert.NotNil(span.Tag(ext.Error))
+	assert.Equal(root.Context().SpanID(), span.ParentID())
+}
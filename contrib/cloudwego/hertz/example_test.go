@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package hertz_test
+
+import (
+	"context"
+
+	hertztrace "github.com/codebrick-corp/dd-trace-go/contrib/cloudwego/hertz"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/client"
+	"github.com/cloudwego/hertz/pkg/app/server"
+)
+
+func Example() {
+	// Start the tracer
+	tracer.Start()
+	defer tracer.Stop()
+
+	// Create a hertz server using the tracer middleware.
+	h := server.Default()
+	h.Use(hertztrace.Middleware(hertztrace.WithServiceName("my-web-app")))
+
+	// Set up some endpoints.
+	h.GET("/", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(200, "test")
+	})
+
+	// And start gathering request traces
+	h.Spin()
+}
+
+func ExampleClientMiddleware() {
+	// Create a hertz client tracing its requests.
+	c, err := client.NewClient()
+	if err != nil {
+		return
+	}
+	c.Use(hertztrace.ClientMiddleware())
+
+	// Requests sent with a context holding a span are its children.
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "parent")
+	defer span.Finish()
+	c.Get(ctx, nil, "http://localhost:8888/")
+}
@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package hertz provides functions to trace the cloudwego/hertz package (https://github.com/cloudwego/hertz).
+package hertz // import "github.com/codebrick-corp/dd-trace-go/contrib/cloudwego/hertz"
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/protocol"
+)
+
+const componentName = "cloudwego/hertz"
+
+func init() {
+	integrations.Register(componentName, "github.com/cloudwego/hertz")
+}
+
+// Middleware returns a server middleware tracing the incoming requests. The requests are named
+// after the route which matched them. The client IP is the one reported by hertz, which only
+// trusts the proxy headers according to the ClientIPOptions of the engine.
+func Middleware(opts ...Option) app.HandlerFunc {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/cloudwego/hertz: Configuring Middleware: %#v", cfg)
+	return func(c context.Context, ctx *app.RequestContext) {
+		if cfg.ignoreRequest(ctx) {
+			ctx.Next(c)
+			return
+		}
+		route := ctx.FullPath()
+		method := string(ctx.Method())
+		opts := []ddtrace.StartSpanOption{
+			tracer.SpanType(ext.SpanTypeWeb),
+			tracer.Tag(ext.SpanKind, ext.SpanKindServer),
+			tracer.ServiceName(cfg.serverServiceName()),
+			tracer.ResourceName(method + " " + route),
+			tracer.Tag(ext.HTTPMethod, method),
+			tracer.Tag(ext.HTTPURL, httptrace.QuantizePath(string(ctx.Request.URI().Path()))),
+			tracer.Tag(ext.HTTPRoute, route),
+			tracer.Tag(ext.HTTPUserAgent, string(ctx.UserAgent())),
+			tracer.Tag("http.host", string(ctx.Host())),
+			tracer.Measured(),
+			tracer.Tag(ext.Component, componentName),
+		}
+		if ip := net.ParseIP(ctx.ClientIP()); ip != nil {
+			opts = append(opts, tracer.Tag(ext.HTTPClientIP, ip.String()))
+			if hook := globalconfig.ClientIPHook(); hook != nil {
+				for k, v := range hook(ip) {
+					opts = append(opts, tracer.Tag(k, v))
+				}
+			}
+		}
+		if spanctx, err := tracer.Extract(requestHeaderCarrier{&ctx.Request.Header}); err == nil {
+			opts = append(opts, tracer.ChildOf(spanctx))
+		}
+		opts = append(opts, cfg.spanOpts...)
+		span, c := tracer.StartSpanFromContext(c, "http.request", opts...)
+		defer func() {
+			status := ctx.Response.StatusCode()
+			span.SetTag(ext.HTTPCode, strconv.Itoa(status))
+			if err := ctx.Errors.Last(); err != nil {
+				span.SetTag(ext.Error, err.Err)
+			} else if cfg.isStatusError(status) {
+				span.SetTag(ext.Error, fmt.Errorf("%d: %s", status, http.StatusText(status)))
+			}
+			span.Finish()
+		}()
+
+		ctx.Next(c)
+	}
+}
+
+// requestHeaderCarrier adapts the headers of a hertz request to the carrier interfaces
+// used for distributed tracing.
+type requestHeaderCarrier struct {
+	h *protocol.RequestHeader
+}
+
+var (
+	_ tracer.TextMapReader = requestHeaderCarrier{}
+	_ tracer.TextMapWriter = requestHeaderCarrier{}
+)
+
+// ForeachKey implements tracer.TextMapReader.
+func (c requestHeaderCarrier) ForeachKey(handler func(key, val string) error) error {
+	var err error
+	c.h.VisitAll(func(k, v []byte) {
+		if err == nil {
+			err = handler(string(k), string(v))
+		}
+	})
+	return err
+}
+
+// Set implements tracer.TextMapWriter.
+func (c requestHeaderCarrier) Set(key, val string) {
+	c.h.Set(key, val)
+}
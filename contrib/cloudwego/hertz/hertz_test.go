@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package hertz
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/cloudwego/hertz/pkg/app"
+	"github.com/cloudwego/hertz/pkg/app/server"
+	"github.com/cloudwego/hertz/pkg/common/ut"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMiddleware(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	h := server.New()
+	h.Use(Middleware(WithServiceName("foobar")))
+	h.GET("/user/:id", func(c context.Context, ctx *app.RequestContext) {
+		span, ok := tracer.SpanFromContext(c)
+		assert.True(ok)
+		span.SetTag("test.hertz", "ok")
+		ctx.String(200, ctx.Param("id"))
+	})
+
+	root := tracer.StartSpan("root")
+	w := ut.PerformRequest(h.Engine, "GET", "/user/123", nil,
+		ut.Header{Key: "x-datadog-trace-id", Value: strconv.FormatUint(root.Context().TraceID(), 10)},
+		ut.Header{Key: "x-datadog-parent-id", Value: strconv.FormatUint(root.Context().SpanID(), 10)})
+	assert.Equal(200, w.Result().StatusCode())
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 1)
+	span := spans[0]
+	assert.Equal("http.request", span.OperationName())
+	assert.Equal(ext.SpanTypeWeb, span.Tag(ext.SpanType))
+	assert.Equal(ext.SpanKindServer, span.Tag(ext.SpanKind))
+	assert.Equal("foobar", span.Tag(ext.ServiceName))
+	assert.Equal("GET /user/:id", span.Tag(ext.ResourceName))
+	assert.Equal("/user/:id", span.Tag(ext.HTTPRoute))
+	assert.Equal("/user/123", span.Tag(ext.HTTPURL))
+	assert.Equal("200", span.Tag(ext.HTTPCode))
+	assert.Equal("ok", span.Tag("test.hertz"))
+	assert.Equal(root.Context().SpanID(), span.ParentID())
+}
+
+func TestMiddlewareError(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	h := server.New()
+	h.Use(Middleware())
+	h.GET("/err", func(c context.Context, ctx *app.RequestContext) {
+		ctx.Error(errors.New("oh no"))
+		ctx.String(500, "failed")
+	})
+	h.GET("/500", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(500, "failed")
+	})
+	ut.PerformRequest(h.Engine, "GET", "/err", nil)
+	ut.PerformRequest(h.Engine, "GET", "/500", nil)
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 2)
+	assert.Equal("oh no", spans[0].Tag(ext.Error).(error).Error())
+	assert.Equal("500", spans[1].Tag(ext.HTTPCode))
+	assert.NotNil(spans[1].Tag(ext.Error))
+}
+
+func TestIgnoreRequest(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	h := server.New()
+	h.Use(Middleware(WithIgnoreRequest(func(ctx *app.RequestContext) bool {
+		return string(ctx.Request.URI().Path()) == "/health"
+	})))
+	h.GET("/:path", func(c context.Context, ctx *app.RequestContext) {
+		ctx.String(200, "ok")
+	})
+	ut.PerformRequest(h.Engine, "GET", "/health", nil)
+	ut.PerformRequest(h.Engine, "GET", "/users", nil)
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 1)
+	assert.Equal("/users", spans[0].Tag(ext.HTTPURL))
+}
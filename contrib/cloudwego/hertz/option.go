@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package hertz
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+
+	"github.com/cloudwego/hertz/pkg/app"
+)
+
+type config struct {
+	serviceName   string
+	spanOpts      []ddtrace.StartSpanOption
+	isStatusError func(statusCode int) bool
+	ignoreRequest func(*app.RequestContext) bool
+}
+
+// Option represents an option that can be passed to Middleware and ClientMiddleware.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+	cfg.isStatusError = isServerError
+	cfg.ignoreRequest = func(*app.RequestContext) bool { return false }
+}
+
+func (cfg *config) serverServiceName() string {
+	if cfg.serviceName == "" {
+		return "hertz"
+	}
+	return cfg.serviceName
+}
+
+func (cfg *config) clientServiceName() string {
+	if cfg.serviceName == "" {
+		return "hertz.client"
+	}
+	return cfg.serviceName
+}
+
+// WithServiceName sets the given service name for the server or the client.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithSpanOptions applies the given set of options to the started spans.
+func WithSpanOptions(opts ...ddtrace.StartSpanOption) Option {
+	return func(cfg *config) {
+		cfg.spanOpts = opts
+	}
+}
+
+// WithStatusCheck sets a function telling whether a status code is an error.
+// It defaults to reporting the 5xx status codes as errors.
+func WithStatusCheck(fn func(statusCode int) bool) Option {
+	return func(cfg *config) {
+		cfg.isStatusError = fn
+	}
+}
+
+// WithIgnoreRequest sets a function deciding whether the request of the given context
+// is served without being traced. It only applies to Middleware.
+func WithIgnoreRequest(fn func(*app.RequestContext) bool) Option {
+	return func(cfg *config) {
+		cfg.ignoreRequest = fn
+	}
+}
+
+func isServerError(statusCode int) bool {
+	return statusCode >= 500 && statusCode < 600
+}
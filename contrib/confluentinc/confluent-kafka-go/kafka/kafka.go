@@ -13,11 +13,18 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/confluentinc/confluent-kafka-go/kafka"
 )
 
+const componentName = "confluentinc/confluent-kafka-go/kafka"
+
+func init() {
+	integrations.Register(componentName, "github.com/confluentinc/confluent-kafka-go/kafka")
+}
+
 // NewConsumer calls kafka.NewConsumer and wraps the resulting Consumer.
 func NewConsumer(conf *kafka.ConfigMap, opts ...Option) (*Consumer, error) {
 	c, err := kafka.NewConsumer(conf)
@@ -94,13 +101,26 @@ func (c *Consumer) startSpan(msg *kafka.Message) ddtrace.Span {
 		tracer.ServiceName(c.cfg.consumerServiceName),
 		tracer.ResourceName("Consume Topic " + *msg.TopicPartition.Topic),
 		tracer.SpanType(ext.SpanTypeMessageConsumer),
+		tracer.Tag(ext.SpanKind, ext.SpanKindConsumer),
 		tracer.Tag("partition", msg.TopicPartition.Partition),
 		tracer.Tag("offset", msg.TopicPartition.Offset),
 		tracer.Measured(),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(c.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, c.cfg.analyticsRate))
 	}
+	// the watermarks are obtained from the local cache, no request is sent to the broker
+	if _, high, err := c.Consumer.GetWatermarkOffsets(*msg.TopicPartition.Topic, msg.TopicPartition.Partition); err == nil && high >= 0 {
+		lag := high - int64(msg.TopicPartition.Offset) - 1
+		if lag < 0 {
+			lag = 0
+		}
+		opts = append(opts,
+			tracer.Tag("kafka.high_watermark", high),
+			tracer.Tag("kafka.lag", lag),
+		)
+	}
 	// kafka supports headers, so try to extract a span context
 	carrier := NewMessageCarrier(msg)
 	if spanctx, err := tracer.Extract(carrier); err == nil {
@@ -160,6 +180,45 @@ func (c *Consumer) ReadMessage(timeout time.Duration) (*kafka.Message, error) {
 	return msg, nil
 }
 
+// Commit calls the underlying Consumer.Commit and traces the request.
+func (c *Consumer) Commit() ([]kafka.TopicPartition, error) {
+	span := c.startCommitSpan("Commit Offsets")
+	offsets, err := c.Consumer.Commit()
+	span.Finish(tracer.WithError(err))
+	return offsets, err
+}
+
+// CommitMessage calls the underlying Consumer.CommitMessage and traces the request.
+func (c *Consumer) CommitMessage(msg *kafka.Message) ([]kafka.TopicPartition, error) {
+	span := c.startCommitSpan("Commit Topic "+*msg.TopicPartition.Topic,
+		tracer.Tag("partition", msg.TopicPartition.Partition),
+		tracer.Tag("offset", msg.TopicPartition.Offset),
+	)
+	offsets, err := c.Consumer.CommitMessage(msg)
+	span.Finish(tracer.WithError(err))
+	return offsets, err
+}
+
+// CommitOffsets calls the underlying Consumer.CommitOffsets and traces the request.
+func (c *Consumer) CommitOffsets(offsets []kafka.TopicPartition) ([]kafka.TopicPartition, error) {
+	span := c.startCommitSpan("Commit Offsets", tracer.Tag("kafka.partitions", len(offsets)))
+	committed, err := c.Consumer.CommitOffsets(offsets)
+	span.Finish(tracer.WithError(err))
+	return committed, err
+}
+
+func (c *Consumer) startCommitSpan(resource string, extra ...tracer.StartSpanOption) ddtrace.Span {
+	opts := append([]tracer.StartSpanOption{
+		tracer.ServiceName(c.cfg.consumerServiceName),
+		tracer.ResourceName(resource),
+		tracer.SpanType(ext.SpanTypeMessageConsumer),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.Tag(ext.Component, componentName),
+	}, extra...)
+	span, _ := tracer.StartSpanFromContext(c.cfg.ctx, "kafka.commit", opts...)
+	return span
+}
+
 // A Producer wraps a kafka.Producer.
 type Producer struct {
 	*kafka.Producer
@@ -200,7 +259,9 @@ func (p *Producer) startSpan(msg *kafka.Message) ddtrace.Span {
 		tracer.ServiceName(p.cfg.producerServiceName),
 		tracer.ResourceName("Produce Topic " + *msg.TopicPartition.Topic),
 		tracer.SpanType(ext.SpanTypeMessageProducer),
+		tracer.Tag(ext.SpanKind, ext.SpanKindProducer),
 		tracer.Tag("partition", msg.TopicPartition.Partition),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(p.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, p.cfg.analyticsRate))
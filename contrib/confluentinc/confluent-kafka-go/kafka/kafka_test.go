@@ -210,6 +210,61 @@ func TestConsumerFunctional(t *testing.T) {
 	}
 }
 
+func TestConsumerCommit(t *testing.T) {
+	if _, ok := os.LookupEnv("INTEGRATION"); !ok {
+		t.Skip("to enable integration test, set the INTEGRATION environment variable")
+	}
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	p, err := NewProducer(&kafka.ConfigMap{
+		"bootstrap.servers":   "127.0.0.1:9092",
+		"go.delivery.reports": true,
+	})
+	assert.NoError(t, err)
+	delivery := make(chan kafka.Event, 1)
+	err = p.Produce(&kafka.Message{
+		TopicPartition: kafka.TopicPartition{Topic: &testTopic, Partition: 0},
+		Value:          []byte("value"),
+	}, delivery)
+	assert.NoError(t, err)
+	msg1, _ := (<-delivery).(*kafka.Message)
+	p.Close()
+
+	c, err := NewConsumer(&kafka.ConfigMap{
+		"group.id":           testGroupID,
+		"bootstrap.servers":  "127.0.0.1:9092",
+		"socket.timeout.ms":  1000,
+		"session.timeout.ms": 1000,
+		"enable.auto.commit": false,
+	})
+	assert.NoError(t, err)
+	err = c.Assign([]kafka.TopicPartition{
+		{Topic: &testTopic, Partition: 0, Offset: msg1.TopicPartition.Offset},
+	})
+	assert.NoError(t, err)
+	msg2, err := c.ReadMessage(3000 * time.Millisecond)
+	assert.NoError(t, err)
+	_, err = c.CommitMessage(msg2)
+	assert.NoError(t, err)
+	c.Close()
+
+	spans := mt.FinishedSpans()
+	assert.Len(t, spans, 3)
+
+	s := spans[1] // commit
+	assert.Equal(t, "kafka.commit", s.OperationName())
+	assert.Equal(t, "Commit Topic gotest", s.Tag(ext.ResourceName))
+	assert.Equal(t, int32(0), s.Tag("partition"))
+	assert.Equal(t, msg2.TopicPartition.Offset, s.Tag("offset"))
+	assert.Nil(t, s.Tag(ext.Error))
+
+	s = spans[2] // consume
+	assert.Equal(t, "kafka.consume", s.OperationName())
+	assert.NotNil(t, s.Tag("kafka.high_watermark"))
+	assert.NotNil(t, s.Tag("kafka.lag"))
+}
+
 // This tests the deprecated behavior of using cfg.context as the context passed via kafka messages
 // instead of the one passed in the message.
 func TestDeprecatedContext(t *testing.T) {
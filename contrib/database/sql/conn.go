@@ -122,8 +122,8 @@ func (tc *tracedConn) QueryContext(ctx context.Context, query string, args []dri
 	if queryerContext, ok := tc.Conn.(driver.QueryerContext); ok {
 		cquery, spanID := injectComments(ctx, query, tc.cfg.commentInjectionMode)
 		rows, err := queryerContext.QueryContext(ctx, cquery, args)
-		tc.tryTrace(ctx, queryTypeQuery, query, start, err, tracer.WithSpanID(spanID))
-		return rows, err
+		span := tc.tryTraceQuery(ctx, query, args, start, err, tracer.WithSpanID(spanID))
+		return tc.wrapRows(rows, span, query), err
 	}
 	if queryer, ok := tc.Conn.(driver.Queryer); ok {
 		dargs, err := namedValueToValue(args)
@@ -137,8 +137,8 @@ func (tc *tracedConn) QueryContext(ctx context.Context, query string, args []dri
 		}
 		cquery, spanID := injectComments(ctx, query, tc.cfg.commentInjectionMode)
 		rows, err = queryer.Query(cquery, dargs)
-		tc.tryTrace(ctx, queryTypeQuery, query, start, err, tracer.WithSpanID(spanID))
-		return rows, err
+		span := tc.tryTraceQuery(ctx, query, args, start, err, tracer.WithSpanID(spanID))
+		return tc.wrapRows(rows, span, query), err
 	}
 	return nil, driver.ErrSkip
 }
@@ -166,6 +166,7 @@ type traceParams struct {
 	cfg        *config
 	driverName string
 	meta       map[string]string
+	plans      *queryPlanCapturer // nil unless query plans are captured
 }
 
 type contextKey int
@@ -200,25 +201,51 @@ func injectComments(ctx context.Context, query string, mode tracer.SQLCommentInj
 
 // tryTrace will create a span using the given arguments, but will act as a no-op when err is driver.ErrSkip.
 func (tp *traceParams) tryTrace(ctx context.Context, qtype queryType, query string, startTime time.Time, err error, spanOpts ...ddtrace.StartSpanOption) {
+	tp.trace(ctx, qtype, query, startTime, time.Now(), err, spanOpts...)
+}
+
+// tryTraceQuery acts like tryTrace for queries ran with the given args. When query plan
+// capture is enabled, the plan of the query may additionally be captured in the background.
+// It returns the finished span, or nil if none was created.
+func (tp *traceParams) tryTraceQuery(ctx context.Context, query string, args []driver.NamedValue, startTime time.Time, err error, spanOpts ...ddtrace.StartSpanOption) ddtrace.Span {
+	finishTime := time.Now()
+	span := tp.trace(ctx, queryTypeQuery, query, startTime, finishTime, err, spanOpts...)
+	if span != nil && tp.shouldCaptureQueryPlan(query, finishTime.Sub(startTime), err) {
+		tp.plans.capture(span, query, args)
+	}
+	return span
+}
+
+// trace creates a span starting at startTime and finishing at finishTime using the given
+// arguments and returns it. It acts as a no-op returning nil when err is driver.ErrSkip.
+func (tp *traceParams) trace(ctx context.Context, qtype queryType, query string, startTime, finishTime time.Time, err error, spanOpts ...ddtrace.StartSpanOption) ddtrace.Span {
 	if err == driver.ErrSkip {
 		// Not a user error: driver is telling sql package that an
 		// optional interface method is not implemented. There is
 		// nothing to trace here.
 		// See: https://github.com/DataDog/dd-trace-go/issues/270
-		return
+		return nil
 	}
 	if _, exists := tracer.SpanFromContext(ctx); tp.cfg.childSpansOnly && !exists {
-		return
+		return nil
 	}
 	name := fmt.Sprintf("%s.query", tp.driverName)
 	opts := append(spanOpts,
 		tracer.ServiceName(tp.cfg.serviceName),
 		tracer.SpanType(ext.SpanTypeSQL),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.Tag(ext.Component, componentName),
 		tracer.StartTime(startTime),
 	)
 	if !math.IsNaN(tp.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, tp.cfg.analyticsRate))
 	}
+	if tp.cfg.dbSystem != "" {
+		opts = append(opts,
+			tracer.Tag(ext.DBType, tp.cfg.dbSystem),
+			tracer.Tag(ext.DBSystem, tp.cfg.dbSystem),
+		)
+	}
 	span, _ := tracer.StartSpanFromContext(ctx, name, opts...)
 	resource := string(qtype)
 	if query != "" {
@@ -234,5 +261,6 @@ func (tp *traceParams) tryTrace(ctx context.Context, qtype queryType, query stri
 			span.SetTag(k, v)
 		}
 	}
-	span.Finish(tracer.WithError(err))
+	span.Finish(tracer.WithError(err), tracer.FinishTime(finishTime))
+	return span
 }
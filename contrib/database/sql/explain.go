@@ -0,0 +1,214 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"math/rand"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+const (
+	// keyQueryPlan is the span tag holding the captured query plan.
+	keyQueryPlan = "sql.plan"
+
+	// maxQueryPlanSize is the maximum size of a query plan attached to a span. Larger
+	// plans are truncated.
+	maxQueryPlanSize = 16 * 1024
+
+	// queryPlanTimeout bounds the time spent capturing a single query plan.
+	queryPlanTimeout = 2 * time.Second
+)
+
+// queryPlanConfig holds the configuration for capturing query plans.
+type queryPlanConfig struct {
+	// threshold is the minimum duration of a query for its plan to be captured.
+	threshold time.Duration
+	// rate is the percentage (between 0 and 1) of slow queries for which a plan is captured.
+	rate float64
+}
+
+// explainPrefix returns the statement prefix used to obtain a query plan for the given
+// driver, or false if the driver does not support capturing query plans.
+func explainPrefix(driverName string) (string, bool) {
+	switch driverName {
+	case "postgres", "pgx":
+		return "EXPLAIN (FORMAT JSON) ", true
+	case "mysql":
+		return "EXPLAIN FORMAT=JSON ", true
+	default:
+		return "", false
+	}
+}
+
+// isReadOnlyQuery reports whether query is a plain read statement which is safe to EXPLAIN.
+// Anything else (DML, DDL, multiple statements or already explained queries) is rejected.
+func isReadOnlyQuery(query string) bool {
+	q := strings.TrimSpace(query)
+	if strings.Contains(strings.TrimSuffix(q, ";"), ";") {
+		// multiple statements
+		return false
+	}
+	fields := strings.Fields(q)
+	if len(fields) == 0 {
+		return false
+	}
+	switch strings.ToUpper(fields[0]) {
+	case "SELECT":
+		return true
+	default:
+		return false
+	}
+}
+
+// shouldCaptureQueryPlan reports whether a query plan should be captured for the given query,
+// which took duration to run and returned err.
+func (tp *traceParams) shouldCaptureQueryPlan(query string, duration time.Duration, err error) bool {
+	qp := tp.cfg.queryPlan
+	if qp == nil || tp.plans == nil || err != nil {
+		return false
+	}
+	if duration < qp.threshold {
+		return false
+	}
+	if _, ok := explainPrefix(tp.driverName); !ok {
+		return false
+	}
+	if !isReadOnlyQuery(query) {
+		return false
+	}
+	return rand.Float64() < qp.rate
+}
+
+// queryPlanCapturer captures the query plans of the queries of a database in the background,
+// so that the queries never wait for their plans. At most one plan is captured at a time for
+// a given database, on a connection of its own: the plans of the queries which are slow while
+// a capture is in flight are skipped.
+type queryPlanCapturer struct {
+	connector  driver.Connector
+	driverName string
+	cfg        *config
+	busy       int32 // accessed atomically, 1 while a plan is being captured
+}
+
+// capture starts capturing in the background the plan of query, ran with args. The plan is
+// reported as a span child of span, the span of the query. It is a no-op if a plan is being
+// captured already.
+func (c *queryPlanCapturer) capture(span ddtrace.Span, query string, args []driver.NamedValue) {
+	if !atomic.CompareAndSwapInt32(&c.busy, 0, 1) {
+		return
+	}
+	args = copyNamedValues(args)
+	go func() {
+		defer atomic.StoreInt32(&c.busy, 0)
+		start := time.Now()
+		// the query returned already, and its context may be canceled at any time
+		plan, err := c.explain(context.Background(), query, args)
+		if err != nil {
+			log.Debug("contrib/database/sql: failed to capture query plan: %v", err)
+			return
+		}
+		if plan == "" {
+			return
+		}
+		opts := []ddtrace.StartSpanOption{
+			tracer.ChildOf(span.Context()),
+			tracer.StartTime(start),
+			tracer.ServiceName(c.cfg.serviceName),
+			tracer.SpanType(ext.SpanTypeSQL),
+			tracer.ResourceName(query),
+			tracer.Tag(ext.Component, componentName),
+			tracer.Tag(keyQueryPlan, plan),
+		}
+		if c.cfg.dbSystem != "" {
+			opts = append(opts, tracer.Tag(ext.DBSystem, c.cfg.dbSystem))
+		}
+		tracer.StartSpan(c.driverName+".query.plan", opts...).Finish()
+	}()
+}
+
+// copyNamedValues returns a copy of args which stays valid once the query returned.
+func copyNamedValues(args []driver.NamedValue) []driver.NamedValue {
+	cp := make([]driver.NamedValue, len(args))
+	copy(cp, args)
+	for i, arg := range cp {
+		if b, ok := arg.Value.([]byte); ok {
+			cp[i].Value = append([]byte(nil), b...)
+		}
+	}
+	return cp
+}
+
+// explain runs EXPLAIN for the given query on a dedicated connection and returns the
+// resulting plan. The statement is never executed: only the plan is computed (EXPLAIN ANALYZE
+// is never used), inside a read-only transaction whenever the driver supports it.
+func (c *queryPlanCapturer) explain(ctx context.Context, query string, args []driver.NamedValue) (plan string, err error) {
+	prefix, _ := explainPrefix(c.driverName)
+	ctx, cancel := context.WithTimeout(ctx, queryPlanTimeout)
+	defer cancel()
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer conn.Close()
+	queryer, ok := conn.(driver.QueryerContext)
+	if !ok {
+		return "", fmt.Errorf("driver %q does not implement driver.QueryerContext", c.driverName)
+	}
+	if beginTx, ok := conn.(driver.ConnBeginTx); ok {
+		tx, err := beginTx.BeginTx(ctx, driver.TxOptions{ReadOnly: true})
+		if err != nil {
+			return "", err
+		}
+		defer tx.Rollback()
+	}
+	rows, err := queryer.QueryContext(ctx, prefix+query, args)
+	if err != nil {
+		return "", err
+	}
+	defer rows.Close()
+	var sb strings.Builder
+	dest := make([]driver.Value, len(rows.Columns()))
+	if len(dest) == 0 {
+		return "", nil
+	}
+	for {
+		if err := rows.Next(dest); err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte('\n')
+		}
+		switch v := dest[0].(type) {
+		case []byte:
+			sb.Write(v)
+		case string:
+			sb.WriteString(v)
+		default:
+			fmt.Fprint(&sb, v)
+		}
+		if sb.Len() > maxQueryPlanSize {
+			break
+		}
+	}
+	plan = sb.String()
+	if len(plan) > maxQueryPlanSize {
+		plan = plan[:maxQueryPlanSize]
+	}
+	return plan, nil
+}
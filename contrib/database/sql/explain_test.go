@@ -0,0 +1,268 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package sql
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+)
+
+// explainDriver is a driver returning a fake plan for EXPLAIN statements, after waiting
+// for explain to be closed when set, and sleeping for delay on any other query.
+type explainDriver struct {
+	delay   time.Duration
+	explain chan struct{}
+
+	mu       sync.Mutex
+	executed []string
+	readOnly []bool
+}
+
+// queries returns the queries executed so far.
+func (d *explainDriver) queries() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.executed...)
+}
+
+func (d *explainDriver) Open(_ string) (driver.Conn, error) { return &explainConn{d: d}, nil }
+
+type explainConn struct {
+	d  *explainDriver
+	tx bool
+}
+
+func (c *explainConn) Prepare(_ string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+
+func (c *explainConn) Close() error { return nil }
+
+func (c *explainConn) Begin() (driver.Tx, error) { return c, nil }
+
+func (c *explainConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.d.mu.Lock()
+	defer c.d.mu.Unlock()
+	c.d.readOnly = append(c.d.readOnly, opts.ReadOnly)
+	return c, nil
+}
+
+func (c *explainConn) Commit() error { return nil }
+
+func (c *explainConn) Rollback() error { return nil }
+
+func (c *explainConn) QueryContext(ctx context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	c.d.mu.Lock()
+	c.d.executed = append(c.d.executed, query)
+	c.d.mu.Unlock()
+	if strings.HasPrefix(query, "EXPLAIN") {
+		if c.d.explain != nil {
+			select {
+			case <-c.d.explain:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		return &explainRows{lines: []string{`[{"Plan": {"Node Type": "Seq Scan"}}]`}}, nil
+	}
+	time.Sleep(c.d.delay)
+	return &explainRows{}, nil
+}
+
+type explainRows struct {
+	lines []string
+}
+
+func (r *explainRows) Columns() []string { return []string{"QUERY PLAN"} }
+
+func (r *explainRows) Close() error { return nil }
+
+func (r *explainRows) Next(dest []driver.Value) error {
+	if len(r.lines) == 0 {
+		return io.EOF
+	}
+	dest[0], r.lines = []byte(r.lines[0]), r.lines[1:]
+	return nil
+}
+
+func TestQueryPlanCapture(t *testing.T) {
+	for name, tt := range map[string]struct {
+		driverName string
+		query      string
+		delay      time.Duration
+		opts       []Option
+		plan       bool
+	}{
+		"slow": {
+			driverName: "postgres",
+			query:      "SELECT * FROM users WHERE id = $1",
+			delay:      10 * time.Millisecond,
+			opts:       []Option{WithQueryPlanCapture(time.Millisecond, 1)},
+			plan:       true,
+		},
+		"fast": {
+			driverName: "postgres",
+			query:      "SELECT * FROM users WHERE id = $1",
+			opts:       []Option{WithQueryPlanCapture(time.Hour, 1)},
+		},
+		"disabled": {
+			driverName: "postgres",
+			query:      "SELECT * FROM users WHERE id = $1",
+			delay:      10 * time.Millisecond,
+		},
+		"not-sampled": {
+			driverName: "postgres",
+			query:      "SELECT * FROM users WHERE id = $1",
+			delay:      10 * time.Millisecond,
+			opts:       []Option{WithQueryPlanCapture(time.Millisecond, 0)},
+		},
+		"write": {
+			driverName: "postgres",
+			query:      "DELETE FROM users WHERE id = $1 RETURNING id",
+			delay:      10 * time.Millisecond,
+			opts:       []Option{WithQueryPlanCapture(time.Millisecond, 1)},
+		},
+		"multiple-statements": {
+			driverName: "mysql",
+			query:      "SELECT 1; DROP TABLE users",
+			delay:      10 * time.Millisecond,
+			opts:       []Option{WithQueryPlanCapture(time.Millisecond, 1)},
+		},
+		"unsupported-driver": {
+			driverName: "sqlserver",
+			query:      "SELECT * FROM users",
+			delay:      10 * time.Millisecond,
+			opts:       []Option{WithQueryPlanCapture(time.Millisecond, 1)},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			d := &explainDriver{delay: tt.delay}
+			Register(tt.driverName, d)
+			defer unregister(tt.driverName)
+			db, err := Open(tt.driverName, "", tt.opts...)
+			require.NoError(t, err)
+			defer db.Close()
+
+			rows, err := db.QueryContext(context.Background(), tt.query, 1)
+			require.NoError(t, err)
+			rows.Close()
+
+			s := querySpan(t, mt)
+			assert.Equal(t, tt.query, s.Tag("resource.name"))
+			assert.Nil(t, s.Tag(keyQueryPlan))
+			if !tt.plan {
+				// leave time to a capture which should not happen
+				time.Sleep(10 * time.Millisecond)
+				assert.Nil(t, planSpan(mt))
+				assert.Len(t, d.queries(), 1)
+				return
+			}
+			plan := waitPlanSpan(t, mt)
+			assert.Equal(t, tt.driverName+".query.plan", plan.OperationName())
+			assert.Equal(t, s.SpanID(), plan.ParentID())
+			assert.Equal(t, tt.query, plan.Tag("resource.name"))
+			assert.Equal(t, `[{"Plan": {"Node Type": "Seq Scan"}}]`, plan.Tag(keyQueryPlan))
+			queries := d.queries()
+			require.Len(t, queries, 2)
+			assert.Equal(t, "EXPLAIN (FORMAT JSON) "+tt.query, queries[1])
+			assert.Equal(t, []bool{true}, d.readOnly)
+		})
+	}
+}
+
+// querySpan returns the only query span finished by mt.
+func querySpan(t *testing.T, mt mocktracer.Tracer) mocktracer.Span {
+	var spans []mocktracer.Span
+	for _, s := range mt.FinishedSpans() {
+		if s.Tag("sql.query_type") == "Query" {
+			spans = append(spans, s)
+		}
+	}
+	require.Len(t, spans, 1)
+	return spans[0]
+}
+
+// planSpan returns the query plan span finished by mt, if any.
+func planSpan(mt mocktracer.Tracer) mocktracer.Span {
+	for _, s := range mt.FinishedSpans() {
+		if s.Tag(keyQueryPlan) != nil {
+			return s
+		}
+	}
+	return nil
+}
+
+// waitPlanSpan waits for the query plan captured in the background to be reported.
+func waitPlanSpan(t *testing.T, mt mocktracer.Tracer) mocktracer.Span {
+	var s mocktracer.Span
+	require.Eventually(t, func() bool {
+		s = planSpan(mt)
+		return s != nil
+	}, time.Second, time.Millisecond)
+	return s
+}
+
+func TestQueryPlanCaptureAsync(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	d := &explainDriver{delay: 10 * time.Millisecond, explain: make(chan struct{})}
+	Register("postgres", d)
+	defer unregister("postgres")
+	db, err := Open("postgres", "", WithQueryPlanCapture(time.Millisecond, 1))
+	require.NoError(t, err)
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	for i := 0; i < 3; i++ {
+		// the queries return while the first EXPLAIN is blocked, instead of waiting
+		// for it until queryPlanTimeout
+		start := time.Now()
+		rows, err := db.QueryContext(ctx, "SELECT * FROM users", 1)
+		require.NoError(t, err)
+		rows.Close()
+		assert.Less(t, int64(time.Since(start)), int64(queryPlanTimeout/2))
+	}
+	// canceling the context of the queries does not cancel the capture
+	cancel()
+	assert.Nil(t, planSpan(mt))
+	close(d.explain)
+
+	waitPlanSpan(t, mt)
+	var explained int
+	for _, q := range d.queries() {
+		if strings.HasPrefix(q, "EXPLAIN") {
+			explained++
+		}
+	}
+	// the other queries were slow while the first plan was being captured
+	assert.Equal(t, 1, explained)
+}
+
+func TestIsReadOnlyQuery(t *testing.T) {
+	for query, want := range map[string]bool{
+		"SELECT 1":                        true,
+		"  select * from users;":          true,
+		"UPDATE users SET name = 'x'":     false,
+		"SELECT 1; DELETE FROM users":     false,
+		"EXPLAIN ANALYZE SELECT 1":        false,
+		"WITH d AS (DELETE FROM users) x": false,
+		"":                                false,
+	} {
+		assert.Equal(t, want, isReadOnlyQuery(query), query)
+	}
+}
@@ -0,0 +1,65 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package internal
+
+import (
+	"database/sql/driver"
+	"reflect"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+)
+
+// driverPackages maps the import paths of known drivers to their database system.
+var driverPackages = map[string]string{
+	"github.com/go-sql-driver/mysql":   ext.DBSystemMySQL,
+	"github.com/lib/pq":                ext.DBSystemPostgreSQL,
+	"github.com/jackc/pgx":             ext.DBSystemPostgreSQL,
+	"github.com/denisenkom/go-mssqldb": ext.DBSystemMSSQL,
+	"github.com/microsoft/go-mssqldb":  ext.DBSystemMSSQL,
+	"github.com/godror/godror":         ext.DBSystemOracle,
+	"github.com/sijms/go-ora":          ext.DBSystemOracle,
+	"github.com/mattn/go-oci8":         ext.DBSystemOracle,
+	"gopkg.in/goracle.v2":              ext.DBSystemOracle,
+	"gopkg.in/rana/ora.v4":             ext.DBSystemOracle,
+}
+
+// driverNames maps the names under which known drivers are commonly registered to
+// their database system.
+var driverNames = map[string]string{
+	"mysql":      ext.DBSystemMySQL,
+	"postgres":   ext.DBSystemPostgreSQL,
+	"postgresql": ext.DBSystemPostgreSQL,
+	"pgx":        ext.DBSystemPostgreSQL,
+	"sqlserver":  ext.DBSystemMSSQL,
+	"mssql":      ext.DBSystemMSSQL,
+	"azuresql":   ext.DBSystemMSSQL,
+	"godror":     ext.DBSystemOracle,
+	"oracle":     ext.DBSystemOracle,
+	"oci8":       ext.DBSystemOracle,
+	"goracle":    ext.DBSystemOracle,
+}
+
+// DBSystem returns the database system of the driver d registered as driverName, e.g.
+// "mssql" for the driver of github.com/denisenkom/go-mssqldb. The system is detected
+// from the package of the driver, falling back to its name. It returns "" if unknown.
+func DBSystem(driverName string, d driver.Driver) string {
+	if d != nil {
+		t := reflect.TypeOf(d)
+		for t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		for pkg := t.PkgPath(); pkg != ""; pkg = pkg[:strings.LastIndex(pkg, "/")] {
+			if system, ok := driverPackages[pkg]; ok {
+				return system
+			}
+			if !strings.Contains(pkg, "/") {
+				break
+			}
+		}
+	}
+	return driverNames[strings.ToLower(driverName)]
+}
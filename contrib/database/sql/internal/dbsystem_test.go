@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package internal
+
+import (
+	"database/sql/driver"
+	"testing"
+
+	mssql "github.com/denisenkom/go-mssqldb"
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v4/stdlib"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+)
+
+func TestDBSystem(t *testing.T) {
+	for _, tt := range []struct {
+		driverName string
+		driver     driver.Driver
+		expected   string
+	}{
+		{"mysql", &mysql.MySQLDriver{}, ext.DBSystemMySQL},
+		{"postgres", &pq.Driver{}, ext.DBSystemPostgreSQL},
+		{"pgx", &stdlib.Driver{}, ext.DBSystemPostgreSQL},
+		{"sqlserver", &mssql.Driver{}, ext.DBSystemMSSQL},
+		// detected from the package of the driver
+		{"my-db", &mssql.Driver{}, ext.DBSystemMSSQL},
+		// detected from the name of the driver
+		{"godror", &MockDriver{}, ext.DBSystemOracle},
+		{"Oracle", nil, ext.DBSystemOracle},
+		{"unknown", &MockDriver{}, ""},
+	} {
+		t.Run(tt.driverName, func(t *testing.T) {
+			assert.Equal(t, tt.expected, DBSystem(tt.driverName, tt.driver))
+		})
+	}
+}
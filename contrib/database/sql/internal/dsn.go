@@ -12,8 +12,9 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 )
 
-// ParseDSN parses various supported DSN types (currently mysql and postgres) into a
-// map of key/value pairs which can be used as valid tags.
+// ParseDSN parses various supported DSN types (currently mysql, postgres, sqlserver and
+// oracle) into a map of key/value pairs which can be used as valid tags. The driverName
+// may also be a database system, as returned by DBSystem. Credentials are left out.
 func ParseDSN(driverName, dsn string) (meta map[string]string, err error) {
 	meta = make(map[string]string)
 	switch driverName {
@@ -22,16 +23,21 @@ func ParseDSN(driverName, dsn string) (meta map[string]string, err error) {
 		if err != nil {
 			return
 		}
-	case "postgres", "pgx":
+	case "postgres", "pgx", ext.DBSystemPostgreSQL:
 		meta, err = parsePostgresDSN(dsn)
 		if err != nil {
 			return
 		}
-	case "sqlserver":
+	case "sqlserver", "azuresql", ext.DBSystemMSSQL:
 		meta, err = parseSQLServerDSN(dsn)
 		if err != nil {
 			return
 		}
+	case "godror", ext.DBSystemOracle:
+		meta, err = parseOracleDSN(dsn)
+		if err != nil {
+			return
+		}
 	default:
 		// not supported
 	}
@@ -47,6 +53,7 @@ func reduceKeys(meta map[string]string) map[string]string {
 		"dbname":           ext.DBName,
 		"host":             ext.TargetHost,
 		"port":             ext.TargetPort,
+		"instanceName":     ext.DBInstance,
 	}
 	m := make(map[string]string)
 	for k, v := range meta {
@@ -103,7 +110,8 @@ func parseSQLServerDSN(dsn string) (map[string]string, error) {
 			return nil, err
 		}
 	} else {
-		meta, err = parseSQLServerADO(dsn)
+		// the odbc form is parsed as an ADO connection string
+		meta, err = parseSQLServerADO(strings.TrimPrefix(dsn, "odbc:"))
 		if err != nil {
 			return nil, err
 		}
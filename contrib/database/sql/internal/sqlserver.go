@@ -94,6 +94,11 @@ func parseSQLServerADO(dsn string) (map[string]string, error) {
 				val = hostParts[0]
 				kvs["port"] = hostParts[1]
 			}
+			if i := strings.Index(val, `\`); i >= 0 {
+				// host\instance
+				kvs["instanceName"] = val[i+1:]
+				val = val[:i]
+			}
 		}
 		kvs[key] = val
 	}
@@ -8,6 +8,7 @@ package sql
 import (
 	"math"
 	"os"
+	"time"
 
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
 	"github.com/codebrick-corp/dd-trace-go/internal"
@@ -19,6 +20,9 @@ type config struct {
 	dsn                  string
 	childSpansOnly       bool
 	commentInjectionMode tracer.SQLCommentInjectionMode
+	queryPlan            *queryPlanConfig
+	traceRows            bool
+	dbSystem             string // database system of the driver, detected in Register
 }
 
 // Option represents an option that can be passed to Register, Open or OpenDB.
@@ -96,3 +100,34 @@ func WithSQLCommentInjection(mode tracer.SQLCommentInjectionMode) Option {
 		cfg.commentInjectionMode = mode
 	}
 }
+
+// WithQueryPlanCapture enables capturing the query plan of slow read queries. For a sample of
+// rate (between 0 and 1) of the queries lasting longer than threshold, an EXPLAIN statement is
+// run in the background on a separate connection, once the query returned, and its output is
+// reported under the "sql.plan" tag of a "<driver>.query.plan" span, child of the query span.
+// At most one plan is captured at a time for each database, the slow queries running meanwhile
+// being skipped. Only plain SELECT statements are explained and they are never executed a second
+// time. It is currently supported for the MySQL and Postgres drivers.
+func WithQueryPlanCapture(threshold time.Duration, rate float64) Option {
+	return func(cfg *config) {
+		if rate <= 0.0 || rate > 1.0 {
+			cfg.queryPlan = nil
+			return
+		}
+		cfg.queryPlan = &queryPlanConfig{
+			threshold: threshold,
+			rate:      rate,
+		}
+	}
+}
+
+// WithRowsIteration enables tracing the iteration over the rows returned by queries. It is
+// reported as a single "<driver>.rows.iterate" span, child of the query span, lasting from
+// the end of the query until the rows are closed and tagged with the number of rows read and
+// the total time spent by the driver fetching them. This separates the latency of the query
+// from the latency of consuming its results.
+func WithRowsIteration() Option {
+	return func(cfg *config) {
+		cfg.traceRows = true
+	}
+}
@@ -0,0 +1,158 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package sql
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+// keyRowsScanDuration holds the total time, in nanoseconds, spent by the driver
+// fetching and decoding the rows of a result set.
+const keyRowsScanDuration = "sql.rows.scan_duration"
+
+var (
+	_ driver.Rows                           = (*tracedRows)(nil)
+	_ driver.RowsNextResultSet              = (*tracedRows)(nil)
+	_ driver.RowsColumnTypeScanType         = (*tracedRows)(nil)
+	_ driver.RowsColumnTypeDatabaseTypeName = (*tracedRows)(nil)
+	_ driver.RowsColumnTypeLength           = (*tracedRows)(nil)
+	_ driver.RowsColumnTypeNullable         = (*tracedRows)(nil)
+	_ driver.RowsColumnTypePrecisionScale   = (*tracedRows)(nil)
+)
+
+// tracedRows is a traced version of driver.Rows. It reports the iteration over the
+// rows as a single span, from the end of the query until the rows are closed.
+type tracedRows struct {
+	driver.Rows
+	*traceParams
+	span  ddtrace.Span
+	count int64
+	scan  time.Duration // total time spent in Next
+	err   error         // first error returned by Next, other than io.EOF
+}
+
+// wrapRows returns rows traced as a child of the given query span when rows
+// iteration tracing is enabled. Otherwise, rows is returned as is.
+func (tp *traceParams) wrapRows(rows driver.Rows, querySpan ddtrace.Span, query string) driver.Rows {
+	if !tp.cfg.traceRows || rows == nil || querySpan == nil {
+		return rows
+	}
+	opts := []ddtrace.StartSpanOption{
+		tracer.ChildOf(querySpan.Context()),
+		tracer.ServiceName(tp.cfg.serviceName),
+		tracer.SpanType(ext.SpanTypeSQL),
+		tracer.ResourceName(query),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.Tag("sql.query_type", queryTypeQuery),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if tp.cfg.dbSystem != "" {
+		opts = append(opts,
+			tracer.Tag(ext.DBType, tp.cfg.dbSystem),
+			tracer.Tag(ext.DBSystem, tp.cfg.dbSystem),
+		)
+	}
+	span := tracer.StartSpan(fmt.Sprintf("%s.rows.iterate", tp.driverName), opts...)
+	for k, v := range tp.meta {
+		span.SetTag(k, v)
+	}
+	return &tracedRows{Rows: rows, traceParams: tp, span: span}
+}
+
+// Next implements driver.Rows.
+func (r *tracedRows) Next(dest []driver.Value) error {
+	start := time.Now()
+	err := r.Rows.Next(dest)
+	r.scan += time.Since(start)
+	switch err {
+	case nil:
+		r.count++
+	case io.EOF:
+	default:
+		if r.err == nil {
+			r.err = err
+		}
+	}
+	return err
+}
+
+// Close implements driver.Rows. It finishes the iteration span.
+func (r *tracedRows) Close() error {
+	err := r.Rows.Close()
+	r.span.SetTag(ext.DBRowCount, r.count)
+	r.span.SetTag(keyRowsScanDuration, r.scan.Nanoseconds())
+	if r.err != nil {
+		r.span.Finish(tracer.WithError(r.err))
+	} else {
+		r.span.Finish(tracer.WithError(err))
+	}
+	return err
+}
+
+// HasNextResultSet implements driver.RowsNextResultSet.
+func (r *tracedRows) HasNextResultSet() bool {
+	if rs, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rs.HasNextResultSet()
+	}
+	return false
+}
+
+// NextResultSet implements driver.RowsNextResultSet.
+func (r *tracedRows) NextResultSet() error {
+	if rs, ok := r.Rows.(driver.RowsNextResultSet); ok {
+		return rs.NextResultSet()
+	}
+	return io.EOF
+}
+
+// ColumnTypeScanType implements driver.RowsColumnTypeScanType.
+func (r *tracedRows) ColumnTypeScanType(index int) reflect.Type {
+	if rs, ok := r.Rows.(driver.RowsColumnTypeScanType); ok {
+		return rs.ColumnTypeScanType(index)
+	}
+	// same default as the database/sql package
+	return reflect.TypeOf(new(interface{})).Elem()
+}
+
+// ColumnTypeDatabaseTypeName implements driver.RowsColumnTypeDatabaseTypeName.
+func (r *tracedRows) ColumnTypeDatabaseTypeName(index int) string {
+	if rs, ok := r.Rows.(driver.RowsColumnTypeDatabaseTypeName); ok {
+		return rs.ColumnTypeDatabaseTypeName(index)
+	}
+	return ""
+}
+
+// ColumnTypeLength implements driver.RowsColumnTypeLength.
+func (r *tracedRows) ColumnTypeLength(index int) (length int64, ok bool) {
+	if rs, ok := r.Rows.(driver.RowsColumnTypeLength); ok {
+		return rs.ColumnTypeLength(index)
+	}
+	return 0, false
+}
+
+// ColumnTypeNullable implements driver.RowsColumnTypeNullable.
+func (r *tracedRows) ColumnTypeNullable(index int) (nullable, ok bool) {
+	if rs, ok := r.Rows.(driver.RowsColumnTypeNullable); ok {
+		return rs.ColumnTypeNullable(index)
+	}
+	return false, false
+}
+
+// ColumnTypePrecisionScale implements driver.RowsColumnTypePrecisionScale.
+func (r *tracedRows) ColumnTypePrecisionScale(index int) (precision, scale int64, ok bool) {
+	if rs, ok := r.Rows.(driver.RowsColumnTypePrecisionScale); ok {
+		return rs.ColumnTypePrecisionScale(index)
+	}
+	return 0, 0, false
+}
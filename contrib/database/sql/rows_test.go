@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package sql
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+)
+
+func TestRowsIteration(t *testing.T) {
+	const query = "EXPLAIN SELECT 1"
+
+	t.Run("enabled", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		Register("postgres", &explainDriver{})
+		defer unregister("postgres")
+		db, err := Open("postgres", "", WithServiceName("pg"), WithRowsIteration())
+		require.NoError(t, err)
+		defer db.Close()
+
+		rows, err := db.QueryContext(context.Background(), query)
+		require.NoError(t, err)
+		types, err := rows.ColumnTypes()
+		require.NoError(t, err)
+		assert.Len(t, types, 1)
+		var n int
+		for rows.Next() {
+			var plan string
+			require.NoError(t, rows.Scan(&plan))
+			n++
+		}
+		require.NoError(t, rows.Err())
+		require.NoError(t, rows.Close())
+		assert.Equal(t, 1, n)
+
+		var querySpan, rowsSpan mocktracer.Span
+		for _, s := range mt.FinishedSpans() {
+			switch s.OperationName() {
+			case "postgres.query":
+				if s.Tag("sql.query_type") == "Query" {
+					querySpan = s
+				}
+			case "postgres.rows.iterate":
+				rowsSpan = s
+			}
+		}
+		require.NotNil(t, querySpan)
+		require.NotNil(t, rowsSpan)
+		assert.Equal(t, querySpan.SpanID(), rowsSpan.ParentID())
+		assert.Equal(t, query, rowsSpan.Tag(ext.ResourceName))
+		assert.Equal(t, "pg", rowsSpan.Tag(ext.ServiceName))
+		assert.Equal(t, ext.DBSystemPostgreSQL, rowsSpan.Tag(ext.DBSystem))
+		assert.EqualValues(t, 1, rowsSpan.Tag(ext.DBRowCount))
+		assert.NotNil(t, rowsSpan.Tag(keyRowsScanDuration))
+		assert.False(t, rowsSpan.StartTime().Before(querySpan.FinishTime()))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		Register("postgres", &explainDriver{})
+		defer unregister("postgres")
+		db, err := Open("postgres", "")
+		require.NoError(t, err)
+		defer db.Close()
+
+		rows, err := db.QueryContext(context.Background(), query)
+		require.NoError(t, err)
+		for rows.Next() {
+		}
+		require.NoError(t, rows.Close())
+
+		for _, s := range mt.FinishedSpans() {
+			assert.NotEqual(t, "postgres.rows.iterate", s.OperationName())
+		}
+	})
+}
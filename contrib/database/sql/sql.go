@@ -22,14 +22,23 @@ import (
 	"database/sql/driver"
 	"errors"
 	"math"
+	"path"
 	"reflect"
+	"sync"
 	"time"
 
 	"github.com/codebrick-corp/dd-trace-go/contrib/database/sql/internal"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 )
 
+const componentName = "database/sql"
+
+func init() {
+	integrations.Register(componentName, "database/sql")
+}
+
 // registeredDrivers holds a registry of all drivers registered via the sqltrace package.
 var registeredDrivers = &driverRegistry{
 	keys:    make(map[reflect.Type]string),
@@ -38,6 +47,9 @@ var registeredDrivers = &driverRegistry{
 }
 
 type driverRegistry struct {
+	// mu guards below fields, drivers being registered by Open and OpenDB
+	// when AutoRegister was called.
+	mu sync.RWMutex
 	// keys maps driver types to their registered names.
 	keys map[reflect.Type]string
 	// drivers maps keys to their registered driver.
@@ -49,13 +61,17 @@ type driverRegistry struct {
 // isRegistered reports whether the name matches an existing entry
 // in the driver registry.
 func (d *driverRegistry) isRegistered(name string) bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
 	_, ok := d.configs[name]
 	return ok
 }
 
 // add adds the driver with the given name and config to the registry.
 func (d *driverRegistry) add(name string, driver driver.Driver, cfg *config) {
-	if d.isRegistered(name) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.configs[name]; ok {
 		return
 	}
 	d.keys[reflect.TypeOf(driver)] = name
@@ -65,24 +81,32 @@ func (d *driverRegistry) add(name string, driver driver.Driver, cfg *config) {
 
 // name returns the name of the driver stored in the registry.
 func (d *driverRegistry) name(driver driver.Driver) (string, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
 	name, ok := d.keys[reflect.TypeOf(driver)]
 	return name, ok
 }
 
 // driver returns the driver stored in the registry with the provided name.
 func (d *driverRegistry) driver(name string) (driver.Driver, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
 	driver, ok := d.drivers[name]
 	return driver, ok
 }
 
 // config returns the config stored in the registry with the provided name.
 func (d *driverRegistry) config(name string) (*config, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
 	config, ok := d.configs[name]
 	return config, ok
 }
 
 // unregister is used to make tests idempotent.
 func (d *driverRegistry) unregister(name string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
 	driver := d.drivers[name]
 	delete(d.keys, reflect.TypeOf(driver))
 	delete(d.configs, name)
@@ -91,7 +115,8 @@ func (d *driverRegistry) unregister(name string) {
 
 // Register tells the sql integration package about the driver that we will be tracing. It must
 // be called before Open, if that connection is to be traced. It uses the driverName suffixed
-// with ".db" as the default service name.
+// with ".db" as the default service name. The database system of the driver (e.g. "mssql" or
+// "oracle") is detected from its package or name, to tag the spans and parse the DSN.
 func Register(driverName string, driver driver.Driver, opts ...RegisterOption) {
 	if driver == nil {
 		panic("sqltrace: Register driver is nil")
@@ -109,10 +134,49 @@ func Register(driverName string, driver driver.Driver, opts ...RegisterOption) {
 	if cfg.serviceName == "" {
 		cfg.serviceName = driverName + ".db"
 	}
+	cfg.dbSystem = internal.DBSystem(driverName, driver)
 	log.Debug("contrib/database/sql: Registering driver: %s %#v", driverName, cfg)
 	registeredDrivers.add(driverName, driver, cfg)
 }
 
+// autoRegistration holds the options of the drivers registered by Open and OpenDB.
+var autoRegistration struct {
+	sync.Mutex
+	enabled bool
+	opts    []RegisterOption
+}
+
+// AutoRegister makes Open and OpenDB register the drivers which weren't registered with
+// Register on their first use, with the given options, rather than failing. The drivers
+// opened by name with Open are looked up among the drivers registered with database/sql,
+// while the drivers of the connectors given to OpenDB are named after their package.
+// Importing the autoload/sql package calls AutoRegister with no options.
+func AutoRegister(opts ...RegisterOption) {
+	autoRegistration.Lock()
+	defer autoRegistration.Unlock()
+	autoRegistration.enabled = true
+	autoRegistration.opts = opts
+}
+
+// autoRegisterOptions returns the options given to AutoRegister, reporting whether it was called.
+func autoRegisterOptions() ([]RegisterOption, bool) {
+	autoRegistration.Lock()
+	defer autoRegistration.Unlock()
+	return autoRegistration.opts, autoRegistration.enabled
+}
+
+// driverPackageName returns the name of the package of driver, e.g. "pq" for *pq.Driver.
+func driverPackageName(driver driver.Driver) string {
+	t := reflect.TypeOf(driver)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if name := path.Base(t.PkgPath()); name != "." && name != "/" {
+		return name
+	}
+	return t.Name()
+}
+
 // unregister is used to make tests idempotent.
 func unregister(name string) {
 	if registeredDrivers.isRegistered(name) {
@@ -128,17 +192,23 @@ type tracedConnector struct {
 	connector  driver.Connector
 	driverName string
 	cfg        *config
+	plans      *queryPlanCapturer // nil unless query plans are captured
 }
 
 func (t *tracedConnector) Connect(ctx context.Context) (driver.Conn, error) {
 	tp := &traceParams{
 		driverName: t.driverName,
 		cfg:        t.cfg,
+		plans:      t.plans,
+	}
+	kind := t.driverName
+	if t.cfg.dbSystem != "" {
+		kind = t.cfg.dbSystem
 	}
 	if dc, ok := t.connector.(*dsnConnector); ok {
-		tp.meta, _ = internal.ParseDSN(t.driverName, dc.dsn)
+		tp.meta, _ = internal.ParseDSN(kind, dc.dsn)
 	} else if t.cfg.dsn != "" {
-		tp.meta, _ = internal.ParseDSN(t.driverName, t.cfg.dsn)
+		tp.meta, _ = internal.ParseDSN(kind, t.cfg.dsn)
 	}
 	start := time.Now()
 	conn, err := t.connector.Connect(ctx)
@@ -168,11 +238,19 @@ func (t dsnConnector) Driver() driver.Driver {
 }
 
 // OpenDB returns connection to a DB using the traced version of the given driver. In order for OpenDB
-// to work, the driver must first be registered using Register. If this did not occur, OpenDB will panic.
+// to work, the driver must first be registered using Register, or AutoRegister must have been called.
+// If this did not occur, OpenDB will panic.
 func OpenDB(c driver.Connector, opts ...Option) *sql.DB {
 	name, ok := registeredDrivers.name(c.Driver())
 	if !ok {
-		panic("sqltrace.OpenDB: driver is not registered via sqltrace.Register")
+		ropts, auto := autoRegisterOptions()
+		if !auto {
+			panic("sqltrace.OpenDB: driver is not registered via sqltrace.Register")
+		}
+		Register(driverPackageName(c.Driver()), c.Driver(), ropts...)
+		if name, ok = registeredDrivers.name(c.Driver()); !ok {
+			panic("sqltrace.OpenDB: a different driver is registered under the name " + driverPackageName(c.Driver()))
+		}
 	}
 	rc, _ := registeredDrivers.config(name)
 	cfg := new(config)
@@ -190,21 +268,47 @@ func OpenDB(c driver.Connector, opts ...Option) *sql.DB {
 	if cfg.commentInjectionMode == tracer.SQLInjectionUndefined {
 		cfg.commentInjectionMode = rc.commentInjectionMode
 	}
+	if cfg.queryPlan == nil {
+		cfg.queryPlan = rc.queryPlan
+	}
 	cfg.childSpansOnly = rc.childSpansOnly
+	cfg.dbSystem = rc.dbSystem
 	tc := &tracedConnector{
 		connector:  c,
 		driverName: name,
 		cfg:        cfg,
 	}
+	if cfg.queryPlan != nil {
+		tc.plans = &queryPlanCapturer{connector: c, driverName: name, cfg: cfg}
+	}
 	return sql.OpenDB(tc)
 }
 
+// stdlibDriver returns the driver registered with database/sql under the given name. It returns
+// errNotRegistered when there is none.
+func stdlibDriver(name, dsn string) (driver.Driver, error) {
+	db, err := sql.Open(name, dsn)
+	if err != nil {
+		return nil, errNotRegistered
+	}
+	defer db.Close()
+	return db.Driver(), nil
+}
+
 // Open returns connection to a DB using the traced version of the given driver. In order for Open
-// to work, the driver must first be registered using Register. If this did not occur, Open will
-// return an error.
+// to work, the driver must first be registered using Register, or AutoRegister must have been
+// called. If this did not occur, Open will return an error.
 func Open(driverName, dataSourceName string, opts ...Option) (*sql.DB, error) {
 	if !registeredDrivers.isRegistered(driverName) {
-		return nil, errNotRegistered
+		ropts, auto := autoRegisterOptions()
+		if !auto {
+			return nil, errNotRegistered
+		}
+		d, err := stdlibDriver(driverName, dataSourceName)
+		if err != nil {
+			return nil, err
+		}
+		Register(driverName, d, ropts...)
 	}
 	d, _ := registeredDrivers.driver(driverName)
 	return OpenDB(&dsnConnector{dsn: dataSourceName, driver: d}, opts...), nil
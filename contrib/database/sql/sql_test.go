@@ -57,6 +57,7 @@ func TestSqlServer(t *testing.T) {
 			ext.SpanType:        ext.SpanTypeSQL,
 			ext.TargetHost:      "127.0.0.1",
 			ext.TargetPort:      "1433",
+			ext.DBSystem:        ext.DBSystemMSSQL,
 			ext.DBUser:          "sa",
 			ext.DBName:          "master",
 			ext.EventSampleRate: nil,
@@ -83,6 +84,7 @@ func TestMySQL(t *testing.T) {
 			ext.SpanType:        ext.SpanTypeSQL,
 			ext.TargetHost:      "127.0.0.1",
 			ext.TargetPort:      "3306",
+			ext.DBSystem:        ext.DBSystemMySQL,
 			ext.DBUser:          "test",
 			ext.DBName:          "test",
 			ext.EventSampleRate: nil,
@@ -109,6 +111,7 @@ func TestPostgres(t *testing.T) {
 			ext.SpanType:        ext.SpanTypeSQL,
 			ext.TargetHost:      "127.0.0.1",
 			ext.TargetPort:      "5432",
+			ext.DBSystem:        ext.DBSystemPostgreSQL,
 			ext.DBUser:          "postgres",
 			ext.DBName:          "postgres",
 			ext.EventSampleRate: 0.2,
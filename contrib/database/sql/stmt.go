@@ -57,8 +57,8 @@ func (s *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue)
 	start := time.Now()
 	if stmtQueryContext, ok := s.Stmt.(driver.StmtQueryContext); ok {
 		rows, err := stmtQueryContext.QueryContext(ctx, args)
-		s.tryTrace(ctx, queryTypeQuery, s.query, start, err)
-		return rows, err
+		span := s.tryTraceQuery(ctx, s.query, args, start, err)
+		return s.wrapRows(rows, span, s.query), err
 	}
 	dargs, err := namedValueToValue(args)
 	if err != nil {
@@ -70,8 +70,8 @@ func (s *tracedStmt) QueryContext(ctx context.Context, args []driver.NamedValue)
 	default:
 	}
 	rows, err = s.Query(dargs)
-	s.tryTrace(ctx, queryTypeQuery, s.query, start, err)
-	return rows, err
+	span := s.tryTraceQuery(ctx, s.query, args, start, err)
+	return s.wrapRows(rows, span, s.query), err
 }
 
 // copied from stdlib database/sql package: src/database/sql/ctxutil.go
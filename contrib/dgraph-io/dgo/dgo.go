@@ -0,0 +1,143 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package dgo provides functions to trace the requests sent to Dgraph by the
+// github.com/dgraph-io/dgo/v210 client. Dgraph clients talk to the database over
+// gRPC, so the requests are traced by installing a gRPC interceptor on the connection
+// given to the client:
+//
+//	conn, err := grpc.Dial("localhost:9080", grpc.WithInsecure(),
+//		grpc.WithUnaryInterceptor(dgotrace.UnaryClientInterceptor()))
+//	client := dgo.NewDgraphClient(api.NewDgraphClient(conn))
+package dgo // import "github.com/codebrick-corp/dd-trace-go/contrib/dgraph-io/dgo"
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/graphquery"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/dgraph-io/dgo/v210/protos/api"
+	"google.golang.org/grpc"
+)
+
+const componentName = "dgraph-io/dgo"
+
+func init() {
+	integrations.Register(componentName, "github.com/dgraph-io/dgo/v210")
+}
+
+const (
+	// servicePrefix prefixes the full names of the methods of the Dgraph service.
+	servicePrefix = "/api.Dgraph/"
+
+	// tagQuery holds the obfuscated DQL query of a request.
+	tagQuery = "dgraph.query"
+	// tagMutations holds the number of mutations of a request.
+	tagMutations = "dgraph.mutations"
+	// tagReadOnly is set when a query runs in a read-only transaction.
+	tagReadOnly = "dgraph.read_only"
+	// tagCommitNow is set when the transaction of a request is committed along with it.
+	tagCommitNow = "dgraph.commit_now"
+	// tagStartTs holds the start timestamp of the transaction of a request.
+	tagStartTs = "dgraph.start_ts"
+	// tagAborted is set when a transaction is aborted.
+	tagAborted = "dgraph.aborted"
+)
+
+// UnaryClientInterceptor returns a grpc.UnaryClientInterceptor which traces the requests
+// sent to Dgraph over the connection, leaving the calls to other services untouched. The
+// queries are obfuscated and the contents of the mutations and the variables are left out.
+func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/dgraph-io/dgo: Configuring UnaryClientInterceptor: %#v", cfg)
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if !strings.HasPrefix(method, servicePrefix) {
+			return invoker(ctx, method, req, reply, cc, opts...)
+		}
+		span := startSpan(ctx, cfg, strings.TrimPrefix(method, servicePrefix), req, cc)
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		switch reply := reply.(type) {
+		case *api.Response:
+			tagTxn(span, reply.Txn)
+		case *api.TxnContext:
+			tagTxn(span, reply)
+		}
+		span.Finish(tracer.WithError(err))
+		return err
+	}
+}
+
+// startSpan starts the span of the request req to the Dgraph method name.
+func startSpan(ctx context.Context, cfg *config, name string, req interface{}, cc *grpc.ClientConn) ddtrace.Span {
+	opts := []ddtrace.StartSpanOption{
+		tracer.SpanType(ext.SpanTypeDgraph),
+		tracer.ServiceName(cfg.serviceName),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.Tag(ext.DBSystem, ext.DBSystemDgraph),
+		tracer.ResourceName(name),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if cc != nil {
+		if host, port, err := net.SplitHostPort(cc.Target()); err == nil {
+			opts = append(opts, tracer.Tag(ext.TargetHost, host), tracer.Tag(ext.TargetPort, port))
+		}
+	}
+	if req, ok := req.(*api.Request); ok {
+		opts = append(opts, queryTags(req)...)
+	}
+	span, _ := tracer.StartSpanFromContext(ctx, operationName, opts...)
+	return span
+}
+
+// queryTags returns the options tagging the span of the request req. The requests holding
+// mutations without a query are named "Mutate", and those holding both are named "Upsert".
+func queryTags(req *api.Request) []ddtrace.StartSpanOption {
+	var opts []ddtrace.StartSpanOption
+	if req.Query != "" {
+		opts = append(opts, tracer.Tag(tagQuery, graphquery.Obfuscate(req.Query)))
+	}
+	if n := len(req.Mutations); n > 0 {
+		opts = append(opts, tracer.Tag(tagMutations, n))
+		if req.Query != "" {
+			opts = append(opts, tracer.ResourceName("Upsert"))
+		} else {
+			opts = append(opts, tracer.ResourceName("Mutate"))
+		}
+	}
+	if req.ReadOnly {
+		opts = append(opts, tracer.Tag(tagReadOnly, true))
+	}
+	if req.CommitNow {
+		opts = append(opts, tracer.Tag(tagCommitNow, true))
+	}
+	if req.StartTs != 0 {
+		opts = append(opts, tracer.Tag(tagStartTs, req.StartTs))
+	}
+	return opts
+}
+
+// tagTxn tags span with the transaction context txn returned by Dgraph, if any.
+func tagTxn(span ddtrace.Span, txn *api.TxnContext) {
+	if txn == nil {
+		return
+	}
+	if txn.StartTs != 0 {
+		span.SetTag(tagStartTs, txn.StartTs)
+	}
+	if txn.Aborted {
+		span.SetTag(tagAborted, true)
+	}
+}
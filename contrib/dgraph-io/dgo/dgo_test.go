@@ -0,0 +1,222 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package dgo
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+
+	"github.com/dgraph-io/dgo/v210"
+	"github.com/dgraph-io/dgo/v210/protos/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// server is a Dgraph server answering the requests of the tests.
+type server struct {
+	api.UnimplementedDgraphServer
+	startTs uint64
+}
+
+func (s *server) Login(context.Context, *api.LoginRequest) (*api.Response, error) {
+	return nil, status.Error(codes.Unauthenticated, "invalid password")
+}
+
+func (s *server) Query(context.Context, *api.Request) (*api.Response, error) {
+	return &api.Response{Json: []byte(`{}`), Txn: &api.TxnContext{StartTs: s.startTs, Keys: []string{"k"}}}, nil
+}
+
+func (s *server) CommitOrAbort(_ context.Context, txn *api.TxnContext) (*api.TxnContext, error) {
+	return txn, nil
+}
+
+func (s *server) CheckVersion(context.Context, *api.Check) (*api.Version, error) {
+	return &api.Version{Tag: "v21.03.0"}, nil
+}
+
+// dial returns a connection to a Dgraph server answering with the given start timestamp,
+// traced by the interceptor built with opts. The server also serves the health service.
+func dial(t *testing.T, startTs uint64, opts ...Option) *grpc.ClientConn {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	srv := grpc.NewServer()
+	api.RegisterDgraphServer(srv, &server{startTs: startTs})
+	healthpb.RegisterHealthServer(srv, health.NewServer())
+	go srv.Serve(l)
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.Dial(l.Addr().String(), grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(UnaryClientInterceptor(opts...)))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func TestQuery(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	conn := dial(t, 42)
+	client := dgo.NewDgraphClient(api.NewDgraphClient(conn))
+	root, ctx := tracer.StartSpanFromContext(context.Background(), "web.request")
+	_, err := client.NewReadOnlyTxn().QueryWithVars(ctx,
+		`query q($name: string) { q(func: eq(name, $name), first: 10) { uid } }`,
+		map[string]string{"$name": "Alice"})
+	root.Finish()
+	require.NoError(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	s := spans[0]
+	host, port, _ := net.SplitHostPort(conn.Target())
+	assert.Equal("dgraph.request", s.OperationName())
+	assert.Equal(root.Context().SpanID(), s.ParentID())
+	assert.Equal("Query", s.Tag(ext.ResourceName))
+	assert.Equal("dgraph", s.Tag(ext.ServiceName))
+	assert.Equal(ext.SpanTypeDgraph, s.Tag(ext.SpanType))
+	assert.Equal(ext.SpanKindClient, s.Tag(ext.SpanKind))
+	assert.Equal(ext.DBSystemDgraph, s.Tag(ext.DBSystem))
+	assert.Equal(componentName, s.Tag(ext.Component))
+	assert.Equal(host, s.Tag(ext.TargetHost))
+	assert.Equal(port, s.Tag(ext.TargetPort))
+	assert.Equal(`query q($name: string) { q(func: eq(name, $name), first: ?) { uid } }`, s.Tag(tagQuery))
+	assert.Equal(true, s.Tag(tagReadOnly))
+	assert.Equal(uint64(42), s.Tag(tagStartTs))
+	assert.Nil(s.Tag(tagMutations))
+	assert.Nil(s.Tag(tagCommitNow))
+	for k, v := range s.Tags() {
+		assert.NotContains(fmt.Sprint(v), "Alice", k)
+	}
+}
+
+func TestMutate(t *testing.T) {
+	for name, tt := range map[string]struct {
+		query    string
+		resource string
+	}{
+		"mutate": {"", "Mutate"},
+		"upsert": {`{ u as var(func: eq(email, "a@b.c")) }`, "Upsert"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			client := dgo.NewDgraphClient(api.NewDgraphClient(dial(t, 7)))
+			_, err := client.NewTxn().Do(context.Background(), &api.Request{
+				Query:     tt.query,
+				Mutations: []*api.Mutation{{SetJson: []byte(`{"name":"Alice"}`)}},
+				CommitNow: true,
+			})
+			require.NoError(t, err)
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			s := spans[0]
+			assert.Equal(tt.resource, s.Tag(ext.ResourceName))
+			assert.Equal(1, s.Tag(tagMutations))
+			assert.Equal(true, s.Tag(tagCommitNow))
+			assert.Equal(uint64(7), s.Tag(tagStartTs))
+			assert.Nil(s.Tag(tagAborted))
+			for k, v := range s.Tags() {
+				assert.NotContains(fmt.Sprint(v), "Alice", k)
+				assert.NotContains(fmt.Sprint(v), "a@b.c", k)
+			}
+		})
+	}
+}
+
+func TestDiscard(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	client := dgo.NewDgraphClient(api.NewDgraphClient(dial(t, 7)))
+	txn := client.NewTxn()
+	_, err := txn.Mutate(context.Background(), &api.Mutation{SetJson: []byte(`{"name":"Alice"}`)})
+	require.NoError(t, err)
+	require.NoError(t, txn.Discard(context.Background()))
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	s := spans[1]
+	assert.Equal("CommitOrAbort", s.Tag(ext.ResourceName))
+	assert.Equal(true, s.Tag(tagAborted))
+	assert.Equal(uint64(7), s.Tag(tagStartTs))
+}
+
+func TestLogin(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	client := dgo.NewDgraphClient(api.NewDgraphClient(dial(t, 0)))
+	err := client.Login(context.Background(), "groot", "secret")
+	require.Error(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal(t, "Login", s.Tag(ext.ResourceName))
+	assert.Equal(t, codes.Unauthenticated, status.Code(s.Tag(ext.Error).(error)))
+	for k, v := range s.Tags() {
+		assert.NotContains(t, fmt.Sprint(v), "secret", k)
+	}
+}
+
+func TestOtherServices(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	_, err := healthpb.NewHealthClient(dial(t, 0)).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	assert.Empty(t, mt.FinishedSpans())
+}
+
+// checkVersion sends a CheckVersion request over a connection traced with opts.
+func checkVersion(t *testing.T, opts ...Option) {
+	_, err := api.NewDgraphClient(dial(t, 0, opts...)).CheckVersion(context.Background(), &api.Check{})
+	require.NoError(t, err)
+}
+
+func TestServiceName(t *testing.T) {
+	t.Run("global", func(t *testing.T) {
+		globalconfig.SetServiceName("global-service")
+		defer globalconfig.SetServiceName("")
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		checkVersion(t)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "global-service", spans[0].Tag(ext.ServiceName))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		checkVersion(t, WithServiceName("my-dgraph"))
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "my-dgraph", spans[0].Tag(ext.ServiceName))
+	})
+}
@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package dgo_test
+
+import (
+	"context"
+	"log"
+
+	dgotrace "github.com/codebrick-corp/dd-trace-go/contrib/dgraph-io/dgo"
+
+	"github.com/dgraph-io/dgo/v210"
+	"github.com/dgraph-io/dgo/v210/protos/api"
+	"google.golang.org/grpc"
+)
+
+func Example() {
+	// Install the interceptor on the connection given to the Dgraph client.
+	conn, err := grpc.Dial("localhost:9080",
+		grpc.WithInsecure(),
+		grpc.WithUnaryInterceptor(dgotrace.UnaryClientInterceptor(dgotrace.WithServiceName("my-dgraph"))),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer conn.Close()
+
+	// The requests of the client are now traced.
+	client := dgo.NewDgraphClient(api.NewDgraphClient(conn))
+	resp, err := client.NewReadOnlyTxn().Query(context.Background(), `{ q(func: has(name)) { name } }`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println(string(resp.Json))
+}
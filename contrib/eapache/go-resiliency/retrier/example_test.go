@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package retrier_test
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	retriertrace "github.com/codebrick-corp/dd-trace-go/contrib/eapache/go-resiliency/retrier"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/eapache/go-resiliency/retrier"
+)
+
+func Example() {
+	r := retriertrace.WrapRetrier(
+		retrier.New(retrier.ExponentialBackoff(3, 100*time.Millisecond), nil),
+		retriertrace.WithResourceName("fetch-config"),
+	)
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "web.request")
+	defer span.Finish()
+
+	// The action is traced as a child of the span in ctx, with its attempts and backoff.
+	_ = r.RunCtx(ctx, func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://config.local/", nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		return resp.Body.Close()
+	})
+}
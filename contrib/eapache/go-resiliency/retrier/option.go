@@ -0,0 +1,34 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package retrier
+
+type config struct {
+	serviceName  string
+	resourceName string
+}
+
+// Option represents an option that can be passed to WrapRetrier.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	// by default, the spans inherit the service name of their parent
+	cfg.resourceName = "retrier.Run"
+}
+
+// WithServiceName sets the given service name for the spans of the Retrier.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithResourceName sets the resource name of the spans of the Retrier, e.g. the name
+// of the retried action.
+func WithResourceName(name string) Option {
+	return func(cfg *config) {
+		cfg.resourceName = name
+	}
+}
@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package retrier provides functions to trace the eapache/go-resiliency/retrier package
+// (https://github.com/eapache/go-resiliency).
+//
+// The actions run by a wrapped Retrier are traced with a span recording the number of
+// attempts made and the total time spent backing off between them.
+package retrier // import "github.com/codebrick-corp/dd-trace-go/contrib/eapache/go-resiliency/retrier"
+
+import (
+	"context"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/retry"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/eapache/go-resiliency/retrier"
+)
+
+const componentName = "eapache/go-resiliency/retrier"
+
+func init() {
+	integrations.Register(componentName, "github.com/eapache/go-resiliency")
+}
+
+// Retrier is a retrier.Retrier tracing the actions it runs.
+type Retrier struct {
+	*retrier.Retrier
+	cfg *config
+}
+
+// WrapRetrier wraps r so that the actions it runs are traced.
+func WrapRetrier(r *retrier.Retrier, opts ...Option) *Retrier {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/eapache/go-resiliency/retrier: Wrapping Retrier: %#v", cfg)
+	return &Retrier{Retrier: r, cfg: cfg}
+}
+
+// Run invokes and traces Retrier.Run.
+func (r *Retrier) Run(work func() error) error {
+	return r.RunCtx(context.Background(), func(context.Context) error { return work() })
+}
+
+// RunCtx runs work as Retrier.Run does, tracing it as a child of the span in ctx. work
+// receives the context of the span, which records its attempts and backoff.
+func (r *Retrier) RunCtx(ctx context.Context, work func(ctx context.Context) error) error {
+	opts := []ddtrace.StartSpanOption{
+		tracer.ResourceName(r.cfg.resourceName),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if r.cfg.serviceName != "" {
+		opts = append(opts, tracer.ServiceName(r.cfg.serviceName))
+	}
+	span, ctx := tracer.StartSpanFromContext(ctx, retry.OperationName, opts...)
+	rec := retry.NewRecorder(span)
+	err := r.Retrier.Run(func() error {
+		rec.StartAttempt()
+		defer rec.EndAttempt()
+		return work(ctx)
+	})
+	span.Finish(tracer.WithError(err))
+	return err
+}
@@ -0,0 +1,91 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package retrier
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/eapache/go-resiliency/retrier"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/retry"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+var errTransient = errors.New("transient")
+
+func TestRunCtx(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	r := WrapRetrier(retrier.New(retrier.ConstantBackoff(3, time.Millisecond), nil),
+		WithResourceName("charge"), WithServiceName("payments"))
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "http.request")
+	var calls int
+	err := r.RunCtx(ctx, func(ctx context.Context) error {
+		calls++
+		child, _ := tracer.StartSpanFromContext(ctx, "payments.charge")
+		child.Finish()
+		if calls < 3 {
+			return errTransient
+		}
+		return nil
+	})
+	parent.Finish()
+	require.NoError(t, err)
+	assert.Equal(3, calls)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 5)
+	s := spans[3]
+	assert.Equal(retry.OperationName, s.OperationName())
+	assert.Equal(parent.Context().SpanID(), s.ParentID())
+	assert.Equal(s.SpanID(), spans[0].ParentID())
+	assert.Equal("charge", s.Tag(ext.ResourceName))
+	assert.Equal("payments", s.Tag(ext.ServiceName))
+	assert.Equal(3, s.Tag(retry.TagAttempts))
+	assert.GreaterOrEqual(s.Tag(retry.TagBackoff).(int64), int64(2*time.Millisecond))
+	assert.Nil(s.Tag(ext.Error))
+}
+
+func TestRun(t *testing.T) {
+	t.Run("exhausted", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		r := WrapRetrier(retrier.New(retrier.ConstantBackoff(1, time.Millisecond), nil))
+		err := r.Run(func() error { return errTransient })
+		assert.Equal(t, errTransient, err)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "retrier.Run", spans[0].Tag(ext.ResourceName))
+		assert.Equal(t, 2, spans[0].Tag(retry.TagAttempts))
+		assert.Equal(t, errTransient, spans[0].Tag(ext.Error))
+	})
+
+	t.Run("fail-fast", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		errFatal := errors.New("fatal")
+		r := WrapRetrier(retrier.New(retrier.ConstantBackoff(3, time.Millisecond), retrier.WhitelistClassifier{errTransient}))
+		err := r.Run(func() error { return errFatal })
+		assert.Equal(t, errFatal, err)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, 1, spans[0].Tag(retry.TagAttempts))
+		assert.Nil(t, spans[0].Tag(retry.TagBackoff))
+	})
+}
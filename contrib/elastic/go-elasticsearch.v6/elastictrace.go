@@ -22,8 +22,15 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 )
 
+const componentName = "elastic/go-elasticsearch.v6"
+
+func init() {
+	integrations.Register(componentName, "github.com/elastic/go-elasticsearch")
+}
+
 // NewRoundTripper returns a new http.Client which traces requests under the given service name.
 func NewRoundTripper(opts ...ClientOption) http.RoundTripper {
 	cfg := new(clientConfig)
@@ -54,10 +61,12 @@ func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	opts := []ddtrace.StartSpanOption{
 		tracer.ServiceName(t.config.serviceName),
 		tracer.SpanType(ext.SpanTypeElasticSearch),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ResourceName(resource),
 		tracer.Tag("elasticsearch.method", method),
 		tracer.Tag("elasticsearch.url", url),
 		tracer.Tag("elasticsearch.params", req.URL.Query().Encode()),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(t.config.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, t.config.analyticsRate))
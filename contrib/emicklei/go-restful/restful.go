@@ -13,11 +13,18 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/emicklei/go-restful"
 )
 
+const componentName = "emicklei/go-restful"
+
+func init() {
+	integrations.Register(componentName, "github.com/emicklei/go-restful")
+}
+
 // FilterFunc returns a restful.FilterFunction which will automatically trace incoming request.
 func FilterFunc(configOpts ...Option) restful.FilterFunction {
 	cfg := newConfig()
@@ -25,15 +32,16 @@ func FilterFunc(configOpts ...Option) restful.FilterFunction {
 		opt(cfg)
 	}
 	log.Debug("contrib/emicklei/go-restful: Creating tracing filter: %#v", cfg)
-	spanOpts := []ddtrace.StartSpanOption{tracer.ServiceName(cfg.serviceName)}
+	spanOpts := []ddtrace.StartSpanOption{tracer.ServiceName(cfg.serviceName), tracer.Tag(ext.Component, componentName)}
+	mw := httptrace.NewMiddleware()
 	return func(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
 		spanOpts := append(spanOpts, tracer.ResourceName(req.SelectedRoutePath()))
 		if !math.IsNaN(cfg.analyticsRate) {
 			spanOpts = append(spanOpts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
 		}
-		span, ctx := httptrace.StartRequestSpan(req.Request, spanOpts...)
+		span, ctx := mw.StartRequestSpan(req.Request, spanOpts...)
 		defer func() {
-			httptrace.FinishRequestSpan(span, resp.StatusCode(), tracer.WithError(resp.Error()))
+			httptrace.FinishRequestSpan(span, resp.StatusCode(), &httptrace.Response{Header: resp.Header()}, tracer.WithError(resp.Error()))
 		}()
 
 		// pass the span through the request context
@@ -44,9 +52,10 @@ func FilterFunc(configOpts ...Option) restful.FilterFunction {
 
 // Filter is deprecated. Please use FilterFunc.
 func Filter(req *restful.Request, resp *restful.Response, chain *restful.FilterChain) {
-	span, ctx := httptrace.StartRequestSpan(req.Request, tracer.ResourceName(req.SelectedRoutePath()))
+	span, ctx := httptrace.StartRequestSpan(req.Request, tracer.ResourceName(req.SelectedRoutePath()),
+		tracer.Tag(ext.Component, componentName))
 	defer func() {
-		httptrace.FinishRequestSpan(span, resp.StatusCode(), tracer.WithError(resp.Error()))
+		httptrace.FinishRequestSpan(span, resp.StatusCode(), &httptrace.Response{Header: resp.Header()}, tracer.WithError(resp.Error()))
 	}()
 
 	// pass the span through the request context
@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package ent provides functions to trace the operations of the query builders generated
+// by entgo.io/ent (https://entgo.io). Each operation of a builder results in a span having
+// the entity type and the operation as resource, e.g. "User.Create", along with the number
+// of predicates of the operation, while the SQL statements it runs are traced as its children
+// when the underlying *sql.DB is opened with the contrib/database/sql package.
+//
+// The mutations are traced by the hook returned by Hook, and the queries by the interceptor
+// returned by Interceptor, both registered on the generated client:
+//
+//	client.Use(enttrace.Hook())
+//	client.Intercept(enttrace.Interceptor())
+//
+// The interceptors are only generated with the "intercept" feature of ent.
+package ent // import "github.com/codebrick-corp/dd-trace-go/contrib/entgo.io/ent"
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"entgo.io/ent"
+)
+
+const componentName = "entgo.io/ent"
+
+func init() {
+	integrations.Register(componentName, "entgo.io/ent")
+}
+
+const (
+	tagEntEntity     = "ent.entity"
+	tagEntOperation  = "ent.operation"
+	tagEntPredicates = "ent.predicates"
+	// tagEntQueryOp holds the method executing a query, e.g. "All" or "Count".
+	tagEntQueryOp = "ent.query.op"
+)
+
+// opQuery is the operation of the spans of queries.
+const opQuery = "Query"
+
+// Hook returns an ent.Hook tracing the mutations of the client it is registered on.
+func Hook(opts ...Option) ent.Hook {
+	cfg := newConfig(opts...)
+	log.Debug("contrib/entgo.io/ent: Configuring Hook: %#v", cfg)
+	return func(next ent.Mutator) ent.Mutator {
+		return ent.MutateFunc(func(ctx context.Context, m ent.Mutation) (v ent.Value, err error) {
+			op := strings.TrimPrefix(m.Op().String(), "Op")
+			span, ctx := startSpan(ctx, cfg, "ent.mutation", m.Type(), op, predicates(m))
+			defer func() { span.Finish(tracer.WithError(err)) }()
+			return next.Mutate(ctx, m)
+		})
+	}
+}
+
+// Interceptor returns an ent.Interceptor tracing the queries of the client it is
+// registered on.
+func Interceptor(opts ...Option) ent.Interceptor {
+	cfg := newConfig(opts...)
+	log.Debug("contrib/entgo.io/ent: Configuring Interceptor: %#v", cfg)
+	return ent.InterceptFunc(func(next ent.Querier) ent.Querier {
+		return ent.QuerierFunc(func(ctx context.Context, q ent.Query) (v ent.Value, err error) {
+			var entity string
+			var sopts []ddtrace.StartSpanOption
+			if qc := ent.QueryFromContext(ctx); qc != nil {
+				entity = qc.Type
+				sopts = append(sopts, tracer.Tag(tagEntQueryOp, qc.Op))
+			}
+			span, ctx := startSpan(ctx, cfg, "ent.query", entity, opQuery, predicates(q), sopts...)
+			defer func() { span.Finish(tracer.WithError(err)) }()
+			return next.Query(ctx, q)
+		})
+	})
+}
+
+// startSpan starts the span of the operation op applying to entity, as a child of the span
+// found in ctx, if any. It returns the span and a context holding it, to be passed along to
+// the next hook or interceptor so that the SQL statements of the operation are traced as
+// children of the span.
+func startSpan(ctx context.Context, cfg *config, name, entity, op string, predicates int, opts ...ddtrace.StartSpanOption) (ddtrace.Span, context.Context) {
+	opts = append([]ddtrace.StartSpanOption{
+		tracer.ServiceName(cfg.serviceName),
+		tracer.ResourceName(entity + "." + op),
+		tracer.SpanType(ext.SpanTypeSQL),
+		tracer.Tag(ext.SpanKind, ext.SpanKindInternal),
+		tracer.Tag(tagEntEntity, entity),
+		tracer.Tag(tagEntOperation, op),
+		tracer.Tag(tagEntPredicates, predicates),
+		tracer.Measured(),
+		tracer.Tag(ext.Component, componentName),
+	}, opts...)
+	opts = append(opts, cfg.spanOpts...)
+	return tracer.StartSpanFromContext(ctx, name, opts...)
+}
+
+// predicates returns the number of predicates of the generated mutation or query v, held
+// by their unexported predicates field, or 0 if v has none. ent exposes no method returning
+// them.
+func predicates(v interface{}) int {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return 0
+	}
+	if f := rv.FieldByName("predicates"); f.Kind() == reflect.Slice {
+		return f.Len()
+	}
+	return 0
+}
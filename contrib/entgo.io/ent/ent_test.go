@@ -0,0 +1,164 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package ent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	sqltrace "github.com/codebrick-corp/dd-trace-go/contrib/database/sql"
+	"github.com/codebrick-corp/dd-trace-go/contrib/entgo.io/ent/internal/entdb"
+	"github.com/codebrick-corp/dd-trace-go/contrib/entgo.io/ent/internal/entdb/user"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	"github.com/mattn/go-sqlite3"
+)
+
+// newClient returns a client of a new in-memory SQLite database, whose operations and
+// SQL statements are traced.
+func newClient(t *testing.T, opts ...Option) *entdb.Client {
+	sqltrace.Register("sqlite3", &sqlite3.SQLiteDriver{})
+	db, err := sqltrace.Open("sqlite3", "file::memory:?_fk=1")
+	require.NoError(t, err)
+	// each connection would open a database of its own
+	db.SetMaxOpenConns(1)
+	client := entdb.NewClient(entdb.Driver(entsql.OpenDB(dialect.SQLite, db)))
+	t.Cleanup(func() { client.Close() })
+	require.NoError(t, client.Schema.Create(context.Background()))
+	client.Use(Hook(opts...))
+	client.Intercept(Interceptor(opts...))
+	return client
+}
+
+// entSpans returns the finished spans of ent operations, along with the number of spans
+// of SQL statements which are children of each.
+func entSpans(mt mocktracer.Tracer) ([]mocktracer.Span, map[uint64]int) {
+	var spans []mocktracer.Span
+	children := make(map[uint64]int)
+	for _, s := range mt.FinishedSpans() {
+		if s.Tag(ext.Component) == componentName {
+			spans = append(spans, s)
+		} else {
+			children[s.ParentID()]++
+		}
+	}
+	return spans, children
+}
+
+func TestHook(t *testing.T) {
+	assert := assert.New(t)
+	client := newClient(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	root, ctx := tracer.StartSpanFromContext(context.Background(), "web.request")
+	_, err := client.User.Create().SetName("alice").Save(ctx)
+	require.NoError(t, err)
+	n, err := client.User.Update().Where(user.Name("alice"), user.Active(true)).SetActive(false).Save(ctx)
+	require.NoError(t, err)
+	assert.Equal(1, n)
+	_, err = client.User.Delete().Where(user.Active(false)).Exec(ctx)
+	require.NoError(t, err)
+	_, err = client.User.Create().Save(ctx)
+	assert.Error(err, "the name is required")
+	root.Finish()
+
+	spans, children := entSpans(mt)
+	require.Len(t, spans, 4)
+	for i, tt := range []struct {
+		op         string
+		predicates int
+	}{
+		{"Create", 0},
+		{"Update", 2},
+		{"Delete", 1},
+		{"Create", 0},
+	} {
+		s := spans[i]
+		assert.Equal("ent.mutation", s.OperationName())
+		assert.Equal(root.Context().SpanID(), s.ParentID())
+		assert.Equal("User."+tt.op, s.Tag(ext.ResourceName))
+		assert.Equal("ent", s.Tag(ext.ServiceName))
+		assert.Equal(ext.SpanTypeSQL, s.Tag(ext.SpanType))
+		assert.Equal(ext.SpanKindInternal, s.Tag(ext.SpanKind))
+		assert.Equal("User", s.Tag(tagEntEntity))
+		assert.Equal(tt.op, s.Tag(tagEntOperation))
+		assert.Equal(tt.predicates, s.Tag(tagEntPredicates))
+	}
+	// the SQL statements of the operations are their children
+	for _, s := range spans[:3] {
+		assert.NotZero(children[s.SpanID()])
+		assert.Nil(s.Tag(ext.Error))
+	}
+	assert.Zero(children[spans[3].SpanID()])
+	assert.NotNil(spans[3].Tag(ext.Error))
+}
+
+func TestInterceptor(t *testing.T) {
+	assert := assert.New(t)
+	client := newClient(t)
+	client.User.Create().SetName("alice").SaveX(context.Background())
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	root, ctx := tracer.StartSpanFromContext(context.Background(), "web.request")
+	users, err := client.User.Query().Where(user.Active(true)).All(ctx)
+	require.NoError(t, err)
+	assert.Len(users, 1)
+	n, err := client.User.Query().Count(ctx)
+	require.NoError(t, err)
+	assert.Equal(1, n)
+	root.Finish()
+
+	spans, children := entSpans(mt)
+	require.Len(t, spans, 2)
+	for i, tt := range []struct {
+		op         string
+		predicates int
+	}{
+		{"All", 1},
+		{"Count", 0},
+	} {
+		s := spans[i]
+		assert.Equal("ent.query", s.OperationName())
+		assert.Equal(root.Context().SpanID(), s.ParentID())
+		assert.Equal("User.Query", s.Tag(ext.ResourceName))
+		assert.Equal("User", s.Tag(tagEntEntity))
+		assert.Equal("Query", s.Tag(tagEntOperation))
+		assert.Equal(tt.op, s.Tag(tagEntQueryOp))
+		assert.Equal(tt.predicates, s.Tag(tagEntPredicates))
+		assert.NotZero(children[s.SpanID()])
+	}
+}
+
+func TestOptions(t *testing.T) {
+	t.Run("service", func(t *testing.T) {
+		client := newClient(t, WithServiceName("users-db"), WithSpanOptions(tracer.Tag("team", "identity")))
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		client.User.Create().SetName("alice").SaveX(context.Background())
+
+		spans, _ := entSpans(mt)
+		require.Len(t, spans, 1)
+		assert.Equal(t, "users-db", spans[0].Tag(ext.ServiceName))
+		assert.Equal(t, "identity", spans[0].Tag("team"))
+	})
+
+	t.Run("global-service", func(t *testing.T) {
+		globalconfig.SetServiceName("shop")
+		defer globalconfig.SetServiceName("")
+		assert.Equal(t, "shop", newConfig().serviceName)
+	})
+}
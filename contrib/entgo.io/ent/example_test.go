@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package ent_test
+
+import (
+	"context"
+	"log"
+
+	sqltrace "github.com/codebrick-corp/dd-trace-go/contrib/database/sql"
+	enttrace "github.com/codebrick-corp/dd-trace-go/contrib/entgo.io/ent"
+	"github.com/codebrick-corp/dd-trace-go/contrib/entgo.io/ent/internal/entdb"
+	"github.com/codebrick-corp/dd-trace-go/contrib/entgo.io/ent/internal/entdb/user"
+
+	"entgo.io/ent/dialect"
+	entsql "entgo.io/ent/dialect/sql"
+	"github.com/mattn/go-sqlite3"
+)
+
+func Example() {
+	// The SQL statements run by the client are traced as children of its operations when
+	// the database is opened with the contrib/database/sql package.
+	sqltrace.Register("sqlite3", &sqlite3.SQLiteDriver{})
+	db, err := sqltrace.Open("sqlite3", "file:users.db?_fk=1")
+	if err != nil {
+		log.Fatal(err)
+	}
+	client := entdb.NewClient(entdb.Driver(entsql.OpenDB(dialect.SQLite, db)))
+	defer client.Close()
+
+	// The mutations and queries of the client are now traced.
+	client.Use(enttrace.Hook(enttrace.WithServiceName("users-db")))
+	client.Intercept(enttrace.Interceptor(enttrace.WithServiceName("users-db")))
+
+	_, err = client.User.Delete().Where(user.Active(false)).Exec(context.Background())
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,9 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package entdb holds the ent client used by the tests, generated from its schema.
+package entdb
+
+//go:generate go run entgo.io/ent/cmd/ent generate --feature intercept ./schema
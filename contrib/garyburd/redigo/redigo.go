@@ -18,11 +18,18 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	redis "github.com/garyburd/redigo/redis"
 )
 
+const componentName = "garyburd/redigo"
+
+func init() {
+	integrations.Register(componentName, "github.com/garyburd/redigo/redis")
+}
+
 // Conn is an implementation of the redis.Conn interface that supports tracing
 type Conn struct {
 	redis.Conn
@@ -102,7 +109,9 @@ func (tc Conn) newChildSpan(ctx context.Context) ddtrace.Span {
 	p := tc.params
 	opts := []ddtrace.StartSpanOption{
 		tracer.SpanType(ext.SpanTypeRedis),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(p.config.serviceName),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(p.config.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, p.config.analyticsRate))
@@ -142,6 +151,10 @@ func (tc Conn) Do(commandName string, args ...interface{}) (reply interface{}, e
 		// See https://godoc.org/github.com/garyburd/redigo/redis#hdr-Pipelining
 		span.SetTag(ext.ResourceName, "redigo.Conn.Flush")
 	}
+	if tc.config.command.Enabled() {
+		span.SetTag("redis.raw_command", tc.config.command.Format(append([]interface{}{commandName}, args...)))
+		return tc.Conn.Do(commandName, args...)
+	}
 	var b bytes.Buffer
 	b.WriteString(commandName)
 	for _, arg := range args {
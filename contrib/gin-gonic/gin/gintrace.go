@@ -9,16 +9,24 @@ package gin // import "github.com/codebrick-corp/dd-trace-go/contrib/gin-gonic/g
 import (
 	"fmt"
 	"math"
+	"net/http"
 
 	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
 	"github.com/codebrick-corp/dd-trace-go/internal/appsec"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/gin-gonic/gin"
 )
 
+const componentName = "gin-gonic/gin"
+
+func init() {
+	integrations.Register(componentName, "github.com/gin-gonic/gin")
+}
+
 // Middleware returns middleware that will trace incoming requests. If service is empty then the
 // default service name will be used.
 func Middleware(service string, opts ...Option) gin.HandlerFunc {
@@ -30,7 +38,9 @@ func Middleware(service string, opts ...Option) gin.HandlerFunc {
 	log.Debug("contrib/gin-gonic/gin: Configuring Middleware: Service: %s, %#v", cfg.serviceName, cfg)
 	spanOpts := []tracer.StartSpanOption{
 		tracer.ServiceName(cfg.serviceName),
+		tracer.Tag(ext.Component, componentName),
 	}
+	mw := httptrace.NewMiddleware()
 	return func(c *gin.Context) {
 		if cfg.ignoreRequest(c) {
 			return
@@ -40,9 +50,13 @@ func Middleware(service string, opts ...Option) gin.HandlerFunc {
 			opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
 		}
 		opts = append(opts, tracer.Tag(ext.HTTPRoute, c.FullPath()))
-		span, ctx := httptrace.StartRequestSpan(c.Request, opts...)
+		span, ctx := mw.StartRequestSpan(c.Request, opts...)
 		defer func() {
-			httptrace.FinishRequestSpan(span, c.Writer.Status())
+			status := c.Writer.Status()
+			if status == http.StatusTooManyRequests {
+				httptrace.SetRateLimitTags(span, c.Writer.Header())
+			}
+			httptrace.FinishRequestSpan(span, status, &httptrace.Response{Header: c.Writer.Header(), Size: int64(c.Writer.Size())})
 		}()
 
 		// pass the span through the request context
@@ -65,7 +79,8 @@ func Middleware(service string, opts ...Option) gin.HandlerFunc {
 
 // HTML will trace the rendering of the template as a child of the span in the given context.
 func HTML(c *gin.Context, code int, name string, obj interface{}) {
-	span, _ := tracer.StartSpanFromContext(c.Request.Context(), "gin.render.html")
+	span, _ := tracer.StartSpanFromContext(c.Request.Context(), "gin.render.html",
+		tracer.Tag(ext.SpanKind, ext.SpanKindInternal), tracer.Tag(ext.Component, componentName))
 	span.SetTag("go.template", name)
 	defer func() {
 		if r := recover(); r != nil {
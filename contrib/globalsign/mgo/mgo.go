@@ -13,11 +13,18 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/globalsign/mgo"
 )
 
+const componentName = "globalsign/mgo"
+
+func init() {
+	integrations.Register(componentName, "github.com/globalsign/mgo")
+}
+
 // Dial opens a connection to a MongoDB server and configures it
 // for tracing.
 func Dial(url string, opts ...DialOption) (*Session, error) {
@@ -54,8 +61,10 @@ type Session struct {
 func newChildSpanFromContext(cfg *mongoConfig, tags map[string]string) ddtrace.Span {
 	opts := []ddtrace.StartSpanOption{
 		tracer.SpanType(ext.SpanTypeMongoDB),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(cfg.serviceName),
 		tracer.ResourceName("mongodb.query"),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
@@ -6,13 +6,17 @@
 package chi
 
 import (
+	"bufio"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	pappsec "github.com/codebrick-corp/dd-trace-go/appsec"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
@@ -411,3 +415,47 @@ func TestAppSec(t *testing.T) {
 		require.True(t, strings.Contains(event.(string), "crs-933-130"))
 	})
 }
+
+func TestWebSocket(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	router := chi.NewRouter()
+	router.Use(Middleware(WithServiceName("foobar"), WithWebSocketFlushInterval(0)))
+	router.Get("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+		frame := make([]byte, 8) // masked text frame
+		_, err = io.ReadFull(rw, frame)
+		require.NoError(t, err)
+		rw.Write([]byte{0x81, 2, 'o', 'k'})
+		rw.Flush()
+	})
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	fmt.Fprintf(conn, "GET /ws HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	conn.Write([]byte{0x81, 0x82, 1, 2, 3, 4, 'h' ^ 1, 'i' ^ 2})
+	_, err = io.ReadFull(br, make([]byte, 4))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return len(mt.FinishedSpans()) == 2 }, time.Second, 10*time.Millisecond)
+	spans := mt.FinishedSpans()
+	ws, req := spans[0], spans[1]
+	assert.Equal(t, "websocket.connection", ws.OperationName())
+	assert.Equal(t, "http.request", req.OperationName())
+	assert.Equal(t, req.SpanID(), ws.ParentID())
+	assert.Equal(t, "foobar", ws.Tag(ext.ServiceName))
+	assert.Equal(t, int64(1), ws.Tag("websocket.messages.received"))
+	assert.Equal(t, int64(1), ws.Tag("websocket.messages.sent"))
+}
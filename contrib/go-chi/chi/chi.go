@@ -15,12 +15,19 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
 	"github.com/codebrick-corp/dd-trace-go/internal/appsec"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/go-chi/chi"
 	"github.com/go-chi/chi/middleware"
 )
 
+const componentName = "go-chi/chi"
+
+func init() {
+	integrations.Register(componentName, "github.com/go-chi/chi")
+}
+
 // Middleware returns middleware that will trace incoming requests.
 func Middleware(opts ...Option) func(next http.Handler) http.Handler {
 	cfg := new(config)
@@ -29,7 +36,8 @@ func Middleware(opts ...Option) func(next http.Handler) http.Handler {
 		fn(cfg)
 	}
 	log.Debug("contrib/go-chi/chi: Configuring Middleware: %#v", cfg)
-	spanOpts := append(cfg.spanOpts, tracer.ServiceName(cfg.serviceName))
+	spanOpts := append(cfg.spanOpts, tracer.ServiceName(cfg.serviceName), tracer.Tag(ext.Component, componentName))
+	mw := httptrace.NewMiddleware()
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			if cfg.ignoreRequest(r) {
@@ -40,15 +48,21 @@ func Middleware(opts ...Option) func(next http.Handler) http.Handler {
 			if !math.IsNaN(cfg.analyticsRate) {
 				opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
 			}
-			span, ctx := httptrace.StartRequestSpan(r, opts...)
+			span, ctx := mw.StartRequestSpan(r, opts...)
+			// trace the connection once upgraded to the WebSocket protocol
+			w = httptrace.WrapWebSocket(w, r, span, cfg.webSocketFlushInterval, tracer.ServiceName(cfg.serviceName),
+				tracer.Tag(ext.Component, componentName))
 			ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
 			defer func() {
 				status := ww.Status()
+				if status == http.StatusTooManyRequests {
+					httptrace.SetRateLimitTags(span, ww.Header())
+				}
 				var opts []tracer.FinishOption
 				if cfg.isStatusError(status) {
 					opts = []tracer.FinishOption{tracer.WithError(fmt.Errorf("%d: %s", status, http.StatusText(status)))}
 				}
-				httptrace.FinishRequestSpan(span, status, opts...)
+				httptrace.FinishRequestSpan(span, status, &httptrace.Response{Header: ww.Header(), Size: int64(ww.BytesWritten())}, opts...)
 			}()
 
 			// pass the span through the request context
@@ -8,7 +8,9 @@ package chi
 import (
 	"math"
 	"net/http"
+	"time"
 
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/internal"
 	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
@@ -20,6 +22,8 @@ type config struct {
 	analyticsRate float64
 	isStatusError func(statusCode int) bool
 	ignoreRequest func(r *http.Request) bool
+
+	webSocketFlushInterval time.Duration
 }
 
 // Option represents an option that can be passed to NewRouter.
@@ -37,6 +41,7 @@ func defaults(cfg *config) {
 	}
 	cfg.isStatusError = isServerError
 	cfg.ignoreRequest = func(_ *http.Request) bool { return false }
+	cfg.webSocketFlushInterval = httptrace.DefaultWebSocketFlushInterval
 }
 
 // WithServiceName sets the given service name for the router.
@@ -96,3 +101,13 @@ func WithIgnoreRequest(fn func(r *http.Request) bool) Option {
 		cfg.ignoreRequest = fn
 	}
 }
+
+// WithWebSocketFlushInterval sets the interval at which the statistics of traced
+// WebSocket connections are flushed, by finishing their current span and starting a
+// new one. A zero interval traces each connection with a single span. It defaults
+// to one minute.
+func WithWebSocketFlushInterval(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.webSocketFlushInterval = d
+	}
+}
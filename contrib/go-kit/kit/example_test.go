@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package kit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	kittrace "github.com/codebrick-corp/dd-trace-go/contrib/go-kit/kit"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/go-kit/kit/endpoint"
+	kithttp "github.com/go-kit/kit/transport/http"
+)
+
+func Example() {
+	// Start the tracer
+	tracer.Start()
+	defer tracer.Stop()
+
+	var sum endpoint.Endpoint = func(ctx context.Context, request interface{}) (interface{}, error) {
+		req := request.([]int)
+		return req[0] + req[1], nil
+	}
+	// Trace the endpoint as the Sum method of the addsvc service.
+	sum = kittrace.TraceServer("addsvc.Sum")(sum)
+
+	handler := kithttp.NewServer(
+		sum,
+		func(_ context.Context, r *http.Request) (interface{}, error) {
+			var req []int
+			err := json.NewDecoder(r.Body).Decode(&req)
+			return req, err
+		},
+		kithttp.EncodeJSONResponse,
+		// Continue the traces propagated by the clients.
+		kithttp.ServerBefore(kittrace.HTTPToContext()),
+	)
+	http.ListenAndServe(":8080", handler)
+}
@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package kit provides functions to trace the endpoints and transports of the go-kit package
+// (https://github.com/go-kit/kit), following the conventions of its own tracing packages.
+//
+// TraceServer and TraceClient trace the calls to endpoints, named after the given operation,
+// e.g. "addsvc.Sum". The trace is propagated by the HTTP and gRPC transports of go-kit with:
+//
+//   - HTTPToContext and GRPCToContext, given to the ServerBefore options of the servers;
+//   - ContextToHTTP and ContextToGRPC, given to the ClientBefore options of the clients.
+package kit // import "github.com/codebrick-corp/dd-trace-go/contrib/go-kit/kit"
+
+import (
+	"context"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/go-kit/kit/endpoint"
+)
+
+const componentName = "go-kit/kit"
+
+func init() {
+	integrations.Register(componentName, "github.com/go-kit/kit")
+}
+
+// remoteParentKey is the context key of the span context propagated by the client.
+type remoteParentKey struct{}
+
+// TraceServer returns an endpoint middleware tracing the calls to the endpoint of a server
+// as the given operation. The spans are children of the span found in the context if any,
+// or else of the span propagated by the client (see HTTPToContext and GRPCToContext).
+func TraceServer(operation string, opts ...Option) endpoint.Middleware {
+	return traceEndpoint(operation, ext.SpanKindServer, "go-kit.server", opts...)
+}
+
+// TraceClient returns an endpoint middleware tracing the calls to the endpoint of a client
+// as the given operation. The spans are propagated to the servers by ContextToHTTP and
+// ContextToGRPC.
+func TraceClient(operation string, opts ...Option) endpoint.Middleware {
+	return traceEndpoint(operation, ext.SpanKindClient, "go-kit.client", opts...)
+}
+
+func traceEndpoint(operation, kind, spanName string, opts ...Option) endpoint.Middleware {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/go-kit/kit: Tracing %s endpoint %s: %#v", kind, operation, cfg)
+	return func(next endpoint.Endpoint) endpoint.Endpoint {
+		return func(ctx context.Context, request interface{}) (interface{}, error) {
+			opts := []ddtrace.StartSpanOption{
+				tracer.ServiceName(cfg.serviceName),
+				tracer.ResourceName(operation),
+				tracer.SpanType(ext.AppTypeRPC),
+				tracer.Tag(ext.SpanKind, kind),
+				tracer.Measured(),
+				tracer.Tag(ext.Component, componentName),
+			}
+			if _, ok := tracer.SpanFromContext(ctx); !ok {
+				if parent, ok := ctx.Value(remoteParentKey{}).(ddtrace.SpanContext); ok {
+					opts = append(opts, tracer.ChildOf(parent))
+				}
+			}
+			opts = append(opts, cfg.spanOpts...)
+			span, ctx := tracer.StartSpanFromContext(ctx, spanName, opts...)
+			response, err := next(ctx, request)
+			failure := err
+			if f, ok := response.(endpoint.Failer); ok && failure == nil {
+				// business errors are reported by the responses of the endpoints
+				failure = f.Failed()
+			}
+			if failure != nil && cfg.isError(failure) {
+				span.Finish(tracer.WithError(failure))
+			} else {
+				span.Finish()
+			}
+			return response, err
+		}
+	}
+}
+
+// contextWithRemoteParent returns a copy of ctx holding the span context extracted from carrier.
+func contextWithRemoteParent(ctx context.Context, carrier interface{}) context.Context {
+	spanctx, err := tracer.Extract(carrier)
+	if err != nil {
+		if err != tracer.ErrSpanContextNotFound {
+			log.Debug("contrib/go-kit/kit: failed to extract the span context: %v", err)
+		}
+		return ctx
+	}
+	return context.WithValue(ctx, remoteParentKey{}, spanctx)
+}
+
+// inject injects the span found in ctx, if any, into carrier.
+func inject(ctx context.Context, carrier interface{}) {
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return
+	}
+	if err := tracer.Inject(span.Context(), carrier); err != nil {
+		log.Debug("contrib/go-kit/kit: failed to inject the span context: %v", err)
+	}
+}
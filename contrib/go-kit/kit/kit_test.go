@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package kit
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type response struct{ err error }
+
+func (r response) Failed() error { return r.err }
+
+func TestTraceServer(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	ep := TraceServer("addsvc.Sum", WithServiceName("addsvc"))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		_, ok := tracer.SpanFromContext(ctx)
+		assert.True(ok)
+		return response{}, nil
+	})
+	_, err := ep(context.Background(), nil)
+	assert.NoError(err)
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 1)
+	span := spans[0]
+	assert.Equal("go-kit.server", span.OperationName())
+	assert.Equal("addsvc.Sum", span.Tag(ext.ResourceName))
+	assert.Equal("addsvc", span.Tag(ext.ServiceName))
+	assert.Equal(ext.SpanKindServer, span.Tag(ext.SpanKind))
+	assert.Nil(span.Tag(ext.Error))
+}
+
+func TestErrors(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	failed := errors.New("failed")
+	ignored := errors.New("ignored")
+	for _, tt := range []struct {
+		resp interface{}
+		err  error
+	}{
+		{nil, failed},
+		{response{failed}, nil},
+		{nil, ignored},
+	} {
+		ep := TraceClient("addsvc.Sum", WithErrorCheck(func(err error) bool {
+			return err != ignored
+		}))(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return tt.resp, tt.err
+		})
+		_, err := ep(context.Background(), nil)
+		assert.Equal(tt.err, err)
+	}
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 3)
+	assert.Equal(failed, spans[0].Tag(ext.Error))
+	assert.Equal(failed, spans[1].Tag(ext.Error))
+	assert.Nil(spans[2].Tag(ext.Error))
+}
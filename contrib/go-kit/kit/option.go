@@ -0,0 +1,53 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package kit
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+const defaultServiceName = "go-kit"
+
+type config struct {
+	serviceName string
+	spanOpts    []ddtrace.StartSpanOption
+	isError     func(err error) bool
+}
+
+// Option represents an option that can be passed to TraceServer and TraceClient.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	cfg.serviceName = defaultServiceName
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+	cfg.isError = func(error) bool { return true }
+}
+
+// WithServiceName sets the given service name for the traced endpoints.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithSpanOptions applies the given set of options to the started spans.
+func WithSpanOptions(opts ...ddtrace.StartSpanOption) Option {
+	return func(cfg *config) {
+		cfg.spanOpts = opts
+	}
+}
+
+// WithErrorCheck sets a function telling whether the error returned by an endpoint, or
+// reported by its response through endpoint.Failer, makes the span an error. All the
+// errors do by default.
+func WithErrorCheck(fn func(err error) bool) Option {
+	return func(cfg *config) {
+		cfg.isError = fn
+	}
+}
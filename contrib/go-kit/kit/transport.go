@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package kit
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	kitgrpc "github.com/go-kit/kit/transport/grpc"
+	kithttp "github.com/go-kit/kit/transport/http"
+	"google.golang.org/grpc/metadata"
+)
+
+// HTTPToContext returns a request function extracting the trace propagated by the client
+// from the headers of the request, to be given to the ServerBefore option of HTTP servers.
+func HTTPToContext() kithttp.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		return contextWithRemoteParent(ctx, tracer.HTTPHeadersCarrier(r.Header))
+	}
+}
+
+// ContextToHTTP returns a request function propagating the trace found in the context to
+// the headers of the request, to be given to the ClientBefore option of HTTP clients.
+func ContextToHTTP() kithttp.RequestFunc {
+	return func(ctx context.Context, r *http.Request) context.Context {
+		inject(ctx, tracer.HTTPHeadersCarrier(r.Header))
+		return ctx
+	}
+}
+
+// GRPCToContext returns a request function extracting the trace propagated by the client
+// from the metadata of the request, to be given to the ServerBefore option of gRPC servers.
+func GRPCToContext() kitgrpc.ServerRequestFunc {
+	return func(ctx context.Context, md metadata.MD) context.Context {
+		return contextWithRemoteParent(ctx, metadataCarrier(md))
+	}
+}
+
+// ContextToGRPC returns a request function propagating the trace found in the context to
+// the metadata of the request, to be given to the ClientBefore option of gRPC clients.
+func ContextToGRPC() kitgrpc.ClientRequestFunc {
+	return func(ctx context.Context, md *metadata.MD) context.Context {
+		inject(ctx, metadataCarrier(*md))
+		return ctx
+	}
+}
+
+// metadataCarrier adapts gRPC metadata to the carrier interfaces used for distributed tracing.
+type metadataCarrier metadata.MD
+
+var (
+	_ tracer.TextMapReader = metadataCarrier{}
+	_ tracer.TextMapWriter = metadataCarrier{}
+)
+
+// ForeachKey implements tracer.TextMapReader.
+func (c metadataCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, vs := range c {
+		for _, v := range vs {
+			if err := handler(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Set implements tracer.TextMapWriter. The keys of gRPC metadata are lowercase.
+func (c metadataCarrier) Set(key, val string) {
+	k := strings.ToLower(key)
+	c[k] = append(c[k], val)
+}
@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package kit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestPropagation(t *testing.T) {
+	noop := func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil }
+
+	t.Run("http", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		r := httptest.NewRequest("GET", "/sum", nil)
+		client := TraceClient("addsvc.Sum")(func(ctx context.Context, req interface{}) (interface{}, error) {
+			ContextToHTTP()(ctx, r)
+			return nil, nil
+		})
+		client(context.Background(), nil)
+		ctx := HTTPToContext()(context.Background(), r)
+		TraceServer("addsvc.Sum")(noop)(ctx, nil)
+
+		spans := mt.FinishedSpans()
+		assert.Len(t, spans, 2)
+		assert.Equal(t, spans[0].SpanID(), spans[1].ParentID())
+		assert.Equal(t, spans[0].TraceID(), spans[1].TraceID())
+	})
+
+	t.Run("grpc", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		md := metadata.MD{}
+		client := TraceClient("addsvc.Sum")(func(ctx context.Context, req interface{}) (interface{}, error) {
+			ContextToGRPC()(ctx, &md)
+			return nil, nil
+		})
+		client(context.Background(), nil)
+		ctx := GRPCToContext()(context.Background(), md)
+		TraceServer("addsvc.Sum")(noop)(ctx, nil)
+
+		spans := mt.FinishedSpans()
+		assert.Len(t, spans, 2)
+		assert.Equal(t, spans[0].SpanID(), spans[1].ParentID())
+	})
+
+	t.Run("none", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		ctx := HTTPToContext()(context.Background(), httptest.NewRequest("GET", "/sum", nil))
+		TraceServer("addsvc.Sum")(noop)(ctx, nil)
+
+		spans := mt.FinishedSpans()
+		assert.Len(t, spans, 1)
+		assert.Zero(t, spans[0].ParentID())
+	})
+}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package kratos_test
+
+import (
+	"context"
+
+	kratostrace "github.com/codebrick-corp/dd-trace-go/contrib/go-kratos/kratos.v2"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/go-kratos/kratos/v2"
+	"github.com/go-kratos/kratos/v2/transport/grpc"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+func Example() {
+	// Start the tracer
+	tracer.Start()
+	defer tracer.Stop()
+
+	// Trace the requests handled by the servers of the app.
+	httpSrv := http.NewServer(http.Address(":8000"), http.Middleware(kratostrace.Server()))
+	grpcSrv := grpc.NewServer(grpc.Address(":9000"), grpc.Middleware(kratostrace.Server()))
+
+	app := kratos.New(kratos.Name("helloworld"), kratos.Server(httpSrv, grpcSrv))
+	app.Run()
+}
+
+func ExampleClient() {
+	// Trace the requests sent by a client, propagating their trace to the server.
+	conn, err := grpc.DialInsecure(context.Background(),
+		grpc.WithEndpoint("127.0.0.1:9000"),
+		grpc.WithMiddleware(kratostrace.Client()),
+	)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+}
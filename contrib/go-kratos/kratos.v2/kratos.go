@@ -0,0 +1,169 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package kratos provides functions to trace the go-kratos/kratos package (https://github.com/go-kratos/kratos).
+//
+// The middleware returned by Server and Client are meant to be part of the middleware chains
+// of the HTTP and gRPC transports of kratos. The spans are named after the operation of the
+// transport, i.e. the full name of the called method.
+package kratos // import "github.com/codebrick-corp/dd-trace-go/contrib/go-kratos/kratos.v2"
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/middleware"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/go-kratos/kratos/v2/transport/http"
+)
+
+const componentName = "go-kratos/kratos.v2"
+
+func init() {
+	integrations.Register(componentName, "github.com/go-kratos/kratos/v2")
+}
+
+const (
+	// tagOperation holds the operation of the transport.
+	tagOperation = "kratos.operation"
+	// tagTransport holds the kind of the transport: "grpc" or "http".
+	tagTransport = "kratos.transport"
+	// tagErrorCode holds the code of the kratos errors, which follows the HTTP status codes.
+	tagErrorCode = "kratos.error.code"
+	// tagErrorReason holds the reason of the kratos errors.
+	tagErrorReason = "kratos.error.reason"
+)
+
+// Server returns a server middleware tracing the requests it handles.
+func Server(opts ...Option) middleware.Middleware {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/go-kratos/kratos.v2: Configuring Server: %#v", cfg)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromServerContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			opts := spanOptions(cfg, tr, cfg.serverServiceName(), ext.SpanKindServer)
+			if spanctx, err := tracer.Extract(headerCarrier{tr.RequestHeader()}); err == nil {
+				opts = append(opts, tracer.ChildOf(spanctx))
+			}
+			span, ctx := tracer.StartSpanFromContext(ctx, "kratos.server", opts...)
+			reply, err := handler(ctx, req)
+			finish(cfg, span, err)
+			return reply, err
+		}
+	}
+}
+
+// Client returns a client middleware tracing the requests it sends and propagating
+// their trace to the servers.
+func Client(opts ...Option) middleware.Middleware {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/go-kratos/kratos.v2: Configuring Client: %#v", cfg)
+	return func(handler middleware.Handler) middleware.Handler {
+		return func(ctx context.Context, req interface{}) (interface{}, error) {
+			tr, ok := transport.FromClientContext(ctx)
+			if !ok {
+				return handler(ctx, req)
+			}
+			opts := spanOptions(cfg, tr, cfg.clientServiceName(), ext.SpanKindClient)
+			span, ctx := tracer.StartSpanFromContext(ctx, "kratos.client", opts...)
+			if err := tracer.Inject(span.Context(), headerCarrier{tr.RequestHeader()}); err != nil {
+				log.Debug("contrib/go-kratos/kratos.v2: failed to inject headers: %v", err)
+			}
+			reply, err := handler(ctx, req)
+			finish(cfg, span, err)
+			return reply, err
+		}
+	}
+}
+
+// spanOptions returns the options of the spans started for the requests going through tr.
+func spanOptions(cfg *config, tr transport.Transporter, service, kind string) []ddtrace.StartSpanOption {
+	opts := []ddtrace.StartSpanOption{
+		tracer.ServiceName(service),
+		tracer.ResourceName(tr.Operation()),
+		tracer.Tag(ext.SpanKind, kind),
+		tracer.Tag(tagOperation, tr.Operation()),
+		tracer.Tag(tagTransport, tr.Kind().String()),
+		tracer.Tag(ext.Component, componentName),
+		tracer.Measured(),
+	}
+	if ht, ok := tr.(http.Transporter); ok {
+		r := ht.Request()
+		opts = append(opts,
+			tracer.SpanType(ext.SpanTypeWeb),
+			tracer.Tag(ext.HTTPMethod, r.Method),
+			tracer.Tag(ext.HTTPURL, httptrace.QuantizePath(r.URL.Path)),
+		)
+		if route := ht.PathTemplate(); route != "" {
+			opts = append(opts, tracer.Tag(ext.HTTPRoute, route))
+		}
+	} else {
+		opts = append(opts, tracer.SpanType(ext.AppTypeRPC))
+	}
+	return append(opts, cfg.spanOpts...)
+}
+
+// finish finishes span, tagging it with the code and reason of err when it is a kratos error.
+func finish(cfg *config, span ddtrace.Span, err error) {
+	if err == nil {
+		span.Finish()
+		return
+	}
+	e := errors.FromError(err)
+	span.SetTag(tagErrorCode, strconv.Itoa(int(e.Code)))
+	if e.Reason != "" {
+		span.SetTag(tagErrorReason, e.Reason)
+	}
+	if !cfg.isStatusError(int(e.Code)) {
+		span.Finish()
+		return
+	}
+	span.Finish(tracer.WithError(err))
+}
+
+// headerCarrier adapts the headers of a kratos transport to the carrier interfaces
+// used for distributed tracing.
+type headerCarrier struct {
+	h transport.Header
+}
+
+var (
+	_ tracer.TextMapReader = headerCarrier{}
+	_ tracer.TextMapWriter = headerCarrier{}
+)
+
+// ForeachKey implements tracer.TextMapReader.
+func (c headerCarrier) ForeachKey(handler func(key, val string) error) error {
+	for _, k := range c.h.Keys() {
+		if err := handler(k, c.h.Get(k)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set implements tracer.TextMapWriter.
+func (c headerCarrier) Set(key, val string) {
+	c.h.Set(key, val)
+}
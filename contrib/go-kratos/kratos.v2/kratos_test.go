@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package kratos
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/go-kratos/kratos/v2/errors"
+	"github.com/go-kratos/kratos/v2/transport"
+	"github.com/stretchr/testify/assert"
+)
+
+type header map[string]string
+
+func (h header) Get(key string) string      { return h[key] }
+func (h header) Set(key, value string)      { h[key] = value }
+func (h header) Add(key, value string)      { h[key] = value }
+func (h header) Values(key string) []string { return []string{h[key]} }
+func (h header) Keys() []string {
+	keys := make([]string, 0, len(h))
+	for k := range h {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+type fakeTransport struct {
+	operation string
+	request   header
+}
+
+func (tr *fakeTransport) Kind() transport.Kind            { return transport.KindGRPC }
+func (tr *fakeTransport) Endpoint() string                { return "localhost:9000" }
+func (tr *fakeTransport) Operation() string               { return tr.operation }
+func (tr *fakeTransport) RequestHeader() transport.Header { return tr.request }
+func (tr *fakeTransport) ReplyHeader() transport.Header   { return header{} }
+
+func TestClientServer(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	tr := &fakeTransport{operation: "/helloworld.Greeter/SayHello", request: header{}}
+	server := Server(WithServiceName("greeter"))(func(ctx context.Context, req interface{}) (interface{}, error) {
+		_, ok := tracer.SpanFromContext(ctx)
+		assert.True(ok)
+		return "hello " + req.(string), nil
+	})
+	client := Client()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		// hand the request over to the server along with the propagated headers
+		return server(transport.NewServerContext(context.Background(), tr), req)
+	})
+
+	reply, err := client(transport.NewClientContext(context.Background(), tr), "world")
+	assert.NoError(err)
+	assert.Equal("hello world", reply)
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 2)
+	s, c := spans[0], spans[1]
+	assert.Equal("kratos.server", s.OperationName())
+	assert.Equal("greeter", s.Tag(ext.ServiceName))
+	assert.Equal(ext.SpanKindServer, s.Tag(ext.SpanKind))
+	assert.Equal("/helloworld.Greeter/SayHello", s.Tag(ext.ResourceName))
+	assert.Equal("grpc", s.Tag(tagTransport))
+	assert.Equal(ext.AppTypeRPC, s.Tag(ext.SpanType))
+	assert.Equal("kratos.client", c.OperationName())
+	assert.Equal("kratos.client", c.Tag(ext.ServiceName))
+	assert.Equal(ext.SpanKindClient, c.Tag(ext.SpanKind))
+	assert.Equal(c.SpanID(), s.ParentID())
+}
+
+func TestError(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	tr := &fakeTransport{operation: "/helloworld.Greeter/SayHello", request: header{}}
+	for _, err := range []error{
+		errors.NotFound("USER_NOT_FOUND", "no such user"),
+		fmt.Errorf("oh no"),
+	} {
+		handler := Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+			return nil, err
+		})
+		_, got := handler(transport.NewServerContext(context.Background(), tr), nil)
+		assert.Equal(err, got)
+	}
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 2)
+	assert.Equal("404", spans[0].Tag(tagErrorCode))
+	assert.Equal("USER_NOT_FOUND", spans[0].Tag(tagErrorReason))
+	assert.Nil(spans[0].Tag(ext.Error))
+	assert.Equal("500", spans[1].Tag(tagErrorCode))
+	assert.NotNil(spans[1].Tag(ext.Error))
+}
+
+func TestNoTransport(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	handler := Server()(func(ctx context.Context, req interface{}) (interface{}, error) {
+		return req, nil
+	})
+	_, err := handler(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Len(t, mt.FinishedSpans(), 0)
+}
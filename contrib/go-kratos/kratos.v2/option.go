@@ -0,0 +1,68 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package kratos
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+type config struct {
+	serviceName   string
+	spanOpts      []ddtrace.StartSpanOption
+	isStatusError func(code int) bool
+}
+
+// Option represents an option that can be passed to Server and Client.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+	cfg.isStatusError = isServerError
+}
+
+func (cfg *config) serverServiceName() string {
+	if cfg.serviceName == "" {
+		return "kratos.server"
+	}
+	return cfg.serviceName
+}
+
+func (cfg *config) clientServiceName() string {
+	if cfg.serviceName == "" {
+		return "kratos.client"
+	}
+	return cfg.serviceName
+}
+
+// WithServiceName sets the given service name for the server or the client.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithSpanOptions applies the given set of options to the started spans.
+func WithSpanOptions(opts ...ddtrace.StartSpanOption) Option {
+	return func(cfg *config) {
+		cfg.spanOpts = opts
+	}
+}
+
+// WithErrorCheck sets a function telling whether the code of a kratos error, which
+// follows the HTTP status codes, makes the span an error. It defaults to reporting
+// the 5xx codes as errors, plain errors having the code 500.
+func WithErrorCheck(fn func(code int) bool) Option {
+	return func(cfg *config) {
+		cfg.isStatusError = fn
+	}
+}
+
+func isServerError(code int) bool {
+	return code >= 500 && code < 600
+}
@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package ldap_test
+
+import (
+	"context"
+	"log"
+
+	ldaptrace "github.com/codebrick-corp/dd-trace-go/contrib/go-ldap/ldap.v3"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+func Example() {
+	l, err := ldap.DialURL("ldap://127.0.0.1:389")
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Wrap the connection to trace its requests.
+	conn := ldaptrace.WrapConn(l, ldaptrace.WithServiceName("my-directory"))
+	defer conn.Close()
+
+	if err := conn.Bind("cn=admin,dc=example,dc=org", "secret"); err != nil {
+		log.Fatal(err)
+	}
+
+	// Use WithContext to make the requests children of the span in ctx.
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "web.request")
+	defer span.Finish()
+	res, err := conn.WithContext(ctx).Search(ldap.NewSearchRequest(
+		"dc=example,dc=org", ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		"(uid=jdoe)", []string{"cn", "mail"}, nil,
+	))
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("found %d entries", len(res.Entries))
+}
@@ -0,0 +1,233 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package ldap provides functions to trace the go-ldap/ldap package (https://github.com/go-ldap/ldap).
+//
+// `WrapConn` will wrap an ldap `Conn` and return a new struct with all the same
+// methods, so should be seamless for existing applications. It also has an
+// additional `WithContext` method which can be used to connect a span to an
+// existing trace.
+package ldap // import "github.com/codebrick-corp/dd-trace-go/contrib/go-ldap/ldap.v3"
+
+import (
+	"context"
+	"errors"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/go-ldap/ldap/v3"
+)
+
+const componentName = "go-ldap/ldap.v3"
+
+func init() {
+	integrations.Register(componentName, "github.com/go-ldap/ldap/v3")
+}
+
+const (
+	// tagDN holds the DN of the entry targeted by a request, or of the user of a bind.
+	tagDN = "ldap.dn"
+	// tagBaseDN holds the base DN of a search.
+	tagBaseDN = "ldap.base_dn"
+	// tagScope holds the scope of a search: "base", "one" or "sub".
+	tagScope = "ldap.scope"
+	// tagResultCount holds the number of entries returned by a search.
+	tagResultCount = "ldap.result_count"
+	// tagResultCode holds the LDAP result code of a failed request.
+	tagResultCode = "ldap.result_code"
+)
+
+// scopes maps the search scopes to their names in LDAP URLs.
+var scopes = map[int]string{
+	ldap.ScopeBaseObject:   "base",
+	ldap.ScopeSingleLevel:  "one",
+	ldap.ScopeWholeSubtree: "sub",
+}
+
+// WrapConn wraps an ldap.Conn so that all its requests are traced using the default
+// tracer with the service name "ldap". The filters of the searches are left out, as
+// they commonly hold user identifiers.
+func WrapConn(conn *ldap.Conn, opts ...Option) *Conn {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/go-ldap/ldap.v3: Wrapping Conn: %#v", cfg)
+	return &Conn{
+		Conn:    conn,
+		cfg:     cfg,
+		context: context.Background(),
+	}
+}
+
+// Conn is used to trace requests to an LDAP server.
+type Conn struct {
+	*ldap.Conn
+	cfg     *config
+	context context.Context
+}
+
+var _ ldap.Client = (*Conn)(nil)
+
+// WithContext creates a copy of the Conn with the given context. The requests of the
+// copy are traced as children of the span found in ctx.
+func (c *Conn) WithContext(ctx context.Context) *Conn {
+	return &Conn{
+		Conn:    c.Conn,
+		cfg:     c.cfg,
+		context: ctx,
+	}
+}
+
+// startSpan starts a span named after the request op from the context set with WithContext.
+func (c *Conn) startSpan(op string, opts ...ddtrace.StartSpanOption) ddtrace.Span {
+	opts = append(opts,
+		tracer.SpanType(ext.SpanTypeLDAP),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.ServiceName(c.cfg.serviceName),
+		tracer.ResourceName(op),
+		tracer.Tag(ext.Component, componentName),
+	)
+	span, _ := tracer.StartSpanFromContext(c.context, operationName, opts...)
+	return span
+}
+
+// finishSpan finishes span with err, tagging the LDAP result code it holds if any.
+func finishSpan(span ddtrace.Span, err error) {
+	var lerr *ldap.Error
+	if errors.As(err, &lerr) {
+		span.SetTag(tagResultCode, int(lerr.ResultCode))
+	}
+	span.Finish(tracer.WithError(err))
+}
+
+// wrapped methods:
+
+// Bind invokes and traces Conn.Bind.
+func (c *Conn) Bind(username, password string) error {
+	span := c.startSpan("Bind", tracer.Tag(tagDN, username))
+	err := c.Conn.Bind(username, password)
+	finishSpan(span, err)
+	return err
+}
+
+// UnauthenticatedBind invokes and traces Conn.UnauthenticatedBind.
+func (c *Conn) UnauthenticatedBind(username string) error {
+	span := c.startSpan("UnauthenticatedBind", tracer.Tag(tagDN, username))
+	err := c.Conn.UnauthenticatedBind(username)
+	finishSpan(span, err)
+	return err
+}
+
+// SimpleBind invokes and traces Conn.SimpleBind.
+func (c *Conn) SimpleBind(req *ldap.SimpleBindRequest) (*ldap.SimpleBindResult, error) {
+	span := c.startSpan("SimpleBind", tracer.Tag(tagDN, req.Username))
+	res, err := c.Conn.SimpleBind(req)
+	finishSpan(span, err)
+	return res, err
+}
+
+// ExternalBind invokes and traces Conn.ExternalBind.
+func (c *Conn) ExternalBind() error {
+	span := c.startSpan("ExternalBind")
+	err := c.Conn.ExternalBind()
+	finishSpan(span, err)
+	return err
+}
+
+// Search invokes and traces Conn.Search.
+func (c *Conn) Search(req *ldap.SearchRequest) (*ldap.SearchResult, error) {
+	span := c.startSpan("Search", searchTags(req)...)
+	res, err := c.Conn.Search(req)
+	finishSearchSpan(span, res, err)
+	return res, err
+}
+
+// SearchWithPaging invokes and traces Conn.SearchWithPaging.
+func (c *Conn) SearchWithPaging(req *ldap.SearchRequest, pagingSize uint32) (*ldap.SearchResult, error) {
+	span := c.startSpan("SearchWithPaging", searchTags(req)...)
+	res, err := c.Conn.SearchWithPaging(req, pagingSize)
+	finishSearchSpan(span, res, err)
+	return res, err
+}
+
+// searchTags returns the options tagging the span of the search req.
+func searchTags(req *ldap.SearchRequest) []ddtrace.StartSpanOption {
+	opts := []ddtrace.StartSpanOption{tracer.Tag(tagBaseDN, req.BaseDN)}
+	if scope, ok := scopes[req.Scope]; ok {
+		opts = append(opts, tracer.Tag(tagScope, scope))
+	}
+	return opts
+}
+
+// finishSearchSpan finishes the span of a search which returned res and err.
+func finishSearchSpan(span ddtrace.Span, res *ldap.SearchResult, err error) {
+	if res != nil {
+		// partial results may be returned along with an error
+		span.SetTag(tagResultCount, len(res.Entries))
+	}
+	finishSpan(span, err)
+}
+
+// Add invokes and traces Conn.Add.
+func (c *Conn) Add(req *ldap.AddRequest) error {
+	span := c.startSpan("Add", tracer.Tag(tagDN, req.DN))
+	err := c.Conn.Add(req)
+	finishSpan(span, err)
+	return err
+}
+
+// Del invokes and traces Conn.Del.
+func (c *Conn) Del(req *ldap.DelRequest) error {
+	span := c.startSpan("Del", tracer.Tag(tagDN, req.DN))
+	err := c.Conn.Del(req)
+	finishSpan(span, err)
+	return err
+}
+
+// Modify invokes and traces Conn.Modify.
+func (c *Conn) Modify(req *ldap.ModifyRequest) error {
+	span := c.startSpan("Modify", tracer.Tag(tagDN, req.DN))
+	err := c.Conn.Modify(req)
+	finishSpan(span, err)
+	return err
+}
+
+// ModifyWithResult invokes and traces Conn.ModifyWithResult.
+func (c *Conn) ModifyWithResult(req *ldap.ModifyRequest) (*ldap.ModifyResult, error) {
+	span := c.startSpan("ModifyWithResult", tracer.Tag(tagDN, req.DN))
+	res, err := c.Conn.ModifyWithResult(req)
+	finishSpan(span, err)
+	return res, err
+}
+
+// ModifyDN invokes and traces Conn.ModifyDN.
+func (c *Conn) ModifyDN(req *ldap.ModifyDNRequest) error {
+	span := c.startSpan("ModifyDN", tracer.Tag(tagDN, req.DN))
+	err := c.Conn.ModifyDN(req)
+	finishSpan(span, err)
+	return err
+}
+
+// Compare invokes and traces Conn.Compare.
+func (c *Conn) Compare(dn, attribute, value string) (bool, error) {
+	span := c.startSpan("Compare", tracer.Tag(tagDN, dn))
+	ok, err := c.Conn.Compare(dn, attribute, value)
+	finishSpan(span, err)
+	return ok, err
+}
+
+// PasswordModify invokes and traces Conn.PasswordModify.
+func (c *Conn) PasswordModify(req *ldap.PasswordModifyRequest) (*ldap.PasswordModifyResult, error) {
+	span := c.startSpan("PasswordModify", tracer.Tag(tagDN, req.UserIdentity))
+	res, err := c.Conn.PasswordModify(req)
+	finishSpan(span, err)
+	return res, err
+}
@@ -0,0 +1,185 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package ldap
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"testing"
+
+	ber "github.com/go-asn1-ber/asn1-ber"
+	"github.com/go-ldap/ldap/v3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+// serveLDAP answers the requests read from conn as a fake LDAP server holding two users.
+// Binds with the password "wrong" fail with invalid credentials.
+func serveLDAP(conn net.Conn) {
+	defer conn.Close()
+	for {
+		p, err := ber.ReadPacket(conn)
+		if err != nil {
+			return
+		}
+		id := p.Children[0].Value.(int64)
+		req := p.Children[1]
+		reply := func(op *ber.Packet) {
+			msg := ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "LDAP Response")
+			msg.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagInteger, id, "Message ID"))
+			msg.AppendChild(op)
+			conn.Write(msg.Bytes())
+		}
+		result := func(tag ber.Tag, code int) *ber.Packet {
+			op := ber.Encode(ber.ClassApplication, ber.TypeConstructed, tag, nil, "Response")
+			op.AppendChild(ber.NewInteger(ber.ClassUniversal, ber.TypePrimitive, ber.TagEnumerated, code, "Result Code"))
+			op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Matched DN"))
+			op.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "", "Diagnostic Message"))
+			return op
+		}
+		switch req.Tag {
+		case ldap.ApplicationBindRequest:
+			code := ldap.LDAPResultSuccess
+			if string(req.Children[2].Data.Bytes()) == "wrong" {
+				code = ldap.LDAPResultInvalidCredentials
+			}
+			reply(result(ldap.ApplicationBindResponse, code))
+		case ldap.ApplicationSearchRequest:
+			for _, cn := range []string{"alice", "bob"} {
+				entry := ber.Encode(ber.ClassApplication, ber.TypeConstructed, ldap.ApplicationSearchResultEntry, nil, "Search Result Entry")
+				entry.AppendChild(ber.NewString(ber.ClassUniversal, ber.TypePrimitive, ber.TagOctetString, "cn="+cn+",dc=example,dc=org", "DN"))
+				entry.AppendChild(ber.Encode(ber.ClassUniversal, ber.TypeConstructed, ber.TagSequence, nil, "Attributes"))
+				reply(entry)
+			}
+			reply(result(ldap.ApplicationSearchResultDone, ldap.LDAPResultSuccess))
+		case ldap.ApplicationModifyRequest:
+			reply(result(ldap.ApplicationModifyResponse, ldap.LDAPResultSuccess))
+		default:
+			return
+		}
+	}
+}
+
+func newConn(t *testing.T, opts ...Option) *Conn {
+	client, server := net.Pipe()
+	go serveLDAP(server)
+	conn := ldap.NewConn(client, false)
+	conn.Start()
+	t.Cleanup(conn.Close)
+	return WrapConn(conn, opts...)
+}
+
+func TestBind(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		assert := assert.New(t)
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		err := newConn(t).Bind("cn=admin,dc=example,dc=org", "secret")
+		require.NoError(t, err)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		s := spans[0]
+		assert.Equal("ldap.request", s.OperationName())
+		assert.Equal("Bind", s.Tag(ext.ResourceName))
+		assert.Equal("ldap", s.Tag(ext.ServiceName))
+		assert.Equal(ext.SpanTypeLDAP, s.Tag(ext.SpanType))
+		assert.Equal(ext.SpanKindClient, s.Tag(ext.SpanKind))
+		assert.Equal("cn=admin,dc=example,dc=org", s.Tag(tagDN))
+		assert.Nil(s.Tag(ext.Error))
+		assert.Nil(s.Tag(tagResultCode))
+	})
+
+	t.Run("error", func(t *testing.T) {
+		assert := assert.New(t)
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		err := newConn(t).Bind("cn=admin,dc=example,dc=org", "wrong")
+		require.Error(t, err)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(err, spans[0].Tag(ext.Error))
+		assert.Equal(ldap.LDAPResultInvalidCredentials, spans[0].Tag(tagResultCode))
+	})
+}
+
+func TestSearch(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "http.request")
+	res, err := newConn(t).WithContext(ctx).Search(ldap.NewSearchRequest(
+		"dc=example,dc=org", ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		"(uid=alice)", []string{"cn"}, nil,
+	))
+	parent.Finish()
+	require.NoError(t, err)
+	assert.Len(res.Entries, 2)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	s := spans[0]
+	assert.Equal("Search", s.Tag(ext.ResourceName))
+	assert.Equal(parent.Context().SpanID(), s.ParentID())
+	assert.Equal("dc=example,dc=org", s.Tag(tagBaseDN))
+	assert.Equal("sub", s.Tag(tagScope))
+	assert.Equal(2, s.Tag(tagResultCount))
+	for k, v := range s.Tags() {
+		assert.NotContains(fmt.Sprint(v), "alice", k)
+	}
+}
+
+func TestModify(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	req := ldap.NewModifyRequest("cn=alice,dc=example,dc=org", nil)
+	req.Replace("mail", []string{"alice@example.org"})
+	err := newConn(t).Modify(req)
+	require.NoError(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal("Modify", spans[0].Tag(ext.ResourceName))
+	assert.Equal("cn=alice,dc=example,dc=org", spans[0].Tag(tagDN))
+}
+
+func TestServiceName(t *testing.T) {
+	t.Run("global", func(t *testing.T) {
+		globalconfig.SetServiceName("global-service")
+		defer globalconfig.SetServiceName("")
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		require.NoError(t, newConn(t).Bind("cn=admin", "secret"))
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "global-service", spans[0].Tag(ext.ServiceName))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		require.NoError(t, newConn(t, WithServiceName("my-ldap")).Bind("cn=admin", "secret"))
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "my-ldap", spans[0].Tag(ext.ServiceName))
+	})
+}
@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package ldap
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+const (
+	defaultServiceName = "ldap"
+	operationName      = "ldap.request"
+)
+
+type config struct {
+	serviceName string
+}
+
+// Option represents an option that can be passed to WrapConn.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	cfg.serviceName = defaultServiceName
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+}
+
+// WithServiceName sets the given service name for the connection.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
@@ -12,12 +12,25 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
+	"github.com/DataDog/datadog-agent/pkg/obfuscate"
 	"github.com/go-pg/pg/v10"
+	"github.com/go-pg/pg/v10/orm"
 )
 
-// Wrap augments the given DB with tracing.
+const componentName = "go-pg/pg.v10"
+
+func init() {
+	integrations.Register(componentName, "github.com/go-pg/pg/v10")
+}
+
+// tagGopgModel holds the name of the model of a query.
+const tagGopgModel = "gopg.model"
+
+// Wrap augments the given DB with tracing. The queries are named after their formatted
+// statement, obfuscated to leave out its literals.
 func Wrap(db *pg.DB, opts ...Option) {
 	cfg := new(config)
 	defaults(cfg)
@@ -25,24 +38,28 @@ func Wrap(db *pg.DB, opts ...Option) {
 		opt(cfg)
 	}
 	log.Debug("contrib/go-pg/pg.v10: Wrapping Database")
-	db.AddQueryHook(&queryHook{cfg: cfg})
+	db.AddQueryHook(&queryHook{
+		cfg:        cfg,
+		obfuscator: obfuscate.NewObfuscator(obfuscate.Config{}),
+	})
 }
 
 type queryHook struct {
-	cfg *config
+	cfg        *config
+	obfuscator *obfuscate.Obfuscator
 }
 
 // BeforeQuery implements pg.QueryHook.
 func (h *queryHook) BeforeQuery(ctx context.Context, qe *pg.QueryEvent) (context.Context, error) {
-	query, err := qe.UnformattedQuery()
-	if err != nil {
-		query = []byte("unknown")
-	}
-
 	opts := []ddtrace.StartSpanOption{
 		tracer.SpanType(ext.SpanTypeSQL),
-		tracer.ResourceName(string(query)),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.ResourceName(h.resource(qe)),
 		tracer.ServiceName(h.cfg.serviceName),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if tm, ok := qe.Model.(orm.TableModel); ok && tm.Table() != nil {
+		opts = append(opts, tracer.Tag(tagGopgModel, tm.Table().TypeName))
 	}
 	if !math.IsNaN(h.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, h.cfg.analyticsRate))
@@ -54,8 +71,28 @@ func (h *queryHook) BeforeQuery(ctx context.Context, qe *pg.QueryEvent) (context
 // AfterQuery implements pg.QueryHook
 func (h *queryHook) AfterQuery(ctx context.Context, qe *pg.QueryEvent) error {
 	if span, ok := tracer.SpanFromContext(ctx); ok {
+		if qe.Err == nil && qe.Result != nil {
+			span.SetTag(ext.DBRowCount, qe.Result.RowsReturned())
+		}
 		span.Finish(tracer.WithError(qe.Err))
 	}
 
 	return qe.Err
 }
+
+// resource returns the obfuscated formatted query of qe, falling back to its unformatted
+// query if it can't be obfuscated.
+func (h *queryHook) resource(qe *pg.QueryEvent) string {
+	if query, err := qe.FormattedQuery(); err == nil && len(query) > 0 {
+		oq, err := h.obfuscator.ObfuscateSQLString(string(query))
+		if err == nil {
+			return oq.Query
+		}
+		log.Debug("contrib/go-pg/pg.v10: Error obfuscating query: %v", err)
+	}
+	query, err := qe.UnformattedQuery()
+	if err != nil {
+		return "unknown"
+	}
+	return string(query)
+}
@@ -15,13 +15,21 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/rediscmd"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 
 	"github.com/go-redis/redis/v7"
 )
 
+const componentName = "go-redis/redis.v7"
+
+func init() {
+	integrations.Register(componentName, "github.com/go-redis/redis/v7")
+}
+
 type datadogHook struct {
 	*params
 }
@@ -104,10 +112,17 @@ func (ddh *datadogHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (con
 	p := ddh.params
 	opts := []ddtrace.StartSpanOption{
 		tracer.SpanType(ext.SpanTypeRedis),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(p.config.serviceName),
 		tracer.ResourceName(parts[0]),
-		tracer.Tag("redis.raw_command", raw),
+		tracer.Tag("redis.raw_command", p.config.formatCommand(cmd)),
 		tracer.Tag("redis.args_length", strconv.Itoa(length)),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if sc, ok := rediscmd.ParseStreamCommand(cmd.Args()); ok {
+		for k, v := range sc.Tags() {
+			opts = append(opts, tracer.Tag(k, v))
+		}
 	}
 	opts = append(opts, ddh.additionalTags...)
 	if !math.IsNaN(p.config.analyticsRate) {
@@ -125,23 +140,34 @@ func (ddh *datadogHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error
 	if errRedis != redis.Nil {
 		finishOpts = append(finishOpts, tracer.WithError(errRedis))
 	}
+	if errRedis == nil {
+		tagStreamResult(span, cmd)
+	}
 	span.Finish(finishOpts...)
 	return nil
 }
 
 func (ddh *datadogHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
-	raw := commandsToString(cmds)
-	parts := strings.Split(raw, " ")
-	length := len(parts) - 1
 	p := ddh.params
+	raw := commandsToString(cmds, nil)
+	length := strings.Count(raw, " ")
+	if p.config.command.Enabled() {
+		// the resource is formatted like the raw command, so that it does not hold what the latter omits
+		raw = commandsToString(cmds, p.config)
+	}
+	resource := raw
+	if p.config.aggregatePipeline {
+		resource = rediscmd.PipelineResource(len(cmds))
+	}
 	opts := []ddtrace.StartSpanOption{
 		tracer.SpanType(ext.SpanTypeRedis),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(p.config.serviceName),
-		tracer.ResourceName(parts[0]),
+		tracer.ResourceName(resource),
 		tracer.Tag("redis.raw_command", raw),
 		tracer.Tag("redis.args_length", strconv.Itoa(length)),
-		tracer.Tag(ext.ResourceName, raw),
 		tracer.Tag("redis.pipeline_length", strconv.Itoa(len(cmds))),
+		tracer.Tag(ext.Component, componentName),
 	}
 	opts = append(opts, ddh.additionalTags...)
 	if !math.IsNaN(p.config.analyticsRate) {
@@ -166,11 +192,24 @@ func (ddh *datadogHook) AfterProcessPipeline(ctx context.Context, cmds []redis.C
 }
 
 // commandsToString returns a string representation of a slice of redis Commands, separated by newlines.
-func commandsToString(cmds []redis.Cmder) string {
+// When cfg is not nil, commands are formatted according to it.
+func commandsToString(cmds []redis.Cmder, cfg *clientConfig) string {
 	var b bytes.Buffer
 	for _, cmd := range cmds {
-		b.WriteString(cmd.String())
+		if cfg != nil {
+			b.WriteString(cfg.formatCommand(cmd))
+		} else {
+			b.WriteString(cmd.String())
+		}
 		b.WriteString("\n")
 	}
 	return b.String()
 }
+
+// formatCommand returns the string representation of cmd used for the "redis.raw_command" tag.
+func (cfg *clientConfig) formatCommand(cmd redis.Cmder) string {
+	if !cfg.command.Enabled() {
+		return cmd.String()
+	}
+	return cfg.command.Format(cmd.Args())
+}
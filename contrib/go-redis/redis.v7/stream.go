@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package redis
+
+import (
+	"context"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/messaging"
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/rediscmd"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/go-redis/redis/v7"
+)
+
+// InjectStreamContext injects the context of the span found in ctx into values, the fields of
+// a stream entry about to be added with XADD, so that the consumers of the entry can continue
+// the trace with StartStreamEntrySpan. The context is stored into fields named after the
+// propagation headers, e.g. "x-datadog-trace-id".
+func InjectStreamContext(ctx context.Context, values map[string]interface{}) error {
+	span, _ := tracer.SpanFromContext(ctx)
+	return tracer.Inject(span.Context(), messaging.Carrier{Headers: &rediscmd.StreamEntry{Values: values}})
+}
+
+// StartStreamEntrySpan starts the span of the processing of msg, an entry of stream read by
+// the consumer group group, which is empty for entries read with XREAD. If the entry holds a
+// span context injected by InjectStreamContext, it is used as the parent of the span. The
+// span must be finished once the entry is processed.
+func StartStreamEntrySpan(ctx context.Context, stream, group string, msg redis.XMessage, opts ...ClientOption) (ddtrace.Span, context.Context) {
+	cfg := new(clientConfig)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	tags := []ddtrace.StartSpanOption{
+		tracer.Tag(rediscmd.TagStream, stream),
+		tracer.Tag(rediscmd.TagEntryID, msg.ID),
+	}
+	if group != "" {
+		tags = append(tags, tracer.Tag(rediscmd.TagConsumerGroup, group))
+	}
+	return messaging.StartConsumeSpan(ctx, messaging.Operation{
+		System:        "redis",
+		Destination:   stream,
+		Resource:      "Consume Stream " + stream,
+		ServiceName:   cfg.serviceName,
+		AnalyticsRate: cfg.analyticsRate,
+		Component:     componentName,
+		Options:       tags,
+	}, &rediscmd.StreamEntry{Values: msg.Values})
+}
+
+// StartMessageSpan starts the span of the processing of msg, a message received on a Pub/Sub
+// channel. The span must be finished once the message is processed.
+func StartMessageSpan(ctx context.Context, msg *redis.Message, opts ...ClientOption) (ddtrace.Span, context.Context) {
+	cfg := new(clientConfig)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	tags := []ddtrace.StartSpanOption{tracer.Tag(rediscmd.TagChannel, msg.Channel)}
+	if msg.Pattern != "" {
+		tags = append(tags, tracer.Tag(rediscmd.TagPattern, msg.Pattern))
+	}
+	// Pub/Sub messages have no fields to carry a span context
+	return messaging.StartConsumeSpan(ctx, messaging.Operation{
+		System:        "redis",
+		Destination:   msg.Channel,
+		Resource:      "Consume Channel " + msg.Channel,
+		ServiceName:   cfg.serviceName,
+		AnalyticsRate: cfg.analyticsRate,
+		Component:     componentName,
+		Options:       tags,
+	}, &rediscmd.StreamEntry{})
+}
+
+// tagStreamResult tags span with the results of the successful stream command cmd.
+func tagStreamResult(span ddtrace.Span, cmd redis.Cmder) {
+	switch cmd := cmd.(type) {
+	case *redis.XStreamSliceCmd:
+		n := 0
+		for _, s := range cmd.Val() {
+			n += len(s.Messages)
+		}
+		span.SetTag(rediscmd.TagEntries, n)
+	case *redis.XPendingCmd:
+		if p := cmd.Val(); p != nil {
+			span.SetTag(rediscmd.TagPending, p.Count)
+		}
+	case *redis.IntCmd:
+		if strings.EqualFold(cmd.Name(), "xack") {
+			span.SetTag(rediscmd.TagAcked, cmd.Val())
+		}
+	}
+}
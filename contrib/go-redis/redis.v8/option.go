@@ -8,13 +8,16 @@ package redis
 import (
 	"math"
 
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/rediscmd"
 	"github.com/codebrick-corp/dd-trace-go/internal"
 )
 
 type clientConfig struct {
-	serviceName   string
-	analyticsRate float64
-	skipRaw       bool
+	serviceName       string
+	analyticsRate     float64
+	skipRaw           bool
+	command           rediscmd.Config
+	aggregatePipeline bool
 }
 
 // ClientOption represents an option that can be used to create or wrap a client.
@@ -39,6 +42,40 @@ func WithSkipRawCommand(skip bool) ClientOption {
 	}
 }
 
+// WithSkipCommandArgs reports whether to omit the arguments of commands from the
+// "redis.raw_command" tag, recording only the command names.
+func WithSkipCommandArgs(skip bool) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.command.SkipArgs = skip
+	}
+}
+
+// WithKeyHashing reports whether to replace the keys found in the "redis.raw_command"
+// tag by a hash of their value. It is useful to avoid leaking sensitive data
+// which is part of keys, such as user identifiers.
+func WithKeyHashing(enabled bool) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.command.HashKeys = enabled
+	}
+}
+
+// WithMaxKeyLength truncates the keys found in the "redis.raw_command" tag to at
+// most n bytes. A value of zero or less disables truncation.
+func WithMaxKeyLength(n int) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.command.MaxKeyLength = n
+	}
+}
+
+// WithPipelineAggregation reports whether pipelines should be reported under a single
+// resource name built from the number of commands they contain, e.g. "pipeline(12 cmds)",
+// instead of the name of their first command.
+func WithPipelineAggregation(enabled bool) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.aggregatePipeline = enabled
+	}
+}
+
 // WithServiceName sets the given service name for the client.
 func WithServiceName(name string) ClientOption {
 	return func(cfg *clientConfig) {
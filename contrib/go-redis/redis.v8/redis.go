@@ -16,13 +16,21 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/rediscmd"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 
 	"github.com/go-redis/redis/v8"
 )
 
+const componentName = "go-redis/redis.v8"
+
+func init() {
+	integrations.Register(componentName, "github.com/go-redis/redis/v8")
+}
+
 type datadogHook struct {
 	*params
 }
@@ -102,15 +110,22 @@ func (ddh *datadogHook) BeforeProcess(ctx context.Context, cmd redis.Cmder) (con
 	raw := cmd.String()
 	length := strings.Count(raw, " ")
 	p := ddh.params
-	opts := make([]ddtrace.StartSpanOption, 0, 4+1+len(ddh.additionalTags)+1) // 4 options below + redis.raw_command + ddh.additionalTags + analyticsRate
+	opts := make([]ddtrace.StartSpanOption, 0, 6+1+len(ddh.additionalTags)+1) // 6 options below + redis.raw_command + ddh.additionalTags + analyticsRate
 	opts = append(opts,
 		tracer.SpanType(ext.SpanTypeRedis),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(p.config.serviceName),
 		tracer.ResourceName(raw[:strings.IndexByte(raw, ' ')]),
 		tracer.Tag("redis.args_length", strconv.Itoa(length)),
+		tracer.Tag(ext.Component, componentName),
 	)
 	if !p.config.skipRaw {
-		opts = append(opts, tracer.Tag("redis.raw_command", raw))
+		opts = append(opts, tracer.Tag("redis.raw_command", p.config.formatCommand(cmd)))
+	}
+	if sc, ok := rediscmd.ParseStreamCommand(cmd.Args()); ok {
+		for k, v := range sc.Tags() {
+			opts = append(opts, tracer.Tag(k, v))
+		}
 	}
 	opts = append(opts, ddh.additionalTags...)
 	if !math.IsNaN(p.config.analyticsRate) {
@@ -128,23 +143,35 @@ func (ddh *datadogHook) AfterProcess(ctx context.Context, cmd redis.Cmder) error
 	if errRedis != redis.Nil {
 		finishOpts = append(finishOpts, tracer.WithError(errRedis))
 	}
+	if errRedis == nil {
+		tagStreamResult(span, cmd)
+	}
 	span.Finish(finishOpts...)
 	return nil
 }
 
 func (ddh *datadogHook) BeforeProcessPipeline(ctx context.Context, cmds []redis.Cmder) (context.Context, error) {
-	raw := commandsToString(cmds)
+	raw := commandsToString(cmds, nil)
 	length := strings.Count(raw, " ")
 	p := ddh.params
-	opts := make([]ddtrace.StartSpanOption, 0, 5+1+len(ddh.additionalTags)+1) // 5 options below + redis.raw_command + ddh.additionalTags + analyticsRate
+	resource := raw[:strings.IndexByte(raw, ' ')]
+	if p.config.aggregatePipeline {
+		resource = rediscmd.PipelineResource(len(cmds))
+	}
+	opts := make([]ddtrace.StartSpanOption, 0, 7+1+len(ddh.additionalTags)+1) // 7 options below + redis.raw_command + ddh.additionalTags + analyticsRate
 	opts = append(opts,
 		tracer.SpanType(ext.SpanTypeRedis),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(p.config.serviceName),
-		tracer.ResourceName(raw[:strings.IndexByte(raw, ' ')]),
+		tracer.ResourceName(resource),
 		tracer.Tag("redis.args_length", strconv.Itoa(length)),
 		tracer.Tag("redis.pipeline_length", strconv.Itoa(len(cmds))),
+		tracer.Tag(ext.Component, componentName),
 	)
 	if !p.config.skipRaw {
+		if p.config.command.Enabled() {
+			raw = commandsToString(cmds, p.config)
+		}
 		opts = append(opts, tracer.Tag("redis.raw_command", raw))
 	}
 	opts = append(opts, ddh.additionalTags...)
@@ -170,11 +197,24 @@ func (ddh *datadogHook) AfterProcessPipeline(ctx context.Context, cmds []redis.C
 }
 
 // commandsToString returns a string representation of a slice of redis Commands, separated by newlines.
-func commandsToString(cmds []redis.Cmder) string {
+// When cfg is not nil, commands are formatted according to it.
+func commandsToString(cmds []redis.Cmder, cfg *clientConfig) string {
 	var b bytes.Buffer
 	for _, cmd := range cmds {
-		b.WriteString(cmd.String())
+		if cfg != nil {
+			b.WriteString(cfg.formatCommand(cmd))
+		} else {
+			b.WriteString(cmd.String())
+		}
 		b.WriteString("\n")
 	}
 	return b.String()
 }
+
+// formatCommand returns the string representation of cmd used for the "redis.raw_command" tag.
+func (cfg *clientConfig) formatCommand(cmd redis.Cmder) string {
+	if !cfg.command.Enabled() {
+		return cmd.String()
+	}
+	return cfg.command.Format(cmd.Args())
+}
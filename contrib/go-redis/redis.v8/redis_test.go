@@ -81,6 +81,50 @@ func TestSkipRaw(t *testing.T) {
 	})
 }
 
+func TestCommandObfuscation(t *testing.T) {
+	runCmds := func(t *testing.T, opts ...ClientOption) []mocktracer.Span {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		ctx := context.Background()
+		client := NewClient(&redis.Options{Addr: "127.0.0.1:6379"}, opts...)
+		client.Set(ctx, "test_key", "test_value", 0)
+		pipeline := client.Pipeline()
+		pipeline.Expire(ctx, "pipeline_counter", time.Hour)
+		pipeline.Get(ctx, "test_key")
+		pipeline.Exec(ctx)
+		spans := mt.FinishedSpans()
+		assert.Len(t, spans, 2)
+		return spans
+	}
+
+	t.Run("skip-args", func(t *testing.T) {
+		spans := runCmds(t, WithSkipCommandArgs(true))
+		assert.Equal(t, "set", spans[0].Tag("redis.raw_command"))
+		assert.Equal(t, "set", spans[0].Tag(ext.ResourceName))
+		assert.Equal(t, "expire\nget\n", spans[1].Tag("redis.raw_command"))
+		assert.Equal(t, "expire", spans[1].Tag(ext.ResourceName))
+	})
+
+	t.Run("hash-keys", func(t *testing.T) {
+		spans := runCmds(t, WithKeyHashing(true))
+		raw := spans[0].Tag("redis.raw_command").(string)
+		assert.NotContains(t, raw, "test_key")
+		assert.Regexp(t, `^set [0-9a-f]+ test_value$`, raw)
+	})
+
+	t.Run("max-key-length", func(t *testing.T) {
+		spans := runCmds(t, WithMaxKeyLength(4))
+		assert.Equal(t, "set test... test_value", spans[0].Tag("redis.raw_command"))
+		assert.Equal(t, "expire pipe... 3600\nget test...\n", spans[1].Tag("redis.raw_command"))
+	})
+
+	t.Run("pipeline-aggregation", func(t *testing.T) {
+		spans := runCmds(t, WithPipelineAggregation(true))
+		assert.Equal(t, "set", spans[0].Tag(ext.ResourceName))
+		assert.Equal(t, "pipeline(2 cmds)", spans[1].Tag(ext.ResourceName))
+	})
+}
+
 func TestClientEvalSha(t *testing.T) {
 	ctx := context.Background()
 	opts := &redis.Options{Addr: "127.0.0.1:6379"}
@@ -509,3 +553,94 @@ func TestWithContext(t *testing.T) {
 	assert.Equal(span1.SpanID(), setSpan.ParentID())
 	assert.Equal(span2.SpanID(), getSpan.ParentID())
 }
+
+func TestStreams(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	client := NewClient(&redis.Options{Addr: "127.0.0.1:6379"})
+	client.Del(ctx, "test_stream")
+	assert.NoError(client.XGroupCreateMkStream(ctx, "test_stream", "test_group", "$").Err())
+	defer client.Del(ctx, "test_stream")
+	mt.Reset()
+
+	root, rctx := tracer.StartSpanFromContext(ctx, "producer")
+	values := map[string]interface{}{"order": "42"}
+	assert.NoError(InjectStreamContext(rctx, values))
+	assert.NoError(client.XAdd(rctx, &redis.XAddArgs{Stream: "test_stream", Values: values}).Err())
+	root.Finish()
+
+	streams, err := client.XReadGroup(ctx, &redis.XReadGroupArgs{
+		Group:    "test_group",
+		Consumer: "test_consumer",
+		Streams:  []string{"test_stream", ">"},
+		Count:    1,
+	}).Result()
+	assert.NoError(err)
+	assert.Len(streams, 1)
+	assert.Len(streams[0].Messages, 1)
+	msg := streams[0].Messages[0]
+	assert.Equal("42", msg.Values["order"])
+	span, _ := StartStreamEntrySpan(ctx, "test_stream", "test_group", msg, WithServiceName("my-consumer"))
+	span.Finish()
+	assert.NoError(client.XAck(ctx, "test_stream", "test_group", msg.ID).Err())
+	assert.NoError(client.XPending(ctx, "test_stream", "test_group").Err())
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 6)
+	xadd, producer, xreadgroup, consume, xack, xpending := spans[0], spans[1], spans[2], spans[3], spans[4], spans[5]
+
+	assert.Equal("xadd", xadd.Tag(ext.ResourceName))
+	assert.Equal("test_stream", xadd.Tag("redis.stream"))
+	assert.Equal(producer.SpanID(), xadd.ParentID())
+
+	assert.Equal("xreadgroup", xreadgroup.Tag(ext.ResourceName))
+	assert.Equal("test_stream", xreadgroup.Tag("redis.stream"))
+	assert.Equal("test_group", xreadgroup.Tag("redis.consumer_group"))
+	assert.Equal("test_consumer", xreadgroup.Tag("redis.consumer"))
+	assert.Equal(1, xreadgroup.Tag("redis.stream.entries"))
+
+	assert.Equal("redis.consume", consume.OperationName())
+	assert.Equal("Consume Stream test_stream", consume.Tag(ext.ResourceName))
+	assert.Equal("my-consumer", consume.Tag(ext.ServiceName))
+	assert.Equal(ext.SpanTypeMessageConsumer, consume.Tag(ext.SpanType))
+	assert.Equal(msg.ID, consume.Tag("redis.stream.entry_id"))
+	assert.Equal("test_group", consume.Tag("redis.consumer_group"))
+	assert.Equal(producer.TraceID(), consume.TraceID())
+	assert.Equal(producer.SpanID(), consume.ParentID())
+
+	assert.Equal("xack", xack.Tag(ext.ResourceName))
+	assert.Equal(int64(1), xack.Tag("redis.stream.acked"))
+
+	assert.Equal("xpending", xpending.Tag(ext.ResourceName))
+	assert.Equal(int64(0), xpending.Tag("redis.stream.pending"))
+}
+
+func TestPubSub(t *testing.T) {
+	ctx := context.Background()
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	client := NewClient(&redis.Options{Addr: "127.0.0.1:6379"})
+	pubsub := client.Subscribe(ctx, "test_channel")
+	defer pubsub.Close()
+	_, err := pubsub.Receive(ctx)
+	assert.NoError(err)
+	mt.Reset()
+
+	assert.NoError(client.Publish(ctx, "test_channel", "hello").Err())
+	msg := <-pubsub.Channel()
+	span, _ := StartMessageSpan(ctx, msg)
+	span.Finish()
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 2)
+	assert.Equal("publish", spans[0].Tag(ext.ResourceName))
+	assert.Equal("test_channel", spans[0].Tag("redis.channel"))
+	assert.Equal("redis.consume", spans[1].OperationName())
+	assert.Equal("Consume Channel test_channel", spans[1].Tag(ext.ResourceName))
+	assert.Equal("test_channel", spans[1].Tag("redis.channel"))
+}
@@ -16,14 +16,22 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/rediscmd"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/go-redis/redis"
 )
 
+const componentName = "go-redis/redis"
+
+func init() {
+	integrations.Register(componentName, "github.com/go-redis/redis")
+}
+
 // Client is used to trace requests to a redis server.
 type Client struct {
 	*redis.Client
@@ -118,18 +126,24 @@ func (c *Pipeliner) execWithContext(ctx context.Context) ([]redis.Cmder, error)
 	p := c.params
 	opts := []ddtrace.StartSpanOption{
 		tracer.SpanType(ext.SpanTypeRedis),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(p.config.serviceName),
 		tracer.ResourceName("redis"),
 		tracer.Tag(ext.TargetHost, p.host),
 		tracer.Tag(ext.TargetPort, p.port),
 		tracer.Tag("out.db", p.db),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(p.config.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, p.config.analyticsRate))
 	}
 	span, _ := tracer.StartSpanFromContext(ctx, "redis.command", opts...)
 	cmds, err := c.Pipeliner.Exec()
-	span.SetTag(ext.ResourceName, commandsToString(cmds))
+	if p.config.aggregatePipeline {
+		span.SetTag(ext.ResourceName, rediscmd.PipelineResource(len(cmds)))
+	} else {
+		span.SetTag(ext.ResourceName, commandsToString(cmds, p.config))
+	}
 	span.SetTag("redis.pipeline_length", strconv.Itoa(len(cmds)))
 	var finishOpts []ddtrace.FinishOption
 	if err != redis.Nil {
@@ -141,10 +155,11 @@ func (c *Pipeliner) execWithContext(ctx context.Context) ([]redis.Cmder, error)
 }
 
 // commandsToString returns a string representation of a slice of redis Commands, separated by newlines.
-func commandsToString(cmds []redis.Cmder) string {
+// The commands are formatted according to cfg.
+func commandsToString(cmds []redis.Cmder, cfg *clientConfig) string {
 	var b bytes.Buffer
 	for _, cmd := range cmds {
-		b.WriteString(cmderToString(cmd))
+		b.WriteString(cfg.formatCommand(cmd))
 		b.WriteString("\n")
 	}
 	return b.String()
@@ -186,13 +201,15 @@ func createWrapperFromClient(tc *Client) func(oldProcess func(cmd redis.Cmder) e
 			p := tc.params
 			opts := []ddtrace.StartSpanOption{
 				tracer.SpanType(ext.SpanTypeRedis),
+				tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 				tracer.ServiceName(p.config.serviceName),
 				tracer.ResourceName(parts[0]),
 				tracer.Tag(ext.TargetHost, p.host),
 				tracer.Tag(ext.TargetPort, p.port),
 				tracer.Tag("out.db", p.db),
-				tracer.Tag("redis.raw_command", raw),
+				tracer.Tag("redis.raw_command", p.config.formatCommand(cmd)),
 				tracer.Tag("redis.args_length", strconv.Itoa(length)),
+				tracer.Tag(ext.Component, componentName),
 			}
 			if !math.IsNaN(p.config.analyticsRate) {
 				opts = append(opts, tracer.Tag(ext.EventSampleRate, p.config.analyticsRate))
@@ -233,3 +250,11 @@ func cmderToString(cmd redis.Cmder) string {
 	}
 	return ""
 }
+
+// formatCommand returns the string representation of cmd used for the "redis.raw_command" tag.
+func (cfg *clientConfig) formatCommand(cmd redis.Cmder) string {
+	if !cfg.command.Enabled() {
+		return cmderToString(cmd)
+	}
+	return cfg.command.Format(cmd.Args())
+}
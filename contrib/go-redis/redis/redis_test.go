@@ -33,6 +33,47 @@ func TestMain(m *testing.M) {
 	os.Exit(m.Run())
 }
 
+func TestCommandObfuscation(t *testing.T) {
+	runCmds := func(t *testing.T, opts ...ClientOption) []mocktracer.Span {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		client := NewClient(&redis.Options{Addr: "127.0.0.1:6379"}, opts...)
+		client.Set("test_key", "test_value", 0)
+		pipeline := client.Pipeline()
+		pipeline.Expire("pipeline_counter", time.Hour)
+		pipeline.Get("test_key")
+		pipeline.Exec()
+		spans := mt.FinishedSpans()
+		assert.Len(t, spans, 2)
+		return spans
+	}
+
+	t.Run("skip-args", func(t *testing.T) {
+		spans := runCmds(t, WithSkipCommandArgs(true))
+		assert.Equal(t, "set", spans[0].Tag("redis.raw_command"))
+		assert.Equal(t, "set", spans[0].Tag(ext.ResourceName))
+		assert.Equal(t, "expire\nget\n", spans[1].Tag(ext.ResourceName))
+	})
+
+	t.Run("hash-keys", func(t *testing.T) {
+		spans := runCmds(t, WithKeyHashing(true))
+		assert.Regexp(t, `^set [0-9a-f]+ test_value$`, spans[0].Tag("redis.raw_command"))
+		assert.Regexp(t, `^expire [0-9a-f]+ 3600\nget [0-9a-f]+\n$`, spans[1].Tag(ext.ResourceName))
+	})
+
+	t.Run("max-key-length", func(t *testing.T) {
+		spans := runCmds(t, WithMaxKeyLength(4))
+		assert.Equal(t, "set test... test_value", spans[0].Tag("redis.raw_command"))
+		assert.Equal(t, "expire pipe... 3600\nget test...\n", spans[1].Tag(ext.ResourceName))
+	})
+
+	t.Run("pipeline-aggregation", func(t *testing.T) {
+		spans := runCmds(t, WithPipelineAggregation(true))
+		assert.Equal(t, "set", spans[0].Tag(ext.ResourceName))
+		assert.Equal(t, "pipeline(2 cmds)", spans[1].Tag(ext.ResourceName))
+	})
+}
+
 func TestClientEvalSha(t *testing.T) {
 	opts := &redis.Options{Addr: "127.0.0.1:6379"}
 	assert := assert.New(t)
@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package goa_test
+
+import (
+	"context"
+	"net/http"
+
+	goatrace "github.com/codebrick-corp/dd-trace-go/contrib/goadesign/goa.v3"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	goa "goa.design/goa/v3/pkg"
+)
+
+// endpoints stands for the endpoints generated by goa for a service.
+type endpoints struct {
+	Multiply goa.Endpoint
+}
+
+func (e *endpoints) Use(m func(goa.Endpoint) goa.Endpoint) {
+	e.Multiply = m(e.Multiply)
+}
+
+func Example() {
+	// Start the tracer
+	tracer.Start()
+	defer tracer.Stop()
+
+	e := &endpoints{
+		Multiply: func(ctx context.Context, req any) (any, error) {
+			return req.(int) * 2, nil
+		},
+	}
+	// Trace the calls to the endpoints of the service.
+	e.Use(goatrace.Middleware(goatrace.WithServiceName("calc")))
+
+	// Trace the requests served by the HTTP server generated by goa from e,
+	// which handler stands for.
+	handler := http.NotFoundHandler()
+	handler = goatrace.HTTPMiddleware()(handler)
+	http.ListenAndServe(":8080", handler)
+}
@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package goa provides functions to trace the services generated by the goadesign/goa package
+// (https://github.com/goadesign/goa).
+//
+// Middleware traces the calls to the endpoints of a service, named after the service and the
+// method of the design, e.g. "calc.multiply". The trace is propagated by the transports:
+// HTTPMiddleware traces the requests served by the HTTP servers of goa, which are then named
+// after the called method as well, while the gRPC servers are traced by the interceptors of
+// the contrib/google.golang.org/grpc package.
+package goa // import "github.com/codebrick-corp/dd-trace-go/contrib/goadesign/goa.v3"
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	httptrace "github.com/codebrick-corp/dd-trace-go/contrib/net/http"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	goa "goa.design/goa/v3/pkg"
+)
+
+const componentName = "goadesign/goa.v3"
+
+func init() {
+	integrations.Register(componentName, "goa.design/goa/v3")
+}
+
+const (
+	// tagService holds the name of the service of the design.
+	tagService = "goa.service"
+	// tagMethod holds the name of the method of the design.
+	tagMethod = "goa.method"
+	// tagErrorName holds the name of the errors of the design.
+	tagErrorName = "goa.error.name"
+)
+
+// requestSpanKey is the context key of the span started by HTTPMiddleware.
+type requestSpanKey struct{}
+
+// Middleware returns an endpoint middleware tracing the calls to the endpoints of a service,
+// to be given to the Use method of the endpoints generated by goa. The errors of the design
+// only make the spans errors when they are faults.
+func Middleware(opts ...Option) func(goa.Endpoint) goa.Endpoint {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/goadesign/goa.v3: Configuring Middleware: %#v", cfg)
+	return func(next goa.Endpoint) goa.Endpoint {
+		return func(ctx context.Context, request any) (any, error) {
+			service, _ := ctx.Value(goa.ServiceKey).(string)
+			method, _ := ctx.Value(goa.MethodKey).(string)
+			resource := service + "." + method
+			if span, ok := ctx.Value(requestSpanKey{}).(ddtrace.Span); ok {
+				span.SetTag(ext.ResourceName, resource)
+			}
+			opts := []ddtrace.StartSpanOption{
+				tracer.ServiceName(cfg.serviceName),
+				tracer.ResourceName(resource),
+				tracer.SpanType(ext.AppTypeRPC),
+				tracer.Tag(tagService, service),
+				tracer.Tag(tagMethod, method),
+				tracer.Measured(),
+				tracer.Tag(ext.Component, componentName),
+			}
+			opts = append(opts, cfg.spanOpts...)
+			span, ctx := tracer.StartSpanFromContext(ctx, "goa.endpoint", opts...)
+			response, err := next(ctx, request)
+			var serr *goa.ServiceError
+			if errors.As(err, &serr) {
+				span.SetTag(tagErrorName, serr.Name)
+				if !serr.Fault {
+					span.Finish()
+					return response, err
+				}
+			}
+			span.Finish(tracer.WithError(err))
+			return response, err
+		}
+	}
+}
+
+// HTTPMiddleware returns a middleware tracing the requests served by an HTTP server generated
+// by goa, to be given to its Use method. The requests are named after the method of the
+// design they call when the endpoints of the service are traced by Middleware.
+func HTTPMiddleware(opts ...Option) func(http.Handler) http.Handler {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/goadesign/goa.v3: Configuring HTTPMiddleware: %#v", cfg)
+	spanOpts := append([]ddtrace.StartSpanOption{tracer.Tag(ext.Component, componentName)}, cfg.spanOpts...)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				span, _ := tracer.SpanFromContext(r.Context())
+				next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestSpanKey{}, span)))
+			})
+			httptrace.TraceAndServe(h, w, r, &httptrace.ServeConfig{
+				Service: cfg.serviceName,
+				// renamed by Middleware after the called method
+				Resource: r.Method,
+				SpanOpts: spanOpts,
+			})
+		})
+	}
+}
@@ -0,0 +1,101 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package goa
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/stretchr/testify/assert"
+	goa "goa.design/goa/v3/pkg"
+)
+
+// methodContext returns a copy of ctx holding the service and method names as set by goa.
+func methodContext(ctx context.Context, service, method string) context.Context {
+	ctx = context.WithValue(ctx, goa.ServiceKey, service)
+	return context.WithValue(ctx, goa.MethodKey, method)
+}
+
+func TestMiddleware(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	ep := Middleware(WithServiceName("calc"))(func(ctx context.Context, req any) (any, error) {
+		_, ok := tracer.SpanFromContext(ctx)
+		assert.True(ok)
+		return req.(int) * 2, nil
+	})
+	res, err := ep(methodContext(context.Background(), "calc", "multiply"), 21)
+	assert.NoError(err)
+	assert.Equal(42, res)
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 1)
+	span := spans[0]
+	assert.Equal("goa.endpoint", span.OperationName())
+	assert.Equal("calc.multiply", span.Tag(ext.ResourceName))
+	assert.Equal("calc", span.Tag(ext.ServiceName))
+	assert.Equal("calc", span.Tag(tagService))
+	assert.Equal("multiply", span.Tag(tagMethod))
+}
+
+func TestErrors(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	for _, err := range []error{
+		goa.PermanentError("not_found", "no such operand"),
+		goa.Fault("overflow"),
+		errors.New("oh no"),
+	} {
+		ep := Middleware()(func(ctx context.Context, req any) (any, error) {
+			return nil, err
+		})
+		_, got := ep(methodContext(context.Background(), "calc", "multiply"), nil)
+		assert.Equal(err, got)
+	}
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 3)
+	assert.Equal("not_found", spans[0].Tag(tagErrorName))
+	assert.Nil(spans[0].Tag(ext.Error))
+	assert.Equal("fault", spans[1].Tag(tagErrorName))
+	assert.NotNil(spans[1].Tag(ext.Error))
+	assert.NotNil(spans[2].Tag(ext.Error))
+}
+
+func TestHTTPMiddleware(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	ep := Middleware()(func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	})
+	// a handler calling the endpoint the way the servers generated by goa do
+	h := HTTPMiddleware()(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ep(methodContext(r.Context(), "calc", "multiply"), nil)
+		w.WriteHeader(http.StatusOK)
+	}))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/multiply/21", nil))
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 2)
+	endpoint, request := spans[0], spans[1]
+	assert.Equal("http.request", request.OperationName())
+	assert.Equal("calc.multiply", request.Tag(ext.ResourceName))
+	assert.Equal("/multiply/21", request.Tag(ext.HTTPURL))
+	assert.Equal(request.SpanID(), endpoint.ParentID())
+}
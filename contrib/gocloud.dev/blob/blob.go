@@ -0,0 +1,226 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package blob provides functions to trace the gocloud.dev/blob package (https://gocloud.dev/howto/blob/),
+// whichever the driver of the buckets.
+package blob // import "github.com/codebrick-corp/dd-trace-go/contrib/gocloud.dev/blob"
+
+import (
+	"context"
+	"io"
+	"net/url"
+	"sync"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"gocloud.dev/blob"
+)
+
+const componentName = "gocloud.dev/blob"
+
+func init() {
+	integrations.Register(componentName, "gocloud.dev/blob")
+}
+
+const (
+	// tagKey holds the key of the blob.
+	tagKey = "blob.key"
+	// tagSize holds the size of the blob, in bytes.
+	tagSize = "blob.size"
+	// tagBytesWritten holds the number of bytes written to the blob.
+	tagBytesWritten = "blob.bytes_written"
+)
+
+// Bucket is a blob.Bucket whose operations are traced. Listing objects is not traced.
+type Bucket struct {
+	*blob.Bucket
+	cfg *config
+}
+
+// OpenBucket opens the bucket identified by urlstr with blob.OpenBucket and wraps it with
+// WrapBucket. The resource name of its spans defaults to urlstr without its query.
+func OpenBucket(ctx context.Context, urlstr string, opts ...Option) (*Bucket, error) {
+	b, err := blob.OpenBucket(ctx, urlstr)
+	if err != nil {
+		return nil, err
+	}
+	return WrapBucket(b, append([]Option{WithResourceName(urlResource(urlstr))}, opts...)...), nil
+}
+
+// WrapBucket wraps b so that its operations are traced.
+func WrapBucket(b *blob.Bucket, opts ...Option) *Bucket {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/gocloud.dev/blob: Wrapping Bucket: %#v", cfg)
+	return &Bucket{Bucket: b, cfg: cfg}
+}
+
+// startSpan starts the span of the operation op on the blob with the given key.
+func (b *Bucket) startSpan(ctx context.Context, op, key string) (ddtrace.Span, context.Context) {
+	opts := []ddtrace.StartSpanOption{
+		tracer.ServiceName(b.cfg.serviceName),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if key != "" {
+		opts = append(opts, tracer.Tag(tagKey, key))
+	}
+	if b.cfg.resourceName != "" {
+		opts = append(opts, tracer.ResourceName(b.cfg.resourceName))
+	}
+	return tracer.StartSpanFromContext(ctx, "gocloud.blob."+op, opts...)
+}
+
+// ReadAll calls blob.Bucket.ReadAll, tracing it.
+func (b *Bucket) ReadAll(ctx context.Context, key string) ([]byte, error) {
+	span, ctx := b.startSpan(ctx, "read", key)
+	p, err := b.Bucket.ReadAll(ctx, key)
+	if err == nil {
+		span.SetTag(tagSize, len(p))
+	}
+	span.Finish(tracer.WithError(err))
+	return p, err
+}
+
+// NewReader calls blob.Bucket.NewReader. The read is traced until the returned Reader
+// is closed.
+func (b *Bucket) NewReader(ctx context.Context, key string, opts *blob.ReaderOptions) (*Reader, error) {
+	return b.NewRangeReader(ctx, key, 0, -1, opts)
+}
+
+// NewRangeReader calls blob.Bucket.NewRangeReader. The read is traced until the returned
+// Reader is closed.
+func (b *Bucket) NewRangeReader(ctx context.Context, key string, offset, length int64, opts *blob.ReaderOptions) (*Reader, error) {
+	span, ctx := b.startSpan(ctx, "read", key)
+	r, err := b.Bucket.NewRangeReader(ctx, key, offset, length, opts)
+	if err != nil {
+		span.Finish(tracer.WithError(err))
+		return nil, err
+	}
+	span.SetTag(tagSize, r.Size())
+	return &Reader{Reader: r, span: span}, nil
+}
+
+// Reader is a blob.Reader finishing the span of the read when closed.
+type Reader struct {
+	*blob.Reader
+	span ddtrace.Span
+	once sync.Once
+}
+
+// Close calls blob.Reader.Close and finishes the span of the read.
+func (r *Reader) Close() error {
+	err := r.Reader.Close()
+	r.once.Do(func() {
+		r.span.Finish(tracer.WithError(err))
+	})
+	return err
+}
+
+// WriteAll calls blob.Bucket.WriteAll, tracing it.
+func (b *Bucket) WriteAll(ctx context.Context, key string, p []byte, opts *blob.WriterOptions) error {
+	span, ctx := b.startSpan(ctx, "write", key)
+	err := b.Bucket.WriteAll(ctx, key, p, opts)
+	span.SetTag(tagBytesWritten, len(p))
+	span.Finish(tracer.WithError(err))
+	return err
+}
+
+// NewWriter calls blob.Bucket.NewWriter. The write is traced until the returned Writer
+// is closed, which is when the blob is committed.
+func (b *Bucket) NewWriter(ctx context.Context, key string, opts *blob.WriterOptions) (*Writer, error) {
+	span, ctx := b.startSpan(ctx, "write", key)
+	w, err := b.Bucket.NewWriter(ctx, key, opts)
+	if err != nil {
+		span.Finish(tracer.WithError(err))
+		return nil, err
+	}
+	return &Writer{Writer: w, span: span}, nil
+}
+
+// Writer is a blob.Writer finishing the span of the write when closed.
+type Writer struct {
+	*blob.Writer
+	span ddtrace.Span
+	once sync.Once
+	n    int64
+}
+
+// Write implements io.Writer.
+func (w *Writer) Write(p []byte) (int, error) {
+	n, err := w.Writer.Write(p)
+	w.n += int64(n)
+	return n, err
+}
+
+// ReadFrom implements io.ReaderFrom.
+func (w *Writer) ReadFrom(r io.Reader) (int64, error) {
+	n, err := w.Writer.ReadFrom(r)
+	w.n += n
+	return n, err
+}
+
+// Close calls blob.Writer.Close and finishes the span of the write.
+func (w *Writer) Close() error {
+	err := w.Writer.Close()
+	w.once.Do(func() {
+		w.span.SetTag(tagBytesWritten, w.n)
+		w.span.Finish(tracer.WithError(err))
+	})
+	return err
+}
+
+// Delete calls blob.Bucket.Delete, tracing it.
+func (b *Bucket) Delete(ctx context.Context, key string) error {
+	span, ctx := b.startSpan(ctx, "delete", key)
+	err := b.Bucket.Delete(ctx, key)
+	span.Finish(tracer.WithError(err))
+	return err
+}
+
+// Attributes calls blob.Bucket.Attributes, tracing it.
+func (b *Bucket) Attributes(ctx context.Context, key string) (*blob.Attributes, error) {
+	span, ctx := b.startSpan(ctx, "attributes", key)
+	attrs, err := b.Bucket.Attributes(ctx, key)
+	if err == nil {
+		span.SetTag(tagSize, attrs.Size)
+	}
+	span.Finish(tracer.WithError(err))
+	return attrs, err
+}
+
+// Exists calls blob.Bucket.Exists, tracing it.
+func (b *Bucket) Exists(ctx context.Context, key string) (bool, error) {
+	span, ctx := b.startSpan(ctx, "exists", key)
+	ok, err := b.Bucket.Exists(ctx, key)
+	span.Finish(tracer.WithError(err))
+	return ok, err
+}
+
+// Copy calls blob.Bucket.Copy, tracing it. The span is tagged with the destination key.
+func (b *Bucket) Copy(ctx context.Context, dstKey, srcKey string, opts *blob.CopyOptions) error {
+	span, ctx := b.startSpan(ctx, "copy", dstKey)
+	err := b.Bucket.Copy(ctx, dstKey, srcKey, opts)
+	span.Finish(tracer.WithError(err))
+	return err
+}
+
+// urlResource returns urlstr without its query, which may hold credentials.
+func urlResource(urlstr string) string {
+	u, err := url.Parse(urlstr)
+	if err != nil {
+		return ""
+	}
+	u.RawQuery = ""
+	u.User = nil
+	return u.String()
+}
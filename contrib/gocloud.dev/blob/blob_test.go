@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package blob
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gocloud.dev/blob/memblob"
+)
+
+func TestBucket(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	ctx := context.Background()
+	b := WrapBucket(memblob.OpenBucket(nil), WithServiceName("storage"), WithResourceName("assets"))
+	defer b.Close()
+
+	w, err := b.NewWriter(ctx, "a.txt", nil)
+	require.NoError(t, err)
+	_, err = io.WriteString(w, "hello")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	r, err := b.NewReader(ctx, "a.txt", nil)
+	require.NoError(t, err)
+	p, err := io.ReadAll(r)
+	require.NoError(t, err)
+	assert.Equal("hello", string(p))
+	require.NoError(t, r.Close())
+
+	ok, err := b.Exists(ctx, "a.txt")
+	require.NoError(t, err)
+	assert.True(ok)
+	require.NoError(t, b.Delete(ctx, "a.txt"))
+	_, err = b.ReadAll(ctx, "a.txt")
+	require.Error(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 5)
+	for i, name := range []string{
+		"gocloud.blob.write",
+		"gocloud.blob.read",
+		"gocloud.blob.exists",
+		"gocloud.blob.delete",
+		"gocloud.blob.read",
+	} {
+		s := spans[i]
+		assert.Equal(name, s.OperationName())
+		assert.Equal("storage", s.Tag(ext.ServiceName))
+		assert.Equal("assets", s.Tag(ext.ResourceName))
+		assert.Equal("a.txt", s.Tag(tagKey))
+	}
+	assert.EqualValues(5, spans[0].Tag(tagBytesWritten))
+	assert.EqualValues(5, spans[1].Tag(tagSize))
+	assert.Nil(spans[3].Tag(ext.Error))
+	assert.NotNil(spans[4].Tag(ext.Error))
+}
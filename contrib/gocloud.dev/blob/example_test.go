@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package blob_test
+
+import (
+	"context"
+	"log"
+
+	blobtrace "github.com/codebrick-corp/dd-trace-go/contrib/gocloud.dev/blob"
+
+	_ "gocloud.dev/blob/memblob"
+)
+
+func Example() {
+	ctx := context.Background()
+	b, err := blobtrace.OpenBucket(ctx, "mem://", blobtrace.WithServiceName("assets"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer b.Close()
+
+	if err := b.WriteAll(ctx, "greeting.txt", []byte("hello"), nil); err != nil {
+		log.Fatal(err)
+	}
+	if _, err := b.ReadAll(ctx, "greeting.txt"); err != nil {
+		log.Fatal(err)
+	}
+}
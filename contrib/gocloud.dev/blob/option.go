@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package blob
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+type config struct {
+	serviceName  string
+	resourceName string
+}
+
+// Option represents an option that can be passed to WrapBucket and OpenBucket.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	cfg.serviceName = "gocloud.blob"
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+}
+
+// WithServiceName sets the given service name for the bucket.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithResourceName sets the resource name of the spans of the bucket,
+// e.g. its name. It defaults to the URL of the bucket without its query when opened
+// with OpenBucket.
+func WithResourceName(name string) Option {
+	return func(cfg *config) {
+		cfg.resourceName = name
+	}
+}
@@ -0,0 +1,44 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package pubsub_test
+
+import (
+	"context"
+	"log"
+
+	pubsubtrace "github.com/codebrick-corp/dd-trace-go/contrib/gocloud.dev/pubsub"
+
+	"gocloud.dev/pubsub"
+	_ "gocloud.dev/pubsub/mempubsub"
+)
+
+func Example() {
+	ctx := context.Background()
+	topic, err := pubsubtrace.OpenTopic(ctx, "mem://orders")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer topic.Shutdown(ctx)
+	sub, err := pubsubtrace.OpenSubscription(ctx, "mem://orders", pubsubtrace.WithServiceName("order-processor"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer sub.Shutdown(ctx)
+
+	// The trace context is sent in the metadata of the message.
+	if err := topic.Send(ctx, &pubsub.Message{Body: []byte("order")}); err != nil {
+		log.Fatal(err)
+	}
+
+	// ctx holds the span of the reception, the processing of the message is traced
+	// in the same trace as its sending.
+	ctx, msg, err := sub.ReceiveContext(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer msg.Ack()
+	_ = ctx
+}
@@ -0,0 +1,173 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package pubsub provides functions to trace the gocloud.dev/pubsub package (https://gocloud.dev/howto/pubsub/).
+// The trace context is propagated through the metadata of the messages, whichever the
+// driver of the topics and subscriptions.
+package pubsub // import "github.com/codebrick-corp/dd-trace-go/contrib/gocloud.dev/pubsub"
+
+import (
+	"context"
+	"net/url"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"gocloud.dev/pubsub"
+)
+
+const componentName = "gocloud.dev/pubsub"
+
+func init() {
+	integrations.Register(componentName, "gocloud.dev/pubsub")
+}
+
+const (
+	tagMessageSize   = "message_size"
+	tagNumAttributes = "num_attributes"
+	tagMessageID     = "message_id"
+)
+
+// Topic is a pubsub.Topic whose sent messages are traced and carry the trace context
+// in their metadata.
+type Topic struct {
+	*pubsub.Topic
+	cfg *config
+}
+
+// OpenTopic opens the topic identified by urlstr with pubsub.OpenTopic and wraps it with
+// WrapTopic. The resource name of its spans defaults to urlstr without its query.
+func OpenTopic(ctx context.Context, urlstr string, opts ...Option) (*Topic, error) {
+	t, err := pubsub.OpenTopic(ctx, urlstr)
+	if err != nil {
+		return nil, err
+	}
+	return WrapTopic(t, append([]Option{WithResourceName(urlResource(urlstr))}, opts...)...), nil
+}
+
+// WrapTopic wraps t so that the messages it sends are traced.
+func WrapTopic(t *pubsub.Topic, opts ...Option) *Topic {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/gocloud.dev/pubsub: Wrapping Topic: %#v", cfg)
+	return &Topic{Topic: t, cfg: cfg}
+}
+
+// Send calls pubsub.Topic.Send, tracing it and injecting the trace context in the
+// metadata of m.
+func (t *Topic) Send(ctx context.Context, m *pubsub.Message) error {
+	opts := []ddtrace.StartSpanOption{
+		tracer.ServiceName(t.cfg.serviceName),
+		tracer.SpanType(ext.SpanTypeMessageProducer),
+		tracer.Tag(ext.SpanKind, ext.SpanKindProducer),
+		tracer.Tag(tagMessageSize, len(m.Body)),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if t.cfg.resourceName != "" {
+		opts = append(opts, tracer.ResourceName(t.cfg.resourceName))
+	}
+	span, ctx := tracer.StartSpanFromContext(ctx, "gocloud.pubsub.send", opts...)
+	if m.Metadata == nil {
+		m.Metadata = make(map[string]string)
+	}
+	if err := tracer.Inject(span.Context(), tracer.TextMapCarrier(m.Metadata)); err != nil {
+		log.Debug("contrib/gocloud.dev/pubsub: failed injecting tracing metadata: %v", err)
+	}
+	span.SetTag(tagNumAttributes, len(m.Metadata))
+	err := t.Topic.Send(ctx, m)
+	span.Finish(tracer.WithError(err))
+	return err
+}
+
+// Subscription is a pubsub.Subscription whose received messages are traced as children
+// of the spans which sent them.
+type Subscription struct {
+	*pubsub.Subscription
+	cfg *config
+}
+
+// OpenSubscription opens the subscription identified by urlstr with pubsub.OpenSubscription
+// and wraps it with WrapSubscription. The resource name of its spans defaults to urlstr
+// without its query.
+func OpenSubscription(ctx context.Context, urlstr string, opts ...Option) (*Subscription, error) {
+	s, err := pubsub.OpenSubscription(ctx, urlstr)
+	if err != nil {
+		return nil, err
+	}
+	return WrapSubscription(s, append([]Option{WithResourceName(urlResource(urlstr))}, opts...)...), nil
+}
+
+// WrapSubscription wraps s so that the messages it receives are traced.
+func WrapSubscription(s *pubsub.Subscription, opts ...Option) *Subscription {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/gocloud.dev/pubsub: Wrapping Subscription: %#v", cfg)
+	return &Subscription{Subscription: s, cfg: cfg}
+}
+
+// Receive calls pubsub.Subscription.Receive, tracing the reception of the message as a
+// child of the span which sent it. Use ReceiveContext to trace the processing of the
+// message in the same trace.
+func (s *Subscription) Receive(ctx context.Context) (*pubsub.Message, error) {
+	_, m, err := s.ReceiveContext(ctx)
+	return m, err
+}
+
+// ReceiveContext acts like Receive, and additionally returns a copy of ctx holding the
+// span of the reception, so that the processing of the message can be traced as its child:
+//
+//	ctx, msg, err := sub.ReceiveContext(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	defer msg.Ack()
+//	process(ctx, msg)
+func (s *Subscription) ReceiveContext(ctx context.Context) (context.Context, *pubsub.Message, error) {
+	m, err := s.Subscription.Receive(ctx)
+	if err != nil {
+		// the subscription was shut down or ctx is done, nothing was received
+		return ctx, nil, err
+	}
+	opts := []ddtrace.StartSpanOption{
+		tracer.ServiceName(s.cfg.serviceName),
+		tracer.SpanType(ext.SpanTypeMessageConsumer),
+		tracer.Tag(ext.SpanKind, ext.SpanKindConsumer),
+		tracer.Tag(tagMessageSize, len(m.Body)),
+		tracer.Tag(tagNumAttributes, len(m.Metadata)),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if m.LoggableID != "" {
+		opts = append(opts, tracer.Tag(tagMessageID, m.LoggableID))
+	}
+	if s.cfg.resourceName != "" {
+		opts = append(opts, tracer.ResourceName(s.cfg.resourceName))
+	}
+	if spanctx, err := tracer.Extract(tracer.TextMapCarrier(m.Metadata)); err == nil {
+		opts = append(opts, tracer.ChildOf(spanctx))
+	}
+	span, ctx := tracer.StartSpanFromContext(ctx, "gocloud.pubsub.receive", opts...)
+	span.Finish()
+	return ctx, m, nil
+}
+
+// urlResource returns urlstr without its query, which may hold credentials.
+func urlResource(urlstr string) string {
+	u, err := url.Parse(urlstr)
+	if err != nil {
+		return ""
+	}
+	u.RawQuery = ""
+	u.User = nil
+	return u.String()
+}
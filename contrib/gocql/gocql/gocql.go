@@ -16,11 +16,18 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/gocql/gocql"
 )
 
+const componentName = "gocql/gocql"
+
+func init() {
+	integrations.Register(componentName, "github.com/gocql/gocql")
+}
+
 // Query inherits from gocql.Query, it keeps the tracer and the context.
 type Query struct {
 	*gocql.Query
@@ -98,10 +105,12 @@ func (tq *Query) newChildSpan(ctx context.Context) ddtrace.Span {
 	p := tq.params
 	opts := []ddtrace.StartSpanOption{
 		tracer.SpanType(ext.SpanTypeCassandra),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(p.config.serviceName),
 		tracer.ResourceName(p.config.resourceName),
 		tracer.Tag(ext.CassandraPaginated, fmt.Sprintf("%t", p.paginated)),
 		tracer.Tag(ext.CassandraKeyspace, p.keyspace),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(p.config.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, p.config.analyticsRate))
@@ -246,10 +255,12 @@ func (tb *Batch) newChildSpan(ctx context.Context) ddtrace.Span {
 	p := tb.params
 	opts := []ddtrace.StartSpanOption{
 		tracer.SpanType(ext.SpanTypeCassandra),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(p.config.serviceName),
 		tracer.ResourceName(p.config.resourceName),
 		tracer.Tag(ext.CassandraConsistencyLevel, tb.Cons.String()),
 		tracer.Tag(ext.CassandraKeyspace, tb.Keyspace()),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(p.config.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, p.config.analyticsRate))
@@ -0,0 +1,46 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package work_test
+
+import (
+	"context"
+	"log"
+
+	worktrace "github.com/codebrick-corp/dd-trace-go/contrib/gocraft/work"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/gocraft/work"
+	"github.com/gomodule/redigo/redis"
+)
+
+func Example() {
+	redisPool := &redis.Pool{
+		Dial: func() (redis.Conn, error) { return redis.Dial("tcp", "127.0.0.1:6379") },
+	}
+
+	// When enqueuing, inject the context of the current span into the arguments of the job.
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "signup")
+	defer span.Finish()
+	args := work.Q{"address": "alice@example.org"}
+	if err := worktrace.InjectArgs(ctx, args); err != nil {
+		log.Printf("could not propagate the trace: %v", err)
+	}
+	if _, err := work.NewEnqueuer("my_app", redisPool).Enqueue("send_email", args); err != nil {
+		log.Fatal(err)
+	}
+
+	// Trace the jobs run by the worker pool.
+	pool := work.NewWorkerPool(struct{}{}, 10, "my_app", redisPool)
+	pool.Middleware(worktrace.Middleware(worktrace.WithServiceName("mailer")))
+	pool.Job("send_email", func(job *work.Job) error {
+		ctx := worktrace.ContextFromJob(context.Background(), job)
+		span, _ := tracer.StartSpanFromContext(ctx, "smtp.send")
+		defer span.Finish()
+		return nil
+	})
+	pool.Start()
+	defer pool.Stop()
+}
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package work provides functions to trace the jobs run by the worker pools of
+// github.com/gocraft/work. The middleware returned by Middleware, registered on a pool,
+// starts the span of each job run by the pool:
+//
+//	pool := work.NewWorkerPool(Context{}, 10, "my_app", redisPool)
+//	pool.Middleware(worktrace.Middleware())
+//
+// The trace of the code enqueuing a job is continued by its span when the context of the
+// enqueuing span is injected into the arguments of the job with InjectArgs. The handler of
+// a job retrieves the context holding its span with ContextFromJob.
+package work // import "github.com/codebrick-corp/dd-trace-go/contrib/gocraft/work"
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/jobtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/gocraft/work"
+)
+
+const componentName = "gocraft/work"
+
+func init() {
+	integrations.Register(componentName, "github.com/gocraft/work")
+}
+
+// jobContexts holds the contexts of the spans of the jobs being run, keyed by job.
+var jobContexts sync.Map
+
+// InjectArgs injects the context of the span found in ctx into args, the arguments of a job
+// about to be enqueued, so that the span of the job continues the trace.
+func InjectArgs(ctx context.Context, args map[string]interface{}) error {
+	return jobtrace.Inject(ctx, args)
+}
+
+// Middleware returns a middleware of a worker pool starting the span of each attempt to run
+// a job, tagged with its name, ID, attempt number and the time elapsed since it was enqueued.
+func Middleware(opts ...Option) func(*work.Job, work.NextMiddlewareFunc) error {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/gocraft/work: Configuring Middleware: %#v", cfg)
+	scfg := jobtrace.Config{
+		OperationName: operationName,
+		ServiceName:   cfg.serviceName,
+		Component:     componentName,
+	}
+	// The pool only recognizes middlewares of this exact, unnamed, type.
+	return func(job *work.Job, next work.NextMiddlewareFunc) error {
+		var enqueuedAt time.Time
+		if job.EnqueuedAt > 0 {
+			enqueuedAt = time.Unix(job.EnqueuedAt, 0)
+		}
+		span, ctx := jobtrace.StartSpan(context.Background(), scfg, jobtrace.Job{
+			Name:       job.Name,
+			ID:         job.ID,
+			Queue:      job.Name,
+			Attempt:    int(job.Fails) + 1,
+			EnqueuedAt: enqueuedAt,
+		}, jobtrace.ArgsCarrier(job.Args))
+		jobContexts.Store(job, ctx)
+		err := next()
+		jobContexts.Delete(job)
+		span.Finish(tracer.WithError(err))
+		return err
+	}
+}
+
+// ContextFromJob returns a copy of ctx holding the span of job, when called by the handler
+// of a job run by a pool traced with Middleware. Otherwise ctx is returned unchanged.
+func ContextFromJob(ctx context.Context, job *work.Job) context.Context {
+	v, ok := jobContexts.Load(job)
+	if !ok {
+		return ctx
+	}
+	if span, ok := tracer.SpanFromContext(v.(context.Context)); ok {
+		return tracer.ContextWithSpan(ctx, span)
+	}
+	return ctx
+}
@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package work
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+
+	"github.com/gocraft/work"
+	"github.com/gomodule/redigo/redis"
+)
+
+func TestMiddleware(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	// enqueue
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "http.request")
+	args := map[string]interface{}{"address": "alice@example.org"}
+	require.NoError(t, InjectArgs(ctx, args))
+	parent.Finish()
+
+	// the job is serialized when enqueued, and deserialized when dequeued
+	b, err := json.Marshal(&work.Job{
+		Name:       "send_email",
+		ID:         "a1b2c3",
+		EnqueuedAt: time.Now().Add(-time.Minute).Unix(),
+		Args:       args,
+		Fails:      1,
+	})
+	require.NoError(t, err)
+	var job work.Job
+	require.NoError(t, json.Unmarshal(b, &job))
+
+	// run
+	errJob := errors.New("smtp unavailable")
+	err = Middleware()(&job, func() error {
+		_, ok := tracer.SpanFromContext(ContextFromJob(context.Background(), &job))
+		assert.True(ok, "the span of the job must be found by its handler")
+		return errJob
+	})
+	assert.Equal(errJob, err)
+	_, ok := tracer.SpanFromContext(ContextFromJob(context.Background(), &job))
+	assert.False(ok, "the span of the job must be forgotten once it is run")
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	s := spans[1]
+	assert.Equal("work.job", s.OperationName())
+	assert.Equal("send_email", s.Tag(ext.ResourceName))
+	assert.Equal("gocraft-work", s.Tag(ext.ServiceName))
+	assert.Equal(ext.SpanTypeMessageConsumer, s.Tag(ext.SpanType))
+	assert.Equal(ext.SpanKindConsumer, s.Tag(ext.SpanKind))
+	assert.Equal(componentName, s.Tag(ext.Component))
+	assert.Equal("a1b2c3", s.Tag("job.id"))
+	assert.Equal("send_email", s.Tag("job.queue"))
+	assert.Equal(2, s.Tag("job.attempt"))
+	assert.GreaterOrEqual(s.Tag("job.latency").(int64), (59 * time.Second).Nanoseconds())
+	assert.Equal(errJob, s.Tag(ext.Error))
+	assert.Equal(parent.Context().TraceID(), s.TraceID())
+	assert.Equal(parent.Context().SpanID(), s.ParentID())
+}
+
+func TestWorkerPool(t *testing.T) {
+	if _, ok := os.LookupEnv("INTEGRATION"); !ok {
+		t.Skip("to enable integration test, set the INTEGRATION environment variable")
+	}
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	redisPool := &redis.Pool{
+		Dial: func() (redis.Conn, error) { return redis.Dial("tcp", "127.0.0.1:6379") },
+	}
+	defer redisPool.Close()
+	const namespace = "dd-trace-go-test"
+
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "http.request")
+	args := work.Q{"address": "alice@example.org"}
+	require.NoError(t, InjectArgs(ctx, args))
+	_, err := work.NewEnqueuer(namespace, redisPool).Enqueue("send_email", args)
+	require.NoError(t, err)
+	parent.Finish()
+
+	done := make(chan bool, 1)
+	pool := work.NewWorkerPool(struct{}{}, 1, namespace, redisPool)
+	pool.Middleware(Middleware())
+	pool.Job("send_email", func(job *work.Job) error {
+		_, ok := tracer.SpanFromContext(ContextFromJob(context.Background(), job))
+		done <- ok
+		return nil
+	})
+	pool.Start()
+	select {
+	case ok := <-done:
+		assert.True(ok, "the span of the job must be found by its handler")
+	case <-time.After(10 * time.Second):
+		t.Fatal("the job was not run")
+	}
+	pool.Stop()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	s := spans[1]
+	assert.Equal("work.job", s.OperationName())
+	assert.Equal("send_email", s.Tag(ext.ResourceName))
+	assert.Equal(1, s.Tag("job.attempt"))
+	assert.Equal(parent.Context().SpanID(), s.ParentID())
+}
+
+// run runs a job with the middleware built with opts.
+func run(opts ...Option) {
+	Middleware(opts...)(&work.Job{Name: "job"}, func() error { return nil })
+}
+
+func TestServiceName(t *testing.T) {
+	t.Run("global", func(t *testing.T) {
+		globalconfig.SetServiceName("global-service")
+		defer globalconfig.SetServiceName("")
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		run()
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "global-service", spans[0].Tag(ext.ServiceName))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		run(WithServiceName("my-worker"))
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "my-worker", spans[0].Tag(ext.ServiceName))
+	})
+}
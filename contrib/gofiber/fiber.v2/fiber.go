@@ -12,14 +12,22 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+const componentName = "gofiber/fiber.v2"
+
+func init() {
+	integrations.Register(componentName, "github.com/gofiber/fiber/v2")
+}
+
 // Middleware returns middleware that will trace incoming requests.
 func Middleware(opts ...Option) func(c *fiber.Ctx) error {
 	cfg := new(config)
@@ -31,10 +39,12 @@ func Middleware(opts ...Option) func(c *fiber.Ctx) error {
 	return func(c *fiber.Ctx) error {
 		opts := []ddtrace.StartSpanOption{
 			tracer.SpanType(ext.SpanTypeWeb),
+			tracer.Tag(ext.SpanKind, ext.SpanKindServer),
 			tracer.ServiceName(cfg.serviceName),
 			tracer.Tag(ext.HTTPMethod, c.Method()),
-			tracer.Tag(ext.HTTPURL, string(c.Request().URI().PathOriginal())),
+			tracer.Tag(ext.HTTPURL, httptrace.QuantizePath(string(c.Request().URI().PathOriginal()))),
 			tracer.Measured(),
+			tracer.Tag(ext.Component, componentName),
 		}
 		if !math.IsNaN(cfg.analyticsRate) {
 			opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
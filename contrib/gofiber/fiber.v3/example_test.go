@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package fiber_test
+
+import (
+	fibertrace "github.com/codebrick-corp/dd-trace-go/contrib/gofiber/fiber.v3"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+func Example() {
+	// Start the tracer
+	tracer.Start()
+	defer tracer.Stop()
+
+	// Create a fiber v3 Router, trusting the X-Forwarded-For header
+	// set by the proxies of the private networks.
+	router := fiber.New(fiber.Config{
+		TrustProxy:       true,
+		TrustProxyConfig: fiber.TrustProxyConfig{Private: true},
+		ProxyHeader:      fiber.HeaderXForwardedFor,
+	})
+
+	// Use the tracer middleware with the default service name "fiber".
+	router.Use(fibertrace.Middleware())
+
+	// Set up some endpoints.
+	router.Get("/", func(c fiber.Ctx) error {
+		return c.SendString("test")
+	})
+
+	// And start gathering request traces
+	router.Listen(":8080")
+}
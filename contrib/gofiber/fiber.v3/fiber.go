@@ -0,0 +1,112 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package fiber provides tracing functions for tracing the fiber v3 package (https://github.com/gofiber/fiber).
+package fiber // import "github.com/codebrick-corp/dd-trace-go/contrib/gofiber/fiber.v3"
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+const componentName = "gofiber/fiber.v3"
+
+func init() {
+	integrations.Register(componentName, "github.com/gofiber/fiber/v3")
+}
+
+// Middleware returns middleware that will trace incoming requests. The requests are named after
+// the template of the route which matched them. The client IP is the one reported by fiber, which
+// only trusts the proxy headers according to the TrustProxy and ProxyHeader settings of the app.
+func Middleware(opts ...Option) fiber.Handler {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("gofiber/fiber.v3: Middleware: %#v", cfg)
+	return func(c fiber.Ctx) error {
+		if cfg.ignoreRequest(c) {
+			return c.Next()
+		}
+		opts := []ddtrace.StartSpanOption{
+			tracer.SpanType(ext.SpanTypeWeb),
+			tracer.Tag(ext.SpanKind, ext.SpanKindServer),
+			tracer.ServiceName(cfg.serviceName),
+			tracer.Tag(ext.HTTPMethod, c.Method()),
+			tracer.Tag(ext.HTTPURL, httptrace.QuantizePath(string(c.Request().URI().PathOriginal()))),
+			tracer.Tag(ext.HTTPUserAgent, c.Get(fiber.HeaderUserAgent)),
+			tracer.Measured(),
+			tracer.Tag(ext.Component, componentName),
+		}
+		if host := c.Hostname(); host != "" {
+			opts = append(opts, tracer.Tag("http.host", host))
+		}
+		if ip := net.ParseIP(c.IP()); ip != nil {
+			opts = append(opts, tracer.Tag(ext.HTTPClientIP, ip.String()))
+			if hook := globalconfig.ClientIPHook(); hook != nil {
+				for k, v := range hook(ip) {
+					opts = append(opts, tracer.Tag(k, v))
+				}
+			}
+		}
+		if spanctx, err := tracer.Extract(tracer.HTTPHeadersCarrier(c.GetReqHeaders())); err == nil {
+			opts = append(opts, tracer.ChildOf(spanctx))
+		}
+		opts = append(opts, cfg.spanOpts...)
+		span, ctx := tracer.StartSpanFromContext(c.Context(), "http.request", opts...)
+		defer span.Finish()
+
+		// pass the span through the request context
+		c.SetContext(ctx)
+
+		// pass the execution down the line
+		err := c.Next()
+
+		// the matched route is only known once the request went through the router
+		span.SetTag(ext.ResourceName, cfg.resourceNamer(c))
+		span.SetTag(ext.HTTPRoute, c.Route().Path)
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			// the error handler of the app, which sets the status of the response
+			// from the error, only runs once the middleware returned
+			status = fiber.StatusInternalServerError
+			var ferr *fiber.Error
+			if errors.As(err, &ferr) {
+				status = ferr.Code
+			}
+		}
+		if status == 0 {
+			// 0 - means we do not have a status code at this point
+			// in case the response was returned by a middleware without one
+			status = http.StatusOK
+		}
+		span.SetTag(ext.HTTPCode, strconv.Itoa(status))
+
+		if cfg.isStatusError(status) {
+			if err != nil {
+				span.SetTag(ext.Error, err)
+			} else {
+				// mark 5xx server error
+				span.SetTag(ext.Error, fmt.Errorf("%d: %s", status, http.StatusText(status)))
+			}
+		}
+		return err
+	}
+}
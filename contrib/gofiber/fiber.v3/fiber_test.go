@@ -0,0 +1,149 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package fiber
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+
+	"github.com/gofiber/fiber/v3"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrace200(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	router := fiber.New()
+	router.Use(Middleware(WithServiceName("foobar")))
+	router.Get("/user/:id", func(c fiber.Ctx) error {
+		span, ok := tracer.SpanFromContext(c.Context())
+		assert.True(ok)
+		span.SetTag("test.fiber", "fibery")
+		return c.SendString(c.Params("id"))
+	})
+
+	root := tracer.StartSpan("root")
+	r := httptest.NewRequest("GET", "/user/123", nil)
+	err := tracer.Inject(root.Context(), tracer.HTTPHeadersCarrier(r.Header))
+	assert.NoError(err)
+	resp, err := router.Test(r)
+	assert.NoError(err)
+	assert.Equal(200, resp.StatusCode)
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 1)
+	span := spans[0]
+	assert.Equal("http.request", span.OperationName())
+	assert.Equal(ext.SpanTypeWeb, span.Tag(ext.SpanType))
+	assert.Equal("foobar", span.Tag(ext.ServiceName))
+	assert.Equal("fibery", span.Tag("test.fiber"))
+	assert.Equal("GET /user/:id", span.Tag(ext.ResourceName))
+	assert.Equal("/user/:id", span.Tag(ext.HTTPRoute))
+	assert.Equal("200", span.Tag(ext.HTTPCode))
+	assert.Equal("GET", span.Tag(ext.HTTPMethod))
+	assert.Equal("/user/123", span.Tag(ext.HTTPURL))
+	assert.Equal(root.Context().SpanID(), span.ParentID())
+}
+
+func TestError(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	router := fiber.New()
+	router.Use(Middleware())
+	router.Get("/err", func(c fiber.Ctx) error {
+		return errors.New("oh no")
+	})
+	router.Get("/teapot", func(c fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusTeapot)
+	})
+
+	for _, path := range []string{"/err", "/teapot"} {
+		_, err := router.Test(httptest.NewRequest("GET", path, nil))
+		assert.NoError(err)
+	}
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 2)
+	assert.Equal("500", spans[0].Tag(ext.HTTPCode))
+	assert.Equal("oh no", spans[0].Tag(ext.Error).(error).Error())
+	assert.Equal("418", spans[1].Tag(ext.HTTPCode))
+	assert.Nil(spans[1].Tag(ext.Error))
+}
+
+func TestClientIP(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	for _, trust := range []bool{true, false} {
+		router := fiber.New(fiber.Config{
+			TrustProxy:       trust,
+			TrustProxyConfig: fiber.TrustProxyConfig{Proxies: []string{"0.0.0.0"}},
+			ProxyHeader:      fiber.HeaderXForwardedFor,
+		})
+		router.Use(Middleware())
+		router.Get("/", func(c fiber.Ctx) error { return nil })
+
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set(fiber.HeaderXForwardedFor, "8.8.8.8")
+		_, err := router.Test(r)
+		assert.NoError(err)
+	}
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 2)
+	assert.Equal("8.8.8.8", spans[0].Tag(ext.HTTPClientIP))
+	assert.NotEqual("8.8.8.8", spans[1].Tag(ext.HTTPClientIP))
+}
+
+func TestIgnoreRequest(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	router := fiber.New()
+	router.Use(Middleware(WithIgnoreRequest(func(c fiber.Ctx) bool {
+		return c.Path() == "/health"
+	})))
+	router.Get("/:path", func(c fiber.Ctx) error { return nil })
+
+	for _, path := range []string{"/health", "/users"} {
+		_, err := router.Test(httptest.NewRequest("GET", path, nil))
+		assert.NoError(err)
+	}
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 1)
+	assert.Equal("/users", spans[0].Tag(ext.HTTPURL))
+}
+
+func TestServiceName(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	globalconfig.SetServiceName("global-service")
+	defer globalconfig.SetServiceName("")
+
+	router := fiber.New()
+	router.Use(Middleware())
+	router.Get("/", func(c fiber.Ctx) error { return nil })
+	_, err := router.Test(httptest.NewRequest("GET", "/", nil))
+	assert.NoError(err)
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 1)
+	assert.Equal("global-service", spans[0].Tag(ext.ServiceName))
+}
@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package fiber
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+
+	"github.com/gofiber/fiber/v3"
+)
+
+type config struct {
+	serviceName   string
+	isStatusError func(statusCode int) bool
+	spanOpts      []ddtrace.StartSpanOption // additional span options to be applied
+	resourceNamer func(fiber.Ctx) string
+	ignoreRequest func(fiber.Ctx) bool
+}
+
+// Option represents an option that can be passed to Middleware.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	cfg.serviceName = "fiber"
+	cfg.isStatusError = isServerError
+	cfg.resourceNamer = defaultResourceNamer
+	cfg.ignoreRequest = func(fiber.Ctx) bool { return false }
+
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+}
+
+// WithServiceName sets the given service name for the router.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithSpanOptions applies the given set of options to the spans started
+// by the router.
+func WithSpanOptions(opts ...ddtrace.StartSpanOption) Option {
+	return func(cfg *config) {
+		cfg.spanOpts = opts
+	}
+}
+
+// WithStatusCheck allow setting of a function to tell whether a status code is an error
+func WithStatusCheck(fn func(statusCode int) bool) Option {
+	return func(cfg *config) {
+		cfg.isStatusError = fn
+	}
+}
+
+// WithResourceNamer specifies a function which will be used to
+// obtain the resource name for a given request taking the go-fiber context
+// as input
+func WithResourceNamer(fn func(fiber.Ctx) string) Option {
+	return func(cfg *config) {
+		cfg.resourceNamer = fn
+	}
+}
+
+// WithIgnoreRequest specifies a function deciding whether the request of the given
+// context is served without being traced.
+func WithIgnoreRequest(fn func(fiber.Ctx) bool) Option {
+	return func(cfg *config) {
+		cfg.ignoreRequest = fn
+	}
+}
+
+func defaultResourceNamer(c fiber.Ctx) string {
+	r := c.Route()
+	return r.Method + " " + r.Path
+}
+
+func isServerError(statusCode int) bool {
+	return statusCode >= 500 && statusCode < 600
+}
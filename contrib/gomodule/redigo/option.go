@@ -8,12 +8,14 @@ package redigo // import "github.com/codebrick-corp/dd-trace-go/contrib/gomodule
 import (
 	"math"
 
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/rediscmd"
 	"github.com/codebrick-corp/dd-trace-go/internal"
 )
 
 type dialConfig struct {
 	serviceName   string
 	analyticsRate float64
+	command       rediscmd.Config
 }
 
 // DialOption represents an option that can be passed to Dial.
@@ -36,6 +38,30 @@ func WithServiceName(name string) DialOption {
 	}
 }
 
+// WithSkipCommandArgs reports whether to omit the arguments of commands from the
+// "redis.raw_command" tag, recording only the command names.
+func WithSkipCommandArgs(skip bool) DialOption {
+	return func(cfg *dialConfig) {
+		cfg.command.SkipArgs = skip
+	}
+}
+
+// WithKeyHashing reports whether to replace the keys found in the "redis.raw_command"
+// tag by a hash of their value, so that sensitive data within keys is not recorded.
+func WithKeyHashing(enabled bool) DialOption {
+	return func(cfg *dialConfig) {
+		cfg.command.HashKeys = enabled
+	}
+}
+
+// WithMaxKeyLength truncates the keys found in the "redis.raw_command" tag to at
+// most n bytes. A value of zero or less disables truncation.
+func WithMaxKeyLength(n int) DialOption {
+	return func(cfg *dialConfig) {
+		cfg.command.MaxKeyLength = n
+	}
+}
+
 // WithAnalytics enables Trace Analytics for all started spans.
 func WithAnalytics(on bool) DialOption {
 	return func(cfg *dialConfig) {
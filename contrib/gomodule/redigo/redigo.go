@@ -19,11 +19,18 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	redis "github.com/gomodule/redigo/redis"
 )
 
+const componentName = "gomodule/redigo"
+
+func init() {
+	integrations.Register(componentName, "github.com/gomodule/redigo/redis")
+}
+
 // Conn is an implementation of the redis.Conn interface that supports tracing
 type Conn struct {
 	redis.Conn
@@ -116,7 +123,9 @@ func DialURL(rawurl string, options ...interface{}) (redis.Conn, error) {
 func newChildSpan(ctx context.Context, p *params) ddtrace.Span {
 	opts := []ddtrace.StartSpanOption{
 		tracer.SpanType(ext.SpanTypeRedis),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(p.config.serviceName),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(p.config.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, p.config.analyticsRate))
@@ -159,6 +168,10 @@ func withSpan(do func(commandName string, args ...interface{}) (interface{}, err
 		// See https://godoc.org/github.com/gomodule/redigo/redis#hdr-Pipelining
 		span.SetTag(ext.ResourceName, "redigo.Conn.Flush")
 	}
+	if p.config.command.Enabled() {
+		span.SetTag("redis.raw_command", p.config.command.Format(append([]interface{}{commandName}, args...)))
+		return do(commandName, args...)
+	}
 	var b bytes.Buffer
 	b.WriteString(commandName)
 	for _, arg := range args {
@@ -53,6 +53,31 @@ func TestClient(t *testing.T) {
 	assert.Equal("2", span.Tag("redis.args_length"))
 }
 
+func TestCommandObfuscation(t *testing.T) {
+	for name, tt := range map[string]struct {
+		opt  DialOption
+		want string
+	}{
+		"skip-args":      {opt: WithSkipCommandArgs(true), want: "SET"},
+		"max-key-length": {opt: WithMaxKeyLength(3), want: "SET use... truck"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			c, err := Dial("tcp", "127.0.0.1:6379", tt.opt)
+			assert.Nil(t, err)
+			c.Do("SET", "user:1", "truck")
+
+			spans := mt.FinishedSpans()
+			assert.Len(t, spans, 1)
+			assert.Equal(t, "SET", spans[0].Tag(ext.ResourceName))
+			assert.Equal(t, tt.want, spans[0].Tag("redis.raw_command"))
+			assert.Equal(t, "2", spans[0].Tag("redis.args_length"))
+		})
+	}
+}
+
 func TestCommandError(t *testing.T) {
 	assert := assert.New(t)
 	mt := mocktracer.Start()
@@ -16,11 +16,19 @@ import (
 	httptrace "github.com/codebrick-corp/dd-trace-go/contrib/net/http"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"golang.org/x/oauth2/google"
 )
 
+const componentName = "google.golang.org/api"
+
+func init() {
+	integrations.Register(componentName, "google.golang.org/api")
+}
+
 // apiEndpoints are all of the defined endpoints for the Google API; it is populated
 // by "go generate".
 var apiEndpoints *internal.Tree
@@ -44,6 +52,7 @@ func WrapRoundTripper(transport http.RoundTripper, options ...Option) http.Round
 	cfg := newConfig(options...)
 	log.Debug("contrib/google.golang.org/api: Wrapping RoundTripper: %#v", cfg)
 	rtOpts := []httptrace.RoundTripperOption{
+		httptrace.RTWithSpanOptions(tracer.Tag(ext.Component, componentName)),
 		httptrace.WithBefore(func(req *http.Request, span ddtrace.Span) {
 			e, ok := apiEndpoints.Get(req.URL.Hostname(), req.Method, req.URL.Path)
 			if ok {
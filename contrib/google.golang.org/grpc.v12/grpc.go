@@ -17,6 +17,7 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
 	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	context "golang.org/x/net/context"
@@ -25,6 +26,12 @@ import (
 	"google.golang.org/grpc/peer"
 )
 
+const componentName = "google.golang.org/grpc.v12"
+
+func init() {
+	integrations.Register(componentName, "google.golang.org/grpc")
+}
+
 // UnaryServerInterceptor will trace requests to the given grpc server.
 func UnaryServerInterceptor(opts ...InterceptorOption) grpc.UnaryServerInterceptor {
 	cfg := new(interceptorConfig)
@@ -53,7 +60,9 @@ func startSpanFromContext(ctx context.Context, method, service string, rate floa
 		tracer.ResourceName(method),
 		tracer.Tag(tagMethod, method),
 		tracer.SpanType(ext.AppTypeRPC),
+		tracer.Tag(ext.SpanKind, ext.SpanKindServer),
 		tracer.Measured(),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(rate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, rate))
@@ -84,6 +93,8 @@ func UnaryClientInterceptor(opts ...InterceptorOption) grpc.UnaryClientIntercept
 		spanopts := []ddtrace.StartSpanOption{
 			tracer.Tag(tagMethod, method),
 			tracer.SpanType(ext.AppTypeRPC),
+			tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+			tracer.Tag(ext.Component, componentName),
 		}
 		if !math.IsNaN(cfg.analyticsRate) {
 			spanopts = append(spanopts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
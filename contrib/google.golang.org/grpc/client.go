@@ -102,6 +102,7 @@ func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
 					return err
 				})
 			if err != nil {
+				callAttemptsFromContext(ctx).tag(span)
 				finishWithError(span, err, cfg)
 				return nil, err
 			}
@@ -114,6 +115,7 @@ func StreamClientInterceptor(opts ...Option) grpc.StreamClientInterceptor {
 
 			go func() {
 				<-stream.Context().Done()
+				callAttemptsFromContext(ctx).tag(span)
 				finishWithError(span, stream.Context().Err(), cfg)
 			}()
 		} else {
@@ -149,10 +151,11 @@ func UnaryClientInterceptor(opts ...Option) grpc.UnaryClientInterceptor {
 	}
 	log.Debug("contrib/google.golang.org/grpc: Configuring UnaryClientInterceptor: %#v", cfg)
 	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
-		span, _, err := doClientRequest(ctx, cfg, method, methodKindUnary, opts,
+		span, ctx, err := doClientRequest(ctx, cfg, method, methodKindUnary, opts,
 			func(ctx context.Context, opts []grpc.CallOption) error {
 				return invoker(ctx, method, req, reply, cc, opts...)
 			})
+		callAttemptsFromContext(ctx).tag(span)
 		finishWithError(span, err, cfg)
 		return err
 	}
@@ -180,6 +183,8 @@ func doClientRequest(
 	var p peer.Peer
 	opts = append(opts, grpc.Peer(&p))
 
+	// record the attempts of the call, tagged by the caller once it's done
+	ctx, _ = withCallAttempts(ctx)
 	handlerCtx := injectSpanIntoContext(ctx)
 	err := handler(handlerCtx, opts)
 
@@ -15,6 +15,7 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 
 	context "golang.org/x/net/context"
 	"google.golang.org/grpc/codes"
@@ -22,6 +23,25 @@ import (
 	"google.golang.org/grpc/status"
 )
 
+const componentName = "google.golang.org/grpc"
+
+func init() {
+	integrations.Register(componentName, "google.golang.org/grpc")
+}
+
+// spanKind returns the span kind of spans having the given operation name.
+func spanKind(operation string) string {
+	switch operation {
+	case "grpc.client":
+		return ext.SpanKindClient
+	case "grpc.server":
+		return ext.SpanKindServer
+	default:
+		// stream messages are traced as children of the call spans
+		return ext.SpanKindInternal
+	}
+}
+
 func startSpanFromContext(
 	ctx context.Context, method, operation, service string, opts ...tracer.StartSpanOption,
 ) (ddtrace.Span, context.Context) {
@@ -30,11 +50,16 @@ func startSpanFromContext(
 		tracer.ResourceName(method),
 		tracer.Tag(tagMethodName, method),
 		tracer.SpanType(ext.AppTypeRPC),
+		tracer.Tag(ext.SpanKind, spanKind(operation)),
+		tracer.Tag(ext.Component, componentName),
 	)
 	md, _ := metadata.FromIncomingContext(ctx) // nil is ok
 	if sctx, err := tracer.Extract(grpcutil.MDCarrier(md)); err == nil {
 		opts = append(opts, tracer.ChildOf(sctx))
 	}
+	if n, ok := previousAttempts(md); ok && operation == "grpc.server" {
+		opts = append(opts, tracer.Tag(tagRetryPreviousAttempts, n))
+	}
 	return tracer.StartSpanFromContext(ctx, operation, opts...)
 }
 
@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package grpc
+
+import (
+	"sync/atomic"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/stats"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+)
+
+// payloadStatsKey is the context key of the *payloadStats of an RPC.
+type payloadStatsKey struct{}
+
+// payloadStats accumulates the sizes of the messages of an RPC, as reported by the payload
+// events of a stats handler. Streamed messages may be sent and received concurrently.
+type payloadStats struct {
+	sent             int64 // uncompressed bytes sent
+	sentWire         int64 // bytes sent on the wire
+	sentMessages     int64
+	received         int64 // uncompressed bytes received
+	receivedWire     int64 // bytes received on the wire
+	receivedMessages int64
+}
+
+// withPayloadStats returns a copy of ctx holding new payload stats.
+func withPayloadStats(ctx context.Context) context.Context {
+	return context.WithValue(ctx, payloadStatsKey{}, new(payloadStats))
+}
+
+// handlePayload records the payload and header events of rs in the payload stats of ctx,
+// tagging span with the compression algorithm of the RPC.
+func handlePayload(ctx context.Context, span ddtrace.Span, rs stats.RPCStats) {
+	p, ok := ctx.Value(payloadStatsKey{}).(*payloadStats)
+	if !ok {
+		return
+	}
+	switch rs := rs.(type) {
+	case *stats.OutPayload:
+		atomic.AddInt64(&p.sent, int64(rs.Length))
+		atomic.AddInt64(&p.sentWire, int64(rs.WireLength))
+		atomic.AddInt64(&p.sentMessages, 1)
+	case *stats.InPayload:
+		atomic.AddInt64(&p.received, int64(rs.Length))
+		atomic.AddInt64(&p.receivedWire, int64(rs.WireLength))
+		atomic.AddInt64(&p.receivedMessages, 1)
+	case *stats.OutHeader:
+		if rs.Compression != "" {
+			span.SetTag(tagCompression, rs.Compression)
+		}
+	case *stats.InHeader:
+		if rs.Compression != "" {
+			span.SetTag(tagCompression, rs.Compression)
+		}
+	}
+}
+
+// setPayloadTags tags span with the payload stats of ctx.
+func setPayloadTags(ctx context.Context, span ddtrace.Span) {
+	p, ok := ctx.Value(payloadStatsKey{}).(*payloadStats)
+	if !ok {
+		return
+	}
+	if n := atomic.LoadInt64(&p.sentMessages); n > 0 {
+		span.SetTag(tagSentMessages, n)
+		span.SetTag(tagSentBytes, atomic.LoadInt64(&p.sent))
+		span.SetTag(tagSentWireBytes, atomic.LoadInt64(&p.sentWire))
+	}
+	if n := atomic.LoadInt64(&p.receivedMessages); n > 0 {
+		span.SetTag(tagReceivedMessages, n)
+		span.SetTag(tagReceivedBytes, atomic.LoadInt64(&p.received))
+		span.SetTag(tagReceivedWireBytes, atomic.LoadInt64(&p.receivedWire))
+	}
+}
@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package grpc
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+	"google.golang.org/grpc/stats"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func TestPayloadStats(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "grpc.client")
+	ctx = withPayloadStats(ctx)
+	for _, rs := range []stats.RPCStats{
+		&stats.OutHeader{Client: true, Compression: "gzip"},
+		&stats.OutPayload{Client: true, Length: 1000, WireLength: 205},
+		&stats.OutPayload{Client: true, Length: 500, WireLength: 105},
+		&stats.InPayload{Client: true, Length: 2000, WireLength: 405},
+	} {
+		handlePayload(ctx, span, rs)
+	}
+	setPayloadTags(ctx, span)
+	span.Finish()
+
+	tags := mt.FinishedSpans()[0].Tags()
+	assert.Equal("gzip", tags[tagCompression])
+	assert.EqualValues(2, tags[tagSentMessages])
+	assert.EqualValues(1500, tags[tagSentBytes])
+	assert.EqualValues(310, tags[tagSentWireBytes])
+	assert.EqualValues(1, tags[tagReceivedMessages])
+	assert.EqualValues(2000, tags[tagReceivedBytes])
+	assert.EqualValues(405, tags[tagReceivedWireBytes])
+}
+
+func TestPayloadStatsNoMessages(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "grpc.client")
+	setPayloadTags(withPayloadStats(ctx), span)
+	span.Finish()
+
+	tags := mt.FinishedSpans()[0].Tags()
+	assert.NotContains(t, tags, tagSentMessages)
+	assert.NotContains(t, tags, tagReceivedMessages)
+	assert.NotContains(t, tags, tagCompression)
+}
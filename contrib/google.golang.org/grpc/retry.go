@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package grpc
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+)
+
+// previousAttemptsKey is the metadata key holding the number of attempts which preceded
+// a retried or hedged attempt of a call, as set by gRPC clients.
+const previousAttemptsKey = "grpc-previous-rpc-attempts"
+
+type callAttemptsKey struct{}
+
+// callAttempts records the outcome of the attempts of a client call. When retries or
+// hedging are enabled in the service config of a client connection, gRPC reports the
+// stats of every attempt to the stats handlers, which record them in the callAttempts
+// found in their context, as set by the client interceptors.
+type callAttempts struct {
+	mu          sync.Mutex // guards below fields
+	codes       []string   // the codes of the attempts which ended
+	transparent int        // the number of transparent retries
+}
+
+// withCallAttempts returns a copy of ctx holding a new callAttempts.
+func withCallAttempts(ctx context.Context) (context.Context, *callAttempts) {
+	a := new(callAttempts)
+	return context.WithValue(ctx, callAttemptsKey{}, a), a
+}
+
+// callAttemptsFromContext returns the callAttempts found in ctx, or nil.
+func callAttemptsFromContext(ctx context.Context) *callAttempts {
+	a, _ := ctx.Value(callAttemptsKey{}).(*callAttempts)
+	return a
+}
+
+// begin records the start of an attempt.
+func (a *callAttempts) begin(transparent bool) {
+	if !transparent {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.transparent++
+}
+
+// end records the end of an attempt which failed with err.
+func (a *callAttempts) end(err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.codes = append(a.codes, status.Code(err).String())
+}
+
+// tag tags the span of the call with the attempts made, when it has been retried. The
+// code of the last attempt is that of the call. a may be nil.
+func (a *callAttempts) tag(span ddtrace.Span) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if len(a.codes) < 2 && a.transparent == 0 {
+		return
+	}
+	span.SetTag(tagRetryAttempts, len(a.codes))
+	if a.transparent > 0 {
+		span.SetTag(tagRetryTransparentAttempts, a.transparent)
+	}
+	if len(a.codes) > 1 {
+		span.SetTag(tagRetryPreviousCodes, strings.Join(a.codes[:len(a.codes)-1], ","))
+	}
+}
+
+// isTransparentRetry reports whether b begins a transparent retry attempt, which gRPC
+// makes when the previous attempt never reached the server application. The field is
+// read by reflection, as it only exists in the versions of gRPC reporting the stats of
+// every attempt.
+func isTransparentRetry(b *stats.Begin) bool {
+	f := reflect.ValueOf(b).Elem().FieldByName("IsTransparentRetryAttempt")
+	return f.IsValid() && f.Kind() == reflect.Bool && f.Bool()
+}
+
+// previousAttempts returns the number of attempts which preceded the attempt of a call
+// sending md, and whether it is a retried or hedged attempt.
+func previousAttempts(md metadata.MD) (int, bool) {
+	v := md.Get(previousAttemptsKey)
+	if len(v) == 0 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(v[0])
+	if err != nil || n <= 0 {
+		return 0, false
+	}
+	return n, true
+}
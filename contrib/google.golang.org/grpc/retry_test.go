@@ -0,0 +1,111 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package grpc
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	context "golang.org/x/net/context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/status"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func TestCallAttempts(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	// simulate a call whose first attempt failed and was retried by gRPC, reporting the
+	// stats of each attempt to the stats handler
+	h := NewClientStatsHandler()
+	interceptor := UnaryClientInterceptor()
+	err := interceptor(context.Background(), "/grpc.Fixture/Ping", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			for i, err := range []error{status.Error(codes.Unavailable, "unavailable"), nil} {
+				actx := h.TagRPC(ctx, &stats.RPCTagInfo{FullMethodName: method})
+				h.HandleRPC(actx, &stats.Begin{Client: true, BeginTime: time.Now()})
+				md := metadata.MD{}
+				if i > 0 {
+					md.Set(previousAttemptsKey, "1")
+				}
+				h.HandleRPC(actx, &stats.OutHeader{Client: true, Header: md, RemoteAddr: &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 50051}})
+				h.HandleRPC(actx, &stats.End{Client: true, Error: err})
+			}
+			return nil
+		})
+	require.NoError(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 3)
+	first, second, call := spans[0], spans[1], spans[2]
+	assert.Equal(t, call.SpanID(), first.ParentID())
+	assert.Equal(t, call.SpanID(), second.ParentID())
+	assert.Equal(t, codes.Unavailable.String(), first.Tag(tagCode))
+	assert.Nil(t, first.Tag(tagRetryPreviousAttempts))
+	assert.Equal(t, 1, second.Tag(tagRetryPreviousAttempts))
+	assert.Equal(t, 2, call.Tag(tagRetryAttempts))
+	assert.Equal(t, "Unavailable", call.Tag(tagRetryPreviousCodes))
+	assert.Nil(t, call.Tag(tagRetryTransparentAttempts))
+}
+
+func TestCallAttemptsTag(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	t.Run("single", func(t *testing.T) {
+		_, a := withCallAttempts(context.Background())
+		a.begin(false)
+		a.end(nil)
+		span := tracer.StartSpan("grpc.client")
+		a.tag(span)
+		span.Finish()
+		assert.Nil(t, span.(mocktracer.Span).Tag(tagRetryAttempts))
+	})
+
+	t.Run("transparent", func(t *testing.T) {
+		_, a := withCallAttempts(context.Background())
+		a.begin(false)
+		a.end(status.Error(codes.Unavailable, "refused"))
+		a.begin(true)
+		a.end(nil)
+		span := tracer.StartSpan("grpc.client")
+		a.tag(span)
+		span.Finish()
+		s := span.(mocktracer.Span)
+		assert.Equal(t, 2, s.Tag(tagRetryAttempts))
+		assert.Equal(t, 1, s.Tag(tagRetryTransparentAttempts))
+		assert.Equal(t, "Unavailable", s.Tag(tagRetryPreviousCodes))
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		span := tracer.StartSpan("grpc.client")
+		callAttemptsFromContext(context.Background()).tag(span)
+		span.Finish()
+	})
+}
+
+func TestServerPreviousAttempts(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(previousAttemptsKey, "2"))
+	_, err := UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.Fixture/Ping"},
+		func(ctx context.Context, req interface{}) (interface{}, error) { return nil, nil })
+	require.NoError(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, 2, spans[0].Tag(tagRetryPreviousAttempts))
+}
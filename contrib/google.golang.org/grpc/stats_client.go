@@ -15,7 +15,15 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
 )
 
-// NewClientStatsHandler returns a gRPC client stats.Handler to trace RPC calls.
+// NewClientStatsHandler returns a gRPC client stats.Handler to trace RPC calls. The versions
+// of gRPC reporting the stats of every attempt of a call, when retries or hedging are
+// enabled in the service config, result in a span per attempt, tagged with the number of
+// previous attempts; when used along with the client interceptors, the span of the call
+// is tagged with the number of attempts and the codes of the previous ones, telling the
+// latency of the server apart from the amplification caused by retries.
+//
+// The spans are tagged with the number of messages sent and received, along with their
+// total uncompressed size and size on the wire, and with the compression algorithm in use.
 func NewClientStatsHandler(opts ...Option) stats.Handler {
 	cfg := new(config)
 	defaults(cfg)
@@ -39,7 +47,7 @@ func (h *clientStatsHandler) TagRPC(ctx context.Context, rti *stats.RPCTagInfo)
 		tracer.AnalyticsRate(h.cfg.analyticsRate),
 	)
 	ctx = injectSpanIntoContext(ctx)
-	return ctx
+	return withPayloadStats(ctx)
 }
 
 // HandleRPC processes the RPC ending event by finishing the span from the context.
@@ -48,7 +56,16 @@ func (h *clientStatsHandler) HandleRPC(ctx context.Context, rs stats.RPCStats) {
 	if !ok {
 		return
 	}
+	handlePayload(ctx, span, rs)
 	switch rs := rs.(type) {
+	case *stats.Begin:
+		transparent := isTransparentRetry(rs)
+		if transparent {
+			span.SetTag(tagRetryTransparent, true)
+		}
+		if a := callAttemptsFromContext(ctx); a != nil {
+			a.begin(transparent)
+		}
 	case *stats.OutHeader:
 		host, port, err := net.SplitHostPort(rs.RemoteAddr.String())
 		if err == nil {
@@ -57,7 +74,14 @@ func (h *clientStatsHandler) HandleRPC(ctx context.Context, rs stats.RPCStats) {
 			}
 			span.SetTag(ext.TargetPort, port)
 		}
+		if n, ok := previousAttempts(rs.Header); ok {
+			span.SetTag(tagRetryPreviousAttempts, n)
+		}
 	case *stats.End:
+		if a := callAttemptsFromContext(ctx); a != nil {
+			a.end(rs.Error)
+		}
+		setPayloadTags(ctx, span)
 		finishWithError(span, rs.Error, h.cfg)
 	}
 }
@@ -12,7 +12,9 @@ import (
 	"google.golang.org/grpc/stats"
 )
 
-// NewServerStatsHandler returns a gRPC server stats.Handler to trace RPC calls.
+// NewServerStatsHandler returns a gRPC server stats.Handler to trace RPC calls. The spans
+// are tagged with the number of messages sent and received, along with their total
+// uncompressed size and size on the wire, and with the compression algorithm in use.
 func NewServerStatsHandler(opts ...Option) stats.Handler {
 	cfg := new(config)
 	defaults(cfg)
@@ -38,7 +40,7 @@ func (h *serverStatsHandler) TagRPC(ctx context.Context, rti *stats.RPCTagInfo)
 		tracer.AnalyticsRate(h.cfg.analyticsRate),
 		tracer.Measured(),
 	)
-	return ctx
+	return withPayloadStats(ctx)
 }
 
 // HandleRPC processes the RPC ending event by finishing the span from the context.
@@ -47,7 +49,9 @@ func (h *serverStatsHandler) HandleRPC(ctx context.Context, rs stats.RPCStats) {
 	if !ok {
 		return
 	}
+	handlePayload(ctx, span, rs)
 	if v, ok := rs.(*stats.End); ok {
+		setPayloadTags(ctx, span)
 		finishWithError(span, v.Error, h.cfg)
 	}
 }
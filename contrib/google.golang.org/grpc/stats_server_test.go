@@ -51,6 +51,10 @@ func TestServerStatsHandler(t *testing.T) {
 	assert.Equal("/grpc.Fixture/Ping", tags["resource.name"])
 	assert.Equal("/grpc.Fixture/Ping", tags[tagMethodName])
 	assert.Equal(1, tags["_dd.measured"])
+	assert.EqualValues(1, tags[tagReceivedMessages])
+	assert.EqualValues(1, tags[tagSentMessages])
+	assert.NotZero(tags[tagReceivedBytes])
+	assert.NotZero(tags[tagSentWireBytes])
 }
 
 func newServerStatsHandlerTestServer(statsHandler stats.Handler) (*rig, error) {
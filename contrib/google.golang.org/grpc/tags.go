@@ -12,6 +12,36 @@ const (
 	tagCode           = "grpc.code"
 	tagMetadataPrefix = "grpc.metadata."
 	tagRequest        = "grpc.request"
+
+	// tagRetryPreviousAttempts holds the number of attempts which preceded a retried or
+	// hedged attempt of a call, on the spans of the attempt on the client and the server.
+	tagRetryPreviousAttempts = "grpc.retry.previous_attempts"
+	// tagRetryTransparent is set on the client spans of transparent retry attempts.
+	tagRetryTransparent = "grpc.retry.transparent"
+	// tagRetryAttempts holds the number of attempts of a retried call.
+	tagRetryAttempts = "grpc.retry.attempts"
+	// tagRetryTransparentAttempts holds the number of transparent retries of a call.
+	tagRetryTransparentAttempts = "grpc.retry.transparent_attempts"
+	// tagRetryPreviousCodes holds the comma-separated codes of the attempts which
+	// preceded the last attempt of a retried call.
+	tagRetryPreviousCodes = "grpc.retry.previous_codes"
+
+	// tagCompression holds the compression algorithm of the messages of an RPC, if any.
+	tagCompression = "grpc.compression"
+	// tagSentMessages holds the number of messages sent by a stats handler span.
+	tagSentMessages = "grpc.sent.messages"
+	// tagSentBytes holds the uncompressed size of the messages sent, in bytes.
+	tagSentBytes = "grpc.sent.bytes"
+	// tagSentWireBytes holds the size on the wire of the messages sent, in bytes,
+	// after compression and framing.
+	tagSentWireBytes = "grpc.sent.wire_bytes"
+	// tagReceivedMessages holds the number of messages received by a stats handler span.
+	tagReceivedMessages = "grpc.received.messages"
+	// tagReceivedBytes holds the uncompressed size of the messages received, in bytes.
+	tagReceivedBytes = "grpc.received.bytes"
+	// tagReceivedWireBytes holds the size on the wire of the messages received, in bytes,
+	// before decompression.
+	tagReceivedWireBytes = "grpc.received.wire_bytes"
 )
 
 const (
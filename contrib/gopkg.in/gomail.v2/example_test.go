@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package gomail_test
+
+import (
+	"context"
+	"log"
+
+	gomailtrace "github.com/codebrick-corp/dd-trace-go/contrib/gopkg.in/gomail.v2"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"gopkg.in/gomail.v2"
+)
+
+func Example() {
+	m := gomail.NewMessage()
+	m.SetHeader("From", "alex@example.com")
+	m.SetHeader("To", "bob@example.com", "cora@example.com")
+	m.SetHeader("Subject", "Hello!")
+	m.SetBody("text/html", "Hello <b>Bob</b> and <i>Cora</i>!")
+
+	d := gomail.NewDialer("smtp.example.com", 587, "user", "123456")
+	s, err := gomailtrace.Dial(d)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+
+	// The delivery attempt is traced as a child of the span in ctx.
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "web.request")
+	defer span.Finish()
+	if err := gomail.Send(s.WithContext(ctx), m); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package gomail provides functions to trace the gomail package (https://github.com/go-gomail/gomail).
+//
+// `WrapSender` and `Dial` return a Sender tracing the delivery attempts of the emails
+// it sends, tagged with the domains of their recipients, never with their addresses,
+// and with the size of their message. Its `WithContext` method can be used to connect
+// the spans to an existing trace.
+package gomail // import "github.com/codebrick-corp/dd-trace-go/contrib/gopkg.in/gomail.v2"
+
+import (
+	"context"
+	"io"
+	"net"
+	"strconv"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/mailtrace"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"gopkg.in/gomail.v2"
+)
+
+const componentName = "gopkg.in/gomail.v2"
+
+func init() {
+	integrations.Register(componentName, "gopkg.in/gomail.v2")
+}
+
+// Sender is a gomail.SendCloser tracing the emails sent through it.
+type Sender struct {
+	sender  gomail.Sender
+	cfg     *mailtrace.Config
+	context context.Context
+}
+
+var _ gomail.SendCloser = (*Sender)(nil)
+
+// WrapSender wraps s so that the emails it sends are traced.
+func WrapSender(s gomail.Sender, opts ...Option) *Sender {
+	return wrapSender(s, "", opts...)
+}
+
+// Dial dials and authenticates to the SMTP server of d, as d.Dial does, and returns
+// a Sender tracing the emails sent through the connection.
+func Dial(d *gomail.Dialer, opts ...Option) (*Sender, error) {
+	s, err := d.Dial()
+	if err != nil {
+		return nil, err
+	}
+	return wrapSender(s, net.JoinHostPort(d.Host, strconv.Itoa(d.Port)), opts...), nil
+}
+
+func wrapSender(s gomail.Sender, addr string, opts ...Option) *Sender {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/gopkg.in/gomail.v2: Wrapping Sender: %#v", cfg)
+	return &Sender{
+		sender: s,
+		cfg: &mailtrace.Config{
+			ServiceName: cfg.serviceName,
+			Addr:        addr,
+			Component:   componentName,
+		},
+		context: context.Background(),
+	}
+}
+
+// WithContext creates a copy of the Sender with the given context. The emails sent
+// by the copy are traced as children of the span found in ctx.
+func (s *Sender) WithContext(ctx context.Context) *Sender {
+	return &Sender{
+		sender:  s.sender,
+		cfg:     s.cfg,
+		context: ctx,
+	}
+}
+
+// Send implements gomail.Sender.
+func (s *Sender) Send(from string, to []string, msg io.WriterTo) error {
+	span := mailtrace.StartSpan(s.context, s.cfg, "Send", to)
+	cmsg := &countingWriterTo{WriterTo: msg}
+	err := s.sender.Send(from, to, cmsg)
+	size := cmsg.n
+	if size == 0 && err != nil {
+		// the message wasn't written
+		size = -1
+	}
+	mailtrace.FinishSpan(span, size, err)
+	return err
+}
+
+// Close closes the wrapped Sender, if it is a gomail.SendCloser.
+func (s *Sender) Close() error {
+	if sc, ok := s.sender.(gomail.SendCloser); ok {
+		return sc.Close()
+	}
+	return nil
+}
+
+// countingWriterTo counts the bytes written by the WriterTo it wraps. They are counted
+// on the written io.Writer, as the count returned by gomail.Message.WriteTo is inaccurate.
+type countingWriterTo struct {
+	io.WriterTo
+	n int64
+}
+
+// WriteTo implements io.WriterTo.
+func (c *countingWriterTo) WriteTo(w io.Writer) (int64, error) {
+	return c.WriterTo.WriteTo(&countingWriter{Writer: w, n: &c.n})
+}
+
+// countingWriter adds the number of bytes written to its Writer to n.
+type countingWriter struct {
+	io.Writer
+	n *int64
+}
+
+// Write implements io.Writer.
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.Writer.Write(p)
+	*c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package gomail
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/gomail.v2"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/mailtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+func newMessage() *gomail.Message {
+	m := gomail.NewMessage()
+	m.SetHeader("From", "noreply@example.com")
+	m.SetHeader("To", "bob@example.org", "Alice <alice@example.net>")
+	m.SetHeader("Subject", "Hello!")
+	m.SetBody("text/plain", "Hello world!")
+	return m
+}
+
+// closeSender is a gomail.SendCloser writing the messages to buf.
+type closeSender struct {
+	buf    bytes.Buffer
+	err    error
+	closed bool
+}
+
+func (s *closeSender) Send(_ string, _ []string, msg io.WriterTo) error {
+	if s.err != nil {
+		return s.err
+	}
+	_, err := msg.WriteTo(&s.buf)
+	return err
+}
+
+func (s *closeSender) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestSend(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	var cs closeSender
+	s := WrapSender(&cs)
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "http.request")
+	err := gomail.Send(s.WithContext(ctx), newMessage())
+	parent.Finish()
+	require.NoError(t, err)
+	require.NoError(t, s.Close())
+	assert.True(cs.closed)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	sp := spans[0]
+	assert.Equal(mailtrace.OperationName, sp.OperationName())
+	assert.Equal(parent.Context().SpanID(), sp.ParentID())
+	assert.Equal("Send", sp.Tag(ext.ResourceName))
+	assert.Equal("smtp", sp.Tag(ext.ServiceName))
+	assert.Equal("example.net,example.org", sp.Tag(mailtrace.TagRecipientDomains))
+	assert.Equal(2, sp.Tag(mailtrace.TagRecipientCount))
+	assert.Equal(int64(cs.buf.Len()), sp.Tag(mailtrace.TagMessageSize))
+	assert.Nil(sp.Tag(ext.Error))
+	for k, v := range sp.Tags() {
+		assert.NotContains(fmt.Sprint(v), "bob@", k)
+	}
+}
+
+func TestSendError(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	s := WrapSender(&closeSender{err: errors.New("connection refused")})
+	err := gomail.Send(s, newMessage())
+	require.Error(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.NotNil(t, spans[0].Tag(ext.Error))
+	assert.Nil(t, spans[0].Tag(mailtrace.TagMessageSize))
+}
+
+func TestSendFunc(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	s := WrapSender(gomail.SendFunc(func(_ string, _ []string, msg io.WriterTo) error {
+		_, err := msg.WriteTo(ioutil.Discard)
+		return err
+	}), WithServiceName("mailer"))
+	require.NoError(t, gomail.Send(s, newMessage()))
+	require.NoError(t, s.Close())
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "mailer", spans[0].Tag(ext.ServiceName))
+}
+
+func TestServiceName(t *testing.T) {
+	globalconfig.SetServiceName("global-service")
+	defer globalconfig.SetServiceName("")
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	require.NoError(t, gomail.Send(WrapSender(&closeSender{}), newMessage()))
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "global-service", spans[0].Tag(ext.ServiceName))
+}
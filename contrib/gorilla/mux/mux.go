@@ -10,18 +10,28 @@ import (
 	"net/http"
 	"strings"
 
+	httpinternal "github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
 	httptrace "github.com/codebrick-corp/dd-trace-go/contrib/net/http"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/gorilla/mux"
 )
 
+const componentName = "gorilla/mux"
+
+func init() {
+	integrations.Register(componentName, "github.com/gorilla/mux")
+}
+
 // Router registers routes to be matched and dispatches a handler.
 type Router struct {
 	*mux.Router
 	config *routerConfig
+	mw     *httpinternal.Middleware
 }
 
 // StrictSlash defines the trailing slash behavior for new routes. The initial
@@ -88,7 +98,7 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 	}
 	var (
 		match    mux.RouteMatch
-		spanopts []ddtrace.StartSpanOption
+		spanopts = []ddtrace.StartSpanOption{tracer.Tag(ext.Component, componentName)}
 		route    string
 	)
 	// get the resource associated to this request
@@ -111,6 +121,7 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 		QueryParams: r.config.queryParams,
 		RouteParams: match.Vars,
 		Route:       route,
+		Middleware:  r.mw,
 	})
 }
 
@@ -119,9 +130,14 @@ func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
 func WrapRouter(router *mux.Router, opts ...RouterOption) *Router {
 	cfg := newConfig(opts)
 	log.Debug("contrib/gorilla/mux: Configuring Router: %#v", cfg)
+	mw := httpinternal.NewMiddleware()
+	if cfg.hasQueryObfuscation {
+		mw = mw.WithQueryObfuscation(cfg.queryObfuscation)
+	}
 	return &Router{
 		Router: router,
 		config: cfg,
+		mw:     mw,
 	}
 }
 
@@ -141,7 +157,7 @@ func defaultResourceNamer(router *Router, req *http.Request) string {
 func headerTagsFromRequest(req *http.Request) ddtrace.StartSpanOption {
 	return func(cfg *ddtrace.StartSpanConfig) {
 		for k := range req.Header {
-			if !strings.HasPrefix(strings.ToLower(k), "x-datadog-") {
+			if httpinternal.RecordHeader(k) {
 				cfg.Tags["http.request.headers."+k] = strings.Join(req.Header.Values(k), ",")
 			}
 		}
@@ -10,6 +10,7 @@ import (
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
 	"strconv"
 	"strings"
 	"testing"
@@ -130,7 +131,25 @@ func TestWithQueryParams(t *testing.T) {
 
 	mux.ServeHTTP(httptest.NewRecorder(), r)
 
-	assert.Equal("/200?token=value&id=3&name=5", mt.FinishedSpans()[0].Tags()[ext.HTTPURL])
+	assert.Equal("/200?<redacted>&id=3&name=5", mt.FinishedSpans()[0].Tags()[ext.HTTPURL])
+}
+
+func TestWithQueryObfuscation(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	for re, want := range map[*regexp.Regexp]string{
+		regexp.MustCompile("id=[^&]+"): "/200?token=value&<redacted>&name=5",
+		nil:                            "/200?token=value&id=3&name=5",
+	} {
+		mt.Reset()
+		mux := NewRouter(WithQueryParams(), WithQueryObfuscation(re))
+		mux.Handle("/200", okHandler()).Host("localhost")
+		r := httptest.NewRequest("GET", "http://localhost/200?token=value&id=3&name=5", nil)
+		mux.ServeHTTP(httptest.NewRecorder(), r)
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, want, spans[0].Tag(ext.HTTPURL))
+	}
 }
 
 func TestSpanOptions(t *testing.T) {
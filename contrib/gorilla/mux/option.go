@@ -8,6 +8,7 @@ package mux
 import (
 	"math"
 	"net/http"
+	"regexp"
 
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
@@ -25,6 +26,10 @@ type routerConfig struct {
 	ignoreRequest func(*http.Request) bool
 	headerTags    bool
 	queryParams   bool
+	// queryObfuscation replaces the global query string obfuscation regexp when
+	// hasQueryObfuscation is set, see WithQueryObfuscation.
+	queryObfuscation    *regexp.Regexp
+	hasQueryObfuscation bool
 }
 
 // RouterOption represents an option that can be passed to NewRouter.
@@ -120,7 +125,8 @@ func WithResourceNamer(namer func(router *Router, req *http.Request) string) Rou
 }
 
 // WithHeaderTags specifies that the integration should attach HTTP request headers as
-// tags to spans.
+// tags to spans. The Cookie header is left out when DD_TRACE_HTTP_OMIT_COOKIES is true, and
+// so is the session header set by DD_TRACE_HTTP_SESSION_HEADER, which is recorded hashed instead.
 // Warning: using this feature can risk exposing sensitive data such as authorisation tokens
 // to Datadog.
 func WithHeaderTags() RouterOption {
@@ -137,3 +143,13 @@ func WithQueryParams() RouterOption {
 		cfg.queryParams = true
 	}
 }
+
+// WithQueryObfuscation replaces the regexp redacting the query parameters attached with
+// WithQueryParams, as configured with DD_TRACE_OBFUSCATION_QUERY_STRING_REGEXP, with re.
+// The query parameters are attached without being redacted when re is nil.
+func WithQueryObfuscation(re *regexp.Regexp) RouterOption {
+	return func(cfg *routerConfig) {
+		cfg.queryObfuscation = re
+		cfg.hasQueryObfuscation = true
+	}
+}
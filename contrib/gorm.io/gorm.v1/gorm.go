@@ -14,11 +14,18 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"gorm.io/gorm"
 )
 
+const componentName = "gorm.io/gorm.v1"
+
+func init() {
+	integrations.Register(componentName, "gorm.io/gorm")
+}
+
 type key string
 
 const (
@@ -114,7 +121,9 @@ func after(db *gorm.DB, operationName string, cfg *config) {
 		tracer.StartTime(t),
 		tracer.ServiceName(cfg.serviceName),
 		tracer.SpanType(ext.SpanTypeSQL),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ResourceName(db.Statement.SQL.String()),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
@@ -15,10 +15,12 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"strings"
 
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/graph-gophers/graphql-go/errors"
@@ -26,13 +28,32 @@ import (
 	"github.com/graph-gophers/graphql-go/trace"
 )
 
+const componentName = "graph-gophers/graphql-go"
+
+func init() {
+	integrations.Register(componentName, "github.com/graph-gophers/graphql-go")
+}
+
 const (
 	tagGraphqlField         = "graphql.field"
 	tagGraphqlQuery         = "graphql.query"
 	tagGraphqlType          = "graphql.type"
 	tagGraphqlOperationName = "graphql.operation.name"
+	tagGraphqlErrorCodes    = "graphql.error.codes"
+	tagGraphqlPersisted     = "graphql.persisted_query"
+	tagGraphqlPersistedHash = "graphql.persisted_query.hash"
 )
 
+type persistedQueryKey struct{}
+
+// ContextWithPersistedQuery returns a copy of ctx recording that the request was an
+// automatic persisted query with the given SHA-256 hash. graphql-go doesn't handle
+// persisted queries, so HTTP handlers resolving them should use the returned context
+// when executing the query for the request span to be tagged accordingly.
+func ContextWithPersistedQuery(ctx context.Context, hash string) context.Context {
+	return context.WithValue(ctx, persistedQueryKey{}, hash)
+}
+
 // A Tracer implements the graphql-go/trace.Tracer interface by sending traces
 // to the Datadog tracer.
 type Tracer struct {
@@ -47,11 +68,19 @@ func (t *Tracer) TraceQuery(ctx context.Context, queryString string, operationNa
 		tracer.ServiceName(t.cfg.serviceName),
 		tracer.Tag(tagGraphqlQuery, queryString),
 		tracer.Tag(tagGraphqlOperationName, operationName),
+		tracer.Tag(ext.SpanKind, ext.SpanKindServer),
 		tracer.Measured(),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(t.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, t.cfg.analyticsRate))
 	}
+	if hash, ok := ctx.Value(persistedQueryKey{}).(string); ok {
+		opts = append(opts,
+			tracer.Tag(tagGraphqlPersisted, true),
+			tracer.Tag(tagGraphqlPersistedHash, hash),
+		)
+	}
 	span, ctx := tracer.StartSpanFromContext(ctx, "graphql.request", opts...)
 
 	return ctx, func(errs []*errors.QueryError) {
@@ -64,6 +93,7 @@ func (t *Tracer) TraceQuery(ctx context.Context, queryString string, operationNa
 		default:
 			err = fmt.Errorf("%s (and %d more errors)", errs[0], n-1)
 		}
+		setErrorCodes(span, errs...)
 		span.Finish(tracer.WithError(err))
 	}
 }
@@ -77,7 +107,9 @@ func (t *Tracer) TraceField(ctx context.Context, label string, typeName string,
 		tracer.ServiceName(t.cfg.serviceName),
 		tracer.Tag(tagGraphqlField, fieldName),
 		tracer.Tag(tagGraphqlType, typeName),
+		tracer.Tag(ext.SpanKind, ext.SpanKindInternal),
 		tracer.Measured(),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(t.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, t.cfg.analyticsRate))
@@ -87,6 +119,7 @@ func (t *Tracer) TraceField(ctx context.Context, label string, typeName string,
 	return ctx, func(err *errors.QueryError) {
 		// must explicitly check for nil, see issue golang/go#22729
 		if err != nil {
+			setErrorCodes(span, err)
 			span.Finish(tracer.WithError(err))
 		} else {
 			span.Finish()
@@ -94,6 +127,30 @@ func (t *Tracer) TraceField(ctx context.Context, label string, typeName string,
 	}
 }
 
+// setErrorCodes tags span with the distinct "code" extensions of errs, in order of
+// appearance.
+func setErrorCodes(span ddtrace.Span, errs ...*errors.QueryError) {
+	var codes []string
+	seen := make(map[string]bool)
+	for _, err := range errs {
+		if err == nil {
+			continue
+		}
+		code, ok := err.Extensions["code"]
+		if !ok {
+			continue
+		}
+		c := fmt.Sprint(code)
+		if !seen[c] {
+			seen[c] = true
+			codes = append(codes, c)
+		}
+	}
+	if len(codes) > 0 {
+		span.SetTag(tagGraphqlErrorCodes, strings.Join(codes, ","))
+	}
+}
+
 // NewTracer creates a new Tracer.
 func NewTracer(opts ...Option) trace.Tracer {
 	cfg := new(config)
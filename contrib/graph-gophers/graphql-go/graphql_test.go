@@ -6,6 +6,8 @@
 package graphql
 
 import (
+	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -25,6 +27,21 @@ type testResolver struct{}
 func (*testResolver) Hello() string                    { return "Hello, world!" }
 func (*testResolver) HelloNonTrivial() (string, error) { return "Hello, world!", nil }
 
+type codeError struct{ code string }
+
+func (e codeError) Error() string { return "failed: " + e.code }
+
+func (e codeError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+type failingResolver struct{}
+
+func (*failingResolver) Denied() (*string, error)    { return nil, codeError{"FORBIDDEN"} }
+func (*failingResolver) Missing() (*string, error)   { return nil, codeError{"NOT_FOUND"} }
+func (*failingResolver) Forbidden() (*string, error) { return nil, codeError{"FORBIDDEN"} }
+func (*failingResolver) Plain() (*string, error)     { return nil, errors.New("plain") }
+
 func Test(t *testing.T) {
 	s := `
 		schema {
@@ -201,3 +218,66 @@ func TestAnalyticsSettings(t *testing.T) {
 		assertRate(t, mt, 0.23, WithAnalyticsRate(0.23))
 	})
 }
+
+func TestErrorCodes(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	s := `
+		schema {
+			query: Query
+		}
+		type Query {
+			denied: String
+			missing: String
+			forbidden: String
+			plain: String
+		}
+	`
+	schema := graphql.MustParseSchema(s, new(failingResolver), graphql.Tracer(NewTracer()))
+	resp := schema.Exec(context.Background(), "{ denied missing forbidden plain }", "", nil)
+	assert.Len(t, resp.Errors, 4)
+
+	spans := mt.FinishedSpans()
+	assert.Len(t, spans, 5)
+	for _, s := range spans {
+		switch s.Tag(tagGraphqlField) {
+		case "denied", "forbidden":
+			assert.Equal(t, "FORBIDDEN", s.Tag(tagGraphqlErrorCodes))
+		case "missing":
+			assert.Equal(t, "NOT_FOUND", s.Tag(tagGraphqlErrorCodes))
+		case "plain":
+			assert.Nil(t, s.Tag(tagGraphqlErrorCodes))
+			assert.NotNil(t, s.Tag(ext.Error))
+		default:
+			assert.Equal(t, "graphql.request", s.OperationName())
+			codes := strings.Split(s.Tag(tagGraphqlErrorCodes).(string), ",")
+			assert.ElementsMatch(t, []string{"FORBIDDEN", "NOT_FOUND"}, codes)
+		}
+	}
+}
+
+func TestPersistedQuery(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	schema := graphql.MustParseSchema(`
+		schema {
+			query: Query
+		}
+		type Query {
+			hello: String!
+		}
+	`, new(testResolver), graphql.Tracer(NewTracer(WithOmitTrivial())))
+
+	schema.Exec(context.Background(), "{ hello }", "", nil)
+	ctx := ContextWithPersistedQuery(context.Background(), "ecf4edb46db40b5132295c0291d62fb65d6759a9eedfa4d5d612dd5ec54a6b38")
+	schema.Exec(ctx, "{ hello }", "", nil)
+
+	spans := mt.FinishedSpans()
+	assert.Len(t, spans, 2)
+	assert.Nil(t, spans[0].Tag(tagGraphqlPersisted))
+	assert.Nil(t, spans[0].Tag(tagGraphqlPersistedHash))
+	assert.Equal(t, true, spans[1].Tag(tagGraphqlPersisted))
+	assert.Equal(t, "ecf4edb46db40b5132295c0291d62fb65d6759a9eedfa4d5d612dd5ec54a6b38", spans[1].Tag(tagGraphqlPersistedHash))
+}
@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package federation_test
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/graphql/federation"
+)
+
+func Example() {
+	client := &http.Client{Transport: federation.WrapRoundTripper(http.DefaultTransport)}
+
+	// for each operation, once its query plan is built
+	span, ctx := federation.StartPlanSpan(context.Background(), "GetUser", federation.Plan{
+		Subgraphs: []string{"users"},
+		Fetches:   1,
+		Depth:     1,
+	})
+	defer span.Finish()
+
+	// fetches sent with the context of the plan are traced as its children
+	body := strings.NewReader(`{"query":"{ user(id: 1) { name } }"}`)
+	req, _ := http.NewRequestWithContext(federation.ContextWithSubgraph(ctx, "users"), "POST", "http://users:4001/graphql", body)
+	client.Do(req)
+}
@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package federation provides functions to trace federated GraphQL gateways, such as
+// Apollo-compatible gateways built with gqlgen, bramble or wundergraph. Gateways
+// start a span for the execution of the query plan of each operation with
+// StartPlanSpan, and a child span for each subgraph fetch, either with StartFetchSpan
+// or by sending the fetches with an http.Client using WrapRoundTripper.
+package federation // import "github.com/codebrick-corp/dd-trace-go/contrib/graphql/federation"
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+)
+
+const componentName = "graphql/federation"
+
+func init() {
+	integrations.Register(componentName, "")
+}
+
+const (
+	tagGraphqlOperationName = "graphql.operation.name"
+	tagGraphqlQuery         = "graphql.query"
+	tagGraphqlSubgraph      = "graphql.subgraph"
+	tagGraphqlPlanSubgraphs = "graphql.plan.subgraphs"
+	tagGraphqlPlanFetches   = "graphql.plan.fetches"
+	tagGraphqlPlanDepth     = "graphql.plan.depth"
+)
+
+// Plan describes the query plan of a federated GraphQL operation.
+type Plan struct {
+	// Subgraphs lists the subgraphs queried by the plan.
+	Subgraphs []string
+	// Fetches is the number of subgraph fetches of the plan.
+	Fetches int
+	// Depth is the number of sequential steps of the plan, a step being made of the
+	// fetches which can be sent in parallel.
+	Depth int
+}
+
+// StartPlanSpan starts the span of the execution of the query plan of the operation
+// having the given name, as a child of the span found in ctx, if any. It returns the
+// span and a context holding it, to be used for the subgraph fetches of the plan.
+func StartPlanSpan(ctx context.Context, operationName string, plan Plan, opts ...Option) (ddtrace.Span, context.Context) {
+	cfg := newConfig(opts...)
+	sopts := append([]ddtrace.StartSpanOption{
+		tracer.ServiceName(cfg.serviceName),
+		tracer.ResourceName(operationName),
+		tracer.SpanType(ext.SpanTypeGraphQL),
+		tracer.Tag(ext.SpanKind, ext.SpanKindInternal),
+		tracer.Tag(tagGraphqlOperationName, operationName),
+		tracer.Tag(tagGraphqlPlanFetches, plan.Fetches),
+		tracer.Tag(tagGraphqlPlanDepth, plan.Depth),
+		tracer.Measured(),
+		tracer.Tag(ext.Component, componentName),
+	}, cfg.spanOpts...)
+	if subgraphs := uniqueSorted(plan.Subgraphs); len(subgraphs) > 0 {
+		sopts = append(sopts, tracer.Tag(tagGraphqlPlanSubgraphs, strings.Join(subgraphs, ",")))
+	}
+	return tracer.StartSpanFromContext(ctx, "graphql.execute_plan", sopts...)
+}
+
+// StartFetchSpan starts the span of a fetch of the given query from the given subgraph,
+// as a child of the span found in ctx, if any. Gateways which don't send their fetches
+// over HTTP must propagate the context of the returned span to the subgraph themselves.
+func StartFetchSpan(ctx context.Context, subgraph, query string, opts ...Option) (ddtrace.Span, context.Context) {
+	return startFetchSpan(ctx, subgraph, query, newConfig(opts...))
+}
+
+func startFetchSpan(ctx context.Context, subgraph, query string, cfg *config, opts ...ddtrace.StartSpanOption) (ddtrace.Span, context.Context) {
+	opts = append(append([]ddtrace.StartSpanOption{
+		tracer.ServiceName(cfg.serviceName),
+		tracer.ResourceName(subgraph),
+		tracer.SpanType(ext.SpanTypeGraphQL),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.Tag(tagGraphqlSubgraph, subgraph),
+		tracer.Measured(),
+		tracer.Tag(ext.Component, componentName),
+	}, opts...), cfg.spanOpts...)
+	if query != "" && !cfg.omitQueryTexts {
+		opts = append(opts, tracer.Tag(tagGraphqlQuery, query))
+	}
+	return tracer.StartSpanFromContext(ctx, "graphql.fetch", opts...)
+}
+
+type subgraphKey struct{}
+
+// ContextWithSubgraph returns a copy of ctx recording that the requests sent with it
+// query the given subgraph. WrapRoundTripper names the fetch spans after it.
+func ContextWithSubgraph(ctx context.Context, subgraph string) context.Context {
+	return context.WithValue(ctx, subgraphKey{}, subgraph)
+}
+
+// uniqueSorted returns the distinct non-empty values of s, sorted.
+func uniqueSorted(s []string) []string {
+	seen := make(map[string]bool, len(s))
+	out := make([]string, 0, len(s))
+	for _, v := range s {
+		if v != "" && !seen[v] {
+			seen[v] = true
+			out = append(out, v)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package federation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+)
+
+func TestPlanSpans(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	plan, ctx := StartPlanSpan(context.Background(), "GetUser", Plan{
+		Subgraphs: []string{"users", "reviews", "users", ""},
+		Fetches:   3,
+		Depth:     2,
+	}, WithServiceName("gateway"))
+	fetch, _ := StartFetchSpan(ctx, "users", "{ user(id: 1) { name } }", WithServiceName("gateway"))
+	fetch.Finish()
+	fetch, _ = StartFetchSpan(ctx, "reviews", "{ reviews { body } }", WithServiceName("gateway"), WithoutQueries())
+	fetch.Finish()
+	plan.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 3)
+	users, reviews, root := spans[0], spans[1], spans[2]
+
+	assert.Equal(t, "graphql.execute_plan", root.OperationName())
+	assert.Equal(t, "gateway", root.Tag(ext.ServiceName))
+	assert.Equal(t, "GetUser", root.Tag(ext.ResourceName))
+	assert.Equal(t, "GetUser", root.Tag(tagGraphqlOperationName))
+	assert.Equal(t, "reviews,users", root.Tag(tagGraphqlPlanSubgraphs))
+	assert.Equal(t, 3, root.Tag(tagGraphqlPlanFetches))
+	assert.Equal(t, 2, root.Tag(tagGraphqlPlanDepth))
+
+	assert.Equal(t, "graphql.fetch", users.OperationName())
+	assert.Equal(t, root.SpanID(), users.ParentID())
+	assert.Equal(t, "users", users.Tag(tagGraphqlSubgraph))
+	assert.Equal(t, ext.SpanKindClient, users.Tag(ext.SpanKind))
+	assert.Equal(t, "{ user(id: 1) { name } }", users.Tag(tagGraphqlQuery))
+
+	assert.Equal(t, root.SpanID(), reviews.ParentID())
+	assert.Equal(t, "reviews", reviews.Tag(ext.ResourceName))
+	assert.Nil(t, reviews.Tag(tagGraphqlQuery))
+}
@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package federation
+
+import (
+	"net/http"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+type config struct {
+	serviceName    string
+	spanOpts       []ddtrace.StartSpanOption
+	subgraphNamer  func(*http.Request) string
+	omitQueryTexts bool
+}
+
+// Option represents an option that can be used to customize the spans of a gateway.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	cfg.serviceName = "graphql.gateway"
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+	cfg.subgraphNamer = func(r *http.Request) string { return r.URL.Host }
+}
+
+func newConfig(opts ...Option) *config {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	return cfg
+}
+
+// WithServiceName sets the given service name for the spans of the gateway.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithSpanOptions defines a set of additional ddtrace.StartSpanOption to be added
+// to spans started by the integration.
+func WithSpanOptions(opts ...ddtrace.StartSpanOption) Option {
+	return func(cfg *config) {
+		cfg.spanOpts = append(cfg.spanOpts, opts...)
+	}
+}
+
+// WithSubgraphNamer sets the function returning the name of the subgraph queried by
+// a request sent by WrapRoundTripper, when the context of the request doesn't hold
+// it. By default, the subgraph is named after the host of the request.
+func WithSubgraphNamer(namer func(*http.Request) string) Option {
+	return func(cfg *config) {
+		cfg.subgraphNamer = namer
+	}
+}
+
+// WithoutQueries prevents the queries sent to the subgraphs from being set as span
+// tags, for instance when they may hold sensitive literals.
+func WithoutQueries() Option {
+	return func(cfg *config) {
+		cfg.omitQueryTexts = true
+	}
+}
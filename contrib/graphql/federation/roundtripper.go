@@ -0,0 +1,67 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package federation
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+type roundTripper struct {
+	base http.RoundTripper
+	cfg  *config
+}
+
+// RoundTrip implements http.RoundTripper.
+func (rt *roundTripper) RoundTrip(req *http.Request) (res *http.Response, err error) {
+	subgraph, ok := req.Context().Value(subgraphKey{}).(string)
+	if !ok {
+		subgraph = rt.cfg.subgraphNamer(req)
+	}
+	span, ctx := startFetchSpan(req.Context(), subgraph, "", rt.cfg,
+		tracer.Tag(ext.HTTPMethod, req.Method),
+		tracer.Tag(ext.HTTPURL, req.URL.String()),
+	)
+	defer func() {
+		span.Finish(tracer.WithError(err))
+	}()
+	req = req.Clone(ctx)
+	if err := tracer.Inject(span.Context(), tracer.HTTPHeadersCarrier(req.Header)); err != nil {
+		log.Debug("contrib/graphql/federation: failed to inject http headers: %v", err)
+	}
+	res, err = rt.base.RoundTrip(req)
+	if err != nil {
+		return res, err
+	}
+	span.SetTag(ext.HTTPCode, strconv.Itoa(res.StatusCode))
+	if res.StatusCode/100 == 5 {
+		span.SetTag(ext.Error, fmt.Errorf("%d: %s", res.StatusCode, http.StatusText(res.StatusCode)))
+	}
+	return res, nil
+}
+
+// Unwrap returns the original http.RoundTripper.
+func (rt *roundTripper) Unwrap() http.RoundTripper {
+	return rt.base
+}
+
+// WrapRoundTripper returns an http.RoundTripper tracing the subgraph fetches sent over
+// rt. Each fetch span is a child of the span found in the context of the request,
+// usually the span started by StartPlanSpan, and its context is propagated to the
+// subgraph. A nil rt stands for http.DefaultTransport.
+func WrapRoundTripper(rt http.RoundTripper, opts ...Option) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	cfg := newConfig(opts...)
+	log.Debug("contrib/graphql/federation: Wrapping RoundTripper: %#v", cfg)
+	return &roundTripper{base: rt, cfg: cfg}
+}
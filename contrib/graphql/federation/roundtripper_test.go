@@ -0,0 +1,87 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package federation
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func TestRoundTripper(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	var upstream ddtrace.SpanContext
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		upstream, _ = tracer.Extract(tracer.HTTPHeadersCarrier(r.Header))
+		if r.URL.Path == "/fail" {
+			w.WriteHeader(http.StatusBadGateway)
+		}
+	}))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	require.NoError(t, err)
+
+	client := &http.Client{Transport: WrapRoundTripper(nil)}
+	plan, ctx := StartPlanSpan(context.Background(), "GetUser", Plan{})
+
+	req, _ := http.NewRequestWithContext(ContextWithSubgraph(ctx, "users"), "POST", srv.URL+"/graphql", nil)
+	res, err := client.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	assert.Empty(t, req.Header.Get(tracer.DefaultTraceIDHeader), "the request of the caller must not be modified")
+
+	req, _ = http.NewRequestWithContext(ctx, "POST", srv.URL+"/fail", nil)
+	res, err = client.Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	plan.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 3)
+	users, failed := spans[0], spans[1]
+	assert.Equal(t, "users", users.Tag(tagGraphqlSubgraph))
+	assert.Equal(t, plan.Context().SpanID(), users.ParentID())
+	assert.Equal(t, "200", users.Tag(ext.HTTPCode))
+	assert.Equal(t, "POST", users.Tag(ext.HTTPMethod))
+	assert.Nil(t, users.Tag(ext.Error))
+
+	assert.Equal(t, u.Host, failed.Tag(tagGraphqlSubgraph))
+	assert.Equal(t, "502", failed.Tag(ext.HTTPCode))
+	assert.NotNil(t, failed.Tag(ext.Error))
+	require.NotNil(t, upstream)
+	assert.Equal(t, failed.SpanID(), upstream.SpanID())
+}
+
+func TestWithSubgraphNamer(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	client := &http.Client{Transport: WrapRoundTripper(http.DefaultTransport, WithSubgraphNamer(func(r *http.Request) string {
+		return "products"
+	}))}
+	res, err := client.Post(srv.URL, "application/json", nil)
+	require.NoError(t, err)
+	res.Body.Close()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "products", spans[0].Tag(tagGraphqlSubgraph))
+}
@@ -12,11 +12,18 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	consul "github.com/hashicorp/consul/api"
 )
 
+const componentName = "hashicorp/consul"
+
+func init() {
+	integrations.Register(componentName, "github.com/hashicorp/consul/api")
+}
+
 // Client wraps the regular *consul.Client and augments it with tracing. Use NewClient to initialize it.
 type Client struct {
 	*consul.Client
@@ -69,7 +76,9 @@ func (k *KV) startSpan(resourceName string, key string) ddtrace.Span {
 		tracer.ResourceName(resourceName),
 		tracer.ServiceName(k.config.serviceName),
 		tracer.SpanType(ext.SpanTypeConsul),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.Tag("consul.key", key),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(k.config.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, k.config.analyticsRate))
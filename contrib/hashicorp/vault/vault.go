@@ -23,11 +23,19 @@ import (
 	httptrace "github.com/codebrick-corp/dd-trace-go/contrib/net/http"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 
 	"github.com/hashicorp/vault/api"
 	"github.com/hashicorp/vault/sdk/helper/consts"
 )
 
+const componentName = "hashicorp/vault"
+
+func init() {
+	integrations.Register(componentName, "github.com/hashicorp/vault/api")
+}
+
 // NewHTTPClient returns an http.Client for use in the Vault API config
 // Client. A set of options can be passed in for further configuration.
 func NewHTTPClient(opts ...Option) *http.Client {
@@ -50,6 +58,7 @@ func WrapHTTPClient(c *http.Client, opts ...Option) *http.Client {
 	}
 	c.Transport = httptrace.WrapRoundTripper(c.Transport,
 		httptrace.RTWithAnalyticsRate(conf.analyticsRate),
+		httptrace.RTWithSpanOptions(tracer.Tag(ext.Component, componentName)),
 		httptrace.WithBefore(func(r *http.Request, s ddtrace.Span) {
 			s.SetTag(ext.ServiceName, conf.serviceName)
 			s.SetTag(ext.HTTPURL, r.URL.Path)
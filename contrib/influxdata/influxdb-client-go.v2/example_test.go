@@ -0,0 +1,42 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package influxdb_test
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	influxtrace "github.com/codebrick-corp/dd-trace-go/contrib/influxdata/influxdb-client-go.v2"
+
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+)
+
+func Example() {
+	client := influxtrace.NewClient("http://localhost:8086", "my-token", influxtrace.WithServiceName("my-influxdb"))
+	defer client.Close()
+
+	// The write batches and the queries of the client are now traced.
+	err := client.WriteAPIBlocking("acme", "metrics").WriteRecord(context.Background(), "cpu,host=a usage=0.5")
+	if err != nil {
+		log.Fatal(err)
+	}
+	res, err := client.QueryAPI("acme").Query(context.Background(), `from(bucket: "metrics") |> range(start: -1h)`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer res.Close()
+}
+
+func ExampleNewRoundTripper() {
+	// The requests of any client talking to InfluxDB over HTTP, such as the InfluxDB 1.x
+	// client, can be traced by the http.RoundTripper of the package.
+	options := influxdb2.DefaultOptions().SetHTTPClient(&http.Client{
+		Transport: influxtrace.NewRoundTripper(influxtrace.WithServiceName("my-influxdb")),
+	})
+	client := influxdb2.NewClientWithOptions("http://localhost:8086", "my-token", options)
+	defer client.Close()
+}
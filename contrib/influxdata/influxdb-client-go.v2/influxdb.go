@@ -0,0 +1,274 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package influxdb provides functions to trace the writes and queries sent to InfluxDB by the
+// github.com/influxdata/influxdb-client-go/v2 package (https://github.com/influxdata/influxdb-client-go).
+// The client talks to InfluxDB over HTTP: the clients returned by NewClient and
+// NewClientWithOptions trace their requests with the http.RoundTripper returned by
+// NewRoundTripper, which can also be set as the Transport of any http.Client. The write
+// batches are traced with their bucket, organization and number of points, and the Flux
+// queries with their obfuscated query.
+//
+// The requests to the InfluxDB 1.x API, sent by github.com/influxdata/influxdb1-client,
+// are traced by the same http.RoundTripper.
+package influxdb // import "github.com/codebrick-corp/dd-trace-go/contrib/influxdata/influxdb-client-go.v2"
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/graphquery"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/DataDog/datadog-agent/pkg/obfuscate"
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+)
+
+const componentName = "influxdata/influxdb-client-go.v2"
+
+func init() {
+	integrations.Register(componentName, "github.com/influxdata/influxdb-client-go/v2")
+}
+
+const (
+	// writeOperationName is the name of the spans of write batches.
+	writeOperationName = "influxdb.write"
+	// queryOperationName is the name of the spans of queries.
+	queryOperationName = "influxdb.query"
+	// requestOperationName is the name of the spans of the other requests.
+	requestOperationName = "influxdb.request"
+
+	// tagOrg holds the organization of a request.
+	tagOrg = "influxdb.org"
+	// tagBucket holds the bucket written to or queried.
+	tagBucket = "influxdb.bucket"
+	// tagPrecision holds the precision of the timestamps of a write batch.
+	tagPrecision = "influxdb.precision"
+	// tagPoints holds the number of points of a write batch.
+	tagPoints = "influxdb.points"
+	// tagQuery holds the obfuscated query of a request.
+	tagQuery = "influxdb.query"
+)
+
+// NewClient returns a new InfluxDB client for the server at serverURL, authenticated with
+// authToken and using the default options of the client, whose requests are traced.
+func NewClient(serverURL, authToken string, opts ...ClientOption) influxdb2.Client {
+	return NewClientWithOptions(serverURL, authToken, influxdb2.DefaultOptions(), opts...)
+}
+
+// NewClientWithOptions returns a new InfluxDB client for the server at serverURL,
+// authenticated with authToken and configured with options, whose requests are traced. The
+// requests are sent through a copy of the http.Client of options, whose Transport is wrapped
+// unless a transport is given with WithTransport: options is modified to hold it.
+func NewClientWithOptions(serverURL, authToken string, options *influxdb2.Options, opts ...ClientOption) influxdb2.Client {
+	client := *options.HTTPClient()
+	if client.Transport != nil {
+		opts = append([]ClientOption{WithTransport(client.Transport)}, opts...)
+	}
+	client.Transport = NewRoundTripper(opts...)
+	options.SetHTTPClient(&client)
+	return influxdb2.NewClientWithOptions(serverURL, authToken, options)
+}
+
+// NewRoundTripper returns a new http.RoundTripper which traces the requests to InfluxDB.
+func NewRoundTripper(opts ...ClientOption) http.RoundTripper {
+	cfg := new(clientConfig)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/influxdata/influxdb-client-go.v2: Configuring RoundTripper: %#v", cfg)
+	return &roundTripper{
+		config:     *cfg,
+		obfuscator: obfuscate.NewObfuscator(obfuscate.Config{}),
+	}
+}
+
+// roundTripper is an implementation of http.RoundTripper that captures InfluxDB spans.
+type roundTripper struct {
+	config     clientConfig
+	obfuscator *obfuscate.Obfuscator
+}
+
+var _ http.RoundTripper = &roundTripper{}
+
+// RoundTrip satisfies the RoundTripper interface, wraps the sub Transport and
+// captures a span of the InfluxDB request.
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	params := req.URL.Query()
+	opts := []ddtrace.StartSpanOption{
+		tracer.ServiceName(t.config.serviceName),
+		tracer.SpanType(ext.SpanTypeInfluxDB),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.Tag(ext.DBSystem, ext.DBSystemInfluxDB),
+		tracer.Tag(ext.HTTPMethod, req.Method),
+		tracer.Tag(ext.TargetHost, req.URL.Hostname()),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if port := req.URL.Port(); port != "" {
+		opts = append(opts, tracer.Tag(ext.TargetPort, port))
+	}
+	if org := params.Get("org"); org != "" {
+		opts = append(opts, tracer.Tag(tagOrg, org))
+	}
+	if db := params.Get("db"); db != "" {
+		// InfluxDB 1.x
+		opts = append(opts, tracer.Tag(ext.DBName, db))
+	}
+	name := requestOperationName
+	switch path := req.URL.Path; {
+	case strings.HasSuffix(path, "/write"):
+		// /api/v2/write or the InfluxDB 1.x /write
+		name = writeOperationName
+		opts = append(opts, tracer.ResourceName("write"))
+		if bucket := params.Get("bucket"); bucket != "" {
+			opts = append(opts, tracer.Tag(tagBucket, bucket))
+		}
+		if precision := params.Get("precision"); precision != "" {
+			opts = append(opts, tracer.Tag(tagPrecision, precision))
+		}
+		if n, ok := countPoints(req); ok {
+			opts = append(opts, tracer.Tag(tagPoints, n))
+		}
+	case strings.HasSuffix(path, "/api/v2/query"):
+		name = queryOperationName
+		opts = append(opts, t.fluxQueryOptions(req)...)
+	case strings.HasSuffix(path, "/query"):
+		// InfluxDB 1.x
+		name = queryOperationName
+		opts = append(opts, t.influxQLQueryOptions(req, params.Get("q"))...)
+	default:
+		opts = append(opts, tracer.ResourceName(req.Method+" "+path))
+	}
+	span, _ := tracer.StartSpanFromContext(req.Context(), name, opts...)
+	defer span.Finish()
+
+	// process using the standard transport
+	res, err := t.config.transport.RoundTrip(req)
+	if err != nil {
+		// roundtrip error
+		span.SetTag(ext.Error, err)
+	} else if res.StatusCode < 200 || res.StatusCode > 299 {
+		// HTTP error
+		span.SetTag(ext.Error, fmt.Errorf("%d: %s", res.StatusCode, http.StatusText(res.StatusCode)))
+	}
+	if res != nil {
+		span.SetTag(ext.HTTPCode, strconv.Itoa(res.StatusCode))
+	}
+	return res, err
+}
+
+// bucketRegexp matches the bucket read by a Flux query, e.g. `from(bucket: "metrics")`.
+var bucketRegexp = regexp.MustCompile(`from\(\s*bucket\s*:\s*"((?:[^"\\]|\\.)*)"`)
+
+// fluxQueryOptions returns the options tagging the span of the Flux query sent in req, whose
+// resource is the obfuscated query.
+func (t *roundTripper) fluxQueryOptions(req *http.Request) []ddtrace.StartSpanOption {
+	body, err := readBody(req)
+	if err != nil {
+		return []ddtrace.StartSpanOption{tracer.ResourceName("query")}
+	}
+	query := string(body)
+	if mt, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mt != "application/vnd.flux" {
+		var q struct {
+			Query string `json:"query"`
+		}
+		if err := json.Unmarshal(body, &q); err != nil {
+			return []ddtrace.StartSpanOption{tracer.ResourceName("query")}
+		}
+		query = q.Query
+	}
+	obfuscated := graphquery.Obfuscate(query)
+	opts := []ddtrace.StartSpanOption{
+		tracer.ResourceName(obfuscated),
+		tracer.Tag(tagQuery, obfuscated),
+	}
+	if m := bucketRegexp.FindStringSubmatch(query); m != nil {
+		opts = append(opts, tracer.Tag(tagBucket, m[1]))
+	}
+	return opts
+}
+
+// influxQLQueryOptions returns the options tagging the span of the InfluxQL query q, sent in
+// the query string or in the form-encoded body of req.
+func (t *roundTripper) influxQLQueryOptions(req *http.Request, q string) []ddtrace.StartSpanOption {
+	if q == "" && req.Method == http.MethodPost {
+		if body, err := readBody(req); err == nil {
+			if mt, _, _ := mime.ParseMediaType(req.Header.Get("Content-Type")); mt == "application/x-www-form-urlencoded" {
+				if form, err := url.ParseQuery(string(body)); err == nil {
+					q = form.Get("q")
+				}
+			}
+		}
+	}
+	if q == "" {
+		return []ddtrace.StartSpanOption{tracer.ResourceName("query")}
+	}
+	oq, err := t.obfuscator.ObfuscateSQLString(q)
+	if err != nil {
+		log.Debug("contrib/influxdata/influxdb-client-go.v2: Error obfuscating query: %v", err)
+		return []ddtrace.StartSpanOption{tracer.ResourceName("query")}
+	}
+	return []ddtrace.StartSpanOption{
+		tracer.ResourceName(oq.Query),
+		tracer.Tag(tagQuery, oq.Query),
+	}
+}
+
+// readBody reads the body of req and replaces it with a copy, so that it can still be sent.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, io.EOF
+	}
+	body, err := ioutil.ReadAll(req.Body)
+	req.Body.Close()
+	req.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return body, err
+}
+
+// countPoints returns the number of points in the line protocol body of the write request req,
+// which may be gzip encoded. Write batches are held in memory by the clients, so reading them
+// is cheap. It returns false if the body could not be read.
+func countPoints(req *http.Request) (int, bool) {
+	body, err := readBody(req)
+	if err != nil {
+		return 0, false
+	}
+	var r io.Reader = bytes.NewReader(body)
+	if req.Header.Get("Content-Encoding") == "gzip" {
+		gzr, err := gzip.NewReader(r)
+		if err != nil {
+			return 0, false
+		}
+		defer gzr.Close()
+		r = gzr
+	}
+	lines, err := ioutil.ReadAll(r)
+	if err != nil {
+		return 0, false
+	}
+	n := 0
+	for _, line := range bytes.Split(lines, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) > 0 && line[0] != '#' {
+			n++
+		}
+	}
+	return n, true
+}
@@ -0,0 +1,243 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package influxdb
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+)
+
+// newServer returns a fake InfluxDB server recording the bodies it receives into bodies.
+// Writes to the bucket "missing" fail with a 404.
+func newServer(t *testing.T, bodies chan<- []byte) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		if bodies != nil {
+			bodies <- body
+		}
+		switch {
+		case r.URL.Query().Get("bucket") == "missing":
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, `{"code":"not found","message":"bucket \"missing\" not found"}`)
+		case r.URL.Path == "/api/v2/query":
+			w.Header().Set("Content-Type", "text/csv")
+			w.WriteHeader(http.StatusOK)
+		default:
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// do sends req through the http.RoundTripper of the package.
+func do(t *testing.T, req *http.Request) int {
+	res, err := (&http.Client{Transport: NewRoundTripper()}).Do(req)
+	require.NoError(t, err)
+	res.Body.Close()
+	return res.StatusCode
+}
+
+var batch = []string{
+	"cpu,host=a usage=0.5 1622505600000000000",
+	"cpu,host=b usage=0.7 1622505600000000000",
+	"mem,host=a used=1024i 1622505600000000000",
+}
+
+func TestWrite(t *testing.T) {
+	for name, gzipped := range map[string]bool{"plain": false, "gzip": true} {
+		t.Run(name, func(t *testing.T) {
+			assert := assert.New(t)
+			mt := mocktracer.Start()
+			defer mt.Stop()
+			bodies := make(chan []byte, 1)
+			srv := newServer(t, bodies)
+
+			client := NewClientWithOptions(srv.URL, "token", influxdb2.DefaultOptions().SetUseGZip(gzipped))
+			defer client.Close()
+			root, ctx := tracer.StartSpanFromContext(context.Background(), "web.request")
+			err := client.WriteAPIBlocking("acme", "metrics").WriteRecord(ctx, batch...)
+			root.Finish()
+			require.NoError(t, err)
+
+			received := <-bodies
+			if gzipped {
+				gzr, err := gzip.NewReader(bytes.NewReader(received))
+				require.NoError(t, err)
+				received, _ = ioutil.ReadAll(gzr)
+			}
+			for _, line := range batch {
+				assert.Contains(string(received), line, "the batch must be left intact")
+			}
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 2)
+			s := spans[0]
+			u, _ := url.Parse(srv.URL)
+			assert.Equal("influxdb.write", s.OperationName())
+			assert.Equal(root.Context().SpanID(), s.ParentID())
+			assert.Equal("write", s.Tag(ext.ResourceName))
+			assert.Equal("influxdb", s.Tag(ext.ServiceName))
+			assert.Equal(ext.SpanTypeInfluxDB, s.Tag(ext.SpanType))
+			assert.Equal(ext.SpanKindClient, s.Tag(ext.SpanKind))
+			assert.Equal(ext.DBSystemInfluxDB, s.Tag(ext.DBSystem))
+			assert.Equal(componentName, s.Tag(ext.Component))
+			assert.Equal(u.Hostname(), s.Tag(ext.TargetHost))
+			assert.Equal(u.Port(), s.Tag(ext.TargetPort))
+			assert.Equal("acme", s.Tag(tagOrg))
+			assert.Equal("metrics", s.Tag(tagBucket))
+			assert.Equal("ns", s.Tag(tagPrecision))
+			assert.Equal(3, s.Tag(tagPoints))
+			assert.Equal("204", s.Tag(ext.HTTPCode))
+			assert.Nil(s.Tag(ext.Error))
+		})
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	client := NewClient(newServer(t, nil).URL, "token")
+	defer client.Close()
+	err := client.WriteAPIBlocking("acme", "missing").WriteRecord(context.Background(), batch...)
+	require.Error(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "404", spans[0].Tag(ext.HTTPCode))
+	assert.EqualError(t, spans[0].Tag(ext.Error).(error), "404: Not Found")
+}
+
+func TestTransport(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	var sent int
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		sent++
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	options := influxdb2.DefaultOptions().SetHTTPClient(&http.Client{Transport: transport})
+	client := NewClientWithOptions(newServer(t, nil).URL, "token", options, WithServiceName("my-influxdb"))
+	defer client.Close()
+	_, err := client.Ping(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, sent, "the transport of the options must be used")
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "influxdb.request", spans[0].OperationName())
+	assert.Equal(t, "GET /ping", spans[0].Tag(ext.ResourceName))
+	assert.Equal(t, "my-influxdb", spans[0].Tag(ext.ServiceName))
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestFluxQuery(t *testing.T) {
+	const flux = `from(bucket: "metrics") |> range(start: -1h) |> filter(fn: (r) => r.host == "web-1")`
+	const obfuscated = `from(bucket: ?) |> range(start: -?) |> filter(fn: (r) => r.host == ?)`
+	assertSpan := func(t *testing.T, mt mocktracer.Tracer) {
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		s := spans[0]
+		assert.Equal(t, "influxdb.query", s.OperationName())
+		assert.Equal(t, obfuscated, s.Tag(ext.ResourceName))
+		assert.Equal(t, obfuscated, s.Tag(tagQuery))
+		assert.Equal(t, "acme", s.Tag(tagOrg))
+		assert.Equal(t, "metrics", s.Tag(tagBucket))
+	}
+
+	t.Run("client", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		bodies := make(chan []byte, 1)
+
+		client := NewClient(newServer(t, bodies).URL, "token")
+		defer client.Close()
+		res, err := client.QueryAPI("acme").Query(context.Background(), flux)
+		require.NoError(t, err)
+		res.Close()
+		var body struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.Unmarshal(<-bodies, &body))
+		assert.Equal(t, flux, body.Query, "the query must be left intact")
+
+		assertSpan(t, mt)
+	})
+
+	t.Run("flux", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		bodies := make(chan []byte, 1)
+
+		req, err := http.NewRequest("POST", newServer(t, bodies).URL+"/api/v2/query?org=acme", strings.NewReader(flux))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/vnd.flux")
+		do(t, req)
+		assert.Equal(t, flux, string(<-bodies), "the query must be left intact")
+
+		assertSpan(t, mt)
+	})
+}
+
+func TestInfluxQLQuery(t *testing.T) {
+	const query = `SELECT mean("usage") FROM "cpu" WHERE "host" = 'web-1' AND time > now() - 1h`
+	const obfuscated = `SELECT mean ( usage ) FROM cpu WHERE host = ? AND time > now ( ) - ? h`
+	t.Run("url", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		req, err := http.NewRequest("GET", newServer(t, nil).URL+"/query?db=telegraf&q="+url.QueryEscape(query), nil)
+		require.NoError(t, err)
+		do(t, req)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		s := spans[0]
+		assert.Equal(t, "influxdb.query", s.OperationName())
+		assert.Equal(t, obfuscated, s.Tag(ext.ResourceName))
+		assert.Equal(t, "telegraf", s.Tag(ext.DBName))
+	})
+
+	t.Run("form", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		req, err := http.NewRequest("POST", newServer(t, nil).URL+"/query?db=telegraf", strings.NewReader(url.Values{"q": {query}}.Encode()))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		do(t, req)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, obfuscated, spans[0].Tag(ext.ResourceName))
+	})
+}
@@ -0,0 +1,37 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package influxdb
+
+import (
+	"net/http"
+)
+
+type clientConfig struct {
+	serviceName string
+	transport   http.RoundTripper
+}
+
+// ClientOption represents an option that can be used when creating a client.
+type ClientOption func(*clientConfig)
+
+func defaults(cfg *clientConfig) {
+	cfg.serviceName = "influxdb"
+	cfg.transport = http.DefaultTransport
+}
+
+// WithTransport sets the given transport as an http.Transport for the client.
+func WithTransport(t http.RoundTripper) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.transport = t
+	}
+}
+
+// WithServiceName sets the given service name for the client.
+func WithServiceName(name string) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.serviceName = name
+	}
+}
@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package graphquery provides functionalities to obfuscate the queries of graph and time
+// series databases, such as Neo4j's Cypher, Dgraph's DQL and InfluxDB's Flux, that are
+// commonly required and used across the contrib/** integrations of these databases.
+package graphquery
+
+import "strings"
+
+// Obfuscate returns query with its string and numeric literals replaced by "?", e.g.
+// `MATCH (p:Person {name: 'Alice'}) WHERE p.age > 30 RETURN p` becomes
+// `MATCH (p:Person {name: ?}) WHERE p.age > ? RETURN p`. Identifiers, including the
+// backtick-quoted ones, and query parameters, such as $name, are kept.
+func Obfuscate(query string) string {
+	var out strings.Builder
+	out.Grow(len(query))
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '\'' || c == '"':
+			i += literalLen(query[i:], c)
+			out.WriteByte('?')
+		case c == '`':
+			// quoted identifier
+			n := literalLen(query[i:], c)
+			out.WriteString(query[i : i+n])
+			i += n
+		case isDigit(c):
+			i += numberLen(query[i:])
+			out.WriteByte('?')
+		case isIdent(c) || c == '$':
+			// identifier, keyword or parameter, which may contain digits
+			j := i + 1
+			for j < len(query) && isIdent(query[j]) {
+				j++
+			}
+			out.WriteString(query[i:j])
+			i = j
+		default:
+			out.WriteByte(c)
+			i++
+		}
+	}
+	return out.String()
+}
+
+// literalLen returns the length of the literal starting s and delimited by quote. Unterminated
+// literals extend to the end of s.
+func literalLen(s string, quote byte) int {
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case quote:
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// numberLen returns the length of the numeric literal starting s, e.g. "42", "3.14", "1e-3"
+// or "0x1f".
+func numberLen(s string) int {
+	i := 1
+	for i < len(s) {
+		switch c := s[i]; {
+		case isIdent(c):
+			// digits, hexadecimal digits and exponents
+			if (c == 'e' || c == 'E') && i+1 < len(s) && (s[i+1] == '-' || s[i+1] == '+') {
+				i++
+			}
+		case c == '.' && i+1 < len(s) && isDigit(s[i+1]):
+		default:
+			return i
+		}
+		i++
+	}
+	return i
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isIdent(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package graphquery
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestObfuscate(t *testing.T) {
+	for in, out := range map[string]string{
+		// Cypher
+		`MATCH (p:Person {name: 'Alice'}) WHERE p.age > 30 RETURN p`:       `MATCH (p:Person {name: ?}) WHERE p.age > ? RETURN p`,
+		`MATCH (p:Person) WHERE p.name = $name RETURN p LIMIT 10`:          `MATCH (p:Person) WHERE p.name = $name RETURN p LIMIT ?`,
+		`CREATE (n:Node2 {score: 3.14, ratio: 1e-3, tags: ["a", 'b\'c']})`: `CREATE (n:Node2 {score: ?, ratio: ?, tags: [?, ?]})`,
+		"MATCH (n:`My Label 1`) RETURN n.x1, -42":                          "MATCH (n:`My Label 1`) RETURN n.x1, -?",
+		`RETURN 'unterminated`: `RETURN ?`,
+		// DQL
+		`{ me(func: eq(name@en, "Alice"), first: 10) { uid name } }`: `{ me(func: eq(name@en, ?), first: ?) { uid name } }`,
+		`{ q(func: uid(0x1f)) @filter(ge(age, 18.5)) { friend } }`:   `{ q(func: uid(?)) @filter(ge(age, ?)) { friend } }`,
+		`query q($a: string) { q(func: eq(name, $a)) { name } }`:     `query q($a: string) { q(func: eq(name, $a)) { name } }`,
+		// Flux
+		`from(bucket: "metrics") |> range(start: -1h) |> filter(fn: (r) => r._value > 90.5)`: `from(bucket: ?) |> range(start: -?) |> filter(fn: (r) => r._value > ?)`,
+	} {
+		assert.Equal(t, out, Obfuscate(in), in)
+	}
+}
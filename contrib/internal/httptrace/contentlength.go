@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package httptrace
+
+import (
+	"io"
+	"strconv"
+	"sync/atomic"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+const (
+	// tagRequestContentLength holds the size in bytes of the body of the request.
+	tagRequestContentLength = "http.request.content_length"
+	// tagResponseContentLength holds the size in bytes of the body of the response.
+	tagResponseContentLength = "http.response.content_length"
+)
+
+// setContentLengths sets the sizes in bytes of the bodies of the response resp, and of its
+// request when it wasn't known as the request span s started.
+func setContentLengths(s tracer.Span, resp *Response) {
+	if resp.RequestSize > 0 {
+		s.SetTag(tagRequestContentLength, resp.RequestSize)
+	}
+	if resp.Size > 0 {
+		s.SetTag(tagResponseContentLength, resp.Size)
+	} else if n, err := strconv.ParseInt(resp.Header.Get("Content-Length"), 10, 64); err == nil && n >= 0 {
+		s.SetTag(tagResponseContentLength, n)
+	}
+}
+
+// CountingBody wraps the body of a request to count the bytes read from it, which gives
+// the size of the bodies whose length isn't known in advance, such as chunked ones.
+type CountingBody struct {
+	io.ReadCloser
+	n int64 // number of bytes read; accessed atomically
+}
+
+// CountBody returns a CountingBody wrapping body.
+func CountBody(body io.ReadCloser) *CountingBody {
+	return &CountingBody{ReadCloser: body}
+}
+
+// Read implements io.Reader.
+func (b *CountingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	atomic.AddInt64(&b.n, int64(n))
+	return n, err
+}
+
+// BytesRead returns the number of bytes read from the body so far.
+func (b *CountingBody) BytesRead() int64 {
+	return atomic.LoadInt64(&b.n)
+}
@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package httptrace
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+)
+
+func TestContentLengths(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	for _, tt := range []struct {
+		name string
+		body string // body of the request; a nil reader when empty
+		resp *Response
+		want map[string]interface{}
+	}{
+		{"known", "hello", &Response{Size: 345}, map[string]interface{}{tagRequestContentLength: int64(5), tagResponseContentLength: int64(345)}},
+		{"empty", "", &Response{}, map[string]interface{}{tagRequestContentLength: int64(0)}},
+		{"header", "", &Response{Header: http.Header{"Content-Length": {"12"}}}, map[string]interface{}{tagRequestContentLength: int64(0), tagResponseContentLength: int64(12)}},
+		{"counted", "", &Response{RequestSize: 7, Size: 3}, map[string]interface{}{tagRequestContentLength: int64(7), tagResponseContentLength: int64(3)}},
+		{"unknown", "", nil, map[string]interface{}{tagRequestContentLength: int64(0)}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mt.Reset()
+			var body io.Reader
+			if tt.body != "" {
+				body = strings.NewReader(tt.body)
+			}
+			span, _ := StartRequestSpan(httptest.NewRequest(http.MethodPost, "/", body))
+			FinishRequestSpan(span, http.StatusOK, tt.resp)
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			tags := spans[0].Tags()
+			for _, k := range []string{tagRequestContentLength, tagResponseContentLength} {
+				assert.Equal(t, tt.want[k], tags[k], k)
+			}
+		})
+	}
+
+	t.Run("chunked", func(t *testing.T) {
+		mt.Reset()
+		r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("hello"))
+		r.ContentLength = -1
+		span, _ := StartRequestSpan(r)
+		FinishRequestSpan(span, http.StatusOK, nil)
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.NotContains(t, spans[0].Tags(), tagRequestContentLength)
+	})
+}
+
+func TestCountingBody(t *testing.T) {
+	body := CountBody(ioutil.NopCloser(strings.NewReader("hello, world")))
+	buf := make([]byte, 5)
+	_, err := io.ReadFull(body, buf)
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), body.BytesRead())
+	_, err = ioutil.ReadAll(body)
+	require.NoError(t, err)
+	assert.Equal(t, int64(12), body.BytesRead())
+	assert.NoError(t, body.Close())
+}
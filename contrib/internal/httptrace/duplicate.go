@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package httptrace
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// maxStackDepth is the maximum number of frames of the stacks of the middlewares
+// reported when a request is traced twice.
+const maxStackDepth = 16
+
+// duplicateWarnRate is the minimum interval between two warnings about requests
+// traced twice, which happens for every request once middlewares are nested.
+const duplicateWarnRate = time.Minute
+
+// lastDuplicateWarn holds the time, in Unix nanoseconds, of the last warning about a
+// request traced twice.
+var lastDuplicateWarn int64
+
+// requestSpanKey is the context key of the request traced by StartRequestSpan.
+type requestSpanKey struct{}
+
+// tracedRequest describes a request traced by StartRequestSpan. It is stored in the
+// context of the request span so that the nested tracing middlewares, e.g. those of
+// nested routers which are both instrumented, can be detected.
+type tracedRequest struct {
+	header http.Header // headers of the request, shared by its shallow copies
+	mw     *Middleware // middleware which traced the request, if known
+}
+
+// callers returns the stack of the caller, skipping skip frames as runtime.Callers does.
+func callers(skip int) []uintptr {
+	pcs := make([]uintptr, maxStackDepth)
+	return pcs[:runtime.Callers(skip, pcs)]
+}
+
+// withTracedRequest returns a copy of ctx holding the request r, traced by the middleware
+// mw, if known, after having warned about r being traced already by another middleware.
+func withTracedRequest(ctx context.Context, r *http.Request, mw *Middleware) context.Context {
+	if outer, ok := r.Context().Value(requestSpanKey{}).(*tracedRequest); ok && outer.is(r) {
+		warnDuplicate(r, outer.mw, mw)
+	}
+	return context.WithValue(ctx, requestSpanKey{}, &tracedRequest{header: r.Header, mw: mw})
+}
+
+// is reports whether r is the traced request t or one of its copies, as made by the
+// routers and http.Request.WithContext, as opposed to a new request sent from within
+// the handler of t.
+func (t *tracedRequest) is(r *http.Request) bool {
+	if t.header == nil || r.Header == nil {
+		return false
+	}
+	return reflect.ValueOf(t.header).Pointer() == reflect.ValueOf(r.Header).Pointer()
+}
+
+// warnDuplicate logs, at most once per duplicateWarnRate, that the request r is traced
+// both by the middleware outer and by the middleware inner. When the inner middleware isn't
+// known, the stack of its call to StartRequestSpan is reported instead.
+func warnDuplicate(r *http.Request, outer, inner *Middleware) {
+	now := time.Now().UnixNano()
+	last := atomic.LoadInt64(&lastDuplicateWarn)
+	if last != 0 && now-last < int64(duplicateWarnRate) {
+		return
+	}
+	if !atomic.CompareAndSwapInt64(&lastDuplicateWarn, last, now) {
+		return
+	}
+	var outerStack, innerStack []uintptr
+	if outer != nil {
+		outerStack = outer.stack
+	}
+	if inner != nil {
+		innerStack = inner.stack
+	} else {
+		// skip runtime.Callers, callers, warnDuplicate, withTracedRequest, startRequestSpan
+		// and StartRequestSpan
+		innerStack = callers(6)
+	}
+	log.Warn("Request %s %s is traced twice, by nested tracing middlewares, which results in duplicate spans. "+
+		"Remove one of them. Outer middleware:%s\nInner middleware:%s", r.Method, r.URL.Path, formatStack(outerStack), formatStack(innerStack))
+}
+
+// formatStack returns the frames of stack, one per line.
+func formatStack(stack []uintptr) string {
+	if len(stack) == 0 {
+		return " unknown"
+	}
+	var b strings.Builder
+	frames := runtime.CallersFrames(stack)
+	for {
+		f, more := frames.Next()
+		if f.Function != "" {
+			fmt.Fprintf(&b, "\n\t%s (%s:%d)", f.Function, f.File, f.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
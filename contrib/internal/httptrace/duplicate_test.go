@@ -0,0 +1,110 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package httptrace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+func TestDuplicateMiddleware(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	defer func(last int64) { lastDuplicateWarn = last }(lastDuplicateWarn)
+	var rl log.RecordLogger
+	defer log.UseLogger(&rl)()
+
+	middleware := func(h http.Handler) http.Handler {
+		mw := NewMiddleware()
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			span, ctx := mw.StartRequestSpan(r)
+			defer FinishRequestSpan(span, http.StatusOK, nil)
+			h.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+	unregistered := func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			span, ctx := StartRequestSpan(r)
+			defer FinishRequestSpan(span, http.StatusOK, nil)
+			h.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+
+	t.Run("single", func(t *testing.T) {
+		lastDuplicateWarn = 0
+		middleware(handler).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+		assert.Empty(t, rl.Logs())
+	})
+
+	t.Run("sub-request", func(t *testing.T) {
+		lastDuplicateWarn = 0
+		sub := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			req := httptest.NewRequest("GET", "/orders", nil).WithContext(r.Context())
+			middleware(handler).ServeHTTP(w, req)
+		})
+		middleware(sub).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+		assert.Empty(t, rl.Logs())
+	})
+
+	t.Run("nested", func(t *testing.T) {
+		lastDuplicateWarn = 0
+		h := middleware(middleware(handler))
+		for i := 0; i < 3; i++ {
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+		}
+		logs := rl.Logs()
+		require.Len(t, logs, 1)
+		assert.Contains(t, logs[0], "Request GET /users is traced twice")
+		outer := logs[0][strings.Index(logs[0], "Outer middleware:"):strings.Index(logs[0], "Inner middleware:")]
+		inner := logs[0][strings.Index(logs[0], "Inner middleware:"):]
+		// the middlewares are reported where they were constructed
+		assert.Contains(t, outer, "duplicate_test.go")
+		assert.Contains(t, inner, "duplicate_test.go")
+		assert.NotContains(t, outer, "ServeHTTP")
+		assert.NotContains(t, inner, "ServeHTTP")
+	})
+
+	t.Run("unregistered", func(t *testing.T) {
+		var rl log.RecordLogger
+		defer log.UseLogger(&rl)()
+		lastDuplicateWarn = 0
+		unregistered(unregistered(handler)).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/users", nil))
+		logs := rl.Logs()
+		require.Len(t, logs, 1)
+		outer := logs[0][strings.Index(logs[0], "Outer middleware:"):strings.Index(logs[0], "Inner middleware:")]
+		inner := logs[0][strings.Index(logs[0], "Inner middleware:"):]
+		assert.Contains(t, outer, "unknown")
+		// the stack of the call to StartRequestSpan is reported instead
+		assert.Contains(t, inner, "httptrace.TestDuplicateMiddleware")
+		assert.NotContains(t, inner, "httptrace.warnDuplicate")
+	})
+	assert.Len(t, mt.FinishedSpans(), 11)
+}
+
+func BenchmarkStartRequestSpan(b *testing.B) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	mw := NewMiddleware()
+	r := httptest.NewRequest("GET", "/users", nil)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		span, _ := mw.StartRequestSpan(r)
+		span.Finish()
+		if i%1000 == 0 {
+			mt.Reset()
+		}
+	}
+}
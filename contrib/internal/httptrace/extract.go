@@ -0,0 +1,28 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package httptrace
+
+import (
+	"net/http"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+// extractSpanContext returns the span context propagated in the headers h, extracted by
+// the global tracer or else by tracer.ExtractFallback. The error is the one of the global
+// tracer when neither of them extracts a span context.
+func extractSpanContext(h http.Header) (ddtrace.SpanContext, error) {
+	carrier := tracer.HTTPHeadersCarrier(h)
+	spanctx, err := tracer.Extract(carrier)
+	if err == nil {
+		return spanctx, nil
+	}
+	if spanctx, ferr := tracer.ExtractFallback(carrier); ferr == nil {
+		return spanctx, nil
+	}
+	return nil, err
+}
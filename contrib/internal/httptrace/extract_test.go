@@ -0,0 +1,77 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package httptrace
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+func TestExtractSpanContextFallback(t *testing.T) {
+	os.Setenv("DD_TRACE_PROPAGATION_STYLE_EXTRACT_FALLBACK", "tracecontext,b3")
+	defer os.Unsetenv("DD_TRACE_PROPAGATION_STYLE_EXTRACT_FALLBACK")
+	tracer.Start(tracer.WithLogger(log.DiscardLogger{}))
+	defer tracer.Stop()
+
+	w3c := http.Header{"Traceparent": {"00-00000000000000000000000000000001-0000000000000002-01"}}
+	b3 := http.Header{"X-B3-Traceid": {"0000000000000003"}, "X-B3-Spanid": {"0000000000000004"}}
+	both := http.Header{}
+	for _, h := range []http.Header{w3c, b3} {
+		for k, v := range h {
+			both[k] = v
+		}
+	}
+
+	for name, tt := range map[string]struct {
+		headers         http.Header
+		traceID, spanID uint64
+	}{
+		"tracecontext": {w3c, 1, 2},
+		"b3":           {b3, 3, 4},
+		"both":         {both, 1, 2},
+	} {
+		t.Run(name, func(t *testing.T) {
+			spanctx, err := extractSpanContext(tt.headers)
+			require.NoError(t, err)
+			assert.Equal(t, tt.traceID, spanctx.TraceID())
+			assert.Equal(t, tt.spanID, spanctx.SpanID())
+		})
+	}
+
+	t.Run("none", func(t *testing.T) {
+		_, err := extractSpanContext(http.Header{})
+		assert.Equal(t, tracer.ErrSpanContextNotFound, err)
+	})
+
+	t.Run("datadog", func(t *testing.T) {
+		// the span contexts extracted by the global tracer take precedence
+		parent := tracer.StartSpan("parent")
+		h := http.Header{}
+		require.NoError(t, tracer.Inject(parent.Context(), tracer.HTTPHeadersCarrier(h)))
+		for k, v := range w3c {
+			h[k] = v
+		}
+		spanctx, err := extractSpanContext(h)
+		require.NoError(t, err)
+		assert.Equal(t, parent.Context().SpanID(), spanctx.SpanID())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		// the fallback is only enabled for the tracer
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		_, err := extractSpanContext(w3c)
+		assert.Equal(t, tracer.ErrSpanContextNotFound, err)
+	})
+}
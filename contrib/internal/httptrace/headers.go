@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package httptrace
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/internal"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+// tagSessionHash holds the hash of the session header value of the request.
+const tagSessionHash = "http.session.hash"
+
+const (
+	// requestHeaderTagPrefix prefixes the default tags of the request headers recorded
+	// with tracer.WithHeaderTags or DD_TRACE_HEADER_TAGS.
+	requestHeaderTagPrefix = "http.request.headers."
+	// responseHeaderTagPrefix prefixes the default tags of the response headers recorded
+	// with tracer.WithHeaderTags or DD_TRACE_HEADER_TAGS.
+	responseHeaderTagPrefix = "http.response.headers."
+)
+
+var (
+	// omitCookies reports whether the Cookie and Set-Cookie headers must never be recorded.
+	omitCookies = internal.BoolEnv("DD_TRACE_HTTP_OMIT_COOKIES", false)
+	// sessionHeader is the header holding the session identifier of the requests, whose value
+	// is recorded hashed rather than in clear.
+	sessionHeader = http.CanonicalHeaderKey(strings.TrimSpace(os.Getenv("DD_TRACE_HTTP_SESSION_HEADER")))
+)
+
+// RecordHeader reports whether the value of the HTTP header with the given name may be recorded
+// in span tags. The Datadog propagation headers are never recorded, and neither are the Cookie
+// and Set-Cookie headers when DD_TRACE_HTTP_OMIT_COOKIES is true, nor the session header set by
+// DD_TRACE_HTTP_SESSION_HEADER, which is only recorded hashed.
+func RecordHeader(name string) bool {
+	name = http.CanonicalHeaderKey(name)
+	switch {
+	case strings.HasPrefix(name, "X-Datadog-"):
+		return false
+	case omitCookies && (name == "Cookie" || name == "Set-Cookie"):
+		return false
+	case sessionHeader != "" && name == sessionHeader:
+		return false
+	}
+	return true
+}
+
+// sessionHash returns the hash of the value of the session header of the request r, which
+// allows searching the traces of a session without recording its identifier. It returns
+// an empty string when no session header is configured or the request has none.
+func sessionHash(r *http.Request) string {
+	if sessionHeader == "" {
+		return ""
+	}
+	v := r.Header.Get(sessionHeader)
+	if v == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(sum[:8])
+}
+
+// headerTags calls fn with the tag and value of every header of h which is configured to be
+// recorded with tracer.WithHeaderTags or DD_TRACE_HEADER_TAGS. The headers are recorded under
+// prefix followed by their lowercased name, unless a tag was configured for them.
+func headerTags(h http.Header, prefix string, fn func(tag, value string)) {
+	if globalconfig.HeaderTagsLen() == 0 {
+		return
+	}
+	globalconfig.ForEachHeaderTag(func(header, tag string) {
+		vals := h.Values(header)
+		if len(vals) == 0 || !RecordHeader(header) {
+			return
+		}
+		if tag == "" {
+			tag = prefix + strings.ToLower(header)
+		}
+		fn(tag, strings.Join(vals, ","))
+	})
+}
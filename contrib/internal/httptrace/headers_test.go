@@ -0,0 +1,116 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package httptrace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+func TestRecordHeader(t *testing.T) {
+	defer func(omit bool, session string) {
+		omitCookies, sessionHeader = omit, session
+	}(omitCookies, sessionHeader)
+
+	t.Run("defaults", func(t *testing.T) {
+		omitCookies, sessionHeader = false, ""
+		assert.True(t, RecordHeader("User-Agent"))
+		assert.True(t, RecordHeader("cookie"))
+		assert.True(t, RecordHeader("Set-Cookie"))
+		assert.False(t, RecordHeader("x-datadog-trace-id"))
+		assert.False(t, RecordHeader("X-Datadog-Parent-Id"))
+	})
+
+	t.Run("omit-cookies", func(t *testing.T) {
+		omitCookies, sessionHeader = true, ""
+		assert.True(t, RecordHeader("User-Agent"))
+		assert.False(t, RecordHeader("cookie"))
+		assert.False(t, RecordHeader("Set-Cookie"))
+	})
+
+	t.Run("session", func(t *testing.T) {
+		omitCookies, sessionHeader = false, "X-Session-Id"
+		assert.False(t, RecordHeader("x-session-id"))
+		assert.True(t, RecordHeader("X-Request-Id"))
+	})
+}
+
+func TestSessionHash(t *testing.T) {
+	defer func(session string) { sessionHeader = session }(sessionHeader)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	start := func(session string) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if session != "" {
+			r.Header.Set("X-Session-Id", session)
+		}
+		s, _ := StartRequestSpan(r)
+		s.Finish()
+	}
+
+	sessionHeader = ""
+	start("abc")
+	sessionHeader = "X-Session-Id"
+	start("abc")
+	start("abc")
+	start("def")
+	start("")
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 5)
+	assert.Nil(t, spans[0].Tag(tagSessionHash))
+	hash := spans[1].Tag(tagSessionHash)
+	assert.Equal(t, "ba7816bf8f01cfea", hash)
+	assert.Equal(t, hash, spans[2].Tag(tagSessionHash))
+	assert.NotEqual(t, hash, spans[3].Tag(tagSessionHash))
+	assert.Nil(t, spans[4].Tag(tagSessionHash))
+	for _, s := range spans {
+		for k, v := range s.Tags() {
+			assert.NotEqual(t, "abc", v, k)
+		}
+	}
+}
+
+func TestHeaderTags(t *testing.T) {
+	defer globalconfig.ClearHeaderTags()
+	globalconfig.SetHeaderTag("x-request-id", "")
+	globalconfig.SetHeaderTag("X-Tenant", "tenant.id")
+	globalconfig.SetHeaderTag("Content-Type", "")
+	globalconfig.SetHeaderTag("X-Datadog-Origin", "")
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Add("X-Request-Id", "abc")
+	r.Header.Add("X-Request-Id", "def")
+	r.Header.Set("X-Tenant", "acme")
+	r.Header.Set("X-Datadog-Origin", "synthetics")
+	r.Header.Set("X-Other", "ignored")
+	s, _ := StartRequestSpan(r)
+	h := http.Header{}
+	h.Set("Content-Type", "text/plain")
+	h.Set("X-Other", "ignored")
+	FinishRequestSpan(s, http.StatusOK, &Response{Header: h})
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	tags := spans[0].Tags()
+	assert.Equal(t, "abc,def", tags["http.request.headers.x-request-id"])
+	assert.Equal(t, "acme", tags["tenant.id"])
+	assert.Equal(t, "text/plain", tags["http.response.headers.content-type"])
+	assert.NotContains(t, tags, "http.request.headers.content-type")
+	assert.NotContains(t, tags, "http.request.headers.x-datadog-origin")
+	assert.NotContains(t, tags, "http.request.headers.x-other")
+	assert.NotContains(t, tags, "http.response.headers.x-other")
+}
@@ -9,10 +9,13 @@ package httptrace
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
+	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -21,8 +24,25 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
 )
 
+// clientIPStrategyRightmost is the value of DD_TRACE_CLIENT_IP_STRATEGY selecting the
+// rightmost global IP of the IP headers.
+const clientIPStrategyRightmost = "rightmost"
+
+// tagStreamErrorCode holds the application error code of the stream error, when known.
+const tagStreamErrorCode = "http.stream.error_code"
+
+// traceIDHeader is the response header echoing the ID of the trace of a request, which
+// allows support tooling to look the trace up from a response.
+const traceIDHeader = "X-Datadog-Trace-Id"
+
+// serverTimingHeader is the response header through which browsers expose the trace
+// context of a request to the Real User Monitoring SDKs.
+const serverTimingHeader = "Server-Timing"
+
 var (
 	ipv6SpecialNetworks = []*netaddr.IPPrefix{
 		ippref("fec0::/10"), // site local
@@ -39,17 +59,73 @@ var (
 		"true-client-ip",
 	}
 	clientIPHeader = os.Getenv("DD_TRACE_CLIENT_IP_HEADER")
+	// clientIPHeaders lists the headers looked up for the client IP, by order of priority, as
+	// configured with DD_TRACE_CLIENT_IP_HEADERS. It takes precedence over clientIPHeader.
+	clientIPHeaders = parseIPHeaders(os.Getenv("DD_TRACE_CLIENT_IP_HEADERS"))
+	// clientIPStrategy selects the IP picked among the nodes of the IP headers: the leftmost
+	// global IP by default, or the rightmost one when set to "rightmost", which can't be
+	// spoofed by clients as long as the proxies in front of the application are trusted.
+	clientIPStrategy = strings.ToLower(strings.TrimSpace(os.Getenv("DD_TRACE_CLIENT_IP_STRATEGY")))
+	// trustedProxies lists the networks of the proxies trusted to set the IP headers, as
+	// configured with DD_TRACE_CLIENT_IP_TRUSTED_PROXIES. When set, the IP headers of requests
+	// whose remote address is out of these networks are ignored.
+	trustedProxies = parseTrustedProxies(os.Getenv("DD_TRACE_CLIENT_IP_TRUSTED_PROXIES"))
+	// rateLimitHeaders lists the rate limiting response headers along with the span tags
+	// their values are recorded as.
+	rateLimitHeaders = []struct{ header, tag string }{
+		{"Retry-After", "http.retry_after"},
+		{"X-RateLimit-Limit", "http.ratelimit.limit"},
+		{"X-RateLimit-Remaining", "http.ratelimit.remaining"},
+		{"X-RateLimit-Reset", "http.ratelimit.reset"},
+	}
 )
 
+// Middleware holds the configuration of a tracing middleware. It is identified by the stack of the code which
+// constructed it, reported when the requests it traces are also traced by another middleware. Integrations should
+// create it once, when their middleware is constructed, with NewMiddleware, and start the spans of the requests with
+// Middleware.StartRequestSpan. A nil Middleware stands for an unknown middleware with the default configuration.
+type Middleware struct {
+	stack []uintptr // stack of the code which constructed the middleware
+
+	// queryStringRegexp replaces the global queryStringRegexp when hasQueryStringRegexp is set.
+	queryStringRegexp    *regexp.Regexp
+	hasQueryStringRegexp bool
+}
+
+// NewMiddleware returns a new Middleware identified by the stack of its caller.
+func NewMiddleware() *Middleware {
+	// skip runtime.Callers, callers and NewMiddleware
+	return &Middleware{stack: callers(3)}
+}
+
 // StartRequestSpan starts an HTTP request span with the standard list of HTTP request span tags (http.method, http.url,
-// http.useragent). Any further span start option can be added with opts.
+// http.useragent), along with the request headers configured with tracer.WithHeaderTags or DD_TRACE_HEADER_TAGS.
+// Any further span start option can be added with opts. A warning is logged when the request is traced already by
+// another middleware, e.g. by those of nested routers which are both instrumented. Middlewares should rather start
+// their spans with Middleware.StartRequestSpan, so that they can be told apart in the warning.
+// The query string of the request is only recorded in http.url when requested with the WithQueryString option.
+// The span context propagated by the request is extracted with the global tracer, or else with the propagation styles
+// of DD_TRACE_PROPAGATION_STYLE_EXTRACT_FALLBACK, see tracer.ExtractFallback, so that the traces of callers
+// instrumented with OpenTelemetry aren't broken.
 func StartRequestSpan(r *http.Request, opts ...ddtrace.StartSpanOption) (tracer.Span, context.Context) {
+	return startRequestSpan(nil, r, opts...)
+}
+
+// StartRequestSpan starts an HTTP request span as StartRequestSpan does, for a request traced by the middleware m,
+// which is reported along with the other middleware tracing the request, if any.
+func (m *Middleware) StartRequestSpan(r *http.Request, opts ...ddtrace.StartSpanOption) (tracer.Span, context.Context) {
+	return startRequestSpan(m, r, opts...)
+}
+
+func startRequestSpan(m *Middleware, r *http.Request, opts ...ddtrace.StartSpanOption) (tracer.Span, context.Context) {
 	// Append our span options before the given ones so that the caller can "overwrite" them.
 	opts = append([]ddtrace.StartSpanOption{
 		tracer.SpanType(ext.SpanTypeWeb),
+		tracer.Tag(ext.SpanKind, ext.SpanKindServer),
 		tracer.Tag(ext.HTTPMethod, r.Method),
-		tracer.Tag(ext.HTTPURL, r.URL.Path),
+		tracer.Tag(ext.HTTPURL, QuantizePath(r.URL.Path)),
 		tracer.Tag(ext.HTTPUserAgent, r.UserAgent()),
+		tracer.Tag(ext.HTTPVersion, ProtoVersion(r)),
 		tracer.Measured(),
 	}, opts...)
 	if r.Host != "" {
@@ -57,18 +133,53 @@ func StartRequestSpan(r *http.Request, opts ...ddtrace.StartSpanOption) (tracer.
 			tracer.Tag("http.host", r.Host),
 		}, opts...)
 	}
-	if ip := getClientIP(r); ip.IsValid() {
+	if ip := clientIP(r); ip.IsValid() {
 		opts = append(opts, tracer.Tag(ext.HTTPClientIP, ip.String()))
+		if hook := globalconfig.ClientIPHook(); hook != nil {
+			for k, v := range hook(ip.IPAddr().IP) {
+				opts = append(opts, tracer.Tag(k, v))
+			}
+		}
+	}
+	if r.ContentLength >= 0 {
+		opts = append(opts, tracer.Tag(tagRequestContentLength, r.ContentLength))
 	}
-	if spanctx, err := tracer.Extract(tracer.HTTPHeadersCarrier(r.Header)); err == nil {
+	if h := sessionHash(r); h != "" {
+		opts = append(opts, tracer.Tag(tagSessionHash, h))
+	}
+	headerTags(r.Header, requestHeaderTagPrefix, func(tag, value string) {
+		opts = append(opts, tracer.Tag(tag, value))
+	})
+	ctx := r.Context()
+	if spanctx, err := extractSpanContext(r.Header); err == nil {
 		opts = append(opts, tracer.ChildOf(spanctx))
+	} else if err != tracer.ErrSpanContextNotFound {
+		// the propagation headers are malformed: keep them for the propagation audit
+		// to report the broken trace.
+		ctx = tracer.ContextWithHeaders(ctx, tracer.HTTPHeadersCarrier(r.Header))
 	}
-	return tracer.StartSpanFromContext(r.Context(), "http.request", opts...)
+	opts = append(opts, recordQueryString(m, r))
+	span, ctx := tracer.StartSpanFromContext(ctx, "http.request", opts...)
+	return span, withTracedRequest(ctx, r, m)
+}
+
+// Response describes the response to a request traced by StartRequestSpan, as recorded by FinishRequestSpan.
+type Response struct {
+	// Header holds the headers of the response, recorded as configured with tracer.WithHeaderTags
+	// or DD_TRACE_HEADER_TAGS.
+	Header http.Header
+	// Size holds the size in bytes of the body of the response. When it isn't positive, the
+	// Content-Length header of Header is recorded instead, if any.
+	Size int64
+	// RequestSize holds the size in bytes of the body of the request when it wasn't known as the
+	// request started, such as that of a chunked body counted with CountingBody. It is recorded
+	// when positive.
+	RequestSize int64
 }
 
 // FinishRequestSpan finishes the given HTTP request span and sets the expected response-related tags such as the status
-// code. Any further span finish option can be added with opts.
-func FinishRequestSpan(s tracer.Span, status int, opts ...tracer.FinishOption) {
+// code, along with those of the response resp, if known. Any further span finish option can be added with opts.
+func FinishRequestSpan(s tracer.Span, status int, resp *Response, opts ...tracer.FinishOption) {
 	var statusStr string
 	if status == 0 {
 		statusStr = "200"
@@ -79,9 +190,121 @@ func FinishRequestSpan(s tracer.Span, status int, opts ...tracer.FinishOption) {
 	if status >= 500 && status < 600 {
 		s.SetTag(ext.Error, fmt.Errorf("%s: %s", statusStr, http.StatusText(status)))
 	}
+	if resp != nil {
+		headerTags(resp.Header, responseHeaderTagPrefix, func(tag, value string) {
+			s.SetTag(tag, value)
+		})
+		setContentLengths(s, resp)
+	}
 	s.Finish(opts...)
 }
 
+// ProtoVersion returns the version of the HTTP protocol of the request r as reported
+// by the http.version tag: "1.0", "1.1", "2" or "3". Servers such as quic-go's http3.Server
+// report HTTP/3 requests with a major version of 3.
+func ProtoVersion(r *http.Request) string {
+	if r.ProtoMajor == 0 && r.ProtoMinor == 0 {
+		// requests built by hand don't always set the protocol version
+		return "1.1"
+	}
+	if r.ProtoMajor >= 2 {
+		return strconv.Itoa(r.ProtoMajor)
+	}
+	return strconv.Itoa(r.ProtoMajor) + "." + strconv.Itoa(r.ProtoMinor)
+}
+
+// SetStreamError tags the span s with the error which terminated the stream carrying the request r,
+// if any. err is the error reported while writing the response, if any. Otherwise, the request context
+// being done before the handler returned means the client reset the stream or closed the connection.
+// Stream errors only exist for multiplexed protocols (HTTP/2 and HTTP/3) and are ignored otherwise.
+func SetStreamError(s tracer.Span, r *http.Request, err error) {
+	if r.ProtoMajor < 2 {
+		return
+	}
+	if err == nil {
+		err = r.Context().Err()
+	}
+	if err == nil {
+		return
+	}
+	s.SetTag(ext.HTTPStreamError, err.Error())
+	if code, ok := streamErrorCode(err); ok {
+		s.SetTag(tagStreamErrorCode, code)
+	}
+}
+
+// streamErrorCode returns the application error code carried by err or by any error it wraps,
+// such as the ErrorCode of quic-go's stream and HTTP/3 errors or the Code of x/net's http2.StreamError.
+func streamErrorCode(err error) (uint64, bool) {
+	for ; err != nil; err = errors.Unwrap(err) {
+		v := reflect.ValueOf(err)
+		for v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			continue
+		}
+		for _, name := range []string{"ErrorCode", "Code"} {
+			f := v.FieldByName(name)
+			switch f.Kind() {
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+				return f.Uint(), true
+			}
+		}
+	}
+	return 0, false
+}
+
+// SetRateLimitTags sets the span tags of the rate limiting headers (Retry-After and
+// X-RateLimit-*) found in the response headers h. Headers which are not present are ignored.
+func SetRateLimitTags(s tracer.Span, h http.Header) {
+	for _, rl := range rateLimitHeaders {
+		if v := h.Get(rl.header); v != "" {
+			s.SetTag(rl.tag, v)
+		}
+	}
+}
+
+// SetTraceIDHeader sets the ID of the trace of s in the response headers h, using the
+// X-Datadog-Trace-Id header. It must be called before the response headers are written.
+func SetTraceIDHeader(h http.Header, s ddtrace.Span) {
+	h.Set(traceIDHeader, strconv.FormatUint(s.Context().TraceID(), 10))
+}
+
+// SetServerTimingHeader adds the trace context of s to the response headers h as a W3C
+// traceparent entry of the Server-Timing header, which lets RUM sessions and synthetic
+// tests link frontend and backend traces. It must be called before the response headers
+// are written. Cross-origin pages can only read the header when the response also allows
+// it with the Timing-Allow-Origin header. The trace is flagged as sampled when its sampling
+// priority is known to be positive.
+func SetServerTimingHeader(h http.Header, s ddtrace.Span) {
+	ctx := s.Context()
+	flags := "00"
+	if pctx, ok := ctx.(samplingPrioritySpanContext); ok {
+		if p, ok := pctx.SamplingPriority(); ok && p > 0 {
+			flags = "01"
+		}
+	}
+	traceID := fmt.Sprintf("%032x", ctx.TraceID())
+	if tctx, ok := ctx.(traceID128SpanContext); ok {
+		// keeps the upper 64 bits of the 128-bit trace IDs received from upstream
+		traceID = tctx.TraceID128()
+	}
+	h.Add(serverTimingHeader, fmt.Sprintf(`traceparent;desc="00-%s-%016x-%s"`, traceID, ctx.SpanID(), flags))
+}
+
+// samplingPrioritySpanContext is implemented by the span contexts exposing the sampling
+// priority of their trace.
+type samplingPrioritySpanContext interface {
+	SamplingPriority() (p int, ok bool)
+}
+
+// traceID128SpanContext is implemented by the span contexts exposing the 128-bit ID of
+// their trace, as 32 hexadecimal digits.
+type traceID128SpanContext interface {
+	TraceID128() string
+}
+
 // ippref returns the IP network from an IP address string s. If not possible, it returns nil.
 func ippref(s string) *netaddr.IPPrefix {
 	if prefix, err := netaddr.ParseIPPrefix(s); err == nil {
@@ -90,14 +313,48 @@ func ippref(s string) *netaddr.IPPrefix {
 	return nil
 }
 
-// getClientIP attempts to find the client IP address in the given request r.
+// clientIP returns the client IP address of the request r, resolved by the function set with
+// tracer.WithClientIPResolver if any, or by getClientIP otherwise.
+func clientIP(r *http.Request) netaddr.IP {
+	if resolve := globalconfig.ClientIPResolver(); resolve != nil {
+		ip, _ := netaddr.FromStdIP(resolve(r))
+		return ip
+	}
+	return getClientIP(r)
+}
+
+// getClientIP attempts to find the client IP address in the given request r. The IP headers are looked up
+// first, in the order of DD_TRACE_CLIENT_IP_HEADERS when set, the first one holding a global IP being
+// used, and the remote address of the request is used as a fallback. Servers accepting connections
+// through a load balancer using the PROXY protocol get the client address as the remote address, as
+// long as their listener decodes the PROXY protocol header of the connections. The nodes of the IP
+// headers are checked from the rightmost one, the last proxy, when DD_TRACE_CLIENT_IP_STRATEGY is
+// "rightmost", in which case all the lines of the headers are taken into account. When
+// DD_TRACE_CLIENT_IP_TRUSTED_PROXIES is set, the IP headers are only looked up when the remote
+// address of the request belongs to one of the trusted proxy networks, so that clients reaching
+// the application directly can't spoof their IP. The nodes of trusted proxies are then skipped
+// in rightmost mode, the client IP being the one of the first untrusted node.
 func getClientIP(r *http.Request) netaddr.IP {
-	ipHeaders := defaultIPHeaders
-	if len(clientIPHeader) > 0 {
-		ipHeaders = []string{clientIPHeader}
+	remoteIP := parseIP(r.RemoteAddr)
+	if !isTrustedProxy(remoteIP) {
+		if remoteIP.IsValid() && isGlobal(remoteIP) {
+			return remoteIP
+		}
+		return netaddr.IP{}
 	}
-	check := func(s string) netaddr.IP {
-		for _, ipstr := range strings.Split(s, ",") {
+	ipHeaders := clientIPHeaders
+	if len(ipHeaders) == 0 {
+		names := defaultIPHeaders
+		if len(clientIPHeader) > 0 {
+			names = []string{clientIPHeader}
+		}
+		ipHeaders = make([]ipHeader, len(names))
+		for i, name := range names {
+			ipHeaders[i] = newIPHeader(name)
+		}
+	}
+	check := func(nodes []string) netaddr.IP {
+		for _, ipstr := range nodes {
 			ip := parseIP(strings.TrimSpace(ipstr))
 			if !ip.IsValid() {
 				continue
@@ -109,18 +366,216 @@ func getClientIP(r *http.Request) netaddr.IP {
 		return netaddr.IP{}
 	}
 	for _, hdr := range ipHeaders {
-		if v := r.Header.Get(hdr); v != "" {
-			if ip := check(v); ip.IsValid() {
-				return ip
+		v := r.Header.Get(hdr.name)
+		if v == "" {
+			continue
+		}
+		rightmost := clientIPStrategy == clientIPStrategyRightmost
+		if rightmost {
+			// the rightmost nodes are the ones of the last header line
+			v = strings.Join(r.Header.Values(hdr.name), ",")
+		}
+		nodes := strings.Split(v, ",")
+		if hdr.forwarded {
+			// the elements of the Forwarded header can be spread over several header lines
+			nodes = forwardedFor(strings.Join(r.Header.Values(hdr.name), ","))
+		}
+		if rightmost {
+			for i, j := 0, len(nodes)-1; i < j; i, j = i+1, j-1 {
+				nodes[i], nodes[j] = nodes[j], nodes[i]
+			}
+			if len(trustedProxies) > 0 {
+				if ip := untrustedHop(nodes); ip.IsValid() {
+					return ip
+				}
+				continue
 			}
 		}
+		if ip := check(nodes); ip.IsValid() {
+			return ip
+		}
 	}
-	if remoteIP := parseIP(r.RemoteAddr); remoteIP.IsValid() && isGlobal(remoteIP) {
+	if remoteIP.IsValid() && isGlobal(remoteIP) {
 		return remoteIP
 	}
 	return netaddr.IP{}
 }
 
+// untrustedHop returns the first node of nodes, ordered from the last proxy, which is not a
+// trusted proxy, provided that it is a global IP. The nodes before it can't be trusted, and are
+// never returned.
+func untrustedHop(nodes []string) netaddr.IP {
+	for _, ipstr := range nodes {
+		ip := parseIP(strings.TrimSpace(ipstr))
+		if !ip.IsValid() || isTrustedProxy(ip) {
+			continue
+		}
+		if isGlobal(ip) {
+			return ip
+		}
+		break
+	}
+	return netaddr.IP{}
+}
+
+// isTrustedProxy reports whether the IP headers set by the peer ip can be trusted, which is
+// always the case when no trusted proxy is configured.
+func isTrustedProxy(ip netaddr.IP) bool {
+	if len(trustedProxies) == 0 {
+		return true
+	}
+	if !ip.IsValid() {
+		return false
+	}
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTrustedProxies parses the comma-separated list of networks v in CIDR notation
+// (e.g. "10.0.0.0/8,fd00::/8"). Bare IP addresses are parsed as single-address networks.
+// Invalid entries are skipped.
+func parseTrustedProxies(v string) []netaddr.IPPrefix {
+	var prefixes []netaddr.IPPrefix
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip, err := netaddr.ParseIP(entry)
+			if err != nil {
+				log.Warn("Ignoring trusted proxy %q of DD_TRACE_CLIENT_IP_TRUSTED_PROXIES: %v", entry, err)
+				continue
+			}
+			prefixes = append(prefixes, netaddr.IPPrefixFrom(ip, ip.BitLen()))
+			continue
+		}
+		prefix, err := netaddr.ParseIPPrefix(entry)
+		if err != nil {
+			log.Warn("Ignoring trusted proxy %q of DD_TRACE_CLIENT_IP_TRUSTED_PROXIES: %v", entry, err)
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
+
+// ipHeader is a header looked up for the client IP, along with the syntax of its value.
+type ipHeader struct {
+	name string
+	// forwarded reports whether the value follows the syntax of the RFC 7239 Forwarded
+	// header, rather than being a comma-separated list of addresses.
+	forwarded bool
+}
+
+// newIPHeader returns the ipHeader of the header name, parsed according to RFC 7239 when it is
+// the Forwarded header, or as a comma-separated list of addresses otherwise.
+func newIPHeader(name string) ipHeader {
+	return ipHeader{name: name, forwarded: strings.EqualFold(name, "forwarded")}
+}
+
+// parseIPHeaders parses the comma-separated list of IP headers v, ordered by priority, whose
+// entries are header names optionally followed by the syntax of their values: "forwarded" for
+// the syntax of the RFC 7239 Forwarded header, or "list" for a comma-separated list of addresses
+// (e.g. "cf-connecting-ip,x-edge-forwarded:forwarded,x-forwarded-for:list"). The syntax defaults
+// to the one of newIPHeader. Entries with an unknown syntax are skipped.
+func parseIPHeaders(v string) []ipHeader {
+	var headers []ipHeader
+	for _, entry := range strings.Split(v, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		i := strings.IndexByte(entry, ':')
+		if i < 0 {
+			headers = append(headers, newIPHeader(entry))
+			continue
+		}
+		name, mode := strings.TrimSpace(entry[:i]), strings.ToLower(strings.TrimSpace(entry[i+1:]))
+		switch mode {
+		case "forwarded":
+			headers = append(headers, ipHeader{name: name, forwarded: true})
+		case "list":
+			headers = append(headers, ipHeader{name: name})
+		default:
+			log.Warn("Ignoring client IP header %q of DD_TRACE_CLIENT_IP_HEADERS: unknown syntax %q, expecting \"forwarded\" or \"list\".", name, mode)
+		}
+	}
+	return headers
+}
+
+// forwardedFor returns the nodes identified by the for= parameters of the elements of the RFC 7239
+// Forwarded header value v (e.g. `for=192.0.2.60;proto=http;by=203.0.113.43, for="[2001:db8::17]:4711"`),
+// in the order of the proxies they went through. The other parameters, such as proto= and host=, are
+// skipped. Elements made of a bare address rather than parameters are returned as they are, as some
+// proxies use this header like X-Forwarded-For.
+func forwardedFor(v string) []string {
+	var nodes []string
+	for _, elem := range splitQuoted(v, ',') {
+		elem = strings.TrimSpace(elem)
+		if !strings.Contains(elem, "=") {
+			nodes = append(nodes, elem)
+			continue
+		}
+		for _, pair := range splitQuoted(elem, ';') {
+			i := strings.IndexByte(pair, '=')
+			if i < 0 || !strings.EqualFold(strings.TrimSpace(pair[:i]), "for") {
+				continue
+			}
+			node := unquote(strings.TrimSpace(pair[i+1:]))
+			// IPv6 nodes are enclosed in brackets, with or without a port
+			if strings.HasPrefix(node, "[") && strings.HasSuffix(node, "]") {
+				node = node[1 : len(node)-1]
+			}
+			nodes = append(nodes, node)
+		}
+	}
+	return nodes
+}
+
+// splitQuoted splits s around each instance of sep which is not part of a quoted string.
+func splitQuoted(s string, sep byte) []string {
+	var (
+		parts   []string
+		quoted  bool
+		escaped bool
+		start   int
+	)
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case escaped:
+			escaped = false
+		case quoted && c == '\\':
+			escaped = true
+		case c == '"':
+			quoted = !quoted
+		case !quoted && c == sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unquote returns the value of the quoted string s, or s when it is not quoted.
+func unquote(s string) string {
+	if len(s) < 2 || s[0] != '"' || s[len(s)-1] != '"' {
+		return s
+	}
+	var b strings.Builder
+	for i := 1; i < len(s)-1; i++ {
+		if s[i] == '\\' && i+1 < len(s)-1 {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
 func parseIP(s string) netaddr.IP {
 	if ip, err := netaddr.ParseIP(s); err == nil {
 		return ip
@@ -6,9 +6,14 @@
 package httptrace
 
 import (
+	"context"
+	"fmt"
 	"math/rand"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"strconv"
 	"testing"
 
 	"inet.af/netaddr"
@@ -16,7 +21,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
 )
 
 func TestStartRequestSpan(t *testing.T) {
@@ -29,6 +39,177 @@ func TestStartRequestSpan(t *testing.T) {
 
 	require.Len(t, spans, 1)
 	assert.Equal(t, "example.com", spans[0].Tag("http.host"))
+	assert.Equal(t, ext.SpanKindServer, spans[0].Tag(ext.SpanKind))
+}
+
+func TestClientIPHook(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	defer globalconfig.SetClientIPHook(nil)
+	var called []string
+	globalconfig.SetClientIPHook(func(ip net.IP) map[string]string {
+		called = append(called, ip.String())
+		return map[string]string{
+			"network.client.geoip.country.iso_code": "NZ",
+			"network.client.geoip.as.number":        "AS13335",
+		}
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Forwarded-For", "1.1.1.1")
+	s, _ := StartRequestSpan(r)
+	s.Finish()
+	// no hook call for requests without a public client IP
+	r = httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	s, _ = StartRequestSpan(r)
+	s.Finish()
+
+	assert.Equal(t, []string{"1.1.1.1"}, called)
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "NZ", spans[0].Tag("network.client.geoip.country.iso_code"))
+	assert.Equal(t, "AS13335", spans[0].Tag("network.client.geoip.as.number"))
+	assert.Nil(t, spans[1].Tag("network.client.geoip.country.iso_code"))
+}
+
+func TestSetRateLimitTags(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	h := http.Header{}
+	h.Set("Retry-After", "120")
+	h.Set("X-Ratelimit-Remaining", "0")
+	h.Set("X-RateLimit-Limit", "100")
+	s, _ := StartRequestSpan(httptest.NewRequest(http.MethodGet, "/", nil))
+	SetRateLimitTags(s, h)
+	s.Finish()
+
+	span := mt.FinishedSpans()[0]
+	assert.Equal(t, "120", span.Tag("http.retry_after"))
+	assert.Equal(t, "100", span.Tag("http.ratelimit.limit"))
+	assert.Equal(t, "0", span.Tag("http.ratelimit.remaining"))
+	assert.Nil(t, span.Tag("http.ratelimit.reset"))
+}
+
+func TestSetTraceIDHeader(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	h := http.Header{}
+	s, _ := StartRequestSpan(httptest.NewRequest(http.MethodGet, "/", nil))
+	SetTraceIDHeader(h, s)
+	s.Finish()
+
+	assert.Equal(t, strconv.FormatUint(s.Context().TraceID(), 10), h.Get("X-Datadog-Trace-Id"))
+}
+
+func TestSetServerTimingHeader(t *testing.T) {
+	for name, tt := range map[string]struct {
+		opts  []ddtrace.StartSpanOption
+		flags string
+	}{
+		"keep":        {[]ddtrace.StartSpanOption{tracer.Tag(ext.SamplingPriority, ext.PriorityAutoKeep)}, "01"},
+		"user-keep":   {[]ddtrace.StartSpanOption{tracer.Tag(ext.SamplingPriority, ext.PriorityUserKeep)}, "01"},
+		"reject":      {[]ddtrace.StartSpanOption{tracer.Tag(ext.SamplingPriority, ext.PriorityAutoReject)}, "00"},
+		"user-reject": {[]ddtrace.StartSpanOption{tracer.Tag(ext.SamplingPriority, ext.PriorityUserReject)}, "00"},
+		"unknown":     {nil, "00"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+			h := http.Header{}
+			s, _ := StartRequestSpan(httptest.NewRequest(http.MethodGet, "/", nil), tt.opts...)
+			SetServerTimingHeader(h, s)
+			s.Finish()
+
+			ctx := s.Context()
+			want := fmt.Sprintf(`traceparent;desc="00-%032x-%016x-%s"`, ctx.TraceID(), ctx.SpanID(), tt.flags)
+			assert.Equal(t, want, h.Get("Server-Timing"))
+		})
+	}
+}
+
+func TestSetServerTimingHeader128BitTraceID(t *testing.T) {
+	os.Setenv("DD_TRACE_PROPAGATION_STYLE_EXTRACT", "tracecontext")
+	defer os.Unsetenv("DD_TRACE_PROPAGATION_STYLE_EXTRACT")
+	tracer.Start(tracer.WithLogger(log.DiscardLogger{}))
+	defer tracer.Stop()
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	h := http.Header{}
+	s, _ := StartRequestSpan(r)
+	SetServerTimingHeader(h, s)
+	s.Finish()
+
+	want := fmt.Sprintf(`traceparent;desc="00-4bf92f3577b34da6a3ce929d0e0e4736-%016x-01"`, s.Context().SpanID())
+	assert.Equal(t, want, h.Get("Server-Timing"))
+}
+
+func TestProtoVersion(t *testing.T) {
+	for _, tt := range []struct {
+		major, minor int
+		want         string
+	}{
+		{0, 0, "1.1"},
+		{1, 0, "1.0"},
+		{1, 1, "1.1"},
+		{2, 0, "2"},
+		{3, 0, "3"},
+	} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.ProtoMajor, r.ProtoMinor = tt.major, tt.minor
+		assert.Equal(t, tt.want, ProtoVersion(r))
+	}
+
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	s, _ := StartRequestSpan(httptest.NewRequest(http.MethodGet, "/", nil))
+	s.Finish()
+	assert.Equal(t, "1.1", mt.FinishedSpans()[0].Tag(ext.HTTPVersion))
+}
+
+// h2StreamError mimics golang.org/x/net/http2.StreamError.
+type h2StreamError struct {
+	StreamID uint32
+	Code     uint32
+}
+
+func (e h2StreamError) Error() string { return "stream error: CANCEL" }
+
+func TestSetStreamError(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	newRequest := func(major int, ctx context.Context) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil).WithContext(ctx)
+		r.ProtoMajor, r.ProtoMinor = major, 0
+		return r
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tt := range []struct {
+		name string
+		r    *http.Request
+		err  error
+		msg  interface{}
+		code interface{}
+	}{
+		{"http1", newRequest(1, canceled), nil, nil, nil},
+		{"none", newRequest(2, context.Background()), nil, nil, nil},
+		{"canceled", newRequest(2, canceled), nil, "context canceled", nil},
+		{"write", newRequest(2, canceled), fmt.Errorf("write: %w", h2StreamError{1, 8}), "write: stream error: CANCEL", uint64(8)},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mt.Reset()
+			s, _ := StartRequestSpan(tt.r)
+			SetStreamError(s, tt.r, tt.err)
+			s.Finish()
+
+			span := mt.FinishedSpans()[0]
+			assert.Equal(t, tt.msg, span.Tag(ext.HTTPStreamError))
+			assert.Equal(t, tt.code, span.Tag("http.stream.error_code"))
+		})
+	}
 }
 
 type IPTestCase struct {
@@ -137,6 +318,68 @@ func genIPTestCases() []IPTestCase {
 			expectedIP: netaddr.MustParseIP(ipv6Global),
 		},
 	}, tcs...)
+	// RFC 7239 Forwarded header
+	tcs = append([]IPTestCase{
+		{
+			name:       "forwarded-for",
+			headers:    map[string]string{"forwarded": "for=" + ipv4Global + ";proto=https;host=example.com"},
+			expectedIP: netaddr.MustParseIP(ipv4Global),
+		},
+		{
+			name:       "forwarded-for-port",
+			headers:    map[string]string{"forwarded": `For="` + ipv4Global + `:4711"`},
+			expectedIP: netaddr.MustParseIP(ipv4Global),
+		},
+		{
+			name:       "forwarded-for-ipv6",
+			headers:    map[string]string{"forwarded": `for="[` + ipv6Global + `]:4711";proto=http`},
+			expectedIP: netaddr.MustParseIP(ipv6Global),
+		},
+		{
+			name:       "forwarded-for-ipv6-no-port",
+			headers:    map[string]string{"forwarded": `for="[` + ipv6Global + `]"`},
+			expectedIP: netaddr.MustParseIP(ipv6Global),
+		},
+		{
+			name:       "forwarded-for-private+global",
+			headers:    map[string]string{"forwarded": "for=unknown, for=" + ipv4Private + ";by=" + ipv4Global + ", for=" + ipv4Global},
+			expectedIP: netaddr.MustParseIP(ipv4Global),
+		},
+		{
+			name:       "forwarded-for-quoted-separators",
+			headers:    map[string]string{"forwarded": `host="a;b,c";for=` + ipv4Global},
+			expectedIP: netaddr.MustParseIP(ipv4Global),
+		},
+		{
+			name:       "forwarded-no-for",
+			headers:    map[string]string{"forwarded": "by=" + ipv4Global + ";host=" + ipv4Global},
+			expectedIP: netaddr.IP{},
+		},
+		{
+			name:       "forwarded-obfuscated",
+			headers:    map[string]string{"forwarded": "for=_hidden, for=unknown"},
+			remoteAddr: ipv4Global + ":1234",
+			expectedIP: netaddr.MustParseIP(ipv4Global),
+		},
+	}, tcs...)
+	// remote address, which is the PROXY protocol source address when the listener decodes it
+	tcs = append([]IPTestCase{
+		{
+			name:       "remote-addr",
+			remoteAddr: ipv6Global,
+			expectedIP: netaddr.MustParseIP(ipv6Global),
+		},
+		{
+			name:       "remote-addr-port",
+			remoteAddr: "[" + ipv6Global + "]:1234",
+			expectedIP: netaddr.MustParseIP(ipv6Global),
+		},
+		{
+			name:       "remote-addr-private",
+			remoteAddr: ipv4Private + ":1234",
+			expectedIP: netaddr.IP{},
+		},
+	}, tcs...)
 	tcs = append([]IPTestCase{
 		{
 			name:       "no-headers",
@@ -180,6 +423,84 @@ func TestIPHeaders(t *testing.T) {
 	}
 }
 
+func TestForwardedFor(t *testing.T) {
+	for _, tt := range []struct {
+		in   string
+		want []string
+	}{
+		{"for=192.0.2.43", []string{"192.0.2.43"}},
+		{"for=192.0.2.43, for=198.51.100.17", []string{"192.0.2.43", "198.51.100.17"}},
+		{`for="_gazonk"`, []string{"_gazonk"}},
+		{`For="[2001:db8:cafe::17]:4711"`, []string{"[2001:db8:cafe::17]:4711"}},
+		{`for="[2001:db8:cafe::17]"`, []string{"2001:db8:cafe::17"}},
+		{"for=192.0.2.60;proto=http;by=203.0.113.43", []string{"192.0.2.60"}},
+		{`proto=https;host="example.com,x";for="\_hidden"`, []string{"_hidden"}},
+		{"proto=http;by=203.0.113.43", nil},
+		{"192.0.2.43, 198.51.100.17", []string{"192.0.2.43", "198.51.100.17"}},
+	} {
+		assert.Equal(t, tt.want, forwardedFor(tt.in), tt.in)
+	}
+}
+
+func TestIPHeadersForwardedLines(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Add("Forwarded", "for=10.0.0.1")
+	r.Header.Add("Forwarded", "for=8.8.8.8;proto=https")
+	assert.Equal(t, "8.8.8.8", getClientIP(r).String())
+}
+
+func TestIPHeadersRightmost(t *testing.T) {
+	defer func(s string) { clientIPStrategy = s }(clientIPStrategy)
+	clientIPStrategy = clientIPStrategyRightmost
+	for _, tt := range []struct {
+		name   string
+		header string
+		lines  []string
+		want   string
+	}{
+		{"single", "X-Forwarded-For", []string{"8.8.8.8"}, "8.8.8.8"},
+		{"spoofed", "X-Forwarded-For", []string{"1.1.1.1, 8.8.8.8, 10.0.0.1"}, "8.8.8.8"},
+		{"lines", "X-Forwarded-For", []string{"1.1.1.1", "8.8.8.8, 10.0.0.2"}, "8.8.8.8"},
+		{"forwarded", "Forwarded", []string{"for=1.1.1.1, for=8.8.8.8", "for=10.0.0.1"}, "8.8.8.8"},
+		{"private", "X-Forwarded-For", []string{"10.0.0.1, 10.0.0.2"}, netaddr.IP{}.String()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = "10.0.0.3:1234"
+			for _, l := range tt.lines {
+				r.Header.Add(tt.header, l)
+			}
+			assert.Equal(t, tt.want, getClientIP(r).String())
+		})
+	}
+}
+
+func TestClientIPResolver(t *testing.T) {
+	defer globalconfig.SetClientIPResolver(nil)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	start := func() mocktracer.Span {
+		mt.Reset()
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("X-Forwarded-For", "8.8.8.8")
+		r.Header.Set("X-Real-Client", "1.1.1.1")
+		s, _ := StartRequestSpan(r)
+		s.Finish()
+		return mt.FinishedSpans()[0]
+	}
+
+	assert.Equal(t, "8.8.8.8", start().Tag(ext.HTTPClientIP))
+
+	globalconfig.SetClientIPResolver(func(r *http.Request) net.IP {
+		return net.ParseIP(r.Header.Get("X-Real-Client"))
+	})
+	assert.Equal(t, "1.1.1.1", start().Tag(ext.HTTPClientIP))
+
+	globalconfig.SetClientIPResolver(func(r *http.Request) net.IP { return nil })
+	assert.Nil(t, start().Tag(ext.HTTPClientIP))
+}
+
 func randIPv4() netaddr.IP {
 	return netaddr.IPv4(uint8(rand.Uint32()), uint8(rand.Uint32()), uint8(rand.Uint32()), uint8(rand.Uint32()))
 }
@@ -228,3 +549,98 @@ func randPrivateIPv6() netaddr.IP {
 		}
 	}
 }
+
+func TestParseIPHeaders(t *testing.T) {
+	assert.Nil(t, parseIPHeaders(""))
+	assert.Equal(t, []ipHeader{
+		{name: "cf-connecting-ip"},
+		{name: "X-Edge-Forwarded", forwarded: true},
+		{name: "Forwarded", forwarded: true},
+		{name: "forwarded"},
+	}, parseIPHeaders(" cf-connecting-ip, X-Edge-Forwarded:Forwarded,,Forwarded, forwarded:list,x-other:json"))
+}
+
+func TestIPHeadersPriority(t *testing.T) {
+	defer func(h []ipHeader) { clientIPHeaders = h }(clientIPHeaders)
+	clientIPHeaders = parseIPHeaders("x-edge-client:forwarded,cf-connecting-ip,x-forwarded-for")
+	for _, tt := range []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{"first", map[string]string{"X-Edge-Client": "for=8.8.8.8;proto=https", "Cf-Connecting-Ip": "1.1.1.1"}, "8.8.8.8"},
+		{"second", map[string]string{"Cf-Connecting-Ip": "1.1.1.1", "X-Forwarded-For": "9.9.9.9"}, "1.1.1.1"},
+		{"private", map[string]string{"X-Edge-Client": "for=10.0.0.1", "X-Forwarded-For": "10.0.0.2, 9.9.9.9"}, "9.9.9.9"},
+		{"unlisted", map[string]string{"X-Real-Ip": "9.9.9.9"}, netaddr.IP{}.String()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = "10.0.0.3:1234"
+			for k, v := range tt.headers {
+				r.Header.Set(k, v)
+			}
+			assert.Equal(t, tt.want, getClientIP(r).String())
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	assert.Nil(t, parseTrustedProxies(""))
+	assert.Equal(t, []netaddr.IPPrefix{
+		netaddr.MustParseIPPrefix("10.0.0.0/8"),
+		netaddr.MustParseIPPrefix("192.0.2.1/32"),
+		netaddr.MustParseIPPrefix("fd00::/8"),
+	}, parseTrustedProxies(" 10.1.2.3/8,, 192.0.2.1,not-an-ip, fd00::/8,10.0.0.0/33"))
+}
+
+func TestIPHeadersTrustedProxies(t *testing.T) {
+	defer func(p []netaddr.IPPrefix) { trustedProxies = p }(trustedProxies)
+	trustedProxies = parseTrustedProxies("10.0.0.0/8,2001:db8::1")
+	for _, tt := range []struct {
+		name   string
+		remote string
+		want   string
+	}{
+		{"trusted", "10.0.0.3:1234", "8.8.8.8"},
+		{"trusted-ipv6", "[2001:db8::1]:1234", "8.8.8.8"},
+		{"untrusted", "1.1.1.1:1234", "1.1.1.1"},
+		{"untrusted-private", "192.168.0.1:1234", netaddr.IP{}.String()},
+		{"invalid", "", netaddr.IP{}.String()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			r.Header.Set("X-Forwarded-For", "8.8.8.8")
+			assert.Equal(t, tt.want, getClientIP(r).String())
+		})
+	}
+}
+
+func TestIPHeadersRightmostTrustedProxies(t *testing.T) {
+	defer func(s string, p []netaddr.IPPrefix) {
+		clientIPStrategy, trustedProxies = s, p
+	}(clientIPStrategy, trustedProxies)
+	clientIPStrategy = clientIPStrategyRightmost
+	// the client goes through an edge proxy with a global IP, then an internal load balancer
+	trustedProxies = parseTrustedProxies("10.0.0.0/8,203.0.113.0/24")
+	for _, tt := range []struct {
+		name  string
+		lines []string
+		want  string
+	}{
+		{"two-hops", []string{"8.8.8.8, 203.0.113.5"}, "8.8.8.8"},
+		{"spoofed", []string{"1.1.1.1, 8.8.8.8, 203.0.113.5"}, "8.8.8.8"},
+		{"lines", []string{"1.1.1.1, 8.8.8.8", "203.0.113.5, 10.0.0.1"}, "8.8.8.8"},
+		{"trusted-only", []string{"203.0.113.5, 10.0.0.1"}, netaddr.IP{}.String()},
+		{"private-client", []string{"1.1.1.1, 192.168.0.1, 203.0.113.5"}, netaddr.IP{}.String()},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = "10.0.0.2:1234"
+			for _, l := range tt.lines {
+				r.Header.Add("X-Forwarded-For", l)
+			}
+			assert.Equal(t, tt.want, getClientIP(r).String())
+		})
+	}
+}
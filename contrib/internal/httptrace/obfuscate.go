@@ -0,0 +1,135 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package httptrace
+
+import (
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// defaultQueryStringRegexp matches the sensitive parts of the query strings, such as
+// passwords, tokens and keys, which are redacted from the http.url tag by default.
+const defaultQueryStringRegexp = `(?i)(?:p(?:ass)?w(?:or)?d|pass(?:_?phrase)?|secret|(?:api_?|private_?|public_?|access_?|secret_?)key(?:_?id)?|token|consumer_?(?:id|key|secret)|sign(?:ed|ature)?|auth(?:entication|orization)?)(?:(?:\s|%20)*(?:=|%3D)[^&]+|(?:"|%22)(?:\s|%20)*(?::|%3A)(?:\s|%20)*(?:"|%22)(?:%2[^2]|%[^2]|[^"%])+(?:"|%22))|bearer(?:\s|%20)+[a-z0-9\._\-]+|token(?::|%3A)[a-z0-9]{13}|gh[opsu]_[0-9a-zA-Z]{36}|ey[I-L](?:[\w=-]|%3D)+\.ey[I-L](?:[\w=-]|%3D)+(?:\.(?:[\w.+\/=-]|%3D|%2F|%2B)+)?|[\-]{5}BEGIN(?:[a-z\s]|%20)+PRIVATE(?:\s|%20)KEY[\-]{5}[^\-]+[\-]{5}END(?:[a-z\s]|%20)+PRIVATE(?:\s|%20)KEY|ssh-rsa(?:\s|%20)*(?:[a-z0-9\/\.+]|%2F|%5C|%2B){100,}`
+
+// redacted replaces the sensitive parts of the query strings.
+const redacted = "<redacted>"
+
+// keyQueryString and keyQueryObfuscation are the keys of the span start configuration tags
+// through which WithQueryString and WithQueryObfuscation configure StartRequestSpan. They
+// are never set on the spans.
+const (
+	keyQueryString      = "_dd.http.query_string"
+	keyQueryObfuscation = "_dd.http.query_obfuscation"
+)
+
+// queryStringRegexp redacts the query strings recorded in the http.url tag of the request
+// spans, see WithQueryString. It is configured with DD_TRACE_OBFUSCATION_QUERY_STRING_REGEXP,
+// and is nil when the obfuscation is disabled by setting the variable to an empty string.
+var queryStringRegexp = newQueryStringRegexp()
+
+// newQueryStringRegexp returns the regexp configured with DD_TRACE_OBFUSCATION_QUERY_STRING_REGEXP,
+// or the default one.
+func newQueryStringRegexp() *regexp.Regexp {
+	v, ok := os.LookupEnv("DD_TRACE_OBFUSCATION_QUERY_STRING_REGEXP")
+	if !ok {
+		return regexp.MustCompile(defaultQueryStringRegexp)
+	}
+	if v == "" {
+		return nil
+	}
+	re, err := regexp.Compile(v)
+	if err != nil {
+		log.Warn("Invalid DD_TRACE_OBFUSCATION_QUERY_STRING_REGEXP, using the default one: %v", err)
+		return regexp.MustCompile(defaultQueryStringRegexp)
+	}
+	return re
+}
+
+// WithQueryString returns a StartRequestSpan option recording the query string of the request,
+// if any, in the http.url tag. Its sensitive parts are redacted with the regexp configured
+// with DD_TRACE_OBFUSCATION_QUERY_STRING_REGEXP, unless overridden by WithQueryObfuscation.
+func WithQueryString() ddtrace.StartSpanOption {
+	return func(cfg *ddtrace.StartSpanConfig) {
+		if cfg.Tags == nil {
+			cfg.Tags = make(map[string]interface{})
+		}
+		cfg.Tags[keyQueryString] = true
+	}
+}
+
+// WithQueryObfuscation returns a StartRequestSpan option replacing the regexp which redacts
+// the query string recorded with WithQueryString with re, for the started span only. The
+// obfuscation is disabled when re is nil. It allows individual routes to apply their own
+// compliance requirements, and takes precedence over Middleware.WithQueryObfuscation.
+func WithQueryObfuscation(re *regexp.Regexp) ddtrace.StartSpanOption {
+	return func(cfg *ddtrace.StartSpanConfig) {
+		if cfg.Tags == nil {
+			cfg.Tags = make(map[string]interface{})
+		}
+		cfg.Tags[keyQueryObfuscation] = re
+	}
+}
+
+// WithQueryObfuscation returns a copy of m replacing the global regexp which redacts the
+// query string recorded with WithQueryString, as configured with
+// DD_TRACE_OBFUSCATION_QUERY_STRING_REGEXP, with re for the requests it traces. The
+// obfuscation is disabled when re is nil. It allows integrations to apply their own
+// compliance requirements.
+func (m *Middleware) WithQueryObfuscation(re *regexp.Regexp) *Middleware {
+	c := new(Middleware)
+	if m != nil {
+		*c = *m
+	}
+	c.queryStringRegexp = re
+	c.hasQueryStringRegexp = true
+	return c
+}
+
+// queryObfuscation returns the regexp redacting the query strings of the requests traced
+// by m, which may be nil.
+func (m *Middleware) queryObfuscation() *regexp.Regexp {
+	if m == nil || !m.hasQueryStringRegexp {
+		return queryStringRegexp
+	}
+	return m.queryStringRegexp
+}
+
+// recordQueryString returns a start option appending the query string of r to the http.url
+// tag when requested with WithQueryString, redacted with the regexp set by WithQueryObfuscation,
+// or else configured for m. It must be the last option applied by StartRequestSpan.
+func recordQueryString(m *Middleware, r *http.Request) ddtrace.StartSpanOption {
+	return func(cfg *ddtrace.StartSpanConfig) {
+		re := m.queryObfuscation()
+		if v, ok := cfg.Tags[keyQueryObfuscation]; ok {
+			delete(cfg.Tags, keyQueryObfuscation)
+			re, _ = v.(*regexp.Regexp)
+		}
+		if _, ok := cfg.Tags[keyQueryString]; !ok {
+			return
+		}
+		delete(cfg.Tags, keyQueryString)
+		if r.URL.RawQuery == "" {
+			return
+		}
+		url, _ := cfg.Tags[ext.HTTPURL].(string)
+		cfg.Tags[ext.HTTPURL] = obfuscateURL(url+"?"+r.URL.RawQuery, re)
+	}
+}
+
+// obfuscateURL returns url with the parts of its query string matching re redacted.
+func obfuscateURL(url string, re *regexp.Regexp) string {
+	i := strings.IndexByte(url, '?')
+	if re == nil || i < 0 {
+		return url
+	}
+	return url[:i+1] + re.ReplaceAllLiteralString(url[i+1:], redacted)
+}
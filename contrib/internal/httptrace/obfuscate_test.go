@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package httptrace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+)
+
+func TestObfuscateURL(t *testing.T) {
+	re := regexp.MustCompile(defaultQueryStringRegexp)
+	for in, want := range map[string]string{
+		"/users":                            "/users",
+		"/users?":                           "/users?",
+		"/users?page=2&sort=asc":            "/users?page=2&sort=asc",
+		"/users?token=abc&page=2":           "/users?<redacted>&page=2",
+		"/login?user=bob&password=s3cr3t":   "/login?user=bob&<redacted>",
+		"/login?api_key=123&secret=456&x=1": "/login?<redacted>&<redacted>&x=1",
+	} {
+		assert.Equal(t, want, obfuscateURL(in, re), in)
+	}
+	assert.Equal(t, "/users?token=abc", obfuscateURL("/users?token=abc", nil))
+}
+
+func TestNewQueryStringRegexp(t *testing.T) {
+	const env = "DD_TRACE_OBFUSCATION_QUERY_STRING_REGEXP"
+	defer func(v string, ok bool) {
+		if ok {
+			os.Setenv(env, v)
+		} else {
+			os.Unsetenv(env)
+		}
+	}(os.LookupEnv(env))
+
+	os.Unsetenv(env)
+	assert.Equal(t, defaultQueryStringRegexp, newQueryStringRegexp().String())
+	os.Setenv(env, "")
+	assert.Nil(t, newQueryStringRegexp())
+	os.Setenv(env, "id=[^&]+")
+	assert.Equal(t, "id=[^&]+", newQueryStringRegexp().String())
+	os.Setenv(env, "[invalid")
+	assert.Equal(t, defaultQueryStringRegexp, newQueryStringRegexp().String())
+}
+
+func TestStartRequestSpanQueryString(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	start := func(mw *Middleware, url string, opts ...ddtrace.StartSpanOption) mocktracer.Span {
+		mt.Reset()
+		r := httptest.NewRequest(http.MethodGet, url, nil)
+		s, _ := mw.StartRequestSpan(r, opts...)
+		s.Finish()
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.NotContains(t, spans[0].Tags(), keyQueryString)
+		assert.NotContains(t, spans[0].Tags(), keyQueryObfuscation)
+		return spans[0]
+	}
+	const url = "/login?user=bob&password=s3cr3t"
+
+	t.Run("not-requested", func(t *testing.T) {
+		assert.Equal(t, "/login", start(nil, url).Tag(ext.HTTPURL))
+	})
+
+	t.Run("no-query", func(t *testing.T) {
+		assert.Equal(t, "/login", start(nil, "/login", WithQueryString()).Tag(ext.HTTPURL))
+	})
+
+	t.Run("global", func(t *testing.T) {
+		assert.Equal(t, "/login?user=bob&<redacted>", start(NewMiddleware(), url, WithQueryString()).Tag(ext.HTTPURL))
+		assert.Equal(t, "/login?user=bob&<redacted>", start(nil, url, WithQueryString()).Tag(ext.HTTPURL))
+	})
+
+	t.Run("middleware", func(t *testing.T) {
+		mw := NewMiddleware()
+		span := start(mw.WithQueryObfuscation(regexp.MustCompile("user=[^&]+")), url, WithQueryString())
+		assert.Equal(t, "/login?<redacted>&password=s3cr3t", span.Tag(ext.HTTPURL))
+		// the middleware it was derived from is left unchanged
+		assert.Equal(t, "/login?user=bob&<redacted>", start(mw, url, WithQueryString()).Tag(ext.HTTPURL))
+		span = start(mw.WithQueryObfuscation(nil), url, WithQueryString())
+		assert.Equal(t, url, span.Tag(ext.HTTPURL))
+	})
+
+	t.Run("per-call", func(t *testing.T) {
+		span := start(nil, url, WithQueryString(), WithQueryObfuscation(regexp.MustCompile("user=[^&]+")))
+		assert.Equal(t, "/login?<redacted>&password=s3cr3t", span.Tag(ext.HTTPURL))
+		span = start(nil, url, WithQueryString(), WithQueryObfuscation(nil))
+		assert.Equal(t, url, span.Tag(ext.HTTPURL))
+		// the option of the call takes precedence over the middleware
+		mw := NewMiddleware().WithQueryObfuscation(nil)
+		span = start(mw, url, WithQueryObfuscation(regexp.MustCompile("user=[^&]+")), WithQueryString())
+		assert.Equal(t, "/login?<redacted>&password=s3cr3t", span.Tag(ext.HTTPURL))
+	})
+}
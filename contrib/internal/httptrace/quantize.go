@@ -0,0 +1,93 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package httptrace
+
+import (
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/internal"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// defaultQuantizationPatterns match the URL path segments replaced by default when the
+// quantization of the paths is enabled.
+var defaultQuantizationPatterns = []string{
+	`^[0-9]+$`, // numeric IDs
+	`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`, // UUIDs
+	`^[0-9a-fA-F]{16,}$`,   // hexadecimal IDs, e.g. object IDs and hashes
+	`^[^@]+@[^@]+\.[^@]+$`, // emails
+}
+
+// pathQuantizer replaces the segments of the URL paths recorded on the request spans
+// which match one of its patterns with "?" (e.g. "/users/123" becomes "/users/?"), so
+// that the IDs, UUIDs and emails embedded in the paths are neither recorded nor blow up
+// the cardinality of the tag.
+type pathQuantizer struct {
+	patterns []*regexp.Regexp
+}
+
+// quantizer quantizes the paths of the requests. It is nil unless the quantization is
+// enabled with DD_TRACE_HTTP_URL_QUANTIZATION_ENABLED.
+var quantizer = newPathQuantizer(
+	internal.BoolEnv("DD_TRACE_HTTP_URL_QUANTIZATION_ENABLED", false),
+	os.Getenv("DD_TRACE_HTTP_URL_QUANTIZATION_PATTERNS"),
+)
+
+// newPathQuantizer returns the quantizer of the paths, or nil when enabled is false. The
+// space-separated regular expressions of patterns match the segments to replace; they
+// default to defaultQuantizationPatterns when patterns is empty. Invalid expressions are
+// skipped.
+func newPathQuantizer(enabled bool, patterns string) *pathQuantizer {
+	if !enabled {
+		return nil
+	}
+	exprs := strings.Fields(patterns)
+	if len(exprs) == 0 {
+		exprs = defaultQuantizationPatterns
+	}
+	q := new(pathQuantizer)
+	for _, expr := range exprs {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			log.Warn("Ignoring invalid pattern %q of DD_TRACE_HTTP_URL_QUANTIZATION_PATTERNS: %v", expr, err)
+			continue
+		}
+		q.patterns = append(q.patterns, re)
+	}
+	return q
+}
+
+// quantize returns path with its segments matching one of the patterns of q replaced
+// with "?". It returns path as is when q is nil.
+func (q *pathQuantizer) quantize(path string) string {
+	if q == nil || len(q.patterns) == 0 {
+		return path
+	}
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		if seg == "" {
+			continue
+		}
+		for _, re := range q.patterns {
+			if re.MatchString(seg) {
+				segments[i] = "?"
+				break
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// QuantizePath returns the URL path p as recorded in the http.url tag of request spans: with
+// the segments identifying resources replaced with "?" when DD_TRACE_HTTP_URL_QUANTIZATION_ENABLED
+// is true, or as is otherwise. It is meant for the server and client integrations which set the
+// tag themselves. The integrations of this repository don't set http.url_details.path: http.url
+// holds the path alone, without the scheme, host or query string, so the tag would duplicate it.
+func QuantizePath(p string) string {
+	return quantizer.quantize(p)
+}
@@ -0,0 +1,69 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package httptrace
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+)
+
+func TestPathQuantizer(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		q := newPathQuantizer(false, "")
+		assert.Nil(t, q)
+		assert.Equal(t, "/users/123", q.quantize("/users/123"))
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		q := newPathQuantizer(true, "")
+		for in, want := range map[string]string{
+			"":                               "",
+			"/":                              "/",
+			"/users/123":                     "/users/?",
+			"/users/123/":                    "/users/?/",
+			"/users/123/orders/4":            "/users/?/orders/?",
+			"/v2/users":                      "/v2/users",
+			"/users/bob@example.com/profile": "/users/?/profile",
+			"/items/3f2504e0-4f89-11d3-9a0c-0305e82c3301": "/items/?",
+			"/blobs/5f1d7a9b2c3e4f60718293a4":             "/blobs/?",
+			"/blobs/cafe":                                 "/blobs/cafe",
+			"relative/42":                                 "relative/?",
+		} {
+			assert.Equal(t, want, q.quantize(in), in)
+		}
+	})
+
+	t.Run("patterns", func(t *testing.T) {
+		q := newPathQuantizer(true, ` ^id-[a-z]+$  [invalid ^[0-9]{3}$ `)
+		require.Len(t, q.patterns, 2)
+		assert.Equal(t, "/users/?/orders/?/1234", q.quantize("/users/id-bob/orders/123/1234"))
+	})
+}
+
+func TestStartRequestSpanQuantizePath(t *testing.T) {
+	defer func(q *pathQuantizer) { quantizer = q }(quantizer)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	r := httptest.NewRequest(http.MethodGet, "/users/123", nil)
+	s, _ := StartRequestSpan(r)
+	s.Finish()
+	quantizer = newPathQuantizer(true, "")
+	s, _ = StartRequestSpan(r)
+	s.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "/users/123", spans[0].Tag(ext.HTTPURL))
+	assert.Equal(t, "/users/?", spans[1].Tag(ext.HTTPURL))
+}
@@ -0,0 +1,306 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package httptrace
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+// DefaultWebSocketFlushInterval is the default interval at which the statistics of
+// WebSocket connections are flushed.
+const DefaultWebSocketFlushInterval = time.Minute
+
+const (
+	tagWebSocketMessagesReceived = "websocket.messages.received"
+	tagWebSocketMessagesSent     = "websocket.messages.sent"
+	tagWebSocketBytesReceived    = "websocket.bytes.received"
+	tagWebSocketBytesSent        = "websocket.bytes.sent"
+)
+
+// IsWebSocketUpgrade reports whether r requests an upgrade to the WebSocket protocol.
+func IsWebSocketUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") &&
+		strings.Contains(strings.ToLower(r.Header.Get("Connection")), "upgrade")
+}
+
+// WrapWebSocket returns a response writer which traces the connection once it is
+// hijacked to serve the WebSocket protocol, when r is an upgrade request and w
+// supports hijacking. Otherwise, w is returned as is.
+//
+// The connection is traced by "websocket.connection" spans, children of parent, which
+// hold the number of messages and bytes received and sent. When flushInterval is
+// positive, the span is finished and replaced by a new one at every interval so that
+// long-lived connections are reported while they are open. Otherwise, a single span
+// covers the whole connection.
+func WrapWebSocket(w http.ResponseWriter, r *http.Request, parent ddtrace.Span, flushInterval time.Duration, opts ...ddtrace.StartSpanOption) http.ResponseWriter {
+	if !IsWebSocketUpgrade(r) {
+		return w
+	}
+	if _, ok := w.(http.Hijacker); !ok {
+		return w
+	}
+	opts = append([]ddtrace.StartSpanOption{
+		tracer.ChildOf(parent.Context()),
+		tracer.SpanType(ext.SpanTypeWeb),
+		tracer.ResourceName(r.URL.Path),
+		tracer.Tag(ext.SpanKind, ext.SpanKindServer),
+	}, opts...)
+	return &webSocketResponseWriter{
+		ResponseWriter: w,
+		flushInterval:  flushInterval,
+		opts:           opts,
+	}
+}
+
+// webSocketResponseWriter wraps the response writer of upgrade requests in order to
+// trace the hijacked connection.
+type webSocketResponseWriter struct {
+	http.ResponseWriter
+	flushInterval time.Duration
+	opts          []ddtrace.StartSpanOption
+	wroteHeader   bool // reports whether the handshake response was written by w
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *webSocketResponseWriter) WriteHeader(status int) {
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter.
+func (w *webSocketResponseWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
+
+// Hijack implements http.Hijacker.
+func (w *webSocketResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := w.ResponseWriter.(http.Hijacker).Hijack()
+	if err != nil {
+		return conn, rw, err
+	}
+	c := newWebSocketConn(conn, w.flushInterval, w.opts)
+	if w.wroteHeader {
+		c.handshake = len(handshakeEnd)
+	}
+	// the buffered reader and writer must go through the traced connection too,
+	// starting with the data which was already read from the client.
+	var r io.Reader = c
+	if n := rw.Reader.Buffered(); n > 0 {
+		buf, _ := rw.Reader.Peek(n)
+		c.countRead(buf)
+		r = io.MultiReader(bytes.NewReader(buf), c)
+	}
+	if err := rw.Writer.Flush(); err != nil {
+		c.Close()
+		return nil, nil, err
+	}
+	rw = bufio.NewReadWriter(bufio.NewReaderSize(r, rw.Reader.Size()), bufio.NewWriterSize(c, rw.Writer.Size()))
+	return c, rw, nil
+}
+
+// Flush implements http.Flusher.
+func (w *webSocketResponseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// ReadFrom implements io.ReaderFrom.
+func (w *webSocketResponseWriter) ReadFrom(r io.Reader) (int64, error) {
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		return rf.ReadFrom(r)
+	}
+	return io.Copy(w.ResponseWriter, r)
+}
+
+// handshakeEnd terminates the handshake response, after which frames are sent.
+const handshakeEnd = "\r\n\r\n"
+
+// webSocketConn is a hijacked connection serving the WebSocket protocol.
+type webSocketConn struct {
+	net.Conn
+	opts []ddtrace.StartSpanOption
+
+	mu        sync.Mutex // guards below fields
+	span      ddtrace.Span
+	handshake int // number of bytes of handshakeEnd sent so far
+	in, out   frameCounter
+	received  int64 // bytes received since span started
+	sent      int64 // bytes sent since span started
+
+	closeOnce sync.Once
+	done      chan struct{}
+}
+
+func newWebSocketConn(conn net.Conn, flushInterval time.Duration, opts []ddtrace.StartSpanOption) *webSocketConn {
+	c := &webSocketConn{
+		Conn: conn,
+		opts: opts,
+		span: tracer.StartSpan("websocket.connection", opts...),
+		done: make(chan struct{}),
+	}
+	if flushInterval > 0 {
+		go c.flushPeriodically(flushInterval)
+	}
+	return c
+}
+
+func (c *webSocketConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.countRead(b[:n])
+	return n, err
+}
+
+func (c *webSocketConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	c.mu.Lock()
+	out := b[:n]
+	// the handshake response is usually written to the hijacked connection too
+	for ; c.handshake < len(handshakeEnd) && len(out) > 0; out = out[1:] {
+		switch out[0] {
+		case handshakeEnd[c.handshake]:
+			c.handshake++
+		case handshakeEnd[0]:
+			c.handshake = 1
+		default:
+			c.handshake = 0
+		}
+	}
+	c.sent += int64(len(out))
+	c.out.consume(out)
+	c.mu.Unlock()
+	return n, err
+}
+
+func (c *webSocketConn) countRead(b []byte) {
+	c.mu.Lock()
+	c.received += int64(len(b))
+	c.in.consume(b)
+	c.mu.Unlock()
+}
+
+// Close closes the connection and finishes its span.
+func (c *webSocketConn) Close() error {
+	err := c.Conn.Close()
+	c.closeOnce.Do(func() {
+		close(c.done)
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		c.finishSpan()
+	})
+	return err
+}
+
+func (c *webSocketConn) flushPeriodically(interval time.Duration) {
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			c.mu.Lock()
+			select {
+			case <-c.done:
+				// closed while waiting for the lock
+			default:
+				c.finishSpan()
+				c.span = tracer.StartSpan("websocket.connection", c.opts...)
+			}
+			c.mu.Unlock()
+		case <-c.done:
+			return
+		}
+	}
+}
+
+// finishSpan finishes the current span with the statistics gathered since it started,
+// and resets them. c.mu must be held.
+func (c *webSocketConn) finishSpan() {
+	c.span.SetTag(tagWebSocketMessagesReceived, c.in.messages)
+	c.span.SetTag(tagWebSocketMessagesSent, c.out.messages)
+	c.span.SetTag(tagWebSocketBytesReceived, c.received)
+	c.span.SetTag(tagWebSocketBytesSent, c.sent)
+	c.span.Finish()
+	c.in.messages, c.out.messages = 0, 0
+	c.received, c.sent = 0, 0
+}
+
+// frameCounter counts the WebSocket messages going one way through a connection by
+// parsing the headers of the frames (RFC 6455, section 5.2).
+type frameCounter struct {
+	header    [14]byte // header of the current frame
+	hlen      int      // number of header bytes read so far
+	remaining uint64   // payload bytes left in the current frame
+	messages  int64    // number of complete data messages
+}
+
+// consume reads the next bytes of the stream.
+func (f *frameCounter) consume(b []byte) {
+	for len(b) > 0 {
+		if f.remaining > 0 {
+			n := f.remaining
+			if uint64(len(b)) < n {
+				n = uint64(len(b))
+			}
+			f.remaining -= n
+			b = b[n:]
+			continue
+		}
+		f.header[f.hlen] = b[0]
+		f.hlen++
+		b = b[1:]
+		h := f.header[:f.hlen]
+		if f.hlen < frameHeaderLen(h) {
+			continue
+		}
+		fin, opcode := h[0]&0x80 != 0, h[0]&0x0f
+		if fin && opcode < 0x8 {
+			// the final frame of a data message, control frames are not counted
+			f.messages++
+		}
+		switch n := h[1] & 0x7f; n {
+		case 126:
+			f.remaining = uint64(binary.BigEndian.Uint16(h[2:4]))
+		case 127:
+			f.remaining = binary.BigEndian.Uint64(h[2:10])
+		default:
+			f.remaining = uint64(n)
+		}
+		f.hlen = 0
+	}
+}
+
+// frameHeaderLen returns the length of the frame header starting with h, which must
+// hold at least its first byte.
+func frameHeaderLen(h []byte) int {
+	if len(h) < 2 {
+		return 2
+	}
+	n := 2
+	switch h[1] & 0x7f {
+	case 126:
+		n += 2
+	case 127:
+		n += 8
+	}
+	if h[1]&0x80 != 0 {
+		// masking key
+		n += 4
+	}
+	return n
+}
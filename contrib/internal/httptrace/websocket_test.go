@@ -0,0 +1,191 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package httptrace
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+)
+
+// frame returns a WebSocket frame with the given opcode and payload, masked when
+// sent by a client.
+func frame(fin bool, opcode byte, payload []byte, masked bool) []byte {
+	b := []byte{opcode, 0}
+	if fin {
+		b[0] |= 0x80
+	}
+	switch n := len(payload); {
+	case n < 126:
+		b[1] = byte(n)
+	case n <= 0xffff:
+		b[1] = 126
+		b = append(b, byte(n>>8), byte(n))
+	default:
+		b[1] = 127
+		for i := 7; i >= 0; i-- {
+			b = append(b, byte(n>>(8*i)))
+		}
+	}
+	if !masked {
+		return append(b, payload...)
+	}
+	b[1] |= 0x80
+	key := []byte{1, 2, 3, 4}
+	b = append(b, key...)
+	for i, c := range payload {
+		b = append(b, c^key[i%4])
+	}
+	return b
+}
+
+func TestFrameCounter(t *testing.T) {
+	var stream []byte
+	stream = append(stream, frame(true, 0x1, []byte("hello"), true)...)
+	stream = append(stream, frame(false, 0x2, make([]byte, 300), false)...)
+	stream = append(stream, frame(true, 0x9, nil, true)...) // ping between fragments
+	stream = append(stream, frame(true, 0x0, make([]byte, 70000), false)...)
+	stream = append(stream, frame(true, 0x8, []byte{3, 232}, true)...)
+
+	for _, size := range []int{1, 3, 1024, len(stream)} {
+		var f frameCounter
+		for b := stream; len(b) > 0; {
+			n := size
+			if n > len(b) {
+				n = len(b)
+			}
+			f.consume(b[:n])
+			b = b[n:]
+		}
+		assert.Equal(t, int64(2), f.messages, "chunk size %d", size)
+		assert.Zero(t, f.hlen)
+		assert.Zero(t, f.remaining)
+	}
+}
+
+func TestWrapWebSocket(t *testing.T) {
+	serve := func(t *testing.T, flushInterval time.Duration, flushed chan struct{}) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			span, _ := StartRequestSpan(r)
+			defer FinishRequestSpan(span, http.StatusSwitchingProtocols, nil)
+			w = WrapWebSocket(w, r, span, flushInterval)
+			conn, rw, err := w.(http.Hijacker).Hijack()
+			require.NoError(t, err)
+			defer conn.Close()
+			rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+			rw.Flush()
+
+			buf := make([]byte, 11) // a masked frame holding "hello"
+			for i := 0; i < 2; i++ {
+				_, err := io.ReadFull(rw, buf)
+				require.NoError(t, err)
+				rw.Write(frame(true, 0x1, []byte("world"), false))
+				rw.Flush()
+				if flushed != nil && i == 0 {
+					<-flushed
+				}
+			}
+		}))
+	}
+	dial := func(t *testing.T, srv *httptest.Server) (net.Conn, *bufio.Reader) {
+		conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+		require.NoError(t, err)
+		req, err := http.NewRequest("GET", srv.URL+"/ws", nil)
+		require.NoError(t, err)
+		req.Header.Set("Upgrade", "websocket")
+		req.Header.Set("Connection", "Upgrade")
+		require.NoError(t, req.Write(conn))
+		br := bufio.NewReader(conn)
+		resp, err := http.ReadResponse(br, req)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+		return conn, br
+	}
+	exchange := func(t *testing.T, conn net.Conn, br *bufio.Reader) {
+		_, err := conn.Write(frame(true, 0x1, []byte("hello"), true))
+		require.NoError(t, err)
+		buf := make([]byte, 7)
+		_, err = io.ReadFull(br, buf)
+		require.NoError(t, err)
+	}
+
+	t.Run("connection", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		srv := serve(t, 0, nil)
+		defer srv.Close()
+
+		conn, br := dial(t, srv)
+		defer conn.Close()
+		exchange(t, conn, br)
+		exchange(t, conn, br)
+
+		require.Eventually(t, func() bool { return len(mt.FinishedSpans()) == 2 }, time.Second, 10*time.Millisecond)
+		spans := mt.FinishedSpans()
+		ws, req := spans[0], spans[1]
+		if ws.OperationName() != "websocket.connection" {
+			ws, req = req, ws
+		}
+		assert.Equal(t, "websocket.connection", ws.OperationName())
+		assert.Equal(t, req.SpanID(), ws.ParentID())
+		assert.Equal(t, "/ws", ws.Tag(ext.ResourceName))
+		assert.Equal(t, ext.SpanKindServer, ws.Tag(ext.SpanKind))
+		assert.Equal(t, int64(2), ws.Tag(tagWebSocketMessagesReceived))
+		assert.Equal(t, int64(2), ws.Tag(tagWebSocketMessagesSent))
+		assert.Equal(t, int64(22), ws.Tag(tagWebSocketBytesReceived))
+		assert.Equal(t, int64(14), ws.Tag(tagWebSocketBytesSent))
+	})
+
+	t.Run("flush", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		flushed := make(chan struct{})
+		srv := serve(t, 10*time.Millisecond, flushed)
+		defer srv.Close()
+
+		conn, br := dial(t, srv)
+		defer conn.Close()
+		exchange(t, conn, br)
+		require.Eventually(t, func() bool { return len(mt.FinishedSpans()) >= 1 }, time.Second, time.Millisecond)
+		first := mt.FinishedSpans()[0]
+		assert.Equal(t, int64(1), first.Tag(tagWebSocketMessagesReceived))
+		assert.Equal(t, int64(1), first.Tag(tagWebSocketMessagesSent))
+		close(flushed)
+		exchange(t, conn, br)
+
+		require.Eventually(t, func() bool {
+			for _, s := range mt.FinishedSpans() {
+				if s.OperationName() == "http.request" {
+					return true
+				}
+			}
+			return false
+		}, time.Second, 10*time.Millisecond)
+		var received int64
+		for _, s := range mt.FinishedSpans() {
+			if s.OperationName() == "websocket.connection" {
+				received += s.Tag(tagWebSocketMessagesReceived).(int64)
+			}
+		}
+		assert.Equal(t, int64(2), received)
+	})
+
+	t.Run("not-upgraded", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/", nil)
+		assert.Equal(t, w, WrapWebSocket(w, r, nil, time.Minute))
+	})
+}
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package jobtrace provides functionalities to trace the jobs run by background job workers
+// that are commonly required and used across the contrib/** job queue integrations.
+package jobtrace
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+const (
+	// TagName holds the name, or kind, of a job.
+	TagName = "job.name"
+	// TagID holds the ID of a job.
+	TagID = "job.id"
+	// TagQueue holds the queue a job was enqueued in.
+	TagQueue = "job.queue"
+	// TagAttempt holds the number of the attempt to run a job, starting at 1.
+	TagAttempt = "job.attempt"
+	// TagLatency holds the time in nanoseconds elapsed between the enqueuing of a job, or the
+	// time it was scheduled at, and the start of its attempt.
+	TagLatency = "job.latency"
+)
+
+// Config holds the settings of the spans started by StartSpan.
+type Config struct {
+	// OperationName is the name of the spans, e.g. "river.job".
+	OperationName string
+	// ServiceName is the service name of the spans.
+	ServiceName string
+	// Component is the name of the integration creating the spans.
+	Component string
+}
+
+// Job describes an attempt to run a job.
+type Job struct {
+	// Name is the name, or kind, of the job. It is the resource of the span.
+	Name string
+	// ID is the ID of the job, if any.
+	ID string
+	// Queue is the queue the job was enqueued in, if any.
+	Queue string
+	// Attempt is the number of the attempt, starting at 1. It is not recorded when zero.
+	Attempt int
+	// EnqueuedAt is the time the job was enqueued, or scheduled to run, at. The latency
+	// of the job is not recorded when it is zero.
+	EnqueuedAt time.Time
+}
+
+// StartSpan starts the span of an attempt to run job. The span is a root span, unless carrier
+// holds a span context, injected when the job was enqueued, in which case it is a child of it.
+func StartSpan(ctx context.Context, cfg Config, job Job, carrier tracer.TextMapReader) (ddtrace.Span, context.Context) {
+	opts := []ddtrace.StartSpanOption{
+		tracer.ServiceName(cfg.ServiceName),
+		tracer.ResourceName(job.Name),
+		tracer.SpanType(ext.SpanTypeMessageConsumer),
+		tracer.Tag(ext.SpanKind, ext.SpanKindConsumer),
+		tracer.Tag(TagName, job.Name),
+		tracer.Measured(),
+	}
+	if job.ID != "" {
+		opts = append(opts, tracer.Tag(TagID, job.ID))
+	}
+	if job.Queue != "" {
+		opts = append(opts, tracer.Tag(TagQueue, job.Queue))
+	}
+	if job.Attempt > 0 {
+		opts = append(opts, tracer.Tag(TagAttempt, job.Attempt))
+	}
+	if !job.EnqueuedAt.IsZero() {
+		if latency := time.Since(job.EnqueuedAt); latency > 0 {
+			opts = append(opts, tracer.Tag(TagLatency, latency.Nanoseconds()))
+		}
+	}
+	if cfg.Component != "" {
+		opts = append(opts, tracer.Tag(ext.Component, cfg.Component))
+	}
+	if carrier != nil {
+		if spanctx, err := tracer.Extract(carrier); err == nil {
+			opts = append(opts, tracer.ChildOf(spanctx))
+		}
+	}
+	return tracer.StartSpanFromContext(ctx, cfg.OperationName, opts...)
+}
+
+// ArgsCarrier injects and extracts span contexts from the arguments, or metadata, of a job,
+// whose values are serialized along with the job.
+type ArgsCarrier map[string]interface{}
+
+var _ interface {
+	tracer.TextMapReader
+	tracer.TextMapWriter
+} = (*ArgsCarrier)(nil)
+
+// ForeachKey implements tracer.TextMapReader. Only the string values are iterated over.
+func (c ArgsCarrier) ForeachKey(handler func(key, val string) error) error {
+	for k, v := range c {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if err := handler(k, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set implements tracer.TextMapWriter.
+func (c ArgsCarrier) Set(key, val string) {
+	c[key] = val
+}
+
+// Inject injects the context of the span found in ctx into args.
+func Inject(ctx context.Context, args map[string]interface{}) error {
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return fmt.Errorf("no span found in context")
+	}
+	return tracer.Inject(span.Context(), ArgsCarrier(args))
+}
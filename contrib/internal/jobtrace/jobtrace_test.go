@@ -0,0 +1,80 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package jobtrace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+var cfg = Config{OperationName: "test.job", ServiceName: "worker", Component: "gocraft/work"}
+
+func TestStartSpan(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	span, _ := StartSpan(context.Background(), cfg, Job{
+		Name:       "send_email",
+		ID:         "42",
+		Queue:      "mailers",
+		Attempt:    2,
+		EnqueuedAt: time.Now().Add(-time.Minute),
+	}, nil)
+	span.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal("test.job", s.OperationName())
+	assert.Equal("send_email", s.Tag(ext.ResourceName))
+	assert.Equal("worker", s.Tag(ext.ServiceName))
+	assert.Equal(ext.SpanTypeMessageConsumer, s.Tag(ext.SpanType))
+	assert.Equal(ext.SpanKindConsumer, s.Tag(ext.SpanKind))
+	assert.Equal("gocraft/work", s.Tag(ext.Component))
+	assert.Equal("send_email", s.Tag(TagName))
+	assert.Equal("42", s.Tag(TagID))
+	assert.Equal("mailers", s.Tag(TagQueue))
+	assert.Equal(2, s.Tag(TagAttempt))
+	assert.GreaterOrEqual(s.Tag(TagLatency).(int64), time.Minute.Nanoseconds())
+	assert.Equal(uint64(0), s.ParentID())
+	assert.Nil(s.Tag(ext.EventSampleRate))
+}
+
+func TestPropagation(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	root, _ := StartSpan(context.Background(), cfg, Job{Name: "job"}, ArgsCarrier{"user": "alice"})
+	root.Finish()
+
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "http.request")
+	args := map[string]interface{}{"user": "alice", "count": 3}
+	require.NoError(t, Inject(ctx, args))
+	parent.Finish()
+	assert.Equal("alice", args["user"])
+	assert.Equal(3, args["count"])
+
+	span, _ := StartSpan(context.Background(), cfg, Job{Name: "job"}, ArgsCarrier(args))
+	span.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 3)
+	assert.Equal(uint64(0), spans[0].ParentID(), "args without a span context start a root span")
+	assert.Equal(parent.Context().TraceID(), spans[2].TraceID())
+	assert.Equal(parent.Context().SpanID(), spans[2].ParentID())
+
+	assert.Error(Inject(context.Background(), args))
+}
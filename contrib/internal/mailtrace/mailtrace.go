@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package mailtrace provides functionalities to trace the sending of emails that are commonly
+// required and used across the contrib/** email integrations. The recipients are only ever
+// recorded by domain, never by address.
+package mailtrace
+
+import (
+	"context"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+const (
+	// OperationName is the name of the spans of delivery attempts.
+	OperationName = "smtp.send"
+
+	// TagRecipientDomains holds the distinct domains of the recipients of an email, comma-separated.
+	TagRecipientDomains = "smtp.recipient_domains"
+	// TagRecipientCount holds the number of recipients of an email.
+	TagRecipientCount = "smtp.recipient_count"
+	// TagMessageSize holds the size in bytes of the sent message.
+	TagMessageSize = "smtp.message_size"
+)
+
+// Config holds the settings of the spans started by StartSpan.
+type Config struct {
+	// ServiceName is the service name of the spans.
+	ServiceName string
+	// Addr is the "host:port" address of the SMTP server, if known.
+	Addr string
+	// Component is the name of the integration creating the spans.
+	Component string
+}
+
+// StartSpan starts the span of the delivery attempt of an email to the recipients to, as a
+// child of the span in ctx. The span is named after resource, e.g. "SendMail".
+func StartSpan(ctx context.Context, cfg *Config, resource string, to []string) ddtrace.Span {
+	opts := []ddtrace.StartSpanOption{
+		tracer.SpanType(ext.SpanTypeSMTP),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.ServiceName(cfg.ServiceName),
+		tracer.ResourceName(resource),
+		tracer.Tag(TagRecipientCount, len(to)),
+	}
+	if domains := RecipientDomains(to); domains != "" {
+		opts = append(opts, tracer.Tag(TagRecipientDomains, domains))
+	}
+	if cfg.Addr != "" {
+		if host, port, err := net.SplitHostPort(cfg.Addr); err == nil {
+			opts = append(opts, tracer.Tag(ext.TargetHost, host), tracer.Tag(ext.TargetPort, port))
+		} else {
+			opts = append(opts, tracer.Tag(ext.TargetHost, cfg.Addr))
+		}
+	}
+	if cfg.Component != "" {
+		opts = append(opts, tracer.Tag(ext.Component, cfg.Component))
+	}
+	span, _ := tracer.StartSpanFromContext(ctx, OperationName, opts...)
+	return span
+}
+
+// FinishSpan finishes span with err, tagging the size of the sent message when it is not negative.
+func FinishSpan(span ddtrace.Span, size int64, err error) {
+	if size >= 0 {
+		span.SetTag(TagMessageSize, size)
+	}
+	span.Finish(tracer.WithError(err))
+}
+
+// Domain returns the lower-cased domain of the email address addr, which may be of the
+// form "Name <user@domain>", or "" if it has none.
+func Domain(addr string) string {
+	i := strings.LastIndex(addr, "@")
+	if i < 0 {
+		return ""
+	}
+	return strings.ToLower(strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(addr[i+1:]), ">")))
+}
+
+// RecipientDomains returns the sorted distinct domains of the email addresses to,
+// comma-separated.
+func RecipientDomains(to []string) string {
+	seen := make(map[string]bool, len(to))
+	domains := make([]string, 0, len(to))
+	for _, addr := range to {
+		d := Domain(addr)
+		if d == "" || seen[d] {
+			continue
+		}
+		seen[d] = true
+		domains = append(domains, d)
+	}
+	sort.Strings(domains)
+	return strings.Join(domains, ",")
+}
@@ -0,0 +1,66 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package mailtrace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+)
+
+func TestDomain(t *testing.T) {
+	for addr, want := range map[string]string{
+		"bob@example.org":         "example.org",
+		"Bob <Bob@Example.ORG>":   "example.org",
+		`"a@b" <bob@example.org>`: "example.org",
+		"bob":                     "",
+	} {
+		assert.Equal(t, want, Domain(addr), addr)
+	}
+}
+
+func TestRecipientDomains(t *testing.T) {
+	assert.Equal(t, "example.com,example.org",
+		RecipientDomains([]string{"bob@example.org", "alice@example.com", "eve@EXAMPLE.org", "nobody"}))
+	assert.Equal(t, "", RecipientDomains(nil))
+}
+
+func TestSpan(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	cfg := &Config{ServiceName: "mail", Addr: "smtp.example.org:587", Component: "net/smtp"}
+	to := []string{"bob@example.org", "alice@example.com"}
+	span := StartSpan(context.Background(), cfg, "SendMail", to)
+	FinishSpan(span, 42, errors.New("boom"))
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal(OperationName, s.OperationName())
+	assert.Equal("SendMail", s.Tag(ext.ResourceName))
+	assert.Equal("mail", s.Tag(ext.ServiceName))
+	assert.Equal(ext.SpanTypeSMTP, s.Tag(ext.SpanType))
+	assert.Equal("net/smtp", s.Tag(ext.Component))
+	assert.Equal("smtp.example.org", s.Tag(ext.TargetHost))
+	assert.Equal("587", s.Tag(ext.TargetPort))
+	assert.Equal("example.com,example.org", s.Tag(TagRecipientDomains))
+	assert.Equal(2, s.Tag(TagRecipientCount))
+	assert.Equal(int64(42), s.Tag(TagMessageSize))
+	assert.NotNil(s.Tag(ext.Error))
+	assert.Nil(s.Tag(ext.EventSampleRate))
+	for k, v := range s.Tags() {
+		assert.NotContains(fmt.Sprint(v), "bob@", k)
+	}
+}
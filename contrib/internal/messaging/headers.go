@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package messaging
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+// Headers abstracts the headers (or attributes) of a message, as exposed by a messaging
+// client library. Implementations are usually thin wrappers around the message type.
+type Headers interface {
+	// Len returns the number of headers.
+	Len() int
+
+	// At returns the key and value of the header at index i.
+	At(i int) (key string, value []byte)
+
+	// Delete removes the header at index i, shifting the following headers.
+	Delete(i int)
+
+	// Add appends a new header.
+	Add(key string, value []byte)
+}
+
+// A Carrier injects and extracts span contexts from message headers.
+type Carrier struct {
+	Headers Headers
+}
+
+var _ interface {
+	tracer.TextMapReader
+	tracer.TextMapWriter
+} = (*Carrier)(nil)
+
+// ForeachKey iterates over every header.
+func (c Carrier) ForeachKey(handler func(key, val string) error) error {
+	for i := 0; i < c.Headers.Len(); i++ {
+		k, v := c.Headers.At(i)
+		if err := handler(k, string(v)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Set sets a header, replacing any existing header having the same key.
+func (c Carrier) Set(key, val string) {
+	// ensure uniqueness of keys
+	for i := 0; i < c.Headers.Len(); i++ {
+		if k, _ := c.Headers.At(i); k == key {
+			c.Headers.Delete(i)
+			i--
+		}
+	}
+	c.Headers.Add(key, []byte(val))
+}
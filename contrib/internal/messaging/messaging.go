@@ -0,0 +1,132 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package messaging provides functionalities to trace message producers and consumers that
+// are commonly required and used across the contrib/** messaging integrations (queues,
+// brokers and pub/sub systems).
+package messaging
+
+import (
+	"context"
+	"math"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// Kind specifies whether a message is being produced or consumed.
+type Kind string
+
+const (
+	// KindProduce is the kind of operations sending a message to a broker.
+	KindProduce Kind = "produce"
+	// KindConsume is the kind of operations receiving a message from a broker.
+	KindConsume Kind = "consume"
+)
+
+// Operation describes the production or consumption of a single message.
+type Operation struct {
+	// System is the name of the messaging system (e.g. "kafka"). It is used as the
+	// prefix of the span operation name, e.g. "kafka.produce".
+	System string
+
+	// Destination is the name of the topic or queue the message is sent to or received from.
+	Destination string
+
+	// Resource is the resource name of the span. It defaults to "Produce Topic <Destination>"
+	// or "Consume Topic <Destination>".
+	Resource string
+
+	// ServiceName is the service name of the span.
+	ServiceName string
+
+	// AnalyticsRate is the analytics rate of the span. It is ignored when set to NaN.
+	AnalyticsRate float64
+
+	// Component is the name of the integration creating the span.
+	Component string
+
+	// Options holds any additional options for the span, such as integration specific tags.
+	Options []ddtrace.StartSpanOption
+}
+
+// StartProduceSpan starts a span for sending the message having the given headers, as a child
+// of the span found in ctx, if any. The context of the started span is then injected into the
+// headers so that consumers can pick it up, replacing any span context they already held.
+func StartProduceSpan(ctx context.Context, op Operation, headers Headers) (ddtrace.Span, context.Context) {
+	return startSpan(ctx, KindProduce, op, headers, ext.SpanTypeMessageProducer, tracer.Tag(ext.SpanKind, ext.SpanKindProducer))
+}
+
+// StartConsumeSpan starts a span for receiving the message having the given headers. If the
+// headers hold a span context, it is used as the parent of the span. The context of the started
+// span is then re-injected into the headers so that the application can pick it up.
+func StartConsumeSpan(ctx context.Context, op Operation, headers Headers) (ddtrace.Span, context.Context) {
+	return startSpan(ctx, KindConsume, op, headers, ext.SpanTypeMessageConsumer, tracer.Tag(ext.SpanKind, ext.SpanKindConsumer), tracer.Measured())
+}
+
+func startSpan(ctx context.Context, kind Kind, op Operation, headers Headers, spanType string, extra ...ddtrace.StartSpanOption) (ddtrace.Span, context.Context) {
+	resource := op.Resource
+	if resource == "" {
+		switch kind {
+		case KindProduce:
+			resource = "Produce Topic " + op.Destination
+		default:
+			resource = "Consume Topic " + op.Destination
+		}
+	}
+	opts := make([]ddtrace.StartSpanOption, 0, 6+len(extra)+len(op.Options))
+	opts = append(opts,
+		tracer.ServiceName(op.ServiceName),
+		tracer.ResourceName(resource),
+		tracer.SpanType(spanType),
+	)
+	opts = append(opts, extra...)
+	if !math.IsNaN(op.AnalyticsRate) {
+		opts = append(opts, tracer.Tag(ext.EventSampleRate, op.AnalyticsRate))
+	}
+	if op.Component != "" {
+		opts = append(opts, tracer.Tag(ext.Component, op.Component))
+	}
+	opts = append(opts, op.Options...)
+	carrier := Carrier{headers}
+	if kind == KindConsume {
+		if spanctx, err := tracer.Extract(carrier); err == nil {
+			opts = append(opts, tracer.ChildOf(spanctx))
+		} else if err != tracer.ErrSpanContextNotFound {
+			// the propagation headers are malformed: keep them for the propagation audit
+			// to report the broken trace.
+			ctx = tracer.ContextWithHeaders(ctx, carrier)
+		}
+	}
+	span, ctx := tracer.StartSpanFromContext(ctx, op.System+"."+string(kind), opts...)
+	if err := tracer.Inject(span.Context(), carrier); err != nil {
+		log.Debug("contrib/internal/messaging: Failed to inject span context into %s message headers: %v", op.System, err)
+	}
+	if c := checkpointer; c != nil {
+		ctx = c.Checkpoint(ctx, kind, op.Destination, headers)
+	}
+	return span, ctx
+}
+
+// Checkpointer is implemented by products that need to observe every message produced or
+// consumed by the traced integrations, such as Data Streams Monitoring which sets pathway
+// checkpoints and propagates them within the message headers.
+type Checkpointer interface {
+	// Checkpoint is called once the span of the operation has been started, and returns the
+	// context to be used for the remainder of the operation.
+	Checkpoint(ctx context.Context, kind Kind, destination string, headers Headers) context.Context
+}
+
+// checkpointer holds the registered Checkpointer, if any.
+var checkpointer Checkpointer
+
+// SetCheckpointer registers c to be notified of all messages going through StartProduceSpan
+// and StartConsumeSpan. It is not safe for concurrent use and must be called before any
+// message is traced. Passing nil unregisters any previous Checkpointer.
+func SetCheckpointer(c Checkpointer) {
+	checkpointer = c
+}
@@ -0,0 +1,187 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package messaging
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+type header struct {
+	key   string
+	value []byte
+}
+
+// testHeaders implements Headers the same way most client libraries store them.
+type testHeaders struct {
+	list []header
+}
+
+func (h *testHeaders) Len() int { return len(h.list) }
+
+func (h *testHeaders) At(i int) (string, []byte) { return h.list[i].key, h.list[i].value }
+
+func (h *testHeaders) Delete(i int) { h.list = append(h.list[:i], h.list[i+1:]...) }
+
+func (h *testHeaders) Add(key string, value []byte) { h.list = append(h.list, header{key, value}) }
+
+func TestCarrier(t *testing.T) {
+	h := &testHeaders{}
+	c := Carrier{h}
+	c.Set("a", "1")
+	c.Set("b", "2")
+	c.Set("a", "3")
+
+	got := map[string]string{}
+	err := c.ForeachKey(func(k, v string) error {
+		got[k] = v
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"a": "3", "b": "2"}, got)
+	assert.Equal(t, 2, h.Len())
+}
+
+func TestProduceConsume(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	h := &testHeaders{}
+	producer, _ := StartProduceSpan(context.Background(), Operation{
+		System:        "kafka",
+		Destination:   "orders",
+		ServiceName:   "producer",
+		AnalyticsRate: 0.5,
+		Component:     "segmentio/kafka.go.v0",
+	}, h)
+	producer.Finish()
+	consumer, _ := StartConsumeSpan(context.Background(), Operation{
+		System:        "kafka",
+		Destination:   "orders",
+		ServiceName:   "consumer",
+		AnalyticsRate: math.NaN(),
+		Options:       []tracer.StartSpanOption{tracer.Tag("partition", 1)},
+	}, h)
+	consumer.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	p, c := spans[0], spans[1]
+	assert.Equal(t, "kafka.produce", p.OperationName())
+	assert.Equal(t, "Produce Topic orders", p.Tag(ext.ResourceName))
+	assert.Equal(t, ext.SpanTypeMessageProducer, p.Tag(ext.SpanType))
+	assert.Equal(t, ext.SpanKindProducer, p.Tag(ext.SpanKind))
+	assert.Equal(t, "producer", p.Tag(ext.ServiceName))
+	assert.Equal(t, 0.5, p.Tag(ext.EventSampleRate))
+	assert.Equal(t, "segmentio/kafka.go.v0", p.Tag(ext.Component))
+
+	assert.Equal(t, "kafka.consume", c.OperationName())
+	assert.Equal(t, "Consume Topic orders", c.Tag(ext.ResourceName))
+	assert.Equal(t, ext.SpanTypeMessageConsumer, c.Tag(ext.SpanType))
+	assert.Equal(t, ext.SpanKindConsumer, c.Tag(ext.SpanKind))
+	assert.Equal(t, "consumer", c.Tag(ext.ServiceName))
+	assert.Equal(t, 1, c.Tag("partition"))
+	assert.Nil(t, c.Tag(ext.EventSampleRate))
+	assert.Nil(t, c.Tag(ext.Component))
+	assert.Equal(t, p.SpanID(), c.ParentID())
+	assert.Equal(t, p.TraceID(), c.TraceID())
+
+	// the consumer span context was re-injected for the application
+	sctx, err := tracer.Extract(Carrier{h})
+	require.NoError(t, err)
+	assert.Equal(t, c.SpanID(), sctx.SpanID())
+}
+
+func TestProduceParent(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	// the message is sent again, holding the headers injected by a previous trace
+	h := &testHeaders{}
+	stale, _ := StartProduceSpan(context.Background(), Operation{System: "kafka", Destination: "orders"}, h)
+	stale.Finish()
+	mt.Reset()
+
+	root, ctx := tracer.StartSpanFromContext(context.Background(), "retry")
+	producer, _ := StartProduceSpan(ctx, Operation{System: "kafka", Destination: "orders"}, h)
+	producer.Finish()
+	root.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	p := spans[0]
+	assert.Equal(t, root.Context().SpanID(), p.ParentID())
+	assert.Equal(t, root.Context().TraceID(), p.TraceID())
+	assert.NotEqual(t, stale.Context().TraceID(), p.TraceID())
+
+	// the headers hold the context of the new producer span only
+	sctx, err := tracer.Extract(Carrier{h})
+	require.NoError(t, err)
+	assert.Equal(t, p.SpanID(), sctx.SpanID())
+}
+
+func TestPropagationAudit(t *testing.T) {
+	var rl log.RecordLogger
+	defer log.UseLogger(&rl)()
+	tracer.Start(tracer.WithPropagationAudit(true))
+	defer tracer.Stop()
+
+	h := &testHeaders{}
+	h.Add(tracer.DefaultTraceIDHeader, []byte("not-a-trace-id"))
+	h.Add(tracer.DefaultParentIDHeader, []byte("456"))
+	span, _ := StartConsumeSpan(context.Background(), Operation{System: "kafka", Destination: "orders"}, h)
+	span.Finish()
+
+	var warnings []string
+	for _, l := range rl.Logs() {
+		if strings.Contains(l, "propagation headers") {
+			warnings = append(warnings, l)
+		}
+	}
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], `Span "kafka.consume"`)
+}
+
+type testCheckpointer struct {
+	kinds []Kind
+}
+
+type checkpointKey struct{}
+
+func (c *testCheckpointer) Checkpoint(ctx context.Context, kind Kind, destination string, headers Headers) context.Context {
+	c.kinds = append(c.kinds, kind)
+	headers.Add("checkpoint", []byte(destination))
+	return context.WithValue(ctx, checkpointKey{}, kind)
+}
+
+func TestCheckpointer(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	cp := &testCheckpointer{}
+	SetCheckpointer(cp)
+	defer SetCheckpointer(nil)
+
+	h := &testHeaders{}
+	op := Operation{System: "sqs", Destination: "queue", Resource: "SendMessage"}
+	span, ctx := StartProduceSpan(context.Background(), op, h)
+	span.Finish()
+	assert.Equal(t, KindProduce, ctx.Value(checkpointKey{}))
+	_, ctx = StartConsumeSpan(context.Background(), op, h)
+	assert.Equal(t, KindConsume, ctx.Value(checkpointKey{}))
+
+	assert.Equal(t, []Kind{KindProduce, KindConsume}, cp.kinds)
+	assert.Equal(t, "SendMessage", mt.FinishedSpans()[0].Tag(ext.ResourceName))
+}
@@ -0,0 +1,105 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package rediscmd provides functionalities to format Redis commands as span tags and resources
+// that are commonly required and used across the contrib/** Redis integrations.
+package rediscmd
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+// Config specifies how Redis commands are formatted into the "redis.raw_command" tag.
+// The zero value keeps commands unchanged.
+type Config struct {
+	// SkipArgs causes only the command name to be recorded, omitting all of its arguments.
+	SkipArgs bool
+
+	// HashKeys causes the key of the command to be replaced by a hash of its value.
+	HashKeys bool
+
+	// MaxKeyLength, when positive, causes keys longer than this value to be truncated.
+	// It has no effect when HashKeys is set.
+	MaxKeyLength int
+}
+
+// Enabled reports whether any of the formatting options are set, meaning that commands
+// will be altered by Format.
+func (c Config) Enabled() bool {
+	return c.SkipArgs || c.HashKeys || c.MaxKeyLength > 0
+}
+
+// Format returns the representation of the command made of the given args according to
+// the configuration. The first argument is the name of the command and the second one is
+// assumed to be the key, which is the case for the vast majority of Redis commands.
+func (c Config) Format(args []interface{}) string {
+	if len(args) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	appendArg(&b, args[0])
+	if c.SkipArgs {
+		return b.String()
+	}
+	for i, arg := range args[1:] {
+		b.WriteByte(' ')
+		if i == 0 && (c.HashKeys || c.MaxKeyLength > 0) {
+			var key strings.Builder
+			appendArg(&key, arg)
+			b.WriteString(c.formatKey(key.String()))
+			continue
+		}
+		appendArg(&b, arg)
+	}
+	return b.String()
+}
+
+// formatKey returns the key k, hashed or truncated according to the configuration.
+func (c Config) formatKey(k string) string {
+	if c.HashKeys {
+		h := fnv.New64a()
+		h.Write([]byte(k))
+		return strconv.FormatUint(h.Sum64(), 16)
+	}
+	if c.MaxKeyLength > 0 && len(k) > c.MaxKeyLength {
+		return k[:c.MaxKeyLength] + "..."
+	}
+	return k
+}
+
+// PipelineResource returns the resource name of a pipeline made of n commands,
+// e.g. "pipeline(12 cmds)".
+func PipelineResource(n int) string {
+	return "pipeline(" + strconv.Itoa(n) + " cmds)"
+}
+
+// appendArg writes the string representation of arg into b.
+func appendArg(b *strings.Builder, arg interface{}) {
+	switch v := arg.(type) {
+	case string:
+		b.WriteString(v)
+	case []byte:
+		b.Write(v)
+	case int:
+		b.WriteString(strconv.Itoa(v))
+	case int32:
+		b.WriteString(strconv.FormatInt(int64(v), 10))
+	case int64:
+		b.WriteString(strconv.FormatInt(v, 10))
+	case uint64:
+		b.WriteString(strconv.FormatUint(v, 10))
+	case float64:
+		b.WriteString(strconv.FormatFloat(v, 'f', -1, 64))
+	case bool:
+		b.WriteString(strconv.FormatBool(v))
+	case fmt.Stringer:
+		b.WriteString(v.String())
+	default:
+		fmt.Fprint(b, v)
+	}
+}
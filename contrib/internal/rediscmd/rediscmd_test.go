@@ -0,0 +1,55 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package rediscmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	args := []interface{}{"set", "user:12345:profile", []byte("value"), 10}
+	for name, tt := range map[string]struct {
+		cfg  Config
+		want string
+	}{
+		"default":    {cfg: Config{}, want: "set user:12345:profile value 10"},
+		"skip-args":  {cfg: Config{SkipArgs: true}, want: "set"},
+		"hash-keys":  {cfg: Config{HashKeys: true}, want: "set de83c141808d5664 value 10"},
+		"truncate":   {cfg: Config{MaxKeyLength: 5}, want: "set user:... value 10"},
+		"short-key":  {cfg: Config{MaxKeyLength: 50}, want: "set user:12345:profile value 10"},
+		"hash-first": {cfg: Config{HashKeys: true, MaxKeyLength: 5}, want: "set de83c141808d5664 value 10"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.cfg.Format(args))
+		})
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Equal(t, "", Config{HashKeys: true}.Format(nil))
+	})
+
+	t.Run("hash-stable", func(t *testing.T) {
+		cfg := Config{HashKeys: true}
+		a := cfg.Format([]interface{}{"get", "key"})
+		b := cfg.Format([]interface{}{"get", "key"})
+		c := cfg.Format([]interface{}{"get", "other"})
+		assert.Equal(t, a, b)
+		assert.NotEqual(t, a, c)
+	})
+}
+
+func TestEnabled(t *testing.T) {
+	assert.False(t, Config{}.Enabled())
+	assert.True(t, Config{SkipArgs: true}.Enabled())
+	assert.True(t, Config{HashKeys: true}.Enabled())
+	assert.True(t, Config{MaxKeyLength: 1}.Enabled())
+}
+
+func TestPipelineResource(t *testing.T) {
+	assert.Equal(t, "pipeline(12 cmds)", PipelineResource(12))
+}
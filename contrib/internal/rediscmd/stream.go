@@ -0,0 +1,172 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package rediscmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/messaging"
+)
+
+const (
+	// TagStream holds the streams targeted by a stream command, comma-separated.
+	TagStream = "redis.stream"
+	// TagConsumerGroup holds the consumer group of a stream command.
+	TagConsumerGroup = "redis.consumer_group"
+	// TagConsumer holds the consumer of a stream command.
+	TagConsumer = "redis.consumer"
+	// TagEntryID holds the ID of a stream entry.
+	TagEntryID = "redis.stream.entry_id"
+	// TagEntries holds the number of entries read by XREAD or XREADGROUP.
+	TagEntries = "redis.stream.entries"
+	// TagPending holds the number of pending entries of a consumer group, as returned by XPENDING.
+	TagPending = "redis.stream.pending"
+	// TagAcked holds the number of entries acknowledged by XACK.
+	TagAcked = "redis.stream.acked"
+	// TagChannel holds the Pub/Sub channel of a command or of a received message.
+	TagChannel = "redis.channel"
+	// TagPattern holds the pattern a received Pub/Sub message matched.
+	TagPattern = "redis.pattern"
+)
+
+// StreamCommand describes the stream or Pub/Sub targeted by a command.
+type StreamCommand struct {
+	// Streams holds the names of the targeted streams.
+	Streams []string
+	// Group is the consumer group of the command, if any.
+	Group string
+	// Consumer is the consumer of the command, if any.
+	Consumer string
+	// Channel is the Pub/Sub channel of the command, if any.
+	Channel string
+}
+
+// ParseStreamCommand parses the stream or Pub/Sub command made of args, e.g. XADD, XREADGROUP,
+// XACK or PUBLISH. It returns false if args is not such a command.
+func ParseStreamCommand(args []interface{}) (StreamCommand, bool) {
+	var sc StreamCommand
+	if len(args) < 2 {
+		return sc, false
+	}
+	arg := func(i int) string {
+		if i >= len(args) {
+			return ""
+		}
+		var b strings.Builder
+		appendArg(&b, args[i])
+		return b.String()
+	}
+	switch name := strings.ToLower(arg(0)); name {
+	case "publish", "spublish":
+		sc.Channel = arg(1)
+	case "xread", "xreadgroup":
+		i := 1
+		if name == "xreadgroup" && strings.EqualFold(arg(1), "group") {
+			sc.Group, sc.Consumer = arg(2), arg(3)
+			i = 4
+		}
+		for ; i < len(args); i++ {
+			if strings.EqualFold(arg(i), "streams") {
+				// STREAMS key [key ...] id [id ...]
+				keys := args[i+1:]
+				for j := 0; j < len(keys)/2; j++ {
+					sc.Streams = append(sc.Streams, arg(i+1+j))
+				}
+				break
+			}
+		}
+	case "xgroup", "xinfo":
+		// XGROUP CREATE key group ..., XINFO STREAM key
+		sc.Streams = []string{arg(2)}
+		if name == "xgroup" || strings.EqualFold(arg(1), "consumers") {
+			sc.Group = arg(3)
+		}
+		if strings.EqualFold(arg(1), "delconsumer") || strings.EqualFold(arg(1), "createconsumer") {
+			sc.Consumer = arg(4)
+		}
+	case "xack", "xpending":
+		sc.Streams, sc.Group = []string{arg(1)}, arg(2)
+	case "xclaim", "xautoclaim":
+		sc.Streams, sc.Group, sc.Consumer = []string{arg(1)}, arg(2), arg(3)
+	case "xadd", "xdel", "xlen", "xrange", "xrevrange", "xtrim", "xsetid":
+		sc.Streams = []string{arg(1)}
+	default:
+		return sc, false
+	}
+	return sc, true
+}
+
+// Tags returns the span tags describing sc.
+func (sc StreamCommand) Tags() map[string]string {
+	tags := make(map[string]string, 4)
+	if len(sc.Streams) > 0 {
+		tags[TagStream] = strings.Join(sc.Streams, ",")
+	}
+	if sc.Group != "" {
+		tags[TagConsumerGroup] = sc.Group
+	}
+	if sc.Consumer != "" {
+		tags[TagConsumer] = sc.Consumer
+	}
+	if sc.Channel != "" {
+		tags[TagChannel] = sc.Channel
+	}
+	return tags
+}
+
+// StreamEntry adapts the field-value pairs of a stream entry to messaging.Headers, so that
+// span contexts can be propagated within the entries of Redis streams.
+type StreamEntry struct {
+	Values map[string]interface{}
+	keys   []string // sorted keys of Values, built on first use
+}
+
+var _ messaging.Headers = (*StreamEntry)(nil)
+
+func (e *StreamEntry) sortedKeys() []string {
+	if e.keys == nil {
+		e.keys = make([]string, 0, len(e.Values))
+		for k := range e.Values {
+			e.keys = append(e.keys, k)
+		}
+		sort.Strings(e.keys)
+	}
+	return e.keys
+}
+
+// Len implements messaging.Headers.
+func (e *StreamEntry) Len() int { return len(e.Values) }
+
+// At implements messaging.Headers.
+func (e *StreamEntry) At(i int) (string, []byte) {
+	k := e.sortedKeys()[i]
+	switch v := e.Values[k].(type) {
+	case string:
+		return k, []byte(v)
+	case []byte:
+		return k, v
+	default:
+		return k, []byte(fmt.Sprint(v))
+	}
+}
+
+// Delete implements messaging.Headers.
+func (e *StreamEntry) Delete(i int) {
+	keys := e.sortedKeys()
+	delete(e.Values, keys[i])
+	e.keys = append(keys[:i], keys[i+1:]...)
+}
+
+// Add implements messaging.Headers.
+func (e *StreamEntry) Add(key string, value []byte) {
+	if e.Values == nil {
+		e.Values = make(map[string]interface{})
+	}
+	e.Values[key] = string(value)
+	e.keys = nil
+}
@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package rediscmd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/messaging"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func TestParseStreamCommand(t *testing.T) {
+	for name, tt := range map[string]struct {
+		args []interface{}
+		want map[string]string
+	}{
+		"xadd": {
+			[]interface{}{"xadd", "orders", "maxlen", "~", 1000, "*", "id", 42},
+			map[string]string{TagStream: "orders"},
+		},
+		"xreadgroup": {
+			[]interface{}{"xreadgroup", "group", "billing", "worker-1", "count", 10, "block", 0, "streams", "orders", "refunds", ">", ">"},
+			map[string]string{TagStream: "orders,refunds", TagConsumerGroup: "billing", TagConsumer: "worker-1"},
+		},
+		"xread": {
+			[]interface{}{"xread", "count", 1, "streams", "orders", "0"},
+			map[string]string{TagStream: "orders"},
+		},
+		"xack": {
+			[]interface{}{"xack", "orders", "billing", "1-0", "2-0"},
+			map[string]string{TagStream: "orders", TagConsumerGroup: "billing"},
+		},
+		"xpending": {
+			[]interface{}{"xpending", "orders", "billing"},
+			map[string]string{TagStream: "orders", TagConsumerGroup: "billing"},
+		},
+		"xclaim": {
+			[]interface{}{"xclaim", "orders", "billing", "worker-2", 60000, "1-0"},
+			map[string]string{TagStream: "orders", TagConsumerGroup: "billing", TagConsumer: "worker-2"},
+		},
+		"xgroup": {
+			[]interface{}{"xgroup", "create", "orders", "billing", "$", "mkstream"},
+			map[string]string{TagStream: "orders", TagConsumerGroup: "billing"},
+		},
+		"xinfo": {
+			[]interface{}{"xinfo", "stream", "orders"},
+			map[string]string{TagStream: "orders"},
+		},
+		"publish": {
+			[]interface{}{"publish", "news", "hello"},
+			map[string]string{TagChannel: "news"},
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			sc, ok := ParseStreamCommand(tt.args)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, sc.Tags())
+		})
+	}
+
+	for _, args := range [][]interface{}{{"get", "key"}, {"xadd"}, {}} {
+		_, ok := ParseStreamCommand(args)
+		assert.False(t, ok, args)
+	}
+}
+
+func TestStreamEntry(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	span := tracer.StartSpan("produce")
+	entry := &StreamEntry{Values: map[string]interface{}{"order": "42", "amount": 10}}
+	carrier := messaging.Carrier{Headers: entry}
+	require.NoError(t, tracer.Inject(span.Context(), carrier))
+	assert.Equal(t, "42", entry.Values["order"])
+	assert.Equal(t, 10, entry.Values["amount"])
+
+	// the values of entries read from Redis are strings
+	read := make(map[string]interface{})
+	for k, v := range entry.Values {
+		read[k] = v
+	}
+	sctx, err := tracer.Extract(messaging.Carrier{Headers: &StreamEntry{Values: read}})
+	require.NoError(t, err)
+	assert.Equal(t, span.Context().TraceID(), sctx.TraceID())
+	assert.Equal(t, span.Context().SpanID(), sctx.SpanID())
+
+	// injecting again replaces the fields
+	n := len(entry.Values)
+	require.NoError(t, tracer.Inject(tracer.StartSpan("retry").Context(), carrier))
+	assert.Len(t, entry.Values, n)
+}
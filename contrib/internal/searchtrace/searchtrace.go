@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package searchtrace provides functionalities to trace HTTP requests to search engines
+// that are commonly required and used across the contrib/** search integrations.
+package searchtrace
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+
+	"github.com/DataDog/datadog-agent/pkg/obfuscate"
+)
+
+// BodyCutoff specifies the maximum number of bytes that will be stored as a tag
+// value obtained from an HTTP request or response body.
+const BodyCutoff = 5 * 1024
+
+// ObfuscateJSON returns the JSON query body with all its values replaced by "?". Bodies
+// which are truncated or otherwise invalid are obfuscated up to the first error.
+func ObfuscateJSON(body string) string {
+	// obfuscators keep their scanning state across calls and are not safe for
+	// concurrent use; one without a query cache is cheap to create.
+	o := obfuscate.NewObfuscator(obfuscate.Config{
+		ES: obfuscate.JSONConfig{Enabled: true},
+	})
+	return o.ObfuscateElasticSearchString(body)
+}
+
+// Peek attempts to return the first n bytes, as a string, from the provided io.ReadCloser.
+// It returns a new io.ReadCloser which points to the same underlying stream and can be read
+// from to access the entire data including the snippet. max is used to specify the length
+// of the stream contained in the reader. If unknown, it should be -1. If 0 < max < n it
+// will override n. Snippets of gzip encoded streams are decompressed.
+func Peek(rc io.ReadCloser, encoding string, max, n int) (string, io.ReadCloser, error) {
+	if rc == nil {
+		return "", rc, errors.New("empty stream")
+	}
+	if max > 0 && max < n {
+		n = max
+	}
+	r := bufio.NewReaderSize(rc, n)
+	rc2 := struct {
+		io.Reader
+		io.Closer
+	}{
+		Reader: r,
+		Closer: rc,
+	}
+	snip, err := r.Peek(n)
+	if err == io.EOF {
+		err = nil
+	}
+	if err != nil {
+		return string(snip), rc2, err
+	}
+	if encoding == "gzip" {
+		// unpack the snippet
+		gzr, err2 := gzip.NewReader(bytes.NewReader(snip))
+		if err2 != nil {
+			// snip wasn't gzip; return it as is
+			return string(snip), rc2, nil
+		}
+		defer gzr.Close()
+		snip, err = ioutil.ReadAll(gzr)
+	}
+	return string(snip), rc2, err
+}
@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package searchtrace
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObfuscateJSON(t *testing.T) {
+	for in, out := range map[string]string{
+		``:                                     ``,
+		`{"query":{"match":{"user":"alice"}}}`: `{"query":{"match":{"user":"?"}}}`,
+		`{"size":10,"query":{"term":{"age":42}}}`: `{"size":"?","query":{"term":{"age":"?"}}}`,
+		`{"query":{"match":{"user":"ali`:          `{"query":{"match":{"user":"?"...`,
+	} {
+		assert.Equal(t, out, ObfuscateJSON(in), in)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	t.Run("plain", func(t *testing.T) {
+		body := ioutil.NopCloser(strings.NewReader("0123456789"))
+		snip, rc, err := Peek(body, "", -1, 4)
+		require.NoError(t, err)
+		assert.Equal(t, "0123", snip)
+		all, err := ioutil.ReadAll(rc)
+		require.NoError(t, err)
+		assert.Equal(t, "0123456789", string(all))
+	})
+
+	t.Run("short", func(t *testing.T) {
+		snip, _, err := Peek(ioutil.NopCloser(strings.NewReader("01")), "", 2, 4)
+		require.NoError(t, err)
+		assert.Equal(t, "01", snip)
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		var buf bytes.Buffer
+		w := gzip.NewWriter(&buf)
+		w.Write([]byte(`{"query":{}}`))
+		w.Close()
+		snip, _, err := Peek(ioutil.NopCloser(&buf), "gzip", buf.Len(), BodyCutoff)
+		require.NoError(t, err)
+		assert.Equal(t, `{"query":{}}`, snip)
+	})
+
+	t.Run("nil", func(t *testing.T) {
+		_, _, err := Peek(nil, "", -1, 4)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package spanpointer
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+)
+
+// AddAWS adds to span the pointer to the S3 object or DynamoDB item written or read by
+// the operation of service, e.g. "s3" and "PutObject", which succeeded with the given
+// input and output. The input and output are the structs of either aws-sdk-go or
+// aws-sdk-go-v2, such as s3.PutObjectInput and s3.PutObjectOutput, from which the
+// identifiers of the object are read. Operations which don't write or read a single
+// object, or whose object can't be identified, are ignored. The items written with
+// PutItem are left out, as their primary key can't be told apart from their attributes.
+func AddAWS(span ddtrace.Span, service, operation string, input, output interface{}) {
+	switch strings.ToLower(service) {
+	case "s3":
+		dir := Downstream
+		switch operation {
+		case "PutObject", "CopyObject", "CompleteMultipartUpload":
+		case "GetObject":
+			dir = Upstream
+		default:
+			return
+		}
+		bucket, key := stringField(input, "Bucket"), stringField(input, "Key")
+		etag := stringField(output, "ETag")
+		if etag == "" {
+			// CopyObject
+			etag = stringField(field(output, "CopyObjectResult"), "ETag")
+		}
+		if bucket == "" || key == "" || etag == "" {
+			return
+		}
+		Add(span, KindS3Object, dir, S3ObjectHash(bucket, key, etag))
+	case "dynamodb":
+		dir := Downstream
+		switch operation {
+		case "UpdateItem", "DeleteItem":
+		case "GetItem":
+			dir = Upstream
+		default:
+			return
+		}
+		table := stringField(input, "TableName")
+		key, ok := dynamoDBKey(field(input, "Key"))
+		if table == "" || !ok {
+			return
+		}
+		Add(span, KindDynamoDBItem, dir, DynamoDBItemHash(table, key))
+	}
+}
+
+// field returns the field name of the struct v, which may be a pointer, or nil if it
+// has none.
+func field(v interface{}, name string) interface{} {
+	rv := indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+	f := rv.FieldByName(name)
+	if !f.IsValid() || !f.CanInterface() {
+		return nil
+	}
+	return f.Interface()
+}
+
+// stringField returns the string or *string field name of the struct v, or "".
+func stringField(v interface{}, name string) string {
+	rv := indirect(reflect.ValueOf(field(v, name)))
+	if rv.Kind() != reflect.String {
+		return ""
+	}
+	return rv.String()
+}
+
+// indirect dereferences the pointers and interfaces of v.
+func indirect(v reflect.Value) reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+	return v
+}
+
+// dynamoDBKey returns the values of the attributes of the DynamoDB primary key v, a
+// map[string]*dynamodb.AttributeValue of aws-sdk-go or a map[string]types.AttributeValue
+// of aws-sdk-go-v2. It reports false if any of them isn't a string, a number or a binary.
+func dynamoDBKey(v interface{}) (map[string][]byte, bool) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Map || rv.Type().Key().Kind() != reflect.String || rv.Len() == 0 {
+		return nil, false
+	}
+	key := make(map[string][]byte, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		val, ok := attributeValue(iter.Value())
+		if !ok {
+			return nil, false
+		}
+		key[iter.Key().String()] = val
+	}
+	return key, true
+}
+
+// attributeValue returns the value of the DynamoDB attribute v: either a
+// dynamodb.AttributeValue with its S, N or B field set, or one of the
+// types.AttributeValueMemberS, AttributeValueMemberN or AttributeValueMemberB.
+func attributeValue(v reflect.Value) ([]byte, bool) {
+	v = indirect(v)
+	if v.Kind() != reflect.Struct {
+		return nil, false
+	}
+	if strings.HasPrefix(v.Type().Name(), "AttributeValueMember") {
+		switch strings.TrimPrefix(v.Type().Name(), "AttributeValueMember") {
+		case "S", "N", "B":
+			return valueBytes(v.FieldByName("Value"))
+		}
+		return nil, false
+	}
+	for _, name := range []string{"S", "N", "B"} {
+		if b, ok := valueBytes(v.FieldByName(name)); ok {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// valueBytes returns the bytes of v, a string, a *string or a []byte.
+func valueBytes(v reflect.Value) ([]byte, bool) {
+	if !v.IsValid() {
+		return nil, false
+	}
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.Uint8 {
+		if v.IsNil() {
+			return nil, false
+		}
+		return v.Bytes(), true
+	}
+	v = indirect(v)
+	if v.Kind() != reflect.String {
+		return nil, false
+	}
+	return []byte(v.String()), true
+}
@@ -0,0 +1,165 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package spanpointer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+// The types below mimic the shapes of the inputs and outputs of the AWS SDKs.
+
+type putObjectInput struct {
+	Bucket *string
+	Key    *string
+}
+
+type putObjectOutput struct {
+	ETag *string
+}
+
+type copyObjectOutput struct {
+	CopyObjectResult *putObjectOutput
+}
+
+// attributeValueV1 mimics dynamodb.AttributeValue of aws-sdk-go.
+type attributeValueV1 struct {
+	B []byte
+	N *string
+	S *string
+}
+
+type updateItemInputV1 struct {
+	TableName *string
+	Key       map[string]*attributeValueV1
+}
+
+// AttributeValue, AttributeValueMemberS and AttributeValueMemberN mimic the
+// types.AttributeValue of aws-sdk-go-v2.
+type AttributeValue interface{ isAttributeValue() }
+
+type AttributeValueMemberS struct{ Value string }
+
+func (*AttributeValueMemberS) isAttributeValue() {}
+
+type AttributeValueMemberN struct{ Value string }
+
+func (*AttributeValueMemberN) isAttributeValue() {}
+
+type AttributeValueMemberBOOL struct{ Value bool }
+
+func (*AttributeValueMemberBOOL) isAttributeValue() {}
+
+type updateItemInputV2 struct {
+	TableName *string
+	Key       map[string]AttributeValue
+}
+
+func str(s string) *string { return &s }
+
+func TestAddAWS(t *testing.T) {
+	for _, tt := range []struct {
+		name            string
+		service, op     string
+		input, output   interface{}
+		kind, dir, hash string
+	}{
+		{
+			name:    "s3/put",
+			service: "s3", op: "PutObject",
+			input:  &putObjectInput{Bucket: str("some-bucket"), Key: str("some-key.data")},
+			output: &putObjectOutput{ETag: str(`"ab12ef34"`)},
+			kind:   KindS3Object, dir: "d", hash: "e721375466d4116ab551213fdea08413",
+		},
+		{
+			name:    "s3/copy",
+			service: "S3", op: "CopyObject",
+			input:  &putObjectInput{Bucket: str("some-bucket"), Key: str("some-key.data")},
+			output: &copyObjectOutput{CopyObjectResult: &putObjectOutput{ETag: str(`"ab12ef34"`)}},
+			kind:   KindS3Object, dir: "d", hash: "e721375466d4116ab551213fdea08413",
+		},
+		{
+			name:    "s3/get",
+			service: "s3", op: "GetObject",
+			input:  &putObjectInput{Bucket: str("some-bucket"), Key: str("some-key.data")},
+			output: putObjectOutput{ETag: str("ab12ef34")},
+			kind:   KindS3Object, dir: "u", hash: "e721375466d4116ab551213fdea08413",
+		},
+		{
+			name:    "dynamodb/v1",
+			service: "dynamodb", op: "UpdateItem",
+			input: &updateItemInputV1{
+				TableName: str("some-table"),
+				Key: map[string]*attributeValueV1{
+					"some-key":  {S: str("some-value")},
+					"other-key": {N: str("123")},
+				},
+			},
+			kind: KindDynamoDBItem, dir: "d", hash: "7aa1b80b0e49bd2078a5453399f4dd67",
+		},
+		{
+			name:    "dynamodb/v2",
+			service: "DynamoDB", op: "DeleteItem",
+			input: &updateItemInputV2{
+				TableName: str("some-table"),
+				Key: map[string]AttributeValue{
+					"some-key":  &AttributeValueMemberS{Value: "some-value"},
+					"other-key": &AttributeValueMemberN{Value: "123"},
+				},
+			},
+			kind: KindDynamoDBItem, dir: "d", hash: "7aa1b80b0e49bd2078a5453399f4dd67",
+		},
+		{
+			name:    "s3/no-etag",
+			service: "s3", op: "PutObject",
+			input:  &putObjectInput{Bucket: str("some-bucket"), Key: str("some-key.data")},
+			output: &putObjectOutput{},
+		},
+		{
+			name:    "s3/other",
+			service: "s3", op: "ListObjects",
+			input: &putObjectInput{Bucket: str("some-bucket")},
+		},
+		{
+			name:    "dynamodb/unsupported-key",
+			service: "dynamodb", op: "UpdateItem",
+			input: &updateItemInputV2{
+				TableName: str("some-table"),
+				Key:       map[string]AttributeValue{"some-key": &AttributeValueMemberBOOL{Value: true}},
+			},
+		},
+		{
+			name:    "nil",
+			service: "dynamodb", op: "GetItem",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+
+			span := tracer.StartSpan("aws.request")
+			AddAWS(span, tt.service, tt.op, tt.input, tt.output)
+			span.Finish()
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			links := spans[0].Links()
+			if tt.hash == "" {
+				assert.Empty(t, links)
+				return
+			}
+			require.Len(t, links, 1)
+			assert.Equal(t, tt.kind, links[0].Attributes["ptr.kind"])
+			assert.Equal(t, tt.dir, links[0].Attributes["ptr.dir"])
+			assert.Equal(t, tt.hash, links[0].Attributes["ptr.hash"])
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package spanpointer provides functions to link spans to the external objects they
+// write or read, such as S3 objects or DynamoDB items. A span pointer is a span link
+// without trace and span IDs, identifying an object by a hash of its identifiers: the
+// spans of the services writing and reading the same object hold pointers with the same
+// hash, which lets the lifecycle of the object be followed across traces without any
+// context being propagated.
+package spanpointer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+const (
+	// KindS3Object is the kind of the pointers to S3 objects.
+	KindS3Object = "aws.s3.object"
+	// KindDynamoDBItem is the kind of the pointers to DynamoDB items.
+	KindDynamoDBItem = "aws.dynamodb.item"
+)
+
+// Direction specifies whether the object pointed to was written or read by a span.
+type Direction string
+
+const (
+	// Downstream points to an object written by the span, which spans reading it
+	// later on follow.
+	Downstream Direction = "d"
+	// Upstream points to an object read by the span, written by spans before it.
+	Upstream Direction = "u"
+)
+
+const (
+	attrKind      = "ptr.kind"
+	attrDirection = "ptr.dir"
+	attrHash      = "ptr.hash"
+	attrLinkKind  = "link.kind"
+	linkKind      = "span-pointer"
+
+	// hashLength is the length of the hashes, in hexadecimal digits.
+	hashLength = 32
+)
+
+// Hash returns the hash identifying an object from its components: the first 128 bits of
+// the SHA-256 of the components separated by "|", in hexadecimal.
+func Hash(components ...[]byte) string {
+	h := sha256.New()
+	for i, c := range components {
+		if i > 0 {
+			h.Write([]byte("|"))
+		}
+		h.Write(c)
+	}
+	return hex.EncodeToString(h.Sum(nil))[:hashLength]
+}
+
+// S3ObjectHash returns the hash identifying the version of the S3 object key of bucket
+// with the given ETag. The quotes surrounding the ETag in the responses of S3 are ignored.
+func S3ObjectHash(bucket, key, etag string) string {
+	etag = strings.Trim(etag, `"`)
+	return Hash([]byte(bucket), []byte(key), []byte(etag))
+}
+
+// DynamoDBItemHash returns the hash identifying the item of table with the given primary
+// key, which maps the names of its attributes, the partition key and the optional sort
+// key, to their value: the string, the number in its string form or the binary value.
+func DynamoDBItemHash(table string, key map[string][]byte) string {
+	names := make([]string, 0, len(key))
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	components := [][]byte{[]byte(table), nil, nil, nil, nil}
+	for i, name := range names {
+		if i >= 2 {
+			break
+		}
+		components[1+2*i] = []byte(name)
+		components[2+2*i] = key[name]
+	}
+	return Hash(components...)
+}
+
+// Link returns the span link pointing to the object of the given kind identified by hash.
+func Link(kind string, dir Direction, hash string) ddtrace.SpanLink {
+	return ddtrace.SpanLink{
+		Attributes: map[string]string{
+			attrKind:      kind,
+			attrDirection: string(dir),
+			attrHash:      hash,
+			attrLinkKind:  linkKind,
+		},
+	}
+}
+
+// Add adds to span the pointer to the object of the given kind identified by hash.
+func Add(span ddtrace.Span, kind string, dir Direction, hash string) {
+	tracer.AddSpanLinks(span, Link(kind, dir, hash))
+}
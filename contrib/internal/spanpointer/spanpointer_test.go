@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package spanpointer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func TestS3ObjectHash(t *testing.T) {
+	assert.Equal(t, "e721375466d4116ab551213fdea08413", S3ObjectHash("some-bucket", "some-key.data", "ab12ef34"))
+	assert.Equal(t, "e721375466d4116ab551213fdea08413", S3ObjectHash("some-bucket", "some-key.data", `"ab12ef34"`))
+}
+
+func TestDynamoDBItemHash(t *testing.T) {
+	t.Run("partition-key", func(t *testing.T) {
+		h := DynamoDBItemHash("some-table", map[string][]byte{"some-key": []byte("some-value")})
+		assert.Equal(t, "7f1aee721472bcb48701d45c7c7f7821", h)
+	})
+
+	t.Run("sort-key", func(t *testing.T) {
+		h := DynamoDBItemHash("some-table", map[string][]byte{
+			"some-key":  []byte("some-value"),
+			"other-key": []byte("123"),
+		})
+		assert.Equal(t, "7aa1b80b0e49bd2078a5453399f4dd67", h)
+	})
+}
+
+func TestAdd(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	span := tracer.StartSpan("s3.request")
+	Add(span, KindS3Object, Downstream, "e721375466d4116ab551213fdea08413")
+	span.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	links := spans[0].Links()
+	require.Len(t, links, 1)
+	assert.Zero(t, links[0].TraceID)
+	assert.Zero(t, links[0].SpanID)
+	assert.Equal(t, map[string]string{
+		"ptr.kind":  "aws.s3.object",
+		"ptr.dir":   "d",
+		"ptr.hash":  "e721375466d4116ab551213fdea08413",
+		"link.kind": "span-pointer",
+	}, links[0].Attributes)
+}
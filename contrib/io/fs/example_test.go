@@ -0,0 +1,51 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package fs_test
+
+import (
+	"context"
+	"io"
+	"os"
+	"time"
+
+	fstrace "github.com/codebrick-corp/dd-trace-go/contrib/io/fs"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func Example() {
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "pipeline.run")
+	defer span.Finish()
+
+	// Reads of more than 4MiB or slower than 50ms are traced.
+	fsys := fstrace.WrapFS(os.DirFS("/data"),
+		fstrace.WithContext(ctx),
+		fstrace.WithSizeThreshold(4<<20),
+		fstrace.WithDurationThreshold(50*time.Millisecond),
+	)
+	in, err := fsys.Open("input/2022/01.csv")
+	if err != nil {
+		return
+	}
+	defer in.Close()
+
+	out, err := fstrace.Create("/data/output/2022/01.csv", fstrace.WithContext(ctx))
+	if err != nil {
+		return
+	}
+	defer out.Close()
+	io.Copy(out, in)
+}
+
+func ExampleFile_WithContext() {
+	f, err := fstrace.Open("/data/input/2022/01.csv")
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "pipeline.load")
+	defer span.Finish()
+	io.ReadAll(f.WithContext(ctx))
+}
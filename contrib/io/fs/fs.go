@@ -0,0 +1,253 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package fs provides functions to trace file operations, through the io/fs package
+// (https://golang.org/pkg/io/fs) or os files (https://golang.org/pkg/os).
+//
+// Only the operations which are slower or larger than the configured thresholds are
+// traced, along with failed operations, so that services dominated by file I/O such
+// as data pipelines can find their bottlenecks without tracing every call. Spans are
+// named after the first directories of the paths of the files.
+package fs // import "github.com/codebrick-corp/dd-trace-go/contrib/io/fs"
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+const componentName = "io/fs"
+
+func init() {
+	integrations.Register(componentName, "io/fs")
+}
+
+const (
+	tagPathPrefix = "file.path_prefix"
+	tagBytes      = "file.bytes"
+)
+
+// errUnsupported is returned by the methods of the files of traced file systems which
+// aren't implemented by the underlying files.
+var errUnsupported = errors.New("operation not supported")
+
+// WrapFS returns a file system tracing the opening and reading of the files of fsys.
+func WrapFS(fsys fs.FS, opts ...Option) fs.FS {
+	cfg := newConfig(opts...)
+	log.Debug("contrib/io/fs: Wrapping FS: %#v", cfg)
+	return &tracedFS{fsys: fsys, cfg: cfg}
+}
+
+type tracedFS struct {
+	fsys fs.FS
+	cfg  *config
+}
+
+// Open implements fs.FS.
+func (t *tracedFS) Open(name string) (fs.File, error) {
+	start := time.Now()
+	f, err := t.fsys.Open(name)
+	t.cfg.trace("fs.open", name, start, -1, err)
+	if err != nil {
+		return nil, err
+	}
+	return &fsFile{File: f, name: name, cfg: t.cfg}, nil
+}
+
+// fsFile is a file opened by a traced file system.
+type fsFile struct {
+	fs.File
+	name string
+	cfg  *config
+}
+
+func (f *fsFile) Read(b []byte) (int, error) {
+	start := time.Now()
+	n, err := f.File.Read(b)
+	f.cfg.trace("fs.read", f.name, start, int64(n), err)
+	return n, err
+}
+
+// ReadAt implements io.ReaderAt when the underlying file does.
+func (f *fsFile) ReadAt(b []byte, off int64) (int, error) {
+	ra, ok := f.File.(io.ReaderAt)
+	if !ok {
+		return 0, &fs.PathError{Op: "readat", Path: f.name, Err: errUnsupported}
+	}
+	start := time.Now()
+	n, err := ra.ReadAt(b, off)
+	f.cfg.trace("fs.read", f.name, start, int64(n), err)
+	return n, err
+}
+
+// Seek implements io.Seeker when the underlying file does.
+func (f *fsFile) Seek(offset int64, whence int) (int64, error) {
+	s, ok := f.File.(io.Seeker)
+	if !ok {
+		return 0, &fs.PathError{Op: "seek", Path: f.name, Err: errUnsupported}
+	}
+	return s.Seek(offset, whence)
+}
+
+// ReadDir implements fs.ReadDirFile when the underlying file does.
+func (f *fsFile) ReadDir(n int) ([]fs.DirEntry, error) {
+	d, ok := f.File.(fs.ReadDirFile)
+	if !ok {
+		return nil, &fs.PathError{Op: "readdir", Path: f.name, Err: errUnsupported}
+	}
+	return d.ReadDir(n)
+}
+
+// File is an os file tracing its reads and writes.
+type File struct {
+	*os.File
+	cfg *config
+}
+
+// Open opens the named file for reading like os.Open and traces the operation.
+func Open(name string, opts ...Option) (*File, error) {
+	return OpenFile(name, os.O_RDONLY, 0, opts...)
+}
+
+// Create creates or truncates the named file like os.Create and traces the operation.
+func Create(name string, opts ...Option) (*File, error) {
+	return OpenFile(name, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0666, opts...)
+}
+
+// OpenFile opens the named file like os.OpenFile and traces the operation.
+func OpenFile(name string, flag int, perm os.FileMode, opts ...Option) (*File, error) {
+	cfg := newConfig(opts...)
+	log.Debug("contrib/io/fs: Opening File: %#v", cfg)
+	start := time.Now()
+	f, err := os.OpenFile(name, flag, perm)
+	cfg.trace("fs.open", filepath.ToSlash(name), start, -1, err)
+	if err != nil {
+		return nil, err
+	}
+	return &File{File: f, cfg: cfg}, nil
+}
+
+// WithContext returns a copy of the file whose operations are children of the span
+// held by ctx.
+func (f *File) WithContext(ctx context.Context) *File {
+	cfg := *f.cfg
+	cfg.ctx = ctx
+	return &File{File: f.File, cfg: &cfg}
+}
+
+func (f *File) Read(b []byte) (int, error) {
+	start := time.Now()
+	n, err := f.File.Read(b)
+	f.trace("fs.read", start, int64(n), err)
+	return n, err
+}
+
+// ReadAt reads from the file like os.File.ReadAt and traces the operation.
+func (f *File) ReadAt(b []byte, off int64) (int, error) {
+	start := time.Now()
+	n, err := f.File.ReadAt(b, off)
+	f.trace("fs.read", start, int64(n), err)
+	return n, err
+}
+
+// WriteTo implements io.WriterTo, tracing the copy of the file to w as a read.
+func (f *File) WriteTo(w io.Writer) (int64, error) {
+	start := time.Now()
+	// hide the methods of the file to avoid calling this method again
+	n, err := io.Copy(w, struct{ io.Reader }{f.File})
+	f.trace("fs.read", start, n, err)
+	return n, err
+}
+
+func (f *File) Write(b []byte) (int, error) {
+	start := time.Now()
+	n, err := f.File.Write(b)
+	f.trace("fs.write", start, int64(n), err)
+	return n, err
+}
+
+// WriteAt writes to the file like os.File.WriteAt and traces the operation.
+func (f *File) WriteAt(b []byte, off int64) (int, error) {
+	start := time.Now()
+	n, err := f.File.WriteAt(b, off)
+	f.trace("fs.write", start, int64(n), err)
+	return n, err
+}
+
+// WriteString writes s to the file like os.File.WriteString and traces the operation.
+func (f *File) WriteString(s string) (int, error) {
+	return f.Write([]byte(s))
+}
+
+// ReadFrom implements io.ReaderFrom, tracing the copy of r to the file as a write.
+func (f *File) ReadFrom(r io.Reader) (int64, error) {
+	start := time.Now()
+	n, err := f.File.ReadFrom(r)
+	f.trace("fs.write", start, n, err)
+	return n, err
+}
+
+func (f *File) trace(op string, start time.Time, n int64, err error) {
+	f.cfg.trace(op, filepath.ToSlash(f.Name()), start, n, err)
+}
+
+// trace creates a span for the file operation op on the file at the given slash
+// separated path, which started at start and processed n bytes, or -1 if it doesn't
+// process any data, when it is slower or larger than the thresholds or failed.
+func (cfg *config) trace(op, name string, start time.Time, n int64, err error) {
+	if err == io.EOF {
+		err = nil
+	}
+	if err == nil && time.Since(start) < cfg.durationThreshold && n < cfg.sizeThreshold {
+		return
+	}
+	prefix := pathPrefix(name, cfg.pathPrefixDepth)
+	opts := []ddtrace.StartSpanOption{
+		tracer.StartTime(start),
+		tracer.ResourceName(prefix),
+		tracer.Tag(tagPathPrefix, prefix),
+		tracer.Tag(ext.SpanKind, ext.SpanKindInternal),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if cfg.serviceName != "" {
+		opts = append(opts, tracer.ServiceName(cfg.serviceName))
+	}
+	if n >= 0 {
+		opts = append(opts, tracer.Tag(tagBytes, n))
+	}
+	span, _ := tracer.StartSpanFromContext(cfg.ctx, op, opts...)
+	span.Finish(tracer.WithError(err))
+}
+
+// pathPrefix returns the first depth elements of the directory of the file at the
+// given slash separated path.
+func pathPrefix(name string, depth int) string {
+	dir := path.Dir(path.Clean(name))
+	if dir == "." || dir == "/" {
+		return dir
+	}
+	rooted := strings.HasPrefix(dir, "/")
+	elems := strings.Split(strings.TrimPrefix(dir, "/"), "/")
+	if len(elems) > depth {
+		elems = elems[:depth]
+	}
+	prefix := strings.Join(elems, "/")
+	if rooted {
+		prefix = "/" + prefix
+	}
+	return prefix
+}
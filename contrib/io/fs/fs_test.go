@@ -0,0 +1,128 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package fs
+
+import (
+	"context"
+	"io/fs"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func TestWrapFS(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	root, ctx := tracer.StartSpanFromContext(context.Background(), "pipeline")
+	fsys := WrapFS(fstest.MapFS{
+		"input/2022/large.csv": {Data: []byte(strings.Repeat("a", 100))},
+		"input/2022/small.csv": {Data: []byte("a")},
+	}, WithContext(ctx), WithServiceName("pipeline"), WithSizeThreshold(10), WithDurationThreshold(time.Hour))
+
+	data, err := fs.ReadFile(fsys, "input/2022/large.csv")
+	require.NoError(t, err)
+	assert.Len(t, data, 100)
+	_, err = fs.ReadFile(fsys, "input/2022/small.csv")
+	require.NoError(t, err)
+	_, err = fsys.Open("input/missing.csv")
+	assert.Error(t, err)
+	entries, err := fs.ReadDir(fsys, "input/2022")
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+	root.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 3)
+	read, open := spans[0], spans[1]
+	assert.Equal(t, "fs.read", read.OperationName())
+	assert.Equal(t, "input/2022", read.Tag(ext.ResourceName))
+	assert.Equal(t, "input/2022", read.Tag(tagPathPrefix))
+	assert.Equal(t, int64(100), read.Tag(tagBytes))
+	assert.Equal(t, "pipeline", read.Tag(ext.ServiceName))
+	assert.Equal(t, root.Context().SpanID(), read.ParentID())
+
+	assert.Equal(t, "fs.open", open.OperationName())
+	assert.Equal(t, "input", open.Tag(ext.ResourceName))
+	assert.Nil(t, open.Tag(tagBytes))
+	assert.NotNil(t, open.Tag(ext.Error))
+}
+
+func TestFile(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	name := filepath.Join(t.TempDir(), "out.csv")
+	f, err := Create(name, WithSizeThreshold(10), WithDurationThreshold(time.Hour))
+	require.NoError(t, err)
+	_, err = f.WriteString("a")
+	require.NoError(t, err)
+	_, err = f.ReadFrom(strings.NewReader(strings.Repeat("a", 99)))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	f, err = Open(name, WithSizeThreshold(10), WithDurationThreshold(time.Hour))
+	require.NoError(t, err)
+	root, ctx := tracer.StartSpanFromContext(context.Background(), "pipeline")
+	data, err := ioutil.ReadAll(f.WithContext(ctx))
+	require.NoError(t, err)
+	assert.Len(t, data, 100)
+	require.NoError(t, f.Close())
+	root.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 3)
+	write, read := spans[0], spans[1]
+	assert.Equal(t, "fs.write", write.OperationName())
+	assert.Equal(t, int64(99), write.Tag(tagBytes))
+	assert.Equal(t, "fs.read", read.OperationName())
+	assert.Equal(t, int64(100), read.Tag(tagBytes))
+	assert.Equal(t, root.Context().SpanID(), read.ParentID())
+	assert.Equal(t, pathPrefix(filepath.ToSlash(name), 2), read.Tag(tagPathPrefix))
+}
+
+func TestDurationThreshold(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	fsys := WrapFS(fstest.MapFS{"a.txt": {Data: []byte("a")}}, WithDurationThreshold(0))
+	_, err := fs.ReadFile(fsys, "a.txt")
+	require.NoError(t, err)
+
+	var ops []string
+	for _, s := range mt.FinishedSpans() {
+		ops = append(ops, s.OperationName())
+	}
+	assert.Equal(t, []string{"fs.open", "fs.read", "fs.read"}, ops)
+}
+
+func TestPathPrefix(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		depth  int
+		prefix string
+	}{
+		{"a.txt", 2, "."},
+		{"/a.txt", 2, "/"},
+		{"in/a.txt", 2, "in"},
+		{"in/2022/01/a.txt", 2, "in/2022"},
+		{"/data/in/2022/a.txt", 2, "/data/in"},
+		{"/data/in/2022/a.txt", 3, "/data/in/2022"},
+		{"/data/./in/../out/a.txt", 2, "/data/out"},
+	} {
+		assert.Equal(t, tt.prefix, pathPrefix(tt.name, tt.depth), tt.name)
+	}
+}
@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package fs
+
+import (
+	"context"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+type config struct {
+	ctx               context.Context
+	serviceName       string
+	sizeThreshold     int64
+	durationThreshold time.Duration
+	pathPrefixDepth   int
+}
+
+func newConfig(opts ...Option) *config {
+	cfg := &config{
+		ctx:               context.Background(),
+		serviceName:       globalconfig.ServiceName(),
+		sizeThreshold:     1 << 20,
+		durationThreshold: 10 * time.Millisecond,
+		pathPrefixDepth:   2,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
+
+// Option represents an option that can be used to customize the tracing of file
+// operations.
+type Option func(*config)
+
+// WithContext sets the context holding the parent span of the file operations.
+func WithContext(ctx context.Context) Option {
+	return func(cfg *config) {
+		cfg.ctx = ctx
+	}
+}
+
+// WithServiceName sets the given service name for the file operations. It defaults
+// to the global service name.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithSizeThreshold sets the number of bytes from which reads and writes are traced,
+// whatever their duration. It defaults to 1MiB.
+func WithSizeThreshold(n int64) Option {
+	return func(cfg *config) {
+		cfg.sizeThreshold = n
+	}
+}
+
+// WithDurationThreshold sets the duration from which file operations are traced,
+// whatever their size. It defaults to 10ms. Failed operations are always traced.
+func WithDurationThreshold(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.durationThreshold = d
+	}
+}
+
+// WithPathPrefixDepth sets the number of directories kept from the paths of the
+// files to name their operations, e.g. "/data/input" for "/data/input/2022/01.csv"
+// with a depth of 2, which is the default.
+func WithPathPrefixDepth(n int) Option {
+	return func(cfg *config) {
+		cfg.pathPrefixDepth = n
+	}
+}
@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package pgx
+
+import (
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+type config struct {
+	serviceName   string
+	statsInterval time.Duration
+}
+
+// Option represents an option that can be used to create or wrap a pool.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	cfg.serviceName = "postgres.db"
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+	cfg.statsInterval = 10 * time.Second
+}
+
+// WithServiceName sets the given service name for the pool.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithPoolStatsInterval sets the interval at which the pool statistics (total,
+// idle and acquired connections, acquire counters) are sent to the agent's
+// DogStatsD endpoint. A zero or negative interval disables the reporting.
+// Defaults to 10 seconds.
+func WithPoolStatsInterval(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.statsInterval = d
+	}
+}
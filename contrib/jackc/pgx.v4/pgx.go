@@ -0,0 +1,330 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package pgx provides functions to trace the jackc/pgx package (https://github.com/jackc/pgx).
+// It traces the time spent waiting for a connection from a pgxpool.Pool and
+// reports the pool statistics to the agent's DogStatsD endpoint.
+package pgx // import "github.com/codebrick-corp/dd-trace-go/contrib/jackc/pgx.v4"
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/jackc/pgconn"
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+)
+
+const componentName = "jackc/pgx.v4"
+
+func init() {
+	integrations.Register(componentName, "github.com/jackc/pgx/v4")
+}
+
+const (
+	// tagPoolTotalConns holds the number of connections in the pool at acquire time.
+	tagPoolTotalConns = "db.pool.total_conns"
+	// tagPoolIdleConns holds the number of idle connections in the pool at acquire time.
+	tagPoolIdleConns = "db.pool.idle_conns"
+	// tagPoolAcquiredConns holds the number of acquired connections at acquire time.
+	tagPoolAcquiredConns = "db.pool.acquired_conns"
+	// tagPoolMaxConns holds the maximum size of the pool.
+	tagPoolMaxConns = "db.pool.max_conns"
+	// tagPoolSaturated is set to true when every connection of the pool is acquired.
+	tagPoolSaturated = "db.pool.saturated"
+)
+
+// Pool is a pgxpool.Pool which traces the time spent acquiring connections.
+// It must be created using ConnectPool or ConnectPoolConfig.
+type Pool struct {
+	*pgxpool.Pool
+
+	cfg    *config
+	dbName string
+	stop   chan struct{}
+	wg     sync.WaitGroup
+}
+
+// ConnectPool creates a new traced Pool from the given connection string.
+// See pgxpool.Connect for more information.
+func ConnectPool(ctx context.Context, connString string, opts ...Option) (*Pool, error) {
+	pcfg, err := pgxpool.ParseConfig(connString)
+	if err != nil {
+		return nil, err
+	}
+	return ConnectPoolConfig(ctx, pcfg, opts...)
+}
+
+// ConnectPoolConfig creates a new traced Pool from the given configuration,
+// which must have been created by pgxpool.ParseConfig. The configuration is
+// copied and left untouched. See pgxpool.ConnectConfig for more information.
+func ConnectPoolConfig(ctx context.Context, pcfg *pgxpool.Config, opts ...Option) (*Pool, error) {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/jackc/pgx.v4: Configuring Pool: %#v", cfg)
+	p := &Pool{
+		cfg:    cfg,
+		dbName: pcfg.ConnConfig.Database,
+		stop:   make(chan struct{}),
+	}
+	pcfg = pcfg.Copy()
+	beforeAcquire := pcfg.BeforeAcquire
+	pcfg.BeforeAcquire = func(ctx context.Context, conn *pgx.Conn) bool {
+		p.acquired(ctx)
+		if beforeAcquire == nil {
+			return true
+		}
+		if ok := beforeAcquire(ctx, conn); !ok {
+			// the connection is destroyed and the pool tries to acquire another one
+			if st, ok := ctx.Value(acquireKey{}).(*acquireState); ok {
+				st.start = time.Now()
+			}
+			return false
+		}
+		return true
+	}
+	pool, err := pgxpool.ConnectConfig(ctx, pcfg)
+	if err != nil {
+		return nil, err
+	}
+	p.Pool = pool
+	if cfg.statsInterval > 0 {
+		p.wg.Add(1)
+		go p.reportStats()
+	}
+	return p, nil
+}
+
+// acquireKey is the context key holding the *acquireState of an acquire call.
+type acquireKey struct{}
+
+// acquireState holds the state of a single call acquiring a connection.
+type acquireState struct {
+	// start is the time at which the call started waiting for a connection.
+	start time.Time
+	// done reports whether the acquire span was already finished.
+	done bool
+}
+
+// startAcquire returns a context holding the state of an acquire call starting
+// now. The returned function must be called with the error returned by the call.
+func (p *Pool) startAcquire(ctx context.Context) (context.Context, func(error)) {
+	st := &acquireState{start: time.Now()}
+	ctx = context.WithValue(ctx, acquireKey{}, st)
+	return ctx, func(err error) {
+		if err == nil || st.done {
+			return
+		}
+		// the pool failed to hand out a connection, e.g. because ctx expired
+		// while every connection was in use.
+		p.finishAcquireSpan(ctx, st, err)
+	}
+}
+
+// acquired is called by the pool every time a connection was acquired.
+func (p *Pool) acquired(ctx context.Context) {
+	st, ok := ctx.Value(acquireKey{}).(*acquireState)
+	if !ok || st.done {
+		// AcquireAllIdle does not wait on the pool and is not traced
+		return
+	}
+	p.finishAcquireSpan(ctx, st, nil)
+}
+
+func (p *Pool) finishAcquireSpan(ctx context.Context, st *acquireState, err error) {
+	st.done = true
+	opts := []ddtrace.StartSpanOption{
+		tracer.StartTime(st.start),
+		tracer.ServiceName(p.cfg.serviceName),
+		tracer.ResourceName("pgx.pool.acquire"),
+		tracer.SpanType(ext.SpanTypeSQL),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.Tag(ext.DBSystem, ext.DBSystemPostgreSQL),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if p.dbName != "" {
+		opts = append(opts, tracer.Tag(ext.DBName, p.dbName))
+	}
+	span, _ := tracer.StartSpanFromContext(ctx, "pgx.pool.acquire", opts...)
+	if p.Pool != nil {
+		s := p.Pool.Stat()
+		span.SetTag(tagPoolTotalConns, s.TotalConns())
+		span.SetTag(tagPoolIdleConns, s.IdleConns())
+		span.SetTag(tagPoolAcquiredConns, s.AcquiredConns())
+		span.SetTag(tagPoolMaxConns, s.MaxConns())
+		span.SetTag(tagPoolSaturated, s.AcquiredConns() >= s.MaxConns())
+	}
+	span.Finish(tracer.WithError(err))
+}
+
+// Acquire calls pgxpool.Pool.Acquire, tracing the time spent waiting for a connection.
+func (p *Pool) Acquire(ctx context.Context) (*pgxpool.Conn, error) {
+	ctx, done := p.startAcquire(ctx)
+	conn, err := p.Pool.Acquire(ctx)
+	done(err)
+	return conn, err
+}
+
+// AcquireFunc calls pgxpool.Pool.AcquireFunc, tracing the time spent waiting for a connection.
+func (p *Pool) AcquireFunc(ctx context.Context, f func(*pgxpool.Conn) error) error {
+	ctx, done := p.startAcquire(ctx)
+	err := p.Pool.AcquireFunc(ctx, f)
+	done(err)
+	return err
+}
+
+// Exec calls pgxpool.Pool.Exec, tracing the time spent waiting for a connection.
+func (p *Pool) Exec(ctx context.Context, sql string, arguments ...interface{}) (pgconn.CommandTag, error) {
+	ctx, done := p.startAcquire(ctx)
+	tag, err := p.Pool.Exec(ctx, sql, arguments...)
+	done(err)
+	return tag, err
+}
+
+// Query calls pgxpool.Pool.Query, tracing the time spent waiting for a connection.
+func (p *Pool) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	ctx, done := p.startAcquire(ctx)
+	rows, err := p.Pool.Query(ctx, sql, args...)
+	done(err)
+	return rows, err
+}
+
+// QueryRow calls pgxpool.Pool.QueryRow, tracing the time spent waiting for a connection.
+// Acquire errors are deferred to the returned row and are not reported on the span.
+func (p *Pool) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	ctx, _ = p.startAcquire(ctx)
+	return p.Pool.QueryRow(ctx, sql, args...)
+}
+
+// QueryFunc calls pgxpool.Pool.QueryFunc, tracing the time spent waiting for a connection.
+func (p *Pool) QueryFunc(ctx context.Context, sql string, args []interface{}, scans []interface{}, f func(pgx.QueryFuncRow) error) (pgconn.CommandTag, error) {
+	ctx, done := p.startAcquire(ctx)
+	tag, err := p.Pool.QueryFunc(ctx, sql, args, scans, f)
+	done(err)
+	return tag, err
+}
+
+// SendBatch calls pgxpool.Pool.SendBatch, tracing the time spent waiting for a connection.
+// Acquire errors are deferred to the returned results and are not reported on the span.
+func (p *Pool) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	ctx, _ = p.startAcquire(ctx)
+	return p.Pool.SendBatch(ctx, b)
+}
+
+// Begin calls pgxpool.Pool.Begin, tracing the time spent waiting for a connection.
+func (p *Pool) Begin(ctx context.Context) (pgx.Tx, error) {
+	ctx, done := p.startAcquire(ctx)
+	tx, err := p.Pool.Begin(ctx)
+	done(err)
+	return tx, err
+}
+
+// BeginTx calls pgxpool.Pool.BeginTx, tracing the time spent waiting for a connection.
+func (p *Pool) BeginTx(ctx context.Context, txOptions pgx.TxOptions) (pgx.Tx, error) {
+	ctx, done := p.startAcquire(ctx)
+	tx, err := p.Pool.BeginTx(ctx, txOptions)
+	done(err)
+	return tx, err
+}
+
+// BeginFunc calls pgxpool.Pool.BeginFunc, tracing the time spent waiting for a connection.
+func (p *Pool) BeginFunc(ctx context.Context, f func(pgx.Tx) error) error {
+	ctx, done := p.startAcquire(ctx)
+	err := p.Pool.BeginFunc(ctx, f)
+	done(err)
+	return err
+}
+
+// BeginTxFunc calls pgxpool.Pool.BeginTxFunc, tracing the time spent waiting for a connection.
+func (p *Pool) BeginTxFunc(ctx context.Context, txOptions pgx.TxOptions, f func(pgx.Tx) error) error {
+	ctx, done := p.startAcquire(ctx)
+	err := p.Pool.BeginTxFunc(ctx, txOptions, f)
+	done(err)
+	return err
+}
+
+// CopyFrom calls pgxpool.Pool.CopyFrom, tracing the time spent waiting for a connection.
+func (p *Pool) CopyFrom(ctx context.Context, tableName pgx.Identifier, columnNames []string, rowSrc pgx.CopyFromSource) (int64, error) {
+	ctx, done := p.startAcquire(ctx)
+	n, err := p.Pool.CopyFrom(ctx, tableName, columnNames, rowSrc)
+	done(err)
+	return n, err
+}
+
+// Ping calls pgxpool.Pool.Ping, tracing the time spent waiting for a connection.
+func (p *Pool) Ping(ctx context.Context) error {
+	ctx, done := p.startAcquire(ctx)
+	err := p.Pool.Ping(ctx)
+	done(err)
+	return err
+}
+
+// Close stops reporting the pool statistics and closes the pool.
+func (p *Pool) Close() {
+	select {
+	case <-p.stop:
+	default:
+		close(p.stop)
+	}
+	p.wg.Wait()
+	p.Pool.Close()
+}
+
+// poolCounters holds the last reported values of the cumulative pool counters.
+type poolCounters struct {
+	acquire         int64
+	emptyAcquire    int64
+	canceledAcquire int64
+}
+
+// reportStats sends the pool statistics every statsInterval until the pool is closed.
+func (p *Pool) reportStats() {
+	defer p.wg.Done()
+	tick := time.NewTicker(p.cfg.statsInterval)
+	defer tick.Stop()
+	var last poolCounters
+	for {
+		select {
+		case <-tick.C:
+			p.sendStats(&last)
+		case <-p.stop:
+			return
+		}
+	}
+}
+
+// sendStats sends a snapshot of the pool statistics. Cumulative counters are sent
+// as the difference with the values recorded in last, which is then updated.
+func (p *Pool) sendStats(last *poolCounters) {
+	s := p.Pool.Stat()
+	var tags []string
+	if p.dbName != "" {
+		tags = []string{ext.DBName + ":" + p.dbName}
+	}
+	tracer.Gauge("pgx.pool.total_conns", float64(s.TotalConns()), tags...)
+	tracer.Gauge("pgx.pool.idle_conns", float64(s.IdleConns()), tags...)
+	tracer.Gauge("pgx.pool.acquired_conns", float64(s.AcquiredConns()), tags...)
+	tracer.Gauge("pgx.pool.constructing_conns", float64(s.ConstructingConns()), tags...)
+	tracer.Gauge("pgx.pool.max_conns", float64(s.MaxConns()), tags...)
+	cur := poolCounters{
+		acquire:         s.AcquireCount(),
+		emptyAcquire:    s.EmptyAcquireCount(),
+		canceledAcquire: s.CanceledAcquireCount(),
+	}
+	tracer.Count("pgx.pool.acquire", cur.acquire-last.acquire, tags...)
+	tracer.Count("pgx.pool.empty_acquire", cur.emptyAcquire-last.emptyAcquire, tags...)
+	tracer.Count("pgx.pool.canceled_acquire", cur.canceledAcquire-last.canceledAcquire, tags...)
+	*last = cur
+}
@@ -15,11 +15,18 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/jinzhu/gorm"
 )
 
+const componentName = "jinzhu/gorm"
+
+func init() {
+	integrations.Register(componentName, "github.com/jinzhu/gorm")
+}
+
 const (
 	gormContextKey       = "dd-trace-go:context"
 	gormConfigKey        = "dd-trace-go:config"
@@ -124,7 +131,9 @@ func after(scope *gorm.Scope, operationName string) {
 		tracer.StartTime(t),
 		tracer.ServiceName(cfg.serviceName),
 		tracer.SpanType(ext.SpanTypeSQL),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ResourceName(scope.SQL),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
@@ -14,10 +14,15 @@ package sqlx // import "github.com/codebrick-corp/dd-trace-go/contrib/jmoiron/sq
 
 import (
 	sqltraced "github.com/codebrick-corp/dd-trace-go/contrib/database/sql"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 
 	"github.com/jmoiron/sqlx"
 )
 
+func init() {
+	integrations.Register("jmoiron/sqlx", "github.com/jmoiron/sqlx")
+}
+
 // Open opens a new (traced) connection to the database using the given driver and source.
 // Note that the driver must formerly be registered using database/sql integration's Register.
 func Open(driverName, dataSourceName string, opts ...sqltraced.Option) (*sqlx.DB, error) {
@@ -14,11 +14,18 @@ import (
 	httptrace "github.com/codebrick-corp/dd-trace-go/contrib/net/http"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/julienschmidt/httprouter"
 )
 
+const componentName = "julienschmidt/httprouter"
+
+func init() {
+	integrations.Register(componentName, "github.com/julienschmidt/httprouter")
+}
+
 // Router is a traced version of httprouter.Router.
 type Router struct {
 	*httprouter.Router
@@ -35,6 +42,7 @@ func New(opts ...RouterOption) *Router {
 	if !math.IsNaN(cfg.analyticsRate) {
 		cfg.spanOpts = append(cfg.spanOpts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
 	}
+	cfg.spanOpts = append(cfg.spanOpts, tracer.Tag(ext.Component, componentName))
 	log.Debug("contrib/julienschmidt/httprouter: Configuring Router: %#v", cfg)
 	return &Router{httprouter.New(), cfg}
 }
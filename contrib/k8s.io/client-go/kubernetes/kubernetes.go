@@ -14,9 +14,17 @@ import (
 	httptrace "github.com/codebrick-corp/dd-trace-go/contrib/net/http"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 )
 
+const componentName = "k8s.io/client-go/kubernetes"
+
+func init() {
+	integrations.Register(componentName, "k8s.io/client-go/kubernetes")
+}
+
 const (
 	prefixAPI   = "/api/v1/"
 	prefixWatch = "watch/"
@@ -38,6 +46,7 @@ func WrapRoundTripper(rt http.RoundTripper) http.RoundTripper {
 }
 
 func wrapRoundTripperWithOptions(rt http.RoundTripper, opts ...httptrace.RoundTripperOption) http.RoundTripper {
+	opts = append(opts, httptrace.RTWithSpanOptions(tracer.Tag(ext.Component, componentName)))
 	opts = append(opts, httptrace.WithBefore(func(req *http.Request, span ddtrace.Span) {
 		span.SetTag(ext.ResourceName, RequestToResource(req.Method, req.URL.Path))
 		traceID := span.Context().TraceID()
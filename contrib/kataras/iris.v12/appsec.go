@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package iris
+
+import (
+	"net"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/appsec/dyngo/instrumentation"
+	"github.com/codebrick-corp/dd-trace-go/internal/appsec/dyngo/instrumentation/httpsec"
+
+	"github.com/kataras/iris/v12"
+)
+
+// useAppSec starts the AppSec operation of the request of ctx and returns the function
+// finishing it. The path parameters are only known when the middleware runs after the router.
+func useAppSec(ctx iris.Context, span tracer.Span) func() {
+	req := ctx.Request()
+	httpsec.SetAppSecTags(span)
+	params := make(map[string]string)
+	ctx.Params().Visit(func(key, value string) {
+		params[key] = value
+	})
+	args := httpsec.MakeHandlerOperationArgs(req, params)
+	c, op := httpsec.StartOperation(req.Context(), args)
+	ctx.ResetRequest(req.WithContext(c))
+	return func() {
+		events := op.Finish(httpsec.HandlerOperationRes{Status: ctx.GetStatusCode()})
+		if len(events) > 0 {
+			remoteIP, _, err := net.SplitHostPort(req.RemoteAddr)
+			if err != nil {
+				remoteIP = req.RemoteAddr
+			}
+			httpsec.SetSecurityEventTags(span, events, remoteIP, args.Headers, ctx.ResponseWriter().Header())
+		}
+		instrumentation.SetTags(span, op.Tags())
+	}
+}
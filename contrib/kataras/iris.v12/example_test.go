@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package iris_test
+
+import (
+	iristrace "github.com/codebrick-corp/dd-trace-go/contrib/kataras/iris.v12"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/kataras/iris/v12"
+)
+
+func Example() {
+	// Start the tracer
+	tracer.Start()
+	defer tracer.Stop()
+
+	// Trace all the requests served by the application.
+	app := iris.New()
+	app.UseRouter(iristrace.Middleware(iristrace.WithServiceName("my-web-app")))
+
+	// Set up some endpoints.
+	app.Get("/", func(ctx iris.Context) {
+		ctx.WriteString("test")
+	})
+
+	// And start gathering request traces
+	app.Listen(":8080")
+}
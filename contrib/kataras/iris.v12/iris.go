@@ -0,0 +1,71 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package iris provides functions to trace the kataras/iris/v12 package (https://github.com/kataras/iris).
+package iris // import "github.com/codebrick-corp/dd-trace-go/contrib/kataras/iris.v12"
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/appsec"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/kataras/iris/v12"
+)
+
+const componentName = "kataras/iris.v12"
+
+func init() {
+	integrations.Register(componentName, "github.com/kataras/iris/v12")
+}
+
+// Middleware returns a middleware tracing the incoming requests. Registered with the UseRouter
+// method of the application, it traces all the requests, including the ones which matched no
+// route, while with the Use method it only traces the requests of the routes registered after it.
+// The requests are named after the template of the route which matched them. The errors set with
+// SetErr or StopWithError are reported when the status code of the response is an error.
+func Middleware(opts ...Option) iris.Handler {
+	appsecEnabled := appsec.Enabled()
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/kataras/iris.v12: Configuring Middleware: %#v", cfg)
+	spanOpts := []ddtrace.StartSpanOption{tracer.ServiceName(cfg.serviceName), tracer.Tag(ext.Component, componentName)}
+	mw := httptrace.NewMiddleware()
+	return func(ctx iris.Context) {
+		if cfg.ignoreRequest(ctx) {
+			ctx.Next()
+			return
+		}
+		req := ctx.Request()
+		opts := append([]ddtrace.StartSpanOption{tracer.ResourceName(req.Method)}, spanOpts...)
+		span, c := mw.StartRequestSpan(req, opts...)
+		ctx.ResetRequest(req.WithContext(c))
+		defer func() {
+			// the route is only known once the request went through the router
+			// when the middleware is registered with UseRouter
+			if route := ctx.GetCurrentRoute(); route != nil {
+				span.SetTag(ext.ResourceName, req.Method+" "+route.Path())
+				span.SetTag(ext.HTTPRoute, route.Path())
+			}
+			status := ctx.GetStatusCode()
+			finishOpts := cfg.finishOpts[:len(cfg.finishOpts):len(cfg.finishOpts)]
+			if err := ctx.GetErr(); err != nil && cfg.isStatusError(status) {
+				finishOpts = append(finishOpts, tracer.WithError(err))
+			}
+			httptrace.FinishRequestSpan(span, status, &httptrace.Response{Header: ctx.ResponseWriter().Header()}, finishOpts...)
+		}()
+		if appsecEnabled {
+			afterMiddleware := useAppSec(ctx, span)
+			defer afterMiddleware()
+		}
+		ctx.Next()
+	}
+}
@@ -0,0 +1,94 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package iris
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/kataras/iris/v12"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newApp(t *testing.T, opts ...Option) *iris.Application {
+	app := iris.New()
+	app.UseRouter(Middleware(opts...))
+	app.Get("/user/{id:uint64}", func(ctx iris.Context) {
+		if _, ok := tracer.SpanFromContext(ctx.Request().Context()); !ok {
+			ctx.StatusCode(http.StatusExpectationFailed)
+			return
+		}
+		ctx.WriteString(ctx.Params().Get("id"))
+	})
+	app.Get("/err", func(ctx iris.Context) {
+		ctx.StopWithError(http.StatusServiceUnavailable, errors.New("unavailable"))
+	})
+	app.Get("/bad", func(ctx iris.Context) {
+		ctx.StopWithError(http.StatusBadRequest, errors.New("bad request"))
+	})
+	require.NoError(t, app.Build())
+	return app
+}
+
+func TestMiddleware(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	app := newApp(t, WithServiceName("foobar"))
+	w := httptest.NewRecorder()
+	app.ServeHTTP(w, httptest.NewRequest("GET", "/user/123", nil))
+	assert.Equal(http.StatusOK, w.Code)
+	assert.Equal("123", w.Body.String())
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 1)
+	span := spans[0]
+	assert.Equal("http.request", span.OperationName())
+	assert.Equal("foobar", span.Tag(ext.ServiceName))
+	assert.Equal("GET /user/{id:uint64}", span.Tag(ext.ResourceName))
+	assert.Equal("/user/{id:uint64}", span.Tag(ext.HTTPRoute))
+	assert.Equal("/user/123", span.Tag(ext.HTTPURL))
+	assert.Equal("200", span.Tag(ext.HTTPCode))
+}
+
+func TestErrors(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	app := newApp(t)
+	for _, path := range []string{"/err", "/bad", "/unknown"} {
+		app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 3)
+	assert.Equal("503", spans[0].Tag(ext.HTTPCode))
+	assert.Equal("unavailable", spans[0].Tag(ext.Error).(error).Error())
+	assert.Equal("400", spans[1].Tag(ext.HTTPCode))
+	assert.Nil(spans[1].Tag(ext.Error))
+	assert.Equal("404", spans[2].Tag(ext.HTTPCode))
+	assert.Equal("GET", spans[2].Tag(ext.ResourceName))
+}
+
+func TestIgnoreRequest(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	app := newApp(t, WithIgnoreRequest(func(ctx iris.Context) bool {
+		return ctx.Path() == "/err"
+	}))
+	app.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/err", nil))
+	assert.Len(t, mt.FinishedSpans(), 0)
+}
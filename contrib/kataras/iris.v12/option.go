@@ -0,0 +1,72 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package iris
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+
+	"github.com/kataras/iris/v12"
+)
+
+const defaultServiceName = "iris"
+
+type config struct {
+	serviceName   string
+	finishOpts    []ddtrace.FinishOption
+	isStatusError func(statusCode int) bool
+	ignoreRequest func(iris.Context) bool
+}
+
+// Option represents an option that can be passed to Middleware.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	cfg.serviceName = defaultServiceName
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+	cfg.isStatusError = isServerError
+	cfg.ignoreRequest = func(iris.Context) bool { return false }
+}
+
+// WithServiceName sets the given service name for the application.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithStatusCheck sets a function telling whether a status code makes the errors set on the
+// context of the request reported. It defaults to the 5xx status codes, which are always
+// reported as errors.
+func WithStatusCheck(fn func(statusCode int) bool) Option {
+	return func(cfg *config) {
+		cfg.isStatusError = fn
+	}
+}
+
+// WithIgnoreRequest sets a function deciding whether the request of the given context
+// is served without being traced.
+func WithIgnoreRequest(fn func(iris.Context) bool) Option {
+	return func(cfg *config) {
+		cfg.ignoreRequest = fn
+	}
+}
+
+// NoDebugStack prevents stack traces from being attached to spans finishing
+// with an error. This is useful in situations where errors are frequent and
+// performance is critical.
+func NoDebugStack() Option {
+	return func(cfg *config) {
+		cfg.finishOpts = append(cfg.finishOpts, tracer.NoDebugStack())
+	}
+}
+
+func isServerError(statusCode int) bool {
+	return statusCode >= 500 && statusCode < 600
+}
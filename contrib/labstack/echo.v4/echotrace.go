@@ -8,17 +8,26 @@ package echo
 
 import (
 	"math"
+	"net/http"
+	"reflect"
 
 	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
 	"github.com/codebrick-corp/dd-trace-go/internal/appsec"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/labstack/echo/v4"
 )
 
+const componentName = "labstack/echo.v4"
+
+func init() {
+	integrations.Register(componentName, "github.com/labstack/echo/v4")
+}
+
 // Middleware returns echo middleware which will trace incoming requests.
 func Middleware(opts ...Option) echo.MiddlewareFunc {
 	appsecEnabled := appsec.Enabled()
@@ -30,13 +39,24 @@ func Middleware(opts ...Option) echo.MiddlewareFunc {
 	log.Debug("contrib/labstack/echo.v4: Configuring Middleware: %#v", cfg)
 	spanOpts := []ddtrace.StartSpanOption{
 		tracer.ServiceName(cfg.serviceName),
+		tracer.Tag(ext.Component, componentName),
 	}
+	mw := httptrace.NewMiddleware()
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
+			if cfg.skipper(c) {
+				return next(c)
+			}
 			request := c.Request()
-			route := c.Path()
-			resource := request.Method + " " + route
-			opts := append(spanOpts, tracer.ResourceName(resource), tracer.Tag(ext.HTTPRoute, route))
+			var opts []ddtrace.StartSpanOption
+			if isNotFound(c) {
+				// name unmatched requests after their method only, rather than
+				// after their path, to avoid unbounded resource names
+				opts = append(spanOpts, tracer.ResourceName(request.Method+" unknown"))
+			} else {
+				route := c.Path()
+				opts = append(spanOpts, tracer.ResourceName(request.Method+" "+route), tracer.Tag(ext.HTTPRoute, route))
+			}
 
 			if !math.IsNaN(cfg.analyticsRate) {
 				opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
@@ -47,13 +67,19 @@ func Middleware(opts ...Option) echo.MiddlewareFunc {
 				finishOpts = []tracer.FinishOption{tracer.NoDebugStack()}
 			}
 
-			span, ctx := httptrace.StartRequestSpan(request, opts...)
+			span, ctx := mw.StartRequestSpan(request, opts...)
 			defer func() {
-				httptrace.FinishRequestSpan(span, c.Response().Status, finishOpts...)
+				if c.Response().Status == http.StatusTooManyRequests {
+					httptrace.SetRateLimitTags(span, c.Response().Header())
+				}
+				httptrace.FinishRequestSpan(span, c.Response().Status, &httptrace.Response{Header: c.Response().Header(), Size: c.Response().Size}, finishOpts...)
 			}()
 
 			// pass the span through the request context
 			c.SetRequest(request.WithContext(ctx))
+			// trace the connection once upgraded to the WebSocket protocol
+			c.Response().Writer = httptrace.WrapWebSocket(c.Response().Writer, request, span, cfg.webSocketFlushInterval,
+				tracer.ServiceName(cfg.serviceName), tracer.Tag(ext.Component, componentName))
 			// serve the request to the next middleware
 			if appsecEnabled {
 				afterMiddleware := useAppSec(c, span)
@@ -70,3 +96,11 @@ func Middleware(opts ...Option) echo.MiddlewareFunc {
 		}
 	}
 }
+
+// isNotFound reports whether no route matched the request of c, in which case echo sets
+// its path to the path of the request. Catch-all routes registered with RouteNotFound
+// on recent versions of echo match the requests, being named after their own path.
+func isNotFound(c echo.Context) bool {
+	h := c.Handler()
+	return h != nil && reflect.ValueOf(h).Pointer() == reflect.ValueOf(echo.NotFoundHandler).Pointer()
+}
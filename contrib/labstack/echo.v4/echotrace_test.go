@@ -6,12 +6,17 @@
 package echo
 
 import (
+	"bufio"
 	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	pappsec "github.com/codebrick-corp/dd-trace-go/appsec"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
@@ -272,6 +277,62 @@ func TestNoDebugStack(t *testing.T) {
 	assert.Equal("<debug stack disabled>", span.Tag(ext.ErrorStack))
 }
 
+func TestNotFound(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	router := echo.New()
+	router.Use(Middleware())
+	router.GET("/user/:id", func(c echo.Context) error {
+		return c.NoContent(200)
+	})
+	router.Any("/static/*", func(c echo.Context) error {
+		return c.NoContent(404)
+	})
+
+	for _, path := range []string{"/unknown/123", "/static/file"} {
+		r := httptest.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		assert.Equal(404, w.Code)
+	}
+
+	spans := mt.FinishedSpans()
+	assert.Len(spans, 2)
+	assert.Equal("GET unknown", spans[0].Tag(ext.ResourceName))
+	assert.Nil(spans[0].Tag(ext.HTTPRoute))
+	assert.Equal("404", spans[0].Tag(ext.HTTPCode))
+	assert.Equal("GET /static/*", spans[1].Tag(ext.ResourceName))
+	assert.Equal("/static/*", spans[1].Tag(ext.HTTPRoute))
+}
+
+func TestSkipper(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	var traced bool
+
+	router := echo.New()
+	router.Use(Middleware(WithSkipper(func(c echo.Context) bool {
+		return c.Request().URL.Path == "/health"
+	})))
+	router.GET("/:path", func(c echo.Context) error {
+		_, traced = tracer.SpanFromContext(c.Request().Context())
+		return c.NoContent(200)
+	})
+
+	r := httptest.NewRequest("GET", "/health", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.False(traced)
+	assert.Len(mt.FinishedSpans(), 0)
+
+	r = httptest.NewRequest("GET", "/users", nil)
+	router.ServeHTTP(httptest.NewRecorder(), r)
+	assert.True(traced)
+	assert.Len(mt.FinishedSpans(), 1)
+}
+
 func TestAppSec(t *testing.T) {
 	appsec.Start()
 	defer appsec.Stop()
@@ -417,3 +478,48 @@ func TestAppSec(t *testing.T) {
 		require.True(t, strings.Contains(event.(string), "crs-933-130"))
 	})
 }
+
+func TestWebSocket(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	router := echo.New()
+	router.Use(Middleware(WithServiceName("foobar"), WithWebSocketFlushInterval(0)))
+	router.GET("/ws", func(c echo.Context) error {
+		conn, rw, err := c.Response().Hijack()
+		require.NoError(t, err)
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		rw.Flush()
+		frame := make([]byte, 8) // masked text frame
+		_, err = io.ReadFull(rw, frame)
+		require.NoError(t, err)
+		rw.Write([]byte{0x81, 2, 'o', 'k'})
+		rw.Flush()
+		return nil
+	})
+	srv := httptest.NewServer(router)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+	fmt.Fprintf(conn, "GET /ws HTTP/1.1\r\nHost: localhost\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	conn.Write([]byte{0x81, 0x82, 1, 2, 3, 4, 'h' ^ 1, 'i' ^ 2})
+	_, err = io.ReadFull(br, make([]byte, 4))
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool { return len(mt.FinishedSpans()) == 2 }, time.Second, 10*time.Millisecond)
+	spans := mt.FinishedSpans()
+	ws, req := spans[0], spans[1]
+	assert.Equal(t, "websocket.connection", ws.OperationName())
+	assert.Equal(t, "http.request", req.OperationName())
+	assert.Equal(t, req.SpanID(), ws.ParentID())
+	assert.Equal(t, "foobar", ws.Tag(ext.ServiceName))
+	assert.Equal(t, int64(1), ws.Tag("websocket.messages.received"))
+	assert.Equal(t, int64(1), ws.Tag("websocket.messages.sent"))
+}
@@ -7,14 +7,21 @@ package echo
 
 import (
 	"math"
+	"time"
 
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
 	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+
+	"github.com/labstack/echo/v4"
 )
 
 type config struct {
 	serviceName   string
 	analyticsRate float64
 	noDebugStack  bool
+	skipper       func(echo.Context) bool
+
+	webSocketFlushInterval time.Duration
 }
 
 // Option represents an option that can be passed to Middleware.
@@ -22,10 +29,12 @@ type Option func(*config)
 
 func defaults(cfg *config) {
 	cfg.serviceName = "echo"
+	cfg.webSocketFlushInterval = httptrace.DefaultWebSocketFlushInterval
 	if svc := globalconfig.ServiceName(); svc != "" {
 		cfg.serviceName = svc
 	}
 	cfg.analyticsRate = math.NaN()
+	cfg.skipper = func(echo.Context) bool { return false }
 }
 
 // WithServiceName sets the given service name for the system.
@@ -66,3 +75,21 @@ func NoDebugStack() Option {
 		cfg.noDebugStack = true
 	}
 }
+
+// WithWebSocketFlushInterval sets the interval at which the statistics of traced
+// WebSocket connections are flushed, by finishing their current span and starting a
+// new one. A zero interval traces each connection with a single span. It defaults
+// to one minute.
+func WithWebSocketFlushInterval(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.webSocketFlushInterval = d
+	}
+}
+
+// WithSkipper sets a function deciding whether the request of the given context is
+// served without being traced, following the convention of echo's own middleware.
+func WithSkipper(skipper func(c echo.Context) bool) Option {
+	return func(cfg *config) {
+		cfg.skipper = skipper
+	}
+}
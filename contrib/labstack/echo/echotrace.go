@@ -8,16 +8,24 @@ package echo
 
 import (
 	"math"
+	"net/http"
 
 	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/labstack/echo"
 )
 
+const componentName = "labstack/echo"
+
+func init() {
+	integrations.Register(componentName, "github.com/labstack/echo")
+}
+
 // Middleware returns echo middleware which will trace incoming requests.
 func Middleware(opts ...Option) echo.MiddlewareFunc {
 	cfg := new(config)
@@ -28,7 +36,9 @@ func Middleware(opts ...Option) echo.MiddlewareFunc {
 	log.Debug("contrib/labstack/echo: Configuring Middleware: %#v", cfg)
 	spanOpts := []ddtrace.StartSpanOption{
 		tracer.ServiceName(cfg.serviceName),
+		tracer.Tag(ext.Component, componentName),
 	}
+	mw := httptrace.NewMiddleware()
 	return func(next echo.HandlerFunc) echo.HandlerFunc {
 		return func(c echo.Context) error {
 			request := c.Request()
@@ -44,13 +54,19 @@ func Middleware(opts ...Option) echo.MiddlewareFunc {
 				finishOpts = []tracer.FinishOption{tracer.NoDebugStack()}
 			}
 
-			span, ctx := httptrace.StartRequestSpan(request, opts...)
+			span, ctx := mw.StartRequestSpan(request, opts...)
 			defer func() {
-				httptrace.FinishRequestSpan(span, c.Response().Status, finishOpts...)
+				if c.Response().Status == http.StatusTooManyRequests {
+					httptrace.SetRateLimitTags(span, c.Response().Header())
+				}
+				httptrace.FinishRequestSpan(span, c.Response().Status, &httptrace.Response{Header: c.Response().Header(), Size: c.Response().Size}, finishOpts...)
 			}()
 
 			// pass the span through the request context
 			c.SetRequest(request.WithContext(ctx))
+			// trace the connection once upgraded to the WebSocket protocol
+			c.Response().Writer = httptrace.WrapWebSocket(c.Response().Writer, request, span, cfg.webSocketFlushInterval,
+				tracer.ServiceName(cfg.serviceName), tracer.Tag(ext.Component, componentName))
 
 			// serve the request to the next middleware
 			err := next(c)
@@ -7,7 +7,9 @@ package echo
 
 import (
 	"math"
+	"time"
 
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
 	"github.com/codebrick-corp/dd-trace-go/internal"
 	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
 )
@@ -16,6 +18,8 @@ type config struct {
 	serviceName   string
 	analyticsRate float64
 	noDebugStack  bool
+
+	webSocketFlushInterval time.Duration
 }
 
 // Option represents an option that can be passed to Middleware.
@@ -23,6 +27,7 @@ type Option func(*config)
 
 func defaults(cfg *config) {
 	cfg.serviceName = "echo"
+	cfg.webSocketFlushInterval = httptrace.DefaultWebSocketFlushInterval
 	if svc := globalconfig.ServiceName(); svc != "" {
 		cfg.serviceName = svc
 	}
@@ -71,3 +76,13 @@ func NoDebugStack() Option {
 		cfg.noDebugStack = true
 	}
 }
+
+// WithWebSocketFlushInterval sets the interval at which the statistics of traced
+// WebSocket connections are flushed, by finishing their current span and starting a
+// new one. A zero interval traces each connection with a single span. It defaults
+// to one minute.
+func WithWebSocketFlushInterval(d time.Duration) Option {
+	return func(cfg *config) {
+		cfg.webSocketFlushInterval = d
+	}
+}
@@ -16,9 +16,16 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 )
 
+const componentName = "miekg/dns"
+
+func init() {
+	integrations.Register(componentName, "github.com/miekg/dns")
+}
+
 // ListenAndServe calls dns.ListenAndServe with a wrapped Handler.
 func ListenAndServe(addr string, network string, handler dns.Handler) error {
 	return dns.ListenAndServe(addr, network, WrapHandler(handler))
@@ -45,7 +52,7 @@ func WrapHandler(handler dns.Handler) *Handler {
 // ServeDNS dispatches requests to the underlying Handler. All requests will be
 // traced.
 func (h *Handler) ServeDNS(w dns.ResponseWriter, r *dns.Msg) {
-	span, _ := startSpan(context.Background(), r.Opcode)
+	span, _ := startSpan(context.Background(), r.Opcode, ext.SpanKindServer)
 	rw := &responseWriter{ResponseWriter: w}
 	h.Handler.ServeDNS(rw, r)
 	span.Finish(tracer.WithError(rw.err))
@@ -67,7 +74,7 @@ func (rw *responseWriter) WriteMsg(msg *dns.Msg) error {
 
 // Exchange calls dns.Exchange and traces the request.
 func Exchange(m *dns.Msg, addr string) (r *dns.Msg, err error) {
-	span, _ := startSpan(context.Background(), m.Opcode)
+	span, _ := startSpan(context.Background(), m.Opcode, ext.SpanKindClient)
 	r, err = dns.Exchange(m, addr)
 	span.Finish(tracer.WithError(err))
 	return r, err
@@ -75,7 +82,7 @@ func Exchange(m *dns.Msg, addr string) (r *dns.Msg, err error) {
 
 // ExchangeConn calls dns.ExchangeConn and traces the request.
 func ExchangeConn(c net.Conn, m *dns.Msg) (r *dns.Msg, err error) {
-	span, _ := startSpan(context.Background(), m.Opcode)
+	span, _ := startSpan(context.Background(), m.Opcode, ext.SpanKindClient)
 	r, err = dns.ExchangeConn(c, m)
 	span.Finish(tracer.WithError(err))
 	return r, err
@@ -83,7 +90,7 @@ func ExchangeConn(c net.Conn, m *dns.Msg) (r *dns.Msg, err error) {
 
 // ExchangeContext calls dns.ExchangeContext and traces the request.
 func ExchangeContext(ctx context.Context, m *dns.Msg, addr string) (r *dns.Msg, err error) {
-	span, ctx := startSpan(ctx, m.Opcode)
+	span, ctx := startSpan(ctx, m.Opcode, ext.SpanKindClient)
 	r, err = dns.ExchangeContext(ctx, m, addr)
 	span.Finish(tracer.WithError(err))
 	return r, err
@@ -96,7 +103,7 @@ type Client struct {
 
 // Exchange calls the underlying Client.Exchange and traces the request.
 func (c *Client) Exchange(m *dns.Msg, addr string) (r *dns.Msg, rtt time.Duration, err error) {
-	span, _ := startSpan(context.Background(), m.Opcode)
+	span, _ := startSpan(context.Background(), m.Opcode, ext.SpanKindClient)
 	r, rtt, err = c.Client.Exchange(m, addr)
 	span.Finish(tracer.WithError(err))
 	return r, rtt, err
@@ -104,15 +111,17 @@ func (c *Client) Exchange(m *dns.Msg, addr string) (r *dns.Msg, rtt time.Duratio
 
 // ExchangeContext calls the underlying Client.ExchangeContext and traces the request.
 func (c *Client) ExchangeContext(ctx context.Context, m *dns.Msg, addr string) (r *dns.Msg, rtt time.Duration, err error) {
-	span, ctx := startSpan(ctx, m.Opcode)
+	span, ctx := startSpan(ctx, m.Opcode, ext.SpanKindClient)
 	r, rtt, err = c.Client.ExchangeContext(ctx, m, addr)
 	span.Finish(tracer.WithError(err))
 	return r, rtt, err
 }
 
-func startSpan(ctx context.Context, opcode int) (ddtrace.Span, context.Context) {
+func startSpan(ctx context.Context, opcode int, kind string) (ddtrace.Span, context.Context) {
 	return tracer.StartSpanFromContext(ctx, "dns.request",
 		tracer.ServiceName("dns"),
 		tracer.ResourceName(dns.OpcodeToString[opcode]),
-		tracer.SpanType(ext.SpanTypeDNS))
+		tracer.SpanType(ext.SpanTypeDNS),
+		tracer.Tag(ext.SpanKind, kind),
+		tracer.Tag(ext.Component, componentName))
 }
@@ -56,12 +56,15 @@ func TestDNS(t *testing.T) {
 
 	spans := mt.FinishedSpans()
 	assert.Len(t, spans, 2)
+	kinds := make(map[interface{}]bool)
 	for _, s := range spans {
+		kinds[s.Tag(ext.SpanKind)] = true
 		assert.Equal(t, "dns.request", s.OperationName())
 		assert.Equal(t, "dns", s.Tag(ext.SpanType))
 		assert.Equal(t, "dns", s.Tag(ext.ServiceName))
 		assert.Equal(t, "QUERY", s.Tag(ext.ResourceName))
 	}
+	assert.Equal(t, map[interface{}]bool{ext.SpanKindServer: true, ext.SpanKindClient: true}, kinds)
 }
 
 func getFreeAddr(t *testing.T) net.Addr {
@@ -0,0 +1,40 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package neo4j_test
+
+import (
+	"context"
+	"log"
+
+	neo4jtrace "github.com/codebrick-corp/dd-trace-go/contrib/neo4j/neo4j-go-driver.v5"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+func Example() {
+	d, err := neo4j.NewDriverWithContext("neo4j://localhost:7687", neo4j.BasicAuth("neo4j", "password", ""))
+	if err != nil {
+		log.Fatal(err)
+	}
+	driver := neo4jtrace.WrapDriver(d, neo4jtrace.WithServiceName("my-graph"))
+	defer driver.Close(context.Background())
+
+	// The session, its transaction and the query are traced.
+	ctx := context.Background()
+	session := driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead, DatabaseName: "movies"})
+	defer session.Close(ctx)
+	titles, err := session.ExecuteRead(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		result, err := tx.Run(ctx, "MATCH (m:Movie) WHERE m.released > $year RETURN m.title", map[string]any{"year": 2000})
+		if err != nil {
+			return nil, err
+		}
+		return result.Collect(ctx)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	log.Println(titles)
+}
@@ -0,0 +1,225 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package neo4j provides functions to trace the github.com/neo4j/neo4j-go-driver/v5 package
+// (https://github.com/neo4j/neo4j-go-driver). The sessions, transactions and queries of a
+// driver returned by WrapDriver are traced, the spans of the queries being children of the
+// span of their transaction, if any, or else of their session. The queries are obfuscated,
+// and their parameters are never recorded.
+//
+// The queries run by neo4j.ExecuteQuery are only traced as part of their session, the
+// function running them through unexported methods of the driver.
+package neo4j // import "github.com/codebrick-corp/dd-trace-go/contrib/neo4j/neo4j-go-driver.v5"
+
+import (
+	"context"
+	"sync"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/graphquery"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+)
+
+const componentName = "neo4j/neo4j-go-driver.v5"
+
+func init() {
+	integrations.Register(componentName, "github.com/neo4j/neo4j-go-driver/v5")
+}
+
+const (
+	// sessionOperationName is the name of the spans of sessions.
+	sessionOperationName = "neo4j.session"
+	// transactionOperationName is the name of the spans of transactions.
+	transactionOperationName = "neo4j.transaction"
+	// runOperationName is the name of the spans of queries.
+	runOperationName = "neo4j.run"
+
+	// tagAccessMode holds the access mode of a session: "read" or "write".
+	tagAccessMode = "neo4j.access_mode"
+)
+
+// WrapDriver returns a driver tracing the sessions opened with d, along with their
+// transactions and queries.
+func WrapDriver(d neo4j.DriverWithContext, opts ...Option) neo4j.DriverWithContext {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	target := d.Target()
+	cfg.host, cfg.port = target.Hostname(), target.Port()
+	log.Debug("contrib/neo4j/neo4j-go-driver.v5: Wrapping Driver: %#v", cfg)
+	return &driver{DriverWithContext: d, cfg: cfg}
+}
+
+// driver is a neo4j.DriverWithContext tracing its sessions.
+type driver struct {
+	neo4j.DriverWithContext
+	cfg *config
+}
+
+// NewSession implements neo4j.DriverWithContext. The span of the session is started from
+// ctx, and finished when the session is closed.
+func (d *driver) NewSession(ctx context.Context, config neo4j.SessionConfig) neo4j.SessionWithContext {
+	mode := "write"
+	if config.AccessMode == neo4j.AccessModeRead {
+		mode = "read"
+	}
+	s := &session{
+		SessionWithContext: d.DriverWithContext.NewSession(ctx, config),
+		cfg:                d.cfg,
+		database:           config.DatabaseName,
+	}
+	s.span, _ = s.startSpan(ctx, nil, sessionOperationName, "session", tracer.Tag(tagAccessMode, mode))
+	return s
+}
+
+// session is a neo4j.SessionWithContext tracing its transactions and queries.
+type session struct {
+	neo4j.SessionWithContext
+	cfg      *config
+	database string
+	span     ddtrace.Span
+}
+
+// BeginTransaction implements neo4j.SessionWithContext. The span of the transaction is
+// finished when it is committed, rolled back or closed.
+func (s *session) BeginTransaction(ctx context.Context, configurers ...func(*neo4j.TransactionConfig)) (neo4j.ExplicitTransaction, error) {
+	span, _ := s.startSpan(ctx, s.span, transactionOperationName, "transaction")
+	tx, err := s.SessionWithContext.BeginTransaction(ctx, configurers...)
+	if err != nil {
+		span.Finish(tracer.WithError(err))
+		return nil, err
+	}
+	return &explicitTransaction{ExplicitTransaction: tx, session: s, span: span}, nil
+}
+
+// ExecuteRead implements neo4j.SessionWithContext. The span of the transaction covers all
+// the attempts of work.
+func (s *session) ExecuteRead(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	return s.execute(ctx, s.SessionWithContext.ExecuteRead, work, configurers...)
+}
+
+// ExecuteWrite implements neo4j.SessionWithContext. The span of the transaction covers all
+// the attempts of work.
+func (s *session) ExecuteWrite(ctx context.Context, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	return s.execute(ctx, s.SessionWithContext.ExecuteWrite, work, configurers...)
+}
+
+type executeFunc func(context.Context, neo4j.ManagedTransactionWork, ...func(*neo4j.TransactionConfig)) (any, error)
+
+func (s *session) execute(ctx context.Context, execute executeFunc, work neo4j.ManagedTransactionWork, configurers ...func(*neo4j.TransactionConfig)) (any, error) {
+	span, _ := s.startSpan(ctx, s.span, transactionOperationName, "transaction")
+	res, err := execute(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return work(&managedTransaction{ManagedTransaction: tx, session: s, span: span})
+	}, configurers...)
+	span.Finish(tracer.WithError(err))
+	return res, err
+}
+
+// Run implements neo4j.SessionWithContext, running the query in an auto-commit transaction.
+func (s *session) Run(ctx context.Context, cypher string, params map[string]any, configurers ...func(*neo4j.TransactionConfig)) (neo4j.ResultWithContext, error) {
+	span, ctx := s.startSpan(ctx, s.span, runOperationName, graphquery.Obfuscate(cypher))
+	res, err := s.SessionWithContext.Run(ctx, cypher, params, configurers...)
+	span.Finish(tracer.WithError(err))
+	return res, err
+}
+
+// Close implements neo4j.SessionWithContext.
+func (s *session) Close(ctx context.Context) error {
+	err := s.SessionWithContext.Close(ctx)
+	s.span.Finish(tracer.WithError(err))
+	return err
+}
+
+// startSpan starts a span of the session from ctx, as a child of parent when set.
+func (s *session) startSpan(ctx context.Context, parent ddtrace.Span, name, resource string, opts ...ddtrace.StartSpanOption) (ddtrace.Span, context.Context) {
+	opts = append(opts,
+		tracer.SpanType(ext.SpanTypeNeo4j),
+		tracer.ServiceName(s.cfg.serviceName),
+		tracer.ResourceName(resource),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.Tag(ext.DBSystem, ext.DBSystemNeo4j),
+		tracer.Tag(ext.Component, componentName),
+	)
+	if parent != nil {
+		ctx = tracer.ContextWithSpan(ctx, parent)
+	}
+	if s.database != "" {
+		opts = append(opts, tracer.Tag(ext.DBName, s.database))
+	}
+	if s.cfg.host != "" {
+		opts = append(opts, tracer.Tag(ext.TargetHost, s.cfg.host))
+	}
+	if s.cfg.port != "" {
+		opts = append(opts, tracer.Tag(ext.TargetPort, s.cfg.port))
+	}
+	return tracer.StartSpanFromContext(ctx, name, opts...)
+}
+
+// explicitTransaction is a neo4j.ExplicitTransaction tracing its queries.
+type explicitTransaction struct {
+	neo4j.ExplicitTransaction
+	session *session
+	span    ddtrace.Span
+	once    sync.Once
+}
+
+// Run implements neo4j.ExplicitTransaction.
+func (tx *explicitTransaction) Run(ctx context.Context, cypher string, params map[string]any) (neo4j.ResultWithContext, error) {
+	span, ctx := tx.session.startSpan(ctx, tx.span, runOperationName, graphquery.Obfuscate(cypher))
+	res, err := tx.ExplicitTransaction.Run(ctx, cypher, params)
+	span.Finish(tracer.WithError(err))
+	return res, err
+}
+
+// Commit implements neo4j.ExplicitTransaction.
+func (tx *explicitTransaction) Commit(ctx context.Context) error {
+	err := tx.ExplicitTransaction.Commit(ctx)
+	tx.finish(err)
+	return err
+}
+
+// Rollback implements neo4j.ExplicitTransaction.
+func (tx *explicitTransaction) Rollback(ctx context.Context) error {
+	err := tx.ExplicitTransaction.Rollback(ctx)
+	tx.finish(err)
+	return err
+}
+
+// Close implements neo4j.ExplicitTransaction.
+func (tx *explicitTransaction) Close(ctx context.Context) error {
+	err := tx.ExplicitTransaction.Close(ctx)
+	tx.finish(err)
+	return err
+}
+
+// finish finishes the span of the transaction once it is committed, rolled back or closed,
+// whichever comes first.
+func (tx *explicitTransaction) finish(err error) {
+	tx.once.Do(func() {
+		tx.span.Finish(tracer.WithError(err))
+	})
+}
+
+// managedTransaction is a neo4j.ManagedTransaction tracing its queries.
+type managedTransaction struct {
+	neo4j.ManagedTransaction
+	session *session
+	span    ddtrace.Span
+}
+
+// Run implements neo4j.ManagedTransaction.
+func (tx *managedTransaction) Run(ctx context.Context, cypher string, params map[string]any) (neo4j.ResultWithContext, error) {
+	span, ctx := tx.session.startSpan(ctx, tx.span, runOperationName, graphquery.Obfuscate(cypher))
+	res, err := tx.ManagedTransaction.Run(ctx, cypher, params)
+	span.Finish(tracer.WithError(err))
+	return res, err
+}
@@ -0,0 +1,231 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package neo4j
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+
+	"github.com/neo4j/neo4j-go-driver/v5/neo4j"
+	neo4jconfig "github.com/neo4j/neo4j-go-driver/v5/neo4j/config"
+)
+
+// The fakes below stand in for a server, the real driver needing one to run queries. They
+// embed the interfaces of the driver, the methods they don't override panicking.
+
+type fakeDriver struct {
+	neo4j.DriverWithContext
+	run func(cypher string) error // called by the sessions and transactions to run queries
+}
+
+func (d *fakeDriver) Target() url.URL {
+	return url.URL{Scheme: "neo4j+s", Host: "graph.local:7687"}
+}
+
+func (d *fakeDriver) NewSession(_ context.Context, _ neo4j.SessionConfig) neo4j.SessionWithContext {
+	return &fakeSession{run: d.run}
+}
+
+type fakeSession struct {
+	neo4j.SessionWithContext
+	run func(cypher string) error
+}
+
+func (s *fakeSession) Run(_ context.Context, cypher string, _ map[string]any, _ ...func(*neo4j.TransactionConfig)) (neo4j.ResultWithContext, error) {
+	return nil, s.run(cypher)
+}
+
+func (s *fakeSession) BeginTransaction(context.Context, ...func(*neo4j.TransactionConfig)) (neo4j.ExplicitTransaction, error) {
+	return &fakeTransaction{run: s.run}, nil
+}
+
+func (s *fakeSession) ExecuteWrite(_ context.Context, work neo4j.ManagedTransactionWork, _ ...func(*neo4j.TransactionConfig)) (any, error) {
+	// retry once on failure, like the driver does with transient errors
+	res, err := work(&fakeTransaction{run: s.run})
+	if err != nil {
+		res, err = work(&fakeTransaction{run: s.run})
+	}
+	return res, err
+}
+
+func (s *fakeSession) Close(context.Context) error { return nil }
+
+type fakeTransaction struct {
+	neo4j.ExplicitTransaction
+	run func(cypher string) error
+}
+
+func (tx *fakeTransaction) Run(_ context.Context, cypher string, _ map[string]any) (neo4j.ResultWithContext, error) {
+	return nil, tx.run(cypher)
+}
+
+func (tx *fakeTransaction) Commit(context.Context) error { return nil }
+
+func (tx *fakeTransaction) Close(context.Context) error { return nil }
+
+func TestDriver(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	// nothing listens on the port: the queries fail with connectivity errors
+	d, err := neo4j.NewDriverWithContext("bolt://127.0.0.1:1", neo4j.NoAuth(), func(c *neo4jconfig.Config) {
+		c.MaxTransactionRetryTime = 0
+	})
+	require.NoError(t, err)
+	driver := WrapDriver(d)
+	defer driver.Close(context.Background())
+
+	root, ctx := tracer.StartSpanFromContext(context.Background(), "root")
+	session := driver.NewSession(ctx, neo4j.SessionConfig{AccessMode: neo4j.AccessModeRead, DatabaseName: "movies"})
+	_, runErr := session.Run(ctx, "MATCH (p:Person {name: 'Alice'}) RETURN p", nil)
+	require.Error(t, runErr)
+	_, txErr := session.BeginTransaction(ctx)
+	require.Error(t, txErr)
+	require.NoError(t, session.Close(ctx))
+	root.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 4)
+	r, tx, s := spans[0], spans[1], spans[2]
+
+	assert.Equal("neo4j.session", s.OperationName())
+	assert.Equal("session", s.Tag(ext.ResourceName))
+	assert.Equal("read", s.Tag(tagAccessMode))
+	assert.Equal(root.Context().SpanID(), s.ParentID())
+	assert.Nil(s.Tag(ext.Error))
+
+	assert.Equal("neo4j.run", r.OperationName())
+	assert.Equal("MATCH (p:Person {name: ?}) RETURN p", r.Tag(ext.ResourceName))
+	assert.Equal(s.SpanID(), r.ParentID())
+	assert.Equal(runErr, r.Tag(ext.Error))
+
+	assert.Equal("neo4j.transaction", tx.OperationName())
+	assert.Equal("transaction", tx.Tag(ext.ResourceName))
+	assert.Equal(s.SpanID(), tx.ParentID())
+	assert.Equal(txErr, tx.Tag(ext.Error))
+
+	for _, span := range spans[:3] {
+		assert.Equal("neo4j", span.Tag(ext.ServiceName))
+		assert.Equal(ext.SpanTypeNeo4j, span.Tag(ext.SpanType))
+		assert.Equal(ext.SpanKindClient, span.Tag(ext.SpanKind))
+		assert.Equal(ext.DBSystemNeo4j, span.Tag(ext.DBSystem))
+		assert.Equal(componentName, span.Tag(ext.Component))
+		assert.Equal("movies", span.Tag(ext.DBName))
+		assert.Equal("127.0.0.1", span.Tag(ext.TargetHost))
+		assert.Equal("1", span.Tag(ext.TargetPort))
+	}
+}
+
+func TestExplicitTransaction(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	driver := WrapDriver(&fakeDriver{run: func(string) error { return nil }})
+	ctx := context.Background()
+	session := driver.NewSession(ctx, neo4j.SessionConfig{})
+	tx, err := session.BeginTransaction(ctx)
+	require.NoError(t, err)
+	_, err = tx.Run(ctx, "CREATE (p:Person {name: $name})", map[string]any{"name": "Alice"})
+	require.NoError(t, err)
+	require.NoError(t, tx.Commit(ctx))
+	require.NoError(t, tx.Close(ctx))
+	require.NoError(t, session.Close(ctx))
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 3)
+	r, txn, s := spans[0], spans[1], spans[2]
+	assert.Equal("neo4j.run", r.OperationName())
+	assert.Equal("CREATE (p:Person {name: $name})", r.Tag(ext.ResourceName))
+	assert.Equal(txn.SpanID(), r.ParentID())
+	assert.Equal("neo4j.transaction", txn.OperationName())
+	assert.Equal(s.SpanID(), txn.ParentID())
+	assert.Equal("write", s.Tag(tagAccessMode))
+	assert.Equal("graph.local", s.Tag(ext.TargetHost))
+	assert.Equal("7687", s.Tag(ext.TargetPort))
+	assert.Nil(s.Tag(ext.DBName))
+}
+
+func TestManagedTransaction(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	deadlock := errors.New("deadlock detected")
+	attempts := 0
+	driver := WrapDriver(&fakeDriver{run: func(string) error {
+		if attempts++; attempts == 1 {
+			return deadlock
+		}
+		return nil
+	}})
+	ctx := context.Background()
+	session := driver.NewSession(ctx, neo4j.SessionConfig{})
+	_, err := session.ExecuteWrite(ctx, func(tx neo4j.ManagedTransaction) (any, error) {
+		return tx.Run(ctx, "MERGE (p:Person {name: 'Bob'})", nil)
+	})
+	require.NoError(t, err)
+	require.NoError(t, session.Close(ctx))
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 4)
+	first, second, txn, s := spans[0], spans[1], spans[2], spans[3]
+	assert.Equal("neo4j.transaction", txn.OperationName())
+	assert.Equal(s.SpanID(), txn.ParentID())
+	assert.Nil(txn.Tag(ext.Error))
+	// the attempts of the transaction are both part of its span
+	assert.Equal(txn.SpanID(), first.ParentID())
+	assert.Equal(deadlock, first.Tag(ext.Error))
+	assert.Equal(txn.SpanID(), second.ParentID())
+	assert.Nil(second.Tag(ext.Error))
+	assert.Equal("MERGE (p:Person {name: ?})", second.Tag(ext.ResourceName))
+}
+
+// run runs a query in a session of a traced fake driver configured with opts.
+func run(opts ...Option) {
+	driver := WrapDriver(&fakeDriver{run: func(string) error { return nil }}, opts...)
+	ctx := context.Background()
+	session := driver.NewSession(ctx, neo4j.SessionConfig{})
+	session.Run(ctx, "RETURN 1", nil)
+	session.Close(ctx)
+}
+
+func TestServiceName(t *testing.T) {
+	t.Run("global", func(t *testing.T) {
+		globalconfig.SetServiceName("global-service")
+		defer globalconfig.SetServiceName("")
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		run()
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "global-service", spans[0].Tag(ext.ServiceName))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		run(WithServiceName("my-graph"))
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "my-graph", spans[0].Tag(ext.ServiceName))
+	})
+}
@@ -9,13 +9,22 @@ package http // import "github.com/codebrick-corp/dd-trace-go/contrib/net/http"
 import (
 	"net/http"
 
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 )
 
+const componentName = "net/http"
+
+func init() {
+	integrations.Register(componentName, "net/http")
+}
+
 // ServeMux is an HTTP request multiplexer that traces all the incoming requests.
 type ServeMux struct {
 	*http.ServeMux
 	cfg *config
+	mw  *httptrace.Middleware
 }
 
 // NewServeMux allocates and returns an http.ServeMux augmented with the
@@ -30,6 +39,7 @@ func NewServeMux(opts ...Option) *ServeMux {
 	return &ServeMux{
 		ServeMux: http.NewServeMux(),
 		cfg:      cfg,
+		mw:       httptrace.NewMiddleware(),
 	}
 }
 
@@ -43,18 +53,24 @@ func (mux *ServeMux) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	// get the resource associated to this request
-	_, route := mux.Handler(r)
+	_, pattern := mux.Handler(r)
+	route := patternRoute(pattern)
 	resource := r.Method + " " + route
 	TraceAndServe(mux.ServeMux, w, r, &ServeConfig{
-		Service:  mux.cfg.serviceName,
-		Resource: resource,
-		SpanOpts: mux.cfg.spanOpts,
-		Route:    route,
+		Service:       mux.cfg.serviceName,
+		Resource:      resource,
+		SpanOpts:      mux.cfg.spanOpts,
+		Route:         route,
+		TraceIDHeader: mux.cfg.traceIDHeader,
+		ServerTiming:  mux.cfg.serverTiming,
+		Middleware:    mux.mw,
 	})
 }
 
 // WrapHandler wraps an http.Handler with tracing using the given service and resource.
 // If the WithResourceNamer option is provided as part of opts, it will take precedence over the resource argument.
+// When the resource is empty and h is an http.ServeMux, requests are named after the pattern which matched
+// them on Go 1.23 and later.
 func WrapHandler(h http.Handler, service, resource string, opts ...Option) http.Handler {
 	cfg := new(config)
 	defaults(cfg)
@@ -62,6 +78,7 @@ func WrapHandler(h http.Handler, service, resource string, opts ...Option) http.
 		fn(cfg)
 	}
 	log.Debug("contrib/net/http: Wrapping Handler: Service: %s, Resource: %s, %#v", service, resource, cfg)
+	mw := httptrace.NewMiddleware()
 	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
 		if cfg.ignoreRequest(req) {
 			h.ServeHTTP(w, req)
@@ -71,11 +88,14 @@ func WrapHandler(h http.Handler, service, resource string, opts ...Option) http.
 			resource = r
 		}
 		TraceAndServe(h, w, req, &ServeConfig{
-			Service:    service,
-			Resource:   resource,
-			FinishOpts: cfg.finishOpts,
-			SpanOpts:   cfg.spanOpts,
-			Route:      req.URL.EscapedPath(),
+			Service:       service,
+			Resource:      resource,
+			FinishOpts:    cfg.finishOpts,
+			SpanOpts:      cfg.spanOpts,
+			Route:         req.URL.EscapedPath(),
+			TraceIDHeader: cfg.traceIDHeader,
+			ServerTiming:  cfg.serverTiming,
+			Middleware:    mw,
 		})
 	})
 }
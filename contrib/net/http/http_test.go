@@ -6,16 +6,22 @@
 package http
 
 import (
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"runtime"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
 	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
 )
 
 func TestHttpTracer200(t *testing.T) {
@@ -37,6 +43,7 @@ func TestHttpTracer200(t *testing.T) {
 	s := spans[0]
 	assert.Equal("http.request", s.OperationName())
 	assert.Equal("my-service", s.Tag(ext.ServiceName))
+	assert.Equal("net/http", s.Tag(ext.Component))
 	assert.Equal("GET "+url, s.Tag(ext.ResourceName))
 	assert.Equal("200", s.Tag(ext.HTTPCode))
 	assert.Equal("GET", s.Tag(ext.HTTPMethod))
@@ -250,6 +257,99 @@ func TestIgnoreRequestOption(t *testing.T) {
 	}
 }
 
+func TestTraceIDHeaderOption(t *testing.T) {
+	mux := NewServeMux(WithTraceIDHeader(true))
+	mux.HandleFunc("/200", handler200)
+
+	t.Run("servemux", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		r := httptest.NewRequest("GET", "http://localhost/200", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, r)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, strconv.FormatUint(spans[0].TraceID(), 10), w.Header().Get("X-Datadog-Trace-Id"))
+	})
+
+	t.Run("wraphandler", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		r := httptest.NewRequest("GET", "http://localhost/200", nil)
+		w := httptest.NewRecorder()
+		handler := WrapHandler(http.HandlerFunc(handler200), "my-service", "my-resource", WithTraceIDHeader(true))
+		handler.ServeHTTP(w, r)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, strconv.FormatUint(spans[0].TraceID(), 10), w.Header().Get("X-Datadog-Trace-Id"))
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		r := httptest.NewRequest("GET", "http://localhost/200", nil)
+		w := httptest.NewRecorder()
+		router().ServeHTTP(w, r)
+
+		assert.Empty(t, w.Header().Get("X-Datadog-Trace-Id"))
+	})
+}
+
+func TestServerTimingOption(t *testing.T) {
+	for priority, flags := range map[string]string{
+		"1":  "01",
+		"-1": "00",
+	} {
+		t.Run(priority, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+			r := httptest.NewRequest("GET", "http://localhost/200", nil)
+			r.Header.Set("x-datadog-trace-id", "1")
+			r.Header.Set("x-datadog-parent-id", "2")
+			r.Header.Set("x-datadog-sampling-priority", priority)
+			w := httptest.NewRecorder()
+			handler := WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Add("Server-Timing", "db;dur=53")
+				handler200(w, r)
+			}), "my-service", "my-resource", WithServerTiming(true))
+			handler.ServeHTTP(w, r)
+
+			spans := mt.FinishedSpans()
+			require.Len(t, spans, 1)
+			traceparent := fmt.Sprintf(`traceparent;desc="00-%032x-%016x-%s"`, spans[0].TraceID(), spans[0].SpanID(), flags)
+			assert.Equal(t, []string{traceparent, "db;dur=53"}, w.Header().Values("Server-Timing"))
+		})
+	}
+}
+
+func TestPropagationAudit(t *testing.T) {
+	var rl log.RecordLogger
+	defer log.UseLogger(&rl)()
+	tracer.Start(tracer.WithPropagationAudit(true))
+	defer tracer.Stop()
+
+	mux := router()
+	for i := 0; i < 3; i++ {
+		r := httptest.NewRequest("GET", "/200", nil)
+		r.Header.Set(tracer.DefaultTraceIDHeader, "not-a-trace-id")
+		r.Header.Set(tracer.DefaultParentIDHeader, "456")
+		mux.ServeHTTP(httptest.NewRecorder(), r)
+	}
+
+	// the malformed headers of the requests are reported once a minute at most
+	var warnings []string
+	for _, l := range rl.Logs() {
+		if strings.Contains(l, "propagation headers") {
+			warnings = append(warnings, l)
+		}
+	}
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], `Span "http.request"`)
+	assert.Contains(t, warnings[0], tracer.ErrSpanContextCorrupted.Error())
+}
+
 func router() http.Handler {
 	mux := NewServeMux(WithServiceName("my-service"), WithSpanOptions(tracer.Tag("foo", "bar")))
 	mux.HandleFunc("/200", handler200)
@@ -264,3 +364,11 @@ func handler200(w http.ResponseWriter, r *http.Request) {
 func handler500(w http.ResponseWriter, r *http.Request) {
 	http.Error(w, "500!", http.StatusInternalServerError)
 }
+
+func TestIntegrationRegistered(t *testing.T) {
+	assert.Contains(t, tracer.Integrations(), tracer.Integration{
+		Name:    "net/http",
+		Library: "net/http",
+		Version: runtime.Version(),
+	})
+}
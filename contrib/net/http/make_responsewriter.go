@@ -7,9 +7,9 @@
 // +build ignore
 
 // This program generates wrapper implementations of http.ResponseWriter that
-// also satisfy http.Flusher, http.Pusher, http.CloseNotifier and http.Hijacker,
-// based on whether or not the passed in http.ResponseWriter also satisfies
-// them.
+// also satisfy http.Flusher, http.Pusher, http.CloseNotifier, http.Hijacker and
+// io.ReaderFrom, based on whether or not the passed in http.ResponseWriter also
+// satisfies them.
 
 package main
 
@@ -21,17 +21,39 @@ import (
 )
 
 func main() {
-	interfaces := []string{"Flusher", "Pusher", "CloseNotifier", "Hijacker"}
+	interfaces := []string{"Flusher", "Pusher", "CloseNotifier", "Hijacker", "ReaderFrom"}
 	var combos [][][]string
 	for pick := len(interfaces); pick > 0; pick-- {
 		combos = append(combos, lists.Combinations(interfaces, pick))
 	}
-	template.Must(template.New("").Parse(tpl)).Execute(os.Stdout, map[string]interface{}{
+	template.Must(template.New("").Funcs(funcs).Parse(tpl)).Execute(os.Stdout, map[string]interface{}{
 		"Interfaces":   interfaces,
 		"Combinations": combos,
 	})
 }
 
+var funcs = template.FuncMap{
+	// pkg returns the package declaring the given interface.
+	"pkg": func(iface string) string {
+		if iface == "ReaderFrom" {
+			return "io"
+		}
+		return "http"
+	},
+	// value returns the implementation of the given interface, which goes through
+	// the monitored response writer when writing the response.
+	"value": func(iface string) string {
+		switch iface {
+		case "Flusher":
+			return "mw.flusher(hFlusher)"
+		case "ReaderFrom":
+			return "mw.readerFrom(hReaderFrom)"
+		default:
+			return "h" + iface
+		}
+	},
+}
+
 var tpl = `// Unless explicitly stated otherwise all files in this repository are licensed
 // under the Apache License Version 2.0.
 // This product includes software developed at Datadog (https://www.datadoghq.com/).
@@ -41,12 +63,15 @@ var tpl = `// Unless explicitly stated otherwise all files in this repository ar
 
 package http
 
-import "net/http"
+import (
+	"io"
+	"net/http"
+)
 
 
 // wrapResponseWriter wraps an underlying http.ResponseWriter so that it can
 // trace the http response codes. It also checks for various http interfaces
-// (Flusher, Pusher, CloseNotifier, Hijacker) and if the underlying
+// (Flusher, Pusher, CloseNotifier, Hijacker, ReaderFrom) and if the underlying
 // http.ResponseWriter implements them it generates an unnamed struct with the
 // appropriate fields.
 //
@@ -54,7 +79,7 @@ import "net/http"
 // of the interfaces.
 func wrapResponseWriter(w http.ResponseWriter) (http.ResponseWriter, *responseWriter) {
 {{- range .Interfaces }}
-	h{{.}}, ok{{.}} := w.(http.{{.}})
+	h{{.}}, ok{{.}} := w.({{ pkg . }}.{{.}})
 {{- end }}
 
 	mw := newResponseWriter(w)
@@ -69,9 +94,9 @@ func wrapResponseWriter(w http.ResponseWriter) (http.ResponseWriter, *responseWr
 		w = struct {
 			monitoredResponseWriter
 		{{- range . }}
-			http.{{.}}
+			{{ pkg . }}.{{.}}
 		{{- end }}
-		}{mw{{ range . }}, h{{.}}{{ end }}}
+		}{mw{{ range . }}, {{ value . }}{{ end }}}
 	{{- end }}
 {{- end }}
 	default:
@@ -8,6 +8,7 @@ package http
 import (
 	"math"
 	"net/http"
+	"strings"
 
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
@@ -23,6 +24,8 @@ type config struct {
 	finishOpts    []ddtrace.FinishOption
 	ignoreRequest func(*http.Request) bool
 	resourceNamer func(*http.Request) string
+	traceIDHeader bool
+	serverTiming  bool
 }
 
 // MuxOption has been deprecated in favor of Option.
@@ -41,12 +44,14 @@ func defaults(cfg *config) {
 	if svc := globalconfig.ServiceName(); svc != "" {
 		cfg.serviceName = svc
 	}
-	cfg.spanOpts = []ddtrace.StartSpanOption{tracer.Measured()}
+	cfg.spanOpts = []ddtrace.StartSpanOption{tracer.Measured(), tracer.Tag(ext.Component, componentName)}
 	if !math.IsNaN(cfg.analyticsRate) {
 		cfg.spanOpts = append(cfg.spanOpts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
 	}
 	cfg.ignoreRequest = func(_ *http.Request) bool { return false }
 	cfg.resourceNamer = func(_ *http.Request) string { return "" }
+	cfg.traceIDHeader = internal.BoolEnv("DD_TRACE_HTTP_RESPONSE_TRACE_ID_ENABLED", false)
+	cfg.serverTiming = internal.BoolEnv("DD_TRACE_HTTP_RESPONSE_SERVER_TIMING_ENABLED", false)
 }
 
 // WithIgnoreRequest holds the function to use for determining if the
@@ -104,6 +109,27 @@ func WithResourceNamer(namer func(req *http.Request) string) Option {
 	}
 }
 
+// WithTraceIDHeader specifies whether the trace ID of each request is echoed in the
+// X-Datadog-Trace-Id response header, e.g. to let support tooling find the trace of a
+// response. It defaults to the value of the DD_TRACE_HTTP_RESPONSE_TRACE_ID_ENABLED
+// environment variable, or false.
+func WithTraceIDHeader(on bool) Option {
+	return func(cfg *config) {
+		cfg.traceIDHeader = on
+	}
+}
+
+// WithServerTiming specifies whether the trace context of each request is added to the
+// Server-Timing response header as a traceparent entry, allowing RUM sessions and synthetic
+// tests to be linked to the backend traces. Cross-origin pages additionally require the
+// Timing-Allow-Origin response header to be set. It defaults to the value of the
+// DD_TRACE_HTTP_RESPONSE_SERVER_TIMING_ENABLED environment variable, or false.
+func WithServerTiming(on bool) Option {
+	return func(cfg *config) {
+		cfg.serverTiming = on
+	}
+}
+
 // NoDebugStack prevents stack traces from being attached to spans finishing
 // with an error. This is useful in situations where errors are frequent and
 // performance is critical.
@@ -128,6 +154,8 @@ type roundTripperConfig struct {
 	serviceName   string
 	resourceNamer func(req *http.Request) string
 	spanOpts      []ddtrace.StartSpanOption
+	ignoreHosts   map[string]bool // lowercase hosts, with or without port, or domains starting with a dot
+	requestFilter func(req *http.Request) bool
 }
 
 func newRoundTripperConfig() *roundTripperConfig {
@@ -206,3 +234,52 @@ func RTWithAnalyticsRate(rate float64) RoundTripperOption {
 		}
 	}
 }
+
+// WithIgnoreHosts disables the tracing of the requests sent to the given hosts, e.g. the
+// telemetry endpoints or the cloud metadata services such as 169.254.169.254. A host matches
+// the requests sent to it on any port, a host:port only the requests sent to this port, and
+// a domain starting with a dot, e.g. ".internal.example.com", the requests sent to any of its
+// subdomains. The requests which are not traced do not carry the trace context either.
+func WithIgnoreHosts(hosts ...string) RoundTripperOption {
+	return func(cfg *roundTripperConfig) {
+		if cfg.ignoreHosts == nil {
+			cfg.ignoreHosts = make(map[string]bool, len(hosts))
+		}
+		for _, h := range hosts {
+			cfg.ignoreHosts[strings.ToLower(h)] = true
+		}
+	}
+}
+
+// WithRequestFilter sets a function which reports whether a request should be traced.
+// The requests for which it returns false are sent untraced and do not carry the trace
+// context. It is applied after WithIgnoreHosts.
+func WithRequestFilter(f func(req *http.Request) bool) RoundTripperOption {
+	return func(cfg *roundTripperConfig) {
+		cfg.requestFilter = f
+	}
+}
+
+// ignored reports whether the tracing of req is disabled by WithIgnoreHosts or
+// WithRequestFilter.
+func (cfg *roundTripperConfig) ignored(req *http.Request) bool {
+	if len(cfg.ignoreHosts) > 0 && req.URL != nil {
+		host := strings.ToLower(req.URL.Host)
+		hostname := strings.ToLower(req.URL.Hostname())
+		if cfg.ignoreHosts[host] || cfg.ignoreHosts[hostname] {
+			return true
+		}
+		// match the parent domains of hostname, e.g. ".example.com" and ".com" for "api.example.com"
+		for d := hostname; ; d = d[1:] {
+			i := strings.IndexByte(d, '.')
+			if i < 0 {
+				break
+			}
+			d = d[i:]
+			if cfg.ignoreHosts[d] {
+				return true
+			}
+		}
+	}
+	return cfg.requestFilter != nil && !cfg.requestFilter(req)
+}
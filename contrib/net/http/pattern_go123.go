@@ -0,0 +1,17 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+//go:build go1.23
+// +build go1.23
+
+package http
+
+import "net/http"
+
+// requestPattern returns the pattern which matched r, when it was routed by an
+// http.ServeMux.
+func requestPattern(r *http.Request) string {
+	return r.Pattern
+}
@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+//go:build go1.23
+// +build go1.23
+
+// The module requires an older version of Go, which disables pattern routing.
+//go:debug httpmuxgo121=0
+
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+)
+
+func TestPatternRouting(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(r.PathValue("id")))
+	}
+
+	t.Run("ServeMux", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		mux := NewServeMux()
+		mux.HandleFunc("GET /items/{id}", handler)
+
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest("GET", "/items/42", nil))
+		assert.Equal(t, "42", w.Body.String())
+
+		spans := mt.FinishedSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "GET /items/{id}", spans[0].Tag(ext.ResourceName))
+		assert.Equal(t, "/items/{id}", spans[0].Tag(ext.HTTPRoute))
+	})
+
+	t.Run("WrapHandler", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /items/{id}", handler)
+
+		w := httptest.NewRecorder()
+		WrapHandler(mux, "service", "").ServeHTTP(w, httptest.NewRequest("GET", "/items/42", nil))
+		assert.Equal(t, "42", w.Body.String())
+
+		spans := mt.FinishedSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "GET /items/{id}", spans[0].Tag(ext.ResourceName))
+		assert.Equal(t, "/items/{id}", spans[0].Tag(ext.HTTPRoute))
+	})
+
+	t.Run("resource", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+		mux := http.NewServeMux()
+		mux.HandleFunc("GET /items/{id}", handler)
+
+		WrapHandler(mux, "service", "items").ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/items/42", nil))
+
+		spans := mt.FinishedSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "items", spans[0].Tag(ext.ResourceName))
+	})
+}
@@ -0,0 +1,25 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package http
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatternRoute(t *testing.T) {
+	for pattern, route := range map[string]string{
+		"":                            "",
+		"/":                           "/",
+		"/items/":                     "/items/",
+		"example.com/items":           "example.com/items",
+		"GET /items/{id}":             "/items/{id}",
+		"POST  example.com/{path...}": "example.com/{path...}",
+	} {
+		assert.Equal(t, route, patternRoute(pattern), pattern)
+	}
+}
@@ -9,10 +9,12 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
@@ -63,6 +65,8 @@ func TestRoundTripper(t *testing.T) {
 	assert.Equal(t, "http.request", s1.OperationName())
 	assert.Equal(t, "http.request", s1.Tag(ext.ResourceName))
 	assert.Equal(t, "200", s1.Tag(ext.HTTPCode))
+	assert.Equal(t, ext.SpanKindClient, s1.Tag(ext.SpanKind))
+	assert.Equal(t, "net/http", s1.Tag(ext.Component))
 	assert.Equal(t, "GET", s1.Tag(ext.HTTPMethod))
 	assert.Equal(t, "/hello/world", s1.Tag(ext.HTTPURL))
 	assert.Equal(t, true, s1.Tag("CalledBefore"))
@@ -119,6 +123,34 @@ func TestRoundTripperServerError(t *testing.T) {
 	assert.Equal(t, true, s1.Tag("CalledAfter"))
 }
 
+func TestRoundTripperRateLimited(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "30")
+		w.Header().Set("X-RateLimit-Limit", "10")
+		w.Header().Set("X-RateLimit-Remaining", "0")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	s := httptest.NewServer(WrapHandler(h, "server", "resource"))
+	defer s.Close()
+
+	client := WrapClient(&http.Client{})
+	resp, err := client.Get(s.URL + "/hello/world")
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	spans := mt.FinishedSpans()
+	assert.Len(t, spans, 2)
+	for _, s := range spans {
+		assert.Equal(t, "429", s.Tag(ext.HTTPCode))
+		assert.Equal(t, "30", s.Tag("http.retry_after"))
+		assert.Equal(t, "10", s.Tag("http.ratelimit.limit"))
+		assert.Equal(t, "0", s.Tag("http.ratelimit.remaining"))
+	}
+}
+
 func TestRoundTripperNetworkError(t *testing.T) {
 	mt := mocktracer.Start()
 	defer mt.Stop()
@@ -323,3 +355,65 @@ func TestSpanOptions(t *testing.T) {
 	assert.Len(t, spans, 1)
 	assert.Equal(t, tagValue, spans[0].Tag(tagKey))
 }
+
+func TestIgnoreRequests(t *testing.T) {
+	var traced bool
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := tracer.Extract(tracer.HTTPHeadersCarrier(r.Header))
+		traced = err == nil
+		w.Write([]byte("Hello World"))
+	}))
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	require.NoError(t, err)
+
+	for name, tt := range map[string]struct {
+		opts   []RoundTripperOption
+		traced bool
+	}{
+		"none":          {traced: true},
+		"hostname":      {opts: []RoundTripperOption{WithIgnoreHosts("169.254.169.254", "127.0.0.1")}},
+		"host-port":     {opts: []RoundTripperOption{WithIgnoreHosts(u.Host)}},
+		"other-port":    {opts: []RoundTripperOption{WithIgnoreHosts("127.0.0.1:1")}, traced: true},
+		"other-host":    {opts: []RoundTripperOption{WithIgnoreHosts("localhost")}, traced: true},
+		"domain":        {opts: []RoundTripperOption{WithIgnoreHosts(".0.0.1")}},
+		"filter-out":    {opts: []RoundTripperOption{WithRequestFilter(func(*http.Request) bool { return false })}},
+		"filter-in":     {opts: []RoundTripperOption{WithRequestFilter(func(*http.Request) bool { return true })}, traced: true},
+		"filter-hosts":  {opts: []RoundTripperOption{WithIgnoreHosts("127.0.0.1"), WithRequestFilter(func(*http.Request) bool { return true })}},
+		"filter-method": {opts: []RoundTripperOption{WithRequestFilter(func(r *http.Request) bool { return r.Method != http.MethodGet })}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mt := mocktracer.Start()
+			defer mt.Stop()
+			traced = false
+
+			client := &http.Client{Transport: WrapRoundTripper(http.DefaultTransport, tt.opts...)}
+			resp, err := client.Get(s.URL + "/hello/world")
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			assert.Equal(t, tt.traced, traced)
+			if tt.traced {
+				assert.Len(t, mt.FinishedSpans(), 1)
+			} else {
+				assert.Len(t, mt.FinishedSpans(), 0)
+			}
+		})
+	}
+}
+
+func TestIgnoreHostsDomains(t *testing.T) {
+	cfg := newRoundTripperConfig()
+	WithIgnoreHosts(".Internal.example.com", "metadata.google.internal")(cfg)
+	for host, want := range map[string]bool{
+		"api.internal.example.com":      true,
+		"a.b.internal.example.com:8080": true,
+		"internal.example.com":          false,
+		"example.com":                   false,
+		"METADATA.google.internal":      true,
+		"google.internal":               false,
+	} {
+		req := httptest.NewRequest("GET", "http://"+host+"/", nil)
+		assert.Equal(t, want, cfg.ignored(req), host)
+	}
+}
@@ -8,7 +8,11 @@ package http // import "github.com/codebrick-corp/dd-trace-go/contrib/net/http"
 //go:generate sh -c "go run make_responsewriter.go | gofmt > trace_gen.go"
 
 import (
+	"io"
 	"net/http"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
@@ -18,6 +22,15 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/internal/appsec/dyngo/instrumentation/httpsec"
 )
 
+const (
+	// tagTimeToFirstByte holds the time, in nanoseconds, elapsed between the start of a
+	// streamed response and the moment its headers were written.
+	tagTimeToFirstByte = "http.response.time_to_first_byte"
+	// tagStreamDuration holds the time, in nanoseconds, during which a streamed response
+	// was written after its headers.
+	tagStreamDuration = "http.response.stream_duration"
+)
+
 // ServeConfig specifies the tracing configuration when using TraceAndServe.
 type ServeConfig struct {
 	// Service specifies the service name to use. If left blank, the global service name
@@ -37,6 +50,24 @@ type ServeConfig struct {
 	FinishOpts []ddtrace.FinishOption
 	// SpanOpts specifies any options to be applied to the request starting span.
 	SpanOpts []ddtrace.StartSpanOption
+	// TraceIDHeader should be true in order to echo the trace ID of the request in the
+	// X-Datadog-Trace-Id response header.
+	TraceIDHeader bool
+	// ServerTiming should be true in order to add the trace context of the request to the
+	// Server-Timing response header, as a traceparent entry read by the RUM SDKs.
+	ServerTiming bool
+	// QueryObfuscation optionally replaces the regexp redacting the query string appended to the
+	// "http.url" tag when QueryParams is set, as configured with DD_TRACE_OBFUSCATION_QUERY_STRING_REGEXP
+	// or by Middleware.
+	QueryObfuscation *regexp.Regexp
+	// NoQueryObfuscation should be true in order to record the query string without redacting it.
+	// It takes precedence over QueryObfuscation.
+	NoQueryObfuscation bool
+	// Middleware optionally specifies the middleware tracing the request, as returned by
+	// httptrace.NewMiddleware for the integrations serving their requests with TraceAndServe.
+	// It is reported when the request is traced by another middleware as well, and its query
+	// string obfuscation applies unless overridden by QueryObfuscation or NoQueryObfuscation.
+	Middleware *httptrace.Middleware
 }
 
 // TraceAndServe serves the handler h using the given ResponseWriter and Request, applying tracing
@@ -47,30 +78,73 @@ func TraceAndServe(h http.Handler, w http.ResponseWriter, r *http.Request, cfg *
 	}
 	opts := append(cfg.SpanOpts, tracer.ServiceName(cfg.Service), tracer.ResourceName(cfg.Resource))
 	if cfg.QueryParams {
-		opts = append(opts, tracer.Tag(ext.HTTPURL, r.URL.Path+"?"+r.URL.RawQuery))
+		opts = append(opts, httptrace.WithQueryString())
+	}
+	if cfg.NoQueryObfuscation {
+		opts = append(opts, httptrace.WithQueryObfuscation(nil))
+	} else if cfg.QueryObfuscation != nil {
+		opts = append(opts, httptrace.WithQueryObfuscation(cfg.QueryObfuscation))
 	}
 	opts = append(opts, tracer.Tag(ext.HTTPRoute, cfg.Route))
-	span, ctx := httptrace.StartRequestSpan(r, opts...)
+	span, ctx := cfg.Middleware.StartRequestSpan(r, opts...)
+	if cfg.TraceIDHeader {
+		httptrace.SetTraceIDHeader(w.Header(), span)
+	}
+	if cfg.ServerTiming {
+		httptrace.SetServerTimingHeader(w.Header(), span)
+	}
 	rw, ddrw := wrapResponseWriter(w)
+	// an http.ServeMux serving the request stores the matched pattern in it
+	rr := r.WithContext(ctx)
+	var body *httptrace.CountingBody
+	if r.ContentLength < 0 && r.Body != nil {
+		// the length of the body isn't known in advance
+		body = httptrace.CountBody(r.Body)
+		rr.Body = body
+	}
 	defer func() {
-		httptrace.FinishRequestSpan(span, ddrw.status, cfg.FinishOpts...)
+		if cfg.Resource == "" {
+			if pattern := requestPattern(rr); pattern != "" {
+				route := patternRoute(pattern)
+				span.SetTag(ext.ResourceName, r.Method+" "+route)
+				span.SetTag(ext.HTTPRoute, route)
+			}
+		}
+		if ddrw.status == http.StatusTooManyRequests {
+			httptrace.SetRateLimitTags(span, w.Header())
+		}
+		resp := &httptrace.Response{Header: w.Header(), Size: ddrw.written}
+		if body != nil {
+			resp.RequestSize = body.BytesRead()
+		}
+		if ddrw.streaming {
+			span.SetTag(tagTimeToFirstByte, ddrw.firstByte.Sub(ddrw.start).Nanoseconds())
+			span.SetTag(tagStreamDuration, time.Since(ddrw.firstByte).Nanoseconds())
+		}
+		httptrace.SetStreamError(span, rr, ddrw.err)
+		httptrace.FinishRequestSpan(span, ddrw.status, resp, cfg.FinishOpts...)
 	}()
 
 	if appsec.Enabled() {
 		h = httpsec.WrapHandler(h, span, cfg.RouteParams)
 	}
-	h.ServeHTTP(rw, r.WithContext(ctx))
+	h.ServeHTTP(rw, rr)
 }
 
 // responseWriter is a small wrapper around an http response writer that will
 // intercept and store the status of a request.
 type responseWriter struct {
 	http.ResponseWriter
-	status int
+	status    int
+	start     time.Time // time at which the response writer was created
+	firstByte time.Time // time at which the response headers were written
+	streaming bool      // reports whether the response is streamed to the client
+	err       error     // first error returned when writing the response body
+	written   int64     // number of bytes of the response body written
 }
 
 func newResponseWriter(w http.ResponseWriter) *responseWriter {
-	return &responseWriter{w, 0}
+	return &responseWriter{ResponseWriter: w, start: time.Now()}
 }
 
 // Status returns the status code that was monitored.
@@ -85,7 +159,12 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 	if w.status == 0 {
 		w.WriteHeader(http.StatusOK)
 	}
-	return w.ResponseWriter.Write(b)
+	n, err := w.ResponseWriter.Write(b)
+	w.written += int64(n)
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+	return n, err
 }
 
 // WriteHeader sends an HTTP response header with status code.
@@ -96,4 +175,46 @@ func (w *responseWriter) WriteHeader(status int) {
 	}
 	w.ResponseWriter.WriteHeader(status)
 	w.status = status
+	w.firstByte = time.Now()
+	if strings.HasPrefix(w.Header().Get("Content-Type"), "text/event-stream") {
+		w.streaming = true
+	}
 }
+
+// flusher returns an http.Flusher which records that the response is streamed
+// before flushing it with f.
+func (w *responseWriter) flusher(f http.Flusher) http.Flusher {
+	return flusherFunc(func() {
+		if w.status == 0 {
+			// the headers are written by the first flush
+			w.WriteHeader(http.StatusOK)
+		}
+		w.streaming = true
+		f.Flush()
+	})
+}
+
+// readerFrom returns an io.ReaderFrom which records the status of the response
+// before writing it with rf, and the number of bytes written.
+func (w *responseWriter) readerFrom(rf io.ReaderFrom) io.ReaderFrom {
+	return readerFromFunc(func(r io.Reader) (int64, error) {
+		if w.status == 0 {
+			w.WriteHeader(http.StatusOK)
+		}
+		n, err := rf.ReadFrom(r)
+		w.written += n
+		return n, err
+	})
+}
+
+// flusherFunc implements http.Flusher by calling itself.
+type flusherFunc func()
+
+// Flush implements http.Flusher.
+func (f flusherFunc) Flush() { f() }
+
+// readerFromFunc implements io.ReaderFrom by calling itself.
+type readerFromFunc func(io.Reader) (int64, error)
+
+// ReadFrom implements io.ReaderFrom.
+func (f readerFromFunc) ReadFrom(r io.Reader) (int64, error) { return f(r) }
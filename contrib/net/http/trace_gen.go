@@ -7,11 +7,14 @@
 
 package http
 
-import "net/http"
+import (
+	"io"
+	"net/http"
+)
 
 // wrapResponseWriter wraps an underlying http.ResponseWriter so that it can
 // trace the http response codes. It also checks for various http interfaces
-// (Flusher, Pusher, CloseNotifier, Hijacker) and if the underlying
+// (Flusher, Pusher, CloseNotifier, Hijacker, ReaderFrom) and if the underlying
 // http.ResponseWriter implements them it generates an unnamed struct with the
 // appropriate fields.
 //
@@ -22,6 +25,7 @@ func wrapResponseWriter(w http.ResponseWriter) (http.ResponseWriter, *responseWr
 	hPusher, okPusher := w.(http.Pusher)
 	hCloseNotifier, okCloseNotifier := w.(http.CloseNotifier)
 	hHijacker, okHijacker := w.(http.Hijacker)
+	hReaderFrom, okReaderFrom := w.(io.ReaderFrom)
 
 	mw := newResponseWriter(w)
 	type monitoredResponseWriter interface {
@@ -29,6 +33,15 @@ func wrapResponseWriter(w http.ResponseWriter) (http.ResponseWriter, *responseWr
 		Status() int
 	}
 	switch {
+	case okFlusher && okPusher && okCloseNotifier && okHijacker && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.Flusher
+			http.Pusher
+			http.CloseNotifier
+			http.Hijacker
+			io.ReaderFrom
+		}{mw, mw.flusher(hFlusher), hPusher, hCloseNotifier, hHijacker, mw.readerFrom(hReaderFrom)}
 	case okFlusher && okPusher && okCloseNotifier && okHijacker:
 		w = struct {
 			monitoredResponseWriter
@@ -36,28 +49,81 @@ func wrapResponseWriter(w http.ResponseWriter) (http.ResponseWriter, *responseWr
 			http.Pusher
 			http.CloseNotifier
 			http.Hijacker
-		}{mw, hFlusher, hPusher, hCloseNotifier, hHijacker}
+		}{mw, mw.flusher(hFlusher), hPusher, hCloseNotifier, hHijacker}
+	case okFlusher && okPusher && okCloseNotifier && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.Flusher
+			http.Pusher
+			http.CloseNotifier
+			io.ReaderFrom
+		}{mw, mw.flusher(hFlusher), hPusher, hCloseNotifier, mw.readerFrom(hReaderFrom)}
+	case okFlusher && okPusher && okHijacker && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.Flusher
+			http.Pusher
+			http.Hijacker
+			io.ReaderFrom
+		}{mw, mw.flusher(hFlusher), hPusher, hHijacker, mw.readerFrom(hReaderFrom)}
+	case okFlusher && okCloseNotifier && okHijacker && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.Flusher
+			http.CloseNotifier
+			http.Hijacker
+			io.ReaderFrom
+		}{mw, mw.flusher(hFlusher), hCloseNotifier, hHijacker, mw.readerFrom(hReaderFrom)}
+	case okPusher && okCloseNotifier && okHijacker && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.Pusher
+			http.CloseNotifier
+			http.Hijacker
+			io.ReaderFrom
+		}{mw, hPusher, hCloseNotifier, hHijacker, mw.readerFrom(hReaderFrom)}
 	case okFlusher && okPusher && okCloseNotifier:
 		w = struct {
 			monitoredResponseWriter
 			http.Flusher
 			http.Pusher
 			http.CloseNotifier
-		}{mw, hFlusher, hPusher, hCloseNotifier}
+		}{mw, mw.flusher(hFlusher), hPusher, hCloseNotifier}
 	case okFlusher && okPusher && okHijacker:
 		w = struct {
 			monitoredResponseWriter
 			http.Flusher
 			http.Pusher
 			http.Hijacker
-		}{mw, hFlusher, hPusher, hHijacker}
+		}{mw, mw.flusher(hFlusher), hPusher, hHijacker}
+	case okFlusher && okPusher && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.Flusher
+			http.Pusher
+			io.ReaderFrom
+		}{mw, mw.flusher(hFlusher), hPusher, mw.readerFrom(hReaderFrom)}
 	case okFlusher && okCloseNotifier && okHijacker:
 		w = struct {
 			monitoredResponseWriter
 			http.Flusher
 			http.CloseNotifier
 			http.Hijacker
-		}{mw, hFlusher, hCloseNotifier, hHijacker}
+		}{mw, mw.flusher(hFlusher), hCloseNotifier, hHijacker}
+	case okFlusher && okCloseNotifier && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.Flusher
+			http.CloseNotifier
+			io.ReaderFrom
+		}{mw, mw.flusher(hFlusher), hCloseNotifier, mw.readerFrom(hReaderFrom)}
+	case okFlusher && okHijacker && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+		}{mw, mw.flusher(hFlusher), hHijacker, mw.readerFrom(hReaderFrom)}
 	case okPusher && okCloseNotifier && okHijacker:
 		w = struct {
 			monitoredResponseWriter
@@ -65,24 +131,51 @@ func wrapResponseWriter(w http.ResponseWriter) (http.ResponseWriter, *responseWr
 			http.CloseNotifier
 			http.Hijacker
 		}{mw, hPusher, hCloseNotifier, hHijacker}
+	case okPusher && okCloseNotifier && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.Pusher
+			http.CloseNotifier
+			io.ReaderFrom
+		}{mw, hPusher, hCloseNotifier, mw.readerFrom(hReaderFrom)}
+	case okPusher && okHijacker && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.Pusher
+			http.Hijacker
+			io.ReaderFrom
+		}{mw, hPusher, hHijacker, mw.readerFrom(hReaderFrom)}
+	case okCloseNotifier && okHijacker && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.CloseNotifier
+			http.Hijacker
+			io.ReaderFrom
+		}{mw, hCloseNotifier, hHijacker, mw.readerFrom(hReaderFrom)}
 	case okFlusher && okPusher:
 		w = struct {
 			monitoredResponseWriter
 			http.Flusher
 			http.Pusher
-		}{mw, hFlusher, hPusher}
+		}{mw, mw.flusher(hFlusher), hPusher}
 	case okFlusher && okCloseNotifier:
 		w = struct {
 			monitoredResponseWriter
 			http.Flusher
 			http.CloseNotifier
-		}{mw, hFlusher, hCloseNotifier}
+		}{mw, mw.flusher(hFlusher), hCloseNotifier}
 	case okFlusher && okHijacker:
 		w = struct {
 			monitoredResponseWriter
 			http.Flusher
 			http.Hijacker
-		}{mw, hFlusher, hHijacker}
+		}{mw, mw.flusher(hFlusher), hHijacker}
+	case okFlusher && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.Flusher
+			io.ReaderFrom
+		}{mw, mw.flusher(hFlusher), mw.readerFrom(hReaderFrom)}
 	case okPusher && okCloseNotifier:
 		w = struct {
 			monitoredResponseWriter
@@ -95,17 +188,35 @@ func wrapResponseWriter(w http.ResponseWriter) (http.ResponseWriter, *responseWr
 			http.Pusher
 			http.Hijacker
 		}{mw, hPusher, hHijacker}
+	case okPusher && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.Pusher
+			io.ReaderFrom
+		}{mw, hPusher, mw.readerFrom(hReaderFrom)}
 	case okCloseNotifier && okHijacker:
 		w = struct {
 			monitoredResponseWriter
 			http.CloseNotifier
 			http.Hijacker
 		}{mw, hCloseNotifier, hHijacker}
+	case okCloseNotifier && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.CloseNotifier
+			io.ReaderFrom
+		}{mw, hCloseNotifier, mw.readerFrom(hReaderFrom)}
+	case okHijacker && okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			http.Hijacker
+			io.ReaderFrom
+		}{mw, hHijacker, mw.readerFrom(hReaderFrom)}
 	case okFlusher:
 		w = struct {
 			monitoredResponseWriter
 			http.Flusher
-		}{mw, hFlusher}
+		}{mw, mw.flusher(hFlusher)}
 	case okPusher:
 		w = struct {
 			monitoredResponseWriter
@@ -121,6 +232,11 @@ func wrapResponseWriter(w http.ResponseWriter) (http.ResponseWriter, *responseWr
 			monitoredResponseWriter
 			http.Hijacker
 		}{mw, hHijacker}
+	case okReaderFrom:
+		w = struct {
+			monitoredResponseWriter
+			io.ReaderFrom
+		}{mw, mw.readerFrom(hReaderFrom)}
 	default:
 		w = mw
 	}
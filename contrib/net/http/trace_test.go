@@ -6,13 +6,22 @@
 package http
 
 import (
+	"crypto/tls"
 	"fmt"
+	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
@@ -111,7 +120,56 @@ func TestTraceAndServe(t *testing.T) {
 
 		assert.True(called)
 		assert.Len(spans, 1)
-		assert.Equal("/path?token=value&id=1", spans[0].Tag(ext.HTTPURL))
+		assert.Equal("/path?<redacted>&id=1", spans[0].Tag(ext.HTTPURL))
+	})
+
+	t.Run("content-length", func(t *testing.T) {
+		mt := mocktracer.Start()
+		assert := assert.New(t)
+		defer mt.Stop()
+
+		handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			b, _ := ioutil.ReadAll(r.Body)
+			w.Write(b)
+			w.Write([]byte("!"))
+		})
+		r := httptest.NewRequest("POST", "/path", strings.NewReader("hello"))
+		TraceAndServe(handler, httptest.NewRecorder(), r, nil)
+		// the length of chunked bodies is counted as they are read
+		r = httptest.NewRequest("POST", "/path", ioutil.NopCloser(strings.NewReader("hello, world")))
+		r.ContentLength = -1
+		TraceAndServe(handler, httptest.NewRecorder(), r, nil)
+		spans := mt.FinishedSpans()
+
+		assert.Len(spans, 2)
+		assert.Equal(int64(5), spans[0].Tag("http.request.content_length"))
+		assert.Equal(int64(6), spans[0].Tag("http.response.content_length"))
+		assert.Equal(int64(12), spans[1].Tag("http.request.content_length"))
+		assert.Equal(int64(13), spans[1].Tag("http.response.content_length"))
+	})
+
+	t.Run("query-params-obfuscation", func(t *testing.T) {
+		mt := mocktracer.Start()
+		assert := assert.New(t)
+		defer mt.Stop()
+
+		w := httptest.NewRecorder()
+		r, err := http.NewRequest("GET", "/path?token=value&id=1", nil)
+		assert.NoError(err)
+		TraceAndServe(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), w, r, &ServeConfig{
+			QueryParams:      true,
+			QueryObfuscation: regexp.MustCompile("id=[^&]+"),
+		})
+		TraceAndServe(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), w, r, &ServeConfig{
+			QueryParams:        true,
+			QueryObfuscation:   regexp.MustCompile("id=[^&]+"),
+			NoQueryObfuscation: true,
+		})
+		spans := mt.FinishedSpans()
+
+		assert.Len(spans, 2)
+		assert.Equal("/path?token=value&<redacted>", spans[0].Tag(ext.HTTPURL))
+		assert.Equal("/path?token=value&id=1", spans[1].Tag(ext.HTTPURL))
 	})
 
 	t.Run("Hijacker,Flusher,CloseNotifier", func(t *testing.T) {
@@ -164,6 +222,67 @@ func TestTraceAndServe(t *testing.T) {
 		assert.True(t, ok)
 	})
 
+	t.Run("ReaderFrom", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			rf, ok := w.(io.ReaderFrom)
+			assert.True(t, ok)
+			rf.ReadFrom(strings.NewReader("Hello, world!\n"))
+		}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			TraceAndServe(http.HandlerFunc(handler), w, r, &ServeConfig{Service: "service"})
+		}))
+		defer srv.Close()
+
+		res, err := srv.Client().Get(srv.URL)
+		assert.NoError(t, err)
+		slurp, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, "Hello, world!\n", string(slurp))
+
+		spans := mt.FinishedSpans()
+		assert.Len(t, spans, 1)
+		assert.Equal(t, "200", spans[0].Tag(ext.HTTPCode))
+		assert.Nil(t, spans[0].Tag(tagStreamDuration))
+	})
+
+	t.Run("streaming", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		handler := func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			time.Sleep(10 * time.Millisecond)
+			w.(http.Flusher).Flush()
+			for i := 0; i < 2; i++ {
+				time.Sleep(10 * time.Millisecond)
+				fmt.Fprintf(w, "data: %d\n\n", i)
+				w.(http.Flusher).Flush()
+			}
+		}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			TraceAndServe(http.HandlerFunc(handler), w, r, &ServeConfig{Service: "service"})
+		}))
+		defer srv.Close()
+
+		res, err := srv.Client().Get(srv.URL)
+		assert.NoError(t, err)
+		slurp, err := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, "data: 0\n\ndata: 1\n\n", string(slurp))
+
+		spans := mt.FinishedSpans()
+		assert.Len(t, spans, 1)
+		s := spans[0]
+		assert.Equal(t, "200", s.Tag(ext.HTTPCode))
+		assert.GreaterOrEqual(t, s.Tag(tagTimeToFirstByte), (10 * time.Millisecond).Nanoseconds())
+		assert.GreaterOrEqual(t, s.Tag(tagStreamDuration), (20 * time.Millisecond).Nanoseconds())
+	})
+
 	t.Run("distributed", func(t *testing.T) {
 		mt := mocktracer.Start()
 		assert := assert.New(t)
@@ -328,6 +447,35 @@ func TestTraceAndServeHost(t *testing.T) {
 	})
 }
 
+func TestTraceAndServeH2C(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	})
+	srv := httptest.NewServer(h2c.NewHandler(WrapHandler(handler, "service", "resource"), &http2.Server{}))
+	defer srv.Close()
+
+	// an HTTP/2 client using prior knowledge over cleartext
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+			return net.Dial(network, addr)
+		},
+	}}
+	resp, err := client.Get(srv.URL + "/h2c")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, 2, resp.ProtoMajor)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "2", spans[0].Tag(ext.HTTPVersion))
+	assert.Equal(t, "200", spans[0].Tag(ext.HTTPCode))
+	assert.Nil(t, spans[0].Tag(ext.HTTPStreamError))
+}
+
 type noopHandler struct{}
 
 func (noopHandler) ServeHTTP(_ http.ResponseWriter, _ *http.Request) {}
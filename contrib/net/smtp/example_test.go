@@ -0,0 +1,31 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package smtp_test
+
+import (
+	"context"
+	"log"
+	"net/smtp"
+
+	smtptrace "github.com/codebrick-corp/dd-trace-go/contrib/net/smtp"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func Example() {
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "web.request")
+	defer span.Finish()
+
+	auth := smtp.PlainAuth("", "user@example.com", "password", "mail.example.com")
+	msg := []byte("To: recipient@example.net\r\n" +
+		"Subject: discount Gophers!\r\n" +
+		"\r\n" +
+		"This is the email body.\r\n")
+	// The delivery attempt is traced as a child of the span in ctx.
+	err := smtptrace.SendMail(ctx, "mail.example.com:25", auth, "sender@example.org", []string{"recipient@example.net"}, msg)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
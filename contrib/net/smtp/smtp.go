@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package smtp provides functions to trace the net/smtp package (https://golang.org/pkg/net/smtp).
+//
+// The delivery attempts are tagged with the domains of their recipients, never with
+// their addresses, and with the size of their message.
+package smtp // import "github.com/codebrick-corp/dd-trace-go/contrib/net/smtp"
+
+import (
+	"context"
+	"net/smtp"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/mailtrace"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+)
+
+const componentName = "net/smtp"
+
+func init() {
+	integrations.Register(componentName, "net/smtp")
+}
+
+// SendMail calls smtp.SendMail, tracing the delivery attempt as a child of the span in ctx.
+func SendMail(ctx context.Context, addr string, a smtp.Auth, from string, to []string, msg []byte, opts ...Option) error {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	span := mailtrace.StartSpan(ctx, &mailtrace.Config{
+		ServiceName: cfg.serviceName,
+		Addr:        addr,
+		Component:   componentName,
+	}, "SendMail", to)
+	err := smtp.SendMail(addr, a, from, to, msg)
+	mailtrace.FinishSpan(span, int64(len(msg)), err)
+	return err
+}
@@ -0,0 +1,152 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package smtp
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/mailtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+// startServer starts a fake SMTP server accepting all the recipients but the ones
+// of the domain "blocked.org". It returns its address.
+func startServer(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go serveSMTP(textproto.NewConn(c))
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func serveSMTP(c *textproto.Conn) {
+	defer c.Close()
+	c.PrintfLine("220 localhost ESMTP")
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			return
+		}
+		switch cmd := strings.ToUpper(strings.Fields(line + " ")[0]); cmd {
+		case "EHLO", "HELO":
+			c.PrintfLine("250 localhost")
+		case "MAIL":
+			c.PrintfLine("250 OK")
+		case "RCPT":
+			if strings.Contains(line, "@blocked.org") {
+				c.PrintfLine("550 mailbox unavailable")
+				continue
+			}
+			c.PrintfLine("250 OK")
+		case "DATA":
+			c.PrintfLine("354 go ahead")
+			if _, err := c.ReadDotBytes(); err != nil {
+				return
+			}
+			c.PrintfLine("250 OK")
+		case "QUIT":
+			c.PrintfLine("221 bye")
+			return
+		default:
+			c.PrintfLine("502 unknown command")
+		}
+	}
+}
+
+var msg = []byte("Subject: hello\r\n\r\nHello world!\r\n")
+
+func TestSendMail(t *testing.T) {
+	assert := assert.New(t)
+	addr := startServer(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "http.request")
+	err := SendMail(ctx, addr, nil, "noreply@example.com", []string{"bob@example.org", "alice@Example.org"}, msg)
+	parent.Finish()
+	require.NoError(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	s := spans[0]
+	host, port, _ := net.SplitHostPort(addr)
+	assert.Equal(mailtrace.OperationName, s.OperationName())
+	assert.Equal(parent.Context().SpanID(), s.ParentID())
+	assert.Equal("SendMail", s.Tag(ext.ResourceName))
+	assert.Equal("smtp", s.Tag(ext.ServiceName))
+	assert.Equal(ext.SpanTypeSMTP, s.Tag(ext.SpanType))
+	assert.Equal(host, s.Tag(ext.TargetHost))
+	assert.Equal(port, s.Tag(ext.TargetPort))
+	assert.Equal("example.org", s.Tag(mailtrace.TagRecipientDomains))
+	assert.Equal(2, s.Tag(mailtrace.TagRecipientCount))
+	assert.Equal(int64(len(msg)), s.Tag(mailtrace.TagMessageSize))
+	assert.Nil(s.Tag(ext.Error))
+	for k, v := range s.Tags() {
+		assert.NotContains(fmt.Sprint(v), "bob@", k)
+	}
+}
+
+func TestSendMailError(t *testing.T) {
+	addr := startServer(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	err := SendMail(context.Background(), addr, nil, "noreply@example.com", []string{"eve@blocked.org"}, msg)
+	require.Error(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, err, spans[0].Tag(ext.Error))
+	assert.Equal(t, "blocked.org", spans[0].Tag(mailtrace.TagRecipientDomains))
+}
+
+func TestServiceName(t *testing.T) {
+	addr := startServer(t)
+
+	t.Run("global", func(t *testing.T) {
+		globalconfig.SetServiceName("global-service")
+		defer globalconfig.SetServiceName("")
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		require.NoError(t, SendMail(context.Background(), addr, nil, "noreply@example.com", []string{"bob@example.org"}, msg))
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "global-service", spans[0].Tag(ext.ServiceName))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		require.NoError(t, SendMail(context.Background(), addr, nil, "noreply@example.com", []string{"bob@example.org"}, msg,
+			WithServiceName("mailer")))
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "mailer", spans[0].Tag(ext.ServiceName))
+	})
+}
@@ -22,9 +22,16 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 )
 
+const componentName = "olivere/elastic"
+
+func init() {
+	integrations.Register(componentName, "gopkg.in/olivere/elastic")
+}
+
 // NewHTTPClient returns a new http.Client which traces requests under the given service name.
 func NewHTTPClient(opts ...ClientOption) *http.Client {
 	cfg := new(clientConfig)
@@ -52,10 +59,12 @@ func (t *httpTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	opts := []ddtrace.StartSpanOption{
 		tracer.ServiceName(t.config.serviceName),
 		tracer.SpanType(ext.SpanTypeElasticSearch),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ResourceName(resource),
 		tracer.Tag("elasticsearch.method", method),
 		tracer.Tag("elasticsearch.url", url),
 		tracer.Tag("elasticsearch.params", req.URL.Query().Encode()),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(t.config.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, t.config.analyticsRate))
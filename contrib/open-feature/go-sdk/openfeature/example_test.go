@@ -0,0 +1,32 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package openfeature_test
+
+import (
+	"context"
+
+	openfeaturetrace "github.com/codebrick-corp/dd-trace-go/contrib/open-feature/go-sdk/openfeature"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/open-feature/go-sdk/openfeature"
+)
+
+func Example() {
+	// Start the tracer
+	tracer.Start()
+	defer tracer.Stop()
+
+	// Record the flag evaluations of all clients on the spans of their context.
+	openfeature.AddHooks(openfeaturetrace.NewHook())
+
+	// The variant of the flag is recorded on the span as "feature_flag.new-checkout.variant".
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "checkout")
+	defer span.Finish()
+	client := openfeature.NewClient("my-app")
+	if enabled, _ := client.BooleanValue(ctx, "new-checkout", false, openfeature.EvaluationContext{}); enabled {
+		// ...
+	}
+}
@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package openfeature provides an OpenFeature hook (https://github.com/open-feature/go-sdk)
+// recording the flag evaluations on the spans of their context, so that experiments can be
+// correlated with the latency and the errors of the traced operations.
+package openfeature // import "github.com/codebrick-corp/dd-trace-go/contrib/open-feature/go-sdk/openfeature"
+
+import (
+	"context"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/open-feature/go-sdk/openfeature"
+)
+
+// componentName is the name of the integration.
+const componentName = "open-feature/go-sdk"
+
+func init() {
+	integrations.Register(componentName, "github.com/open-feature/go-sdk")
+}
+
+// tagPrefix prefixes the tags of the flag evaluations, which are named after the flag key,
+// e.g. "feature_flag.new-checkout.variant", so that a span can hold several evaluations.
+const tagPrefix = "feature_flag."
+
+// Hook is an openfeature.Hook tagging the span of the context of every flag evaluation with
+// the variant and the reason of its outcome, or with its error. The evaluations made from a
+// context holding no span are left out. Hooks are registered with openfeature.AddHooks or
+// the AddHooks method of a client.
+type Hook struct {
+	openfeature.UnimplementedHook
+}
+
+var _ openfeature.Hook = (*Hook)(nil)
+
+// NewHook returns a new Hook.
+func NewHook() *Hook {
+	log.Debug("contrib/open-feature/go-sdk/openfeature: Creating Hook")
+	return &Hook{}
+}
+
+// After implements openfeature.Hook.
+func (*Hook) After(ctx context.Context, hookContext openfeature.HookContext, details openfeature.InterfaceEvaluationDetails, _ openfeature.HookHints) error {
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	prefix := tagPrefix + hookContext.FlagKey()
+	if details.Variant != "" {
+		span.SetTag(prefix+".variant", details.Variant)
+	}
+	if details.Reason != "" {
+		span.SetTag(prefix+".reason", string(details.Reason))
+	}
+	if name := hookContext.ProviderMetadata().Name; name != "" {
+		span.SetTag(prefix+".provider_name", name)
+	}
+	return nil
+}
+
+// Error implements openfeature.Hook. The span isn't marked as an error, the evaluation
+// falling back to the default value of the flag.
+func (*Hook) Error(ctx context.Context, hookContext openfeature.HookContext, err error, _ openfeature.HookHints) {
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return
+	}
+	prefix := tagPrefix + hookContext.FlagKey()
+	span.SetTag(prefix+".error", err.Error())
+	if name := hookContext.ProviderMetadata().Name; name != "" {
+		span.SetTag(prefix+".provider_name", name)
+	}
+}
@@ -0,0 +1,92 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package openfeature
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/open-feature/go-sdk/openfeature"
+	"github.com/open-feature/go-sdk/openfeature/memprovider"
+)
+
+func newClient(t *testing.T) *openfeature.Client {
+	provider := memprovider.NewInMemoryProvider(map[string]memprovider.InMemoryFlag{
+		"new-checkout": {
+			Key:            "new-checkout",
+			State:          memprovider.Enabled,
+			DefaultVariant: "on",
+			Variants:       map[string]interface{}{"on": true, "off": false},
+		},
+		"disabled": {
+			Key:            "disabled",
+			State:          memprovider.Disabled,
+			DefaultVariant: "on",
+			Variants:       map[string]interface{}{"on": true},
+		},
+	})
+	require.NoError(t, openfeature.SetNamedProvider(t.Name(), provider))
+	client := openfeature.NewClient(t.Name())
+	client.AddHooks(NewHook())
+	return client
+}
+
+func TestHook(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	client := newClient(t)
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "http.request")
+	v, err := client.BooleanValue(ctx, "new-checkout", false, openfeature.EvaluationContext{})
+	require.NoError(t, err)
+	assert.True(t, v)
+	span.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal(t, "on", s.Tag("feature_flag.new-checkout.variant"))
+	assert.Equal(t, string(openfeature.StaticReason), s.Tag("feature_flag.new-checkout.reason"))
+	assert.Equal(t, "InMemoryProvider", s.Tag("feature_flag.new-checkout.provider_name"))
+	assert.Nil(t, s.Tag("feature_flag.new-checkout.error"))
+}
+
+func TestHookError(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	client := newClient(t)
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "http.request")
+	v, err := client.BooleanValue(ctx, "disabled", false, openfeature.EvaluationContext{})
+	require.Error(t, err)
+	assert.False(t, v)
+	span.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal(t, err.Error(), s.Tag("feature_flag.disabled.error"))
+	assert.Equal(t, "InMemoryProvider", s.Tag("feature_flag.disabled.provider_name"))
+	assert.Nil(t, s.Tag("feature_flag.disabled.variant"))
+	assert.Nil(t, s.Tag(ext.Error))
+}
+
+func TestHookNoSpan(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	client := newClient(t)
+
+	_, err := client.BooleanValue(context.Background(), "new-checkout", false, openfeature.EvaluationContext{})
+	require.NoError(t, err)
+	assert.Empty(t, mt.FinishedSpans())
+}
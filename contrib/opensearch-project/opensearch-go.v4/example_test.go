@@ -0,0 +1,41 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package opensearch_test
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	opensearchtrace "github.com/codebrick-corp/dd-trace-go/contrib/opensearch-project/opensearch-go.v4"
+
+	"github.com/opensearch-project/opensearch-go/v4"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+func Example() {
+	client, err := opensearchtrace.NewClient(opensearchapi.Config{
+		Client: opensearch.Config{Addresses: []string{"https://localhost:9200"}},
+	}, opensearchtrace.WithServiceName("my-search"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The requests of the client are now traced.
+	_, err = client.Document.Get(context.Background(), opensearchapi.DocumentGetReq{Index: "twitter", DocumentID: "1"})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleNewRoundTripper() {
+	// Any other HTTP client sending requests to OpenSearch may be traced by the
+	// http.RoundTripper of the package.
+	client := &http.Client{
+		Transport: opensearchtrace.NewRoundTripper(opensearchtrace.WithServiceName("my-search")),
+	}
+	client.Get("http://localhost:9200/twitter/_doc/1")
+}
@@ -0,0 +1,196 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package opensearch provides functions to trace the github.com/opensearch-project/opensearch-go/v4
+// package (https://github.com/opensearch-project/opensearch-go). The requests of the clients
+// returned by NewClient are traced by the http.RoundTripper returned by NewRoundTripper, which
+// can also be set as the Transport of an opensearch.Config or of any http.Client.
+package opensearch // import "github.com/codebrick-corp/dd-trace-go/contrib/opensearch-project/opensearch-go.v4"
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/searchtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+const componentName = "opensearch-project/opensearch-go.v4"
+
+func init() {
+	integrations.Register(componentName, "github.com/opensearch-project/opensearch-go/v4")
+}
+
+const (
+	// tagMethod holds the HTTP method of the request.
+	tagMethod = "opensearch.method"
+	// tagURL holds the path of the request.
+	tagURL = "opensearch.url"
+	// tagParams holds the query string of the request, with its search query obfuscated.
+	tagParams = "opensearch.params"
+	// tagIndex holds the index, alias or data stream targeted by the request.
+	tagIndex = "opensearch.index"
+	// tagBody holds the obfuscated body of the request.
+	tagBody = "opensearch.body"
+)
+
+// NewClient returns a new OpenSearch client configured with cfg, whose requests are traced.
+// The Transport of cfg, if any, is wrapped unless a transport is given with WithTransport.
+func NewClient(cfg opensearchapi.Config, opts ...ClientOption) (*opensearchapi.Client, error) {
+	transport := cfg.Client.Transport
+	if cfg.Client.CACert != nil {
+		// the client only adds the certificates to an *http.Transport, which the
+		// traced transport wraps
+		t, err := withCACert(transport, cfg.Client.CACert)
+		if err != nil {
+			return nil, err
+		}
+		transport = t
+		cfg.Client.CACert = nil
+	}
+	if transport != nil {
+		opts = append([]ClientOption{WithTransport(transport)}, opts...)
+	}
+	cfg.Client.Transport = NewRoundTripper(opts...)
+	return opensearchapi.NewClient(cfg)
+}
+
+// withCACert returns a copy of transport, which must be an *http.Transport or nil for the
+// default transport, trusting the PEM-encoded certificate authorities of cert only.
+func withCACert(transport http.RoundTripper, cert []byte) (http.RoundTripper, error) {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	t, ok := transport.(*http.Transport)
+	if !ok {
+		return nil, fmt.Errorf("unable to set CA certificate for transport of type %T", transport)
+	}
+	t = t.Clone()
+	if t.TLSClientConfig == nil {
+		t.TLSClientConfig = &tls.Config{}
+	}
+	t.TLSClientConfig.RootCAs = x509.NewCertPool()
+	if !t.TLSClientConfig.RootCAs.AppendCertsFromPEM(cert) {
+		return nil, errors.New("unable to add CA certificate")
+	}
+	return t, nil
+}
+
+// NewRoundTripper returns a new http.RoundTripper which traces the requests to OpenSearch.
+func NewRoundTripper(opts ...ClientOption) http.RoundTripper {
+	cfg := new(clientConfig)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/opensearch-project/opensearch-go.v4: Configuring RoundTripper: %#v", cfg)
+	return &roundTripper{config: *cfg}
+}
+
+// roundTripper is an implementation of http.RoundTripper that captures OpenSearch spans.
+type roundTripper struct {
+	config clientConfig
+}
+
+var _ http.RoundTripper = &roundTripper{}
+
+// RoundTrip satisfies the RoundTripper interface, wraps the sub Transport and
+// captures a span of the OpenSearch request.
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	url := req.URL.Path
+	method := req.Method
+	opts := []ddtrace.StartSpanOption{
+		tracer.ServiceName(t.config.serviceName),
+		tracer.SpanType(ext.SpanTypeOpenSearch),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.ResourceName(t.config.resourceNamer(url, method)),
+		tracer.Tag(tagMethod, method),
+		tracer.Tag(tagURL, url),
+		tracer.Tag(tagParams, params(req)),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if index := index(url); index != "" {
+		opts = append(opts, tracer.Tag(tagIndex, index))
+	}
+	span, _ := tracer.StartSpanFromContext(req.Context(), "opensearch.query", opts...)
+	defer span.Finish()
+
+	contentEncoding := req.Header.Get("Content-Encoding")
+	snip, rc, err := searchtrace.Peek(req.Body, contentEncoding, int(req.ContentLength), searchtrace.BodyCutoff)
+	if err == nil && snip != "" {
+		span.SetTag(tagBody, searchtrace.ObfuscateJSON(snip))
+	}
+	req.Body = rc
+	// process using the standard transport
+	res, err := t.config.transport.RoundTrip(req)
+	if err != nil {
+		// roundtrip error
+		span.SetTag(ext.Error, err)
+	} else if res.StatusCode < 200 || res.StatusCode > 299 {
+		// HTTP error
+		snip, rc, err := searchtrace.Peek(res.Body, res.Header.Get("Content-Encoding"), int(res.ContentLength), searchtrace.BodyCutoff)
+		if err != nil {
+			snip = http.StatusText(res.StatusCode)
+		}
+		span.SetTag(ext.Error, errors.New(snip))
+		res.Body = rc
+	}
+	if res != nil {
+		span.SetTag(ext.HTTPCode, strconv.Itoa(res.StatusCode))
+	}
+	return res, err
+}
+
+// params returns the encoded query parameters of req, with the value of the "q" parameter,
+// which holds a Lucene query, replaced by "?".
+func params(req *http.Request) string {
+	q := req.URL.Query()
+	if _, ok := q["q"]; ok {
+		q.Set("q", "?")
+	}
+	return q.Encode()
+}
+
+// index returns the index targeted by the request to path, or "" if the request does not
+// target one, as for "/_search" or "/_cluster/health".
+func index(path string) string {
+	seg := strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]
+	if seg == "" || strings.HasPrefix(seg, "_") {
+		return ""
+	}
+	return seg
+}
+
+var (
+	docRegexp        = regexp.MustCompile("/(_doc|_create|_update|_source|_explain|_termvectors)/[^/?]+")
+	docPlaceholder   = []byte("/$1/?")
+	idRegexp         = regexp.MustCompile("/([0-9]+)([/\\?]|$)")
+	idPlaceholder    = []byte("/?$2")
+	indexRegexp      = regexp.MustCompile("[0-9]{2,}")
+	indexPlaceholder = []byte("?")
+)
+
+// quantize quantizes an OpenSearch request to extract a meaningful resource from it.
+// We quantize based on the method+url with some cleanup applied to the URL. Document IDs
+// and numeric path segments are generalized as are (potential) timestamped indices, e.g.
+// "GET /logs-2021.06.01/_doc/a1b2" becomes "GET /logs-?.?.?/_doc/?".
+func quantize(url, method string) string {
+	quantizedURL := docRegexp.ReplaceAll([]byte(url), docPlaceholder)
+	quantizedURL = idRegexp.ReplaceAll(quantizedURL, idPlaceholder)
+	quantizedURL = indexRegexp.ReplaceAll(quantizedURL, indexPlaceholder)
+	return fmt.Sprintf("%s %s", method, quantizedURL)
+}
@@ -0,0 +1,199 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package opensearch
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+
+	"github.com/opensearch-project/opensearch-go/v4"
+	"github.com/opensearch-project/opensearch-go/v4/opensearchapi"
+)
+
+// newServer returns a fake OpenSearch server which echoes the bodies of the requests and
+// answers requests to missing indices with a 404.
+func newServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasPrefix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"index_not_found_exception"}`))
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if len(body) == 0 {
+			body = []byte(`{}`)
+		}
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// newClient returns a traced client of the server at url.
+func newClient(t *testing.T, url string, opts ...ClientOption) *opensearchapi.Client {
+	client, err := NewClient(opensearchapi.Config{
+		Client: opensearch.Config{Addresses: []string{url}},
+	}, opts...)
+	require.NoError(t, err)
+	return client
+}
+
+// do sends a request through the http.RoundTripper rt.
+func do(t *testing.T, ctx context.Context, rt http.RoundTripper, method, url, body string) (int, string) {
+	req, err := http.NewRequestWithContext(ctx, method, url, strings.NewReader(body))
+	require.NoError(t, err)
+	res, err := (&http.Client{Transport: rt}).Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(b)
+}
+
+func TestClient(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	client := newClient(t, newServer(t).URL, WithServiceName("my-os"))
+
+	root, ctx := tracer.StartSpanFromContext(context.Background(), "web.request")
+	body := `{"query":{"match":{"user":"alice"}}}`
+	res, err := client.Search(ctx, &opensearchapi.SearchReq{
+		Indices: []string{"logs-2021.06.01"},
+		Body:    strings.NewReader(body),
+		Params:  opensearchapi.SearchParams{Query: "user:alice", Size: opensearchapi.ToPointer(10)},
+	})
+	root.Finish()
+	require.NoError(t, err)
+	got, err := ioutil.ReadAll(res.Inspect().Response.Body)
+	require.NoError(t, err)
+	assert.Equal(body, string(got), "the body must be left intact")
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	s := spans[0]
+	assert.Equal("opensearch.query", s.OperationName())
+	assert.Equal(root.Context().SpanID(), s.ParentID())
+	assert.Equal("my-os", s.Tag(ext.ServiceName))
+	assert.Equal(ext.SpanTypeOpenSearch, s.Tag(ext.SpanType))
+	assert.Equal(ext.SpanKindClient, s.Tag(ext.SpanKind))
+	assert.Equal(componentName, s.Tag(ext.Component))
+	assert.Equal("POST /logs-?.?.?/_search", s.Tag(ext.ResourceName))
+	assert.Equal("POST", s.Tag(tagMethod))
+	assert.Equal("/logs-2021.06.01/_search", s.Tag(tagURL))
+	assert.Equal("logs-2021.06.01", s.Tag(tagIndex))
+	assert.Equal("q=%3F&size=10", s.Tag(tagParams))
+	assert.Equal(`{"query":{"match":{"user":"?"}}}`, s.Tag(tagBody))
+	assert.Equal("200", s.Tag(ext.HTTPCode))
+	assert.Nil(s.Tag(ext.Error))
+}
+
+func TestClientError(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	client := newClient(t, newServer(t).URL)
+
+	_, err := client.Document.Get(context.Background(), opensearchapi.DocumentGetReq{Index: "missing", DocumentID: "a1b2"})
+	assert.Error(err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal("opensearch.client", s.Tag(ext.ServiceName))
+	assert.Equal("GET /missing/_doc/?", s.Tag(ext.ResourceName))
+	assert.Equal("404", s.Tag(ext.HTTPCode))
+	assert.EqualError(s.Tag(ext.Error).(error), `{"error":"index_not_found_exception"}`)
+	assert.Nil(s.Tag(tagBody))
+}
+
+func TestClientTransport(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	var sent int
+	transport := roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		sent++
+		return http.DefaultTransport.RoundTrip(req)
+	})
+	client, err := NewClient(opensearchapi.Config{
+		Client: opensearch.Config{Addresses: []string{newServer(t).URL}, Transport: transport},
+	})
+	require.NoError(t, err)
+	_, err = client.Cluster.Health(context.Background(), nil)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, sent, "the transport of the config must be used")
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /_cluster/health", spans[0].Tag(ext.ResourceName))
+
+	t.Run("ca", func(t *testing.T) {
+		_, err := NewClient(opensearchapi.Config{
+			Client: opensearch.Config{Addresses: []string{"https://localhost:9200"}, Transport: transport, CACert: []byte("cert")},
+		})
+		assert.EqualError(t, err, "unable to set CA certificate for transport of type opensearch.roundTripperFunc")
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestQuantize(t *testing.T) {
+	for _, tt := range []struct{ url, method, expected string }{
+		{"/twitter/_doc/1", "GET", "GET /twitter/_doc/?"},
+		{"/twitter/_doc/a1b2-c3d4", "PUT", "PUT /twitter/_doc/?"},
+		{"/twitter/_update/abc", "POST", "POST /twitter/_update/?"},
+		{"/twitter/_source/abc", "GET", "GET /twitter/_source/?"},
+		{"/logs-2021.06.01/_search", "POST", "POST /logs-?.?.?/_search"},
+		{"/_bulk", "POST", "POST /_bulk"},
+		{"/_cluster/health", "GET", "GET /_cluster/health"},
+		{"/index/type/123/_termvectors", "GET", "GET /index/type/?/_termvectors"},
+	} {
+		assert.Equal(t, tt.expected, quantize(tt.url, tt.method), tt.url)
+	}
+}
+
+func TestIndex(t *testing.T) {
+	for url, expected := range map[string]string{
+		"/twitter/_doc/1":  "twitter",
+		"/logs-*/_search":  "logs-*",
+		"/_search":         "",
+		"/_cluster/health": "",
+		"/":                "",
+	} {
+		assert.Equal(t, expected, index(url), url)
+	}
+}
+
+func TestServiceName(t *testing.T) {
+	globalconfig.SetServiceName("global-service")
+	defer globalconfig.SetServiceName("")
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	do(t, context.Background(), NewRoundTripper(), "GET", newServer(t).URL+"/_cluster/health", "")
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "opensearch.client", spans[0].Tag(ext.ServiceName))
+}
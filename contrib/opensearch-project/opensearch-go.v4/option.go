@@ -0,0 +1,49 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package opensearch
+
+import (
+	"net/http"
+)
+
+type clientConfig struct {
+	serviceName   string
+	transport     http.RoundTripper
+	resourceNamer func(url, method string) string
+}
+
+// ClientOption represents an option that can be used when creating a client.
+type ClientOption func(*clientConfig)
+
+func defaults(cfg *clientConfig) {
+	cfg.serviceName = "opensearch.client"
+	cfg.transport = http.DefaultTransport
+	cfg.resourceNamer = quantize
+}
+
+// WithTransport sets the given transport as an http.Transport for the client.
+func WithTransport(t http.RoundTripper) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.transport = t
+	}
+}
+
+// WithServiceName sets the given service name for the client.
+func WithServiceName(name string) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.serviceName = name
+	}
+}
+
+// WithResourceNamer specifies a quantizing function which will be used to obtain a resource name for a given
+// OpenSearch request, using the request's URL and method. Note that the default quantizer obfuscates
+// IDs and indexes and by replacing it, sensitive data could possibly be exposed, unless the new quantizer
+// specifically takes care of that.
+func WithResourceNamer(namer func(url, method string) string) ClientOption {
+	return func(cfg *clientConfig) {
+		cfg.resourceNamer = namer
+	}
+}
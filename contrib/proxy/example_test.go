@@ -0,0 +1,39 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package proxy_test
+
+import (
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/proxy"
+)
+
+func ExampleWrapHandler() {
+	upstream, _ := url.Parse("http://localhost:8081")
+	rp := httputil.NewSingleHostReverseProxy(upstream)
+	http.ListenAndServe(":8080", proxy.WrapHandler(rp, proxy.WithServiceName("edge")))
+}
+
+func ExampleProcessor() {
+	p := proxy.NewProcessor(proxy.WithServiceName("edge"))
+
+	// on the request_headers message of an Envoy ext_proc stream
+	req := p.ProcessRequestHeaders(http.Header{
+		":method":    {"GET"},
+		":path":      {"/users/1"},
+		":authority": {"example.com"},
+	})
+	if req == nil {
+		return
+	}
+	// answer with a header mutation setting these headers
+	_ = req.UpstreamHeaders()
+
+	// on the response_headers message
+	req.ProcessResponseHeaders(http.Header{":status": {"200"}})
+}
@@ -0,0 +1,79 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package proxy
+
+import (
+	"net/http"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// WrapHandler wraps next, the handler forwarding requests upstream, such as the next
+// handler of a Traefik middleware plugin or an httputil.ReverseProxy. The span of each
+// request is started before forwarding it, and its context is injected into the headers
+// of the forwarded request.
+func WrapHandler(next http.Handler, opts ...Option) http.Handler {
+	var cfg config
+	defaults(&cfg)
+	for _, fn := range opts {
+		fn(&cfg)
+	}
+	log.Debug("contrib/proxy: Wrapping Handler: %#v", cfg)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if cfg.ignoreRequest(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		span, ctx := startSpan(r, &cfg)
+		r = r.Clone(ctx)
+		if err := tracer.Inject(span.Context(), tracer.HTTPHeadersCarrier(r.Header)); err != nil {
+			log.Debug("contrib/proxy: Failed to inject the span context: %v", err)
+		}
+		sw := &statusWriter{ResponseWriter: w}
+		defer func() {
+			if sw.status == http.StatusTooManyRequests {
+				httptrace.SetRateLimitTags(span, w.Header())
+			}
+			httptrace.FinishRequestSpan(span, sw.status, &httptrace.Response{Header: w.Header()}, cfg.finishOpts...)
+		}()
+		next.ServeHTTP(sw, r)
+	})
+}
+
+// statusWriter is an http.ResponseWriter recording the status of the response.
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+// WriteHeader implements http.ResponseWriter.
+func (w *statusWriter) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write implements http.ResponseWriter.
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher, so that streamed responses are forwarded as they are
+// written.
+func (w *statusWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func TestWrapHandler(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	var upstream ddtrace.SpanContext
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		upstream, err = tracer.Extract(tracer.HTTPHeadersCarrier(r.Header))
+		assert.NoError(t, err)
+		w.Header().Set("Retry-After", "10")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	h := WrapHandler(next, WithServiceName("traefik"))
+
+	r := httptest.NewRequest("GET", "/api", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.Empty(t, r.Header.Get(tracer.DefaultTraceIDHeader), "the incoming request must not be modified")
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal(t, "traefik", s.Tag(ext.ServiceName))
+	assert.Equal(t, "429", s.Tag(ext.HTTPCode))
+	assert.Equal(t, "10", s.Tag("http.retry_after"))
+	require.NotNil(t, upstream)
+	assert.Equal(t, s.SpanID(), upstream.SpanID())
+}
+
+func TestWrapHandlerIgnored(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Empty(t, r.Header.Get(tracer.DefaultTraceIDHeader))
+		w.Write([]byte("ok"))
+	})
+	h := WrapHandler(next, WithIgnoreRequest(func(*http.Request) bool { return true }))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	assert.Empty(t, mt.FinishedSpans())
+}
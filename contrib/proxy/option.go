@@ -0,0 +1,75 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package proxy
+
+import (
+	"net/http"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+type config struct {
+	serviceName   string
+	spanOpts      []ddtrace.StartSpanOption
+	finishOpts    []ddtrace.FinishOption
+	ignoreRequest func(*http.Request) bool
+	resourceNamer func(*http.Request) string
+}
+
+// Option represents an option that can be passed to NewProcessor or WrapHandler.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	cfg.serviceName = "proxy"
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+	cfg.ignoreRequest = func(_ *http.Request) bool { return false }
+	cfg.resourceNamer = func(r *http.Request) string { return r.Method }
+}
+
+// WithServiceName sets the given service name for the spans of the proxied requests.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithSpanOptions defines a set of additional ddtrace.StartSpanOption to be added
+// to spans started by the integration.
+func WithSpanOptions(opts ...ddtrace.StartSpanOption) Option {
+	return func(cfg *config) {
+		cfg.spanOpts = append(cfg.spanOpts, opts...)
+	}
+}
+
+// WithIgnoreRequest holds the function to use for determining if the
+// incoming HTTP request tracing should be skipped. Ignored requests are
+// forwarded upstream without any tracing headers.
+func WithIgnoreRequest(f func(*http.Request) bool) Option {
+	return func(cfg *config) {
+		cfg.ignoreRequest = f
+	}
+}
+
+// WithResourceNamer populates the name of a resource based on a custom function.
+// By default, the resource is the method of the request.
+func WithResourceNamer(namer func(*http.Request) string) Option {
+	return func(cfg *config) {
+		cfg.resourceNamer = namer
+	}
+}
+
+// NoDebugStack prevents stack traces from being attached to spans finishing
+// with an error. This is useful in situations where errors are frequent and
+// performance is critical.
+func NoDebugStack() Option {
+	return func(cfg *config) {
+		cfg.finishOpts = append(cfg.finishOpts, tracer.NoDebugStack())
+	}
+}
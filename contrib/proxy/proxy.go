@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package proxy provides functions to trace the requests going through proxies and
+// edge components written in Go, such as Envoy external processing (ext_proc) services
+// or Traefik middleware plugins. The request span is started at the proxy layer and
+// its context is forwarded to the upstream services, so that their spans are part of
+// the same trace.
+//
+// An Envoy ext_proc service calls Processor.ProcessRequestHeaders when it receives the
+// request_headers message of a request, answers with a header mutation setting the
+// headers returned by Request.UpstreamHeaders, and calls Request.ProcessResponseHeaders
+// when it receives the response_headers message. Traefik plugins and other proxies
+// built on net/http use WrapHandler instead.
+package proxy // import "github.com/codebrick-corp/dd-trace-go/contrib/proxy"
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+const componentName = "proxy"
+
+func init() {
+	integrations.Register(componentName, "")
+}
+
+// Pseudo-headers describing the request and the response in Envoy's ext_proc messages.
+const (
+	headerMethod    = ":method"
+	headerPath      = ":path"
+	headerAuthority = ":authority"
+	headerScheme    = ":scheme"
+	headerStatus    = ":status"
+)
+
+// Processor traces the requests processed by a proxy which sees their headers but
+// doesn't forward them itself, such as an Envoy ext_proc service. It is safe for
+// concurrent use.
+type Processor struct {
+	cfg config
+}
+
+// NewProcessor returns a Processor configured with the given options.
+func NewProcessor(opts ...Option) *Processor {
+	p := new(Processor)
+	defaults(&p.cfg)
+	for _, fn := range opts {
+		fn(&p.cfg)
+	}
+	log.Debug("contrib/proxy: Configuring Processor: %#v", p.cfg)
+	return p
+}
+
+// ProcessRequestHeaders starts the span of the request having the given headers, which
+// must hold the ":method", ":path" and ":authority" pseudo-headers. The span continues
+// the trace found in the headers, if any. It returns nil when the request is ignored
+// or when its headers don't describe a valid request.
+func (p *Processor) ProcessRequestHeaders(headers http.Header) *Request {
+	r, err := requestFromHeaders(headers)
+	if err != nil {
+		log.Debug("contrib/proxy: Not tracing request: %v", err)
+		return nil
+	}
+	if p.cfg.ignoreRequest(r) {
+		return nil
+	}
+	span, _ := startSpan(r, &p.cfg)
+	upstream := make(http.Header)
+	if err := tracer.Inject(span.Context(), tracer.HTTPHeadersCarrier(upstream)); err != nil {
+		log.Debug("contrib/proxy: Failed to inject the span context: %v", err)
+	}
+	return &Request{span: span, upstream: upstream, finishOpts: p.cfg.finishOpts}
+}
+
+// Request is a request traced by a Processor.
+type Request struct {
+	span       tracer.Span
+	upstream   http.Header
+	finishOpts []ddtrace.FinishOption
+}
+
+// Span returns the span of the request.
+func (r *Request) Span() ddtrace.Span {
+	return r.span
+}
+
+// UpstreamHeaders returns the headers which must be set on the request forwarded upstream
+// to propagate the trace.
+func (r *Request) UpstreamHeaders() http.Header {
+	return r.upstream
+}
+
+// ProcessResponseHeaders finishes the span of the request using the response status found
+// in the ":status" pseudo-header of the given response headers.
+func (r *Request) ProcessResponseHeaders(headers http.Header) {
+	status, _ := strconv.Atoi(headers.Get(headerStatus))
+	if status == http.StatusTooManyRequests {
+		httptrace.SetRateLimitTags(r.span, headers)
+	}
+	httptrace.FinishRequestSpan(r.span, status, &httptrace.Response{Header: headers}, r.finishOpts...)
+}
+
+// Finish finishes the span of the request with the given response status. It must be
+// called when the request is aborted before its response headers are received, with a
+// status of 0 if there is no response.
+func (r *Request) Finish(status int) {
+	httptrace.FinishRequestSpan(r.span, status, nil, r.finishOpts...)
+}
+
+// requestFromHeaders returns the request described by the pseudo-headers of h. The
+// pseudo-headers are not part of the headers of the returned request.
+func requestFromHeaders(h http.Header) (*http.Request, error) {
+	method := h.Get(headerMethod)
+	if method == "" {
+		return nil, errors.New("missing " + headerMethod + " header")
+	}
+	u, err := url.ParseRequestURI(h.Get(headerPath))
+	if err != nil {
+		return nil, err
+	}
+	u.Scheme = h.Get(headerScheme)
+	u.Host = h.Get(headerAuthority)
+	header := make(http.Header, len(h))
+	for k, v := range h {
+		if !strings.HasPrefix(k, ":") {
+			header[http.CanonicalHeaderKey(k)] = v
+		}
+	}
+	r := &http.Request{
+		Method:     method,
+		URL:        u,
+		Proto:      "HTTP/1.1",
+		ProtoMajor: 1,
+		ProtoMinor: 1,
+		Header:     header,
+		Host:       u.Host,
+	}
+	return r.WithContext(context.Background()), nil
+}
+
+// startSpan starts the span of the request r using the configuration cfg.
+func startSpan(r *http.Request, cfg *config) (tracer.Span, context.Context) {
+	opts := append([]ddtrace.StartSpanOption{
+		tracer.ServiceName(cfg.serviceName),
+		tracer.ResourceName(cfg.resourceNamer(r)),
+		tracer.Tag(ext.Component, componentName),
+	}, cfg.spanOpts...)
+	return httptrace.StartRequestSpan(r, opts...)
+}
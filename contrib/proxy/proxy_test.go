@@ -0,0 +1,89 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package proxy
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func TestProcessor(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	parent := tracer.StartSpan("client")
+	headers := http.Header{
+		":method":    {"GET"},
+		":path":      {"/users/1?q=2"},
+		":authority": {"example.com"},
+		":scheme":    {"https"},
+		"user-agent": {"curl"},
+	}
+	require.NoError(t, tracer.Inject(parent.Context(), tracer.HTTPHeadersCarrier(headers)))
+
+	p := NewProcessor(WithServiceName("edge"))
+	req := p.ProcessRequestHeaders(headers)
+	require.NotNil(t, req)
+	upstream := req.UpstreamHeaders()
+	req.ProcessResponseHeaders(http.Header{":status": {"503"}})
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal(t, "http.request", s.OperationName())
+	assert.Equal(t, "edge", s.Tag(ext.ServiceName))
+	assert.Equal(t, "GET", s.Tag(ext.ResourceName))
+	assert.Equal(t, "/users/1", s.Tag(ext.HTTPURL))
+	assert.Equal(t, "example.com", s.Tag("http.host"))
+	assert.Equal(t, "curl", s.Tag(ext.HTTPUserAgent))
+	assert.Equal(t, "503", s.Tag(ext.HTTPCode))
+	assert.NotNil(t, s.Tag(ext.Error))
+	assert.Equal(t, parent.Context().TraceID(), s.TraceID())
+	assert.Equal(t, parent.Context().SpanID(), s.ParentID())
+
+	// the upstream continues the trace from the span of the proxy
+	sctx, err := tracer.Extract(tracer.HTTPHeadersCarrier(upstream))
+	require.NoError(t, err)
+	assert.Equal(t, s.TraceID(), sctx.TraceID())
+	assert.Equal(t, s.SpanID(), sctx.SpanID())
+}
+
+func TestProcessorIgnored(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	p := NewProcessor(WithIgnoreRequest(func(r *http.Request) bool {
+		return r.URL.Path == "/healthz"
+	}))
+	assert.Nil(t, p.ProcessRequestHeaders(http.Header{":method": {"GET"}, ":path": {"/healthz"}}))
+	assert.Nil(t, p.ProcessRequestHeaders(http.Header{":path": {"/"}}))
+	assert.Nil(t, p.ProcessRequestHeaders(http.Header{":method": {"GET"}, ":path": {"no-slash"}}))
+	assert.Empty(t, mt.OpenSpans())
+}
+
+func TestProcessorFinish(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	p := NewProcessor(WithResourceNamer(func(r *http.Request) string {
+		return r.Method + " " + r.URL.Path
+	}))
+	req := p.ProcessRequestHeaders(http.Header{":method": {"POST"}, ":path": {"/cart"}})
+	require.NotNil(t, req)
+	req.Finish(0)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "POST /cart", spans[0].Tag(ext.ResourceName))
+	assert.Equal(t, "200", spans[0].Tag(ext.HTTPCode))
+}
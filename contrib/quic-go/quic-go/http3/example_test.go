@@ -0,0 +1,32 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package http3_test
+
+import (
+	"net/http"
+
+	http3trace "github.com/codebrick-corp/dd-trace-go/contrib/quic-go/quic-go/http3"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func Example() {
+	tracer.Start()
+	defer tracer.Stop()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("Hello World!\n"))
+	})
+
+	// Serve the traced handler over HTTP/3:
+	//
+	//	server := http3.Server{
+	//		Addr:    ":443",
+	//		Handler: http3trace.WrapHandler(mux, http3trace.WithServiceName("edge-proxy")),
+	//	}
+	//	server.ListenAndServeTLS("cert.pem", "key.pem")
+	_ = http3trace.WrapHandler(mux, http3trace.WithServiceName("edge-proxy"))
+}
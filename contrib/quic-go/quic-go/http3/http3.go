@@ -0,0 +1,58 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package http3 provides functions to trace the HTTP/3 servers of the quic-go/quic-go
+// package (https://github.com/quic-go/quic-go).
+//
+// The returned handlers are meant to be set as the Handler of an http3.Server. Request spans
+// are tagged with the version of the protocol (http.version) and, when the client resets the
+// stream or the response could not be written to it, with the stream error (http.stream.error)
+// and its HTTP/3 error code (http.stream.error_code).
+package http3 // import "github.com/codebrick-corp/dd-trace-go/contrib/quic-go/quic-go/http3"
+
+import (
+	"net/http"
+
+	httptrace "github.com/codebrick-corp/dd-trace-go/contrib/net/http"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+const componentName = "quic-go/quic-go/http3"
+
+func init() {
+	integrations.Register(componentName, "github.com/quic-go/quic-go")
+}
+
+// WrapHandler wraps the http.Handler h served by an http3.Server with tracing:
+//
+//	server := http3.Server{
+//		Addr:    ":443",
+//		Handler: http3trace.WrapHandler(mux),
+//	}
+func WrapHandler(h http.Handler, opts ...Option) http.Handler {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	spanOpts := []ddtrace.StartSpanOption{tracer.Tag(ext.Component, componentName)}
+	log.Debug("contrib/quic-go/quic-go/http3: Wrapping Handler: %#v", cfg)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resource string
+		if cfg.resourceNamer != nil {
+			resource = cfg.resourceNamer(r)
+		}
+		httptrace.TraceAndServe(h, w, r, &httptrace.ServeConfig{
+			Service:  cfg.serviceName,
+			Resource: resource,
+			Route:    r.URL.EscapedPath(),
+			SpanOpts: spanOpts,
+		})
+	})
+}
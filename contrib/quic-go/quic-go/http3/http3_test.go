@@ -0,0 +1,123 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package http3
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+// streamError mimics the errors returned by quic-go when writing to a stream reset by the peer.
+type streamError struct {
+	StreamID  int64
+	ErrorCode uint64
+	Remote    bool
+}
+
+func (e *streamError) Error() string {
+	return fmt.Sprintf("stream %d canceled by remote with error code %d", e.StreamID, e.ErrorCode)
+}
+
+// resetWriter is an http.ResponseWriter whose stream was reset by the client.
+type resetWriter struct {
+	*httptest.ResponseRecorder
+	err error
+}
+
+func (w *resetWriter) Write(_ []byte) (int, error) { return 0, w.err }
+
+// newRequest returns a request as served by an http3.Server.
+func newRequest(ctx context.Context, target string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, target, nil).WithContext(ctx)
+	r.Proto, r.ProtoMajor, r.ProtoMinor = "HTTP/3.0", 3, 0
+	return r
+}
+
+func TestWrapHandler(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Write([]byte("ok"))
+	})
+
+	t.Run("version", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		w := httptest.NewRecorder()
+		WrapHandler(ok, WithServiceName("edge")).ServeHTTP(w, newRequest(context.Background(), "/hello"))
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		s := spans[0]
+		assert.Equal(t, "http.request", s.OperationName())
+		assert.Equal(t, "edge", s.Tag(ext.ServiceName))
+		assert.Equal(t, "3", s.Tag(ext.HTTPVersion))
+		assert.Equal(t, "200", s.Tag(ext.HTTPCode))
+		assert.Equal(t, "/hello", s.Tag(ext.HTTPRoute))
+		assert.Nil(t, s.Tag(ext.HTTPStreamError))
+	})
+
+	t.Run("resource", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		namer := func(r *http.Request) string { return "GET /greetings" }
+		WrapHandler(ok, WithResourceNamer(namer)).ServeHTTP(httptest.NewRecorder(), newRequest(context.Background(), "/hello"))
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "GET /greetings", spans[0].Tag(ext.ResourceName))
+		assert.Equal(t, defaultServiceName, spans[0].Tag(ext.ServiceName))
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		w := &resetWriter{httptest.NewRecorder(), &streamError{StreamID: 4, ErrorCode: 0x10c, Remote: true}}
+		WrapHandler(ok).ServeHTTP(w, newRequest(context.Background(), "/hello"))
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		s := spans[0]
+		assert.Equal(t, "stream 4 canceled by remote with error code 268", s.Tag(ext.HTTPStreamError))
+		assert.Equal(t, uint64(0x10c), s.Tag("http.stream.error_code"))
+		assert.Nil(t, s.Tag(ext.Error))
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		h := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			// the client cancels the request while it is being handled
+			cancel()
+		})
+		WrapHandler(h).ServeHTTP(httptest.NewRecorder(), newRequest(ctx, "/hello"))
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, context.Canceled.Error(), spans[0].Tag(ext.HTTPStreamError))
+		assert.Nil(t, spans[0].Tag("http.stream.error_code"))
+	})
+}
+
+func TestIntegrationRegistered(t *testing.T) {
+	assert.Contains(t, tracer.Integrations(), tracer.Integration{
+		Name:    "quic-go/quic-go/http3",
+		Library: "github.com/quic-go/quic-go",
+	})
+}
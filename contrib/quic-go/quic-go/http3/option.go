@@ -0,0 +1,45 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package http3
+
+import (
+	"net/http"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+const defaultServiceName = "http3.server"
+
+type config struct {
+	serviceName   string
+	resourceNamer func(*http.Request) string
+}
+
+// Option represents an option that can be passed to WrapHandler.
+type Option func(*config)
+
+func defaults(cfg *config) {
+	cfg.serviceName = defaultServiceName
+	if svc := globalconfig.ServiceName(); svc != "" {
+		cfg.serviceName = svc
+	}
+}
+
+// WithServiceName sets the given service name for the served requests.
+func WithServiceName(name string) Option {
+	return func(cfg *config) {
+		cfg.serviceName = name
+	}
+}
+
+// WithResourceNamer sets a function which returns the resource name of the served requests.
+// By default, requests are named after the pattern of the http.ServeMux which matched them,
+// if any.
+func WithResourceNamer(namer func(*http.Request) string) Option {
+	return func(cfg *config) {
+		cfg.resourceNamer = namer
+	}
+}
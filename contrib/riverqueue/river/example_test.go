@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package river_test
+
+import (
+	"context"
+	"log"
+
+	rivertrace "github.com/codebrick-corp/dd-trace-go/contrib/riverqueue/river"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/riverqueue/river"
+	"github.com/riverqueue/river/riverdriver/riverpgxv5"
+	"github.com/riverqueue/river/rivertype"
+)
+
+type sendReceiptArgs struct {
+	OrderID int `json:"order_id"`
+}
+
+func (sendReceiptArgs) Kind() string { return "send_receipt" }
+
+type sendReceiptWorker struct {
+	river.WorkerDefaults[sendReceiptArgs]
+}
+
+func (w *sendReceiptWorker) Work(ctx context.Context, job *river.Job[sendReceiptArgs]) error {
+	// ctx holds the span of the job.
+	return nil
+}
+
+func Example() {
+	pool, err := pgxpool.New(context.Background(), "postgres://localhost:5432/orders")
+	if err != nil {
+		log.Fatal(err)
+	}
+	workers := river.NewWorkers()
+	river.AddWorker(workers, &sendReceiptWorker{})
+	client, err := river.NewClient(riverpgxv5.New(pool), &river.Config{
+		Middleware: []rivertype.Middleware{rivertrace.NewMiddleware(rivertrace.WithServiceName("receipts"))},
+		Queues:     map[string]river.QueueConfig{river.QueueDefault: {MaxWorkers: 10}},
+		Workers:    workers,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := client.Start(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+
+	// The span of the job continues the trace of the span inserting it.
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "checkout")
+	defer span.Finish()
+	if _, err := client.Insert(ctx, sendReceiptArgs{OrderID: 42}, nil); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,115 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package river provides functions to trace the jobs worked by github.com/riverqueue/river
+// clients (https://riverqueue.com). The middleware returned by NewMiddleware, registered on
+// the client, starts the span of each job worked by the client, and injects the context of
+// the span inserting a job, if any, into the metadata of the job, so that the span of the job
+// continues its trace:
+//
+//	client, err := river.NewClient(riverpgxv5.New(pool), &river.Config{
+//		Middleware: []rivertype.Middleware{rivertrace.NewMiddleware()},
+//		Workers:    workers,
+//	})
+package river // import "github.com/codebrick-corp/dd-trace-go/contrib/riverqueue/river"
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/jobtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/riverqueue/river/rivertype"
+)
+
+const componentName = "riverqueue/river"
+
+func init() {
+	integrations.Register(componentName, "github.com/riverqueue/river")
+}
+
+// Middleware is a middleware of a River client tracing the jobs it inserts and works.
+type Middleware struct {
+	cfg config
+}
+
+var (
+	_ rivertype.JobInsertMiddleware = (*Middleware)(nil)
+	_ rivertype.WorkerMiddleware    = (*Middleware)(nil)
+)
+
+// NewMiddleware returns a new middleware tracing the jobs inserted and worked by the River
+// client it is registered on.
+func NewMiddleware(opts ...Option) *Middleware {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/riverqueue/river: Configuring Middleware: %#v", cfg)
+	return &Middleware{cfg: *cfg}
+}
+
+// IsMiddleware implements rivertype.Middleware.
+func (*Middleware) IsMiddleware() bool { return true }
+
+// InsertMany implements rivertype.JobInsertMiddleware. It injects the context of the span
+// found in ctx, if any, into the metadata of the jobs.
+func (m *Middleware) InsertMany(ctx context.Context, manyParams []*rivertype.JobInsertParams, doInner func(context.Context) ([]*rivertype.JobInsertResult, error)) ([]*rivertype.JobInsertResult, error) {
+	if span, ok := tracer.SpanFromContext(ctx); ok {
+		for _, params := range manyParams {
+			metadata, err := injectMetadata(span, params.Metadata)
+			if err != nil {
+				log.Debug("contrib/riverqueue/river: Error injecting the span context into the metadata of a %s job: %v", params.Kind, err)
+				continue
+			}
+			params.Metadata = metadata
+		}
+	}
+	return doInner(ctx)
+}
+
+// Work implements rivertype.WorkerMiddleware. It starts the span of the attempt to work job,
+// tagged with its kind, ID, queue, attempt number and the time elapsed since it was scheduled.
+func (m *Middleware) Work(ctx context.Context, job *rivertype.JobRow, doInner func(context.Context) error) (err error) {
+	var carrier jobtrace.ArgsCarrier
+	if len(job.Metadata) > 0 {
+		if err := json.Unmarshal(job.Metadata, &carrier); err != nil {
+			log.Debug("contrib/riverqueue/river: Error reading the metadata of job %d: %v", job.ID, err)
+		}
+	}
+	span, ctx := jobtrace.StartSpan(ctx, jobtrace.Config{
+		OperationName: operationName,
+		ServiceName:   m.cfg.serviceName,
+		Component:     componentName,
+	}, jobtrace.Job{
+		Name:       job.Kind,
+		ID:         strconv.FormatInt(job.ID, 10),
+		Queue:      job.Queue,
+		Attempt:    job.Attempt,
+		EnqueuedAt: job.ScheduledAt,
+	}, carrier)
+	defer func() { span.Finish(tracer.WithError(err)) }()
+	return doInner(ctx)
+}
+
+// injectMetadata injects the context of span into metadata, the JSON metadata of a job about
+// to be inserted, which may be empty, and returns the resulting metadata.
+func injectMetadata(span tracer.Span, metadata []byte) ([]byte, error) {
+	md := make(map[string]interface{})
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &md); err != nil {
+			return nil, err
+		}
+	}
+	if err := tracer.Inject(span.Context(), jobtrace.ArgsCarrier(md)); err != nil {
+		return nil, err
+	}
+	return json.Marshal(md)
+}
@@ -6,49 +6,39 @@
 package kafka
 
 import (
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/messaging"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
 
 	"github.com/segmentio/kafka-go"
 )
 
-// A messageCarrier implements TextMapReader/TextMapWriter for extracting/injecting traces on a kafka.Message
-type messageCarrier struct {
+// messageHeaders implements messaging.Headers for a kafka.Message.
+type messageHeaders struct {
 	msg *kafka.Message
 }
 
-var _ interface {
-	tracer.TextMapReader
-	tracer.TextMapWriter
-} = (*messageCarrier)(nil)
-
-// ForeachKey conforms to the TextMapReader interface.
-func (c messageCarrier) ForeachKey(handler func(key, val string) error) error {
-	for _, h := range c.msg.Headers {
-		err := handler(h.Key, string(h.Value))
-		if err != nil {
-			return err
-		}
-	}
-	return nil
+var _ messaging.Headers = (*messageHeaders)(nil)
+
+// Len implements messaging.Headers.
+func (h messageHeaders) Len() int { return len(h.msg.Headers) }
+
+// At implements messaging.Headers.
+func (h messageHeaders) At(i int) (string, []byte) {
+	return h.msg.Headers[i].Key, h.msg.Headers[i].Value
+}
+
+// Delete implements messaging.Headers.
+func (h messageHeaders) Delete(i int) {
+	h.msg.Headers = append(h.msg.Headers[:i], h.msg.Headers[i+1:]...)
 }
 
-// Set implements TextMapWriter
-func (c messageCarrier) Set(key, val string) {
-	// ensure uniqueness of keys
-	for i := 0; i < len(c.msg.Headers); i++ {
-		if string(c.msg.Headers[i].Key) == key {
-			c.msg.Headers = append(c.msg.Headers[:i], c.msg.Headers[i+1:]...)
-			i--
-		}
-	}
-	c.msg.Headers = append(c.msg.Headers, kafka.Header{
-		Key:   key,
-		Value: []byte(val),
-	})
+// Add implements messaging.Headers.
+func (h messageHeaders) Add(key string, value []byte) {
+	h.msg.Headers = append(h.msg.Headers, kafka.Header{Key: key, Value: value})
 }
 
 // ExtractSpanContext retrieves the SpanContext from a kafka.Message
 func ExtractSpanContext(msg kafka.Message) (ddtrace.SpanContext, error) {
-	return tracer.Extract(messageCarrier{&msg})
+	return tracer.Extract(messaging.Carrier{Headers: messageHeaders{&msg}})
 }
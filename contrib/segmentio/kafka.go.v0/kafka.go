@@ -7,16 +7,23 @@ package kafka
 
 import (
 	"context"
-	"math"
 
 	"github.com/segmentio/kafka-go"
 
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/messaging"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 )
 
+const componentName = "segmentio/kafka.go.v0"
+
+func init() {
+	integrations.Register(componentName, "github.com/segmentio/kafka-go")
+}
+
 // NewReader calls kafka.NewReader and wraps the resulting Consumer.
 func NewReader(conf kafka.ReaderConfig, opts ...Option) *Reader {
 	return WrapReader(kafka.NewReader(conf), opts...)
@@ -45,27 +52,25 @@ type Reader struct {
 }
 
 func (r *Reader) startSpan(ctx context.Context, msg *kafka.Message) ddtrace.Span {
-	opts := []tracer.StartSpanOption{
-		tracer.ServiceName(r.cfg.consumerServiceName),
-		tracer.ResourceName("Consume Topic " + msg.Topic),
-		tracer.SpanType(ext.SpanTypeMessageConsumer),
-		tracer.Tag("partition", msg.Partition),
-		tracer.Tag("offset", msg.Offset),
-		tracer.Measured(),
-	}
-	if !math.IsNaN(r.cfg.analyticsRate) {
-		opts = append(opts, tracer.Tag(ext.EventSampleRate, r.cfg.analyticsRate))
-	}
-	// kafka supports headers, so try to extract a span context
-	carrier := messageCarrier{msg}
-	if spanctx, err := tracer.Extract(carrier); err == nil {
-		opts = append(opts, tracer.ChildOf(spanctx))
+	op := messaging.Operation{
+		System:        "kafka",
+		Destination:   msg.Topic,
+		ServiceName:   r.cfg.consumerServiceName,
+		AnalyticsRate: r.cfg.analyticsRate,
+		Component:     componentName,
+		Options: []ddtrace.StartSpanOption{
+			tracer.Tag("partition", msg.Partition),
+			tracer.Tag("offset", msg.Offset),
+		},
 	}
-	span, _ := tracer.StartSpanFromContext(ctx, "kafka.consume", opts...)
-	// reinject the span context so consumers can pick it up
-	if err := tracer.Inject(span.Context(), carrier); err != nil {
-		log.Debug("contrib/segmentio/kafka.go.v0: Failed to inject span context into carrier, %v", err)
+	// the lag is unknown (-1) when the reader is backed by a consumer group
+	if lag := r.Reader.Lag(); lag >= 0 {
+		op.Options = append(op.Options,
+			tracer.Tag("kafka.high_watermark", msg.Offset+lag+1),
+			tracer.Tag("kafka.lag", lag),
+		)
 	}
+	span, _ := messaging.StartConsumeSpan(ctx, op, messageHeaders{msg})
 	return span
 }
 
@@ -94,6 +99,28 @@ func (r *Reader) ReadMessage(ctx context.Context) (kafka.Message, error) {
 	return msg, nil
 }
 
+// CommitMessages calls the underlying Reader.CommitMessages and traces the request.
+func (r *Reader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	opts := []tracer.StartSpanOption{
+		tracer.ServiceName(r.cfg.consumerServiceName),
+		tracer.ResourceName("Commit Topic " + r.Reader.Config().Topic),
+		tracer.SpanType(ext.SpanTypeMessageConsumer),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.Tag("kafka.messages", len(msgs)),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if n := len(msgs); n > 0 {
+		opts = append(opts,
+			tracer.Tag("partition", msgs[n-1].Partition),
+			tracer.Tag("offset", msgs[n-1].Offset),
+		)
+	}
+	span, ctx := tracer.StartSpanFromContext(ctx, "kafka.commit", opts...)
+	err := r.Reader.CommitMessages(ctx, msgs...)
+	span.Finish(tracer.WithError(err))
+	return err
+}
+
 // WrapWriter wraps a kafka.Writer so requests are traced.
 func WrapWriter(w *kafka.Writer, opts ...Option) *Writer {
 	writer := &Writer{
@@ -111,18 +138,13 @@ type Writer struct {
 }
 
 func (w *Writer) startSpan(ctx context.Context, msg *kafka.Message) ddtrace.Span {
-	opts := []tracer.StartSpanOption{
-		tracer.ServiceName(w.cfg.producerServiceName),
-		tracer.ResourceName("Produce Topic " + w.Writer.Topic),
-		tracer.SpanType(ext.SpanTypeMessageProducer),
-	}
-	if !math.IsNaN(w.cfg.analyticsRate) {
-		opts = append(opts, tracer.Tag(ext.EventSampleRate, w.cfg.analyticsRate))
-	}
-	carrier := messageCarrier{msg}
-	span, _ := tracer.StartSpanFromContext(ctx, "kafka.produce", opts...)
-	err := tracer.Inject(span.Context(), carrier)
-	log.Debug("contrib/segmentio/kafka.go.v0: Failed to inject span context into carrier, %v", err)
+	span, _ := messaging.StartProduceSpan(ctx, messaging.Operation{
+		System:        "kafka",
+		Destination:   w.Writer.Topic,
+		ServiceName:   w.cfg.producerServiceName,
+		AnalyticsRate: w.cfg.analyticsRate,
+		Component:     componentName,
+	}, messageHeaders{msg})
 	return span
 }
 
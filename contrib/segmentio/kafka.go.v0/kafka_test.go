@@ -90,3 +90,40 @@ func TestConsumerFunctional(t *testing.T) {
 	assert.Equal(t, "queue", s1.Tag(ext.SpanType))
 	assert.Equal(t, 0, s1.Tag("partition"))
 }
+
+func TestConsumerCommit(t *testing.T) {
+	skipIntegrationTest(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	w := WrapWriter(&kafka.Writer{
+		Addr:         kafka.TCP("localhost:9092"),
+		Topic:        testTopic,
+		RequiredAcks: kafka.RequireOne,
+	})
+	err := w.WriteMessages(ctx, kafka.Message{Value: []byte("value")})
+	assert.NoError(t, err)
+	w.Close()
+
+	r := NewReader(kafka.ReaderConfig{
+		Brokers: []string{"localhost:9092"},
+		GroupID: testGroupID,
+		Topic:   testTopic,
+	})
+	msg, err := r.FetchMessage(ctx)
+	assert.NoError(t, err)
+	err = r.CommitMessages(ctx, msg)
+	assert.NoError(t, err)
+	r.Close()
+
+	spans := mt.FinishedSpans()
+	assert.Len(t, spans, 2)
+	s := spans[1] // commit
+	assert.Equal(t, "kafka.commit", s.OperationName())
+	assert.Equal(t, "Commit Topic "+testTopic, s.Tag(ext.ResourceName))
+	assert.Equal(t, 1, s.Tag("kafka.messages"))
+	assert.Equal(t, msg.Offset, s.Tag("offset"))
+	assert.Nil(t, s.Tag(ext.Error))
+}
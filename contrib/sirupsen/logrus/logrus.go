@@ -8,10 +8,15 @@ package logrus
 
 import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 
 	"github.com/sirupsen/logrus"
 )
 
+func init() {
+	integrations.Register("sirupsen/logrus", "github.com/sirupsen/logrus")
+}
+
 // DDContextLogHook ensures that any span in the log context is correlated to log output.
 type DDContextLogHook struct{}
 
@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package gobreaker provides functions to trace the sony/gobreaker package (https://github.com/sony/gobreaker).
+//
+// Every request executed through a traced circuit breaker results in a span tagged with the
+// state of the breaker. Requests rejected without being run are tagged as short-circuited, and
+// the span of the request which caused the breaker to trip records the state transition.
+package gobreaker // import "github.com/codebrick-corp/dd-trace-go/contrib/sony/gobreaker"
+
+import (
+	"context"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/sony/gobreaker"
+)
+
+const componentName = "sony/gobreaker"
+
+func init() {
+	integrations.Register(componentName, "github.com/sony/gobreaker")
+}
+
+const (
+	// tagName holds the name of the circuit breaker.
+	tagName = "circuit_breaker.name"
+	// tagState holds the state of the circuit breaker when the request was made.
+	tagState = "circuit_breaker.state"
+	// tagShortCircuited is set when the request was rejected by the circuit breaker.
+	tagShortCircuited = "circuit_breaker.short_circuited"
+	// tagTransition holds the state transition caused by the request, e.g. "closed->open".
+	tagTransition = "circuit_breaker.transition"
+	// tagTripped is set when the request caused the circuit breaker to open.
+	tagTripped = "circuit_breaker.tripped"
+)
+
+// CircuitBreaker wraps a gobreaker.CircuitBreaker so that executed requests are traced.
+type CircuitBreaker struct {
+	*gobreaker.CircuitBreaker
+	cfg *config
+}
+
+// NewCircuitBreaker calls gobreaker.NewCircuitBreaker and wraps the resulting CircuitBreaker.
+func NewCircuitBreaker(st gobreaker.Settings, opts ...Option) *CircuitBreaker {
+	return WrapCircuitBreaker(gobreaker.NewCircuitBreaker(st), opts...)
+}
+
+// WrapCircuitBreaker wraps a gobreaker.CircuitBreaker so that executed requests are traced.
+func WrapCircuitBreaker(cb *gobreaker.CircuitBreaker, opts ...Option) *CircuitBreaker {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/sony/gobreaker: Wrapping CircuitBreaker: %#v", cfg)
+	return &CircuitBreaker{CircuitBreaker: cb, cfg: cfg}
+}
+
+// Execute calls gobreaker.CircuitBreaker.Execute and traces the request.
+func (cb *CircuitBreaker) Execute(req func() (interface{}, error)) (interface{}, error) {
+	return cb.ExecuteContext(context.Background(), func(context.Context) (interface{}, error) {
+		return req()
+	})
+}
+
+// ExecuteContext traces the execution of req through the circuit breaker as a child of any
+// span found in ctx. The context passed to req holds the started span.
+func (cb *CircuitBreaker) ExecuteContext(ctx context.Context, req func(context.Context) (interface{}, error)) (interface{}, error) {
+	from := cb.State()
+	opts := []ddtrace.StartSpanOption{
+		tracer.ResourceName(cb.Name()),
+		tracer.Tag(tagName, cb.Name()),
+		tracer.Tag(tagState, from.String()),
+		tracer.Tag(ext.SpanKind, ext.SpanKindInternal),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if cb.cfg.serviceName != "" {
+		opts = append(opts, tracer.ServiceName(cb.cfg.serviceName))
+	}
+	span, ctx := tracer.StartSpanFromContext(ctx, "gobreaker.execute", opts...)
+	res, err := cb.CircuitBreaker.Execute(func() (interface{}, error) {
+		return req(ctx)
+	})
+	if err == gobreaker.ErrOpenState || err == gobreaker.ErrTooManyRequests {
+		span.SetTag(tagShortCircuited, true)
+	}
+	if to := cb.State(); to != from {
+		span.SetTag(tagTransition, from.String()+"->"+to.String())
+		if to == gobreaker.StateOpen {
+			span.SetTag(tagTripped, true)
+		}
+	}
+	span.Finish(tracer.WithError(err))
+	return res, err
+}
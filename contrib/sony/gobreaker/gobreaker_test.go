@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package gobreaker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/sony/gobreaker"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	var transitions []string
+	cb := NewCircuitBreaker(gobreaker.Settings{
+		Name:    "payments",
+		Timeout: time.Hour,
+		ReadyToTrip: func(counts gobreaker.Counts) bool {
+			return counts.ConsecutiveFailures >= 2
+		},
+		OnStateChange: func(_ string, from, to gobreaker.State) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		},
+	}, WithServiceName("breaker"))
+
+	errFailed := errors.New("failed")
+	fail := func() (interface{}, error) { return nil, errFailed }
+	res, err := cb.Execute(func() (interface{}, error) { return "ok", nil })
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", res)
+	_, err = cb.Execute(fail)
+	assert.Equal(t, errFailed, err)
+	_, err = cb.Execute(fail)
+	assert.Equal(t, errFailed, err)
+	_, err = cb.Execute(fail)
+	assert.Equal(t, gobreaker.ErrOpenState, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 4)
+	for _, s := range spans {
+		assert.Equal(t, "gobreaker.execute", s.OperationName())
+		assert.Equal(t, "payments", s.Tag(ext.ResourceName))
+		assert.Equal(t, "payments", s.Tag(tagName))
+		assert.Equal(t, "breaker", s.Tag(ext.ServiceName))
+	}
+	assert.Equal(t, "closed", spans[0].Tag(tagState))
+	assert.Nil(t, spans[0].Tag(ext.Error))
+	assert.Nil(t, spans[1].Tag(tagTransition))
+	assert.Equal(t, errFailed, spans[1].Tag(ext.Error))
+
+	// the second consecutive failure trips the breaker
+	assert.Equal(t, "closed", spans[2].Tag(tagState))
+	assert.Equal(t, "closed->open", spans[2].Tag(tagTransition))
+	assert.Equal(t, true, spans[2].Tag(tagTripped))
+	assert.Nil(t, spans[2].Tag(tagShortCircuited))
+
+	// further requests are rejected
+	assert.Equal(t, "open", spans[3].Tag(tagState))
+	assert.Equal(t, true, spans[3].Tag(tagShortCircuited))
+	assert.Nil(t, spans[3].Tag(tagTransition))
+
+	// the settings of the wrapped breaker are still honored
+	assert.Equal(t, []string{"closed->open"}, transitions)
+}
+
+func TestExecuteContext(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "parent")
+	cb := NewCircuitBreaker(gobreaker.Settings{Name: "db"})
+	_, err := cb.ExecuteContext(ctx, func(ctx context.Context) (interface{}, error) {
+		child, _ := tracer.StartSpanFromContext(ctx, "child")
+		child.Finish()
+		return nil, nil
+	})
+	assert.NoError(t, err)
+	parent.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 3)
+	child, breaker := spans[0], spans[1]
+	assert.Equal(t, breaker.SpanID(), child.ParentID())
+	assert.Equal(t, parent.Context().SpanID(), breaker.ParentID())
+}
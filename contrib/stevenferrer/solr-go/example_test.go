@@ -0,0 +1,36 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package solr_test
+
+import (
+	"context"
+	"log"
+	"net/http"
+
+	solrtrace "github.com/codebrick-corp/dd-trace-go/contrib/stevenferrer/solr-go"
+
+	"github.com/stevenferrer/solr-go"
+)
+
+func Example() {
+	client := solr.NewJSONClient("http://localhost:8983").
+		WithRequestSender(solrtrace.NewRequestSender(solrtrace.WithServiceName("my-solr")))
+
+	// The requests of the client are now traced.
+	query := solr.NewQuery(solr.NewStandardQueryParser().Query("title:solr").BuildParser())
+	if _, err := client.Query(context.Background(), "products", query); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func ExampleNewRoundTripper() {
+	// The requests sent to Solr by any other HTTP client can be traced by the
+	// http.RoundTripper of the package.
+	client := &http.Client{
+		Transport: solrtrace.NewRoundTripper(solrtrace.WithServiceName("my-solr")),
+	}
+	client.Get("http://localhost:8983/solr/products/select?q=title:solr")
+}
@@ -0,0 +1,292 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package solr provides functions to trace the requests sent to Apache Solr (https://solr.apache.org)
+// by the github.com/stevenferrer/solr-go package. The requests of a solr.JSONClient are traced
+// when it sends them with the solr.RequestSender returned by NewRequestSender. The requests
+// sent by any other HTTP client are traced by the http.RoundTripper returned by NewRoundTripper,
+// set as the Transport of its http.Client.
+package solr // import "github.com/codebrick-corp/dd-trace-go/contrib/stevenferrer/solr-go"
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/searchtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/stevenferrer/solr-go"
+)
+
+const componentName = "stevenferrer/solr-go"
+
+func init() {
+	integrations.Register(componentName, "github.com/stevenferrer/solr-go")
+}
+
+const (
+	// tagCollection holds the collection or core targeted by the request.
+	tagCollection = "solr.collection"
+	// tagHandler holds the request handler, e.g. "select" or "update/json/docs".
+	tagHandler = "solr.handler"
+	// tagParams holds the query string of the request, with its queries obfuscated.
+	tagParams = "solr.params"
+	// tagBody holds the obfuscated body of the request.
+	tagBody = "solr.body"
+)
+
+// NewRequestSender returns a new request sender for a solr.JSONClient, whose requests are
+// traced. Basic authentication can be set on the returned sender with WithBasicAuth.
+func NewRequestSender(opts ...ClientOption) *solr.DefaultRequestSender {
+	return solr.NewDefaultRequestSender().WithHTTPClient(&http.Client{
+		Transport: NewRoundTripper(opts...),
+	})
+}
+
+// NewRoundTripper returns a new http.RoundTripper which traces the requests to Solr.
+func NewRoundTripper(opts ...ClientOption) http.RoundTripper {
+	cfg := new(clientConfig)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/stevenferrer/solr-go: Configuring RoundTripper: %#v", cfg)
+	return &roundTripper{config: *cfg}
+}
+
+// roundTripper is an implementation of http.RoundTripper that captures Solr spans.
+type roundTripper struct {
+	config clientConfig
+}
+
+var _ http.RoundTripper = &roundTripper{}
+
+// RoundTrip satisfies the RoundTripper interface, wraps the sub Transport and
+// captures a span of the Solr request.
+func (t *roundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	collection, handler := splitPath(req.URL.Path)
+	opts := []ddtrace.StartSpanOption{
+		tracer.ServiceName(t.config.serviceName),
+		tracer.SpanType(ext.SpanTypeSolr),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.ResourceName(resource(req.Method, req.URL.Path, collection)),
+		tracer.Tag(ext.HTTPMethod, req.Method),
+		tracer.Tag(tagParams, obfuscateParams(req.URL.Query()).Encode()),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if collection != "" {
+		opts = append(opts, tracer.Tag(tagCollection, collection))
+	}
+	if handler != "" {
+		opts = append(opts, tracer.Tag(tagHandler, handler))
+	}
+	span, _ := tracer.StartSpanFromContext(req.Context(), "solr.query", opts...)
+	defer span.Finish()
+
+	snip, rc, err := searchtrace.Peek(req.Body, req.Header.Get("Content-Encoding"), int(req.ContentLength), searchtrace.BodyCutoff)
+	if err == nil && snip != "" {
+		if body, ok := obfuscateBody(req.Header.Get("Content-Type"), snip); ok {
+			span.SetTag(tagBody, body)
+		}
+	}
+	req.Body = rc
+	// process using the standard transport
+	res, err := t.config.transport.RoundTrip(req)
+	if err != nil {
+		// roundtrip error
+		span.SetTag(ext.Error, err)
+	} else if res.StatusCode < 200 || res.StatusCode > 299 {
+		// HTTP error
+		snip, rc, err := searchtrace.Peek(res.Body, res.Header.Get("Content-Encoding"), int(res.ContentLength), searchtrace.BodyCutoff)
+		if err != nil {
+			snip = http.StatusText(res.StatusCode)
+		}
+		span.SetTag(ext.Error, errors.New(snip))
+		res.Body = rc
+	}
+	if res != nil {
+		span.SetTag(ext.HTTPCode, strconv.Itoa(res.StatusCode))
+	}
+	return res, err
+}
+
+// splitPath returns the collection and the request handler targeted by a request to path,
+// which is either a V1 path ("/solr/products/select") or a V2 path ("/api/collections/products/select").
+// The collection is empty for requests to the admin handlers, such as "/solr/admin/cores".
+func splitPath(path string) (collection, handler string) {
+	var rest string
+	switch {
+	case strings.HasPrefix(path, "/solr/"):
+		rest = path[len("/solr/"):]
+	case strings.HasPrefix(path, "/api/collections/"):
+		rest = path[len("/api/collections/"):]
+	case strings.HasPrefix(path, "/api/c/"):
+		rest = path[len("/api/c/"):]
+	default:
+		return "", ""
+	}
+	parts := strings.SplitN(strings.Trim(rest, "/"), "/", 2)
+	if parts[0] == "admin" {
+		return "", strings.Trim(rest, "/")
+	}
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+	return parts[0], parts[1]
+}
+
+var (
+	numRegexp         = regexp.MustCompile("/([0-9]+)([/\\?]|$)")
+	numPlaceholder    = "/?$2"
+	digitsRegexp      = regexp.MustCompile("[0-9]{2,}")
+	digitsPlaceholder = "?"
+)
+
+// resource returns the resource of a request to path targeting collection. Numeric path
+// segments are generalized as are (potential) timestamped or sharded collections, e.g.
+// "GET /solr/logs_20210601/select" becomes "GET /solr/logs_?/select".
+func resource(method, path, collection string) string {
+	if collection != "" {
+		quantized := digitsRegexp.ReplaceAllString(collection, digitsPlaceholder)
+		path = strings.Replace(path, "/"+collection, "/"+quantized, 1)
+	}
+	return method + " " + numRegexp.ReplaceAllString(path, numPlaceholder)
+}
+
+// queryParams holds the parameters whose values are queries in the standard query syntax.
+var queryParams = []string{"q", "fq", "q.alt", "bq"}
+
+// obfuscateParams obfuscates the queries held by the request parameters params, in place,
+// and returns them.
+func obfuscateParams(params url.Values) url.Values {
+	for _, k := range queryParams {
+		for i, v := range params[k] {
+			params[k][i] = obfuscateQuery(v)
+		}
+	}
+	for i, v := range params["json"] {
+		params["json"][i] = searchtrace.ObfuscateJSON(v)
+	}
+	return params
+}
+
+// obfuscateBody returns the obfuscated body of a request with the given content type.
+// It returns false if the body is neither JSON nor form-encoded.
+func obfuscateBody(contentType, body string) (string, bool) {
+	mt, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case mt == "application/x-www-form-urlencoded":
+		params, err := url.ParseQuery(body)
+		if err != nil {
+			return "", false
+		}
+		return obfuscateParams(params).Encode(), true
+	case mt == "application/json" || strings.HasSuffix(mt, "+json"):
+		return searchtrace.ObfuscateJSON(body), true
+	default:
+		return "", false
+	}
+}
+
+// obfuscateQuery replaces the terms, phrases and ranges of the query q, in the standard query
+// syntax, by "?". Field names, operators and local params are kept, e.g.
+// `title:"foo bar" AND price:[10 TO 20]` becomes `title:? AND price:?`.
+func obfuscateQuery(q string) string {
+	var out strings.Builder
+	for i := 0; i < len(q); {
+		switch c := q[i]; {
+		case c == ' ' || c == '\t' || c == '\n' || c == '(' || c == ')' || c == '+' || c == '-' || c == '!':
+			out.WriteByte(c)
+			i++
+		case strings.HasPrefix(q[i:], "{!"):
+			// local params, e.g. {!edismax qf=title}
+			j := strings.IndexByte(q[i:], '}')
+			if j < 0 {
+				j = len(q) - i - 1
+			}
+			out.WriteString(q[i : i+j+1])
+			i += j + 1
+		default:
+			j := i + termLen(q[i:])
+			term := q[i:j]
+			switch {
+			case term == "AND" || term == "OR" || term == "NOT" || term == "&&" || term == "||" || term == "*":
+				out.WriteString(term)
+			case fieldEnd(term) > 0:
+				// field name; its value is obfuscated on the next iteration
+				n := fieldEnd(term)
+				out.WriteString(term[:n+1])
+				j = i + n + 1
+			default:
+				out.WriteByte('?')
+			}
+			i = j
+		}
+	}
+	return out.String()
+}
+
+// termLen returns the length of the term, phrase or range starting s.
+func termLen(s string) int {
+	var end byte
+	switch s[0] {
+	case '"':
+		end = '"'
+	case '[':
+		end = ']'
+	case '{':
+		end = '}'
+	}
+	if end != 0 {
+		if j := indexUnescaped(s[1:], end); j >= 0 {
+			return j + 2
+		}
+		return len(s)
+	}
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case ' ', '\t', '\n', '(', ')':
+			return i
+		case '"', '[', '{':
+			if i > 0 && s[i-1] == ':' {
+				// value of a field
+				return i
+			}
+		}
+	}
+	return len(s)
+}
+
+// fieldEnd returns the index of the colon ending the field name prefixing term, or -1.
+func fieldEnd(term string) int {
+	if term[0] == '"' || term[0] == '[' || term[0] == '{' {
+		return -1
+	}
+	return indexUnescaped(term, ':')
+}
+
+// indexUnescaped returns the index of the first c in s not escaped by a backslash, or -1.
+func indexUnescaped(s string, c byte) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case c:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,241 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package solr
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/stevenferrer/solr-go"
+)
+
+// newServer returns a fake Solr server which echoes the bodies of the requests, except
+// for updates, and answers requests to missing collections with a 404.
+func newServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/solr/missing/") {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte("Not Found"))
+			return
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if len(body) == 0 || strings.HasSuffix(r.URL.Path, "/update") {
+			body = []byte(`{}`)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func do(t *testing.T, req *http.Request, opts ...ClientOption) (int, string) {
+	res, err := (&http.Client{Transport: NewRoundTripper(opts...)}).Do(req)
+	require.NoError(t, err)
+	defer res.Body.Close()
+	b, err := ioutil.ReadAll(res.Body)
+	require.NoError(t, err)
+	return res.StatusCode, string(b)
+}
+
+func TestSelect(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	srv := newServer(t)
+
+	root, ctx := tracer.StartSpanFromContext(context.Background(), "web.request")
+	req, err := http.NewRequestWithContext(ctx, "GET", srv.URL+"/solr/logs_20210601/select?q=user:alice&rows=10", nil)
+	require.NoError(t, err)
+	code, _ := do(t, req, WithServiceName("my-solr"))
+	root.Finish()
+	assert.Equal(http.StatusOK, code)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	s := spans[0]
+	assert.Equal("solr.query", s.OperationName())
+	assert.Equal(root.Context().SpanID(), s.ParentID())
+	assert.Equal("my-solr", s.Tag(ext.ServiceName))
+	assert.Equal(ext.SpanTypeSolr, s.Tag(ext.SpanType))
+	assert.Equal(ext.SpanKindClient, s.Tag(ext.SpanKind))
+	assert.Equal("GET /solr/logs_?/select", s.Tag(ext.ResourceName))
+	assert.Equal("logs_20210601", s.Tag(tagCollection))
+	assert.Equal("select", s.Tag(tagHandler))
+	assert.Equal("q=user%3A%3F&rows=10", s.Tag(tagParams))
+	assert.Equal("200", s.Tag(ext.HTTPCode))
+	assert.Nil(s.Tag(tagBody))
+	assert.Nil(s.Tag(ext.Error))
+}
+
+func TestJSONClient(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+	client := solr.NewJSONClient(newServer(t).URL).WithRequestSender(NewRequestSender())
+
+	root, ctx := tracer.StartSpanFromContext(context.Background(), "web.request")
+	query := solr.NewQuery(solr.NewStandardQueryParser().Query("title:solr").BuildParser()).
+		Filters("price:[10 TO 20]").Limit(5)
+	_, err := client.Query(ctx, "products", query)
+	require.NoError(t, err)
+	_, err = client.Update(ctx, "products", solr.JSON, strings.NewReader(`[{"id":"1","title":"solr"}]`))
+	require.NoError(t, err)
+	require.NoError(t, client.Commit(ctx, "products"))
+	_, err = client.Query(ctx, "missing", query)
+	assert.Error(err)
+	root.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 5)
+	for _, s := range spans[:4] {
+		assert.Equal(root.Context().SpanID(), s.ParentID())
+		assert.Equal("solr.query", s.OperationName())
+		assert.Equal("solr.client", s.Tag(ext.ServiceName))
+		assert.Equal(componentName, s.Tag(ext.Component))
+	}
+
+	s := spans[0]
+	assert.Equal("POST /solr/products/query", s.Tag(ext.ResourceName))
+	assert.Equal("products", s.Tag(tagCollection))
+	assert.Equal("query", s.Tag(tagHandler))
+	assert.NotContains(s.Tag(tagBody), "solr")
+	assert.NotContains(s.Tag(tagBody), "20")
+
+	s = spans[1]
+	assert.Equal("POST /solr/products/update", s.Tag(ext.ResourceName))
+	assert.Equal(`[{"id":"?","title":"?"}]`, s.Tag(tagBody))
+
+	s = spans[2]
+	assert.Equal("GET /solr/products/update", s.Tag(ext.ResourceName))
+	assert.Equal("commit=true", s.Tag(tagParams))
+
+	s = spans[3]
+	assert.Equal("missing", s.Tag(tagCollection))
+	assert.Equal("404", s.Tag(ext.HTTPCode))
+	assert.EqualError(s.Tag(ext.Error).(error), "Not Found")
+}
+
+func TestBody(t *testing.T) {
+	t.Run("json", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		body := `{"query":"title:solr","filter":["price:[10 TO 20]"],"limit":5}`
+		req, err := http.NewRequest("POST", newServer(t).URL+"/api/collections/products/query", strings.NewReader(body))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		_, got := do(t, req)
+		assert.Equal(t, body, got, "the body must be left intact")
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		s := spans[0]
+		assert.Equal(t, "POST /api/collections/products/query", s.Tag(ext.ResourceName))
+		assert.Equal(t, "products", s.Tag(tagCollection))
+		assert.Equal(t, "query", s.Tag(tagHandler))
+		assert.Equal(t, `{"query":"?","filter":["?"],"limit":"?"}`, s.Tag(tagBody))
+	})
+
+	t.Run("form", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		form := url.Values{"q": {"title:solr"}, "fq": {"price:[10 TO 20]"}, "wt": {"json"}}
+		req, err := http.NewRequest("POST", newServer(t).URL+"/solr/products/select", strings.NewReader(form.Encode()))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		do(t, req)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "fq=price%3A%3F&q=title%3A%3F&wt=json", spans[0].Tag(tagBody))
+	})
+
+	t.Run("other", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		req, err := http.NewRequest("POST", newServer(t).URL+"/solr/products/update", strings.NewReader(`<add><doc><field name="id">1</field></doc></add>`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "text/xml")
+		do(t, req)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Nil(t, spans[0].Tag(tagBody))
+	})
+}
+
+func TestError(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	req, err := http.NewRequest("GET", newServer(t).URL+"/solr/missing/get?id=42", nil)
+	require.NoError(t, err)
+	code, _ := do(t, req)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "404", spans[0].Tag(ext.HTTPCode))
+	assert.EqualError(t, spans[0].Tag(ext.Error).(error), "Not Found")
+}
+
+func TestSplitPath(t *testing.T) {
+	for _, tt := range []struct{ path, collection, handler string }{
+		{"/solr/products/select", "products", "select"},
+		{"/solr/products/update/json/docs", "products", "update/json/docs"},
+		{"/solr/products", "products", ""},
+		{"/solr/admin/cores", "", "admin/cores"},
+		{"/api/collections/products/select", "products", "select"},
+		{"/api/c/products/update", "products", "update"},
+		{"/other/products/select", "", ""},
+	} {
+		collection, handler := splitPath(tt.path)
+		assert.Equal(t, tt.collection, collection, tt.path)
+		assert.Equal(t, tt.handler, handler, tt.path)
+	}
+}
+
+func TestResource(t *testing.T) {
+	for _, tt := range []struct{ method, path, collection, expected string }{
+		{"GET", "/solr/products/select", "products", "GET /solr/products/select"},
+		{"GET", "/solr/logs_20210601/select", "logs_20210601", "GET /solr/logs_?/select"},
+		{"GET", "/solr/shard12_replica_n3/select", "shard12_replica_n3", "GET /solr/shard?_replica_n3/select"},
+		{"GET", "/solr/admin/cores", "", "GET /solr/admin/cores"},
+		{"DELETE", "/api/c/products/docs/123", "products", "DELETE /api/c/products/docs/?"},
+	} {
+		assert.Equal(t, tt.expected, resource(tt.method, tt.path, tt.collection), tt.path)
+	}
+}
+
+func TestObfuscateQuery(t *testing.T) {
+	for in, out := range map[string]string{
+		`*:*`:                                  `*:*`,
+		`alice`:                                `?`,
+		`user:alice`:                           `user:?`,
+		`title:"foo bar" AND price:[10 TO 20]`: `title:? AND price:?`,
+		`+name:(alice OR bob) -age:{* TO 18}`:  `+name:(? OR ?) -age:?`,
+		`{!edismax qf=title}solr rocks`:        `{!edismax qf=title}? ?`,
+		`path:a\:b NOT tag:x*`:                 `path:? NOT tag:?`,
+		`title:"unterminated`:                  `title:?`,
+	} {
+		assert.Equal(t, out, obfuscateQuery(in), in)
+	}
+}
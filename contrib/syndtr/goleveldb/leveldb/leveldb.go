@@ -13,6 +13,7 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/syndtr/goleveldb/leveldb"
@@ -22,6 +23,12 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/util"
 )
 
+const componentName = "syndtr/goleveldb/leveldb"
+
+func init() {
+	integrations.Register(componentName, "github.com/syndtr/goleveldb/leveldb")
+}
+
 // A DB wraps a leveldb.DB and traces all queries.
 type DB struct {
 	*leveldb.DB
@@ -269,8 +276,10 @@ func (it *Iterator) Release() {
 func startSpan(cfg *config, name string) ddtrace.Span {
 	opts := []ddtrace.StartSpanOption{
 		tracer.SpanType(ext.SpanTypeLevelDB),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(cfg.serviceName),
 		tracer.ResourceName(name),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
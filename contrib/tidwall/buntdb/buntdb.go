@@ -13,11 +13,18 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/tidwall/buntdb"
 )
 
+const componentName = "tidwall/buntdb"
+
+func init() {
+	integrations.Register(componentName, "github.com/tidwall/buntdb")
+}
+
 // A DB wraps a buntdb.DB, automatically tracing any transactions.
 type DB struct {
 	*buntdb.DB
@@ -96,8 +103,10 @@ func WrapTx(tx *buntdb.Tx, opts ...Option) *Tx {
 func (tx *Tx) startSpan(name string) ddtrace.Span {
 	opts := []ddtrace.StartSpanOption{
 		tracer.SpanType(ext.AppTypeDB),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(tx.cfg.serviceName),
 		tracer.ResourceName(name),
+		tracer.Tag(ext.Component, componentName),
 	}
 	if !math.IsNaN(tx.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, tx.cfg.analyticsRate))
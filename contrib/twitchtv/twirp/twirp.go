@@ -14,13 +14,21 @@ import (
 	"net/http"
 	"strconv"
 
+	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/twitchtv/twirp"
 )
 
+const componentName = "twitchtv/twirp"
+
+func init() {
+	integrations.Register(componentName, "github.com/twitchtv/twirp")
+}
+
 type (
 	twirpErrorKey struct{}
 	twirpSpanKey  struct{}
@@ -52,9 +60,11 @@ func WrapClient(c HTTPClient, opts ...Option) HTTPClient {
 func (wc *wrappedClient) Do(req *http.Request) (*http.Response, error) {
 	opts := []tracer.StartSpanOption{
 		tracer.SpanType(ext.SpanTypeHTTP),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
 		tracer.ServiceName(wc.cfg.clientServiceName()),
 		tracer.Tag(ext.HTTPMethod, req.Method),
-		tracer.Tag(ext.HTTPURL, req.URL.Path),
+		tracer.Tag(ext.HTTPURL, httptrace.QuantizePath(req.URL.Path)),
+		tracer.Tag(ext.Component, componentName),
 	}
 	ctx := req.Context()
 	if pkg, ok := twirp.PackageName(ctx); ok {
@@ -107,10 +117,12 @@ func WrapServer(h http.Handler, opts ...Option) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		opts := []tracer.StartSpanOption{
 			tracer.SpanType(ext.SpanTypeWeb),
+			tracer.Tag(ext.SpanKind, ext.SpanKindServer),
 			tracer.ServiceName(cfg.serverServiceName()),
 			tracer.Tag(ext.HTTPMethod, r.Method),
-			tracer.Tag(ext.HTTPURL, r.URL.Path),
+			tracer.Tag(ext.HTTPURL, httptrace.QuantizePath(r.URL.Path)),
 			tracer.Measured(),
+			tracer.Tag(ext.Component, componentName),
 		}
 		if !math.IsNaN(cfg.analyticsRate) {
 			opts = append(opts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
@@ -157,8 +169,10 @@ func requestReceivedHook(cfg *config) func(context.Context) (context.Context, er
 	return func(ctx context.Context) (context.Context, error) {
 		opts := []tracer.StartSpanOption{
 			tracer.SpanType(ext.SpanTypeWeb),
+			tracer.Tag(ext.SpanKind, ext.SpanKindServer),
 			tracer.ServiceName(cfg.serverServiceName()),
 			tracer.Measured(),
+			tracer.Tag(ext.Component, componentName),
 		}
 		if pkg, ok := twirp.PackageName(ctx); ok {
 			opts = append(opts, tracer.Tag("twirp.package", pkg))
@@ -0,0 +1,103 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package bun provides functions to trace the uptrace/bun package (https://github.com/uptrace/bun).
+package bun // import "github.com/codebrick-corp/dd-trace-go/contrib/uptrace/bun"
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/DataDog/datadog-agent/pkg/obfuscate"
+	"github.com/uptrace/bun"
+)
+
+const componentName = "uptrace/bun"
+
+func init() {
+	integrations.Register(componentName, "github.com/uptrace/bun")
+}
+
+// tagBunModel holds the name of the model of a query.
+const tagBunModel = "bun.model"
+
+// Wrap augments the given DB with tracing. The queries are traced as children of the
+// span found in the context they are run with. They are named after their formatted
+// statement, obfuscated to leave out its literals.
+func Wrap(db *bun.DB, opts ...Option) {
+	cfg := new(config)
+	defaults(cfg)
+	for _, fn := range opts {
+		fn(cfg)
+	}
+	log.Debug("contrib/uptrace/bun: Wrapping Database")
+	db.AddQueryHook(&queryHook{
+		cfg:        cfg,
+		dbType:     db.Dialect().Name().String(),
+		obfuscator: obfuscate.NewObfuscator(obfuscate.Config{}),
+	})
+}
+
+type queryHook struct {
+	cfg        *config
+	dbType     string
+	obfuscator *obfuscate.Obfuscator
+}
+
+var _ bun.QueryHook = (*queryHook)(nil)
+
+// BeforeQuery implements bun.QueryHook.
+func (h *queryHook) BeforeQuery(ctx context.Context, qe *bun.QueryEvent) context.Context {
+	opts := []ddtrace.StartSpanOption{
+		tracer.SpanType(ext.SpanTypeSQL),
+		tracer.Tag(ext.SpanKind, ext.SpanKindClient),
+		tracer.ResourceName(h.resource(qe)),
+		tracer.ServiceName(h.cfg.serviceName),
+		tracer.Tag(ext.DBType, h.dbType),
+		tracer.Tag(ext.DBOperation, qe.Operation()),
+		tracer.Tag(ext.Component, componentName),
+	}
+	if tm, ok := qe.Model.(bun.TableModel); ok && tm.Table() != nil {
+		opts = append(opts, tracer.Tag(tagBunModel, tm.Table().TypeName))
+	}
+	_, ctx = tracer.StartSpanFromContext(ctx, "bun.query", opts...)
+	return ctx
+}
+
+// AfterQuery implements bun.QueryHook.
+func (h *queryHook) AfterQuery(ctx context.Context, qe *bun.QueryEvent) {
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return
+	}
+	if qe.Result != nil {
+		if n, err := qe.Result.RowsAffected(); err == nil {
+			span.SetTag(ext.DBRowCount, n)
+		}
+	}
+	err := qe.Err
+	if err == sql.ErrNoRows {
+		// not an error of the database
+		err = nil
+	}
+	span.Finish(tracer.WithError(err))
+}
+
+// resource returns the obfuscated formatted query of qe, or its template if it can't
+// be obfuscated.
+func (h *queryHook) resource(qe *bun.QueryEvent) string {
+	oq, err := h.obfuscator.ObfuscateSQLString(qe.Query)
+	if err != nil {
+		log.Debug("contrib/uptrace/bun: Error obfuscating query: %v", err)
+		return qe.QueryTemplate
+	}
+	return oq.Query
+}
@@ -0,0 +1,216 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package bun
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/uptrace/bun"
+	"github.com/uptrace/bun/dialect/pgdialect"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+// fakeConnector connects to a fake database returning two users for any query but the
+// ones filtering with "WHERE false", and failing the statements on the "fail" table.
+type fakeConnector struct{}
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) { return fakeConn{}, nil }
+
+func (fakeConnector) Driver() driver.Driver { return nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(_ string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+
+func (fakeConn) Close() error { return nil }
+
+func (fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (fakeConn) QueryContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Rows, error) {
+	if strings.Contains(query, `"fail"`) {
+		return nil, errors.New("relation does not exist")
+	}
+	if strings.Contains(query, "WHERE false") {
+		return &fakeRows{}, nil
+	}
+	return &fakeRows{rows: [][]driver.Value{{int64(1), "alice"}, {int64(2), "bob"}}}, nil
+}
+
+func (fakeConn) ExecContext(_ context.Context, query string, _ []driver.NamedValue) (driver.Result, error) {
+	if strings.Contains(query, `"fail"`) {
+		return nil, errors.New("relation does not exist")
+	}
+	return driver.RowsAffected(1), nil
+}
+
+type fakeRows struct {
+	rows [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return []string{"id", "name"} }
+
+func (r *fakeRows) Close() error { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+type User struct {
+	ID   int64
+	Name string
+}
+
+func newDB(opts ...Option) *bun.DB {
+	db := bun.NewDB(sql.OpenDB(fakeConnector{}), pgdialect.New())
+	Wrap(db, opts...)
+	return db
+}
+
+func TestImplementsHook(t *testing.T) {
+	var _ bun.QueryHook = (*queryHook)(nil)
+}
+
+func TestSelect(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	db := newDB()
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "http.request",
+		tracer.ServiceName("fake-http-server"),
+		tracer.SpanType(ext.SpanTypeWeb),
+	)
+	var users []User
+	err := db.NewSelect().Model(&users).Where("name = ?", "alice").Scan(ctx)
+	parent.Finish()
+	require.NoError(t, err)
+	assert.Len(users, 2)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	s := spans[0]
+	assert.Equal("bun.query", s.OperationName())
+	assert.Equal(parent.Context().SpanID(), s.ParentID())
+	assert.Equal("SELECT user . id, user . name FROM users WHERE ( name = ? )", s.Tag(ext.ResourceName))
+	assert.NotContains(s.Tag(ext.ResourceName), "alice")
+	assert.Equal("bun.db", s.Tag(ext.ServiceName))
+	assert.Equal(ext.SpanTypeSQL, s.Tag(ext.SpanType))
+	assert.Equal("pg", s.Tag(ext.DBType))
+	assert.Equal("SELECT", s.Tag(ext.DBOperation))
+	assert.Equal("User", s.Tag(tagBunModel))
+	assert.Equal(int64(2), s.Tag(ext.DBRowCount))
+	assert.Nil(s.Tag(ext.Error))
+}
+
+func TestInsert(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	db := newDB()
+	_, err := db.NewInsert().Model(&User{ID: 3, Name: "carol"}).Exec(context.Background())
+	require.NoError(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	s := spans[0]
+	assert.Equal("INSERT INTO users ( id, name ) VALUES ( ? )", s.Tag(ext.ResourceName))
+	assert.Equal("INSERT", s.Tag(ext.DBOperation))
+	assert.Equal("User", s.Tag(tagBunModel))
+	// bun only reports the rows of the queries it scans
+	assert.Nil(s.Tag(ext.DBRowCount))
+}
+
+func TestError(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	db := newDB()
+	_, err := db.ExecContext(context.Background(), `DELETE FROM "fail"`)
+	require.Error(t, err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(err, spans[0].Tag(ext.Error))
+	assert.Nil(spans[0].Tag(tagBunModel))
+	assert.Nil(spans[0].Tag(ext.DBRowCount))
+}
+
+func TestNoRows(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	db := newDB()
+	var n int
+	err := db.NewRaw(`SELECT 1 FROM "users" WHERE false`).Scan(context.Background(), &n)
+	assert.Equal(t, sql.ErrNoRows, err)
+
+	// sql.ErrNoRows is not an error of the database.
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Nil(t, spans[0].Tag(ext.Error))
+}
+
+func TestServiceName(t *testing.T) {
+	t.Run("default", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		db := newDB()
+		_, err := db.NewSelect().ColumnExpr("1").Exec(context.Background())
+		require.NoError(t, err)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "bun.db", spans[0].Tag(ext.ServiceName))
+	})
+
+	t.Run("global", func(t *testing.T) {
+		globalconfig.SetServiceName("global-service")
+		defer globalconfig.SetServiceName("")
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		db := newDB()
+		_, err := db.NewSelect().ColumnExpr("1").Exec(context.Background())
+		require.NoError(t, err)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "global-service", spans[0].Tag(ext.ServiceName))
+	})
+
+	t.Run("custom", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		db := newDB(WithServiceName("my-service"))
+		_, err := db.NewSelect().ColumnExpr("1").Exec(context.Background())
+		require.NoError(t, err)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "my-service", spans[0].Tag(ext.ServiceName))
+	})
+}
@@ -16,34 +16,48 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/contrib/internal/httptrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 )
 
+const componentName = "urfave/negroni"
+
+func init() {
+	integrations.Register(componentName, "github.com/urfave/negroni")
+}
+
 // DatadogMiddleware returns middleware that will trace incoming requests.
 type DatadogMiddleware struct {
 	cfg *config
+	mw  *httptrace.Middleware
 }
 
 func (m *DatadogMiddleware) ServeHTTP(w http.ResponseWriter, r *http.Request, next http.HandlerFunc) {
-	opts := append(m.cfg.spanOpts, tracer.ServiceName(m.cfg.serviceName), tracer.ResourceName(m.cfg.resourceNamer(r)))
+	opts := append(m.cfg.spanOpts, tracer.ServiceName(m.cfg.serviceName), tracer.ResourceName(m.cfg.resourceNamer(r)),
+		tracer.Tag(ext.Component, componentName))
 	if !math.IsNaN(m.cfg.analyticsRate) {
 		opts = append(opts, tracer.Tag(ext.EventSampleRate, m.cfg.analyticsRate))
 	}
-	span, ctx := httptrace.StartRequestSpan(r, opts...)
+	span, ctx := m.mw.StartRequestSpan(r, opts...)
 	defer func() {
 		// check if the responseWriter is of type negroni.ResponseWriter
 		var (
 			status int
+			resp   *httptrace.Response
 			opts   []tracer.FinishOption
 		)
 		responseWriter, ok := w.(negroni.ResponseWriter)
 		if ok {
 			status = responseWriter.Status()
+			resp = &httptrace.Response{Header: responseWriter.Header(), Size: int64(responseWriter.Size())}
+			if status == http.StatusTooManyRequests {
+				httptrace.SetRateLimitTags(span, responseWriter.Header())
+			}
 			if m.cfg.isStatusError(status) {
 				opts = []tracer.FinishOption{tracer.WithError(fmt.Errorf("%d: %s", status, http.StatusText(status)))}
 			}
 		}
-		httptrace.FinishRequestSpan(span, status, opts...)
+		httptrace.FinishRequestSpan(span, status, resp, opts...)
 	}()
 
 	next(w, r.WithContext(ctx))
@@ -60,6 +74,7 @@ func Middleware(opts ...Option) *DatadogMiddleware {
 
 	m := DatadogMiddleware{
 		cfg: cfg,
+		mw:  httptrace.NewMiddleware(),
 	}
 
 	return &m
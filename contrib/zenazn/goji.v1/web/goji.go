@@ -15,11 +15,18 @@ import (
 	httptrace "github.com/codebrick-corp/dd-trace-go/contrib/net/http"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
 	"github.com/zenazn/goji/web"
 )
 
+const componentName = "zenazn/goji.v1/web"
+
+func init() {
+	integrations.Register(componentName, "github.com/zenazn/goji/web")
+}
+
 // Middleware returns a goji middleware function that will trace incoming requests.
 // If goji's Router middleware is also installed, the tracer will be able to determine
 // the original route name (e.g. "/user/:id"), and include it as part of the traces' resource
@@ -36,6 +43,7 @@ func Middleware(opts ...Option) func(*web.C, http.Handler) http.Handler {
 	if !math.IsNaN(cfg.analyticsRate) {
 		cfg.spanOpts = append(cfg.spanOpts, tracer.Tag(ext.EventSampleRate, cfg.analyticsRate))
 	}
+	cfg.spanOpts = append(cfg.spanOpts, tracer.Tag(ext.Component, componentName))
 	log.Debug("contrib/zenazn/goji.v1/web: Configuring Middleware: %#v", cfg)
 	return func(c *web.C, h http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
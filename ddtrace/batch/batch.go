@@ -0,0 +1,163 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package batch provides functions to trace the batching components of data pipelines, such
+// as the buffers accumulating items in memory until they are flushed to a database or a queue.
+// Items are recorded as they are added to the batch, and each flush is traced as a span holding
+// the number of items of the batch, the times at which its first and last items were added, and
+// the outcome of the flush:
+//
+//	b := batch.New("events.insert", tracer.ServiceName("ingester"))
+//	for {
+//		select {
+//		case ev := <-events:
+//			buf = append(buf, ev)
+//			b.Add(1)
+//			if len(buf) < maxSize {
+//				continue
+//			}
+//			b.Flush(ctx, "size", func(ctx context.Context) error { return insert(ctx, buf) })
+//		case <-ticker.C:
+//			b.Flush(ctx, "interval", func(ctx context.Context) error { return insert(ctx, buf) })
+//		}
+//		buf = buf[:0]
+//	}
+package batch // import "github.com/codebrick-corp/dd-trace-go/ddtrace/batch"
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+const (
+	// OperationName is the name of the spans of batch flushes.
+	OperationName = "batch.flush"
+
+	// TagItems holds the number of items of a flushed batch.
+	TagItems = "batch.items"
+	// TagFirstItem holds the time at which the first item of a batch was added, in
+	// nanoseconds since the Unix epoch.
+	TagFirstItem = "batch.first_item"
+	// TagLastItem holds the time at which the last item of a batch was added, in
+	// nanoseconds since the Unix epoch.
+	TagLastItem = "batch.last_item"
+	// TagReason holds the reason of a flush, e.g. "size" or "interval".
+	TagReason = "batch.flush_reason"
+	// TagOutcome holds the outcome of a flush, one of OutcomeSuccess, OutcomeError or
+	// OutcomeDropped.
+	TagOutcome = "batch.outcome"
+)
+
+// Values for the TagOutcome tag.
+const (
+	// OutcomeSuccess is the outcome of a batch which was flushed successfully.
+	OutcomeSuccess = "success"
+	// OutcomeError is the outcome of a batch whose flush failed.
+	OutcomeError = "error"
+	// OutcomeDropped is the outcome of a batch which was discarded without being flushed.
+	OutcomeDropped = "dropped"
+)
+
+// Batch records the items added to a batch and traces its flushes. After each flush, it
+// starts recording the next batch. It is safe for concurrent use.
+type Batch struct {
+	name string
+	opts []ddtrace.StartSpanOption
+
+	mu    sync.Mutex // guards below fields
+	items int
+	first time.Time
+	last  time.Time
+}
+
+// New returns a Batch whose flush spans have their resource set to name, and are started
+// with the given options.
+func New(name string, opts ...ddtrace.StartSpanOption) *Batch {
+	return &Batch{
+		name: name,
+		opts: opts[:len(opts):len(opts)],
+	}
+}
+
+// Add records n items added to the current batch now.
+func (b *Batch) Add(n int) {
+	b.AddAt(time.Now(), n)
+}
+
+// AddAt records n items added to the current batch at time t, e.g. the time at which they
+// were received when they are added to the batch later.
+func (b *Batch) AddAt(t time.Time, n int) {
+	if n <= 0 {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.items += n
+	if b.first.IsZero() || t.Before(b.first) {
+		b.first = t
+	}
+	if t.After(b.last) {
+		b.last = t
+	}
+}
+
+// Items returns the number of items of the current batch.
+func (b *Batch) Items() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.items
+}
+
+// Flush traces the flush of the current batch made by flush, for the given reason, and
+// starts recording the next batch. The flush is traced as a child of the span in ctx, and
+// flush receives the context of its span. It returns the error of flush. Items added while
+// flush runs are recorded in the next batch.
+func (b *Batch) Flush(ctx context.Context, reason string, flush func(ctx context.Context) error) error {
+	span, ctx := b.start(ctx, reason)
+	err := flush(ctx)
+	if err != nil {
+		span.SetTag(TagOutcome, OutcomeError)
+	} else {
+		span.SetTag(TagOutcome, OutcomeSuccess)
+	}
+	span.Finish(tracer.WithError(err))
+	return err
+}
+
+// Drop traces the current batch as discarded without being flushed for the given reason,
+// and starts recording the next batch. err is the error which caused the batch to be
+// discarded, if any, e.g. the last error of a flush which exhausted its retries.
+func (b *Batch) Drop(ctx context.Context, reason string, err error) {
+	span, _ := b.start(ctx, reason)
+	span.SetTag(TagOutcome, OutcomeDropped)
+	span.Finish(tracer.WithError(err))
+}
+
+// start starts the span of a flush of the current batch, and resets it.
+func (b *Batch) start(ctx context.Context, reason string) (ddtrace.Span, context.Context) {
+	b.mu.Lock()
+	items, first, last := b.items, b.first, b.last
+	b.items, b.first, b.last = 0, time.Time{}, time.Time{}
+	b.mu.Unlock()
+
+	opts := append(b.opts,
+		tracer.ResourceName(b.name),
+		tracer.Tag(TagItems, items),
+	)
+	if reason != "" {
+		opts = append(opts, tracer.Tag(TagReason, reason))
+	}
+	if items > 0 {
+		opts = append(opts,
+			tracer.Tag(TagFirstItem, first.UnixNano()),
+			tracer.Tag(TagLastItem, last.UnixNano()),
+		)
+	}
+	return tracer.StartSpanFromContext(ctx, OperationName, opts...)
+}
@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package batch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func TestFlush(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	b := New("events.insert", tracer.ServiceName("ingester"))
+	t0 := time.Unix(100, 0)
+	b.AddAt(t0.Add(time.Second), 2)
+	b.AddAt(t0, 1)
+	b.AddAt(t0.Add(2*time.Second), 3)
+	b.Add(0)
+	assert.Equal(6, b.Items())
+
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "parent")
+	err := b.Flush(ctx, "size", func(ctx context.Context) error {
+		span, ok := tracer.SpanFromContext(ctx)
+		require.True(t, ok)
+		assert.Equal(OperationName, span.(mocktracer.Span).OperationName())
+		return nil
+	})
+	assert.NoError(err)
+	assert.Equal(0, b.Items())
+	parent.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	span := spans[0]
+	assert.Equal(OperationName, span.OperationName())
+	assert.Equal(parent.Context().SpanID(), span.ParentID())
+	assert.Equal("events.insert", span.Tag(ext.ResourceName))
+	assert.Equal("ingester", span.Tag(ext.ServiceName))
+	assert.Equal(6, span.Tag(TagItems))
+	assert.Equal(t0.UnixNano(), span.Tag(TagFirstItem))
+	assert.Equal(t0.Add(2*time.Second).UnixNano(), span.Tag(TagLastItem))
+	assert.Equal("size", span.Tag(TagReason))
+	assert.Equal(OutcomeSuccess, span.Tag(TagOutcome))
+	assert.Nil(span.Tag(ext.Error))
+}
+
+func TestFlushError(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	b := New("events.insert")
+	b.Add(1)
+	want := errors.New("insert failed")
+	err := b.Flush(context.Background(), "interval", func(ctx context.Context) error { return want })
+	assert.Equal(want, err)
+
+	// empty batch
+	err = b.Flush(context.Background(), "", func(ctx context.Context) error { return nil })
+	assert.NoError(err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 2)
+	assert.Equal(want, spans[0].Tag(ext.Error))
+	assert.Equal(OutcomeError, spans[0].Tag(TagOutcome))
+	assert.Equal(1, spans[0].Tag(TagItems))
+	assert.Equal(0, spans[1].Tag(TagItems))
+	assert.Nil(spans[1].Tag(TagFirstItem))
+	assert.Nil(spans[1].Tag(TagReason))
+}
+
+func TestDrop(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	b := New("events.insert")
+	b.Add(3)
+	want := errors.New("retries exhausted")
+	b.Drop(context.Background(), "shutdown", want)
+	assert.Equal(0, b.Items())
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 1)
+	assert.Equal(OutcomeDropped, spans[0].Tag(TagOutcome))
+	assert.Equal("shutdown", spans[0].Tag(TagReason))
+	assert.Equal(3, spans[0].Tag(TagItems))
+	assert.Equal(want, spans[0].Tag(ext.Error))
+}
+
+func TestConcurrent(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	b := New("events.insert")
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				b.Add(1)
+			}
+		}()
+	}
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				b.Flush(context.Background(), "interval", func(context.Context) error { return nil })
+			}
+		}
+	}()
+	wg.Wait()
+	close(done)
+	<-stopped
+	b.Flush(context.Background(), "shutdown", func(context.Context) error { return nil })
+
+	var total int
+	for _, s := range mt.FinishedSpans() {
+		total += s.Tag(TagItems).(int)
+	}
+	assert.Equal(t, 1000, total)
+}
@@ -128,6 +128,27 @@ type StartSpanConfig struct {
 
 	// Context is the parent context where the span should be stored.
 	Context context.Context
+
+	// SpanLinks holds links to spans which are causally related to the new span
+	// without being its parent, such as spans belonging to other traces.
+	SpanLinks []SpanLink
+
+	// UnifiedServiceTags, when not nil, overrides whether the version and env of the
+	// application are set on the new span, regardless of its service.
+	UnifiedServiceTags *bool
+}
+
+// SpanLink represents a causal relationship between a span and another span,
+// which may belong to a different trace.
+type SpanLink struct {
+	// TraceID is the ID of the trace the linked span belongs to.
+	TraceID uint64
+
+	// SpanID is the ID of the linked span.
+	SpanID uint64
+
+	// Attributes holds optional metadata describing the relationship.
+	Attributes map[string]string
 }
 
 // Logger implementations are able to log given messages that the tracer might output.
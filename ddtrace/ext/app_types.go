@@ -57,6 +57,21 @@ const (
 	// SpanTypeMongoDB marks a span as a MongoDB operation.
 	SpanTypeMongoDB = "mongodb"
 
+	// SpanTypeNeo4j marks a span as a Neo4j operation.
+	SpanTypeNeo4j = "neo4j"
+
+	// SpanTypeDgraph marks a span as a Dgraph operation.
+	SpanTypeDgraph = "dgraph"
+
+	// SpanTypeInfluxDB marks a span as an InfluxDB operation.
+	SpanTypeInfluxDB = "influxdb"
+
+	// SpanTypeOpenSearch marks a span as an OpenSearch operation.
+	SpanTypeOpenSearch = "opensearch"
+
+	// SpanTypeSolr marks a span as a Solr operation.
+	SpanTypeSolr = "solr"
+
 	// SpanTypeElasticSearch marks a span as an ElasticSearch operation.
 	// These spans may also have an "elasticsearch.body" tag.
 	SpanTypeElasticSearch = "elasticsearch"
@@ -75,4 +90,16 @@ const (
 
 	// SpanTypeConsul marks a span as a Consul operation.
 	SpanTypeConsul = "consul"
+
+	// SpanTypeGraphQL marks a span as a GraphQL operation.
+	SpanTypeGraphQL = "graphql"
+
+	// SpanTypeLDAP marks a span as an LDAP operation.
+	SpanTypeLDAP = "ldap"
+
+	// SpanTypeSMTP marks a span as the sending of an email.
+	SpanTypeSMTP = "smtp"
+
+	// SpanTypeServerless marks a span as the invocation of a serverless function.
+	SpanTypeServerless = "serverless"
 )
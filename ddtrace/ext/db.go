@@ -12,10 +12,27 @@ const (
 	DBName = "db.name"
 	// DBType indicates the type of Database.
 	DBType = "db.type"
+	// DBSystem indicates the database management system, e.g. "postgresql" or "mssql".
+	DBSystem = "db.system"
 	// DBInstance indicates the instance name of Database.
 	DBInstance = "db.instance"
 	// DBUser indicates the user name of Database, e.g. "readonly_user" or "reporting_user".
 	DBUser = "db.user"
 	// DBStatement records a database statement for the given database type.
 	DBStatement = "db.statement"
+	// DBOperation indicates the operation of a database statement, e.g. "SELECT".
+	DBOperation = "db.operation"
+	// DBRowCount indicates the number of rows returned or affected by a database statement.
+	DBRowCount = "db.row_count"
+)
+
+// Values for the DBSystem tag.
+const (
+	DBSystemMySQL      = "mysql"
+	DBSystemPostgreSQL = "postgresql"
+	DBSystemMSSQL      = "mssql"
+	DBSystemOracle     = "oracle"
+	DBSystemNeo4j      = "neo4j"
+	DBSystemDgraph     = "dgraph"
+	DBSystemInfluxDB   = "influxdb"
 )
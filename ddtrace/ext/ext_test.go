@@ -29,6 +29,13 @@ func TestSpec(t *testing.T) {
 		SQLQuery, "sql.query",
 		HTTPURL, "http.url",
 		Environment, "env",
+		SpanKind, "span.kind",
+		SpanKindServer, "server",
+		SpanKindClient, "client",
+		SpanKindProducer, "producer",
+		SpanKindConsumer, "consumer",
+		SpanKindInternal, "internal",
+		Component, "component",
 	}
 	if len(tests)%2 != 0 {
 		t.Fatal("uneven test count")
@@ -0,0 +1,30 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package ext
+
+// Span kinds are the values of the SpanKind tag. They describe the relationship
+// between a span and its remote or asynchronous counterparts, which is used to infer
+// the dependencies between services.
+const (
+	// SpanKindServer marks a span handling a synchronous request, such as an
+	// incoming HTTP request or RPC. Its parent, if any, is a remote client span.
+	SpanKindServer = "server"
+
+	// SpanKindClient marks a span making a synchronous request to a remote service,
+	// such as an outgoing HTTP request, RPC or database query.
+	SpanKindClient = "client"
+
+	// SpanKindProducer marks a span sending a message to a broker, to be processed
+	// asynchronously by consumers.
+	SpanKindProducer = "producer"
+
+	// SpanKindConsumer marks a span processing a message received from a broker.
+	SpanKindConsumer = "consumer"
+
+	// SpanKindInternal marks a span representing an operation which stays within
+	// the application. It is the default when no kind is set.
+	SpanKindInternal = "internal"
+)
@@ -42,6 +42,13 @@ const (
 	// HTTPClientIP sets the HTTP client IP tag.
 	HTTPClientIP = "http.client_ip"
 
+	// HTTPVersion is the version of the HTTP protocol used by the request (e.g. "1.1", "2" or "3").
+	HTTPVersion = "http.version"
+
+	// HTTPStreamError holds the error which terminated the HTTP/2 or HTTP/3 stream
+	// carrying the request before its response was completed.
+	HTTPStreamError = "http.stream.error"
+
 	// SpanName is a pseudo-key for setting a span's operation name by means of
 	// a tag. It is mostly here to facilitate vendor-agnostic frameworks like Opentracing
 	// and OpenCensus.
@@ -50,6 +57,14 @@ const (
 	// SpanType defines the Span type (web, db, cache).
 	SpanType = "span.type"
 
+	// SpanKind defines the role of the span in a remote or asynchronous interaction,
+	// using one of the SpanKind* constants.
+	SpanKind = "span.kind"
+
+	// Component defines the integration which created the span, as the path of its
+	// contrib package, e.g. "gorilla/mux".
+	Component = "component"
+
 	// ServiceName defines the Service name for this Span.
 	ServiceName = "service.name"
 
@@ -79,10 +94,16 @@ const (
 
 	// EventSampleRate specifies the rate at which this span will be sampled
 	// as an APM event.
+	//
+	// Deprecated: App Analytics is replaced by ingestion controls and retention
+	// filters. Use ManualKeep to keep the trace of a span.
 	EventSampleRate = "_dd1.sr.eausr"
 
 	// AnalyticsEvent specifies whether the span should be recorded as a Trace
 	// Search & Analytics event.
+	//
+	// Deprecated: App Analytics is replaced by ingestion controls and retention
+	// filters. Use ManualKeep to keep the trace of a span.
 	AnalyticsEvent = "analytics.event"
 
 	// ManualKeep is a tag which specifies that the trace to which this span
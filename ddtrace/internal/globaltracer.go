@@ -30,6 +30,16 @@ func SetGlobalTracer(t ddtrace.Tracer) {
 	}
 }
 
+// SwapGlobalTracer sets the global tracer to t and returns the previous one, which is
+// left for the caller to stop.
+func SwapGlobalTracer(t ddtrace.Tracer) ddtrace.Tracer {
+	mu.Lock()
+	defer mu.Unlock()
+	old := globalTracer
+	globalTracer = t
+	return old
+}
+
 // GetGlobalTracer returns the currently active tracer.
 func GetGlobalTracer() ddtrace.Tracer {
 	mu.RLock()
@@ -46,6 +46,9 @@ type Span interface {
 	// Context returns the span's SpanContext.
 	Context() ddtrace.SpanContext
 
+	// Links returns the links to other spans set on the span.
+	Links() []ddtrace.SpanLink
+
 	// Stringer allows pretty-printing the span's fields for debugging.
 	fmt.Stringer
 }
@@ -60,6 +63,7 @@ func newSpan(t *mocktracer, operationName string, cfg *ddtrace.StartSpanConfig)
 	s := &mockspan{
 		name:   operationName,
 		tracer: t,
+		links:  append([]ddtrace.SpanLink(nil), cfg.SpanLinks...),
 	}
 	if cfg.StartTime.IsZero() {
 		s.startTime = time.Now()
@@ -106,6 +110,7 @@ type mockspan struct {
 	parentID  uint64
 	context   *spanContext
 	tracer    *mocktracer
+	links     []ddtrace.SpanLink
 }
 
 // SetTag sets a given tag on the span.
@@ -231,3 +236,20 @@ baggage: %#v
 
 // Context returns the SpanContext of this Span.
 func (s *mockspan) Context() ddtrace.SpanContext { return s.context }
+
+// Links returns the links to other spans set on the span.
+func (s *mockspan) Links() []ddtrace.SpanLink {
+	s.RLock()
+	defer s.RUnlock()
+	return s.links
+}
+
+// AddSpanLinks adds links to the span.
+func (s *mockspan) AddSpanLinks(links ...ddtrace.SpanLink) {
+	s.Lock()
+	defer s.Unlock()
+	if s.finished {
+		return
+	}
+	s.links = append(s.links, links...)
+}
@@ -85,6 +85,9 @@ func TestSpanSetTagPriority(t *testing.T) {
 	s.SetTag(ext.SamplingPriority, -1)
 	assert.True(s.context.hasSamplingPriority())
 	assert.Equal(-1, s.context.samplingPriority())
+	p, ok := s.context.SamplingPriority()
+	assert.True(ok)
+	assert.Equal(-1, p)
 }
 
 func TestSpanTagImmutability(t *testing.T) {
@@ -198,3 +201,19 @@ func TestSpanWithID(t *testing.T) {
 	assert := assert.New(t)
 	assert.Equal(spanID, span.Context().SpanID())
 }
+
+func TestSpanWithLinks(t *testing.T) {
+	links := []ddtrace.SpanLink{{TraceID: 1, SpanID: 2}}
+	span := newMockTracer().StartSpan("", tracer.WithSpanLinks(links))
+
+	assert := assert.New(t)
+	assert.Equal(links, span.(Span).Links())
+	assert.Empty(basicSpan("").Links())
+
+	tracer.AddSpanLinks(span, ddtrace.SpanLink{TraceID: 3, SpanID: 4})
+	assert.Equal([]ddtrace.SpanLink{{TraceID: 1, SpanID: 2}, {TraceID: 3, SpanID: 4}}, span.(Span).Links())
+	assert.Len(links, 1)
+	span.Finish()
+	tracer.AddSpanLinks(span, ddtrace.SpanLink{TraceID: 5, SpanID: 6})
+	assert.Len(span.(Span).Links(), 2)
+}
@@ -73,6 +73,14 @@ func (sc *spanContext) samplingPriority() int {
 	return sc.priority
 }
 
+// SamplingPriority returns the sampling priority of the span context. A second return value
+// indicates if the priority was set.
+func (sc *spanContext) SamplingPriority() (p int, ok bool) {
+	sc.RLock()
+	defer sc.RUnlock()
+	return sc.priority, sc.hasPriority
+}
+
 var mockIDSource uint64 = 123
 
 func nextID() uint64 { return atomic.AddUint64(&mockIDSource, 1) }
@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package pagination provides functions to trace the page fetches of paginated APIs, such as
+// the pagers and iterators of the Google Cloud and AWS SDKs. The listing is traced as a whole,
+// and each of its page fetches as a child span recording the index of the page and the number
+// of items fetched so far, so that slow pages show up even when the listing is cut short:
+//
+//	pager, ctx := pagination.Start(ctx, "s3.ListObjectsV2")
+//	p := s3.NewListObjectsV2Paginator(client, input)
+//	var err error
+//	for p.HasMorePages() && err == nil {
+//		err = pager.Next(ctx, func(ctx context.Context) (int, error) {
+//			page, err := p.NextPage(ctx)
+//			if err != nil {
+//				return 0, err
+//			}
+//			process(page.Contents)
+//			return len(page.Contents), nil
+//		})
+//	}
+//	pager.Finish(err)
+package pagination // import "github.com/codebrick-corp/dd-trace-go/ddtrace/pagination"
+
+import (
+	"context"
+	"sync"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+const (
+	// OperationName is the name of the spans of paginated listings.
+	OperationName = "pagination"
+	// PageOperationName is the name of the spans of page fetches.
+	PageOperationName = "pagination.page"
+
+	// TagPageIndex holds the index of a fetched page, starting at 0.
+	TagPageIndex = "pagination.page_index"
+	// TagPageItems holds the number of items of a fetched page.
+	TagPageItems = "pagination.page_items"
+	// TagPages holds the number of pages fetched by a listing.
+	TagPages = "pagination.pages"
+	// TagItems holds the number of items fetched by a listing, up to and including the page
+	// on the spans of page fetches.
+	TagItems = "pagination.items"
+)
+
+// Pager traces the page fetches of a paginated listing. It is safe for concurrent use.
+type Pager struct {
+	span ddtrace.Span
+	name string
+
+	mu    sync.Mutex // guards below fields
+	pages int
+	items int
+}
+
+// Start starts the span of a listing, with its resource set to name, as a child of the span
+// in ctx. The returned context holds the span of the listing. The listing must be ended by
+// calling Finish.
+func Start(ctx context.Context, name string, opts ...ddtrace.StartSpanOption) (*Pager, context.Context) {
+	span, ctx := tracer.StartSpanFromContext(ctx, OperationName, append([]ddtrace.StartSpanOption{tracer.ResourceName(name)}, opts...)...)
+	return &Pager{span: span, name: name}, ctx
+}
+
+// Next traces the page fetch made by fetch, which returns the number of items of the page.
+// The fetch is traced as a child of the span in ctx, which is usually the context returned
+// by Start, and fetch receives the context of its span. It returns the error of fetch.
+func (p *Pager) Next(ctx context.Context, fetch func(ctx context.Context) (items int, err error)) error {
+	p.mu.Lock()
+	index := p.pages
+	p.pages++
+	p.mu.Unlock()
+
+	span, ctx := tracer.StartSpanFromContext(ctx, PageOperationName,
+		tracer.ResourceName(p.name),
+		tracer.Tag(TagPageIndex, index),
+	)
+	n, err := fetch(ctx)
+
+	p.mu.Lock()
+	p.items += n
+	total := p.items
+	p.mu.Unlock()
+
+	span.SetTag(TagPageItems, n)
+	span.SetTag(TagItems, total)
+	span.Finish(tracer.WithError(err))
+	return err
+}
+
+// Pages returns the number of pages fetched so far.
+func (p *Pager) Pages() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.pages
+}
+
+// Items returns the number of items fetched so far.
+func (p *Pager) Items() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.items
+}
+
+// Finish finishes the span of the listing, recording the number of pages and items fetched,
+// and err, which is the error ending the listing, if any.
+func (p *Pager) Finish(err error) {
+	p.mu.Lock()
+	p.span.SetTag(TagPages, p.pages)
+	p.span.SetTag(TagItems, p.items)
+	p.mu.Unlock()
+	p.span.Finish(tracer.WithError(err))
+}
@@ -0,0 +1,84 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package pagination
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func TestPager(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	parent, ctx := tracer.StartSpanFromContext(context.Background(), "http.request")
+	pager, ctx := Start(ctx, "storage.Objects", tracer.ServiceName("gcs"))
+	for _, n := range []int{100, 100, 42} {
+		n := n
+		err := pager.Next(ctx, func(ctx context.Context) (int, error) {
+			span, ok := tracer.SpanFromContext(ctx)
+			require.True(t, ok)
+			assert.Equal(PageOperationName, span.(mocktracer.Span).OperationName())
+			return n, nil
+		})
+		require.NoError(t, err)
+	}
+	assert.Equal(3, pager.Pages())
+	assert.Equal(242, pager.Items())
+	pager.Finish(nil)
+	parent.Finish()
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 5)
+	listing := spans[3]
+	assert.Equal(OperationName, listing.OperationName())
+	assert.Equal("storage.Objects", listing.Tag(ext.ResourceName))
+	assert.Equal("gcs", listing.Tag(ext.ServiceName))
+	assert.Equal(parent.Context().SpanID(), listing.ParentID())
+	assert.Equal(3, listing.Tag(TagPages))
+	assert.Equal(242, listing.Tag(TagItems))
+	assert.Nil(listing.Tag(ext.Error))
+
+	for i, want := range []struct{ items, total int }{{100, 100}, {100, 200}, {42, 242}} {
+		page := spans[i]
+		assert.Equal(PageOperationName, page.OperationName())
+		assert.Equal("storage.Objects", page.Tag(ext.ResourceName))
+		assert.Equal(listing.SpanID(), page.ParentID())
+		assert.Equal(i, page.Tag(TagPageIndex))
+		assert.Equal(want.items, page.Tag(TagPageItems))
+		assert.Equal(want.total, page.Tag(TagItems))
+	}
+}
+
+func TestPagerError(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	errThrottled := errors.New("throttled")
+	pager, ctx := Start(context.Background(), "s3.ListObjectsV2")
+	require.NoError(t, pager.Next(ctx, func(context.Context) (int, error) { return 1000, nil }))
+	err := pager.Next(ctx, func(context.Context) (int, error) { return 0, errThrottled })
+	assert.Equal(errThrottled, err)
+	pager.Finish(err)
+
+	spans := mt.FinishedSpans()
+	require.Len(t, spans, 3)
+	assert.Equal(errThrottled, spans[1].Tag(ext.Error))
+	assert.Equal(1000, spans[1].Tag(TagItems))
+	assert.Equal(errThrottled, spans[2].Tag(ext.Error))
+	assert.Equal(2, spans[2].Tag(TagPages))
+	assert.Equal(1000, spans[2].Tag(TagItems))
+}
@@ -0,0 +1,141 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package retry provides functions to trace retried operations, recording on their span
+// the number of attempts made and the total time spent backing off between them.
+//
+// Retry runs and traces an operation following a retry Policy:
+//
+//	err := retry.Retry(ctx, "charge", func(ctx context.Context) error {
+//		return payments.Charge(ctx, order)
+//	}, retry.Policy{Backoff: []time.Duration{100 * time.Millisecond, time.Second}})
+//
+// Retry libraries may instead annotate the span of an operation with a Recorder, calling
+// its StartAttempt and EndAttempt methods around every attempt.
+package retry // import "github.com/codebrick-corp/dd-trace-go/ddtrace/retry"
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+const (
+	// OperationName is the name of the spans of retried operations.
+	OperationName = "retry"
+	// AttemptOperationName is the name of the spans of the attempts started by Retry.
+	AttemptOperationName = "retry.attempt"
+
+	// TagAttempts holds the number of attempts made by a retried operation.
+	TagAttempts = "retry.attempts"
+	// TagAttempt holds the number of an attempt, starting at 1.
+	TagAttempt = "retry.attempt_number"
+	// TagBackoff holds the total time in nanoseconds spent backing off between the attempts
+	// of a retried operation.
+	TagBackoff = "retry.backoff"
+)
+
+// Recorder records the attempts of a retried operation on its span, along with the time
+// spent backing off between them. It is safe for concurrent use.
+type Recorder struct {
+	span ddtrace.Span
+
+	mu       sync.Mutex // guards below fields
+	attempts int
+	backoff  time.Duration
+	lastEnd  time.Time // end of the last attempt
+}
+
+// NewRecorder returns a Recorder annotating span.
+func NewRecorder(span ddtrace.Span) *Recorder {
+	return &Recorder{span: span}
+}
+
+// RecorderFromContext returns a Recorder annotating the span found in ctx, if any. The
+// same Recorder must be used for all the attempts of an operation.
+func RecorderFromContext(ctx context.Context) (*Recorder, bool) {
+	span, ok := tracer.SpanFromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+	return NewRecorder(span), true
+}
+
+// StartAttempt records the start of an attempt and returns its number, starting at 1.
+// The time elapsed since the end of the previous attempt is recorded as backoff.
+func (r *Recorder) StartAttempt() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.attempts++
+	r.span.SetTag(TagAttempts, r.attempts)
+	if !r.lastEnd.IsZero() {
+		r.backoff += time.Since(r.lastEnd)
+		r.span.SetTag(TagBackoff, r.backoff.Nanoseconds())
+	}
+	return r.attempts
+}
+
+// EndAttempt records the end of the current attempt.
+func (r *Recorder) EndAttempt() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastEnd = time.Now()
+}
+
+// Attempts returns the number of attempts recorded so far.
+func (r *Recorder) Attempts() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.attempts
+}
+
+// Backoff returns the total time spent backing off recorded so far.
+func (r *Recorder) Backoff() time.Duration {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.backoff
+}
+
+// Policy specifies how Retry retries an operation.
+type Policy struct {
+	// Backoff holds the durations to wait for before each retry. The operation is
+	// attempted at most len(Backoff)+1 times.
+	Backoff []time.Duration
+
+	// Retryable reports whether an attempt which failed with err should be retried.
+	// When nil, all errors are retried.
+	Retryable func(err error) bool
+}
+
+// Retry runs fn until it succeeds or policy stops retrying it, and returns the error of its
+// last attempt. The operation is traced as a child of the span in ctx, with its resource set
+// to name, and each of its attempts as a child of the operation's span. fn receives the
+// context of the span of its attempt. Retry stops backing off when ctx is done, returning
+// ctx.Err().
+func Retry(ctx context.Context, name string, fn func(ctx context.Context) error, policy Policy, opts ...ddtrace.StartSpanOption) (err error) {
+	span, ctx := tracer.StartSpanFromContext(ctx, OperationName, append([]ddtrace.StartSpanOption{tracer.ResourceName(name)}, opts...)...)
+	defer func() { span.Finish(tracer.WithError(err)) }()
+	rec := NewRecorder(span)
+	for i := 0; ; i++ {
+		n := rec.StartAttempt()
+		aspan, actx := tracer.StartSpanFromContext(ctx, AttemptOperationName, tracer.ResourceName(name), tracer.Tag(TagAttempt, n))
+		err = fn(actx)
+		aspan.Finish(tracer.WithError(err))
+		rec.EndAttempt()
+		if err == nil || i >= len(policy.Backoff) || (policy.Retryable != nil && !policy.Retryable(err)) {
+			return err
+		}
+		t := time.NewTimer(policy.Backoff[i])
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,137 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+var errTransient = errors.New("transient")
+
+func TestRetry(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		assert := assert.New(t)
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		parent, ctx := tracer.StartSpanFromContext(context.Background(), "http.request")
+		var calls int
+		err := Retry(ctx, "charge", func(ctx context.Context) error {
+			calls++
+			span, ok := tracer.SpanFromContext(ctx)
+			require.True(t, ok)
+			assert.Equal(AttemptOperationName, span.(mocktracer.Span).OperationName())
+			if calls < 3 {
+				return errTransient
+			}
+			return nil
+		}, Policy{Backoff: []time.Duration{time.Millisecond, 2 * time.Millisecond, time.Hour}})
+		parent.Finish()
+		require.NoError(t, err)
+		assert.Equal(3, calls)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 5)
+		op := spans[3]
+		assert.Equal(OperationName, op.OperationName())
+		assert.Equal("charge", op.Tag(ext.ResourceName))
+		assert.Equal(parent.Context().SpanID(), op.ParentID())
+		assert.Equal(3, op.Tag(TagAttempts))
+		assert.GreaterOrEqual(op.Tag(TagBackoff).(int64), int64(3*time.Millisecond))
+		assert.Nil(op.Tag(ext.Error))
+		for i, s := range spans[:3] {
+			assert.Equal(op.SpanID(), s.ParentID())
+			assert.Equal(i+1, s.Tag(TagAttempt))
+		}
+		assert.Equal(errTransient, spans[0].Tag(ext.Error))
+		assert.Nil(spans[2].Tag(ext.Error))
+	})
+
+	t.Run("exhausted", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		err := Retry(context.Background(), "charge", func(ctx context.Context) error {
+			return errTransient
+		}, Policy{Backoff: []time.Duration{time.Millisecond}})
+		assert.Equal(t, errTransient, err)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 3)
+		assert.Equal(t, 2, spans[2].Tag(TagAttempts))
+		assert.Equal(t, errTransient, spans[2].Tag(ext.Error))
+	})
+
+	t.Run("not-retryable", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		errFatal := errors.New("fatal")
+		err := Retry(context.Background(), "charge", func(ctx context.Context) error {
+			return errFatal
+		}, Policy{
+			Backoff:   []time.Duration{time.Millisecond},
+			Retryable: func(err error) bool { return err != errFatal },
+		})
+		assert.Equal(t, errFatal, err)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, 1, spans[1].Tag(TagAttempts))
+		assert.Nil(t, spans[1].Tag(TagBackoff))
+	})
+
+	t.Run("canceled", func(t *testing.T) {
+		mt := mocktracer.Start()
+		defer mt.Stop()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		err := Retry(ctx, "charge", func(ctx context.Context) error {
+			cancel()
+			return errTransient
+		}, Policy{Backoff: []time.Duration{time.Hour}})
+		assert.Equal(t, context.Canceled, err)
+
+		spans := mt.FinishedSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, context.Canceled, spans[1].Tag(ext.Error))
+	})
+}
+
+func TestRecorder(t *testing.T) {
+	assert := assert.New(t)
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	span, ctx := tracer.StartSpanFromContext(context.Background(), "op")
+	rec, ok := RecorderFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(1, rec.StartAttempt())
+	rec.EndAttempt()
+	time.Sleep(time.Millisecond)
+	assert.Equal(2, rec.StartAttempt())
+	rec.EndAttempt()
+	span.Finish()
+
+	assert.Equal(2, rec.Attempts())
+	assert.GreaterOrEqual(rec.Backoff(), time.Millisecond)
+	s := mt.FinishedSpans()[0]
+	assert.Equal(2, s.Tag(TagAttempts))
+	assert.Equal(rec.Backoff().Nanoseconds(), s.Tag(TagBackoff))
+
+	_, ok = RecorderFromContext(context.Background())
+	assert.False(ok)
+}
@@ -0,0 +1,121 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/internal"
+)
+
+// keyDroppedAttributes is the metric holding the number of tags which were not set on a
+// span because it reached the attribute count limit.
+const keyDroppedAttributes = "_dd.span.dropped_attributes"
+
+// attributeLimits holds the limits applied to the tags set on spans, compatible with the
+// span limits of OpenTelemetry. A zero limit means no limit.
+type attributeLimits struct {
+	// count is the maximum number of tags of a span, not counting the tags which are
+	// not subject to the limits (see isLimitedAttribute).
+	count int
+	// valueLength is the maximum length in bytes of the string values of tags.
+	valueLength int
+}
+
+// attributeLimitEnv returns the value of the first of the given env variables which is
+// set to a positive integer, or zero.
+func attributeLimitEnv(keys ...string) int {
+	for _, k := range keys {
+		if v := internal.IntEnv(k, 0); v > 0 {
+			return v
+		}
+	}
+	return 0
+}
+
+// newAttributeLimits returns the attribute limits configured by the environment. The
+// Datadog variables take precedence over the OpenTelemetry span limits, which take
+// precedence over the OpenTelemetry general attribute limits.
+func newAttributeLimits() attributeLimits {
+	return attributeLimits{
+		count: attributeLimitEnv(
+			"DD_TRACE_SPAN_ATTRIBUTE_COUNT_LIMIT",
+			"OTEL_SPAN_ATTRIBUTE_COUNT_LIMIT",
+			"OTEL_ATTRIBUTE_COUNT_LIMIT",
+		),
+		valueLength: attributeLimitEnv(
+			"DD_TRACE_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT",
+			"OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT",
+			"OTEL_ATTRIBUTE_VALUE_LENGTH_LIMIT",
+		),
+	}
+}
+
+// isLimitedAttribute reports whether the tag key is subject to the attribute limits.
+// The internal tags, the tags set by the tracer on every span and the tags setting the
+// fields of a span are not.
+func isLimitedAttribute(key string) bool {
+	switch key {
+	case ext.SpanName, ext.ServiceName, ext.ResourceName, ext.SpanType,
+		ext.Pid, ext.RuntimeID, ext.Version, ext.Environment, "language",
+		ext.ErrorMsg, ext.ErrorType, ext.ErrorStack, ext.ErrorDetails:
+		return false
+	}
+	return !strings.HasPrefix(key, "_")
+}
+
+// allowAttribute reports whether the tag key can be set on s without exceeding the
+// attribute count limit, counting the tag as dropped when it can't. s must be locked.
+func (s *span) allowAttribute(key string) bool {
+	l := s.attrLimits
+	if l == nil || l.count <= 0 || !isLimitedAttribute(key) {
+		return true
+	}
+	if len(s.Meta)+len(s.Metrics) < l.count {
+		return true
+	}
+	if _, ok := s.Meta[key]; ok {
+		return true
+	}
+	if _, ok := s.Metrics[key]; ok {
+		return true
+	}
+	n := 0
+	for k := range s.Meta {
+		if isLimitedAttribute(k) {
+			n++
+		}
+	}
+	for k := range s.Metrics {
+		if isLimitedAttribute(k) {
+			n++
+		}
+	}
+	if n < l.count {
+		return true
+	}
+	if s.Metrics == nil {
+		s.Metrics = make(map[string]float64, 1)
+	}
+	s.Metrics[keyDroppedAttributes]++
+	return false
+}
+
+// limitValue returns v truncated to the attribute value length limit when the tag key
+// is subject to it, without splitting a UTF-8 encoded rune.
+func (s *span) limitValue(key, v string) string {
+	l := s.attrLimits
+	if l == nil || l.valueLength <= 0 || len(v) <= l.valueLength || !isLimitedAttribute(key) {
+		return v
+	}
+	i := l.valueLength
+	for i > 0 && !utf8.RuneStart(v[i]) {
+		i--
+	}
+	return v[:i]
+}
@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+)
+
+func TestAttributeLimitsEnv(t *testing.T) {
+	setenv := func(key, value string) func() {
+		old, ok := os.LookupEnv(key)
+		os.Setenv(key, value)
+		return func() {
+			if ok {
+				os.Setenv(key, old)
+			} else {
+				os.Unsetenv(key)
+			}
+		}
+	}
+
+	t.Run("default", func(t *testing.T) {
+		assert.Equal(t, attributeLimits{}, newConfig().attrLimits)
+	})
+
+	t.Run("otel", func(t *testing.T) {
+		defer setenv("OTEL_ATTRIBUTE_COUNT_LIMIT", "10")()
+		defer setenv("OTEL_ATTRIBUTE_VALUE_LENGTH_LIMIT", "100")()
+		defer setenv("OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT", "200")()
+		assert.Equal(t, attributeLimits{count: 10, valueLength: 200}, newConfig().attrLimits)
+	})
+
+	t.Run("dd", func(t *testing.T) {
+		defer setenv("OTEL_SPAN_ATTRIBUTE_COUNT_LIMIT", "10")()
+		defer setenv("DD_TRACE_SPAN_ATTRIBUTE_COUNT_LIMIT", "20")()
+		defer setenv("DD_TRACE_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT", "invalid")()
+		assert.Equal(t, attributeLimits{count: 20}, newConfig().attrLimits)
+	})
+
+	t.Run("option", func(t *testing.T) {
+		defer setenv("DD_TRACE_SPAN_ATTRIBUTE_COUNT_LIMIT", "20")()
+		c := newConfig(WithSpanAttributeLimits(5, 50))
+		assert.Equal(t, attributeLimits{count: 5, valueLength: 50}, c.attrLimits)
+	})
+}
+
+func TestAttributeLimits(t *testing.T) {
+	tracer, _, _, stop := startTestTracer(t, WithSpanAttributeLimits(3, 8))
+	defer stop()
+
+	t.Run("count", func(t *testing.T) {
+		assert := assert.New(t)
+		s := tracer.StartSpan("op", Tag("a", 1)).(*span)
+		s.SetTag("b", "b")
+		s.SetTag("c", true)
+		s.SetTag("d", 4)
+		s.SetTag("e", "e")
+		s.SetTag("a", "updated")
+		s.SetTag(ext.ResourceName, "resource name beyond the limits")
+		s.SetTag("_dd.internal", 1)
+		s.SetTag(ext.Error, fmt.Errorf("boom"))
+		s.Finish()
+
+		assert.Equal("updated", s.Meta["a"])
+		assert.Equal("b", s.Meta["b"])
+		assert.Equal("true", s.Meta["c"])
+		assert.NotContains(s.Metrics, "d")
+		assert.NotContains(s.Meta, "e")
+		assert.Equal(2.0, s.Metrics[keyDroppedAttributes])
+		assert.Equal("resource name beyond the limits", s.Resource)
+		assert.Equal(1.0, s.Metrics["_dd.internal"])
+		assert.Equal("boom", s.Meta[ext.ErrorMsg])
+	})
+
+	t.Run("length", func(t *testing.T) {
+		assert := assert.New(t)
+		s := tracer.StartSpan("op").(*span)
+		s.SetTag("ascii", "0123456789")
+		s.SetTag("utf8", "0123456é")
+		s.SetTag("stringer", valueStringer("0123456789"))
+		s.SetTag(ext.ServiceName, "service name beyond the limits")
+		s.Finish()
+
+		assert.Equal("01234567", s.Meta["ascii"])
+		assert.Equal("0123456", s.Meta["utf8"])
+		assert.Equal("01234567", s.Meta["stringer"])
+		assert.Equal("service name beyond the limits", s.Service)
+	})
+}
+
+type valueStringer string
+
+func (s valueStringer) String() string { return string(s) }
@@ -0,0 +1,90 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// auditInterval is the minimum interval between two warnings of the propagation audit.
+var auditInterval = time.Minute
+
+type headersContextKey struct{}
+
+// ContextWithHeaders returns a copy of ctx holding the propagation headers found in
+// carrier, e.g. the HTTPHeadersCarrier of an incoming request, for the propagation audit
+// enabled with WithPropagationAudit. Frameworks and middlewares receiving requests can
+// call it so that the spans started from their context without a parent are reported,
+// as the HTTP and messaging integrations do when the headers they receive are malformed.
+// It has no effect on the spans themselves: use Extract to continue a trace.
+func ContextWithHeaders(ctx context.Context, carrier TextMapReader) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, headersContextKey{}, carrier)
+}
+
+// propagationAudit rate-limits the warnings logged by auditPropagation.
+type propagationAudit struct {
+	mu         sync.Mutex // guards below fields
+	last       time.Time  // time of the last warning
+	suppressed int        // occurrences left out since the last warning
+}
+
+// allow reports whether a warning can be logged now, along with the number of
+// occurrences left out since the previous one.
+func (a *propagationAudit) allow() (bool, int) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if now := time.Now(); a.last.IsZero() || now.Sub(a.last) >= auditInterval {
+		n := a.suppressed
+		a.last, a.suppressed = now, 0
+		return true, n
+	}
+	a.suppressed++
+	return false, 0
+}
+
+// auditPropagation logs a warning if s, started from ctx by StartSpanFromContext, is the
+// root of a new trace although ctx holds propagation headers, whether their span context
+// was never extracted or they are malformed.
+func auditPropagation(ctx context.Context, operationName string, s ddtrace.Span) {
+	t, ok := internal.GetGlobalTracer().(*tracer)
+	if !ok || !t.config.propagationAudit || t.overhead.throttled() {
+		return
+	}
+	sp, ok := s.(*span)
+	if !ok || sp.ParentID != 0 {
+		return
+	}
+	carrier, ok := ctx.Value(headersContextKey{}).(TextMapReader)
+	if !ok {
+		return
+	}
+	upstream, err := t.Extract(carrier)
+	if err == ErrSpanContextNotFound {
+		return
+	}
+	allow, suppressed := t.audit.allow()
+	if !allow {
+		return
+	}
+	if err != nil {
+		log.Warn("Span %q was started as the root of a new trace although the context holds propagation headers: "+
+			"they could not be extracted: %v (%d similar occurrences left out).",
+			operationName, err, suppressed)
+		return
+	}
+	log.Warn("Span %q was started as the root of a new trace although the context holds the propagation headers of trace %d: "+
+		"the span context of the headers was never extracted (%d similar occurrences left out).",
+		operationName, upstream.TraceID(), suppressed)
+}
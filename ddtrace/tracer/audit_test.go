@@ -0,0 +1,136 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// auditWarnings returns the warnings of the propagation audit recorded by tl.
+func auditWarnings(tl *log.RecordLogger) []string {
+	var warnings []string
+	for _, l := range tl.Logs() {
+		if strings.Contains(l, "WARN:") && strings.Contains(l, "propagation headers") {
+			warnings = append(warnings, l)
+		}
+	}
+	return warnings
+}
+
+func TestPropagationAudit(t *testing.T) {
+	headers := http.Header{}
+	headers.Set(DefaultTraceIDHeader, "123")
+	headers.Set(DefaultParentIDHeader, "456")
+	ctx := ContextWithHeaders(context.Background(), HTTPHeadersCarrier(headers))
+
+	t.Run("broken", func(t *testing.T) {
+		tl := new(log.RecordLogger)
+		defer log.UseLogger(tl)()
+		_, _, _, stop := startTestTracer(t, WithPropagationAudit(true))
+		defer stop()
+
+		span, _ := StartSpanFromContext(ctx, "db.query")
+		span.Finish()
+
+		warnings := auditWarnings(tl)
+		require.Len(t, warnings, 1)
+		assert.Contains(t, warnings[0], `Span "db.query"`)
+		assert.Contains(t, warnings[0], "trace 123")
+	})
+
+	t.Run("extracted", func(t *testing.T) {
+		tl := new(log.RecordLogger)
+		defer log.UseLogger(tl)()
+		tracer, _, _, stop := startTestTracer(t, WithPropagationAudit(true))
+		defer stop()
+
+		sctx, err := tracer.Extract(HTTPHeadersCarrier(headers))
+		require.NoError(t, err)
+		parent, pctx := StartSpanFromContext(ctx, "http.request", ChildOf(sctx))
+		span, _ := StartSpanFromContext(pctx, "db.query")
+		span.Finish()
+		parent.Finish()
+
+		assert.Empty(t, auditWarnings(tl))
+	})
+
+	t.Run("no-headers", func(t *testing.T) {
+		tl := new(log.RecordLogger)
+		defer log.UseLogger(tl)()
+		_, _, _, stop := startTestTracer(t, WithPropagationAudit(true))
+		defer stop()
+
+		empty := ContextWithHeaders(context.Background(), HTTPHeadersCarrier(http.Header{}))
+		span, _ := StartSpanFromContext(empty, "db.query")
+		span.Finish()
+		span, _ = StartSpanFromContext(context.Background(), "db.query")
+		span.Finish()
+
+		assert.Empty(t, auditWarnings(tl))
+	})
+
+	t.Run("malformed", func(t *testing.T) {
+		tl := new(log.RecordLogger)
+		defer log.UseLogger(tl)()
+		_, _, _, stop := startTestTracer(t, WithPropagationAudit(true))
+		defer stop()
+
+		malformed := http.Header{}
+		malformed.Set(DefaultTraceIDHeader, "not-a-trace-id")
+		malformed.Set(DefaultParentIDHeader, "456")
+		span, _ := StartSpanFromContext(ContextWithHeaders(context.Background(), HTTPHeadersCarrier(malformed)), "http.request")
+		span.Finish()
+
+		warnings := auditWarnings(tl)
+		require.Len(t, warnings, 1)
+		assert.Contains(t, warnings[0], `Span "http.request"`)
+		assert.Contains(t, warnings[0], ErrSpanContextCorrupted.Error())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		tl := new(log.RecordLogger)
+		defer log.UseLogger(tl)()
+		_, _, _, stop := startTestTracer(t)
+		defer stop()
+
+		span, _ := StartSpanFromContext(ctx, "db.query")
+		span.Finish()
+
+		assert.Empty(t, auditWarnings(tl))
+	})
+
+	t.Run("rate-limited", func(t *testing.T) {
+		defer func(d time.Duration) { auditInterval = d }(auditInterval)
+		auditInterval = time.Hour
+		tl := new(log.RecordLogger)
+		defer log.UseLogger(tl)()
+		tracer, _, _, stop := startTestTracer(t, WithPropagationAudit(true))
+		defer stop()
+
+		for i := 0; i < 3; i++ {
+			span, _ := StartSpanFromContext(ctx, "db.query")
+			span.Finish()
+		}
+		require.Len(t, auditWarnings(tl), 1)
+
+		// the next warning reports the occurrences left out
+		tracer.audit.last = time.Now().Add(-2 * time.Hour)
+		span, _ := StartSpanFromContext(ctx, "db.query")
+		span.Finish()
+		warnings := auditWarnings(tl)
+		require.Len(t, warnings, 2)
+		assert.Contains(t, warnings[1], "2 similar occurrences left out")
+	})
+}
@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// defaultAgentUnreachableThreshold specifies the default duration during which the
+	// payloads must fail to be sent for the agent to be considered unreachable.
+	defaultAgentUnreachableThreshold = 30 * time.Second
+
+	// defaultAgentProbeInterval specifies the default interval at which an unreachable
+	// agent is probed.
+	defaultAgentProbeInterval = 10 * time.Second
+)
+
+// agentBreaker is a circuit breaker on the connectivity to the agent. It opens once the
+// payloads have failed to be sent for longer than threshold. While it is open, finished
+// traces are dropped without being encoded, except once every probeInterval, when they
+// are let through to be sent as a probe. It closes as soon as a payload is sent.
+// The zero value is a disabled breaker, which never opens.
+type agentBreaker struct {
+	threshold     time.Duration // zero disables the breaker
+	probeInterval time.Duration
+
+	open      int32 // 1 when the breaker is open; accessed atomically
+	nextProbe int64 // time in nanoseconds after which traces are let through; accessed atomically
+
+	mu           sync.Mutex // guards below fields
+	failingSince time.Time  // the time of the first failure since the last success
+	openSince    time.Time  // the time at which the breaker opened
+}
+
+func newAgentBreaker(threshold, probeInterval time.Duration) *agentBreaker {
+	return &agentBreaker{threshold: threshold, probeInterval: probeInterval}
+}
+
+// allow reports whether a finished trace should be encoded to be sent to the agent.
+// It is called for every trace and must stay cheap.
+func (b *agentBreaker) allow() bool {
+	if atomic.LoadInt32(&b.open) == 0 {
+		return true
+	}
+	return now() >= atomic.LoadInt64(&b.nextProbe)
+}
+
+// isOpen reports whether the agent is considered unreachable.
+func (b *agentBreaker) isOpen() bool {
+	return atomic.LoadInt32(&b.open) == 1
+}
+
+// since returns the time at which the breaker opened, or the zero time if it is closed.
+func (b *agentBreaker) since() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.openSince
+}
+
+// failure records that a payload failed to be sent and reports whether the breaker
+// opened because of it.
+func (b *agentBreaker) failure() (opened bool) {
+	if b.threshold <= 0 {
+		return false
+	}
+	n := time.Now()
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	atomic.StoreInt64(&b.nextProbe, n.Add(b.probeInterval).UnixNano())
+	if b.failingSince.IsZero() {
+		b.failingSince = n
+	}
+	if b.isOpen() || n.Sub(b.failingSince) < b.threshold {
+		return false
+	}
+	b.openSince = n
+	atomic.StoreInt32(&b.open, 1)
+	return true
+}
+
+// success records that a payload was sent and reports whether the breaker closed
+// because of it.
+func (b *agentBreaker) success() (closed bool) {
+	if b.threshold <= 0 {
+		return false
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failingSince = time.Time{}
+	if !b.isOpen() {
+		return false
+	}
+	b.openSince = time.Time{}
+	atomic.StoreInt32(&b.open, 0)
+	return true
+}
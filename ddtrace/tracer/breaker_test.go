@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAgentBreaker(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		var b agentBreaker
+		for i := 0; i < 10; i++ {
+			assert.False(t, b.failure())
+		}
+		assert.False(t, b.isOpen())
+		assert.True(t, b.allow())
+		assert.False(t, b.success())
+	})
+
+	t.Run("threshold", func(t *testing.T) {
+		b := newAgentBreaker(time.Hour, time.Hour)
+		assert.False(t, b.failure())
+		assert.False(t, b.isOpen())
+		assert.True(t, b.allow())
+
+		// the payloads have been failing for longer than the threshold
+		b.failingSince = b.failingSince.Add(-time.Hour)
+		assert.True(t, b.failure())
+		assert.True(t, b.isOpen())
+		assert.False(t, b.allow())
+		assert.False(t, b.since().IsZero())
+		// already open
+		assert.False(t, b.failure())
+
+		assert.True(t, b.success())
+		assert.False(t, b.isOpen())
+		assert.True(t, b.allow())
+		assert.True(t, b.since().IsZero())
+		assert.False(t, b.success())
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		b := newAgentBreaker(time.Hour, time.Hour)
+		b.failure()
+		b.success()
+		assert.True(t, b.failingSince.IsZero())
+		b.failure()
+		assert.False(t, b.isOpen())
+	})
+
+	t.Run("probe", func(t *testing.T) {
+		b := newAgentBreaker(time.Nanosecond, time.Hour)
+		b.failure()
+		b.failure()
+		assert.True(t, b.isOpen())
+		assert.False(t, b.allow())
+
+		// time to probe the agent
+		atomic.StoreInt64(&b.nextProbe, now())
+		assert.True(t, b.allow())
+		// the probe failed
+		b.failure()
+		assert.False(t, b.allow())
+	})
+}
+
+func TestAgentWriterBreaker(t *testing.T) {
+	var tg testStatsdClient
+	tr := &flakyTransport{dummyTransport: newDummyTransport(), fails: 3}
+	c := newConfig(withTransport(tr), withStatsdClient(&tg), WithSendRetries(0), WithAgentCircuitBreaker(time.Nanosecond, time.Hour))
+	h := newAgentTraceWriter(c, newPrioritySampler())
+	send := func(name string) {
+		h.add([]*span{newBasicSpan(name)})
+		h.flush()
+		h.wg.Wait()
+	}
+
+	send("a")
+	assert.False(t, h.breaker.isOpen())
+	send("b")
+	assert.True(t, h.breaker.isOpen())
+	assert.Equal(t, 1, tg.CallsByName()["datadog.tracer.agent_unreachable"])
+
+	// the trace is dropped without being encoded
+	send("c")
+	assert.Zero(t, h.payload.itemCount())
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&h.status.tracesSkipped))
+	assert.Empty(t, tr.Traces())
+
+	// the probe fails
+	atomic.StoreInt64(&h.breaker.nextProbe, now())
+	send("d")
+	assert.True(t, h.breaker.isOpen())
+	assert.Equal(t, uint64(1), atomic.LoadUint64(&h.status.tracesSkipped))
+
+	// the probe succeeds
+	atomic.StoreInt64(&h.breaker.nextProbe, now())
+	send("e")
+	assert.False(t, h.breaker.isOpen())
+	assert.Equal(t, 1, tg.CallsByName()["datadog.tracer.agent_reachable"])
+	traces := tr.Traces()
+	if assert.Len(t, traces, 1) {
+		assert.Equal(t, "e", traces[0][0].Name)
+	}
+	send("f")
+	assert.Len(t, tr.Traces(), 1)
+}
@@ -0,0 +1,48 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import "sync/atomic"
+
+// keyDroppedChildren is the metric holding the number of child spans of a span which were
+// dropped because the span reached the limit set with WithMaxChildSpans.
+const keyDroppedChildren = "_dd.span.dropped_children"
+
+// limitChildren returns the context from which a child of parent must be started, counting
+// the children of the local span of parent. Once limit children were started, it returns a
+// detached context instead, whose trace is never buffered nor sent, so that the child and
+// its own descendants are dropped; the overflow is counted on the span of parent. A limit of
+// zero or less disables the limit.
+func limitChildren(parent *spanContext, limit int) *spanContext {
+	if limit <= 0 || parent == nil || parent.span == nil {
+		return parent
+	}
+	p := parent.span
+	n := int(atomic.AddInt32(&p.children, 1))
+	if n <= limit {
+		return parent
+	}
+	p.Lock()
+	if !p.finished {
+		p.setMetric(keyDroppedChildren, float64(n-limit))
+	}
+	p.Unlock()
+	t := newTrace()
+	// a full trace doesn't keep track of its spans
+	t.full = true
+	detached := &spanContext{
+		trace:   t,
+		span:    p,
+		traceID: parent.traceID,
+		spanID:  parent.spanID,
+		origin:  parent.origin,
+	}
+	parent.ForeachBaggageItem(func(k, v string) bool {
+		detached.setBaggageItem(k, v)
+		return true
+	})
+	return detached
+}
@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxChildSpans(t *testing.T) {
+	t.Run("limited", func(t *testing.T) {
+		tracer, transport, flush, stop := startTestTracer(t, WithMaxChildSpans(2))
+		defer stop()
+
+		root := tracer.StartSpan("web.request").(*span)
+		root.SetBaggageItem("user", "alice")
+		for i := 0; i < 5; i++ {
+			child := tracer.StartSpan("db.query", ChildOf(root.Context()))
+			grandchild := tracer.StartSpan("db.fetch", ChildOf(child.Context()))
+			assert.Equal(t, root.TraceID, grandchild.Context().TraceID())
+			assert.Equal(t, "alice", grandchild.BaggageItem("user"))
+			grandchild.Finish()
+			child.Finish()
+		}
+		root.Finish()
+		flush(1)
+
+		traces := transport.Traces()
+		require.Len(t, traces, 1)
+		// the root, 2 children and their own children
+		assert.Len(t, traces[0], 5)
+		assert.Equal(t, 3.0, root.Metrics[keyDroppedChildren])
+		for _, s := range traces[0] {
+			assert.Equal(t, root.TraceID, s.TraceID)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		tracer, transport, flush, stop := startTestTracer(t)
+		defer stop()
+
+		root := tracer.StartSpan("web.request").(*span)
+		for i := 0; i < 5; i++ {
+			tracer.StartSpan("db.query", ChildOf(root.Context())).Finish()
+		}
+		root.Finish()
+		flush(1)
+
+		traces := transport.Traces()
+		require.Len(t, traces, 1)
+		assert.Len(t, traces[0], 6)
+		assert.NotContains(t, root.Metrics, keyDroppedChildren)
+	})
+}
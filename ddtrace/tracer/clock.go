@@ -0,0 +1,64 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import "time"
+
+const (
+	// keyClockSkew is the metric holding the difference in nanoseconds between the
+	// wall clock duration of a span and its duration according to the monotonic clock.
+	keyClockSkew = "_dd.clock_skew"
+	// keyTimestampCorrected holds the reason why the start time or the duration of a
+	// span were corrected.
+	keyTimestampCorrected = "_dd.timestamp_corrected"
+)
+
+const (
+	// clockSkewThreshold is the difference between the wall clock and the monotonic
+	// durations of a span from which the span is tagged with keyClockSkew.
+	clockSkewThreshold = int64(100 * time.Millisecond)
+
+	// maxTimestampLead is how far in the future the start and finish times set by the
+	// user are allowed to be.
+	maxTimestampLead = int64(time.Hour)
+)
+
+// checkStartTime returns the time in nanoseconds at which a span started at the time t
+// set by the user starts, along with the reason why it was corrected if it was.
+func checkStartTime(t time.Time) (start int64, corrected string) {
+	n := now()
+	switch {
+	case t.Unix() <= 0:
+		return n, "start_before_epoch"
+	case t.UnixNano()-n > maxTimestampLead:
+		return n, "start_in_future"
+	}
+	return t.UnixNano(), ""
+}
+
+// setDuration sets the duration of the span finishing at finishTime, or after elapsed
+// according to the monotonic clock if elapsed isn't negative. This method is not safe
+// for concurrent use.
+func (s *span) setDuration(finishTime int64, elapsed time.Duration) {
+	if n := now(); finishTime-n > maxTimestampLead {
+		s.setMeta(keyTimestampCorrected, "finish_in_future")
+		finishTime = n
+	}
+	d := finishTime - s.Start
+	if elapsed >= 0 {
+		if skew := d - int64(elapsed); skew > clockSkewThreshold || skew < -clockSkewThreshold {
+			// the wall clock jumped while the span was open
+			s.setMetric(keyClockSkew, float64(skew))
+			s.setMeta(keyTimestampCorrected, "clock_skew")
+		}
+		d = int64(elapsed)
+	}
+	if d < 0 {
+		s.setMeta(keyTimestampCorrected, "finish_before_start")
+		d = 0
+	}
+	s.Duration = d
+}
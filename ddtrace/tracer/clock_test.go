@@ -0,0 +1,108 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClockCorrection(t *testing.T) {
+	t.Run("monotonic", func(t *testing.T) {
+		tracer := newTracer()
+		defer tracer.Stop()
+
+		span := tracer.StartSpan("web.request").(*span)
+		assert.False(t, span.startMonotonic.IsZero())
+		// the wall clock jumps back by an hour
+		span.Start += int64(time.Hour)
+		span.Finish()
+		assert.True(t, span.Duration >= 0 && span.Duration < int64(time.Minute), span.Duration)
+		assert.InDelta(t, -float64(time.Hour), span.Metrics[keyClockSkew], float64(time.Minute))
+		assert.Equal(t, "clock_skew", span.Meta[keyTimestampCorrected])
+	})
+
+	t.Run("no-skew", func(t *testing.T) {
+		tracer := newTracer()
+		defer tracer.Stop()
+
+		span := tracer.StartSpan("web.request").(*span)
+		span.Finish()
+		assert.NotContains(t, span.Metrics, keyClockSkew)
+		assert.NotContains(t, span.Meta, keyTimestampCorrected)
+	})
+
+	t.Run("finish-time", func(t *testing.T) {
+		tracer := newTracer()
+		defer tracer.Stop()
+
+		span := tracer.StartSpan("web.request").(*span)
+		finish := time.Unix(0, span.Start).Add(time.Second)
+		span.Finish(FinishTime(finish))
+		assert.Equal(t, int64(time.Second), span.Duration)
+		assert.NotContains(t, span.Meta, keyTimestampCorrected)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		tracer := newTracer(WithClockCorrection(false))
+		defer tracer.Stop()
+
+		span := tracer.StartSpan("web.request").(*span)
+		assert.True(t, span.startMonotonic.IsZero())
+		span.Start -= int64(time.Hour)
+		span.Finish()
+		assert.True(t, span.Duration >= int64(time.Hour))
+		assert.NotContains(t, span.Metrics, keyClockSkew)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		os.Setenv("DD_TRACE_CLOCK_CORRECTION_ENABLED", "false")
+		defer os.Unsetenv("DD_TRACE_CLOCK_CORRECTION_ENABLED")
+		c := newConfig()
+		assert.False(t, c.clockCorrection)
+	})
+}
+
+func TestTimestampValidation(t *testing.T) {
+	tracer := newTracer()
+	defer tracer.Stop()
+
+	t.Run("start-before-epoch", func(t *testing.T) {
+		span := tracer.StartSpan("web.request", StartTime(time.Unix(0, 0))).(*span)
+		assert.InDelta(t, float64(now()), float64(span.Start), float64(time.Minute))
+		assert.Equal(t, "start_before_epoch", span.Meta[keyTimestampCorrected])
+	})
+
+	t.Run("start-in-future", func(t *testing.T) {
+		span := tracer.StartSpan("web.request", StartTime(time.Now().Add(24*time.Hour))).(*span)
+		assert.InDelta(t, float64(now()), float64(span.Start), float64(time.Minute))
+		assert.Equal(t, "start_in_future", span.Meta[keyTimestampCorrected])
+	})
+
+	t.Run("start-in-past", func(t *testing.T) {
+		start := time.Now().Add(-24 * time.Hour)
+		span := tracer.StartSpan("web.request", StartTime(start)).(*span)
+		assert.Equal(t, start.UnixNano(), span.Start)
+		assert.NotContains(t, span.Meta, keyTimestampCorrected)
+	})
+
+	t.Run("finish-before-start", func(t *testing.T) {
+		span := tracer.StartSpan("web.request").(*span)
+		span.Finish(FinishTime(time.Unix(0, span.Start).Add(-time.Second)))
+		assert.Equal(t, int64(0), span.Duration)
+		assert.Equal(t, "finish_before_start", span.Meta[keyTimestampCorrected])
+	})
+
+	t.Run("finish-in-future", func(t *testing.T) {
+		span := tracer.StartSpan("web.request").(*span)
+		span.Finish(FinishTime(time.Now().Add(24 * time.Hour)))
+		assert.True(t, span.Duration < int64(time.Minute), span.Duration)
+		assert.Equal(t, "finish_in_future", span.Meta[keyTimestampCorrected])
+	})
+}
@@ -0,0 +1,59 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+)
+
+// keyComponentVersion holds the version of the library instrumented by the integration
+// which created the span, as named by the component tag of the span.
+const keyComponentVersion = "_dd.component.version"
+
+// componentSpans counts the spans created by each integration, keyed by their component
+// tag, which helps in finding out duplicate instrumentation.
+type componentSpans struct {
+	counts sync.Map // string -> *int64
+}
+
+// add records the span s created by the integration named by its component tag, if any,
+// setting the version of the instrumented library on s.
+func (c *componentSpans) add(s *span) {
+	component, ok := s.Meta[ext.Component]
+	if !ok {
+		return
+	}
+	if v := integrations.Version(component); v != "" {
+		s.setMeta(keyComponentVersion, v)
+	}
+	n, ok := c.counts.Load(component)
+	if !ok {
+		n, _ = c.counts.LoadOrStore(component, new(int64))
+	}
+	atomic.AddInt64(n.(*int64), 1)
+}
+
+// report sends the number of spans created by each integration since the last report
+// with the statsd client of t.
+func (c *componentSpans) report(t *tracer) {
+	c.counts.Range(func(k, v interface{}) bool {
+		n := atomic.SwapInt64(v.(*int64), 0)
+		if n == 0 {
+			return true
+		}
+		component := k.(string)
+		tags := []string{"integration_name:" + component}
+		if v := integrations.Version(component); v != "" {
+			tags = append(tags, "integration_version:"+v)
+		}
+		t.config.statsd.Count("datadog.tracer.integration.spans_created", n, tags, 1)
+		return true
+	})
+}
@@ -0,0 +1,47 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponentSpans(t *testing.T) {
+	var tg testStatsdClient
+	tracer, _, _, stop := startTestTracer(t, withStatsdClient(&tg))
+	defer stop()
+
+	for i := 0; i < 3; i++ {
+		tracer.StartSpan("http.request", Tag(ext.Component, "test/http")).Finish()
+	}
+	tracer.StartSpan("redis.command", Tag(ext.Component, "test/redis")).Finish()
+	s := tracer.StartSpan("operation").(*span)
+	s.Finish()
+	assert.NotContains(t, s.Meta, keyComponentVersion)
+
+	tg.Reset()
+	tracer.components.report(tracer)
+	counts := make(map[string]int64)
+	for _, c := range tg.CountCalls() {
+		assert.Equal(t, "datadog.tracer.integration.spans_created", c.name)
+		if assert.Len(t, c.tags, 1) {
+			counts[c.tags[0]] = c.intVal
+		}
+	}
+	assert.Equal(t, map[string]int64{
+		"integration_name:test/http":  3,
+		"integration_name:test/redis": 1,
+	}, counts)
+
+	// the counts are reset by each report
+	tg.Reset()
+	tracer.components.report(tracer)
+	assert.Empty(t, tg.CountCalls())
+}
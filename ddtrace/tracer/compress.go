@@ -0,0 +1,120 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/zstd"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// Encodings of the trace payloads, set with WithPayloadCompression.
+const (
+	encodingGzip = "gzip"
+	encodingZstd = "zstd"
+)
+
+// compressor compresses the trace payloads sent to the agent, as long as the agent
+// accepts them.
+type compressor struct {
+	encoding string
+	gzipPool sync.Pool     // *gzip.Writer, when encoding is gzip
+	zstdEnc  *zstd.Encoder // when encoding is zstd; safe for concurrent use with EncodeAll
+
+	// accepted is set to 1 once the agent accepted a compressed payload, and rejected
+	// once it rejected one without accepting any before. Payloads are no longer
+	// compressed once rejected is set.
+	accepted, rejected int32
+}
+
+// newCompressor returns a compressor using the given encoding and level, or nil if
+// encoding is empty, "none" or invalid. A level of zero selects the default level of
+// the encoding.
+func newCompressor(encoding string, level int) *compressor {
+	c := &compressor{encoding: strings.ToLower(encoding)}
+	switch c.encoding {
+	case "", "none":
+		return nil
+	case encodingGzip:
+		if level == 0 {
+			level = gzip.DefaultCompression
+		}
+		if level < gzip.HuffmanOnly || level > gzip.BestCompression {
+			log.Warn("Invalid gzip compression level %d, using the default level", level)
+			level = gzip.DefaultCompression
+		}
+		c.gzipPool.New = func() interface{} {
+			w, _ := gzip.NewWriterLevel(ioutil.Discard, level)
+			return w
+		}
+	case encodingZstd:
+		opts := []zstd.EOption{zstd.WithEncoderConcurrency(1)}
+		if level != 0 {
+			opts = append(opts, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+		}
+		enc, err := zstd.NewWriter(nil, opts...)
+		if err != nil {
+			log.Error("Payload compression disabled: %v", err)
+			return nil
+		}
+		c.zstdEnc = enc
+	default:
+		log.Warn("Unsupported payload compression %q, payloads won't be compressed", encoding)
+		return nil
+	}
+	return c
+}
+
+// enabled reports whether payloads should be compressed.
+func (c *compressor) enabled() bool {
+	return c != nil && atomic.LoadInt32(&c.rejected) == 0
+}
+
+// compress returns the content of r compressed.
+func (c *compressor) compress(r io.Reader) (*bytes.Buffer, error) {
+	var buf bytes.Buffer
+	if c.zstdEnc != nil {
+		src, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(c.zstdEnc.EncodeAll(src, make([]byte, 0, len(src)/4)))
+		return &buf, nil
+	}
+	w := c.gzipPool.Get().(*gzip.Writer)
+	defer c.gzipPool.Put(w)
+	w.Reset(&buf)
+	if _, err := io.Copy(w, r); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return &buf, nil
+}
+
+// accept records that the agent accepted a compressed payload.
+func (c *compressor) accept() {
+	atomic.StoreInt32(&c.accepted, 1)
+}
+
+// reject records that the agent rejected a compressed payload, which disables the
+// compression unless the agent accepted compressed payloads before. It reports whether
+// the compression was disabled.
+func (c *compressor) reject() bool {
+	if atomic.LoadInt32(&c.accepted) == 1 {
+		return false
+	}
+	return atomic.CompareAndSwapInt32(&c.rejected, 0, 1)
+}
@@ -0,0 +1,183 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// decompress returns the body of r, decompressed according to its Content-Encoding.
+func decompress(t *testing.T, r *http.Request) []byte {
+	var (
+		body io.Reader = r.Body
+		err  error
+	)
+	switch r.Header.Get("Content-Encoding") {
+	case encodingGzip:
+		body, err = gzip.NewReader(r.Body)
+		require.NoError(t, err)
+	case encodingZstd:
+		d, err := zstd.NewReader(r.Body)
+		require.NoError(t, err)
+		defer d.Close()
+		body = d
+	}
+	b, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+	return b
+}
+
+func TestPayloadCompression(t *testing.T) {
+	for _, encoding := range []string{encodingGzip, encodingZstd} {
+		t.Run(encoding, func(t *testing.T) {
+			want, err := encode(getTestTrace(10, 10))
+			require.NoError(t, err)
+			wantBytes, err := ioutil.ReadAll(want)
+			require.NoError(t, err)
+
+			var got []byte
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, encoding, r.Header.Get("Content-Encoding"))
+				assert.Equal(t, "10", r.Header.Get(traceCountHeader))
+				got = decompress(t, r)
+			}))
+			defer srv.Close()
+
+			transport := newHTTPTransport(strings.TrimPrefix(srv.URL, "http://"), defaultClient)
+			transport.compressor = newCompressor(encoding, 0)
+			p, err := encode(getTestTrace(10, 10))
+			require.NoError(t, err)
+			_, err = transport.send(p)
+			require.NoError(t, err)
+			assert.Equal(t, wantBytes, got)
+		})
+	}
+}
+
+func TestPayloadCompressionRejected(t *testing.T) {
+	var encodings []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		encoding := r.Header.Get("Content-Encoding")
+		encodings = append(encodings, encoding)
+		if encoding != "" {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+		}
+	}))
+	defer srv.Close()
+
+	transport := newHTTPTransport(strings.TrimPrefix(srv.URL, "http://"), defaultClient)
+	transport.compressor = newCompressor("GZIP", 9)
+	for i := 0; i < 3; i++ {
+		p, err := encode(getTestTrace(1, 1))
+		require.NoError(t, err)
+		_, err = transport.send(p)
+		if i == 0 {
+			assert.Error(t, err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+	assert.Equal(t, []string{encodingGzip, "", ""}, encodings)
+}
+
+func TestPayloadCompressionAccepted(t *testing.T) {
+	fail := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, encodingZstd, r.Header.Get("Content-Encoding"))
+		if fail {
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	transport := newHTTPTransport(strings.TrimPrefix(srv.URL, "http://"), defaultClient)
+	transport.compressor = newCompressor(encodingZstd, 3)
+	for _, fail = range []bool{false, true, false} {
+		p, err := encode(getTestTrace(1, 1))
+		require.NoError(t, err)
+		_, err = transport.send(p)
+		assert.Equal(t, fail, err != nil)
+	}
+	// the agent accepted compressed payloads, an error doesn't disable the compression
+	assert.True(t, transport.compressor.enabled())
+}
+
+func TestNewCompressor(t *testing.T) {
+	assert.Nil(t, newCompressor("", 0))
+	assert.Nil(t, newCompressor("none", 0))
+	assert.Nil(t, newCompressor("brotli", 0))
+	assert.NotNil(t, newCompressor("gzip", 42))
+	assert.NotNil(t, newCompressor("zstd", 42))
+
+	t.Run("env", func(t *testing.T) {
+		os.Setenv("DD_TRACE_PAYLOAD_COMPRESSION", "zstd")
+		defer os.Unsetenv("DD_TRACE_PAYLOAD_COMPRESSION")
+		os.Setenv("DD_TRACE_PAYLOAD_COMPRESSION_LEVEL", "7")
+		defer os.Unsetenv("DD_TRACE_PAYLOAD_COMPRESSION_LEVEL")
+		c := newConfig()
+		assert.Equal(t, "zstd", c.payloadCompression)
+		assert.Equal(t, 7, c.payloadCompressionLevel)
+		assert.Equal(t, encodingZstd, c.transport.(*httpTransport).compressor.encoding)
+
+		c = newConfig(WithPayloadCompression("gzip", 1))
+		assert.Equal(t, encodingGzip, c.transport.(*httpTransport).compressor.encoding)
+	})
+}
+
+func BenchmarkPayloadCompression(b *testing.B) {
+	for _, bm := range []struct {
+		encoding string
+		level    int
+	}{
+		{"none", 0},
+		{encodingGzip, gzip.BestSpeed},
+		{encodingGzip, gzip.DefaultCompression},
+		{encodingGzip, gzip.BestCompression},
+		{encodingZstd, 1},
+		{encodingZstd, 3},
+		{encodingZstd, 11},
+	} {
+		b.Run(fmt.Sprintf("%s/%d", bm.encoding, bm.level), func(b *testing.B) {
+			p, err := encode(getTestTrace(100, 20))
+			if err != nil {
+				b.Fatal(err)
+			}
+			raw, err := ioutil.ReadAll(p)
+			if err != nil {
+				b.Fatal(err)
+			}
+			c := newCompressor(bm.encoding, bm.level)
+			size := len(raw)
+			b.SetBytes(int64(len(raw)))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if c == nil {
+					continue
+				}
+				buf, err := c.compress(bytes.NewReader(raw))
+				if err != nil {
+					b.Fatal(err)
+				}
+				size = buf.Len()
+			}
+			b.ReportMetric(float64(len(raw))/float64(size), "ratio")
+		})
+	}
+}
@@ -0,0 +1,73 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// consoleWriter specifies the output target of the consoleTraceWriter; replaced in tests.
+var consoleWriter io.Writer = os.Stdout
+
+// consoleTraceWriter pretty-prints the finished traces as JSON trees instead of sending
+// them to the agent, to inspect the instrumentation of an application locally.
+type consoleTraceWriter struct {
+	enc *json.Encoder
+}
+
+func newConsoleTraceWriter() *consoleTraceWriter {
+	enc := json.NewEncoder(consoleWriter)
+	enc.SetIndent("", "  ")
+	return &consoleTraceWriter{enc: enc}
+}
+
+// consoleSpan is a span printed by the consoleTraceWriter, along with its children.
+type consoleSpan struct {
+	ExportedSpan
+	Children []*consoleSpan `json:"children,omitempty"`
+}
+
+// add implements traceWriter. It is only called from the worker goroutine.
+func (h *consoleTraceWriter) add(trace []*span) {
+	for _, root := range consoleTree(trace) {
+		if err := h.enc.Encode(root); err != nil {
+			log.Error("Error printing trace: %v", err)
+		}
+	}
+}
+
+// flush implements traceWriter.
+func (h *consoleTraceWriter) flush() {}
+
+// stop implements traceWriter.
+func (h *consoleTraceWriter) stop() {}
+
+// consoleTree returns the roots of the trees formed by the spans of trace, with the
+// children of each span ordered by start time. The spans whose parent is not part of
+// the trace are roots.
+func consoleTree(trace []*span) []*consoleSpan {
+	spans := make(map[uint64]*consoleSpan, len(trace))
+	all := make([]*consoleSpan, len(trace))
+	for i, s := range trace {
+		all[i] = &consoleSpan{ExportedSpan: newExportedSpan(s)}
+		spans[s.SpanID] = all[i]
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].Start.Before(all[j].Start) })
+	var roots []*consoleSpan
+	for _, s := range all {
+		if p, ok := spans[s.ParentID]; ok && s.ParentID != s.SpanID {
+			p.Children = append(p.Children, s)
+			continue
+		}
+		roots = append(roots, s)
+	}
+	return roots
+}
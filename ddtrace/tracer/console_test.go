@@ -0,0 +1,88 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
+)
+
+func TestConsoleExport(t *testing.T) {
+	var buf bytes.Buffer
+	defer func(old io.Writer) { consoleWriter = old }(consoleWriter)
+	consoleWriter = &buf
+
+	tracer := newTracer(WithConsoleExport(true))
+	internal.SetGlobalTracer(tracer)
+	defer internal.SetGlobalTracer(&internal.NoopTracer{})
+	assert.IsType(t, &consoleTraceWriter{}, tracer.traceWriter)
+
+	start := time.Now()
+	root := tracer.StartSpan("web.request", ResourceName("GET /"), StartTime(start))
+	tracer.StartSpan("template.render", ChildOf(root.Context()), StartTime(start.Add(2*time.Millisecond))).Finish()
+	db := tracer.StartSpan("db.query", ChildOf(root.Context()), StartTime(start.Add(time.Millisecond)))
+	tracer.StartSpan("db.connect", ChildOf(db.Context())).Finish()
+	db.Finish()
+	root.Finish()
+	tracer.Stop()
+
+	var tree struct {
+		Name     string
+		Resource string
+		Children []struct {
+			Name     string
+			Children []struct{ Name string }
+		}
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &tree))
+	assert.Equal(t, "web.request", tree.Name)
+	assert.Equal(t, "GET /", tree.Resource)
+	require.Len(t, tree.Children, 2)
+	assert.Equal(t, "db.query", tree.Children[0].Name)
+	assert.Equal(t, "template.render", tree.Children[1].Name)
+	require.Len(t, tree.Children[0].Children, 1)
+	assert.Equal(t, "db.connect", tree.Children[0].Children[0].Name)
+	// pretty-printed
+	assert.Contains(t, buf.String(), "\n  \"name\": \"web.request\"")
+}
+
+func TestConsoleTree(t *testing.T) {
+	root := newSpan("web.request", "shop", "GET /", 1, 1, 0)
+	child := newSpan("db.query", "shop", "SELECT", 2, 1, 1)
+	// its parent belongs to another service
+	orphan := newSpan("http.request", "shop", "GET /", 3, 1, 42)
+
+	roots := consoleTree([]*span{child, orphan, root})
+	require.Len(t, roots, 2)
+	names := []string{roots[0].Name, roots[1].Name}
+	assert.ElementsMatch(t, []string{"web.request", "http.request"}, names)
+	for _, r := range roots {
+		if r.Name == "web.request" {
+			require.Len(t, r.Children, 1)
+			assert.Equal(t, "db.query", r.Children[0].Name)
+		}
+	}
+}
+
+func TestConsoleExportEnv(t *testing.T) {
+	os.Setenv("DD_TRACE_EXPORT", "stdout")
+	defer os.Unsetenv("DD_TRACE_EXPORT")
+	c := newConfig()
+	assert.True(t, c.consoleExport)
+
+	os.Setenv("DD_TRACE_EXPORT", "kafka")
+	c = newConfig()
+	assert.False(t, c.consoleExport)
+}
@@ -7,6 +7,7 @@ package tracer
 
 import (
 	"context"
+	"runtime/pprof"
 
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
@@ -22,15 +23,18 @@ func ContextWithSpan(ctx context.Context, s Span) context.Context {
 }
 
 // SpanFromContext returns the span contained in the given context. A second return
-// value indicates if a span was found in the context. If no span is found, a no-op
-// span is returned.
+// value indicates if a span was found in the context. If no span is found in the context,
+// the active span of the calling goroutine is returned when the tracer was started with
+// WithGoroutineLocalSpans, otherwise a no-op span is returned.
 func SpanFromContext(ctx context.Context) (Span, bool) {
-	if ctx == nil {
-		return &internal.NoopSpan{}, false
+	if ctx != nil {
+		v := ctx.Value(activeSpanKey)
+		if s, ok := v.(ddtrace.Span); ok {
+			return s, true
+		}
 	}
-	v := ctx.Value(activeSpanKey)
-	if s, ok := v.(ddtrace.Span); ok {
-		return s, true
+	if t, ok := internal.GetGlobalTracer().(*tracer); ok && t.config.goroutineSpans {
+		return ActiveSpan()
 	}
 	return &internal.NoopSpan{}, false
 }
@@ -52,6 +56,7 @@ func StartSpanFromContext(ctx context.Context, operationName string, opts ...Sta
 	}
 	optsLocal = append(optsLocal, withContext(ctx))
 	s := StartSpan(operationName, optsLocal...)
+	auditPropagation(ctx, operationName, s)
 	if span, ok := s.(*span); ok && span.pprofCtxActive != nil {
 		// If pprof labels were applied for this span, use the derived ctx that
 		// includes them. Otherwise a child of this span wouldn't be able to
@@ -60,3 +65,38 @@ func StartSpanFromContext(ctx context.Context, operationName string, opts ...Sta
 	}
 	return s, ContextWithSpan(ctx, s)
 }
+
+// WithPprofLabels applies the profiler labels of the span found in ctx, if any, to the
+// calling goroutine, and returns a copy of ctx holding them. Goroutines inherit the
+// labels of the goroutine starting them, but not those of spans started afterwards:
+// it should be called by goroutines doing work on behalf of a span which they didn't
+// start from, such as the workers of a pool, so that their profiling samples are linked
+// to the span. Workers should restore their labels with pprof.SetGoroutineLabels once
+// the work is done. ctx is returned unchanged when the span has no profiler labels.
+func WithPprofLabels(ctx context.Context) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	s, ok := SpanFromContext(ctx)
+	if !ok {
+		return ctx
+	}
+	sp, ok := s.(*span)
+	if !ok {
+		return ctx
+	}
+	sp.RLock()
+	active := sp.pprofCtxActive
+	sp.RUnlock()
+	if active == nil {
+		return ctx
+	}
+	var labels []string
+	pprof.ForLabels(active, func(k, v string) bool {
+		labels = append(labels, k, v)
+		return true
+	})
+	ctx = pprof.WithLabels(ctx, pprof.Labels(labels...))
+	pprof.SetGoroutineLabels(ctx)
+	return ctx
+}
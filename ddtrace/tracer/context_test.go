@@ -7,11 +7,14 @@ package tracer
 
 import (
 	"context"
+	"runtime/pprof"
+	"strconv"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
+	"github.com/codebrick-corp/dd-trace-go/internal/traceprof"
 )
 
 func TestContextWithSpan(t *testing.T) {
@@ -125,3 +128,45 @@ func TestStartSpanFromNilContext(t *testing.T) {
 	assert.True(ok)
 	assert.Equal(child, ctxSpan)
 }
+
+func TestWithPprofLabels(t *testing.T) {
+	t.Run("enabled", func(t *testing.T) {
+		_, _, _, stop := startTestTracer(t, WithProfilerCodeHotspots(true))
+		defer stop()
+
+		root := StartSpan("web.request")
+		defer root.Finish()
+		child := StartSpan("work", ChildOf(root.Context()))
+		defer child.Finish()
+
+		done := make(chan context.Context)
+		go func() {
+			// the span is handed over to a goroutine which was not started from it
+			done <- WithPprofLabels(ContextWithSpan(context.Background(), child))
+		}()
+		ctx := <-done
+		spanID, _ := pprof.Label(ctx, traceprof.SpanID)
+		assert.Equal(t, strconv.FormatUint(child.Context().SpanID(), 10), spanID)
+		rootID, _ := pprof.Label(ctx, traceprof.LocalRootSpanID)
+		assert.Equal(t, strconv.FormatUint(root.Context().SpanID(), 10), rootID)
+		s, ok := SpanFromContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, child, s)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		_, _, _, stop := startTestTracer(t, WithProfilerCodeHotspots(false), WithProfilerEndpoints(false))
+		defer stop()
+
+		span := StartSpan("web.request")
+		defer span.Finish()
+		ctx := ContextWithSpan(context.Background(), span)
+		assert.Equal(t, ctx, WithPprofLabels(ctx))
+	})
+
+	t.Run("no-span", func(t *testing.T) {
+		ctx := context.Background()
+		assert.Equal(t, ctx, WithPprofLabels(ctx))
+		assert.NotNil(t, WithPprofLabels(nil))
+	})
+}
@@ -0,0 +1,199 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"os/signal"
+	"runtime"
+	"syscall"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+	"github.com/codebrick-corp/dd-trace-go/internal/samplernames"
+)
+
+const (
+	// keyCrash holds what crashed the process on the span reporting the crash: "panic"
+	// or the name of the signal which killed it.
+	keyCrash = "_dd.crash"
+
+	// crashOperationName is the operation name of the spans reporting the signals which
+	// killed the process, and the panics of the goroutines holding no span.
+	crashOperationName = "process.crash"
+)
+
+var (
+	// crashSignals are the signals which make the Go runtime dump the stacks of the goroutines
+	// and exit, and which are reported when crash tracking is enabled.
+	crashSignals = []os.Signal{syscall.SIGQUIT, syscall.SIGABRT}
+
+	// crashFlushTimeout is how long the tracer waits for the trace reporting a crash to be
+	// sent before letting the process die.
+	crashFlushTimeout = 2 * time.Second
+)
+
+// setCrash marks s as having crashed with the given reason, which is recorded as its error
+// along with the given message and stack, and keeps its trace.
+func (s *span) setCrash(reason, msg, stack string) {
+	s.Lock()
+	defer s.Unlock()
+	if s.finished {
+		return
+	}
+	s.setTagError(true, errorConfig{})
+	s.setMeta(ext.ErrorMsg, msg)
+	s.setMeta(ext.ErrorType, reason)
+	s.setMeta(ext.ErrorStack, stack)
+	s.setMeta(keyCrash, reason)
+	s.setSamplingPriorityLocked(ext.PriorityUserKeep, samplernames.Manual, math.NaN())
+}
+
+// ReportPanic reports the panic of the calling goroutine, if any, as a crash of the process
+// when the tracer was started with WithCrashTracking, then resumes panicking. It is meant to
+// be deferred at the top of the goroutines whose panics aren't recovered, such as main, once
+// their root span is started, so that the trace of the crash is sent before the process dies:
+//
+//	span, ctx := tracer.StartSpanFromContext(ctx, "job")
+//	defer span.Finish()
+//	defer tracer.ReportPanic(ctx)
+//
+// The panic is recorded as the error of the root span of the span found in ctx, along with its
+// stack trace, and the root span is finished. A process.crash span reports it when ctx holds
+// no unfinished span.
+func ReportPanic(ctx context.Context) {
+	p := recover()
+	if p == nil {
+		return
+	}
+	if t, ok := internal.GetGlobalTracer().(*tracer); ok && t.config.crashTracking {
+		// skip ReportPanic, the frames from runtime.gopanic show where the panic happened
+		t.reportPanic(ctx, p, takeStacktrace(0, 1))
+	}
+	panic(p)
+}
+
+// reportPanic reports the panic p, which happened with the given stack, on the root span of
+// the span found in ctx, or else on a process.crash span.
+func (t *tracer) reportPanic(ctx context.Context, p interface{}, stack string) {
+	msg := fmt.Sprintf("panic: %v", p)
+	log.Error("reporting %s as a crash", msg)
+	var root *span
+	if s, ok := SpanFromContext(ctx); ok {
+		if s, ok := s.(*span); ok && s.context != nil && s.context.trace != nil {
+			root = s.context.trace.root
+		}
+	}
+	if root != nil {
+		root.RLock()
+		finished := root.finished
+		root.RUnlock()
+		if finished {
+			root = nil
+		}
+	}
+	if root == nil {
+		root = t.StartSpan(crashOperationName).(*span)
+	}
+	root.setCrash("panic", msg, stack)
+	root.Finish()
+	reportCrash()
+}
+
+// reportCrash sends the buffered traces, including the trace reporting the crash, waiting for
+// at most crashFlushTimeout. It must only be called once the process is about to die.
+func reportCrash() {
+	t, ok := internal.GetGlobalTracer().(*tracer)
+	if !ok {
+		return
+	}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		t.flushSync()
+		if w, ok := t.traceWriter.(*agentTraceWriter); ok {
+			// the agent writer sends its payloads asynchronously
+			w.wg.Wait()
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(crashFlushTimeout):
+		log.Error("timed out reporting the crash after %s", crashFlushTimeout)
+	}
+}
+
+// startCrashTracking makes the tracer report the crash signals received by the process.
+func (t *tracer) startCrashTracking() {
+	sigs := make(chan os.Signal, 1)
+	// the signals are tracked as soon as the tracer is started
+	signal.Notify(sigs, crashSignals...)
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.trackCrashSignals(sigs)
+	}()
+}
+
+// trackCrashSignals reports the crash signal received from sigs, if any, until the tracer is
+// stopped.
+func (t *tracer) trackCrashSignals(sigs chan os.Signal) {
+	defer signal.Stop(sigs)
+	select {
+	case sig := <-sigs:
+		t.reportCrashSignal(sig)
+	case <-t.stop:
+	}
+}
+
+// reportCrashSignal reports the signal sig as a crash span holding the stacks of all the
+// goroutines, then restores the default action of sig and raises it again, for the Go runtime
+// to dump the stacks and exit with status 2. The handlers of sig registered by the application
+// are thus dropped.
+func (t *tracer) reportCrashSignal(sig os.Signal) {
+	stack := allStacks()
+	reason := "signal"
+	if s, ok := sig.(syscall.Signal); ok {
+		reason = signalName(s)
+	}
+	s := t.StartSpan(crashOperationName).(*span)
+	s.setCrash(reason, "received signal: "+sig.String(), stack)
+	s.Finish()
+	reportCrash()
+	if sig, ok := sig.(syscall.Signal); ok {
+		signal.Reset(sig)
+		raise(sig)
+	}
+}
+
+// signalName returns the name of the crash signal sig, as printed by the Go runtime.
+func signalName(sig syscall.Signal) string {
+	switch sig {
+	case syscall.SIGQUIT:
+		return "SIGQUIT"
+	case syscall.SIGABRT:
+		return "SIGABRT"
+	}
+	return "signal " + fmt.Sprint(int(sig))
+}
+
+// allStacks returns the stacks of all the goroutines, growing the buffer they are dumped
+// into until they fit.
+func allStacks() string {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return string(buf[:n])
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
@@ -0,0 +1,251 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+)
+
+// crashAgentEnv holds the address of the agent to which the subprocesses started by
+// TestCrashTrackingPanic and TestCrashTrackingSignal report their crashes.
+const crashAgentEnv = "DD_TEST_CRASH_AGENT"
+
+// panicWithSpan panics while the root span of ctx is open, both reported by ReportPanic.
+func panicWithSpan() {
+	root, ctx := StartSpanFromContext(context.Background(), "root")
+	defer root.Finish()
+	defer ReportPanic(ctx)
+	child, _ := StartSpanFromContext(ctx, "child")
+	defer child.Finish()
+	panic("boom")
+}
+
+func TestCrashTrackingPanic(t *testing.T) {
+	if addr := os.Getenv(crashAgentEnv); addr != "" {
+		// the subprocess crashing
+		Start(WithAgentAddr(addr), WithCrashTracking(true), WithService("crashing"), withNoopStats())
+		defer Stop()
+		panicWithSpan()
+		return
+	}
+
+	traces := make(chan spanLists, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0.4/traces" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var got spanLists
+		if err := msgp.Decode(r.Body, &got); err == nil && len(got) > 0 {
+			traces <- got
+		}
+	}))
+	defer srv.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCrashTrackingPanic$")
+	cmd.Env = append(os.Environ(), crashAgentEnv+"="+strings.TrimPrefix(srv.URL, "http://"))
+	out, err := cmd.CombinedOutput()
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr), "%v: %s", err, out)
+	assert.Equal(t, 2, exitErr.ExitCode())
+	assert.Contains(t, string(out), "panic: boom")
+
+	var got spanLists
+	select {
+	case got = <-traces:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the crash wasn't reported")
+	}
+	require.Len(t, got, 1)
+	require.Len(t, got[0], 2)
+	var root, child *span
+	for _, s := range got[0] {
+		if s.Name == "root" {
+			root = s
+		} else {
+			child = s
+		}
+	}
+	require.NotNil(t, root)
+	assert.Equal(t, "crashing", root.Service)
+	assert.Equal(t, int32(1), root.Error)
+	assert.Equal(t, "panic", root.Meta[keyCrash])
+	assert.Equal(t, "panic", root.Meta[ext.ErrorType])
+	assert.Equal(t, "panic: boom", root.Meta[ext.ErrorMsg])
+	assert.Contains(t, root.Meta[ext.ErrorStack], "tracer.panicWithSpan")
+	assert.Equal(t, float64(ext.PriorityUserKeep), root.Metrics[keySamplingPriority])
+	// the child span was finished by the panic, without reporting it
+	assert.Equal(t, int32(0), child.Error)
+	assert.NotContains(t, child.Meta, keyCrash)
+}
+
+func TestReportPanic(t *testing.T) {
+	// panicInRoot panics with p, reported by ReportPanic with ctx, and returns the value of
+	// the panic which reached the caller.
+	panicInRoot := func(ctx context.Context, p interface{}) (recovered interface{}) {
+		defer func() { recovered = recover() }()
+		defer ReportPanic(ctx)
+		panic(p)
+	}
+
+	t.Run("enabled", func(t *testing.T) {
+		_, transport, _, stop := startTestTracer(t, WithCrashTracking(true))
+		defer stop()
+
+		root, ctx := StartSpanFromContext(context.Background(), "root")
+		assert.Equal(t, "boom", panicInRoot(ctx, "boom"))
+		// the trace was sent before the panic resumed
+		traces := transport.Traces()
+		require.Len(t, traces, 1)
+		assert.Equal(t, root.Context().SpanID(), traces[0][0].SpanID)
+		assert.Equal(t, "panic: boom", traces[0][0].Meta[ext.ErrorMsg])
+	})
+
+	t.Run("no-span", func(t *testing.T) {
+		_, transport, _, stop := startTestTracer(t, WithCrashTracking(true))
+		defer stop()
+
+		assert.Equal(t, "boom", panicInRoot(context.Background(), "boom"))
+		traces := transport.Traces()
+		require.Len(t, traces, 1)
+		s := traces[0][0]
+		assert.Equal(t, crashOperationName, s.Name)
+		assert.Equal(t, "panic", s.Meta[keyCrash])
+		assert.Equal(t, "panic: boom", s.Meta[ext.ErrorMsg])
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		_, transport, flush, stop := startTestTracer(t)
+		defer stop()
+
+		root, ctx := StartSpanFromContext(context.Background(), "root")
+		assert.Equal(t, "boom", panicInRoot(ctx, "boom"))
+		root.Finish()
+		flush(1)
+		traces := transport.Traces()
+		require.Len(t, traces, 1)
+		assert.Equal(t, int32(0), traces[0][0].Error)
+		assert.NotContains(t, traces[0][0].Meta, keyCrash)
+	})
+
+	t.Run("no-panic", func(t *testing.T) {
+		_, transport, flush, stop := startTestTracer(t, WithCrashTracking(true))
+		defer stop()
+
+		func() {
+			root, ctx := StartSpanFromContext(context.Background(), "root")
+			defer root.Finish()
+			defer ReportPanic(ctx)
+		}()
+		flush(1)
+		traces := transport.Traces()
+		require.Len(t, traces, 1)
+		assert.Equal(t, int32(0), traces[0][0].Error)
+	})
+}
+
+func TestAllStacks(t *testing.T) {
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 1000; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			<-done
+		}()
+	}
+	defer wg.Wait()
+	defer close(done)
+	// the stacks of a thousand goroutines don't fit in the initial buffer
+	stacks := allStacks()
+	assert.Greater(t, len(stacks), 64*1024)
+	assert.GreaterOrEqual(t, strings.Count(stacks, "\ngoroutine ")+1, 1001)
+}
+
+func TestCrashTrackingSignal(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGQUIT can't be sent to the process on Windows")
+	}
+	if addr := os.Getenv(crashAgentEnv); addr != "" {
+		// the subprocess crashing
+		Start(WithAgentAddr(addr), WithCrashTracking(true), WithService("crashing"), withNoopStats())
+		p, err := os.FindProcess(os.Getpid())
+		if err == nil {
+			err = p.Signal(syscall.SIGQUIT)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Second)
+		return
+	}
+
+	traces := make(chan spanLists, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0.4/traces" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var got spanLists
+		if err := msgp.Decode(r.Body, &got); err == nil && len(got) > 0 {
+			traces <- got
+		}
+	}))
+	defer srv.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestCrashTrackingSignal$")
+	cmd.Env = append(os.Environ(), crashAgentEnv+"="+strings.TrimPrefix(srv.URL, "http://"))
+	out, err := cmd.CombinedOutput()
+	// the Go runtime dumped the stacks and exited
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr), "%v: %s", err, out)
+	assert.Equal(t, 2, exitErr.ExitCode())
+	assert.Contains(t, string(out), "SIGQUIT: quit")
+	assert.Contains(t, string(out), "goroutine ")
+
+	var got spanLists
+	select {
+	case got = <-traces:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the crash wasn't reported")
+	}
+	require.Len(t, got, 1)
+	require.Len(t, got[0], 1)
+	s := got[0][0]
+	assert.Equal(t, crashOperationName, s.Name)
+	assert.Equal(t, "crashing", s.Service)
+	assert.Equal(t, int32(1), s.Error)
+	assert.Equal(t, "SIGQUIT", s.Meta[keyCrash])
+	assert.Equal(t, "received signal: quit", s.Meta[ext.ErrorMsg])
+	assert.Contains(t, s.Meta[ext.ErrorStack], "tracer.TestCrashTrackingSignal")
+}
+
+func TestCrashTrackingConfig(t *testing.T) {
+	assert.False(t, newConfig().crashTracking)
+	assert.True(t, newConfig(WithCrashTracking(true)).crashTracking)
+
+	os.Setenv("DD_CRASHTRACKING_ENABLED", "true")
+	defer os.Unsetenv("DD_CRASHTRACKING_ENABLED")
+	assert.True(t, newConfig().crashTracking)
+	assert.False(t, newConfig(WithCrashTracking(false)).crashTracking)
+}
@@ -0,0 +1,198 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// maxDebugOperations is the maximum number of distinct operation names recorded for
+// DebugHandler.
+const maxDebugOperations = 100
+
+// debugStats records the statistics of a tracer reported by DebugHandler. Unlike the
+// health metrics, its counters are never reset.
+type debugStats struct {
+	tracesTooLarge  uint64 // traces dropped because they had too many spans
+	tracesQueueFull uint64 // traces dropped because the payload queue was full
+
+	operations      sync.Map // the distinct operation names of the started spans
+	operationsCount int32    // the number of entries in operations
+}
+
+// recordOperation records that a span with the given operation name was started.
+func (d *debugStats) recordOperation(name string) {
+	if _, ok := d.operations.Load(name); ok {
+		return
+	}
+	if atomic.LoadInt32(&d.operationsCount) >= maxDebugOperations {
+		return
+	}
+	if _, loaded := d.operations.LoadOrStore(name, struct{}{}); !loaded {
+		atomic.AddInt32(&d.operationsCount, 1)
+	}
+}
+
+// operationNames returns the recorded operation names, sorted.
+func (d *debugStats) operationNames() []string {
+	names := make([]string, 0, atomic.LoadInt32(&d.operationsCount))
+	d.operations.Range(func(k, _ interface{}) bool {
+		names = append(names, k.(string))
+		return true
+	})
+	sort.Strings(names)
+	return names
+}
+
+// writerStatus records the state of an agentTraceWriter reported by DebugHandler.
+type writerStatus struct {
+	bufferedBytes  int64  // the size of the payload being filled
+	bufferedTraces int64  // the number of traces in the payload being filled
+	tracesLost     uint64 // traces lost because their payload couldn't be sent
+	tracesSkipped  uint64 // traces dropped without being encoded while the agent was unreachable
+
+	mu            sync.Mutex // guards below fields
+	lastFlush     time.Time  // the time of the last successful flush
+	lastError     string     // the error of the last failed flush
+	lastErrorTime time.Time  // the time of the last failed flush
+}
+
+// setBuffered records the occupancy of the payload p being filled.
+func (s *writerStatus) setBuffered(p *payload) {
+	atomic.StoreInt64(&s.bufferedBytes, int64(p.size()))
+	atomic.StoreInt64(&s.bufferedTraces, int64(p.itemCount()))
+}
+
+// flushed records the outcome of an attempt to send a payload.
+func (s *writerStatus) flushed(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.lastError = err.Error()
+		s.lastErrorTime = time.Now()
+		return
+	}
+	s.lastFlush = time.Now()
+}
+
+// lost records that count traces were dropped because they couldn't be sent.
+func (s *writerStatus) lost(count int) {
+	atomic.AddUint64(&s.tracesLost, uint64(count))
+}
+
+// skipped records that a trace was dropped because the agent was unreachable.
+func (s *writerStatus) skipped() {
+	atomic.AddUint64(&s.tracesSkipped, 1)
+}
+
+// debugStatus is the document served by DebugHandler.
+type debugStatus struct {
+	Service          string             `json:"service"`
+	Env              string             `json:"env"`
+	Version          string             `json:"version"`
+	AgentURL         string             `json:"agent_url"`
+	SamplingRates    map[string]float64 `json:"sampling_rates"` // rates set by the agent, by service
+	SamplingRules    []SamplingRule     `json:"sampling_rules"`
+	GlobalRate       *float64           `json:"global_sample_rate,omitempty"`
+	Buffer           debugBuffer        `json:"buffer"`
+	Dropped          debugDropped       `json:"dropped_traces"`
+	Operations       []string           `json:"operations"` // operation names of the started spans, revealing the active integrations
+	LastFlush        string             `json:"last_flush,omitempty"`
+	LastError        string             `json:"last_flush_error,omitempty"`
+	LastErrorTime    string             `json:"last_flush_error_time,omitempty"`
+	UnreachableSince string             `json:"agent_unreachable_since,omitempty"` // time since which the agent is considered unreachable
+}
+
+type debugBuffer struct {
+	QueuedTraces  int   `json:"queued_traces"`  // traces waiting to be added to the payload
+	QueueCapacity int   `json:"queue_capacity"` // capacity of the queue of traces
+	PayloadTraces int64 `json:"payload_traces"` // traces in the payload being filled
+	PayloadBytes  int64 `json:"payload_bytes"`  // size of the payload being filled
+}
+
+type debugDropped struct {
+	TooLarge         uint64 `json:"trace_too_large"`
+	QueueFull        uint64 `json:"queue_full"`
+	SendFailed       uint64 `json:"send_failed"`
+	AgentUnreachable uint64 `json:"agent_unreachable"`
+}
+
+// status returns the current debugStatus of t.
+func (t *tracer) status() debugStatus {
+	s := debugStatus{
+		Service:       t.config.serviceName,
+		Env:           t.config.env,
+		Version:       t.config.version,
+		AgentURL:      t.config.transport.endpoint(),
+		SamplingRates: t.prioritySampling.ratesByService(),
+		SamplingRules: t.rulesSampling.rules,
+		Buffer: debugBuffer{
+			QueuedTraces:  len(t.out),
+			QueueCapacity: cap(t.out),
+		},
+		Dropped: debugDropped{
+			TooLarge:  atomic.LoadUint64(&t.debug.tracesTooLarge),
+			QueueFull: atomic.LoadUint64(&t.debug.tracesQueueFull),
+		},
+		Operations: t.debug.operationNames(),
+	}
+	if rate := t.rulesSampling.globalRate; !math.IsNaN(rate) {
+		s.GlobalRate = &rate
+	}
+	if w, ok := t.traceWriter.(*agentTraceWriter); ok {
+		st := &w.status
+		s.Buffer.PayloadTraces = atomic.LoadInt64(&st.bufferedTraces)
+		s.Buffer.PayloadBytes = atomic.LoadInt64(&st.bufferedBytes)
+		s.Dropped.SendFailed = atomic.LoadUint64(&st.tracesLost)
+		s.Dropped.AgentUnreachable = atomic.LoadUint64(&st.tracesSkipped)
+		if since := w.breaker.since(); !since.IsZero() {
+			s.UnreachableSince = since.Format(time.RFC3339)
+		}
+		st.mu.Lock()
+		if !st.lastFlush.IsZero() {
+			s.LastFlush = st.lastFlush.Format(time.RFC3339)
+		}
+		if st.lastError != "" {
+			s.LastError = st.lastError
+			s.LastErrorTime = st.lastErrorTime.Format(time.RFC3339)
+		}
+		st.mu.Unlock()
+	}
+	return s
+}
+
+// DebugHandler returns an http.Handler serving a JSON document describing the internal
+// state of the running tracer, for live troubleshooting: the sampling rates, the
+// occupancy of its buffers, the number of traces it dropped, the operation names of
+// the spans it started and the outcome of its last flushes. It can be mounted on any
+// mux, e.g. mux.Handle("/debug/tracer", tracer.DebugHandler()). It responds with
+// the status 503 Service Unavailable when the tracer is not started. The document
+// reveals details of the configuration of the application: the handler should not be
+// publicly reachable.
+func DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t, ok := internal.GetGlobalTracer().(*tracer)
+		if !ok {
+			http.Error(w, "tracer not started", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(t.status()); err != nil {
+			log.Error("Error encoding the tracer status: %v", err)
+		}
+	})
+}
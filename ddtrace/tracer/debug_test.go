@@ -0,0 +1,102 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+// failingTransport is a transport failing to send payloads.
+type failingTransport struct {
+	dummyTransport
+}
+
+func (t *failingTransport) send(p *payload) (io.ReadCloser, error) {
+	return nil, errors.New("connection refused")
+}
+
+func getDebugStatus(t *testing.T) debugStatus {
+	w := httptest.NewRecorder()
+	DebugHandler().ServeHTTP(w, httptest.NewRequest("GET", "/debug/tracer", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	var s debugStatus
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &s))
+	return s
+}
+
+func TestDebugHandler(t *testing.T) {
+	t.Run("not-started", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		DebugHandler().ServeHTTP(w, httptest.NewRequest("GET", "/debug/tracer", nil))
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	})
+
+	t.Run("started", func(t *testing.T) {
+		_, _, flush, stop := startTestTracer(t, WithService("shop"), WithEnv("prod"),
+			WithSamplingRules([]SamplingRule{ServiceRule("shop", 0.5)}))
+		defer stop()
+		defer globalconfig.SetServiceName("")
+
+		StartSpan("web.request").Finish()
+		StartSpan("redis.command").Finish()
+		flush(2)
+
+		s := getDebugStatus(t)
+		assert.Equal(t, "shop", s.Service)
+		assert.Equal(t, "prod", s.Env)
+		assert.Equal(t, "http://localhost:9/v0.4/traces", s.AgentURL)
+		assert.Equal(t, []string{"redis.command", "web.request"}, s.Operations)
+		assert.Len(t, s.SamplingRules, 1)
+		assert.Equal(t, payloadQueueSize, s.Buffer.QueueCapacity)
+		assert.Zero(t, s.Buffer.PayloadTraces)
+		assert.NotEmpty(t, s.LastFlush)
+		assert.Empty(t, s.LastError)
+	})
+
+	t.Run("send-failed", func(t *testing.T) {
+		tick := make(chan time.Time)
+		tr := newTracer(withTransport(&failingTransport{}), withTickChan(tick))
+		defer tr.Stop()
+		internal.SetGlobalTracer(tr)
+		defer internal.SetGlobalTracer(&internal.NoopTracer{})
+
+		tr.StartSpan("web.request").Finish()
+		assert.Eventually(t, func() bool {
+			return getDebugStatus(t).Buffer.PayloadTraces == 1
+		}, 5*time.Second, 10*time.Millisecond)
+
+		tick <- time.Now()
+		assert.Eventually(t, func() bool {
+			return getDebugStatus(t).Dropped.SendFailed == 1
+		}, 5*time.Second, 10*time.Millisecond)
+		s := getDebugStatus(t)
+		assert.Equal(t, "connection refused", s.LastError)
+		assert.NotEmpty(t, s.LastErrorTime)
+		assert.Empty(t, s.LastFlush)
+	})
+}
+
+func TestDebugStatsOperations(t *testing.T) {
+	var d debugStats
+	for i := 0; i < maxDebugOperations+10; i++ {
+		d.recordOperation(string(rune('a'+i%26)) + string(rune('a'+i/26)))
+	}
+	d.recordOperation("aa")
+	assert.Len(t, d.operationNames(), maxDebugOperations)
+}
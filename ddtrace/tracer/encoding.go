@@ -0,0 +1,257 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// The functions in this file encode span contexts so that they can be stored and used
+// to resume a trace later on, e.g. by workflow engines. The encodings are stable: data
+// encoded by a version of the tracer can be decoded by any later version.
+
+// spanContextJSON is the JSON representation of a span context. IDs are encoded as strings
+// because most JSON decoders can't represent 64-bit integers accurately.
+type spanContextJSON struct {
+	TraceID          string            `json:"trace_id"`
+	SpanID           string            `json:"span_id"`
+	SamplingPriority *int              `json:"sampling_priority,omitempty"`
+	Origin           string            `json:"origin,omitempty"`
+	Baggage          map[string]string `json:"baggage,omitempty"`
+	PropagatingTags  map[string]string `json:"propagating_tags,omitempty"`
+}
+
+// MarshalSpanContextJSON returns the JSON encoding of ctx, holding its trace and span IDs,
+// sampling priority, origin, baggage and propagating tags. The upper 64 bits of 128-bit
+// trace IDs are kept along with the propagating tags, in the _dd.p.tid tag. The span
+// context can be recreated using UnmarshalSpanContextJSON.
+func MarshalSpanContextJSON(ctx ddtrace.SpanContext) ([]byte, error) {
+	cfg, err := spanContextConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(spanContextJSON{
+		TraceID:          strconv.FormatUint(cfg.TraceID, 10),
+		SpanID:           strconv.FormatUint(cfg.SpanID, 10),
+		SamplingPriority: cfg.SamplingPriority,
+		Origin:           cfg.Origin,
+		Baggage:          cfg.Baggage,
+		PropagatingTags:  cfg.PropagatingTags,
+	})
+}
+
+// UnmarshalSpanContextJSON returns the span context encoded in data by MarshalSpanContextJSON.
+// It can be used as the parent of new spans with ChildOf.
+func UnmarshalSpanContextJSON(data []byte) (ddtrace.SpanContext, error) {
+	var v spanContextJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return nil, ErrSpanContextCorrupted
+	}
+	cfg := SpanContextConfig{
+		SamplingPriority: v.SamplingPriority,
+		Origin:           v.Origin,
+		Baggage:          v.Baggage,
+		PropagatingTags:  v.PropagatingTags,
+	}
+	var err error
+	if cfg.TraceID, err = parseUint64(v.TraceID); err != nil {
+		return nil, ErrSpanContextCorrupted
+	}
+	if cfg.SpanID, err = parseUint64(v.SpanID); err != nil {
+		return nil, ErrSpanContextCorrupted
+	}
+	return NewSpanContext(cfg)
+}
+
+// Field numbers of the protobuf encoding of span contexts, which is equivalent to
+// the following message:
+//
+//	message SpanContext {
+//		uint64 trace_id = 1;
+//		uint64 span_id = 2;
+//		optional sint32 sampling_priority = 3;
+//		string origin = 4;
+//		map<string, string> baggage = 5;
+//		map<string, string> propagating_tags = 6;
+//	}
+const (
+	protoFieldTraceID          protowire.Number = 1
+	protoFieldSpanID           protowire.Number = 2
+	protoFieldSamplingPriority protowire.Number = 3
+	protoFieldOrigin           protowire.Number = 4
+	protoFieldBaggage          protowire.Number = 5
+	protoFieldPropagatingTags  protowire.Number = 6
+
+	// map entries hold their key and value as fields 1 and 2.
+	protoFieldMapKey   protowire.Number = 1
+	protoFieldMapValue protowire.Number = 2
+)
+
+// MarshalSpanContextProto returns the protobuf encoding of ctx, holding the same values
+// as MarshalSpanContextJSON. The span context can be recreated using
+// UnmarshalSpanContextProto.
+func MarshalSpanContextProto(ctx ddtrace.SpanContext) ([]byte, error) {
+	cfg, err := spanContextConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var b []byte
+	b = protowire.AppendTag(b, protoFieldTraceID, protowire.VarintType)
+	b = protowire.AppendVarint(b, cfg.TraceID)
+	b = protowire.AppendTag(b, protoFieldSpanID, protowire.VarintType)
+	b = protowire.AppendVarint(b, cfg.SpanID)
+	if cfg.SamplingPriority != nil {
+		b = protowire.AppendTag(b, protoFieldSamplingPriority, protowire.VarintType)
+		b = protowire.AppendVarint(b, protowire.EncodeZigZag(int64(*cfg.SamplingPriority)))
+	}
+	if cfg.Origin != "" {
+		b = protowire.AppendTag(b, protoFieldOrigin, protowire.BytesType)
+		b = protowire.AppendString(b, cfg.Origin)
+	}
+	b = appendProtoMap(b, protoFieldBaggage, cfg.Baggage)
+	b = appendProtoMap(b, protoFieldPropagatingTags, cfg.PropagatingTags)
+	return b, nil
+}
+
+// appendProtoMap appends the entries of m, sorted by key, as the map field num to b.
+func appendProtoMap(b []byte, num protowire.Number, m map[string]string) []byte {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		var entry []byte
+		entry = protowire.AppendTag(entry, protoFieldMapKey, protowire.BytesType)
+		entry = protowire.AppendString(entry, k)
+		entry = protowire.AppendTag(entry, protoFieldMapValue, protowire.BytesType)
+		entry = protowire.AppendString(entry, m[k])
+		b = protowire.AppendTag(b, num, protowire.BytesType)
+		b = protowire.AppendBytes(b, entry)
+	}
+	return b
+}
+
+// UnmarshalSpanContextProto returns the span context encoded in data by
+// MarshalSpanContextProto. Unknown fields are ignored. It can be used as the parent
+// of new spans with ChildOf.
+func UnmarshalSpanContextProto(data []byte) (ddtrace.SpanContext, error) {
+	var cfg SpanContextConfig
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return nil, ErrSpanContextCorrupted
+		}
+		data = data[n:]
+		switch {
+		case num == protoFieldTraceID && typ == protowire.VarintType:
+			cfg.TraceID, n = protowire.ConsumeVarint(data)
+		case num == protoFieldSpanID && typ == protowire.VarintType:
+			cfg.SpanID, n = protowire.ConsumeVarint(data)
+		case num == protoFieldSamplingPriority && typ == protowire.VarintType:
+			var v uint64
+			v, n = protowire.ConsumeVarint(data)
+			p := int(protowire.DecodeZigZag(v))
+			cfg.SamplingPriority = &p
+		case num == protoFieldOrigin && typ == protowire.BytesType:
+			cfg.Origin, n = protowire.ConsumeString(data)
+		case num == protoFieldBaggage && typ == protowire.BytesType:
+			n = consumeProtoMapEntry(data, &cfg.Baggage)
+		case num == protoFieldPropagatingTags && typ == protowire.BytesType:
+			n = consumeProtoMapEntry(data, &cfg.PropagatingTags)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return nil, ErrSpanContextCorrupted
+		}
+		data = data[n:]
+	}
+	return NewSpanContext(cfg)
+}
+
+// consumeProtoMapEntry parses the map entry at the start of b into m, allocating it if
+// needed. It returns the number of bytes consumed, or a negative value on error.
+func consumeProtoMapEntry(b []byte, m *map[string]string) int {
+	entry, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return n
+	}
+	var key, value string
+	for len(entry) > 0 {
+		num, typ, tn := protowire.ConsumeTag(entry)
+		if tn < 0 {
+			return tn
+		}
+		entry = entry[tn:]
+		var vn int
+		switch {
+		case num == protoFieldMapKey && typ == protowire.BytesType:
+			key, vn = protowire.ConsumeString(entry)
+		case num == protoFieldMapValue && typ == protowire.BytesType:
+			value, vn = protowire.ConsumeString(entry)
+		default:
+			vn = protowire.ConsumeFieldValue(num, typ, entry)
+		}
+		if vn < 0 {
+			return vn
+		}
+		entry = entry[vn:]
+	}
+	if *m == nil {
+		*m = make(map[string]string)
+	}
+	(*m)[key] = value
+	return n
+}
+
+// spanContextConfig returns the values of ctx which are needed to recreate it with
+// NewSpanContext.
+func spanContextConfig(ctx ddtrace.SpanContext) (SpanContextConfig, error) {
+	if ctx == nil || ctx.TraceID() == 0 {
+		return SpanContextConfig{}, ErrInvalidSpanContext
+	}
+	cfg := SpanContextConfig{
+		TraceID: ctx.TraceID(),
+		SpanID:  ctx.SpanID(),
+	}
+	ctx.ForeachBaggageItem(func(k, v string) bool {
+		if cfg.Baggage == nil {
+			cfg.Baggage = make(map[string]string)
+		}
+		cfg.Baggage[k] = v
+		return true
+	})
+	sctx, ok := ctx.(*spanContext)
+	if !ok {
+		return cfg, nil
+	}
+	cfg.Origin = sctx.origin
+	if p, ok := sctx.samplingPriority(); ok {
+		cfg.SamplingPriority = &p
+	}
+	if sctx.trace != nil {
+		sctx.trace.mu.RLock()
+		defer sctx.trace.mu.RUnlock()
+		for k, v := range sctx.trace.tags {
+			if !strings.HasPrefix(k, propagatingTagPrefix) {
+				continue
+			}
+			if cfg.PropagatingTags == nil {
+				cfg.PropagatingTags = make(map[string]string)
+			}
+			cfg.PropagatingTags[k] = v
+		}
+	}
+	return cfg, nil
+}
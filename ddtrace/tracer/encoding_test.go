@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+)
+
+// otherSpanContext implements ddtrace.SpanContext without being a *spanContext.
+type otherSpanContext struct {
+	traceID, spanID uint64
+	baggage         map[string]string
+}
+
+func (c otherSpanContext) SpanID() uint64 { return c.spanID }
+
+func (c otherSpanContext) TraceID() uint64 { return c.traceID }
+
+func (c otherSpanContext) ForeachBaggageItem(handler func(k, v string) bool) {
+	for k, v := range c.baggage {
+		if !handler(k, v) {
+			break
+		}
+	}
+}
+
+func TestSpanContextEncoding(t *testing.T) {
+	encodings := map[string]struct {
+		marshal   func(ddtrace.SpanContext) ([]byte, error)
+		unmarshal func([]byte) (ddtrace.SpanContext, error)
+	}{
+		"json":  {MarshalSpanContextJSON, UnmarshalSpanContextJSON},
+		"proto": {MarshalSpanContextProto, UnmarshalSpanContextProto},
+	}
+	for name, enc := range encodings {
+		t.Run(name, func(t *testing.T) {
+			t.Run("roundtrip", func(t *testing.T) {
+				assert := assert.New(t)
+				tracer := newTracer()
+				defer tracer.Stop()
+				root := tracer.StartSpan("workflow.start", Tag(ext.SamplingPriority, ext.PriorityUserKeep)).(*span)
+				root.SetBaggageItem("order", "42")
+				root.context.origin = "rum"
+
+				data, err := enc.marshal(root.Context())
+				require.NoError(t, err)
+				sctx, err := enc.unmarshal(data)
+				require.NoError(t, err)
+
+				resumed := tracer.StartSpan("workflow.resume", ChildOf(sctx)).(*span)
+				assert.Equal(root.TraceID, resumed.TraceID)
+				assert.Equal(root.SpanID, resumed.ParentID)
+				assert.Equal(float64(ext.PriorityUserKeep), resumed.Metrics[keySamplingPriority])
+				assert.Equal("rum", resumed.Meta[keyOrigin])
+				assert.Equal("42", resumed.BaggageItem("order"))
+				assert.Equal("-4", resumed.context.trace.tags[keyDecisionMaker])
+			})
+
+			t.Run("large-ids", func(t *testing.T) {
+				want := &spanContext{traceID: 1<<64 - 1, spanID: 1<<63 + 1}
+				data, err := enc.marshal(want)
+				require.NoError(t, err)
+				got, err := enc.unmarshal(data)
+				require.NoError(t, err)
+				assert.Equal(t, want.traceID, got.TraceID())
+				assert.Equal(t, want.spanID, got.SpanID())
+				_, ok := got.(*spanContext).samplingPriority()
+				assert.False(t, ok)
+			})
+
+			t.Run("128-bit-ids", func(t *testing.T) {
+				p := NewPropagator(&PropagatorConfig{
+					InjectStyles:  []string{"tracecontext"},
+					ExtractStyles: []string{"tracecontext"},
+				})
+				want, err := p.Extract(TextMapCarrier{
+					traceparentHeader: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				})
+				require.NoError(t, err)
+				data, err := enc.marshal(want)
+				require.NoError(t, err)
+				got, err := enc.unmarshal(data)
+				require.NoError(t, err)
+				assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", got.(*spanContext).TraceID128())
+				headers := TextMapCarrier{}
+				require.NoError(t, p.Inject(got, headers))
+				assert.Equal(t, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01", headers[traceparentHeader])
+			})
+
+			t.Run("other-tracer", func(t *testing.T) {
+				want := otherSpanContext{traceID: 1, spanID: 2, baggage: map[string]string{"key": "value"}}
+				data, err := enc.marshal(want)
+				require.NoError(t, err)
+				got, err := enc.unmarshal(data)
+				require.NoError(t, err)
+				assert.Equal(t, want.traceID, got.TraceID())
+				assert.Equal(t, want.spanID, got.SpanID())
+				assert.Equal(t, "value", got.(*spanContext).baggageItem("key"))
+			})
+
+			t.Run("invalid", func(t *testing.T) {
+				_, err := enc.marshal(&spanContext{})
+				assert.Equal(t, ErrInvalidSpanContext, err)
+				_, err = enc.unmarshal([]byte{0xff})
+				assert.Equal(t, ErrSpanContextCorrupted, err)
+				_, err = enc.unmarshal(nil)
+				assert.Error(t, err)
+			})
+		})
+	}
+}
+
+func TestSpanContextJSON(t *testing.T) {
+	priority := ext.PriorityAutoKeep
+	sctx, err := NewSpanContext(SpanContextConfig{
+		TraceID:          1,
+		SpanID:           2,
+		SamplingPriority: &priority,
+		Baggage:          map[string]string{"a": "b"},
+	})
+	require.NoError(t, err)
+	data, err := MarshalSpanContextJSON(sctx)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"trace_id":"1","span_id":"2","sampling_priority":1,"baggage":{"a":"b"}}`, string(data))
+}
@@ -0,0 +1,156 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// TraceExporter is a secondary consumer of finished traces, called alongside their
+// submission to the agent. It receives every finished trace, regardless of the sampling
+// decision, e.g. to retain all the spans of an application for audit purposes.
+// TraceExporters are registered with WithTraceExporter.
+type TraceExporter interface {
+	// Export exports the spans of a finished trace. It is called from a single
+	// goroutine, in the order in which the traces finished. Errors are logged.
+	Export(trace []ExportedSpan) error
+}
+
+// ExportedSpan holds the data of a finished span, as received by a TraceExporter.
+type ExportedSpan struct {
+	Name     string             `json:"name"`
+	Service  string             `json:"service"`
+	Resource string             `json:"resource"`
+	Type     string             `json:"type,omitempty"`
+	TraceID  uint64             `json:"trace_id"`
+	SpanID   uint64             `json:"span_id"`
+	ParentID uint64             `json:"parent_id"`
+	Start    time.Time          `json:"start"`
+	Duration time.Duration      `json:"duration"` // in nanoseconds once encoded
+	Error    bool               `json:"error"`
+	Meta     map[string]string  `json:"meta,omitempty"`
+	Metrics  map[string]float64 `json:"metrics,omitempty"` // only finite values
+}
+
+// newExportedSpan returns the ExportedSpan holding the data of the finished span s.
+func newExportedSpan(s *span) ExportedSpan {
+	s.RLock()
+	defer s.RUnlock()
+	e := ExportedSpan{
+		Name:     s.Name,
+		Service:  s.Service,
+		Resource: s.Resource,
+		Type:     s.Type,
+		TraceID:  s.TraceID,
+		SpanID:   s.SpanID,
+		ParentID: s.ParentID,
+		Start:    time.Unix(0, s.Start),
+		Duration: time.Duration(s.Duration),
+		Error:    s.Error != 0,
+	}
+	if len(s.Meta) > 0 {
+		e.Meta = make(map[string]string, len(s.Meta))
+		for k, v := range s.Meta {
+			e.Meta[k] = v
+		}
+	}
+	if len(s.Metrics) > 0 {
+		e.Metrics = make(map[string]float64, len(s.Metrics))
+		for k, v := range s.Metrics {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				continue
+			}
+			e.Metrics[k] = v
+		}
+	}
+	return e
+}
+
+// exportQueueSize is the buffer size of the channel of traces to export.
+const exportQueueSize = 1000
+
+// exportTrace queues trace to be passed to the TraceExporters of t, if any.
+func (t *tracer) exportTrace(trace []*span) {
+	if t.exported == nil {
+		return
+	}
+	select {
+	case <-t.stop:
+		return
+	default:
+	}
+	select {
+	case t.exported <- trace:
+	default:
+		t.config.statsd.Incr("datadog.tracer.traces_dropped", []string{"reason:export_queue_full"}, 1)
+		log.Error("export queue full, dropping %d traces", len(trace))
+	}
+}
+
+// exportWorker passes the queued traces to the TraceExporters of t, until t is stopped.
+func (t *tracer) exportWorker() {
+	for {
+		select {
+		case trace := <-t.exported:
+			t.export(trace)
+		case <-t.stop:
+			// export the traces queued before stopping
+			for {
+				select {
+				case trace := <-t.exported:
+					t.export(trace)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// export passes trace to the TraceExporters of t.
+func (t *tracer) export(trace []*span) {
+	spans := make([]ExportedSpan, len(trace))
+	for i, s := range trace {
+		spans[i] = newExportedSpan(s)
+	}
+	for _, e := range t.config.traceExporters {
+		if err := e.Export(spans); err != nil {
+			t.config.statsd.Incr("datadog.tracer.export_errors", nil, 1)
+			log.Error("Error exporting trace: %v", err)
+		}
+	}
+}
+
+// jsonExporter is a TraceExporter writing the spans as JSON lines.
+type jsonExporter struct {
+	mu  sync.Mutex // guards enc
+	enc *json.Encoder
+}
+
+// NewJSONExporter returns a TraceExporter writing each span of the exported traces to w
+// as a JSON object on its own line, e.g. to be used with WithTraceExporter to keep all
+// the spans of an application in a file.
+func NewJSONExporter(w io.Writer) TraceExporter {
+	return &jsonExporter{enc: json.NewEncoder(w)}
+}
+
+// Export implements TraceExporter.
+func (e *jsonExporter) Export(trace []ExportedSpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for i := range trace {
+		if err := e.enc.Encode(&trace[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
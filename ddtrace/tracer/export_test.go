@@ -0,0 +1,155 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+)
+
+// testExporter is a TraceExporter recording the exported traces.
+type testExporter struct {
+	mu     sync.Mutex
+	traces [][]ExportedSpan
+	err    error
+}
+
+func (e *testExporter) Export(trace []ExportedSpan) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.traces = append(e.traces, trace)
+	return e.err
+}
+
+func (e *testExporter) Traces() [][]ExportedSpan {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.traces
+}
+
+func TestTraceExporter(t *testing.T) {
+	t.Run("all-traces", func(t *testing.T) {
+		var e1, e2 testExporter
+		tr, _, flush, stop := startTestTracer(t, WithTraceExporter(&e1), WithTraceExporter(&e2))
+		defer stop()
+
+		root := tr.StartSpan("web.request", ResourceName("GET /"), Tag(ext.HTTPCode, "200"))
+		tr.StartSpan("db.query", ChildOf(root.Context()), Tag("rows", 3)).Finish()
+		root.Finish()
+		tr.StartSpan("web.request", Tag(ext.SamplingPriority, ext.PriorityUserReject)).Finish()
+
+		flush(2)
+		assert.Eventually(t, func() bool { return len(e1.Traces()) == 2 && len(e2.Traces()) == 2 }, 5*time.Second, 10*time.Millisecond)
+		trace := e1.Traces()[0]
+		require.Len(t, trace, 2)
+		s := trace[0]
+		assert.Equal(t, "web.request", s.Name)
+		assert.Equal(t, "GET /", s.Resource)
+		assert.Equal(t, "200", s.Meta[ext.HTTPCode])
+		assert.Equal(t, root.Context().TraceID(), s.TraceID)
+		assert.Equal(t, root.Context().SpanID(), s.SpanID)
+		assert.Equal(t, s.SpanID, trace[1].ParentID)
+		assert.Equal(t, 3.0, trace[1].Metrics["rows"])
+		assert.False(t, s.Start.IsZero())
+		assert.Equal(t, float64(ext.PriorityUserReject), e2.Traces()[1][0].Metrics[keySamplingPriority])
+	})
+
+	t.Run("dropped-by-sampler", func(t *testing.T) {
+		var e testExporter
+		tr, transport, flush, stop := startTestTracer(t, WithTraceExporter(&e))
+		defer stop()
+		// as when computing stats on the client side, where unsampled traces are dropped
+		s := tr.StartSpan("web.request", Tag(ext.SamplingPriority, ext.PriorityUserReject)).(*span)
+		atomic.StoreInt64((*int64)(&s.context.trace.samplingDecision), int64(decisionDrop))
+		s.Finish()
+		tr.StartSpan("web.request").Finish()
+
+		flush(1)
+		assert.Eventually(t, func() bool { return len(e.Traces()) == 2 }, 5*time.Second, 10*time.Millisecond)
+		assert.Len(t, transport.Traces(), 1)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var tg testStatsdClient
+		e := testExporter{err: errors.New("broken pipe")}
+		tr, _, _, stop := startTestTracer(t, WithTraceExporter(&e), withStatsdClient(&tg))
+		tr.StartSpan("web.request").Finish()
+		stop()
+		assert.Len(t, e.Traces(), 1)
+		assert.Equal(t, 1, tg.CallsByName()["datadog.tracer.export_errors"])
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		var e testExporter
+		tr, _, _, stop := startTestTracer(t, WithTraceExporter(&e))
+		for i := 0; i < 10; i++ {
+			tr.StartSpan("web.request").Finish()
+		}
+		stop()
+		// the queued traces are exported before stopping
+		assert.Len(t, e.Traces(), 10)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		tr := newUnstartedTracer()
+		assert.Nil(t, tr.exported)
+		tr.exportTrace([]*span{newBasicSpan("web.request")})
+	})
+}
+
+func TestJSONExporter(t *testing.T) {
+	var buf bytes.Buffer
+	e := NewJSONExporter(&buf)
+	err := e.Export([]ExportedSpan{
+		{Name: "db.query", TraceID: 1, SpanID: 2, ParentID: 3, Metrics: map[string]float64{"rows": 3}},
+		{Name: "web.request", TraceID: 1, SpanID: 3, Error: true, Meta: map[string]string{"http.status_code": "500"}},
+	})
+	require.NoError(t, err)
+
+	var spans []map[string]interface{}
+	sc := bufio.NewScanner(&buf)
+	for sc.Scan() {
+		var s map[string]interface{}
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &s))
+		spans = append(spans, s)
+	}
+	require.Len(t, spans, 2)
+	assert.Equal(t, "db.query", spans[0]["name"])
+	assert.Equal(t, 3.0, spans[0]["parent_id"])
+	assert.Equal(t, map[string]interface{}{"rows": 3.0}, spans[0]["metrics"])
+	assert.Equal(t, true, spans[1]["error"])
+	assert.Equal(t, map[string]interface{}{"http.status_code": "500"}, spans[1]["meta"])
+}
+
+func TestNewExportedSpan(t *testing.T) {
+	s := newBasicSpan("web.request")
+	s.Error = 1
+	s.Duration = int64(time.Second)
+	s.Metrics["nan"] = math.NaN()
+	s.Metrics["inf"] = math.Inf(1)
+	e := newExportedSpan(s)
+	assert.True(t, e.Error)
+	assert.Equal(t, time.Second, e.Duration)
+	assert.Equal(t, s.Start, e.Start.UnixNano())
+	assert.NotContains(t, e.Metrics, "nan")
+	assert.NotContains(t, e.Metrics, "inf")
+
+	// the exported span is a copy
+	s.Meta["k"] = "v"
+	assert.NotContains(t, e.Meta, "k")
+}
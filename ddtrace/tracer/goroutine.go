@@ -0,0 +1,200 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"bytes"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// goroutineSpans holds the active span of every goroutine which started spans while
+// WithGoroutineLocalSpans was enabled.
+var goroutineSpans = newGoroutineRegistry()
+
+// maxGoroutineSpans is the maximum number of unfinished spans kept by the registry. Once
+// reached, the spans of the goroutines which exited are removed, and if the registry is
+// still full, new spans aren't registered until some finish.
+var maxGoroutineSpans = 10000
+
+// goroutineSweepInterval is the minimum interval between two removals of the spans of
+// the goroutines which exited, which requires the stack traces of all goroutines.
+const goroutineSweepInterval = time.Second
+
+// goroutineRegistry keeps track of the unfinished spans started by each goroutine.
+type goroutineRegistry struct {
+	// size holds the number of spans in active. It allows skipping the goroutine
+	// lookup when the registry is empty, which is always the case unless enabled.
+	size int32 // accessed atomically
+
+	mu        sync.Mutex
+	active    map[uint64][]*span // goroutine ID -> unfinished spans, in start order
+	lastSweep time.Time          // time of the last removal of the spans of exited goroutines
+}
+
+func newGoroutineRegistry() *goroutineRegistry {
+	return &goroutineRegistry{active: make(map[uint64][]*span)}
+}
+
+// push makes s the active span of the calling goroutine until it finishes.
+func (r *goroutineRegistry) push(s *span) {
+	gid := goroutineID()
+	if gid == 0 {
+		return
+	}
+	if int(atomic.LoadInt32(&r.size)) >= maxGoroutineSpans {
+		r.sweep()
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if n := atomic.LoadInt32(&r.size); int(n) >= maxGoroutineSpans {
+		log.Debug("Not registering span %d as the active span of its goroutine: %d unfinished spans are already registered", s.SpanID, n)
+		return
+	}
+	s.goroutineID = gid
+	r.active[gid] = append(r.active[gid], s)
+	atomic.AddInt32(&r.size, 1)
+}
+
+// pop removes s from the spans of the goroutine which started it. The span started last
+// among the remaining ones, if any, becomes the active span of the goroutine.
+func (r *goroutineRegistry) pop(s *span) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spans := r.active[s.goroutineID]
+	for i := len(spans) - 1; i >= 0; i-- {
+		if spans[i] != s {
+			continue
+		}
+		if len(spans) == 1 {
+			delete(r.active, s.goroutineID)
+		} else {
+			copy(spans[i:], spans[i+1:])
+			spans[len(spans)-1] = nil
+			r.active[s.goroutineID] = spans[:len(spans)-1]
+		}
+		atomic.AddInt32(&r.size, -1)
+		return
+	}
+}
+
+// get returns the active span of the calling goroutine, if any.
+func (r *goroutineRegistry) get() (*span, bool) {
+	if atomic.LoadInt32(&r.size) == 0 {
+		return nil, false
+	}
+	gid := goroutineID()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spans := r.active[gid]
+	if len(spans) == 0 {
+		return nil, false
+	}
+	return spans[len(spans)-1], true
+}
+
+// sweep removes the spans of the goroutines which exited without finishing them. It does
+// nothing if called less than goroutineSweepInterval after the previous call. The stack
+// traces of all goroutines, which stop the world, are collected without holding r.mu.
+func (r *goroutineRegistry) sweep() {
+	r.mu.Lock()
+	now := time.Now()
+	if now.Sub(r.lastSweep) < goroutineSweepInterval {
+		r.mu.Unlock()
+		return
+	}
+	r.lastSweep = now
+	// only the goroutines registered before collecting the stack traces can be told
+	// to have exited, as the IDs of the goroutines aren't reused
+	registered := make([]uint64, 0, len(r.active))
+	for gid := range r.active {
+		registered = append(registered, gid)
+	}
+	r.mu.Unlock()
+
+	alive, ok := goroutineIDs()
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, gid := range registered {
+		if _, ok := alive[gid]; ok {
+			continue
+		}
+		if spans, ok := r.active[gid]; ok {
+			delete(r.active, gid)
+			atomic.AddInt32(&r.size, -int32(len(spans)))
+		}
+	}
+}
+
+// maxGoroutineStacksSize is the maximum size in bytes of the stack traces of all
+// goroutines collected to find the goroutines which exited.
+const maxGoroutineStacksSize = 16 << 20
+
+// goroutineIDs returns the IDs of all the goroutines, as found in their stack traces. It
+// returns false if the stack traces exceed maxGoroutineStacksSize.
+func goroutineIDs() (map[uint64]struct{}, bool) {
+	buf := make([]byte, 64*1024)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		if len(buf) >= maxGoroutineStacksSize {
+			return nil, false
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	ids := make(map[uint64]struct{})
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if id := parseGoroutineID(stack); id != 0 {
+			ids[id] = struct{}{}
+		}
+	}
+	return ids, true
+}
+
+// goroutineID returns the ID of the calling goroutine, as found in the header of its
+// stack trace (e.g. "goroutine 18 [running]:"). It returns 0 if the ID can't be parsed.
+func goroutineID() uint64 {
+	var buf [64]byte
+	return parseGoroutineID(buf[:runtime.Stack(buf[:], false)])
+}
+
+// parseGoroutineID returns the ID of the goroutine found in the header of stack. It
+// returns 0 if the ID can't be parsed.
+func parseGoroutineID(stack []byte) uint64 {
+	b := bytes.TrimPrefix(stack, []byte("goroutine "))
+	if i := bytes.IndexByte(b, ' '); i > 0 {
+		b = b[:i]
+	}
+	id, err := strconv.ParseUint(string(b), 10, 64)
+	if err != nil {
+		return 0
+	}
+	return id
+}
+
+// ActiveSpan returns the last span started by the calling goroutine which hasn't finished
+// yet. It is meant for code which doesn't have access to a context.Context holding the
+// span, and only finds spans when the tracer was started with WithGoroutineLocalSpans.
+// A second return value indicates if a span was found. If no span is found, a no-op span
+// is returned.
+func ActiveSpan() (Span, bool) {
+	if s, ok := goroutineSpans.get(); ok {
+		return s, true
+	}
+	return &internal.NoopSpan{}, false
+}
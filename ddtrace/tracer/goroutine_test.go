@@ -0,0 +1,204 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
+)
+
+func TestGoroutineID(t *testing.T) {
+	id := goroutineID()
+	assert.NotZero(t, id)
+	assert.Equal(t, id, goroutineID())
+
+	var other uint64
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		other = goroutineID()
+	}()
+	wg.Wait()
+	assert.NotZero(t, other)
+	assert.NotEqual(t, id, other)
+}
+
+func TestGoroutineLocalSpans(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		_, _, _, stop := startTestTracer(t)
+		defer stop()
+
+		s := StartSpan("legacy")
+		defer s.Finish()
+		// the span isn't registered, which would require its goroutine ID
+		assert.Zero(t, s.(*span).goroutineID)
+		active, ok := ActiveSpan()
+		assert.False(t, ok)
+		assert.IsType(t, &internal.NoopSpan{}, active)
+		_, ok = SpanFromContext(context.Background())
+		assert.False(t, ok)
+	})
+
+	t.Run("enabled", func(t *testing.T) {
+		assert := assert.New(t)
+		_, _, _, stop := startTestTracer(t, WithGoroutineLocalSpans(true))
+		defer stop()
+
+		root := StartSpan("root")
+		active, ok := ActiveSpan()
+		assert.True(ok)
+		assert.Equal(root, active)
+
+		// legacy code without the context still finds its parent
+		child := StartSpan("child", ChildOf(active.Context()))
+		assert.Equal(root.Context().SpanID(), child.(*span).ParentID)
+		active, _ = ActiveSpan()
+		assert.Equal(child, active)
+
+		// contexts without a span fall back to the active span
+		active, ok = SpanFromContext(context.Background())
+		assert.True(ok)
+		assert.Equal(child, active)
+		active, ok = SpanFromContext(nil)
+		assert.True(ok)
+		assert.Equal(child, active)
+		s, ctx := StartSpanFromContext(context.Background(), "fallback")
+		assert.Equal(child.Context().SpanID(), s.(*span).ParentID)
+		s.Finish()
+
+		// the span of the context takes precedence over the active span
+		active, ok = SpanFromContext(ctx)
+		assert.True(ok)
+		assert.Equal(s, active)
+
+		child.Finish()
+		active, _ = ActiveSpan()
+		assert.Equal(root, active)
+		root.Finish()
+		_, ok = ActiveSpan()
+		assert.False(ok)
+	})
+
+	t.Run("goroutines", func(t *testing.T) {
+		_, _, _, stop := startTestTracer(t, WithGoroutineLocalSpans(true))
+		defer stop()
+
+		root := StartSpan("root")
+		defer root.Finish()
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, ok := ActiveSpan()
+			assert.False(t, ok)
+			s := StartSpan("worker")
+			active, ok := ActiveSpan()
+			assert.True(t, ok)
+			assert.Equal(t, s, active)
+			s.Finish()
+		}()
+		wg.Wait()
+		active, ok := ActiveSpan()
+		assert.True(t, ok)
+		assert.Equal(t, root, active)
+	})
+
+	t.Run("out-of-order", func(t *testing.T) {
+		_, _, _, stop := startTestTracer(t, WithGoroutineLocalSpans(true))
+		defer stop()
+
+		root := StartSpan("root")
+		parent := StartSpan("parent", ChildOf(root.Context()))
+		child := StartSpan("child", ChildOf(parent.Context()))
+
+		// finishing a span which isn't active keeps the active span
+		parent.Finish()
+		active, _ := ActiveSpan()
+		assert.Equal(t, child, active)
+
+		// finished spans are skipped when restoring
+		child.Finish()
+		active, _ = ActiveSpan()
+		assert.Equal(t, root, active)
+
+		root.Finish()
+		_, ok := ActiveSpan()
+		require.False(t, ok)
+	})
+
+	t.Run("finished", func(t *testing.T) {
+		_, _, _, stop := startTestTracer(t, WithGoroutineLocalSpans(true))
+		defer stop()
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < 100; j++ {
+					root := StartSpan("root")
+					parent := StartSpan("parent", ChildOf(root.Context()))
+					child := StartSpan("child", ChildOf(parent.Context()))
+					parent.Finish()
+					root.Finish()
+					child.Finish()
+				}
+			}()
+		}
+		wg.Wait()
+
+		// the registry doesn't grow with finished spans
+		goroutineSpans.mu.Lock()
+		defer goroutineSpans.mu.Unlock()
+		assert.Empty(t, goroutineSpans.active)
+		assert.Zero(t, goroutineSpans.size)
+	})
+
+	t.Run("unfinished", func(t *testing.T) {
+		_, _, _, stop := startTestTracer(t, WithGoroutineLocalSpans(true))
+		defer stop()
+		defer func(max int) { maxGoroutineSpans = max }(maxGoroutineSpans)
+		maxGoroutineSpans = 10
+		defer func(r *goroutineRegistry) { goroutineSpans = r }(goroutineSpans)
+		goroutineSpans = newGoroutineRegistry()
+
+		// goroutines exiting without finishing their spans
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				StartSpan("leaked")
+			}()
+		}
+		wg.Wait()
+		assert.EqualValues(t, 10, atomic.LoadInt32(&goroutineSpans.size))
+
+		// the spans of the goroutines which exited are removed once the registry is full
+		s := StartSpan("root")
+		active, ok := ActiveSpan()
+		assert.True(t, ok)
+		assert.Equal(t, s, active)
+		assert.EqualValues(t, 1, atomic.LoadInt32(&goroutineSpans.size))
+
+		// once full with the spans of running goroutines, new spans aren't registered
+		for i := 0; i < 9; i++ {
+			StartSpan("leaked")
+		}
+		extra := StartSpan("extra")
+		active, _ = ActiveSpan()
+		assert.NotEqual(t, extra, active)
+		assert.EqualValues(t, 10, atomic.LoadInt32(&goroutineSpans.size))
+	})
+}
@@ -0,0 +1,138 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// HostnameStrategy specifies how the tracer looks up the hostname reported on spans,
+// when it isn't set with WithHostname or the DD_TRACE_SOURCE_HOSTNAME environment variable.
+type HostnameStrategy string
+
+const (
+	// HostnameDefault reports no hostname, unless it is set explicitly.
+	HostnameDefault HostnameStrategy = ""
+	// HostnameOS reports the hostname given by the kernel, as returned by os.Hostname.
+	HostnameOS HostnameStrategy = "os"
+	// HostnameEC2 reports the ID of the EC2 instance, looked up from its metadata service.
+	HostnameEC2 HostnameStrategy = "ec2"
+	// HostnameGCP reports the hostname of the GCE instance, looked up from its metadata server.
+	HostnameGCP HostnameStrategy = "gcp"
+	// HostnameDisabled never reports a hostname, even when it is set explicitly.
+	HostnameDisabled HostnameStrategy = "none"
+)
+
+// metadataTimeout is the maximum duration of the lookups of hostnames from cloud
+// metadata services, which are not reachable outside of their cloud.
+const metadataTimeout = 300 * time.Millisecond
+
+var (
+	// ec2MetadataURL is the address of the EC2 instance metadata service.
+	ec2MetadataURL = "http://169.254.169.254/latest"
+	// gcpMetadataURL is the address of the GCE metadata server.
+	gcpMetadataURL = "http://metadata.google.internal/computeMetadata/v1"
+)
+
+// resolveHostnameStrategy returns the hostname strategy configured through the
+// environment.
+func resolveHostnameStrategy() HostnameStrategy {
+	if v := os.Getenv("DD_TRACE_HOSTNAME_STRATEGY"); v != "" {
+		switch s := HostnameStrategy(strings.ToLower(v)); s {
+		case HostnameOS, HostnameEC2, HostnameGCP, HostnameDisabled:
+			return s
+		default:
+			log.Warn("ignoring DD_TRACE_HOSTNAME_STRATEGY: unknown strategy %q", v)
+		}
+	}
+	if os.Getenv("DD_TRACE_REPORT_HOSTNAME") == "true" {
+		return HostnameOS
+	}
+	return HostnameDefault
+}
+
+// lookupHostname returns the hostname according to strategy s, or an empty string
+// if it can't be found.
+func lookupHostname(s HostnameStrategy) string {
+	var (
+		name string
+		err  error
+	)
+	switch s {
+	case HostnameOS:
+		name, err = os.Hostname()
+	case HostnameEC2:
+		name, err = ec2InstanceID()
+	case HostnameGCP:
+		name, err = gcpHostname()
+	default:
+		return ""
+	}
+	if err != nil {
+		log.Warn("unable to look up hostname (strategy %q): %v", s, err)
+		return ""
+	}
+	return name
+}
+
+// ec2InstanceID returns the ID of the current EC2 instance using IMDSv2.
+func ec2InstanceID() (string, error) {
+	req, err := http.NewRequest(http.MethodPut, ec2MetadataURL+"/api/token", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	token, err := fetchMetadata(req)
+	if err != nil {
+		return "", err
+	}
+	req, err = http.NewRequest(http.MethodGet, ec2MetadataURL+"/meta-data/instance-id", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", token)
+	return fetchMetadata(req)
+}
+
+// gcpHostname returns the hostname of the current GCE instance.
+func gcpHostname() (string, error) {
+	req, err := http.NewRequest(http.MethodGet, gcpMetadataURL+"/instance/hostname", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	return fetchMetadata(req)
+}
+
+// fetchMetadata sends req to a metadata service and returns the body of the response.
+func fetchMetadata(req *http.Request) (string, error) {
+	client := &http.Client{Timeout: metadataTimeout}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s %s: unexpected status %s", req.Method, req.URL, resp.Status)
+	}
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	if err != nil {
+		return "", err
+	}
+	v := strings.TrimSpace(string(b))
+	if v == "" {
+		return "", fmt.Errorf("%s %s: empty response", req.Method, req.URL)
+	}
+	return v, nil
+}
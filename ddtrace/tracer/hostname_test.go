@@ -0,0 +1,134 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHostnameStrategy(t *testing.T) {
+	osHostname, err := os.Hostname()
+	if err != nil {
+		t.Skip(err)
+	}
+
+	t.Run("default", func(t *testing.T) {
+		c := newConfig()
+		assert.Equal(t, "", c.hostname)
+	})
+
+	t.Run("os", func(t *testing.T) {
+		c := newConfig(WithHostnameStrategy(HostnameOS))
+		assert.Equal(t, osHostname, c.hostname)
+	})
+
+	t.Run("report-hostname-env", func(t *testing.T) {
+		os.Setenv("DD_TRACE_REPORT_HOSTNAME", "true")
+		defer os.Unsetenv("DD_TRACE_REPORT_HOSTNAME")
+		c := newConfig()
+		assert.Equal(t, osHostname, c.hostname)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		os.Setenv("DD_TRACE_HOSTNAME_STRATEGY", "OS")
+		defer os.Unsetenv("DD_TRACE_HOSTNAME_STRATEGY")
+		c := newConfig()
+		assert.Equal(t, osHostname, c.hostname)
+	})
+
+	t.Run("env-invalid", func(t *testing.T) {
+		os.Setenv("DD_TRACE_HOSTNAME_STRATEGY", "k8s")
+		defer os.Unsetenv("DD_TRACE_HOSTNAME_STRATEGY")
+		c := newConfig()
+		assert.Equal(t, HostnameDefault, c.hostnameStrategy)
+		assert.Equal(t, "", c.hostname)
+	})
+
+	t.Run("explicit", func(t *testing.T) {
+		os.Setenv("DD_TRACE_SOURCE_HOSTNAME", "hostname-env")
+		defer os.Unsetenv("DD_TRACE_SOURCE_HOSTNAME")
+		c := newConfig(WithHostnameStrategy(HostnameOS))
+		assert.Equal(t, "hostname-env", c.hostname)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		os.Setenv("DD_TRACE_REPORT_HOSTNAME", "true")
+		defer os.Unsetenv("DD_TRACE_REPORT_HOSTNAME")
+		c := newConfig(WithHostname("hostname"), WithHostnameStrategy(HostnameDisabled))
+		assert.Equal(t, "", c.hostname)
+	})
+
+	t.Run("disabled-env", func(t *testing.T) {
+		os.Setenv("DD_TRACE_HOSTNAME_STRATEGY", "none")
+		defer os.Unsetenv("DD_TRACE_HOSTNAME_STRATEGY")
+		os.Setenv("DD_TRACE_SOURCE_HOSTNAME", "hostname-env")
+		defer os.Unsetenv("DD_TRACE_SOURCE_HOSTNAME")
+		c := newConfig()
+		assert.Equal(t, "", c.hostname)
+	})
+}
+
+func TestHostnameMetadata(t *testing.T) {
+	t.Run("ec2", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/latest/api/token":
+				if r.Method != http.MethodPut || r.Header.Get("X-aws-ec2-metadata-token-ttl-seconds") == "" {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				w.Write([]byte("token"))
+			case "/latest/meta-data/instance-id":
+				if r.Header.Get("X-aws-ec2-metadata-token") != "token" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Write([]byte("i-0123456789abcdef0\n"))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		defer srv.Close()
+		defer func(old string) { ec2MetadataURL = old }(ec2MetadataURL)
+		ec2MetadataURL = srv.URL + "/latest"
+
+		c := newConfig(WithHostnameStrategy(HostnameEC2))
+		assert.Equal(t, "i-0123456789abcdef0", c.hostname)
+	})
+
+	t.Run("gcp", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/computeMetadata/v1/instance/hostname" || r.Header.Get("Metadata-Flavor") != "Google" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte("instance-1.c.project.internal"))
+		}))
+		defer srv.Close()
+		defer func(old string) { gcpMetadataURL = old }(gcpMetadataURL)
+		gcpMetadataURL = srv.URL + "/computeMetadata/v1"
+
+		c := newConfig(WithHostnameStrategy(HostnameGCP))
+		assert.Equal(t, "instance-1.c.project.internal", c.hostname)
+	})
+
+	t.Run("unavailable", func(t *testing.T) {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+		}))
+		defer srv.Close()
+		defer func(old string) { gcpMetadataURL = old }(gcpMetadataURL)
+		gcpMetadataURL = srv.URL
+
+		c := newConfig(WithHostnameStrategy(HostnameGCP))
+		assert.Equal(t, "", c.hostname)
+	})
+}
@@ -0,0 +1,38 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import "github.com/codebrick-corp/dd-trace-go/internal/integrations"
+
+// Integration describes a contrib package imported by the application.
+type Integration struct {
+	// Name is the path of the contrib package under the contrib directory,
+	// e.g. "gorilla/mux".
+	Name string
+	// Library is the import path of the instrumented library, e.g.
+	// "github.com/gorilla/mux". It is empty for generic integrations.
+	Library string
+	// Version is the version of the instrumented library used by the application,
+	// or empty when it is unknown.
+	Version string
+}
+
+// Integrations returns the contrib packages imported by the application, sorted by
+// name, along with the versions of the libraries they instrument. It does not require
+// the tracer to be started, and can be used to assert at startup that the expected
+// instrumentation is loaded:
+//
+//	for _, in := range tracer.Integrations() {
+//		log.Printf("tracing %s %s", in.Library, in.Version)
+//	}
+func Integrations() []Integration {
+	list := integrations.List()
+	ins := make([]Integration, len(list))
+	for i, in := range list {
+		ins[i] = Integration(in)
+	}
+	return ins
+}
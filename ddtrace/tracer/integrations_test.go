@@ -0,0 +1,26 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
+)
+
+func TestIntegrations(t *testing.T) {
+	// registering an integration would leak into the other tests
+	list := integrations.List()
+	ins := Integrations()
+	if assert.Len(t, ins, len(list)) {
+		for i, in := range list {
+			assert.Equal(t, in.Name, ins[i].Name)
+			assert.Equal(t, in.Version, ins[i].Version)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// spanLink is the JSON representation of a ddtrace.SpanLink, as stored in the
+// "_dd.span_links" tag.
+type spanLink struct {
+	TraceID    string            `json:"trace_id"`
+	SpanID     string            `json:"span_id"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+// encodeSpanLinks returns the JSON representation of links. Trace and span IDs
+// are encoded as zero-padded hexadecimal strings.
+func encodeSpanLinks(links []ddtrace.SpanLink) string {
+	out := make([]spanLink, len(links))
+	for i, l := range links {
+		out[i] = spanLink{
+			TraceID:    fmt.Sprintf("%032x", l.TraceID),
+			SpanID:     fmt.Sprintf("%016x", l.SpanID),
+			Attributes: l.Attributes,
+		}
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		log.Error("Failed to encode span links: %v", err)
+		return ""
+	}
+	return string(b)
+}
+
+// AddSpanLinks adds links to s after it started, e.g. when the spans or objects it
+// relates to are only known once its operation completed. It has no effect on finished
+// spans, nor on spans which don't support links.
+func AddSpanLinks(s Span, links ...ddtrace.SpanLink) {
+	if l, ok := s.(interface{ AddSpanLinks(...ddtrace.SpanLink) }); ok {
+		l.AddSpanLinks(links...)
+	}
+}
+
+// StartLinkedSpans starts one span for each of the given carriers, as is typically
+// needed when consuming a batch of messages which were produced by different traces.
+// Instead of arbitrarily parenting every span to a single producer, each span is the
+// root of a new trace and links to the span context extracted from its carrier. Spans
+// are returned in the same order as carriers and must be finished by the caller.
+//
+// Carriers from which no span context can be extracted result in spans without links.
+func StartLinkedSpans(operationName string, carriers []interface{}, opts ...StartSpanOption) []Span {
+	spans := make([]Span, len(carriers))
+	for i, carrier := range carriers {
+		spanOpts := opts
+		if sctx, err := Extract(carrier); err == nil {
+			link := ddtrace.SpanLink{TraceID: sctx.TraceID(), SpanID: sctx.SpanID()}
+			spanOpts = append(spanOpts[:len(spanOpts):len(spanOpts)], WithSpanLinks([]ddtrace.SpanLink{link}))
+		}
+		spans[i] = StartSpan(operationName, spanOpts...)
+	}
+	return spans
+}
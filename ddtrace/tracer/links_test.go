@@ -0,0 +1,100 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
+)
+
+func TestEncodeSpanLinks(t *testing.T) {
+	links := []ddtrace.SpanLink{
+		{TraceID: 1, SpanID: 2},
+		{TraceID: 255, SpanID: 16, Attributes: map[string]string{"link.kind": "producer"}},
+	}
+	assert.Equal(t,
+		`[{"trace_id":"00000000000000000000000000000001","span_id":"0000000000000002"},`+
+			`{"trace_id":"000000000000000000000000000000ff","span_id":"0000000000000010","attributes":{"link.kind":"producer"}}]`,
+		encodeSpanLinks(links))
+}
+
+func TestWithSpanLinks(t *testing.T) {
+	tracer, _, _, stop := startTestTracer(t)
+	defer stop()
+
+	t.Run("none", func(t *testing.T) {
+		s := tracer.StartSpan("op").(*span)
+		_, ok := s.Meta[keySpanLinks]
+		assert.False(t, ok)
+	})
+
+	t.Run("multiple", func(t *testing.T) {
+		s := tracer.StartSpan("op",
+			WithSpanLinks([]ddtrace.SpanLink{{TraceID: 1, SpanID: 2}}),
+			WithSpanLinks([]ddtrace.SpanLink{{TraceID: 3, SpanID: 4}}),
+		).(*span)
+		assert.Equal(t,
+			`[{"trace_id":"00000000000000000000000000000001","span_id":"0000000000000002"},`+
+				`{"trace_id":"00000000000000000000000000000003","span_id":"0000000000000004"}]`,
+			s.Meta[keySpanLinks])
+	})
+}
+
+func TestAddSpanLinks(t *testing.T) {
+	tracer, _, _, stop := startTestTracer(t)
+	defer stop()
+
+	s := tracer.StartSpan("op", WithSpanLinks([]ddtrace.SpanLink{{TraceID: 1, SpanID: 2}})).(*span)
+	AddSpanLinks(s, ddtrace.SpanLink{Attributes: map[string]string{"link.kind": "span-pointer"}})
+	assert.Equal(t,
+		`[{"trace_id":"00000000000000000000000000000001","span_id":"0000000000000002"},`+
+			`{"trace_id":"00000000000000000000000000000000","span_id":"0000000000000000","attributes":{"link.kind":"span-pointer"}}]`,
+		s.Meta[keySpanLinks])
+
+	s.Finish()
+	AddSpanLinks(s, ddtrace.SpanLink{TraceID: 3, SpanID: 4})
+	assert.Len(t, s.links, 2)
+
+	// no-op for spans without links support
+	AddSpanLinks(internal.NoopSpan{}, ddtrace.SpanLink{TraceID: 3, SpanID: 4})
+}
+
+func TestStartLinkedSpans(t *testing.T) {
+	_, _, _, stop := startTestTracer(t)
+	defer stop()
+
+	var carriers []interface{}
+	var producers []Span
+	for i := 0; i < 3; i++ {
+		p := StartSpan("producer")
+		c := TextMapCarrier{}
+		require.NoError(t, Inject(p.Context(), c))
+		producers = append(producers, p)
+		carriers = append(carriers, c)
+	}
+	// a message produced by an untraced application
+	carriers = append(carriers, TextMapCarrier{})
+
+	spans := StartLinkedSpans("consumer", carriers, ServiceName("batch-consumer"))
+	require.Len(t, spans, 4)
+	for i, s := range spans {
+		sp := s.(*span)
+		assert.Equal(t, "batch-consumer", sp.Service)
+		assert.Zero(t, sp.ParentID, "span should be the root of a new trace")
+		if i == len(producers) {
+			assert.NotContains(t, sp.Meta, keySpanLinks)
+			continue
+		}
+		p := producers[i].Context()
+		assert.NotEqual(t, p.TraceID(), sp.TraceID)
+		assert.Equal(t, encodeSpanLinks([]ddtrace.SpanLink{{TraceID: p.TraceID(), SpanID: p.SpanID()}}), sp.Meta[keySpanLinks])
+	}
+}
@@ -16,6 +16,7 @@ import (
 
 	"github.com/codebrick-corp/dd-trace-go/internal/appsec"
 	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+	"github.com/codebrick-corp/dd-trace-go/internal/integrations"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 	"github.com/codebrick-corp/dd-trace-go/internal/osinfo"
 	"github.com/codebrick-corp/dd-trace-go/internal/version"
@@ -51,6 +52,7 @@ type startupInfo struct {
 	LambdaMode                  string            `json:"lambda_mode"`                    // Whether or not the client has enabled lambda mode
 	AppSec                      bool              `json:"appsec"`                         // AppSec status: true when started, false otherwise.
 	AgentFeatures               agentFeatures     `json:"agent_features"`                 // Lists the capabilities of the agent.
+	Integrations                map[string]string `json:"integrations,omitempty"`         // Versions of the libraries instrumented by the imported contrib packages, by contrib package
 }
 
 // checkEndpoint tries to connect to the URL specified by endpoint.
@@ -106,6 +108,12 @@ func logStartup(t *tracer) {
 		AgentFeatures:               t.config.agent,
 		AppSec:                      appsec.Enabled(),
 	}
+	for _, in := range integrations.List() {
+		if info.Integrations == nil {
+			info.Integrations = make(map[string]string)
+		}
+		info.Integrations[in.Name] = in.Version
+	}
 	if _, err := samplingRulesFromEnv(); err != nil {
 		info.SamplingRulesError = fmt.Sprintf("%s", err)
 	}
@@ -113,7 +121,7 @@ func logStartup(t *tracer) {
 	if limit, ok := t.rulesSampling.limit(); ok {
 		info.SampleRateLimit = fmt.Sprintf("%v", limit)
 	}
-	if !t.config.logToStdout {
+	if !t.config.logToStdout && !t.config.consoleExport {
 		if err := checkEndpoint(t.config.transport.endpoint()); err != nil {
 			info.AgentError = fmt.Sprintf("%s", err)
 			log.Warn("DIAGNOSTICS Unable to reach agent intake: %s", err)
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// maxLeakStackDepth limits the number of frames recorded when a span is started with
+// leak detection enabled.
+const maxLeakStackDepth = 32
+
+// openSpans keeps track of the spans which were started and haven't finished yet, in
+// order to report long running spans and spans which were never finished.
+type openSpans struct {
+	mu    sync.Mutex
+	spans map[*span]*openSpan
+}
+
+// openSpan holds information about an unfinished span.
+type openSpan struct {
+	stack  []uintptr // program counters of the stack which started the span, if recorded
+	leaked bool      // reports whether the span was already reported as leaked
+}
+
+func newOpenSpans() *openSpans {
+	return &openSpans{spans: make(map[*span]*openSpan)}
+}
+
+// add registers s as open. The stack of the caller is recorded when withStack is true,
+// skipping the given number of frames.
+func (o *openSpans) add(s *span, withStack bool, skip int) {
+	var info openSpan
+	if withStack {
+		pcs := make([]uintptr, maxLeakStackDepth)
+		info.stack = pcs[:runtime.Callers(skip+2, pcs)]
+	}
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.spans[s] = &info
+}
+
+// remove unregisters s once it has finished.
+func (o *openSpans) remove(s *span) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	delete(o.spans, s)
+}
+
+// leakedSpan describes a span which wasn't finished in time.
+type leakedSpan struct {
+	name            string
+	traceID, spanID uint64
+	age             time.Duration
+	stack           []uintptr
+}
+
+// inspect returns the number of spans open for longer than threshold along with the
+// age of the oldest span. It also returns the spans open for longer than leakTimeout
+// which weren't returned by previous calls. Zero durations disable the corresponding
+// checks.
+func (o *openSpans) inspect(now time.Time, threshold, leakTimeout time.Duration) (longRunning int, oldest time.Duration, leaked []leakedSpan) {
+	var leakedSpans []*span
+	o.mu.Lock()
+	for s, info := range o.spans {
+		// the start time of a span doesn't change once it is started
+		age := now.Sub(time.Unix(0, s.Start))
+		if age > oldest {
+			oldest = age
+		}
+		if threshold > 0 && age >= threshold {
+			longRunning++
+		}
+		if leakTimeout > 0 && age >= leakTimeout && !info.leaked {
+			info.leaked = true
+			leakedSpans = append(leakedSpans, s)
+			leaked = append(leaked, leakedSpan{age: age, stack: info.stack})
+		}
+	}
+	o.mu.Unlock()
+	// finishing spans removes them while holding their lock, so spans must not
+	// be locked while holding o.mu
+	for i, s := range leakedSpans {
+		s.RLock()
+		leaked[i].name, leaked[i].traceID, leaked[i].spanID = s.Name, s.TraceID, s.SpanID
+		s.RUnlock()
+	}
+	return longRunning, oldest, leaked
+}
+
+// reportOpenSpans sends the long running span metrics and logs the spans which were never
+// finished, depending on the configuration.
+func (t *tracer) reportOpenSpans(now time.Time) {
+	longRunning, oldest, leaked := t.openSpans.inspect(now, t.config.longRunningThreshold, t.config.spanLeakTimeout)
+	if t.config.longRunningThreshold > 0 {
+		t.config.statsd.Gauge("datadog.tracer.spans_long_running", float64(longRunning), nil, 1)
+		t.config.statsd.Gauge("datadog.tracer.spans_open.max_age", oldest.Seconds(), nil, 1)
+	}
+	for _, s := range leaked {
+		log.Warn("Span %q (trace_id: %d, span_id: %d) has not been finished after %s, it was started at:\n%s",
+			s.name, s.traceID, s.spanID, s.age.Round(time.Second), formatStack(s.stack))
+	}
+}
+
+// formatStack returns the functions and locations of the given program counters, one
+// frame per line.
+func formatStack(pcs []uintptr) string {
+	if len(pcs) == 0 {
+		return "\t(stack not recorded)"
+	}
+	var sb strings.Builder
+	frames := runtime.CallersFrames(pcs)
+	for {
+		f, more := frames.Next()
+		fmt.Fprintf(&sb, "\t%s\n\t\t%s:%d\n", f.Function, f.File, f.Line)
+		if !more {
+			break
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
@@ -0,0 +1,122 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+func TestOpenSpans(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		tracer, _, _, stop := startTestTracer(t)
+		defer stop()
+		assert.Nil(t, tracer.openSpans)
+	})
+
+	t.Run("inspect", func(t *testing.T) {
+		assert := assert.New(t)
+		tracer, _, _, stop := startTestTracer(t,
+			WithLongRunningSpanMetrics(time.Minute),
+			WithSpanLeakDetection(time.Hour),
+		)
+		defer stop()
+
+		start := time.Now()
+		old := tracer.StartSpan("job.old", StartTime(start.Add(-2*time.Hour))).(*span)
+		long := tracer.StartSpan("job.long", StartTime(start.Add(-2*time.Minute)))
+		tracer.StartSpan("job.short").Finish()
+
+		n, oldest, leaked := tracer.openSpans.inspect(start, time.Minute, time.Hour)
+		assert.Equal(2, n)
+		assert.Equal(2*time.Hour, oldest)
+		require.Len(t, leaked, 1)
+		assert.Equal("job.old", leaked[0].name)
+		assert.Equal(old.SpanID, leaked[0].spanID)
+		assert.Contains(formatStack(leaked[0].stack), "TestOpenSpans")
+
+		// leaked spans are only reported once
+		_, _, leaked = tracer.openSpans.inspect(start, time.Minute, time.Hour)
+		assert.Empty(leaked)
+
+		old.Finish()
+		long.Finish()
+		n, oldest, _ = tracer.openSpans.inspect(start, time.Minute, time.Hour)
+		assert.Equal(0, n)
+		assert.Zero(oldest)
+	})
+
+	t.Run("no-stack", func(t *testing.T) {
+		tracer, _, _, stop := startTestTracer(t, WithLongRunningSpanMetrics(time.Minute))
+		defer stop()
+
+		s := tracer.StartSpan("job")
+		defer s.Finish()
+		for _, info := range tracer.openSpans.spans {
+			assert.Nil(t, info.stack)
+		}
+	})
+}
+
+func TestReportOpenSpans(t *testing.T) {
+	tl := new(log.RecordLogger)
+	defer log.UseLogger(tl)()
+	var tg testStatsdClient
+	tracer, _, _, stop := startTestTracer(t,
+		withStatsdClient(&tg),
+		WithLongRunningSpanMetrics(time.Minute),
+		WithSpanLeakDetection(time.Hour),
+	)
+	defer stop()
+
+	s := tracer.StartSpan("job", StartTime(time.Now().Add(-2*time.Hour)))
+	defer s.Finish()
+	tg.Reset()
+	tracer.reportOpenSpans(time.Now())
+
+	gauges := map[string]float64{}
+	for _, c := range tg.GaugeCalls() {
+		gauges[c.name] = c.floatVal
+	}
+	assert.Equal(t, float64(1), gauges["datadog.tracer.spans_long_running"])
+	assert.InDelta(t, (2 * time.Hour).Seconds(), gauges["datadog.tracer.spans_open.max_age"], 10)
+	var warnings []string
+	for _, l := range tl.Logs() {
+		// debug logs may be enabled by other tests
+		if strings.Contains(l, "WARN:") {
+			warnings = append(warnings, l)
+		}
+	}
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], `Span "job"`)
+	assert.Contains(t, warnings[0], "has not been finished after 2h0m0s")
+	assert.Contains(t, warnings[0], "TestReportOpenSpans")
+}
+
+func TestReportOpenSpansOnStop(t *testing.T) {
+	tl := new(log.RecordLogger)
+	defer log.UseLogger(tl)()
+	tracer, _, _, stop := startTestTracer(t, WithSpanLeakDetection(time.Minute))
+
+	tracer.StartSpan("abandoned", StartTime(time.Now().Add(-time.Hour)))
+	tracer.StartSpan("recent")
+	stop()
+
+	var warnings []string
+	for _, l := range tl.Logs() {
+		if strings.Contains(l, "WARN:") {
+			warnings = append(warnings, l)
+		}
+	}
+	require.Len(t, warnings, 1)
+	assert.Contains(t, warnings[0], `Span "abandoned"`)
+}
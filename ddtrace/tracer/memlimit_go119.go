@@ -0,0 +1,23 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+//go:build go1.19
+// +build go1.19
+
+package tracer
+
+import (
+	"math"
+	"runtime/debug"
+)
+
+// runtimeMemoryLimit returns the soft memory limit of the Go runtime, set with GOMEMLIMIT
+// or debug.SetMemoryLimit, or 0 when there is none.
+func runtimeMemoryLimit() int64 {
+	if limit := debug.SetMemoryLimit(-1); limit != math.MaxInt64 {
+		return limit
+	}
+	return 0
+}
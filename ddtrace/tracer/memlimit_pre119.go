@@ -0,0 +1,25 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+//go:build !go1.19
+// +build !go1.19
+
+package tracer
+
+import (
+	"os"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// runtimeMemoryLimit returns the memory limit set with GOMEMLIMIT, which is honoured by
+// the Go runtime starting with Go 1.19, or 0 when there is none.
+func runtimeMemoryLimit() int64 {
+	limit, err := parseMemoryLimit(os.Getenv("GOMEMLIMIT"))
+	if err != nil {
+		log.Warn("Ignoring GOMEMLIMIT: %v", err)
+	}
+	return limit
+}
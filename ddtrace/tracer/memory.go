@@ -0,0 +1,154 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+const (
+	// defaultMemoryPressureThreshold specifies the default percentage of the memory limit
+	// above which the process is considered under memory pressure.
+	defaultMemoryPressureThreshold = 90
+
+	// memoryPressureHysteresis specifies by how many percents of the memory limit the
+	// memory in use must fall below the threshold for the pressure to be relieved, which
+	// prevents the tracer from flapping around the threshold.
+	memoryPressureHysteresis = 5
+
+	// memoryCheckInterval specifies the interval at which the memory in use is checked.
+	memoryCheckInterval = time.Second
+
+	// memoryPressureSampleRate is the rate applied on top of the agent sampling rates
+	// while under memory pressure.
+	memoryPressureSampleRate = 0.1
+
+	// memoryPressurePayloadDivisor divides the size above which payloads are flushed
+	// while under memory pressure.
+	memoryPressurePayloadDivisor = 4
+)
+
+// MemoryPressureHook is called by the tracer when the process enters memory pressure,
+// with underPressure set to true, and when it leaves it. inUse and limit are the memory
+// obtained from the OS by the Go runtime and the memory limit, in bytes.
+type MemoryPressureHook func(underPressure bool, inUse, limit int64)
+
+// memoryGuard tracks whether the process is nearing its memory limit. While it is, the
+// tracer sheds load: payloads are flushed sooner, payloads which couldn't be sent aren't
+// retained, errors don't record their stack trace and fewer traces are sampled.
+// A nil *memoryGuard is never under pressure.
+type memoryGuard struct {
+	limit     int64              // memory limit in bytes
+	threshold int                // percentage of limit above which the pressure starts
+	hook      MemoryPressureHook // notified of the changes of pressure; may be nil
+
+	pressure int32 // 1 while under memory pressure; accessed atomically
+}
+
+// newMemoryGuard returns a memoryGuard for the given limit and threshold, or nil when
+// either is not positive.
+func newMemoryGuard(limit int64, threshold int, hook MemoryPressureHook) *memoryGuard {
+	if limit <= 0 || threshold <= 0 {
+		return nil
+	}
+	return &memoryGuard{limit: limit, threshold: threshold, hook: hook}
+}
+
+// underPressure reports whether the process is nearing its memory limit. It is called
+// on hot paths and must stay cheap.
+func (g *memoryGuard) underPressure() bool {
+	return g != nil && atomic.LoadInt32(&g.pressure) == 1
+}
+
+// update records inUse bytes of memory in use and reports whether the pressure changed
+// because of it.
+func (g *memoryGuard) update(inUse int64) (changed bool) {
+	percent := float64(inUse) * 100 / float64(g.limit)
+	switch {
+	case !g.underPressure() && percent >= float64(g.threshold):
+		atomic.StoreInt32(&g.pressure, 1)
+	case g.underPressure() && percent < float64(g.threshold-memoryPressureHysteresis):
+		atomic.StoreInt32(&g.pressure, 0)
+	default:
+		return false
+	}
+	return true
+}
+
+// monitorMemory periodically checks the memory in use until the tracer stops.
+func (t *tracer) monitorMemory(interval time.Duration) {
+	var ms runtime.MemStats
+	tick := time.NewTicker(interval)
+	defer tick.Stop()
+	for {
+		select {
+		case <-tick.C:
+			runtime.ReadMemStats(&ms)
+			// the memory accounted for by the runtime against its memory limit
+			t.checkMemory(int64(ms.Sys - ms.HeapReleased))
+		case <-t.stop:
+			return
+		}
+	}
+}
+
+// checkMemory updates the memory pressure with inUse bytes of memory in use, and reports
+// its changes.
+func (t *tracer) checkMemory(inUse int64) {
+	g := t.config.memory
+	if !g.update(inUse) {
+		return
+	}
+	pressure := g.underPressure()
+	if pressure {
+		log.Warn("Memory in use (%d bytes) is above %d%% of the memory limit (%d bytes): shedding tracing load.", inUse, g.threshold, g.limit)
+		t.config.statsd.Incr("datadog.tracer.memory_pressure", []string{"state:entered"}, 1)
+	} else {
+		log.Info("Memory in use (%d bytes) is back below the memory limit (%d bytes) threshold: tracing resumed.", inUse, g.limit)
+		t.config.statsd.Incr("datadog.tracer.memory_pressure", []string{"state:exited"}, 1)
+	}
+	if g.hook != nil {
+		g.hook(pressure, inUse, g.limit)
+	}
+}
+
+// parseMemoryLimit parses a memory limit in the format of GOMEMLIMIT: a number of bytes
+// with an optional unit suffix among B, KiB, MiB, GiB and TiB, or "off". It returns 0
+// when there is no limit.
+func parseMemoryLimit(v string) (int64, error) {
+	v = strings.TrimSpace(v)
+	if v == "" || v == "off" {
+		return 0, nil
+	}
+	mult := int64(1)
+	for _, u := range []struct {
+		suffix string
+		mult   int64
+	}{
+		{"KiB", 1 << 10},
+		{"MiB", 1 << 20},
+		{"GiB", 1 << 30},
+		{"TiB", 1 << 40},
+		{"B", 1},
+	} {
+		if strings.HasSuffix(v, u.suffix) {
+			v, mult = strings.TrimSuffix(v, u.suffix), u.mult
+			break
+		}
+	}
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid memory limit %q", v)
+	}
+	return n * mult, nil
+}
@@ -0,0 +1,133 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMemoryLimit(t *testing.T) {
+	for in, want := range map[string]int64{
+		"":        0,
+		"off":     0,
+		"1024":    1024,
+		"512B":    512,
+		"64KiB":   64 << 10,
+		" 512MiB": 512 << 20,
+		"2GiB":    2 << 30,
+		"1TiB":    1 << 40,
+	} {
+		limit, err := parseMemoryLimit(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, limit, in)
+	}
+	for _, in := range []string{"1GB", "-1", "lots", "1.5GiB"} {
+		_, err := parseMemoryLimit(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestMemoryGuard(t *testing.T) {
+	var g *memoryGuard
+	assert.False(t, g.underPressure())
+	assert.Nil(t, newMemoryGuard(0, 90, nil))
+	assert.Nil(t, newMemoryGuard(1000, 0, nil))
+
+	g = newMemoryGuard(1000, 90, nil)
+	for _, tt := range []struct {
+		inUse    int64
+		changed  bool
+		pressure bool
+	}{
+		{500, false, false},
+		{899, false, false},
+		{900, true, true},
+		{950, false, true},
+		{860, false, true}, // within the hysteresis
+		{849, true, false},
+		{880, false, false},
+	} {
+		assert.Equal(t, tt.changed, g.update(tt.inUse), tt.inUse)
+		assert.Equal(t, tt.pressure, g.underPressure(), tt.inUse)
+	}
+}
+
+func TestMemoryPressureConfig(t *testing.T) {
+	c := newConfig(WithMemoryPressure(1<<30, 80))
+	require.NotNil(t, c.memory)
+	assert.Equal(t, int64(1<<30), c.memory.limit)
+	assert.Equal(t, 80, c.memory.threshold)
+
+	c = newConfig(WithMemoryPressure(0, 80))
+	assert.Nil(t, c.memory)
+	c = newConfig(WithMemoryPressure(1<<30, 0))
+	assert.Nil(t, c.memory)
+}
+
+func TestMemoryPressureLoadShedding(t *testing.T) {
+	var tg testStatsdClient
+	type notification struct {
+		pressure     bool
+		inUse, limit int64
+	}
+	var hooked []notification
+	tracer := newUnstartedTracer(
+		withStatsdClient(&tg),
+		WithMemoryPressure(1000, 90),
+		WithMemoryPressureHook(func(pressure bool, inUse, limit int64) {
+			hooked = append(hooked, notification{pressure, inUse, limit})
+		}),
+	)
+	defer tracer.Stop()
+
+	start := func() *span {
+		s := tracer.StartSpan("operation").(*span)
+		s.SetTag(ext.Error, errors.New("failure"))
+		return s
+	}
+
+	s := start()
+	assert.Contains(t, s.Meta, ext.ErrorStack)
+	assert.EqualValues(t, 1, s.Metrics[keySamplingPriorityRate])
+
+	tracer.checkMemory(950)
+	assert.True(t, tracer.config.memory.underPressure())
+	s = start()
+	assert.NotContains(t, s.Meta, ext.ErrorStack)
+	assert.EqualValues(t, memoryPressureSampleRate, s.Metrics[keySamplingPriorityRate])
+
+	tracer.checkMemory(960)
+	tracer.checkMemory(500)
+	assert.False(t, tracer.config.memory.underPressure())
+	assert.Contains(t, start().Meta, ext.ErrorStack)
+
+	assert.Equal(t, []notification{{true, 950, 1000}, {false, 500, 1000}}, hooked)
+	var states []string
+	for _, c := range tg.IncrCalls() {
+		if c.name == "datadog.tracer.memory_pressure" {
+			states = append(states, c.tags...)
+		}
+	}
+	assert.Equal(t, []string{"state:entered", "state:exited"}, states)
+}
+
+func TestMemoryPressureWriter(t *testing.T) {
+	c := newConfig(WithMemoryPressure(1000, 90))
+	h := newAgentTraceWriter(c, newPrioritySampler())
+	c.sendBufferSize = 1 << 20
+	r := retainedPayload{items: []byte("traces"), count: 1}
+	assert.True(t, h.retain(r))
+
+	c.memory.update(950)
+	assert.False(t, h.retain(r))
+	assert.Equal(t, len(r.items), h.retainedSize)
+}
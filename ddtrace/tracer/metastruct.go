@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// maxMetaStructSize is the maximum size of the encoded structured tags of a span.
+const maxMetaStructSize = 256 << 10
+
+// metaStructValue wraps the values of structured tags.
+type metaStructValue struct {
+	value interface{}
+}
+
+// MetaStruct returns a tag value which keeps the structure of value, for it to be
+// sent to the agent as is instead of being converted to a string:
+//
+//	span.SetTag("_dd.appsec.rules", tracer.MetaStruct([]interface{}{
+//		map[string]interface{}{"id": "crs-942-100", "tags": []string{"sqli"}},
+//	}))
+//
+// The value may be made of nils, booleans, numbers, strings, byte slices, times,
+// slices and arrays, and maps of type map[string]interface{} or map[string]string.
+// Tags making the structured tags of a span larger than 256KiB once encoded are
+// dropped.
+func MetaStruct(value interface{}) interface{} {
+	return metaStructValue{value: value}
+}
+
+// setMetaStruct sets the structured tag key to the msgpack encoding of v.
+// This method is not safe for concurrent use.
+func (s *span) setMetaStruct(key string, v interface{}) {
+	b, err := msgp.AppendIntf(nil, v)
+	if err != nil {
+		log.Error("Failed to encode structured tag %q: %v", key, err)
+		return
+	}
+	size := len(b)
+	for k, v := range s.MetaStruct {
+		if k != key {
+			size += len(v)
+		}
+	}
+	if size > maxMetaStructSize {
+		log.Warn("Dropping structured tag %q of span %q: the structured tags of the span would exceed %d bytes.",
+			key, s.Name, maxMetaStructSize)
+		return
+	}
+	if s.MetaStruct == nil {
+		s.MetaStruct = make(map[string][]byte, 1)
+	}
+	s.MetaStruct[key] = b
+}
@@ -0,0 +1,60 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+)
+
+func TestMetaStruct(t *testing.T) {
+	t.Run("encoding", func(t *testing.T) {
+		s := newBasicSpan("web.request")
+		rules := []interface{}{
+			map[string]interface{}{"id": "crs-942-100", "tags": []string{"sqli"}},
+		}
+		s.SetTag("_dd.appsec.rules", MetaStruct(rules))
+		assert.NotContains(t, s.Meta, "_dd.appsec.rules")
+
+		v, _, err := msgp.ReadIntfBytes(s.MetaStruct["_dd.appsec.rules"])
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"id": "crs-942-100", "tags": []interface{}{"sqli"}},
+		}, v)
+
+		// the field is sent to the agent
+		var buf bytes.Buffer
+		require.NoError(t, msgp.Encode(&buf, s))
+		var decoded span
+		require.NoError(t, msgp.Decode(&buf, &decoded))
+		assert.Equal(t, s.MetaStruct, decoded.MetaStruct)
+		assert.Equal(t, s.Name, decoded.Name)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		s := newBasicSpan("web.request")
+		s.SetTag("key", MetaStruct(struct{ A int }{1}))
+		assert.Empty(t, s.MetaStruct)
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		s := newBasicSpan("web.request")
+		half := strings.Repeat("a", maxMetaStructSize/2)
+		s.SetTag("a", MetaStruct(half))
+		s.SetTag("b", MetaStruct(half))
+		assert.Contains(t, s.MetaStruct, "a")
+		assert.NotContains(t, s.MetaStruct, "b")
+
+		// replacing a tag only accounts for its new size
+		s.SetTag("a", MetaStruct(half[1:]))
+		assert.Len(t, s.MetaStruct["a"], msgp.StringPrefixSize+len(half)-1)
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/codebrick-corp/dd-trace-go/internal/dogstatsd"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 )
 
@@ -18,13 +19,9 @@ import (
 // be reported.
 const defaultMetricsReportInterval = 10 * time.Second
 
-type statsdClient interface {
-	Incr(name string, tags []string, rate float64) error
-	Count(name string, value int64, tags []string, rate float64) error
-	Gauge(name string, value float64, tags []string, rate float64) error
-	Timing(name string, value time.Duration, tags []string, rate float64) error
-	Close() error
-}
+// statsdClient is the DogStatsD client shared by runtime metrics, health metrics and
+// user metrics.
+type statsdClient = dogstatsd.Client
 
 // reportRuntimeMetrics periodically reports go runtime metrics at
 // the given interval.
@@ -102,6 +99,13 @@ func (t *tracer) reportHealthMetrics(interval time.Duration) {
 			t.config.statsd.Count("datadog.tracer.spans_started", atomic.SwapInt64(&t.spansStarted, 0), nil, 1)
 			t.config.statsd.Count("datadog.tracer.spans_finished", atomic.SwapInt64(&t.spansFinished, 0), nil, 1)
 			t.config.statsd.Count("datadog.tracer.traces_dropped", atomic.SwapInt64(&t.tracesDropped, 0), []string{"reason:trace_too_large"}, 1)
+			t.components.report(t)
+			if t.overhead != nil {
+				t.reportOverhead(time.Now())
+			}
+			if t.openSpans != nil {
+				t.reportOpenSpans(time.Unix(0, now()))
+			}
 		case <-t.stop:
 			return
 		}
@@ -22,6 +22,7 @@ const (
 	callTypeIncr
 	callTypeCount
 	callTypeTiming
+	callTypeDistribution
 )
 
 type testStatsdClient struct {
@@ -30,6 +31,7 @@ type testStatsdClient struct {
 	incrCalls   []testStatsdCall
 	countCalls  []testStatsdCall
 	timingCalls []testStatsdCall
+	distCalls   []testStatsdCall
 	counts      map[string]int64
 	tags        []string
 	waitCh      chan struct{}
@@ -98,6 +100,15 @@ func (tg *testStatsdClient) Timing(name string, value time.Duration, tags []stri
 	})
 }
 
+func (tg *testStatsdClient) Distribution(name string, value float64, tags []string, rate float64) error {
+	return tg.addMetric(callTypeDistribution, tags, testStatsdCall{
+		name:     name,
+		floatVal: value,
+		tags:     make([]string, len(tags)),
+		rate:     rate,
+	})
+}
+
 func (tg *testStatsdClient) addMetric(ct callType, tags []string, c testStatsdCall) error {
 	tg.mu.Lock()
 	defer tg.mu.Unlock()
@@ -111,6 +122,8 @@ func (tg *testStatsdClient) addMetric(ct callType, tags []string, c testStatsdCa
 		tg.countCalls = append(tg.countCalls, c)
 	case callTypeTiming:
 		tg.timingCalls = append(tg.timingCalls, c)
+	case callTypeDistribution:
+		tg.distCalls = append(tg.distCalls, c)
 	}
 	tg.tags = tags
 	if tg.n > 0 {
@@ -151,6 +164,14 @@ func (tg *testStatsdClient) CountCalls() []testStatsdCall {
 	return c
 }
 
+func (tg *testStatsdClient) DistributionCalls() []testStatsdCall {
+	tg.mu.RLock()
+	defer tg.mu.RUnlock()
+	c := make([]testStatsdCall, len(tg.distCalls))
+	copy(c, tg.distCalls)
+	return c
+}
+
 func (tg *testStatsdClient) CallNames() []string {
 	tg.mu.RLock()
 	defer tg.mu.RUnlock()
@@ -167,6 +188,9 @@ func (tg *testStatsdClient) CallNames() []string {
 	for _, c := range tg.timingCalls {
 		n = append(n, c.name)
 	}
+	for _, c := range tg.distCalls {
+		n = append(n, c.name)
+	}
 	return n
 }
 
@@ -186,6 +210,9 @@ func (tg *testStatsdClient) CallsByName() map[string]int {
 	for _, c := range tg.timingCalls {
 		counts[c.name]++
 	}
+	for _, c := range tg.distCalls {
+		counts[c.name]++
+	}
 	return counts
 }
 
@@ -214,6 +241,7 @@ func (tg *testStatsdClient) Reset() {
 	tg.incrCalls = tg.incrCalls[:0]
 	tg.countCalls = tg.countCalls[:0]
 	tg.timingCalls = tg.timingCalls[:0]
+	tg.distCalls = tg.distCalls[:0]
 	tg.counts = make(map[string]int64)
 	tg.tags = tg.tags[:0]
 	if tg.waitCh != nil {
@@ -22,12 +22,11 @@ import (
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/internal"
+	"github.com/codebrick-corp/dd-trace-go/internal/dogstatsd"
 	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 	"github.com/codebrick-corp/dd-trace-go/internal/traceprof"
 	"github.com/codebrick-corp/dd-trace-go/internal/version"
-
-	"github.com/DataDog/datadog-go/v5/statsd"
 )
 
 var (
@@ -41,6 +40,14 @@ var (
 
 	// defaultMaxTagsHeaderLen specifies the default maximum length of the X-Datadog-Tags header value.
 	defaultMaxTagsHeaderLen = 512
+
+	// defaultAbandonedSpanTimeout specifies the default duration after which open spans
+	// are logged when DD_TRACE_DEBUG_ABANDONED_SPANS is enabled.
+	defaultAbandonedSpanTimeout = 10 * time.Minute
+
+	// defaultSendRetries specifies the default number of times the sending of a trace
+	// payload is retried when the agent can't be reached.
+	defaultSendRetries = 2
 )
 
 // config holds the tracer configuration.
@@ -59,6 +66,10 @@ type config struct {
 	// output instead of using the agent. This is used in Lambda environments.
 	logToStdout bool
 
+	// consoleExport reports whether the finished traces are pretty-printed to the
+	// standard output instead of being sent to the agent, for local development.
+	consoleExport bool
+
 	// logStartup, when true, causes various startup info to be written
 	// when the tracer starts.
 	logStartup bool
@@ -70,6 +81,10 @@ type config struct {
 	// should match to set application version tag. False by default
 	universalVersion bool
 
+	// universalEnv reports whether the env tag is set on all spans rather than only on
+	// the spans of the application's service. True by default.
+	universalEnv bool
+
 	// version specifies the version of this application
 	version string
 
@@ -96,6 +111,7 @@ type config struct {
 	// propagator propagates span context cross-process
 	propagator Propagator
 
+
 	// httpClient specifies the HTTP client to be used by the agent's transport.
 	httpClient *http.Client
 
@@ -103,6 +119,9 @@ type config struct {
 	// and is added as a special tag to the root span of traces.
 	hostname string
 
+	// hostnameStrategy specifies how the hostname is looked up when it is not set explicitly.
+	hostnameStrategy HostnameStrategy
+
 	// logger specifies the logger to use when printing errors. If not specified, the "log" package
 	// will be used.
 	logger ddtrace.Logger
@@ -118,6 +137,10 @@ type config struct {
 	// statsd is used for tracking metrics associated with the runtime and the tracer.
 	statsd statsdClient
 
+	// userStatsd is used for the metrics reported by the user. It shares the connection
+	// of statsd, without its tags.
+	userStatsd statsdClient
+
 	// samplingRules contains user-defined rules determine the sampling rate to apply
 	// to spans.
 	samplingRules []SamplingRule
@@ -126,6 +149,9 @@ type config struct {
 	// It defaults to time.Ticker; replaced in tests.
 	tickChan <-chan time.Time
 
+	// idGenerator generates the IDs of spans and traces. The IDs are random if nil.
+	idGenerator IDGenerator
+
 	// noDebugStack disables the collection of debug stack traces globally. No traces reporting
 	// errors will record a stack trace when this option is set.
 	noDebugStack bool
@@ -136,6 +162,101 @@ type config struct {
 	// profilerEndpoints specifies whether profiler endpoint filtering is enabled.
 	profilerEndpoints bool
 
+	// goroutineSpans specifies whether started spans are registered as the active
+	// span of their goroutine.
+	goroutineSpans bool
+
+	// clockCorrection specifies whether the durations of spans are measured with the
+	// monotonic clock rather than the wall clock.
+	clockCorrection bool
+
+	// keepAnalyticsEvents specifies whether the traces of the spans sampled as analytics
+	// events are kept.
+	keepAnalyticsEvents bool
+
+	// payloadCompression specifies the encoding used to compress the trace payloads
+	// sent to the agent, "gzip" or "zstd", at the level payloadCompressionLevel. The
+	// payloads are not compressed when it is empty.
+	payloadCompression      string
+	payloadCompressionLevel int
+
+	// sendRetries specifies the number of times the sending of a payload is retried,
+	// with an exponential backoff, when the agent can't be reached.
+	sendRetries int
+
+	// sendBufferSize specifies the maximum size in bytes of the payloads which couldn't
+	// be sent, kept in memory to be sent again once the agent is reachable.
+	sendBufferSize int
+
+	// agentUnreachableThreshold specifies the duration during which payloads must fail
+	// to be sent for the agent to be considered unreachable, after which traces are
+	// dropped without being encoded and the agent is probed every agentProbeInterval.
+	// Zero disables this behaviour.
+	agentUnreachableThreshold time.Duration
+	agentProbeInterval        time.Duration
+
+	// traceExporters receive every finished trace along with the agent.
+	traceExporters []TraceExporter
+
+	// longRunningThreshold specifies the duration after which open spans are reported
+	// as long running. Zero disables reporting.
+	longRunningThreshold time.Duration
+
+	// spanLeakTimeout specifies the duration after which open spans are logged as
+	// leaked. Zero disables leak detection.
+	spanLeakTimeout time.Duration
+
+	// propagationAudit specifies whether the root spans started from a context holding
+	// propagation headers are logged as broken propagation chains.
+	propagationAudit bool
+
+	// crashTracking specifies whether the crash signals and the panics reported with
+	// ReportPanic are reported as error traces.
+	crashTracking bool
+
+	// flushOnSIGTERM specifies whether the tracer is stopped, flushing the buffered
+	// traces, when the process receives SIGTERM.
+	flushOnSIGTERM bool
+
+	// attrLimits holds the limits applied to the tags set on spans.
+	attrLimits attributeLimits
+
+	// traceProtocol specifies the version of the trace API used to send traces to the agent:
+	// "0.4" or "0.7". The v0.7 protocol is only used when the agent supports it.
+	traceProtocol string
+
+	// ignoreUpstreamPriority specifies whether the sampling priorities of extracted span
+	// contexts are discarded.
+	ignoreUpstreamPriority bool
+
+	// traceDebug specifies whether the debug flags of extracted span contexts are honoured.
+	traceDebug bool
+
+	// maxChildSpans limits the number of child spans started from a single span. Zero
+	// disables the limit.
+	maxChildSpans int
+
+	// requiredTags holds the keys of the tags which top level spans are expected to have
+	// when they finish.
+	requiredTags []string
+
+	// memoryLimit specifies the memory limit of the process in bytes, and memoryPressureThreshold
+	// the percentage of it above which the tracer sheds load. The limit defaults to the soft
+	// memory limit of the Go runtime. Zero disables load shedding.
+	memoryLimit             int64
+	memoryPressureThreshold int
+
+	// memoryPressureHook is notified when the process enters or leaves memory pressure.
+	memoryPressureHook MemoryPressureHook
+
+	// memory tracks the memory pressure. It is nil when load shedding is disabled.
+	memory *memoryGuard
+
+	// cpuOverheadBudget specifies the maximum overhead of the tracer, as a percentage of
+	// the CPU time available to the process, above which optional features are throttled.
+	// Zero disables the budget.
+	cpuOverheadBudget float64
+
 	// enabled reports whether tracing is enabled.
 	enabled bool
 }
@@ -187,16 +308,11 @@ func newConfig(opts ...StartOption) *config {
 	if internal.BoolEnv("DD_TRACE_ANALYTICS_ENABLED", false) {
 		globalconfig.SetAnalyticsRate(1.0)
 	}
-	if os.Getenv("DD_TRACE_REPORT_HOSTNAME") == "true" {
-		var err error
-		c.hostname, err = os.Hostname()
-		if err != nil {
-			log.Warn("unable to look up hostname: %v", err)
-		}
-	}
+	c.hostnameStrategy = resolveHostnameStrategy()
 	if v := os.Getenv("DD_TRACE_SOURCE_HOSTNAME"); v != "" {
 		c.hostname = v
 	}
+	applyOTelEnv(c)
 	if v := os.Getenv("DD_ENV"); v != "" {
 		c.env = v
 	}
@@ -212,6 +328,8 @@ func newConfig(opts ...StartOption) *config {
 	if ver := os.Getenv("DD_VERSION"); ver != "" {
 		c.version = ver
 	}
+	c.universalVersion = internal.BoolEnv("DD_TRACE_UNIVERSAL_VERSION", false)
+	c.universalEnv = internal.BoolEnv("DD_TRACE_UNIVERSAL_ENV", true)
 	if v := os.Getenv("DD_SERVICE_MAPPING"); v != "" {
 		forEachStringTag(v, func(key, val string) { WithServiceMapping(key, val)(c) })
 	}
@@ -223,17 +341,59 @@ func newConfig(opts ...StartOption) *config {
 		// See: https://docs.aws.amazon.com/lambda/latest/dg/configuration-envvars.html
 		c.logToStdout = true
 	}
+	if v := os.Getenv("DD_TRACE_EXPORT"); v != "" {
+		if strings.ToLower(v) == "stdout" {
+			c.consoleExport = true
+		} else {
+			log.Warn("ignoring DD_TRACE_EXPORT=%q: only \"stdout\" is supported", v)
+		}
+	}
 	c.logStartup = internal.BoolEnv("DD_TRACE_STARTUP_LOGS", true)
 	c.runtimeMetrics = internal.BoolEnv("DD_RUNTIME_METRICS_ENABLED", false)
 	c.debug = internal.BoolEnv("DD_TRACE_DEBUG", false)
 	c.enabled = internal.BoolEnv("DD_TRACE_ENABLED", true)
 	c.profilerEndpoints = internal.BoolEnv(traceprof.EndpointEnvVar, true)
 	c.profilerHotspots = internal.BoolEnv(traceprof.CodeHotspotsEnvVar, true)
+	c.goroutineSpans = internal.BoolEnv("DD_TRACE_GOROUTINE_LOCAL_SPANS_ENABLED", false)
+	c.clockCorrection = internal.BoolEnv("DD_TRACE_CLOCK_CORRECTION_ENABLED", true)
+	c.keepAnalyticsEvents = internal.BoolEnv("DD_TRACE_KEEP_ANALYTICS_EVENTS_ENABLED", false)
+	c.payloadCompression = os.Getenv("DD_TRACE_PAYLOAD_COMPRESSION")
+	c.payloadCompressionLevel = internal.IntEnv("DD_TRACE_PAYLOAD_COMPRESSION_LEVEL", 0)
+	c.sendRetries = internal.IntEnv("DD_TRACE_SEND_RETRIES", defaultSendRetries)
+	c.sendBufferSize = internal.IntEnv("DD_TRACE_SEND_BUFFER_SIZE", 0)
+	c.agentUnreachableThreshold = internal.DurationEnv("DD_TRACE_AGENT_UNREACHABLE_THRESHOLD", defaultAgentUnreachableThreshold)
+	c.agentProbeInterval = internal.DurationEnv("DD_TRACE_AGENT_PROBE_INTERVAL", defaultAgentProbeInterval)
+	if internal.BoolEnv("DD_TRACE_DEBUG_ABANDONED_SPANS", false) {
+		c.spanLeakTimeout = internal.DurationEnv("DD_TRACE_ABANDONED_SPAN_TIMEOUT", defaultAbandonedSpanTimeout)
+	}
+	c.propagationAudit = internal.BoolEnv("DD_TRACE_PROPAGATION_AUDIT_ENABLED", false)
+	c.crashTracking = internal.BoolEnv("DD_CRASHTRACKING_ENABLED", false)
+	c.flushOnSIGTERM = internal.BoolEnv("DD_TRACE_FLUSH_ON_SIGTERM", false)
+	c.attrLimits = newAttributeLimits()
+	c.requiredTags = requiredTagsFromEnv()
+	c.maxChildSpans = internal.IntEnv("DD_TRACE_MAX_CHILD_SPANS", 0)
+	c.ignoreUpstreamPriority = internal.BoolEnv("DD_TRACE_IGNORE_UPSTREAM_PRIORITY", false)
+	c.traceDebug = internal.BoolEnv("DD_TRACE_PROPAGATION_DEBUG_ENABLED", false)
+	c.memoryLimit = runtimeMemoryLimit()
+	c.memoryPressureThreshold = internal.IntEnv("DD_TRACE_MEMORY_PRESSURE_THRESHOLD", defaultMemoryPressureThreshold)
+	c.cpuOverheadBudget = cpuOverheadBudgetEnv()
+	c.traceProtocol = "0.7"
+	if v := os.Getenv("DD_TRACE_AGENT_PROTOCOL_VERSION"); v != "" {
+		c.traceProtocol = v
+	}
+	if v := os.Getenv("DD_TRACE_HEADER_TAGS"); v != "" {
+		WithHeaderTags(strings.Split(v, ","))(c)
+	}
 
 	for _, fn := range opts {
 		fn(c)
 	}
 	WithGlobalTag(ext.RuntimeID, globalconfig.RuntimeID())(c)
+	if c.hostnameStrategy == HostnameDisabled {
+		c.hostname = ""
+	} else if c.hostname == "" {
+		c.hostname = lookupHostname(c.hostnameStrategy)
+	}
 	if c.env == "" {
 		if v, ok := c.globalTags["env"]; ok {
 			if e, ok := v.(string); ok {
@@ -259,10 +419,20 @@ func newConfig(opts ...StartOption) *config {
 		}
 	}
 	if c.transport == nil {
-		c.transport = newHTTPTransport(c.agentAddr, c.httpClient)
+		t := newHTTPTransport(c.agentAddr, c.httpClient)
+		t.compressor = newCompressor(c.payloadCompression, c.payloadCompressionLevel)
+		c.transport = t
+	}
+	maxTagsHeaderLen := internal.IntEnv("DD_TRACE_TAGS_PROPAGATION_MAX_LENGTH", defaultMaxTagsHeaderLen)
+	if maxTagsHeaderLen == 0 {
+		// a zero length disables the propagation of the trace tags, as a zero
+		// PropagatorConfig.MaxTagsHeaderLen stands for the default length.
+		maxTagsHeaderLen = -1
 	}
 	pcfg := &PropagatorConfig{
-		MaxTagsHeaderLen: internal.IntEnv("DD_TRACE_TAGS_PROPAGATION_MAX_LENGTH", defaultMaxTagsHeaderLen),
+		MaxTagsHeaderLen:       maxTagsHeaderLen,
+		IgnoreUpstreamPriority: c.ignoreUpstreamPriority,
+		TraceDebug:             c.traceDebug,
 	}
 	if c.propagator != nil {
 		c.propagator = NewPropagator(pcfg, c.propagator)
@@ -283,7 +453,7 @@ func newConfig(opts ...StartOption) *config {
 			// no config defined address; use defaults
 			addr = defaultDogstatsdAddr()
 		}
-		if agentport := c.agent.StatsdPort; agentport > 0 {
+		if agentport := c.agent.StatsdPort; agentport > 0 && os.Getenv("DD_DOGSTATSD_URL") == "" {
 			// the agent reported a non-standard port
 			host, _, err := net.SplitHostPort(addr)
 			if err == nil {
@@ -297,14 +467,18 @@ func newConfig(opts ...StartOption) *config {
 			// not a valid TCP address, leave it as it is (could be a socket connection)
 		}
 		c.dogstatsdAddr = addr
-		client, err := statsd.New(addr, statsd.WithMaxMessagesPerPayload(40), statsd.WithTags(statsTags(c)))
+		client, err := dogstatsd.New(addr)
 		if err != nil {
 			log.Warn("Runtime and health metrics disabled: %v", err)
-			c.statsd = &statsd.NoOpClient{}
-		} else {
-			c.statsd = client
+			client = dogstatsd.NoOp()
 		}
+		c.statsd = dogstatsd.WithTags(client, statsTags(c))
+		c.userStatsd = client
 	}
+	if c.userStatsd == nil {
+		c.userStatsd = c.statsd
+	}
+	c.memory = newMemoryGuard(c.memoryLimit, c.memoryPressureThreshold, c.memoryPressureHook)
 	return c
 }
 
@@ -339,12 +513,19 @@ func udsClient(socketPath string) *http.Client {
 
 // defaultDogstatsdAddr returns the default connection address for Dogstatsd.
 func defaultDogstatsdAddr() string {
+	if v := os.Getenv("DD_DOGSTATSD_URL"); v != "" {
+		addr, err := dogstatsd.AddrFromURL(v)
+		if err == nil {
+			return addr
+		}
+		log.Warn("Ignoring DD_DOGSTATSD_URL: %v", err)
+	}
 	envHost, envPort := os.Getenv("DD_AGENT_HOST"), os.Getenv("DD_DOGSTATSD_PORT")
 	if _, err := os.Stat(defaultSocketDSD); err == nil && envHost == "" && envPort == "" {
 		// socket exists and user didn't specify otherwise via env vars
 		return "unix://" + defaultSocketDSD
 	}
-	host, port := defaultHostname, "8125"
+	host, port := defaultHostname, dogstatsd.DefaultPort
 	if envHost != "" {
 		host = envHost
 	}
@@ -366,6 +547,10 @@ type agentFeatures struct {
 	// the /v0.6/stats endpoint.
 	Stats bool
 
+	// TracesV07 reports whether the agent can receive traces on the /v0.7/traces
+	// endpoint.
+	TracesV07 bool
+
 	// StatsdPort specifies the Dogstatsd port as provided by the agent.
 	// If it's the default, it will be 0, which means 8125.
 	StatsdPort int
@@ -384,7 +569,7 @@ func (a *agentFeatures) HasFlag(feat string) bool {
 // the tracer's behaviour.
 func (c *config) loadAgentFeatures() {
 	c.agent = agentFeatures{}
-	if c.logToStdout {
+	if c.logToStdout || c.consoleExport {
 		// there is no agent; all features off
 		return
 	}
@@ -415,6 +600,8 @@ func (c *config) loadAgentFeatures() {
 		switch endpoint {
 		case "/v0.6/stats":
 			c.agent.Stats = true
+		case "/v0.7/traces":
+			c.agent.TracesV07 = true
 		}
 	}
 	c.agent.featureFlags = make(map[string]struct{}, len(info.FeatureFlags))
@@ -457,7 +644,7 @@ func statsTags(c *config) []string {
 // withNoopStats is used for testing to disable statsd client
 func withNoopStats() StartOption {
 	return func(c *config) {
-		c.statsd = &statsd.NoOpClient{}
+		c.statsd = dogstatsd.NoOp()
 	}
 }
 
@@ -517,6 +704,16 @@ func WithLambdaMode(enabled bool) StartOption {
 	}
 }
 
+// WithConsoleExport enables pretty-printing the finished traces as JSON trees to the
+// standard output instead of sending them to the agent, to inspect the instrumentation
+// of an application during local development, without running an agent. It can also
+// be enabled by setting the DD_TRACE_EXPORT env variable to "stdout".
+func WithConsoleExport(enabled bool) StartOption {
+	return func(c *config) {
+		c.consoleExport = enabled
+	}
+}
+
 // WithPropagator sets an alternative propagator to be used by the tracer.
 func WithPropagator(p Propagator) StartOption {
 	return func(c *config) {
@@ -539,7 +736,9 @@ func WithServiceName(name string) StartOption {
 	}
 }
 
-// WithService sets the default service name for the program.
+// WithService sets the default service name for the program. The default value is the
+// environment variable DD_SERVICE, or else OTEL_SERVICE_NAME or the service.name attribute
+// of OTEL_RESOURCE_ATTRIBUTES, if set.
 func WithService(name string) StartOption {
 	return func(c *config) {
 		c.serviceName = name
@@ -556,7 +755,8 @@ func WithAgentAddr(addr string) StartOption {
 }
 
 // WithEnv sets the environment to which all traces started by the tracer will be submitted.
-// The default value is the environment variable DD_ENV, if it is set.
+// The default value is the environment variable DD_ENV, or else the deployment.environment
+// attribute of OTEL_RESOURCE_ATTRIBUTES, if it is set.
 func WithEnv(env string) StartOption {
 	return func(c *config) {
 		c.env = env
@@ -616,6 +816,11 @@ func WithUDS(socketPath string) StartOption {
 
 // WithAnalytics allows specifying whether Trace Search & Analytics should be enabled
 // for integrations.
+//
+// Deprecated: App Analytics is replaced by ingestion controls and retention filters.
+// The traces of the spans marked as analytics events can be kept with WithKeepAnalyticsEvents
+// so that retention filters can retain them, but WithSamplingRules should be used to choose
+// the traces ingested.
 func WithAnalytics(on bool) StartOption {
 	return func(cfg *config) {
 		if on {
@@ -627,6 +832,9 @@ func WithAnalytics(on bool) StartOption {
 }
 
 // WithAnalyticsRate sets the global sampling rate for sampling APM events.
+//
+// Deprecated: App Analytics is replaced by ingestion controls and retention filters.
+// See WithAnalytics.
 func WithAnalyticsRate(rate float64) StartOption {
 	return func(_ *config) {
 		if rate >= 0.0 && rate <= 1.0 {
@@ -637,6 +845,68 @@ func WithAnalyticsRate(rate float64) StartOption {
 	}
 }
 
+// WithClientIPHook sets a function which is called with the client IP resolved by the server
+// integrations, such as net/http, for each request. The tags it returns are set on the request
+// span, which allows enriching it from a GeoIP database of your own, for example:
+//
+//	tracer.Start(tracer.WithClientIPHook(func(ip net.IP) map[string]string {
+//		record, err := db.City(ip)
+//		if err != nil {
+//			return nil
+//		}
+//		return map[string]string{"network.client.geoip.country.iso_code": record.Country.IsoCode}
+//	}))
+//
+// The function is called synchronously when the request span starts and must be safe for
+// concurrent use. It is not called when the client IP is unknown or not a public address.
+func WithClientIPHook(fn func(ip net.IP) map[string]string) StartOption {
+	return func(_ *config) {
+		globalconfig.SetClientIPHook(fn)
+	}
+}
+
+// WithHeaderTags enables recording the values of the given HTTP request and response
+// headers as tags of the spans of the HTTP server integrations. Each entry is either a
+// header name, whose values are recorded under the http.request.headers.<header> and
+// http.response.headers.<header> tags, the header being lowercased, or a "header:tag"
+// pair recording the values under the given tag. The headers replace the ones set
+// previously, including the ones of the comma-separated DD_TRACE_HEADER_TAGS env variable.
+// Headers which are never recorded, such as the Datadog propagation headers, are ignored.
+// Warning: recording headers can expose sensitive data such as authorization tokens.
+func WithHeaderTags(headerAsTags []string) StartOption {
+	return func(_ *config) {
+		globalconfig.ClearHeaderTags()
+		for _, h := range headerAsTags {
+			header, tag := h, ""
+			if i := strings.IndexByte(h, ':'); i >= 0 {
+				header, tag = h[:i], strings.TrimSpace(h[i+1:])
+			}
+			if header = strings.TrimSpace(header); header == "" {
+				continue
+			}
+			globalconfig.SetHeaderTag(header, tag)
+		}
+	}
+}
+
+// WithClientIPResolver sets a function which resolves the client IP of the requests received
+// by the server integrations, recorded in the http.client_ip tag, in place of the default
+// resolution looking up the IP headers such as X-Forwarded-For. It allows applications behind
+// several proxies or CDNs to apply their own trust rules, for example:
+//
+//	tracer.Start(tracer.WithClientIPResolver(func(r *http.Request) net.IP {
+//		return net.ParseIP(r.Header.Get("CF-Connecting-IP"))
+//	}))
+//
+// The function is called synchronously when the request span starts and must be safe for
+// concurrent use. No client IP is recorded when it returns nil. The hook set with
+// WithClientIPHook is called with the IP it returns.
+func WithClientIPResolver(fn func(r *http.Request) net.IP) StartOption {
+	return func(_ *config) {
+		globalconfig.SetClientIPResolver(fn)
+	}
+}
+
 // WithRuntimeMetrics enables automatic collection of runtime metrics every 10 seconds.
 func WithRuntimeMetrics() StartOption {
 	return func(cfg *config) {
@@ -647,6 +917,7 @@ func WithRuntimeMetrics() StartOption {
 // WithDogstatsdAddress specifies the address to connect to for sending metrics to the Datadog
 // Agent. It should be a "host:port" string, or the path to a unix domain socket.If not set, it
 // attempts to determine the address of the statsd service according to the following rules:
+//   0. Use DD_DOGSTATSD_URL if set, e.g. "udp://host:port" or "unix:///path/to/socket".
 //   1. Look for /var/run/datadog/dsd.socket and use it if present. IF NOT, continue to #2.
 //   2. The host is determined by DD_AGENT_HOST, and defaults to "localhost"
 //   3. The port is retrieved from the agent. If not present, it is determined by DD_DOGSTATSD_PORT, and defaults to 8125
@@ -685,6 +956,38 @@ func WithUniversalVersion(version string) StartOption {
 	}
 }
 
+// WithUniversalEnv specifies whether the env set with WithEnv is applied to all spans,
+// or only to the spans whose service name matches the service of the application, like
+// the version set with WithServiceVersion. It defaults to the value of the
+// DD_TRACE_UNIVERSAL_ENV env variable or true. Similarly, the version set with DD_VERSION
+// is applied to all spans when DD_TRACE_UNIVERSAL_VERSION is true. Integrations can
+// override both for their spans with WithUnifiedServiceTags.
+func WithUniversalEnv(enabled bool) StartOption {
+	return func(c *config) {
+		c.universalEnv = enabled
+	}
+}
+
+// IDGenerator generates the IDs of the spans and traces started by the tracer, e.g.
+// to make them sortable by time or to prefix them with the ID of a datacenter. The
+// generated IDs should keep enough randomness to remain unique across all the
+// services of a distributed trace. Implementations must be safe for concurrent use.
+type IDGenerator interface {
+	// TraceID returns the ID of a new trace.
+	TraceID() uint64
+	// SpanID returns the ID of a new span of the trace with the given ID.
+	SpanID(traceID uint64) uint64
+}
+
+// WithIDGenerator sets the generator of the IDs of the spans and traces started by the
+// tracer, which are random by default. Zero IDs returned by g are replaced by random
+// ones. IDs set with the WithSpanID span option take precedence.
+func WithIDGenerator(g IDGenerator) StartOption {
+	return func(c *config) {
+		c.idGenerator = g
+	}
+}
+
 // WithHostname allows specifying the hostname with which to mark outgoing traces.
 func WithHostname(name string) StartOption {
 	return func(c *config) {
@@ -692,6 +995,16 @@ func WithHostname(name string) StartOption {
 	}
 }
 
+// WithHostnameStrategy specifies how the hostname with which to mark outgoing traces
+// is looked up when it isn't set with WithHostname or DD_TRACE_SOURCE_HOSTNAME. It can
+// also be set with the DD_TRACE_HOSTNAME_STRATEGY environment variable, to one of "os",
+// "ec2", "gcp" or "none". With HostnameDisabled, no hostname is reported at all.
+func WithHostnameStrategy(s HostnameStrategy) StartOption {
+	return func(c *config) {
+		c.hostnameStrategy = s
+	}
+}
+
 // WithTraceEnabled allows specifying whether tracing will be enabled
 func WithTraceEnabled(enabled bool) StartOption {
 	return func(c *config) {
@@ -731,6 +1044,293 @@ func WithProfilerEndpoints(enabled bool) StartOption {
 	}
 }
 
+// WithGoroutineLocalSpans enables keeping track of the active span of each goroutine,
+// for code which can't pass a context.Context along with its spans. When enabled, every
+// started span becomes the active span of its goroutine until it finishes, at which point
+// the previously active span is restored. The active span is returned by ActiveSpan, and
+// SpanFromContext and StartSpanFromContext fall back to it when the context holds no span.
+// Starting a span and looking up the active span require parsing the goroutine ID from its
+// stack trace, and at most 10000 unfinished spans are kept track of, those of goroutines
+// which exited being dropped first at most once per second, so this should only be enabled
+// for codebases which can't be instrumented otherwise. The enabled value defaults to the value of the
+// DD_TRACE_GOROUTINE_LOCAL_SPANS_ENABLED env variable or false.
+func WithGoroutineLocalSpans(enabled bool) StartOption {
+	return func(c *config) {
+		c.goroutineSpans = enabled
+	}
+}
+
+// WithClockCorrection specifies whether the durations of the spans whose start and
+// finish times are not set explicitly are measured with the monotonic clock, which is
+// not affected by the jumps of the wall clock, e.g. when it is adjusted by NTP. The
+// spans whose wall clock duration differs from their monotonic duration by more than
+// 100ms are tagged with the difference in nanoseconds as the _dd.clock_skew metric. The
+// enabled value defaults to the value of the DD_TRACE_CLOCK_CORRECTION_ENABLED env
+// variable or true.
+func WithClockCorrection(enabled bool) StartOption {
+	return func(c *config) {
+		c.clockCorrection = enabled
+	}
+}
+
+// WithKeepAnalyticsEvents specifies whether the traces of the spans sampled as analytics
+// events, with the AnalyticsRate option or the ext.EventSampleRate tag, are kept, so that
+// retention filters can retain the events of the deprecated App Analytics. Traces dropped
+// explicitly or by the sampler are left alone. The enabled value defaults to the value of
+// the DD_TRACE_KEEP_ANALYTICS_EVENTS_ENABLED env variable or false.
+func WithKeepAnalyticsEvents(enabled bool) StartOption {
+	return func(c *config) {
+		c.keepAnalyticsEvents = enabled
+	}
+}
+
+// WithPayloadCompression compresses the trace payloads sent to the agent with the given
+// encoding, "gzip" or "zstd", at the given level, trading CPU time for network usage.
+// A level of zero selects the default level of the encoding. If the agent rejects the
+// first compressed payload, compression is disabled and the following payloads are sent
+// uncompressed. The encoding and the level default to the values of the
+// DD_TRACE_PAYLOAD_COMPRESSION and DD_TRACE_PAYLOAD_COMPRESSION_LEVEL env variables;
+// payloads are not compressed by default.
+func WithPayloadCompression(encoding string, level int) StartOption {
+	return func(c *config) {
+		c.payloadCompression = encoding
+		c.payloadCompressionLevel = level
+	}
+}
+
+// WithSendRetries sets the number of times the sending of a trace payload is retried,
+// with an exponential backoff, when the agent can't be reached. It defaults to the value
+// of the DD_TRACE_SEND_RETRIES env variable, or 2. Zero disables retries.
+func WithSendRetries(n int) StartOption {
+	return func(c *config) {
+		c.sendRetries = n
+	}
+}
+
+// WithSendBuffer keeps the trace payloads which couldn't be sent in memory, up to size
+// bytes in total, to send them again once the agent is reachable instead of dropping
+// them. It defaults to the value of the DD_TRACE_SEND_BUFFER_SIZE env variable; payloads
+// are dropped by default. The number of traces kept this way is reported as the
+// datadog.tracer.traces_retained metric.
+func WithSendBuffer(size int) StartOption {
+	return func(c *config) {
+		c.sendBufferSize = size
+	}
+}
+
+// WithAgentCircuitBreaker sets how the tracer behaves during agent outages. Once trace
+// payloads have failed to be sent for longer than threshold, finished traces are dropped
+// without being encoded, sparing the application the cost of tracing while the agent is
+// down; spans are still created and their context propagated. The agent is probed with
+// the traces finished after each probeInterval, and the traces are sent again as soon as
+// it is reachable. They default to the values of the DD_TRACE_AGENT_UNREACHABLE_THRESHOLD
+// and DD_TRACE_AGENT_PROBE_INTERVAL env variables, or 30 and 10 seconds. A threshold of
+// zero disables this behaviour.
+func WithAgentCircuitBreaker(threshold, probeInterval time.Duration) StartOption {
+	return func(c *config) {
+		c.agentUnreachableThreshold = threshold
+		c.agentProbeInterval = probeInterval
+	}
+}
+
+// WithSpanAttributeLimits limits the tags set on spans, like the span limits of OpenTelemetry:
+// spans hold at most count tags, the tags set beyond it being dropped and counted in the
+// _dd.span.dropped_attributes metric, and string values are truncated to valueLength bytes.
+// The internal tags, whose key starts with an underscore, the tags set by the tracer on every
+// span, the error tags and the tags setting the name, service, resource and type of spans are
+// not limited. A zero limit means no limit. They
+// default to the values of the DD_TRACE_SPAN_ATTRIBUTE_COUNT_LIMIT and
+// DD_TRACE_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT env variables, or else of the
+// OTEL_SPAN_ATTRIBUTE_COUNT_LIMIT and OTEL_SPAN_ATTRIBUTE_VALUE_LENGTH_LIMIT, or else of the
+// OTEL_ATTRIBUTE_COUNT_LIMIT and OTEL_ATTRIBUTE_VALUE_LENGTH_LIMIT env variables; spans are
+// not limited by default.
+func WithSpanAttributeLimits(count, valueLength int) StartOption {
+	return func(c *config) {
+		c.attrLimits = attributeLimits{count: count, valueLength: valueLength}
+	}
+}
+
+// WithMemoryPressure sets the memory limit of the process, in bytes, along with the
+// percentage of it above which the process is considered under memory pressure. Under
+// memory pressure, the tracer sheds load until the memory in use falls back below the
+// threshold: payloads are flushed sooner, payloads which couldn't be sent are dropped
+// rather than kept to be sent again, errors don't record their stack trace, and only a
+// tenth of the traces otherwise kept by the agent sampling rates are kept. The limit
+// defaults to the soft memory limit of the Go runtime, set with GOMEMLIMIT, and the
+// threshold to the value of the DD_TRACE_MEMORY_PRESSURE_THRESHOLD env variable, or 90.
+// Load is never shed when either is zero.
+func WithMemoryPressure(limit int64, threshold int) StartOption {
+	return func(c *config) {
+		c.memoryLimit = limit
+		c.memoryPressureThreshold = threshold
+	}
+}
+
+// WithMemoryPressureHook sets a function notified when the process enters or leaves
+// memory pressure, as configured with WithMemoryPressure, e.g. to alert about it or to
+// shed load elsewhere in the application. It is called from a goroutine of the tracer.
+func WithMemoryPressureHook(fn MemoryPressureHook) StartOption {
+	return func(c *config) {
+		c.memoryPressureHook = fn
+	}
+}
+
+// WithCPUOverheadBudget limits the overhead of the tracer to the given percentage of the
+// CPU time available to the process, i.e. the wall time multiplied by GOMAXPROCS. The
+// overhead accounts for the time spent encoding and flushing traces, and by the WAF of
+// AppSec. It is measured over the interval of the health metrics, and reported as the
+// datadog.tracer.cpu_overhead gauge. When the budget is exceeded, the optional features
+// are throttled until the overhead falls below half of the budget: errors don't record
+// their stack trace, spans don't record the stack used by span leak detection, and the
+// propagation audit and the required tags checks are skipped. It defaults to the value
+// of the DD_TRACE_CPU_OVERHEAD_BUDGET env variable; zero disables the budget.
+func WithCPUOverheadBudget(percent float64) StartOption {
+	return func(c *config) {
+		c.cpuOverheadBudget = percent
+	}
+}
+
+// WithTraceExporter registers e to receive every finished trace, regardless of the
+// sampling decision, alongside their submission to the agent, e.g. to retain all the
+// spans in an audit pipeline. The traces are passed to the exporters from a dedicated
+// goroutine; they are dropped when the exporters can't keep up with the application.
+// It can be used several times to register several exporters. See NewJSONExporter for
+// an exporter writing the spans as JSON lines.
+func WithTraceExporter(e TraceExporter) StartOption {
+	return func(c *config) {
+		c.traceExporters = append(c.traceExporters, e)
+	}
+}
+
+// WithLongRunningSpanMetrics enables reporting the number of spans which have been open
+// for longer than threshold, along with the age of the oldest open span, as the
+// datadog.tracer.spans_long_running and datadog.tracer.spans_open.max_age gauges. They
+// are sent along with the other health metrics of the tracer.
+func WithLongRunningSpanMetrics(threshold time.Duration) StartOption {
+	return func(c *config) {
+		c.longRunningThreshold = threshold
+	}
+}
+
+// WithSpanLeakDetection enables logging a warning for every span which hasn't been
+// finished timeout after it started, along with the stack trace of the code which
+// started it. Spans which are still open when the tracer stops are also checked. Recording
+// stack traces adds an overhead to every started span, so this is meant to be used when
+// investigating missing spans or memory growth caused by missing calls to Finish. It can
+// also be enabled by setting DD_TRACE_DEBUG_ABANDONED_SPANS to true, in which case the
+// timeout defaults to the value of DD_TRACE_ABANDONED_SPAN_TIMEOUT or 10 minutes.
+func WithSpanLeakDetection(timeout time.Duration) StartOption {
+	return func(c *config) {
+		c.spanLeakTimeout = timeout
+	}
+}
+
+// WithPropagationAudit enables logging a warning when StartSpanFromContext starts the root
+// span of a new trace from a context which holds no span but holds propagation headers
+// recorded with ContextWithHeaders, revealing a broken propagation chain: the headers
+// were available upstream but their span context was never extracted, or they could not
+// be extracted at all. The warnings are logged at most once a minute, along with the
+// number of occurrences left out. The enabled value defaults to the value of the
+// DD_TRACE_PROPAGATION_AUDIT_ENABLED env variable or false.
+func WithPropagationAudit(enabled bool) StartOption {
+	return func(c *config) {
+		c.propagationAudit = enabled
+	}
+}
+
+// WithAgentProtocolVersion specifies the version of the trace API used to send the traces
+// to the agent: "0.7", the default, sends the traces as chunks holding the sampling priority
+// and trace tags shared by their spans, which reduces the size of the payloads; it is only
+// used when the agent supports it, the traces being sent using "0.4" otherwise. The version
+// defaults to the value of the DD_TRACE_AGENT_PROTOCOL_VERSION env variable.
+func WithAgentProtocolVersion(v string) StartOption {
+	return func(c *config) {
+		c.traceProtocol = v
+	}
+}
+
+// WithUpstreamPriorityIgnored specifies whether the sampling priorities of the span contexts
+// extracted from incoming requests are ignored, in which case the tracer makes its own
+// sampling decisions, as if the traces started locally. It is meant for services receiving
+// requests from untrusted edges, such as API gateways shared by several organizations. The
+// value defaults to the value of the DD_TRACE_IGNORE_UPSTREAM_PRIORITY env variable, or false.
+func WithUpstreamPriorityIgnored(ignored bool) StartOption {
+	return func(c *config) {
+		c.ignoreUpstreamPriority = ignored
+	}
+}
+
+// WithTraceDebug specifies whether the traces of incoming requests marked as being debugged,
+// with the x-datadog-trace-debug header or the _dd.p.debug trace tag, are always kept. Any
+// client able to send requests to the service can mark them, bypassing the sampling rules
+// and rate limits, so it should only be enabled for services whose requests come from
+// trusted sources, such as internal services behind an edge which removes the header. The
+// value defaults to the value of the DD_TRACE_PROPAGATION_DEBUG_ENABLED env variable, or false.
+func WithTraceDebug(enabled bool) StartOption {
+	return func(c *config) {
+		c.traceDebug = enabled
+	}
+}
+
+// WithMaxChildSpans limits the number of child spans which can be started from a single
+// span to n, e.g. 5000, preventing pathological loops from generating huge traces. The
+// spans started from a span past its limit, along with their own descendants, are dropped
+// and counted in the _dd.span.dropped_children metric of the span. Zero disables the limit,
+// which defaults to the value of the DD_TRACE_MAX_CHILD_SPANS env variable, or 0.
+func WithMaxChildSpans(n int) StartOption {
+	return func(c *config) {
+		c.maxChildSpans = n
+	}
+}
+
+// WithRequiredTags registers the keys of tags which are required on every top level span,
+// i.e. the entry spans of each service, e.g. "tenant_id" or "region", in order to enforce
+// tagging standards across services. The tags are checked when the spans finish: each
+// missing tag is counted in the datadog.tracer.spans_missing_required_tags health metric,
+// tagged with the key of the tag, and a warning is logged at most once a minute. Spans
+// are sent regardless. The keys are added to the ones listed in the comma-separated
+// DD_TRACE_REQUIRED_TAGS env variable.
+func WithRequiredTags(keys ...string) StartOption {
+	return func(c *config) {
+		c.requiredTags = append(c.requiredTags, keys...)
+	}
+}
+
+// WithCrashTracking enables reporting the crashes of the process as error traces, which
+// are sent synchronously before the process dies:
+//
+//   - the panics going through ReportPanic, deferred at the top of the goroutines, are
+//     recorded as the error of their root span, along with their stack trace, before the
+//     panic resumes;
+//   - the SIGQUIT and SIGABRT signals are reported as a process.crash span holding the
+//     stacks of all the goroutines, then raised again with their default action, for the
+//     Go runtime to dump the stacks and exit with status 2.
+//
+// Only the panics going through ReportPanic are reported: the integrations don't report
+// the panics of the handlers they trace as crashes, even those recording them as errors
+// before resuming them. Crash tracking must not be enabled by applications handling SIGQUIT or
+// SIGABRT with signal.Notify, e.g. to dump their state without exiting, since their
+// handlers are dropped and the process dies upon the first of these signals.
+//
+// The enabled value defaults to the value of the DD_CRASHTRACKING_ENABLED env variable
+// or false.
+func WithCrashTracking(enabled bool) StartOption {
+	return func(c *config) {
+		c.crashTracking = enabled
+	}
+}
+
+// WithFlushOnSIGTERM enables stopping the tracer when the process receives SIGTERM, giving it
+// up to 5 seconds to send the buffered traces. The tracer then raises SIGTERM again: it terminates
+// the programs which don't handle it, and is delivered to the handlers of those which do with
+// signal.Notify, which thus receive it twice. SIGTERM is not raised again on Windows.
+// The enabled value defaults to the value of the DD_TRACE_FLUSH_ON_SIGTERM env variable or false.
+func WithFlushOnSIGTERM(enabled bool) StartOption {
+	return func(c *config) {
+		c.flushOnSIGTERM = enabled
+	}
+}
+
 // StartSpanOption is a configuration option for StartSpan. It is aliased in order
 // to help godoc group all the functions returning it together. It is considered
 // more correct to refer to it as the type as the origin, ddtrace.StartSpanOption.
@@ -795,6 +1395,26 @@ func withContext(ctx context.Context) StartSpanOption {
 	}
 }
 
+// WithSpanLinks links the created span to the given spans. Unlike ChildOf, links
+// do not make the span part of the linked traces; they record a causal relationship,
+// e.g. between a message consumer and the many producers of a batch.
+func WithSpanLinks(links []ddtrace.SpanLink) StartSpanOption {
+	return func(cfg *ddtrace.StartSpanConfig) {
+		cfg.SpanLinks = append(cfg.SpanLinks, links...)
+	}
+}
+
+// WithUnifiedServiceTags specifies whether the version and env of the application are
+// set on the created span, overriding the scope configured with WithUniversalVersion and
+// WithUniversalEnv. It can be passed to the integrations accepting span options to report
+// their spans with, or without, the version and env of the application regardless of
+// their service.
+func WithUnifiedServiceTags(enabled bool) StartSpanOption {
+	return func(cfg *ddtrace.StartSpanConfig) {
+		cfg.UnifiedServiceTags = &enabled
+	}
+}
+
 // StartTime sets a custom time as the start time for the created span. By
 // default a span is started using the creation time.
 func StartTime(t time.Time) StartSpanOption {
@@ -806,6 +1426,10 @@ func StartTime(t time.Time) StartSpanOption {
 // AnalyticsRate sets a custom analytics rate for a span. It decides the percentage
 // of events that will be picked up by the App Analytics product. It's represents a
 // float64 between 0 and 1 where 0.5 would represent 50% of events.
+//
+// Deprecated: App Analytics is replaced by ingestion controls and retention filters.
+// The traces of the sampled events can be kept with WithKeepAnalyticsEvents, as by setting
+// the ext.ManualKeep tag, which should be used instead along with sampling rules.
 func AnalyticsRate(rate float64) StartSpanOption {
 	if math.IsNaN(rate) {
 		return func(cfg *ddtrace.StartSpanConfig) {}
@@ -255,6 +255,53 @@ func TestTracerOptionsDefaults(t *testing.T) {
 		})
 	})
 
+	t.Run("client-ip-hook", func(t *testing.T) {
+		defer globalconfig.SetClientIPHook(nil)
+		assert.Nil(t, globalconfig.ClientIPHook())
+		newConfig(WithClientIPHook(func(ip net.IP) map[string]string {
+			return map[string]string{"network.client.geoip.country.iso_code": "FR"}
+		}))
+		hook := globalconfig.ClientIPHook()
+		require.NotNil(t, hook)
+		assert.Equal(t, "FR", hook(net.ParseIP("8.8.8.8"))["network.client.geoip.country.iso_code"])
+	})
+
+	t.Run("client-ip-resolver", func(t *testing.T) {
+		defer globalconfig.SetClientIPResolver(nil)
+		assert.Nil(t, globalconfig.ClientIPResolver())
+		newConfig(WithClientIPResolver(func(r *http.Request) net.IP {
+			return net.ParseIP(r.Header.Get("CF-Connecting-IP"))
+		}))
+		resolve := globalconfig.ClientIPResolver()
+		require.NotNil(t, resolve)
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("CF-Connecting-IP", "8.8.8.8")
+		assert.Equal(t, "8.8.8.8", resolve(r).String())
+	})
+
+	t.Run("header-tags", func(t *testing.T) {
+		headerTags := func() map[string]string {
+			m := make(map[string]string)
+			globalconfig.ForEachHeaderTag(func(header, tag string) { m[header] = tag })
+			return m
+		}
+		defer globalconfig.ClearHeaderTags()
+
+		t.Run("env", func(t *testing.T) {
+			os.Setenv("DD_TRACE_HEADER_TAGS", "x-request-id, X-Tenant : tenant.id,,:ignored")
+			defer os.Unsetenv("DD_TRACE_HEADER_TAGS")
+			newConfig()
+			assert.Equal(t, map[string]string{"X-Request-Id": "", "X-Tenant": "tenant.id"}, headerTags())
+		})
+
+		t.Run("option", func(t *testing.T) {
+			os.Setenv("DD_TRACE_HEADER_TAGS", "x-request-id")
+			defer os.Unsetenv("DD_TRACE_HEADER_TAGS")
+			newConfig(WithHeaderTags([]string{"content-type:mime"}))
+			assert.Equal(t, map[string]string{"Content-Type": "mime"}, headerTags())
+		})
+	})
+
 	t.Run("dogstatsd", func(t *testing.T) {
 		t.Run("default", func(t *testing.T) {
 			tracer := newTracer()
@@ -386,6 +433,90 @@ func TestTracerOptionsDefaults(t *testing.T) {
 		})
 	})
 
+	t.Run("abandoned-spans", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			c := newConfig()
+			assert.Zero(t, c.spanLeakTimeout)
+		})
+
+		t.Run("enabled", func(t *testing.T) {
+			os.Setenv("DD_TRACE_DEBUG_ABANDONED_SPANS", "true")
+			defer os.Unsetenv("DD_TRACE_DEBUG_ABANDONED_SPANS")
+			c := newConfig()
+			assert.Equal(t, 10*time.Minute, c.spanLeakTimeout)
+		})
+
+		t.Run("timeout", func(t *testing.T) {
+			os.Setenv("DD_TRACE_DEBUG_ABANDONED_SPANS", "true")
+			defer os.Unsetenv("DD_TRACE_DEBUG_ABANDONED_SPANS")
+			os.Setenv("DD_TRACE_ABANDONED_SPAN_TIMEOUT", "30s")
+			defer os.Unsetenv("DD_TRACE_ABANDONED_SPAN_TIMEOUT")
+			c := newConfig()
+			assert.Equal(t, 30*time.Second, c.spanLeakTimeout)
+		})
+
+		t.Run("timeout-only", func(t *testing.T) {
+			os.Setenv("DD_TRACE_ABANDONED_SPAN_TIMEOUT", "30s")
+			defer os.Unsetenv("DD_TRACE_ABANDONED_SPAN_TIMEOUT")
+			c := newConfig()
+			assert.Zero(t, c.spanLeakTimeout)
+		})
+	})
+
+	t.Run("propagation-audit", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			c := newConfig()
+			assert.False(t, c.propagationAudit)
+		})
+
+		t.Run("env", func(t *testing.T) {
+			os.Setenv("DD_TRACE_PROPAGATION_AUDIT_ENABLED", "true")
+			defer os.Unsetenv("DD_TRACE_PROPAGATION_AUDIT_ENABLED")
+			c := newConfig()
+			assert.True(t, c.propagationAudit)
+		})
+
+		t.Run("option", func(t *testing.T) {
+			os.Setenv("DD_TRACE_PROPAGATION_AUDIT_ENABLED", "true")
+			defer os.Unsetenv("DD_TRACE_PROPAGATION_AUDIT_ENABLED")
+			c := newConfig(WithPropagationAudit(false))
+			assert.False(t, c.propagationAudit)
+		})
+	})
+
+	t.Run("keep-analytics-events", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			c := newConfig()
+			assert.False(t, c.keepAnalyticsEvents)
+		})
+
+		t.Run("env", func(t *testing.T) {
+			os.Setenv("DD_TRACE_KEEP_ANALYTICS_EVENTS_ENABLED", "true")
+			defer os.Unsetenv("DD_TRACE_KEEP_ANALYTICS_EVENTS_ENABLED")
+			c := newConfig()
+			assert.True(t, c.keepAnalyticsEvents)
+		})
+
+		t.Run("option", func(t *testing.T) {
+			c := newConfig(WithKeepAnalyticsEvents(true))
+			assert.True(t, c.keepAnalyticsEvents)
+		})
+	})
+
+	t.Run("goroutine-local-spans", func(t *testing.T) {
+		t.Run("default", func(t *testing.T) {
+			c := newConfig()
+			assert.False(t, c.goroutineSpans)
+		})
+
+		t.Run("override", func(t *testing.T) {
+			os.Setenv("DD_TRACE_GOROUTINE_LOCAL_SPANS_ENABLED", "true")
+			defer os.Unsetenv("DD_TRACE_GOROUTINE_LOCAL_SPANS_ENABLED")
+			c := newConfig()
+			assert.True(t, c.goroutineSpans)
+		})
+	})
+
 	t.Run("profiler-hotspots", func(t *testing.T) {
 		t.Run("default", func(t *testing.T) {
 			c := newConfig()
@@ -465,6 +596,16 @@ func TestDefaultDogstatsdAddr(t *testing.T) {
 		assert.Equal(t, defaultDogstatsdAddr(), "localhost:8111")
 	})
 
+	t.Run("url", func(t *testing.T) {
+		defer os.Unsetenv("DD_DOGSTATSD_URL")
+		os.Setenv("DD_DOGSTATSD_URL", "udp://my-host:8111")
+		assert.Equal(t, "my-host:8111", defaultDogstatsdAddr())
+		os.Setenv("DD_DOGSTATSD_URL", "unix:///var/run/dsd.sock")
+		assert.Equal(t, "unix:///var/run/dsd.sock", defaultDogstatsdAddr())
+		os.Setenv("DD_DOGSTATSD_URL", "tcp://my-host:8111")
+		assert.Equal(t, "localhost:8125", defaultDogstatsdAddr())
+	})
+
 	t.Run("socket", func(t *testing.T) {
 		defer func(old string) { os.Setenv("DD_AGENT_HOST", old) }(os.Getenv("DD_AGENT_HOST"))
 		defer func(old string) { os.Setenv("DD_DOGSTATSD_PORT", old) }(os.Getenv("DD_DOGSTATSD_PORT"))
@@ -0,0 +1,74 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// OpenTelemetry resource attributes mapped to the unified service tags.
+const (
+	otelServiceName           = "service.name"
+	otelServiceVersion        = "service.version"
+	otelDeploymentEnvironment = "deployment.environment"
+	// otelDeploymentEnvironmentName replaces deployment.environment in the recent versions
+	// of the OpenTelemetry semantic conventions.
+	otelDeploymentEnvironmentName = "deployment.environment.name"
+)
+
+// applyOTelEnv configures c from the OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME env
+// variables, so that services configured for OpenTelemetry are configured alike. The service
+// name, environment and version are taken from the service.name, deployment.environment
+// and service.version attributes, the other attributes being added as global tags. It
+// must be called before the Datadog env variables are applied, which take precedence.
+func applyOTelEnv(c *config) {
+	if v := os.Getenv("OTEL_RESOURCE_ATTRIBUTES"); v != "" {
+		forEachOTelResourceAttribute(v, func(key, val string) {
+			switch key {
+			case otelServiceName:
+				c.serviceName = val
+				globalconfig.SetServiceName(val)
+			case otelServiceVersion:
+				c.version = val
+			case otelDeploymentEnvironment, otelDeploymentEnvironmentName:
+				c.env = val
+			default:
+				WithGlobalTag(key, val)(c)
+			}
+		})
+	}
+	if v := os.Getenv("OTEL_SERVICE_NAME"); v != "" {
+		c.serviceName = v
+		globalconfig.SetServiceName(v)
+	}
+}
+
+// forEachOTelResourceAttribute calls fn with each of the key-value pairs of str, a comma
+// separated list of key=value pairs whose values are percent encoded, as specified for the
+// OTEL_RESOURCE_ATTRIBUTES env variable. The invalid pairs are skipped.
+func forEachOTelResourceAttribute(str string, fn func(key, val string)) {
+	for _, attr := range strings.Split(str, ",") {
+		kv := strings.SplitN(attr, "=", 2)
+		key := strings.TrimSpace(kv[0])
+		if len(kv) != 2 || key == "" {
+			if strings.TrimSpace(attr) != "" {
+				log.Warn("ignoring invalid OTEL_RESOURCE_ATTRIBUTES entry %q", attr)
+			}
+			continue
+		}
+		val, err := url.PathUnescape(strings.TrimSpace(kv[1]))
+		if err != nil {
+			log.Warn("ignoring invalid OTEL_RESOURCE_ATTRIBUTES entry %q: %v", attr, err)
+			continue
+		}
+		fn(key, val)
+	}
+}
@@ -0,0 +1,76 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+func TestOTelEnv(t *testing.T) {
+	setenv := func(key, value string) func() {
+		old, ok := os.LookupEnv(key)
+		os.Setenv(key, value)
+		return func() {
+			if ok {
+				os.Setenv(key, old)
+			} else {
+				os.Unsetenv(key)
+			}
+		}
+	}
+	defer globalconfig.SetServiceName("")
+
+	t.Run("resource-attributes", func(t *testing.T) {
+		assert := assert.New(t)
+		defer setenv("OTEL_RESOURCE_ATTRIBUTES", "service.name=checkout,deployment.environment=prod,"+
+			"service.version=1.2.3,team=payments, k8s.pod.name = checkout-7d9%2Cx ,invalid,=novalue")()
+		c := newConfig()
+		assert.Equal("checkout", c.serviceName)
+		assert.Equal("checkout", globalconfig.ServiceName())
+		assert.Equal("prod", c.env)
+		assert.Equal("1.2.3", c.version)
+		assert.Equal("payments", c.globalTags["team"])
+		assert.Equal("checkout-7d9,x", c.globalTags["k8s.pod.name"])
+		assert.NotContains(c.globalTags, "invalid")
+		assert.NotContains(c.globalTags, "service.name")
+	})
+
+	t.Run("environment-name", func(t *testing.T) {
+		defer setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment.name=staging")()
+		assert.Equal(t, "staging", newConfig().env)
+	})
+
+	t.Run("service-name", func(t *testing.T) {
+		defer setenv("OTEL_RESOURCE_ATTRIBUTES", "service.name=checkout")()
+		defer setenv("OTEL_SERVICE_NAME", "cart")()
+		assert.Equal(t, "cart", newConfig().serviceName)
+	})
+
+	t.Run("dd-precedence", func(t *testing.T) {
+		assert := assert.New(t)
+		defer setenv("OTEL_RESOURCE_ATTRIBUTES", "deployment.environment=prod,service.version=1.2.3,team=payments")()
+		defer setenv("OTEL_SERVICE_NAME", "cart")()
+		defer setenv("DD_SERVICE", "orders")()
+		defer setenv("DD_ENV", "dev")()
+		defer setenv("DD_VERSION", "2.0.0")()
+		defer setenv("DD_TAGS", "team:core")()
+		c := newConfig()
+		assert.Equal("orders", c.serviceName)
+		assert.Equal("dev", c.env)
+		assert.Equal("2.0.0", c.version)
+		assert.Equal("core", c.globalTags["team"])
+	})
+
+	t.Run("option-precedence", func(t *testing.T) {
+		defer setenv("OTEL_SERVICE_NAME", "cart")()
+		assert.Equal(t, "orders", newConfig(WithService("orders")).serviceName)
+	})
+}
@@ -0,0 +1,144 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// keyWAFDuration holds the time, in microseconds, spent by the WAF of AppSec on the
+// request of the span, as set by the appsec package.
+const keyWAFDuration = "_dd.appsec.waf.duration_ext"
+
+// cpuBudget measures the time spent by the tracer encoding and flushing traces and by
+// the WAF of AppSec, and compares it to a budget. While the budget is exceeded, the
+// optional features of the tracer are throttled: errors don't record their stack trace,
+// spans don't record the stack used by leak detection, and neither the propagation
+// audit nor the required tags audit run.
+// A nil *cpuBudget is disabled and never throttled.
+type cpuBudget struct {
+	budget float64 // maximum overhead, as a percentage of the available CPU time
+
+	spent    int64 // time spent in nanoseconds during the current window; accessed atomically
+	throttle int32 // 1 while the optional features are throttled; accessed atomically
+
+	mu          sync.Mutex // guards below field
+	windowStart time.Time  // start of the current window
+}
+
+// newCPUBudget returns a cpuBudget limiting the overhead of the tracer to the given
+// percentage of the CPU time available to the process, or nil when it is not positive.
+func newCPUBudget(percent float64) *cpuBudget {
+	if percent <= 0 {
+		return nil
+	}
+	return &cpuBudget{budget: percent, windowStart: time.Now()}
+}
+
+// cpuOverheadBudgetEnv returns the budget set with DD_TRACE_CPU_OVERHEAD_BUDGET, or 0.
+func cpuOverheadBudgetEnv() float64 {
+	v := os.Getenv("DD_TRACE_CPU_OVERHEAD_BUDGET")
+	if v == "" {
+		return 0
+	}
+	percent, err := strconv.ParseFloat(v, 64)
+	if err != nil {
+		log.Warn("Non-float value for env var DD_TRACE_CPU_OVERHEAD_BUDGET, ignoring: %v", err)
+		return 0
+	}
+	return percent
+}
+
+// throttled reports whether the optional features must be skipped. It is called on hot
+// paths and must stay cheap.
+func (b *cpuBudget) throttled() bool {
+	return b != nil && atomic.LoadInt32(&b.throttle) == 1
+}
+
+// start returns the time at which a measured piece of work starts, or the zero time
+// when b is disabled.
+func (b *cpuBudget) start() time.Time {
+	if b == nil {
+		return time.Time{}
+	}
+	return time.Now()
+}
+
+// stop records the time spent since start, as returned by b.start.
+func (b *cpuBudget) stop(start time.Time) {
+	if b == nil || start.IsZero() {
+		return
+	}
+	b.add(time.Since(start))
+}
+
+// add records d as spent by the tracer.
+func (b *cpuBudget) add(d time.Duration) {
+	if b != nil && d > 0 {
+		atomic.AddInt64(&b.spent, int64(d))
+	}
+}
+
+// update closes the window ending at now, with procs CPUs available to the process, and
+// starts a new one. It returns the overhead during the window, as a percentage of the
+// available CPU time, and reports whether the throttling changed because of it. The
+// throttling stops once the overhead falls below half of the budget.
+func (b *cpuBudget) update(now time.Time, procs int) (overhead float64, changed bool) {
+	b.mu.Lock()
+	window := now.Sub(b.windowStart)
+	b.windowStart = now
+	b.mu.Unlock()
+	spent := atomic.SwapInt64(&b.spent, 0)
+	if window <= 0 || procs <= 0 {
+		return 0, false
+	}
+	overhead = float64(spent) * 100 / (float64(window) * float64(procs))
+	switch {
+	case !b.throttled() && overhead > b.budget:
+		atomic.StoreInt32(&b.throttle, 1)
+	case b.throttled() && overhead < b.budget/2:
+		atomic.StoreInt32(&b.throttle, 0)
+	default:
+		return overhead, false
+	}
+	return overhead, true
+}
+
+// addWAFDuration records the time spent by the WAF on the request of s, if any. The
+// caller must hold the lock of s.
+func (b *cpuBudget) addWAFDuration(s *span) {
+	if b == nil {
+		return
+	}
+	if us, ok := s.Metrics[keyWAFDuration]; ok {
+		b.add(time.Duration(us * float64(time.Microsecond)))
+	}
+}
+
+// reportOverhead sends the overhead of the tracer since the last report as the
+// datadog.tracer.cpu_overhead gauge, in percents, and reports the changes of throttling.
+func (t *tracer) reportOverhead(now time.Time) {
+	b := t.overhead
+	overhead, changed := b.update(now, runtime.GOMAXPROCS(0))
+	t.config.statsd.Gauge("datadog.tracer.cpu_overhead", overhead, nil, 1)
+	if !changed {
+		return
+	}
+	if b.throttled() {
+		log.Warn("Tracer overhead (%.2f%% of the CPU) exceeds its budget (%.2f%%): throttling optional features.", overhead, b.budget)
+		t.config.statsd.Incr("datadog.tracer.cpu_overhead.throttled", []string{"state:entered"}, 1)
+	} else {
+		log.Info("Tracer overhead (%.2f%% of the CPU) is back within its budget (%.2f%%): optional features resumed.", overhead, b.budget)
+		t.config.statsd.Incr("datadog.tracer.cpu_overhead.throttled", []string{"state:exited"}, 1)
+	}
+}
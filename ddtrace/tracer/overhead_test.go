@@ -0,0 +1,107 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCPUBudget(t *testing.T) {
+	var b *cpuBudget
+	assert.False(t, b.throttled())
+	assert.True(t, b.start().IsZero())
+	b.stop(time.Now())
+	assert.Nil(t, newCPUBudget(0))
+
+	b = newCPUBudget(1)
+	now := b.windowStart
+	for _, tt := range []struct {
+		spent     time.Duration
+		overhead  float64
+		changed   bool
+		throttled bool
+	}{
+		{100 * time.Millisecond, 0.5, false, false},
+		{300 * time.Millisecond, 1.5, true, true},
+		{150 * time.Millisecond, 0.75, false, true},
+		{50 * time.Millisecond, 0.25, true, false},
+	} {
+		// 10 seconds on 2 CPUs
+		now = now.Add(10 * time.Second)
+		b.add(tt.spent)
+		overhead, changed := b.update(now, 2)
+		assert.InDelta(t, tt.overhead, overhead, 1e-9, tt.spent)
+		assert.Equal(t, tt.changed, changed, tt.spent)
+		assert.Equal(t, tt.throttled, b.throttled(), tt.spent)
+	}
+}
+
+func TestCPUOverheadBudgetConfig(t *testing.T) {
+	const env = "DD_TRACE_CPU_OVERHEAD_BUDGET"
+	defer os.Unsetenv(env)
+
+	assert.Zero(t, newConfig().cpuOverheadBudget)
+	os.Setenv(env, "2.5")
+	assert.Equal(t, 2.5, newConfig().cpuOverheadBudget)
+	assert.Equal(t, 1.0, newConfig(WithCPUOverheadBudget(1)).cpuOverheadBudget)
+	os.Setenv(env, "lots")
+	assert.Zero(t, newConfig().cpuOverheadBudget)
+}
+
+func TestCPUOverheadThrottling(t *testing.T) {
+	var tg testStatsdClient
+	tracer, _, _, stop := startTestTracer(t, withStatsdClient(&tg), WithCPUOverheadBudget(1))
+	defer stop()
+	require.NotNil(t, tracer.overhead)
+
+	start := func() *span {
+		s := tracer.StartSpan("operation").(*span)
+		s.SetTag(ext.Error, errors.New("failure"))
+		return s
+	}
+	assert.Contains(t, start().Meta, ext.ErrorStack)
+
+	// the time spent by the WAF is accounted for, along with the time spent encoding
+	// the finished traces
+	atomic.StoreInt64(&tracer.overhead.spent, 0)
+	s := tracer.StartSpan("http.request")
+	s.SetTag(keyWAFDuration, 1500.0)
+	s.Finish()
+	assert.GreaterOrEqual(t, atomic.LoadInt64(&tracer.overhead.spent), int64(1500*time.Microsecond))
+
+	tracer.overhead.add(time.Hour)
+	tracer.reportOverhead(time.Now().Add(time.Second))
+	assert.True(t, tracer.overhead.throttled())
+	assert.NotContains(t, start().Meta, ext.ErrorStack)
+
+	tracer.reportOverhead(time.Now().Add(2 * time.Second))
+	assert.False(t, tracer.overhead.throttled())
+	assert.Contains(t, start().Meta, ext.ErrorStack)
+
+	var gauges int
+	for _, c := range tg.GaugeCalls() {
+		if c.name == "datadog.tracer.cpu_overhead" {
+			gauges++
+		}
+	}
+	assert.Equal(t, 2, gauges)
+	var states []string
+	for _, c := range tg.IncrCalls() {
+		if c.name == "datadog.tracer.cpu_overhead.throttled" {
+			states = append(states, c.tags...)
+		}
+	}
+	assert.Equal(t, []string{"state:entered", "state:exited"}, states)
+}
@@ -40,6 +40,16 @@ type payload struct {
 
 	// buf holds the sequence of msgpack-encoded items.
 	buf bytes.Buffer
+
+	// protocol specifies the version of the trace API the payload is encoded for.
+	protocol traceProtocol
+
+	// prefix holds the msgpack-encoded bytes preceding the array of items, which is
+	// wrapped in the tracer payload of the v0.7 protocol. It is empty with v0.4.
+	prefix []byte
+
+	// prefixOff specifies the current read position on the prefix.
+	prefixOff int
 }
 
 var _ io.Reader = (*payload)(nil)
@@ -55,7 +65,13 @@ func newPayload() *payload {
 
 // push pushes a new item into the stream.
 func (p *payload) push(t spanList) error {
-	if err := msgp.Encode(&p.buf, t); err != nil {
+	var err error
+	if p.protocol == traceProtocolV07 {
+		err = encodeChunk(&p.buf, t)
+	} else {
+		err = msgp.Encode(&p.buf, t)
+	}
+	if err != nil {
 		return err
 	}
 	atomic.AddUint64(&p.count, 1)
@@ -63,6 +79,14 @@ func (p *payload) push(t spanList) error {
 	return nil
 }
 
+// pop removes the last item pushed into the stream, encoded in its last n bytes. It must
+// not be called once the payload is being read.
+func (p *payload) pop(n int) {
+	p.buf.Truncate(p.buf.Len() - n)
+	atomic.AddUint64(&p.count, ^uint64(0))
+	p.updateHeader()
+}
+
 // itemCount returns the number of items available in the srteam.
 func (p *payload) itemCount() int {
 	return int(atomic.LoadUint64(&p.count))
@@ -71,7 +95,7 @@ func (p *payload) itemCount() int {
 // size returns the payload size in bytes. After the first read the value becomes
 // inaccurate by up to 8 bytes.
 func (p *payload) size() int {
-	return p.buf.Len() + len(p.header) - p.off
+	return p.buf.Len() + len(p.header) - p.off + len(p.prefix) - p.prefixOff
 }
 
 // reset should *not* be used. It is not implemented and is only here to serve
@@ -129,6 +153,12 @@ func (p *payload) Close() error {
 
 // Read implements io.Reader. It reads from the msgpack-encoded stream.
 func (p *payload) Read(b []byte) (n int, err error) {
+	if p.prefixOff < len(p.prefix) {
+		// reading prefix
+		n = copy(b, p.prefix[p.prefixOff:])
+		p.prefixOff += n
+		return n, nil
+	}
 	if p.off < len(p.header) {
 		// reading header
 		n = copy(b, p.header[p.off:])
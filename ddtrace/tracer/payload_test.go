@@ -56,6 +56,31 @@ func TestPayloadIntegrity(t *testing.T) {
 	}
 }
 
+// TestPayloadPop tests that the last item pushed into the payload can be removed from it,
+// across the changes of the header.
+func TestPayloadPop(t *testing.T) {
+	for _, n := range []int{1, 16, 1 << 16} {
+		t.Run(strconv.Itoa(n), func(t *testing.T) {
+			p := newPayload()
+			lists := make(spanLists, n-1)
+			for i := range lists {
+				lists[i] = newSpanList(i%5 + 1)
+				p.push(lists[i])
+			}
+			size := p.buf.Len()
+			p.push(newSpanList(3))
+			p.pop(p.buf.Len() - size)
+			assert.Equal(t, n-1, p.itemCount())
+
+			want := new(bytes.Buffer)
+			assert.NoError(t, msgp.Encode(want, lists))
+			got, err := ioutil.ReadAll(p)
+			assert.NoError(t, err)
+			assert.Equal(t, want.Bytes(), got)
+		})
+	}
+}
+
 // TestPayloadDecode ensures that whatever we push into the payload can
 // be decoded by the codec.
 func TestPayloadDecode(t *testing.T) {
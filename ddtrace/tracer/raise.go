@@ -0,0 +1,24 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+//go:build !windows
+// +build !windows
+
+package tracer
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// raise sends sig to the process, for it to be handled by the handlers registered for it,
+// or else by its default action.
+func raise(sig syscall.Signal) {
+	if err := syscall.Kill(os.Getpid(), sig); err != nil {
+		log.Error("failed to raise %s: %v", sig, err)
+	}
+}
@@ -0,0 +1,63 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"os"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// requiredTagsFromEnv returns the required tag keys listed in the comma-separated
+// DD_TRACE_REQUIRED_TAGS environment variable.
+func requiredTagsFromEnv() []string {
+	var keys []string
+	for _, k := range strings.Split(os.Getenv("DD_TRACE_REQUIRED_TAGS"), ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
+
+// missingRequiredTags returns the required tags which are not set on s. Only top level
+// spans, the entry spans of each service, are checked. The span must be locked.
+func missingRequiredTags(s *span, required []string) []string {
+	if len(required) == 0 || s.Metrics[keyTopLevel] != 1 {
+		return nil
+	}
+	var missing []string
+	for _, k := range required {
+		if _, ok := s.Meta[k]; ok {
+			continue
+		}
+		if _, ok := s.Metrics[k]; ok {
+			continue
+		}
+		missing = append(missing, k)
+	}
+	return missing
+}
+
+// checkRequiredTags reports the required tags missing from s, which is finishing. Every
+// missing tag is counted in the datadog.tracer.spans_missing_required_tags metric, and a
+// warning is logged at most once a minute. The span must be locked.
+func (t *tracer) checkRequiredTags(s *span) {
+	missing := missingRequiredTags(s, t.config.requiredTags)
+	if len(missing) == 0 {
+		return
+	}
+	for _, k := range missing {
+		t.config.statsd.Incr("datadog.tracer.spans_missing_required_tags", []string{"tag:" + k}, 1)
+	}
+	allow, suppressed := t.tagsAudit.allow()
+	if !allow {
+		return
+	}
+	log.Warn("Span %q of service %q finished without the required tags %s (%d similar occurrences left out).",
+		s.Name, s.Service, strings.Join(missing, ", "), suppressed)
+}
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// requiredTagsWarnings returns the warnings about required tags recorded by tl.
+func requiredTagsWarnings(tl *log.RecordLogger) []string {
+	var warnings []string
+	for _, l := range tl.Logs() {
+		if strings.Contains(l, "WARN:") && strings.Contains(l, "required tags") {
+			warnings = append(warnings, l)
+		}
+	}
+	return warnings
+}
+
+// missingTagsCalls returns the tags of the calls incrementing the missing required tags metric.
+func missingTagsCalls(tg *testStatsdClient) [][]string {
+	var tags [][]string
+	for _, c := range tg.IncrCalls() {
+		if c.name == "datadog.tracer.spans_missing_required_tags" {
+			tags = append(tags, c.tags)
+		}
+	}
+	return tags
+}
+
+func TestRequiredTags(t *testing.T) {
+	t.Run("missing", func(t *testing.T) {
+		tl := new(log.RecordLogger)
+		defer log.UseLogger(tl)()
+		var tg testStatsdClient
+		tracer, _, _, stop := startTestTracer(t, WithRequiredTags("tenant_id", "region"), withStatsdClient(&tg))
+		defer stop()
+
+		root := tracer.StartSpan("http.request", ServiceName("web"), Tag("region", "eu"))
+		child := tracer.StartSpan("db.query", ChildOf(root.Context()), ServiceName("web"))
+		child.Finish()
+		root.Finish()
+
+		assert.Equal(t, [][]string{{"tag:tenant_id"}}, missingTagsCalls(&tg))
+		warnings := requiredTagsWarnings(tl)
+		require.Len(t, warnings, 1)
+		assert.Contains(t, warnings[0], `Span "http.request" of service "web"`)
+		assert.Contains(t, warnings[0], "tenant_id")
+		assert.NotContains(t, warnings[0], "region")
+	})
+
+	t.Run("present", func(t *testing.T) {
+		tl := new(log.RecordLogger)
+		defer log.UseLogger(tl)()
+		var tg testStatsdClient
+		tracer, _, _, stop := startTestTracer(t, WithRequiredTags("tenant_id", "region"), withStatsdClient(&tg))
+		defer stop()
+
+		root := tracer.StartSpan("http.request", Tag("region", "eu"))
+		root.SetTag("tenant_id", 42)
+		root.Finish()
+
+		assert.Empty(t, missingTagsCalls(&tg))
+		assert.Empty(t, requiredTagsWarnings(tl))
+	})
+
+	t.Run("rate-limited", func(t *testing.T) {
+		tl := new(log.RecordLogger)
+		defer log.UseLogger(tl)()
+		var tg testStatsdClient
+		tracer, _, _, stop := startTestTracer(t, WithRequiredTags("tenant_id"), withStatsdClient(&tg))
+		defer stop()
+
+		for i := 0; i < 3; i++ {
+			tracer.StartSpan("http.request").Finish()
+		}
+		assert.Len(t, missingTagsCalls(&tg), 3)
+		assert.Len(t, requiredTagsWarnings(tl), 1)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		old, ok := os.LookupEnv("DD_TRACE_REQUIRED_TAGS")
+		os.Setenv("DD_TRACE_REQUIRED_TAGS", "tenant_id, region,,")
+		defer func() {
+			if ok {
+				os.Setenv("DD_TRACE_REQUIRED_TAGS", old)
+			} else {
+				os.Unsetenv("DD_TRACE_REQUIRED_TAGS")
+			}
+		}()
+		c := newConfig(WithRequiredTags("team"))
+		assert.Equal(t, []string{"tenant_id", "region", "team"}, c.requiredTags)
+	})
+}
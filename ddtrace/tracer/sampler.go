@@ -114,26 +114,57 @@ func newPrioritySampler() *prioritySampler {
 	}
 }
 
+// defaultRateKey is the key of the rate applied to the services unknown to the agent
+// in its rates by service.
+const defaultRateKey = "service:,env:"
+
 // readRatesJSON will try to read the rates as JSON from the given io.ReadCloser.
 func (ps *prioritySampler) readRatesJSON(rc io.ReadCloser) error {
+	defer rc.Close()
 	var payload struct {
 		Rates map[string]float64 `json:"rate_by_service"`
 	}
 	if err := json.NewDecoder(rc).Decode(&payload); err != nil {
 		return err
 	}
-	rc.Close()
-	const defaultRateKey = "service:,env:"
 	ps.mu.Lock()
 	defer ps.mu.Unlock()
-	ps.rates = payload.Rates
-	if v, ok := ps.rates[defaultRateKey]; ok {
+	if v, ok := payload.Rates[defaultRateKey]; ok {
 		ps.defaultRate = v
-		delete(ps.rates, defaultRateKey)
+		delete(payload.Rates, defaultRateKey)
+	}
+	if !ratesEqual(ps.rates, payload.Rates) {
+		log.Debug("Agent sampling rates updated: %v (default: %v)", payload.Rates, ps.defaultRate)
 	}
+	ps.rates = payload.Rates
 	return nil
 }
 
+// ratesByService returns a copy of the rates by service, including the default rate
+// under defaultRateKey.
+func (ps *prioritySampler) ratesByService() map[string]float64 {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+	rates := make(map[string]float64, len(ps.rates)+1)
+	for k, v := range ps.rates {
+		rates[k] = v
+	}
+	rates[defaultRateKey] = ps.defaultRate
+	return rates
+}
+
+func ratesEqual(a, b map[string]float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if w, ok := b[k]; !ok || v != w {
+			return false
+		}
+	}
+	return true
+}
+
 // getRate returns the sampling rate to be used for the given span. Callers must
 // guard the span.
 func (ps *prioritySampler) getRate(spn *span) float64 {
@@ -149,7 +180,12 @@ func (ps *prioritySampler) getRate(spn *span) float64 {
 // apply applies sampling priority to the given span. Caller must ensure it is safe
 // to modify the span.
 func (ps *prioritySampler) apply(spn *span) {
-	rate := ps.getRate(spn)
+	ps.applyRate(spn, ps.getRate(spn))
+}
+
+// applyRate applies the sampling priority given by rate to the given span. Caller must
+// ensure it is safe to modify the span.
+func (ps *prioritySampler) applyRate(spn *span, rate float64) {
 	if sampledByRate(spn.TraceID, rate) {
 		spn.setSamplingPriority(ext.PriorityAutoKeep, samplernames.AgentRate, rate)
 	} else {
@@ -7,6 +7,7 @@ package tracer
 
 import (
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
 	"os"
@@ -167,6 +168,57 @@ func TestPrioritySampler(t *testing.T) {
 		assert.EqualValues(ext.PriorityAutoReject, testSpan1.Metrics[keySamplingPriority])
 		assert.EqualValues(0.5, testSpan1.Metrics[keySamplingPriorityRate])
 	})
+
+	t.Run("rates-by-service", func(t *testing.T) {
+		ps := newPrioritySampler()
+		assert := assert.New(t)
+		assert.Equal(map[string]float64{"service:,env:": 1}, ps.ratesByService())
+
+		assert.NoError(ps.readRatesJSON(ioutil.NopCloser(strings.NewReader(
+			`{"rate_by_service":{"service:,env:":0.8,"service:web,env:prod":0.2}}`,
+		))))
+		rates := ps.ratesByService()
+		assert.Equal(map[string]float64{"service:,env:": 0.8, "service:web,env:prod": 0.2}, rates)
+		rates["service:web,env:prod"] = 1
+		assert.Equal(0.2, ps.getRate(mkSpan("web", "prod")), "returned rates are a copy")
+
+		// rates are replaced by the ones of the latest response, and the default rate
+		// is kept when the agent doesn't send it
+		assert.NoError(ps.readRatesJSON(ioutil.NopCloser(strings.NewReader(
+			`{"rate_by_service":{"service:api,env:prod":0.4}}`,
+		))))
+		assert.Equal(map[string]float64{"service:,env:": 0.8, "service:api,env:prod": 0.4}, ps.ratesByService())
+		assert.Equal(0.8, ps.getRate(mkSpan("web", "prod")))
+	})
+
+	t.Run("close", func(t *testing.T) {
+		ps := newPrioritySampler()
+		rc := &closeRecorder{Reader: strings.NewReader("OK")}
+		assert.Error(t, ps.readRatesJSON(rc))
+		assert.True(t, rc.closed)
+	})
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestSamplingRatesByService(t *testing.T) {
+	assert.Nil(t, SamplingRatesByService())
+
+	tracer, _, _, stop := startTestTracer(t)
+	defer stop()
+	assert.Equal(t, map[string]float64{"service:,env:": 1}, SamplingRatesByService())
+	assert.NoError(t, tracer.prioritySampling.readRatesJSON(ioutil.NopCloser(strings.NewReader(
+		`{"rate_by_service":{"service:,env:":0.5,"service:web,env:":0.1}}`,
+	))))
+	assert.Equal(t, map[string]float64{"service:,env:": 0.5, "service:web,env:": 0.1}, SamplingRatesByService())
 }
 
 func TestRateSampler(t *testing.T) {
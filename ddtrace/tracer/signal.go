@@ -0,0 +1,61 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// sigtermStopTimeout is how long the tracer is given to flush the buffered traces when
+// the process receives SIGTERM.
+var sigtermStopTimeout = 5 * time.Second
+
+// startFlushOnSIGTERM makes the tracer stop when the process receives SIGTERM.
+func (t *tracer) startFlushOnSIGTERM() {
+	sigs := make(chan os.Signal, 1)
+	// the signal is handled as soon as the tracer is started
+	signal.Notify(sigs, syscall.SIGTERM)
+	// not waited for when stopping the tracer since it stops the tracer itself
+	go t.flushOnSIGTERM(sigs)
+}
+
+// flushOnSIGTERM stops the tracer upon receiving a signal from sigs. It returns when the
+// tracer is stopped.
+func (t *tracer) flushOnSIGTERM(sigs chan os.Signal) {
+	select {
+	case sig := <-sigs:
+		signal.Stop(sigs)
+		t.stopOnSignal(sig)
+	case <-t.stop:
+		signal.Stop(sigs)
+	}
+}
+
+// stopOnSignal stops the tracer, giving it at most sigtermStopTimeout to flush the buffered
+// traces, then raises sig again now that the tracer doesn't handle it anymore: it terminates
+// the process unless the application handles it too, in which case it is delivered to its
+// handlers.
+func (t *tracer) stopOnSignal(sig os.Signal) {
+	log.Info("Received %s, stopping the tracer", sig)
+	ctx, cancel := context.WithTimeout(context.Background(), sigtermStopTimeout)
+	defer cancel()
+	if internal.GetGlobalTracer() == t {
+		// no spans are started anymore once the tracer is stopping
+		internal.SwapGlobalTracer(&internal.NoopTracer{})
+	}
+	t.stopContext(ctx)
+	log.Flush()
+	if sig, ok := sig.(syscall.Signal); ok {
+		raise(sig)
+	}
+}
@@ -0,0 +1,130 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"os/signal"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tinylib/msgp/msgp"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
+)
+
+func TestFlushOnSIGTERM(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGTERM can't be sent to the process on Windows")
+	}
+	// the handler of the application
+	sigs := make(chan os.Signal, 2)
+	signal.Notify(sigs, syscall.SIGTERM)
+	defer signal.Stop(sigs)
+
+	tracer, transport, _, stop := startTestTracer(t, WithFlushOnSIGTERM(true))
+	defer stop()
+	tracer.StartSpan("op").Finish()
+
+	p, err := os.FindProcess(os.Getpid())
+	assert.NoError(t, err)
+	assert.NoError(t, p.Signal(syscall.SIGTERM))
+
+	select {
+	case <-tracer.stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the tracer isn't stopped")
+	}
+	// the traces were flushed and the tracer stopped
+	assert.Len(t, transport.Traces(), 1)
+	assert.IsType(t, &internal.NoopTracer{}, internal.GetGlobalTracer())
+
+	// the application received the signal, then again once raised by the tracer
+	for i := 0; i < 2; i++ {
+		select {
+		case sig := <-sigs:
+			assert.Equal(t, syscall.SIGTERM, sig)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("SIGTERM wasn't delivered %d times to the application", i+1)
+		}
+	}
+}
+
+// sigtermAgentEnv holds the address of the agent to which the subprocess started by
+// TestFlushOnSIGTERMExit sends its traces.
+const sigtermAgentEnv = "DD_TEST_SIGTERM_AGENT"
+
+func TestFlushOnSIGTERMExit(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("SIGTERM can't be sent to the process on Windows")
+	}
+	if addr := os.Getenv(sigtermAgentEnv); addr != "" {
+		// the subprocess, which doesn't handle SIGTERM
+		Start(WithAgentAddr(addr), WithFlushOnSIGTERM(true), withNoopStats())
+		StartSpan("op").Finish()
+		p, err := os.FindProcess(os.Getpid())
+		if err == nil {
+			err = p.Signal(syscall.SIGTERM)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(10 * time.Second)
+		return
+	}
+
+	traces := make(chan spanLists, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v0.4/traces" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		var got spanLists
+		if err := msgp.Decode(r.Body, &got); err == nil && len(got) > 0 {
+			traces <- got
+		}
+	}))
+	defer srv.Close()
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFlushOnSIGTERMExit$")
+	cmd.Env = append(os.Environ(), sigtermAgentEnv+"="+strings.TrimPrefix(srv.URL, "http://"))
+	out, err := cmd.CombinedOutput()
+	// the process was terminated by SIGTERM
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr), "%v: %s", err, out)
+	status, ok := exitErr.Sys().(syscall.WaitStatus)
+	require.True(t, ok)
+	assert.True(t, status.Signaled(), "%v: %s", err, out)
+	assert.Equal(t, syscall.SIGTERM, status.Signal())
+
+	// after flushing the trace
+	select {
+	case got := <-traces:
+		require.Len(t, got, 1)
+		require.Len(t, got[0], 1)
+		assert.Equal(t, "op", got[0][0].Name)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the trace wasn't flushed")
+	}
+}
+
+func TestFlushOnSIGTERMConfig(t *testing.T) {
+	assert.False(t, newConfig().flushOnSIGTERM)
+	assert.True(t, newConfig(WithFlushOnSIGTERM(true)).flushOnSIGTERM)
+
+	os.Setenv("DD_TRACE_FLUSH_ON_SIGTERM", "true")
+	defer os.Unsetenv("DD_TRACE_FLUSH_ON_SIGTERM")
+	assert.True(t, newConfig().flushOnSIGTERM)
+}
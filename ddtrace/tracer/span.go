@@ -74,6 +74,8 @@ type span struct {
 	ParentID uint64             `msg:"parent_id"`         // identifier of the span's direct parent
 	Error    int32              `msg:"error"`             // error status of the span; 0 means no errors
 
+	MetaStruct map[string][]byte `msg:"meta_struct,omitempty"` // arbitrary map of structured metadata, encoded with msgpack
+
 	noDebugStack bool         `msg:"-"` // disables debug stack traces
 	finished     bool         `msg:"-"` // true if the span has been submitted to a tracer.
 	context      *spanContext `msg:"-"` // span propagation context
@@ -81,6 +83,16 @@ type span struct {
 	pprofCtxActive  context.Context `msg:"-"` // contains pprof.WithLabel labels to tell the profiler more about this span
 	pprofCtxRestore context.Context `msg:"-"` // contains pprof.WithLabel labels of the parent span (if any) that need to be restored when this span finishes
 
+	startMonotonic time.Time `msg:"-"` // start time holding a monotonic clock reading, zero unless the duration is measured with it
+
+	goroutineID uint64 `msg:"-"` // ID of the goroutine which started the span, if it was registered as its active span
+
+	links []ddtrace.SpanLink `msg:"-"` // links to other spans, encoded as the _dd.span_links tag
+
+	attrLimits *attributeLimits `msg:"-"` // limits applied to the tags set with SetTag, nil if none
+
+	children int32 `msg:"-"` // number of child spans started, when their number is limited with WithMaxChildSpans
+
 	taskEnd func() // ends execution tracer (runtime/trace) task, if started
 }
 
@@ -102,6 +114,20 @@ func (s *span) BaggageItem(key string) string {
 	return s.context.baggageItem(key)
 }
 
+// AddSpanLinks adds links to the span. It has no effect once the span is finished.
+func (s *span) AddSpanLinks(links ...ddtrace.SpanLink) {
+	if len(links) == 0 {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	if s.finished {
+		return
+	}
+	s.links = append(s.links, links...)
+	s.setMeta(keySpanLinks, encodeSpanLinks(s.links))
+}
+
 // SetTag adds a set of key/value metadata to the span.
 func (s *span) SetTag(key string, value interface{}) {
 	s.Lock()
@@ -119,10 +145,17 @@ func (s *span) SetTag(key string, value interface{}) {
 		})
 		return
 	}
+	if !s.allowAttribute(key) {
+		return
+	}
 	if v, ok := value.(bool); ok {
 		s.setTagBool(key, v)
 		return
 	}
+	if v, ok := value.(metaStructValue); ok {
+		s.setMetaStruct(key, v.value)
+		return
+	}
 	if v, ok := value.(string); ok {
 		if key == ext.ResourceName && s.pprofCtxActive != nil && spanResourcePIISafe(s) {
 			// If the user overrides the resource name for the span,
@@ -134,7 +167,7 @@ func (s *span) SetTag(key string, value interface{}) {
 			s.pprofCtxActive = pprof.WithLabels(s.pprofCtxActive, pprof.Labels(traceprof.TraceEndpoint, v))
 			pprof.SetGoroutineLabels(s.pprofCtxActive)
 		}
-		s.setMeta(key, v)
+		s.setMeta(key, s.limitValue(key, v))
 		return
 	}
 	if v, ok := toFloat64(value); ok {
@@ -154,11 +187,11 @@ func (s *span) SetTag(key string, value interface{}) {
 				panic(e)
 			}
 		}()
-		s.setMeta(key, v.String())
+		s.setMeta(key, s.limitValue(key, v.String()))
 		return
 	}
 	// not numeric, not a string, not a fmt.Stringer, not a bool, and not an error
-	s.setMeta(key, fmt.Sprint(value))
+	s.setMeta(key, s.limitValue(key, fmt.Sprint(value)))
 }
 
 // setSamplingPriority locks then span, then updates the sampling priority.
@@ -340,6 +373,10 @@ func (s *span) setMetric(key string, v float64) {
 // of its part of the tracing session.
 func (s *span) Finish(opts ...ddtrace.FinishOption) {
 	t := now()
+	elapsed := time.Duration(-1)
+	if !s.startMonotonic.IsZero() {
+		elapsed = time.Since(s.startMonotonic)
+	}
 	if len(opts) > 0 {
 		cfg := ddtrace.FinishConfig{
 			NoDebugStack: s.noDebugStack,
@@ -349,6 +386,7 @@ func (s *span) Finish(opts ...ddtrace.FinishOption) {
 		}
 		if !cfg.FinishTime.IsZero() {
 			t = cfg.FinishTime.UnixNano()
+			elapsed = -1
 		}
 		if cfg.Error != nil {
 			s.Lock()
@@ -363,13 +401,16 @@ func (s *span) Finish(opts ...ddtrace.FinishOption) {
 	if s.taskEnd != nil {
 		s.taskEnd()
 	}
-	s.finish(t)
+	s.finish(t, elapsed)
 
 	if s.pprofCtxRestore != nil {
 		// Restore the labels of the parent span so any CPU samples after this
 		// point are attributed correctly.
 		pprof.SetGoroutineLabels(s.pprofCtxRestore)
 	}
+	if s.goroutineID != 0 {
+		goroutineSpans.pop(s)
+	}
 }
 
 // SetOperationName sets or changes the operation name.
@@ -380,7 +421,9 @@ func (s *span) SetOperationName(operationName string) {
 	s.Name = operationName
 }
 
-func (s *span) finish(finishTime int64) {
+// finish finishes the span at finishTime, or after elapsed according to the monotonic
+// clock if elapsed isn't negative.
+func (s *span) finish(finishTime int64, elapsed time.Duration) {
 	s.Lock()
 	defer s.Unlock()
 	// We don't lock spans when flushing, so we could have a data race when
@@ -390,8 +433,13 @@ func (s *span) finish(finishTime int64) {
 		// already finished
 		return
 	}
+	if rate, ok := s.Metrics[ext.EventSampleRate]; ok {
+		if t, ok := internal.GetGlobalTracer().(*tracer); ok && t.config.keepAnalyticsEvents {
+			s.keepAnalyticsEvent(rate)
+		}
+	}
 	if s.Duration == 0 {
-		s.Duration = finishTime - s.Start
+		s.setDuration(finishTime, elapsed)
 	}
 	if s.Duration < 0 {
 		s.Duration = 0
@@ -401,6 +449,19 @@ func (s *span) finish(finishTime int64) {
 	keep := true
 	if t, ok := internal.GetGlobalTracer().(*tracer); ok {
 		// we have an active tracer
+		if t.openSpans != nil {
+			t.openSpans.remove(s)
+		}
+		if svc := t.config.serviceName; svc != "" && s.Service != svc {
+			// the span is reported under a service other than the one of the application,
+			// e.g. by an integration configured with WithServiceName: attribute it to the
+			// application.
+			s.Meta[keyBaseService] = svc
+		}
+		if !t.overhead.throttled() {
+			t.checkRequiredTags(s)
+		}
+		t.overhead.addWAFDuration(s)
 		if t.config.canComputeStats() && shouldComputeStats(s) {
 			// the agent supports computed stats
 			select {
@@ -422,6 +483,30 @@ func (s *span) finish(finishTime int64) {
 	s.context.finish()
 }
 
+// keepAnalyticsEvent keeps the trace of the span when it is sampled as an analytics event
+// at the given rate, so that the events of the deprecated App Analytics can be retained
+// from the ingested traces by retention filters. The decision is recorded as made by a
+// sampling rule if one matched the span, and as made manually otherwise. Traces dropped
+// explicitly or by the client sampler are not kept. This method is not safe for
+// concurrent use.
+func (s *span) keepAnalyticsEvent(rate float64) {
+	if p, ok := s.context.samplingPriority(); ok && p != ext.PriorityAutoReject {
+		return
+	}
+	if samplingDecision(atomic.LoadInt64((*int64)(&s.context.trace.samplingDecision))) == decisionDrop {
+		// dropped by the client sampler
+		return
+	}
+	if rate <= 0 || !sampledByRate(s.TraceID, rate) {
+		return
+	}
+	sampler := samplernames.Manual
+	if _, ok := s.Metrics[keyRulesSamplerAppliedRate]; ok {
+		sampler = samplernames.RuleRate
+	}
+	s.setSamplingPriorityLocked(ext.PriorityUserKeep, sampler, rate)
+}
+
 // newAggregableSpan creates a new summary for the span s, within an application
 // version version.
 func newAggregableSpan(s *span, obfuscator *obfuscate.Obfuscator) *aggregableSpan {
@@ -525,6 +610,9 @@ func (s *span) String() string {
 	for key, val := range s.Metrics {
 		lines = append(lines, fmt.Sprintf("\t%s:%f", key, val))
 	}
+	for key, val := range s.MetaStruct {
+		lines = append(lines, fmt.Sprintf("\t%s:<%d bytes>", key, len(val)))
+	}
 	s.RUnlock()
 	return strings.Join(lines, "\n")
 }
@@ -563,11 +651,19 @@ const (
 	keySamplingPriority        = "_sampling_priority_v1"
 	keySamplingPriorityRate    = "_dd.agent_psr"
 	keyUpstreamServices        = "_dd.p.upstream_services"
+	keyDecisionMaker           = "_dd.p.dm"
+	keyTraceDebug              = "_dd.p.debug"
+	keyTraceID128              = "_dd.p.tid" // the upper 64 bits of 128-bit trace IDs, in hexadecimal
+	keyPropagationError        = "_dd.propagation_error"
 	keyOrigin                  = "_dd.origin"
 	keyHostname                = "_dd.hostname"
 	keyRulesSamplerAppliedRate = "_dd.rule_psr"
 	keyRulesSamplerLimiterRate = "_dd.limit_psr"
 	keyMeasured                = "_dd.measured"
+	keySpanLinks               = "_dd.span_links"
+	// keyBaseService holds the service of the application reporting a span whose service
+	// was overridden.
+	keyBaseService = "_dd.base_service"
 	// keyTopLevel is the key of top level metric indicating if a span is top level.
 	// A top level span is a local root (parent span of the local trace) or the first span of each service.
 	keyTopLevel = "_dd.top_level"
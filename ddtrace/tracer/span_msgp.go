@@ -133,6 +133,33 @@ func (z *span) DecodeMsg(dc *msgp.Reader) (err error) {
 			if err != nil {
 				return
 			}
+		case "meta_struct":
+			var zb0004 uint32
+			zb0004, err = dc.ReadMapHeader()
+			if err != nil {
+				return
+			}
+			if z.MetaStruct == nil && zb0004 > 0 {
+				z.MetaStruct = make(map[string][]byte, zb0004)
+			} else if len(z.MetaStruct) > 0 {
+				for key := range z.MetaStruct {
+					delete(z.MetaStruct, key)
+				}
+			}
+			for zb0004 > 0 {
+				zb0004--
+				var za0005 string
+				var za0006 []byte
+				za0005, err = dc.ReadString()
+				if err != nil {
+					return
+				}
+				za0006, err = dc.ReadBytes(za0006)
+				if err != nil {
+					return
+				}
+				z.MetaStruct[za0005] = za0006
+			}
 		default:
 			err = dc.Skip()
 			if err != nil {
@@ -145,9 +172,9 @@ func (z *span) DecodeMsg(dc *msgp.Reader) (err error) {
 
 // EncodeMsg implements msgp.Encodable
 func (z *span) EncodeMsg(en *msgp.Writer) (err error) {
-	// map header, size 12
+	// map header, size 13
 	// write "name"
-	err = en.Append(0x8c, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
+	err = en.Append(0x8d, 0xa4, 0x6e, 0x61, 0x6d, 0x65)
 	if err != nil {
 		return
 	}
@@ -274,6 +301,25 @@ func (z *span) EncodeMsg(en *msgp.Writer) (err error) {
 	if err != nil {
 		return
 	}
+	// write "meta_struct"
+	err = en.Append(0xab, 0x6d, 0x65, 0x74, 0x61, 0x5f, 0x73, 0x74, 0x72, 0x75, 0x63, 0x74)
+	if err != nil {
+		return
+	}
+	err = en.WriteMapHeader(uint32(len(z.MetaStruct)))
+	if err != nil {
+		return
+	}
+	for za0005, za0006 := range z.MetaStruct {
+		err = en.WriteString(za0005)
+		if err != nil {
+			return
+		}
+		err = en.WriteBytes(za0006)
+		if err != nil {
+			return
+		}
+	}
 	return
 }
 
@@ -293,7 +339,13 @@ func (z *span) Msgsize() (s int) {
 			s += msgp.StringPrefixSize + len(za0003) + msgp.Float64Size
 		}
 	}
-	s += 8 + msgp.Uint64Size + 9 + msgp.Uint64Size + 10 + msgp.Uint64Size + 6 + msgp.Int32Size
+	s += 8 + msgp.Uint64Size + 9 + msgp.Uint64Size + 10 + msgp.Uint64Size + 6 + msgp.Int32Size + 12 + msgp.MapHeaderSize
+	if z.MetaStruct != nil {
+		for za0005, za0006 := range z.MetaStruct {
+			_ = za0006
+			s += msgp.StringPrefixSize + len(za0005) + msgp.BytesPrefixSize + len(za0006)
+		}
+	}
 	return
 }
 
@@ -81,6 +81,40 @@ func TestSpanFinish(t *testing.T) {
 	assert.True(span.finished)
 }
 
+func TestSpanBaseService(t *testing.T) {
+	tracer, _, _, stop := startTestTracer(t, WithService("app"))
+	defer stop()
+
+	t.Run("same", func(t *testing.T) {
+		span := tracer.StartSpan("http.request").(*span)
+		span.Finish()
+		assert.NotContains(t, span.Meta, keyBaseService)
+	})
+
+	t.Run("override", func(t *testing.T) {
+		span := tracer.StartSpan("redis.command", ServiceName("redis")).(*span)
+		span.Finish()
+		assert.Equal(t, "redis", span.Service)
+		assert.Equal(t, "app", span.Meta[keyBaseService])
+	})
+
+	t.Run("set-tag", func(t *testing.T) {
+		span := tracer.StartSpan("vault.request").(*span)
+		span.SetTag(ext.ServiceName, "vault")
+		span.Finish()
+		assert.Equal(t, "app", span.Meta[keyBaseService])
+	})
+
+	t.Run("child", func(t *testing.T) {
+		parent := tracer.StartSpan("grpc.client", ServiceName("grpc-client")).(*span)
+		child := tracer.StartSpan("grpc.message", ChildOf(parent.Context())).(*span)
+		child.Finish()
+		parent.Finish()
+		assert.Equal(t, "grpc-client", child.Service)
+		assert.Equal(t, "app", child.Meta[keyBaseService])
+	})
+}
+
 func TestSpanFinishTwice(t *testing.T) {
 	assert := assert.New(t)
 	wait := time.Millisecond * 2
@@ -485,9 +519,9 @@ func TestSpanError(t *testing.T) {
 	span.Finish()
 	span.SetTag(ext.Error, err)
 	assert.Equal(int32(0), span.Error)
-	// '+1' is `_dd.p.upstream_services`,
-	// because we add it into Meta of the first span, when root is finished.
-	assert.Equal(nMeta+1, len(span.Meta))
+	// '+2' is `_dd.p.upstream_services` and `_dd.p.dm`,
+	// because we add them into Meta of the first span, when root is finished.
+	assert.Equal(nMeta+2, len(span.Meta))
 	assert.Equal("", span.Meta["error.msg"])
 	assert.Equal("", span.Meta["error.type"])
 	assert.Equal("", span.Meta["error.stack"])
@@ -747,3 +781,47 @@ type stringer struct{}
 func (s *stringer) String() string {
 	return "string"
 }
+
+func TestSpanKeepAnalyticsEvent(t *testing.T) {
+	t.Run("disabled", func(t *testing.T) {
+		tracer, _, _, stop := startTestTracer(t)
+		defer stop()
+		tracer.prioritySampling.defaultRate = 0
+
+		span := tracer.StartSpan("http.request", AnalyticsRate(1)).(*span)
+		span.Finish()
+		assert.Equal(t, float64(ext.PriorityAutoReject), span.Metrics[keySamplingPriority])
+		assert.NotContains(t, span.context.trace.tags, keyDecisionMaker)
+	})
+
+	tracer, _, _, stop := startTestTracer(t, WithKeepAnalyticsEvents(true))
+	defer stop()
+	tracer.prioritySampling.defaultRate = 0
+
+	t.Run("kept", func(t *testing.T) {
+		span := tracer.StartSpan("http.request", AnalyticsRate(1)).(*span)
+		span.Finish()
+		assert.Equal(t, float64(ext.PriorityUserKeep), span.Metrics[keySamplingPriority])
+		assert.NotContains(t, span.Metrics, keyRulesSamplerAppliedRate)
+		assert.Equal(t, "-4", span.context.trace.tags[keyDecisionMaker])
+	})
+
+	t.Run("not-sampled", func(t *testing.T) {
+		span := tracer.StartSpan("http.request", AnalyticsRate(0)).(*span)
+		span.Finish()
+		assert.Equal(t, float64(ext.PriorityAutoReject), span.Metrics[keySamplingPriority])
+	})
+
+	t.Run("manual-drop", func(t *testing.T) {
+		span := tracer.StartSpan("http.request", AnalyticsRate(1), Tag(ext.ManualDrop, true)).(*span)
+		span.Finish()
+		assert.Equal(t, float64(ext.PriorityUserReject), span.Metrics[keySamplingPriority])
+	})
+
+	t.Run("analytics-event", func(t *testing.T) {
+		span := tracer.StartSpan("http.request").(*span)
+		span.SetTag(ext.AnalyticsEvent, true)
+		span.Finish()
+		assert.Equal(t, float64(ext.PriorityUserKeep), span.Metrics[keySamplingPriority])
+	})
+}
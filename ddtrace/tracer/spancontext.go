@@ -6,8 +6,10 @@
 package tracer
 
 import (
+	"fmt"
 	"math"
 	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 
@@ -74,12 +76,91 @@ func newSpanContext(span *span, parent *spanContext) *spanContext {
 	return context
 }
 
+// SpanContextConfig holds the values of a span context created by NewSpanContext.
+type SpanContextConfig struct {
+	// TraceID and SpanID identify the parent span. They are required, except for SpanID
+	// when Origin is "synthetics".
+	TraceID uint64
+	SpanID  uint64
+
+	// SamplingPriority holds the sampling decision of the upstream system, if any. A nil
+	// value leaves the decision to the tracer.
+	SamplingPriority *int
+
+	// Origin holds the origin of the trace, e.g. "synthetics".
+	Origin string
+
+	// Baggage holds baggage items which will be propagated to descendant spans.
+	Baggage map[string]string
+
+	// PropagatingTags holds the trace tags set by upstream services. Only tags having
+	// the "_dd.p." prefix are kept.
+	PropagatingTags map[string]string
+}
+
+// NewSpanContext returns a span context holding the values of cfg, as if it had been
+// extracted from a carrier. It allows systems which propagate these values using their
+// own protocols to continue the trace, by using the returned context with ChildOf.
+// ErrInvalidSpanContext is returned if the config doesn't identify a span.
+func NewSpanContext(cfg SpanContextConfig) (ddtrace.SpanContext, error) {
+	if cfg.TraceID == 0 || (cfg.SpanID == 0 && cfg.Origin != "synthetics") {
+		return nil, ErrInvalidSpanContext
+	}
+	ctx := &spanContext{
+		traceID: cfg.TraceID,
+		spanID:  cfg.SpanID,
+		origin:  cfg.Origin,
+	}
+	if len(cfg.PropagatingTags) > 0 {
+		ctx.trace = newTrace()
+		for k, v := range cfg.PropagatingTags {
+			if strings.HasPrefix(k, propagatingTagPrefix) {
+				ctx.trace.setTag(k, v)
+			}
+		}
+		ctx.trace.upstreamServices = ctx.trace.tags[keyUpstreamServices]
+	}
+	if cfg.SamplingPriority != nil {
+		ctx.setSamplingPriority("", *cfg.SamplingPriority, samplernames.Upstream, math.NaN())
+	}
+	for k, v := range cfg.Baggage {
+		ctx.setBaggageItem(k, v)
+	}
+	return ctx, nil
+}
+
 // SpanID implements ddtrace.SpanContext.
 func (c *spanContext) SpanID() uint64 { return c.spanID }
 
 // TraceID implements ddtrace.SpanContext.
 func (c *spanContext) TraceID() uint64 { return c.traceID }
 
+// TraceID128 returns the 128-bit ID of the trace of the span context as 32 hexadecimal digits.
+// Its upper 64 bits are those received from upstream along with the trace ID, if any, and
+// are zero otherwise.
+func (c *spanContext) TraceID128() string {
+	return fmt.Sprintf("%016x%016x", c.traceIDUpper(), c.traceID)
+}
+
+// traceIDUpper returns the upper 64 bits of the 128-bit trace ID, held by the _dd.p.tid
+// trace tag, or zero when it isn't set or is invalid.
+func (c *spanContext) traceIDUpper() uint64 {
+	if c.trace == nil {
+		return 0
+	}
+	c.trace.mu.RLock()
+	v := c.trace.tags[keyTraceID128]
+	c.trace.mu.RUnlock()
+	if len(v) != 16 {
+		return 0
+	}
+	upper, err := strconv.ParseUint(v, 16, 64)
+	if err != nil {
+		return 0
+	}
+	return upper
+}
+
 // ForeachBaggageItem implements ddtrace.SpanContext.
 func (c *spanContext) ForeachBaggageItem(handler func(k, v string) bool) {
 	if atomic.LoadInt32(&c.hasBaggage) == 0 {
@@ -101,6 +182,23 @@ func (c *spanContext) setSamplingPriority(service string, p int, sampler sampler
 	c.trace.setSamplingPriority(service, p, sampler, rate)
 }
 
+// setDebug marks the trace as being debugged, which forces it to be kept. The flag
+// is propagated to downstream services along with the other trace tags.
+func (c *spanContext) setDebug() {
+	if c.trace == nil {
+		c.trace = newTrace()
+	}
+	t := c.trace
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.setTag(keyTraceDebug, "1")
+	// the decision is not made by a service, so it isn't recorded in the upstream services
+	t.setSamplingPriorityLocked("", ext.PriorityUserKeep, samplernames.Upstream, math.NaN())
+	if _, ok := t.tags[keyDecisionMaker]; !ok {
+		t.setTag(keyDecisionMaker, "-"+strconv.Itoa(int(samplernames.Manual)))
+	}
+}
+
 func (c *spanContext) samplingPriority() (p int, ok bool) {
 	if c.trace == nil {
 		return 0, false
@@ -108,6 +206,12 @@ func (c *spanContext) samplingPriority() (p int, ok bool) {
 	return c.trace.samplingPriority()
 }
 
+// SamplingPriority returns the sampling priority of the trace of the span context. A second
+// return value indicates if the priority was set.
+func (c *spanContext) SamplingPriority() (p int, ok bool) {
+	return c.samplingPriority()
+}
+
 func (c *spanContext) setBaggageItem(key, val string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -158,6 +262,7 @@ type trace struct {
 	spans            []*span           // all the spans that are part of this trace
 	tags             map[string]string // trace level tags
 	upstreamServices string            // _dd.p.upstream_services value from the upstream service
+	tracestate       string            // tracestate list members of other vendors, from the upstream service
 	finished         int               // the number of finished spans
 	full             bool              // signifies that the span buffer is full
 	priority         *float64          // sampling priority
@@ -217,6 +322,13 @@ func (t *trace) drop() {
 	atomic.CompareAndSwapInt64((*int64)(&t.samplingDecision), int64(decisionNone), int64(decisionDrop))
 }
 
+// isDebug reports whether the trace was marked as being debugged.
+func (t *trace) isDebug() bool {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.tags[keyTraceDebug] != ""
+}
+
 func (t *trace) setTag(key, value string) {
 	if t.tags == nil {
 		t.tags = make(map[string]string, 1)
@@ -232,12 +344,21 @@ func (t *trace) setSamplingPriorityLocked(service string, p int, sampler sampler
 		t.priority = new(float64)
 	}
 	*t.priority = float64(p)
+	_, hasDM := t.tags[keyDecisionMaker]
+	if p <= 0 && hasDM {
+		// the trace is dropped, there is no decision maker to propagate
+		delete(t.tags, keyDecisionMaker)
+	}
 	if sampler != samplernames.Upstream {
 		if t.upstreamServices != "" {
 			t.setTag(keyUpstreamServices, t.upstreamServices+";"+compactUpstreamServices(service, p, sampler, rate))
 		} else {
 			t.setTag(keyUpstreamServices, compactUpstreamServices(service, p, sampler, rate))
 		}
+		if p > 0 && !hasDM {
+			// the first service keeping the trace is its decision maker
+			t.setTag(keyDecisionMaker, "-"+strconv.Itoa(int(sampler)))
+		}
 	}
 }
 
@@ -257,6 +378,7 @@ func (t *trace) push(sp *span) {
 		log.Error("trace buffer full (%d), dropping trace", traceMaxSize)
 		if haveTracer {
 			atomic.AddInt64(&tr.tracesDropped, 1)
+			atomic.AddUint64(&tr.debug.tracesTooLarge, 1)
 		}
 		return
 	}
@@ -313,6 +435,8 @@ func (t *trace) finishedOne(s *span) {
 	}
 	// we have a tracer that can receive completed traces.
 	atomic.AddInt64(&tr.spansFinished, int64(len(t.spans)))
+	// the exporters receive the traces regardless of the sampling decision
+	tr.exportTrace(t.spans)
 	sd := samplingDecision(atomic.LoadInt64((*int64)(&t.samplingDecision)))
 	if sd != decisionKeep {
 		if p, ok := t.samplingPriorityLocked(); ok && p == ext.PriorityAutoReject {
@@ -207,6 +207,18 @@ func TestSpanTracePushSeveral(t *testing.T) {
 	}
 }
 
+func TestSpanContextSamplingPriority(t *testing.T) {
+	ctx := &spanContext{}
+	_, ok := ctx.SamplingPriority()
+	assert.False(t, ok)
+
+	ctx = newBasicSpan("root").context
+	ctx.setSamplingPriority("", ext.PriorityUserReject, samplernames.Manual, math.NaN())
+	p, ok := ctx.SamplingPriority()
+	assert.True(t, ok)
+	assert.Equal(t, ext.PriorityUserReject, p)
+}
+
 // TestSpanFinishPriority asserts that the root span will have the sampling
 // priority metric set by inheriting it from a child.
 func TestSpanFinishPriority(t *testing.T) {
@@ -284,7 +296,7 @@ func TestNewSpanContext(t *testing.T) {
 		ctx, err := NewPropagator(nil).Extract(TextMapCarrier(map[string]string{
 			DefaultTraceIDHeader:  "1",
 			DefaultParentIDHeader: "2",
-			DefaultPriorityHeader: "3",
+			DefaultPriorityHeader: "2",
 		}))
 		assert.Nil(err)
 		sctx, ok := ctx.(*spanContext)
@@ -292,11 +304,62 @@ func TestNewSpanContext(t *testing.T) {
 		span := StartSpan("some-span", ChildOf(ctx))
 		assert.EqualValues(sctx.traceID, 1)
 		assert.EqualValues(sctx.spanID, 2)
-		assert.EqualValues(*sctx.trace.priority, 3)
+		assert.EqualValues(*sctx.trace.priority, 2)
 		assert.Equal(sctx.trace.root, span)
 	})
 }
 
+func TestNewSpanContextFromConfig(t *testing.T) {
+	t.Run("invalid", func(t *testing.T) {
+		_, err := NewSpanContext(SpanContextConfig{SpanID: 1})
+		assert.Equal(t, ErrInvalidSpanContext, err)
+		_, err = NewSpanContext(SpanContextConfig{TraceID: 1})
+		assert.Equal(t, ErrInvalidSpanContext, err)
+		_, err = NewSpanContext(SpanContextConfig{TraceID: 1, Origin: "synthetics"})
+		assert.NoError(t, err)
+	})
+
+	t.Run("parent", func(t *testing.T) {
+		assert := assert.New(t)
+		tracer := newTracer()
+		defer tracer.Stop()
+		priority := ext.PriorityUserKeep
+		sctx, err := NewSpanContext(SpanContextConfig{
+			TraceID:          123,
+			SpanID:           456,
+			SamplingPriority: &priority,
+			Origin:           "rpc",
+			Baggage:          map[string]string{"item": "x"},
+			PropagatingTags: map[string]string{
+				keyDecisionMaker: "-4",
+				"other":          "ignored",
+			},
+		})
+		assert.NoError(err)
+		assert.Equal(uint64(123), sctx.TraceID())
+		assert.Equal(uint64(456), sctx.SpanID())
+
+		child := tracer.StartSpan("child", ChildOf(sctx)).(*span)
+		assert.Equal(uint64(123), child.TraceID)
+		assert.Equal(uint64(456), child.ParentID)
+		assert.Equal(float64(ext.PriorityUserKeep), child.Metrics[keySamplingPriority])
+		assert.Equal("rpc", child.Meta[keyOrigin])
+		assert.Equal("x", child.BaggageItem("item"))
+		assert.Equal(map[string]string{keyDecisionMaker: "-4"}, child.context.trace.tags)
+	})
+
+	t.Run("no-priority", func(t *testing.T) {
+		tracer := newTracer()
+		defer tracer.Stop()
+		sctx, err := NewSpanContext(SpanContextConfig{TraceID: 123, SpanID: 456})
+		assert.NoError(t, err)
+		child := tracer.StartSpan("child", ChildOf(sctx)).(*span)
+		// the tracer makes the sampling decision
+		_, ok := child.Metrics[keySamplingPriority]
+		assert.True(t, ok)
+	})
+}
+
 func TestSpanContextParent(t *testing.T) {
 	s := &span{
 		TraceID:  1,
@@ -12,9 +12,9 @@ import (
 	"sync/atomic"
 	"time"
 
+	"github.com/codebrick-corp/dd-trace-go/internal/dogstatsd"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
 
-	"github.com/DataDog/datadog-go/v5/statsd"
 	"github.com/DataDog/sketches-go/ddsketch"
 	"google.golang.org/protobuf/proto"
 )
@@ -124,7 +124,7 @@ func (c *concentrator) runFlusher(tick <-chan time.Time) {
 // statsd returns any tracer configured statsd client, or a no-op.
 func (c *concentrator) statsd() statsdClient {
 	if c.cfg.statsd == nil {
-		return &statsd.NoOpClient{}
+		return dogstatsd.NoOp()
 	}
 	return c.cfg.statsd
 }
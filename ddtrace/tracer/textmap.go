@@ -10,6 +10,7 @@ import (
 	"math"
 	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -68,6 +69,12 @@ func (c TextMapCarrier) ForeachKey(handler func(key, val string) error) error {
 const (
 	headerPropagationStyleInject  = "DD_PROPAGATION_STYLE_INJECT"
 	headerPropagationStyleExtract = "DD_PROPAGATION_STYLE_EXTRACT"
+
+	headerTracePropagationStyle        = "DD_TRACE_PROPAGATION_STYLE"
+	headerTracePropagationStyleInject  = "DD_TRACE_PROPAGATION_STYLE_INJECT"
+	headerTracePropagationStyleExtract = "DD_TRACE_PROPAGATION_STYLE_EXTRACT"
+
+	headerTracePropagationStyleExtractFallback = "DD_TRACE_PROPAGATION_STYLE_EXTRACT_FALLBACK"
 )
 
 const (
@@ -92,9 +99,22 @@ const (
 // It is used with the Synthetics product and usually has the value "synthetics".
 const originHeader = "x-datadog-origin"
 
+// traceDebugHeader specifies the name of the header which marks a trace as being debugged.
+// Debugged traces are always kept, and the flag is propagated to downstream services so
+// that the whole trace can be troubleshot. The flag is only extracted when enabled with
+// PropagatorConfig.TraceDebug.
+const traceDebugHeader = "x-datadog-trace-debug"
+
 // traceTagsHeader holds the propagated trace tags
 const traceTagsHeader = "x-datadog-tags"
 
+// propagatingTagPrefix is the prefix of the trace tags which are propagated in traceTagsHeader.
+const propagatingTagPrefix = "_dd.p."
+
+// propagationExtractMaxSize limits the length of the traceTagsHeader value which gets
+// extracted, regardless of the limit used when injecting.
+const propagationExtractMaxSize = 512
+
 // PropagatorConfig defines the configuration for initializing a propagator.
 type PropagatorConfig struct {
 	// BaggagePrefix specifies the prefix that will be used to store baggage
@@ -114,11 +134,52 @@ type PropagatorConfig struct {
 	PriorityHeader string
 
 	// MaxTagsHeaderLen specifies the maximum length of trace tags header value.
+	// It defaults to 512. Trace tags are not injected if their length exceeds it, or if
+	// it is negative.
 	MaxTagsHeaderLen int
 
 	// B3 specifies if B3 headers should be added for trace propagation.
 	// See https://github.com/openzipkin/b3-propagation
 	B3 bool
+
+	// InjectStyles specifies the propagation styles used to inject span contexts. All
+	// of them are injected, which allows downstream services to be migrated from one
+	// style to another gradually. Valid styles are "datadog", "b3" (or "b3multi"),
+	// "tracecontext" and "none". When empty, the styles are read from the
+	// DD_TRACE_PROPAGATION_STYLE_INJECT, DD_PROPAGATION_STYLE_INJECT and
+	// DD_TRACE_PROPAGATION_STYLE environment variables, in that order, and default
+	// to "datadog".
+	InjectStyles []string
+
+	// ExtractStyles specifies the propagation styles used to extract span contexts, in
+	// order of preference: the span context is extracted using the first style found in
+	// the carrier. When empty, the styles are read from the DD_TRACE_PROPAGATION_STYLE_EXTRACT,
+	// DD_PROPAGATION_STYLE_EXTRACT and DD_TRACE_PROPAGATION_STYLE environment variables,
+	// in that order, and default to "datadog".
+	ExtractStyles []string
+
+	// ExtractFallbackStyles specifies the propagation styles used by ExtractFallback to extract
+	// the span contexts which the ExtractStyles don't, in order of preference, e.g. "tracecontext"
+	// and "b3" for services receiving requests from callers instrumented with OpenTelemetry. When
+	// empty, the styles are read from the DD_TRACE_PROPAGATION_STYLE_EXTRACT_FALLBACK environment
+	// variable. No span context is extracted by ExtractFallback when neither of them is set, or
+	// when the extraction is disabled with the "none" style. The styles of the propagators set
+	// with WithPropagator are ignored, as ExtractFallback doesn't apply to custom propagators.
+	ExtractFallbackStyles []string
+
+	// IgnoreUpstreamPriority specifies whether the sampling priorities and debug flags of
+	// extracted span contexts are discarded, letting the tracer make its own sampling
+	// decision. It is meant for services receiving requests from untrusted edges, such as
+	// API gateways shared by several organizations. Extracted priorities are otherwise
+	// clamped to the valid range, from PriorityUserReject to PriorityUserKeep.
+	IgnoreUpstreamPriority bool
+
+	// TraceDebug specifies whether the debug flags of extracted span contexts, set with the
+	// x-datadog-trace-debug header or the _dd.p.debug trace tag, are honoured, forcing their
+	// traces to be kept. Any client able to reach the service can set them, bypassing the
+	// sampling rules and rate limits, so it should only be enabled for services whose
+	// requests come from trusted sources. The flags are otherwise discarded.
+	TraceDebug bool
 }
 
 // NewPropagator returns a new propagator which uses TextMap to inject
@@ -140,16 +201,61 @@ func NewPropagator(cfg *PropagatorConfig, propagators ...Propagator) Propagator
 	if cfg.PriorityHeader == "" {
 		cfg.PriorityHeader = DefaultPriorityHeader
 	}
+	if cfg.MaxTagsHeaderLen == 0 {
+		cfg.MaxTagsHeaderLen = defaultMaxTagsHeaderLen
+	}
 	if len(propagators) > 0 {
 		return &chainedPropagator{
-			injectors:  propagators,
-			extractors: propagators,
+			injectors:              propagators,
+			extractors:             propagators,
+			ignoreUpstreamPriority: cfg.IgnoreUpstreamPriority,
+			traceDebug:             cfg.TraceDebug,
 		}
 	}
+	injectStyles := cfg.InjectStyles
+	if len(injectStyles) == 0 {
+		injectStyles = propagationStyles(headerTracePropagationStyleInject, headerPropagationStyleInject, headerTracePropagationStyle)
+	}
+	extractStyles := cfg.ExtractStyles
+	if len(extractStyles) == 0 {
+		extractStyles = propagationStyles(headerTracePropagationStyleExtract, headerPropagationStyleExtract, headerTracePropagationStyle)
+	}
+	fallbackStyles := cfg.ExtractFallbackStyles
+	if len(fallbackStyles) == 0 {
+		fallbackStyles = propagationStyles(headerTracePropagationStyleExtractFallback)
+	}
+	var fallbackExtractors []Propagator
+	if len(fallbackStyles) > 0 && !hasStyle(extractStyles, "none") {
+		fallbackExtractors = getPropagators(cfg, fallbackStyles)
+	}
 	return &chainedPropagator{
-		injectors:  getPropagators(cfg, headerPropagationStyleInject),
-		extractors: getPropagators(cfg, headerPropagationStyleExtract),
+		injectors:              getPropagators(cfg, injectStyles),
+		extractors:             getPropagators(cfg, extractStyles),
+		fallbackExtractors:     fallbackExtractors,
+		ignoreUpstreamPriority: cfg.IgnoreUpstreamPriority,
+		traceDebug:             cfg.TraceDebug,
+	}
+}
+
+// hasStyle reports whether the propagation style is found in styles.
+func hasStyle(styles []string, style string) bool {
+	for _, v := range styles {
+		if strings.EqualFold(strings.TrimSpace(v), style) {
+			return true
+		}
 	}
+	return false
+}
+
+// propagationStyles returns the comma-separated list of propagation styles found in
+// the first of the given environment variables which is set.
+func propagationStyles(envs ...string) []string {
+	for _, env := range envs {
+		if v := os.Getenv(env); v != "" {
+			return strings.Split(v, ",")
+		}
+	}
+	return nil
 }
 
 // chainedPropagator implements Propagator and applies a list of injectors and extractors.
@@ -158,40 +264,63 @@ func NewPropagator(cfg *PropagatorConfig, propagators ...Propagator) Propagator
 type chainedPropagator struct {
 	injectors  []Propagator
 	extractors []Propagator
+
+	// fallbackExtractors are used by ExtractFallback, see PropagatorConfig.ExtractFallbackStyles.
+	fallbackExtractors []Propagator
+
+	// ignoreUpstreamPriority reports whether the sampling decisions of the extracted span
+	// contexts are discarded.
+	ignoreUpstreamPriority bool
+
+	// traceDebug reports whether the debug flags of the extracted span contexts are kept.
+	traceDebug bool
 }
 
-// getPropagators returns a list of propagators based on the list found in the
-// given environment variable. If the list doesn't contain any valid values the
-// default propagator will be returned. Any invalid values in the list will log
-// a warning and be ignored.
-func getPropagators(cfg *PropagatorConfig, env string) []Propagator {
+// getPropagators returns a list of propagators based on the given styles. If the list
+// doesn't contain any valid values the default propagator will be returned. Any invalid
+// values in the list will log a warning and be ignored. The "none" style disables
+// propagation.
+func getPropagators(cfg *PropagatorConfig, styles []string) []Propagator {
 	dd := &propagator{cfg}
-	ps := os.Getenv(env)
 	defaultPs := []Propagator{dd}
 	if cfg.B3 {
 		defaultPs = append(defaultPs, &propagatorB3{})
 	}
-	if ps == "" {
+	if len(styles) == 0 {
 		return defaultPs
 	}
-	var list []Propagator
+	var (
+		list []Propagator
+		none bool
+		seen = make(map[string]bool)
+	)
 	if cfg.B3 {
 		list = append(list, &propagatorB3{})
+		seen["b3"] = true
 	}
-	for _, v := range strings.Split(ps, ",") {
-		switch strings.ToLower(v) {
+	for _, v := range styles {
+		style := strings.ToLower(strings.TrimSpace(v))
+		if style == "b3multi" {
+			style = "b3"
+		}
+		if seen[style] {
+			continue
+		}
+		seen[style] = true
+		switch style {
 		case "datadog":
 			list = append(list, dd)
 		case "b3":
-			if !cfg.B3 {
-				// propagatorB3 hasn't already been added, add a new one.
-				list = append(list, &propagatorB3{})
-			}
+			list = append(list, &propagatorB3{})
+		case "tracecontext":
+			list = append(list, &propagatorW3C{})
+		case "none":
+			none = true
 		default:
 			log.Warn("unrecognized propagator: %s\n", v)
 		}
 	}
-	if len(list) == 0 {
+	if len(list) == 0 && !none {
 		// return the default
 		return defaultPs
 	}
@@ -213,10 +342,23 @@ func (p *chainedPropagator) Inject(spanCtx ddtrace.SpanContext, carrier interfac
 
 // Extract implements Propagator.
 func (p *chainedPropagator) Extract(carrier interface{}) (ddtrace.SpanContext, error) {
-	for _, v := range p.extractors {
+	return p.extract(p.extractors, carrier)
+}
+
+// extractFallback extracts the span context from carrier with the fallback extractors.
+func (p *chainedPropagator) extractFallback(carrier interface{}) (ddtrace.SpanContext, error) {
+	return p.extract(p.fallbackExtractors, carrier)
+}
+
+// extract extracts the span context from carrier with the first of extractors finding one.
+func (p *chainedPropagator) extract(extractors []Propagator, carrier interface{}) (ddtrace.SpanContext, error) {
+	for _, v := range extractors {
 		ctx, err := v.Extract(carrier)
 		if ctx != nil {
 			// first extractor returns
+			if sctx, ok := ctx.(*spanContext); ok {
+				p.checkSamplingPriority(sctx)
+			}
 			log.Debug("Extracted span context: %#v", ctx)
 			return ctx, nil
 		}
@@ -228,6 +370,40 @@ func (p *chainedPropagator) Extract(carrier interface{}) (ddtrace.SpanContext, e
 	return nil, ErrSpanContextNotFound
 }
 
+// checkSamplingPriority validates the sampling decision of the extracted span context ctx.
+// The decision is discarded when upstream priorities are ignored, in which case the tracer
+// samples the trace itself; otherwise, priorities out of the valid range are clamped.
+func (p *chainedPropagator) checkSamplingPriority(ctx *spanContext) {
+	if ctx.trace == nil {
+		return
+	}
+	t := ctx.trace
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !p.traceDebug {
+		// the flag may be propagated by any style in the trace tags
+		delete(t.tags, keyTraceDebug)
+	}
+	if p.ignoreUpstreamPriority {
+		t.priority = nil
+		delete(t.tags, keyDecisionMaker)
+		delete(t.tags, keyTraceDebug)
+		return
+	}
+	prio, ok := t.samplingPriorityLocked()
+	if !ok {
+		return
+	}
+	switch {
+	case prio < ext.PriorityUserReject:
+		log.Debug("Clamping invalid upstream sampling priority %d to %d", prio, ext.PriorityUserReject)
+		t.setSamplingPriorityLocked("", ext.PriorityUserReject, samplernames.Upstream, math.NaN())
+	case prio > ext.PriorityUserKeep:
+		log.Debug("Clamping invalid upstream sampling priority %d to %d", prio, ext.PriorityUserKeep)
+		t.setSamplingPriorityLocked("", ext.PriorityUserKeep, samplernames.Upstream, math.NaN())
+	}
+}
+
 // propagator implements Propagator and injects/extracts span contexts
 // using datadog headers. Only TextMap carriers are supported.
 type propagator struct {
@@ -257,6 +433,14 @@ func (p *propagator) injectTextMap(spanCtx ddtrace.SpanContext, writer TextMapWr
 	if ctx.origin != "" {
 		writer.Set(originHeader, ctx.origin)
 	}
+	if ctx.trace != nil {
+		if ctx.trace.isDebug() {
+			writer.Set(traceDebugHeader, "1")
+		}
+		if tags := p.marshalPropagatingTags(ctx.trace); tags != "" {
+			writer.Set(traceTagsHeader, tags)
+		}
+	}
 	// propagate OpenTracing baggage
 	for k, v := range ctx.baggage {
 		writer.Set(p.cfg.BaggagePrefix+k, v)
@@ -264,6 +448,49 @@ func (p *propagator) injectTextMap(spanCtx ddtrace.SpanContext, writer TextMapWr
 	return nil
 }
 
+// marshalPropagatingTags returns the value of traceTagsHeader holding the propagating tags
+// of t, sorted by key. If the tags can't be propagated, the reason is set as a tag of the
+// trace and the empty string is returned.
+func (p *propagator) marshalPropagatingTags(t *trace) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	keys := make([]string, 0, len(t.tags))
+	for k := range t.tags {
+		if strings.HasPrefix(k, propagatingTagPrefix) {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	if p.cfg.MaxTagsHeaderLen < 0 {
+		t.setTag(keyPropagationError, "disabled")
+		return ""
+	}
+	sort.Strings(keys)
+	var sb strings.Builder
+	for _, k := range keys {
+		v := t.tags[k]
+		if err := isValidPropagatableTraceTag(k, v); err != nil {
+			log.Warn("did not inject trace tags (err: %s)", err.Error())
+			t.setTag(keyPropagationError, "encoding_error")
+			return ""
+		}
+		if sb.Len() > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(v)
+	}
+	if sb.Len() > p.cfg.MaxTagsHeaderLen {
+		log.Warn("did not inject trace tags: size limit exceeded: %d > %d", sb.Len(), p.cfg.MaxTagsHeaderLen)
+		t.setTag(keyPropagationError, "inject_max_size")
+		return ""
+	}
+	return sb.String()
+}
+
 func (p *propagator) Extract(carrier interface{}) (ddtrace.SpanContext, error) {
 	switch c := carrier.(type) {
 	case TextMapReader:
@@ -274,7 +501,10 @@ func (p *propagator) Extract(carrier interface{}) (ddtrace.SpanContext, error) {
 }
 
 func (p *propagator) extractTextMap(reader TextMapReader) (ddtrace.SpanContext, error) {
-	var ctx spanContext
+	var (
+		ctx   spanContext
+		debug bool
+	)
 	err := reader.ForeachKey(func(k, v string) error {
 		var err error
 		key := strings.ToLower(k)
@@ -284,6 +514,8 @@ func (p *propagator) extractTextMap(reader TextMapReader) (ddtrace.SpanContext,
 			if err != nil {
 				return ErrSpanContextCorrupted
 			}
+		case traceDebugHeader:
+			debug, _ = strconv.ParseBool(v)
 		case p.cfg.ParentHeader:
 			ctx.spanID, err = parseUint64(v)
 			if err != nil {
@@ -298,14 +530,7 @@ func (p *propagator) extractTextMap(reader TextMapReader) (ddtrace.SpanContext,
 		case originHeader:
 			ctx.origin = v
 		case traceTagsHeader:
-			if ctx.trace == nil {
-				ctx.trace = newTrace()
-			}
-			ctx.trace.tags, err = parsePropagatableTraceTags(v)
-			ctx.trace.upstreamServices = ctx.trace.tags[keyUpstreamServices]
-			if err != nil {
-				log.Warn("did not extract trace tags (err: %s)", err.Error())
-			}
+			unmarshalPropagatingTags(&ctx, v)
 		default:
 			if strings.HasPrefix(key, p.cfg.BaggagePrefix) {
 				ctx.setBaggageItem(strings.TrimPrefix(key, p.cfg.BaggagePrefix), v)
@@ -319,9 +544,50 @@ func (p *propagator) extractTextMap(reader TextMapReader) (ddtrace.SpanContext,
 	if ctx.traceID == 0 || (ctx.spanID == 0 && ctx.origin != "synthetics") {
 		return nil, ErrSpanContextNotFound
 	}
+	if ctx.trace != nil {
+		// The headers may come in any order: the decision maker is only checked against
+		// the sampling priority once both are known.
+		ctx.trace.mu.Lock()
+		if p, ok := ctx.trace.samplingPriorityLocked(); ok && p <= 0 {
+			// the decision maker is only propagated along with a decision to keep the trace
+			delete(ctx.trace.tags, keyDecisionMaker)
+		}
+		ctx.trace.mu.Unlock()
+	}
+	if p.cfg.TraceDebug && (debug || (ctx.trace != nil && ctx.trace.isDebug())) {
+		ctx.setDebug()
+	}
 	return &ctx, nil
 }
 
+// unmarshalPropagatingTags sets the propagating tags found in v, the value of traceTagsHeader,
+// as tags of the trace of ctx. Tags which aren't propagating tags are ignored. If v can't be
+// extracted, the reason is set as a tag of the trace instead.
+func unmarshalPropagatingTags(ctx *spanContext, v string) {
+	if ctx.trace == nil {
+		ctx.trace = newTrace()
+	}
+	ctx.trace.mu.Lock()
+	defer ctx.trace.mu.Unlock()
+	if len(v) > propagationExtractMaxSize {
+		log.Warn("did not extract trace tags: size limit exceeded: %d > %d", len(v), propagationExtractMaxSize)
+		ctx.trace.setTag(keyPropagationError, "extract_max_size")
+		return
+	}
+	tags, err := parsePropagatableTraceTags(v)
+	if err != nil {
+		log.Warn("did not extract trace tags (err: %s)", err.Error())
+		ctx.trace.setTag(keyPropagationError, "decoding_error")
+		return
+	}
+	for k, v := range tags {
+		if strings.HasPrefix(k, propagatingTagPrefix) {
+			ctx.trace.setTag(k, v)
+		}
+	}
+	ctx.trace.upstreamServices = ctx.trace.tags[keyUpstreamServices]
+}
+
 const (
 	b3TraceIDHeader = "x-b3-traceid"
 	b3SpanIDHeader  = "x-b3-spanid"
@@ -404,3 +670,231 @@ func (*propagatorB3) extractTextMap(reader TextMapReader) (ddtrace.SpanContext,
 	}
 	return &ctx, nil
 }
+
+const (
+	traceparentHeader = "traceparent"
+	tracestateHeader  = "tracestate"
+)
+
+// tracestateMaxMembers is the maximum number of list members of the tracestate header.
+const tracestateMaxMembers = 32
+
+// propagatorW3C implements Propagator and injects/extracts span contexts using the
+// W3C Trace Context headers. Only TextMap carriers are supported.
+// See https://www.w3.org/TR/trace-context/
+type propagatorW3C struct{}
+
+func (p *propagatorW3C) Inject(spanCtx ddtrace.SpanContext, carrier interface{}) error {
+	switch c := carrier.(type) {
+	case TextMapWriter:
+		return p.injectTextMap(spanCtx, c)
+	default:
+		return ErrInvalidCarrier
+	}
+}
+
+func (*propagatorW3C) injectTextMap(spanCtx ddtrace.SpanContext, writer TextMapWriter) error {
+	ctx, ok := spanCtx.(*spanContext)
+	if !ok || ctx.traceID == 0 || ctx.spanID == 0 {
+		return ErrInvalidSpanContext
+	}
+	flags := "00"
+	if p, ok := ctx.samplingPriority(); ok && p >= ext.PriorityAutoKeep {
+		flags = "01"
+	}
+	writer.Set(traceparentHeader, fmt.Sprintf("00-%s-%016x-%s", ctx.TraceID128(), ctx.spanID, flags))
+	writer.Set(tracestateHeader, composeTracestate(ctx))
+	return nil
+}
+
+// composeTracestate returns the value of the tracestate header for ctx: the "dd" list
+// member holding the sampling priority, the origin and the propagating tags, followed
+// by the list members of other vendors received from the upstream service.
+func composeTracestate(ctx *spanContext) string {
+	var dd []string
+	if p, ok := ctx.samplingPriority(); ok {
+		dd = append(dd, "s:"+strconv.Itoa(p))
+	}
+	if ctx.origin != "" {
+		dd = append(dd, "o:"+sanitizeTracestateValue(ctx.origin))
+	}
+	var others string
+	if t := ctx.trace; t != nil {
+		t.mu.RLock()
+		keys := make([]string, 0, len(t.tags))
+		for k := range t.tags {
+			// the upper bits of the trace ID are propagated with the traceparent header
+			if strings.HasPrefix(k, propagatingTagPrefix) && k != keyTraceID128 {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v := t.tags[k]
+			if strings.ContainsAny(v, ",;~") {
+				log.Debug("did not inject trace tag %s in tracestate: invalid value %q", k, v)
+				continue
+			}
+			dd = append(dd, "t."+strings.TrimPrefix(k, propagatingTagPrefix)+":"+sanitizeTracestateValue(v))
+		}
+		others = t.tracestate
+		t.mu.RUnlock()
+	}
+	var sb strings.Builder
+	sb.WriteString("dd=")
+	sb.WriteString(strings.Join(dd, ";"))
+	if others != "" {
+		members := strings.Split(others, ",")
+		if len(members) > tracestateMaxMembers-1 {
+			members = members[:tracestateMaxMembers-1]
+		}
+		for _, m := range members {
+			sb.WriteByte(',')
+			sb.WriteString(m)
+		}
+	}
+	return sb.String()
+}
+
+// sanitizeTracestateValue replaces the characters of v which can't be part of the value
+// of the "dd" list member of the tracestate header.
+func sanitizeTracestateValue(v string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '=':
+			return '~'
+		case r < 0x20 || r > 0x7e || r == ',' || r == ';' || r == '~':
+			return '_'
+		}
+		return r
+	}, v)
+}
+
+func (p *propagatorW3C) Extract(carrier interface{}) (ddtrace.SpanContext, error) {
+	switch c := carrier.(type) {
+	case TextMapReader:
+		return p.extractTextMap(c)
+	default:
+		return nil, ErrInvalidCarrier
+	}
+}
+
+func (*propagatorW3C) extractTextMap(reader TextMapReader) (ddtrace.SpanContext, error) {
+	var traceparent, tracestate string
+	err := reader.ForeachKey(func(k, v string) error {
+		switch strings.ToLower(k) {
+		case traceparentHeader:
+			if traceparent != "" {
+				// multiple traceparent headers are ambiguous
+				return ErrSpanContextCorrupted
+			}
+			traceparent = v
+		case tracestateHeader:
+			if tracestate != "" {
+				tracestate += ","
+			}
+			tracestate += v
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if traceparent == "" {
+		return nil, ErrSpanContextNotFound
+	}
+	var ctx spanContext
+	upper, sampled, err := parseTraceparent(&ctx, traceparent)
+	if err != nil {
+		return nil, err
+	}
+	parseTracestate(&ctx, tracestate, sampled)
+	// the upper bits of the trace ID of the traceparent header take precedence over the
+	// trace tag of the tracestate header
+	if upper != 0 {
+		ctx.trace.setTag(keyTraceID128, fmt.Sprintf("%016x", upper))
+	} else {
+		delete(ctx.trace.tags, keyTraceID128)
+	}
+	return &ctx, nil
+}
+
+// parseTraceparent sets the trace and span IDs found in the traceparent header value v
+// on ctx. It returns the upper 64 bits of the 128-bit trace ID, the trace ID of ctx being
+// its lower 64 bits, and reports whether the sampled flag is set.
+func parseTraceparent(ctx *spanContext, v string) (upper uint64, sampled bool, err error) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return 0, false, ErrSpanContextCorrupted
+	}
+	version, err := strconv.ParseUint(parts[0], 16, 8)
+	if err != nil || version == 0xff || (version == 0 && len(parts) != 4) {
+		return 0, false, ErrSpanContextCorrupted
+	}
+	upper, err = strconv.ParseUint(parts[1][:16], 16, 64)
+	if err != nil {
+		return 0, false, ErrSpanContextCorrupted
+	}
+	ctx.traceID, err = strconv.ParseUint(parts[1][16:], 16, 64)
+	if err != nil || ctx.traceID == 0 {
+		return 0, false, ErrSpanContextCorrupted
+	}
+	ctx.spanID, err = strconv.ParseUint(parts[2], 16, 64)
+	if err != nil || ctx.spanID == 0 {
+		return 0, false, ErrSpanContextCorrupted
+	}
+	flags, err := strconv.ParseUint(parts[3], 16, 8)
+	if err != nil {
+		return 0, false, ErrSpanContextCorrupted
+	}
+	return upper, flags&0x1 == 1, nil
+}
+
+// parseTracestate sets the sampling priority, the origin and the propagating tags found
+// in the "dd" list member of the tracestate header value v on ctx, and keeps the list
+// members of other vendors so that they are propagated downstream. The sampling
+// priority is only kept if it agrees with sampled, the flag of the traceparent header.
+func parseTracestate(ctx *spanContext, v string, sampled bool) {
+	priority := ext.PriorityAutoReject
+	if sampled {
+		priority = ext.PriorityAutoKeep
+	}
+	tags := make(map[string]string)
+	var others []string
+	for _, member := range strings.Split(v, ",") {
+		member = strings.TrimSpace(member)
+		if member == "" {
+			continue
+		}
+		if !strings.HasPrefix(member, "dd=") {
+			others = append(others, member)
+			continue
+		}
+		for _, field := range strings.Split(member[len("dd="):], ";") {
+			i := strings.IndexByte(field, ':')
+			if i < 0 {
+				continue
+			}
+			key, val := field[:i], field[i+1:]
+			switch {
+			case key == "s":
+				p, err := strconv.Atoi(val)
+				if err == nil && (p > 0) == sampled {
+					priority = p
+				}
+			case key == "o":
+				ctx.origin = strings.ReplaceAll(val, "~", "=")
+			case strings.HasPrefix(key, "t."):
+				tags[propagatingTagPrefix+key[len("t."):]] = strings.ReplaceAll(val, "~", "=")
+			}
+		}
+	}
+	ctx.trace = newTrace()
+	ctx.trace.tracestate = strings.Join(others, ",")
+	for k, v := range tags {
+		ctx.trace.setTag(k, v)
+	}
+	ctx.trace.upstreamServices = ctx.trace.tags[keyUpstreamServices]
+	// setting a priority of a dropped trace also removes its decision maker
+	ctx.setSamplingPriority("", priority, samplernames.Upstream, math.NaN())
+}
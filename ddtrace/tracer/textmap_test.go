@@ -8,6 +8,7 @@ package tracer
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"os"
 	"strconv"
@@ -16,8 +17,10 @@ import (
 
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
 	"github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
+	"github.com/codebrick-corp/dd-trace-go/internal/samplernames"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHTTPHeadersCarrierSet(t *testing.T) {
@@ -181,6 +184,144 @@ func TestTextMapPropagatorOrigin(t *testing.T) {
 	}
 }
 
+func TestTextMapPropagatorDebug(t *testing.T) {
+	for name, src := range map[string]TextMapCarrier{
+		"header": {
+			traceDebugHeader:      "1",
+			DefaultPriorityHeader: "0",
+			DefaultTraceIDHeader:  "1",
+			DefaultParentIDHeader: "1",
+		},
+		"tag": {
+			traceTagsHeader:       "_dd.p.debug=1",
+			DefaultPriorityHeader: "-1",
+			DefaultTraceIDHeader:  "1",
+			DefaultParentIDHeader: "1",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			tracer := newTracer(WithTraceDebug(true))
+			defer tracer.Stop()
+			sctx, err := tracer.Extract(src)
+			require.NoError(t, err)
+			p, ok := sctx.(*spanContext).samplingPriority()
+			assert.True(t, ok)
+			assert.Equal(t, ext.PriorityUserKeep, p)
+
+			child := tracer.StartSpan("test", ChildOf(sctx)).(*span)
+			dst := map[string]string{}
+			err = tracer.Inject(child.Context(), TextMapCarrier(dst))
+			require.NoError(t, err)
+			assert.Equal(t, "1", dst[traceDebugHeader])
+			assert.Equal(t, "2", dst[DefaultPriorityHeader])
+			assert.Equal(t, "_dd.p.debug=1,_dd.p.dm=-4", dst[traceTagsHeader])
+		})
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		// the flags of untrusted clients are discarded by default
+		src := TextMapCarrier(map[string]string{
+			traceDebugHeader:      "1",
+			traceTagsHeader:       "_dd.p.debug=1,_dd.p.team=a",
+			DefaultPriorityHeader: "0",
+			DefaultTraceIDHeader:  "1",
+			DefaultParentIDHeader: "1",
+		})
+		tracer := newTracer()
+		defer tracer.Stop()
+		sctx, err := tracer.Extract(src)
+		require.NoError(t, err)
+		p, _ := sctx.(*spanContext).samplingPriority()
+		assert.Equal(t, ext.PriorityAutoReject, p)
+		dst := map[string]string{}
+		err = tracer.Inject(sctx, TextMapCarrier(dst))
+		require.NoError(t, err)
+		assert.NotContains(t, dst, traceDebugHeader)
+		assert.Equal(t, "_dd.p.team=a", dst[traceTagsHeader])
+	})
+
+	t.Run("env", func(t *testing.T) {
+		os.Setenv("DD_TRACE_PROPAGATION_DEBUG_ENABLED", "true")
+		defer os.Unsetenv("DD_TRACE_PROPAGATION_DEBUG_ENABLED")
+		tracer := newTracer()
+		defer tracer.Stop()
+		assert.True(t, tracer.config.traceDebug)
+	})
+
+	t.Run("off", func(t *testing.T) {
+		src := TextMapCarrier(map[string]string{
+			traceDebugHeader:      "false",
+			DefaultPriorityHeader: "0",
+			DefaultTraceIDHeader:  "1",
+			DefaultParentIDHeader: "1",
+		})
+		tracer := newTracer(WithTraceDebug(true))
+		defer tracer.Stop()
+		sctx, err := tracer.Extract(src)
+		require.NoError(t, err)
+		p, _ := sctx.(*spanContext).samplingPriority()
+		assert.Equal(t, ext.PriorityAutoReject, p)
+		dst := map[string]string{}
+		err = tracer.Inject(sctx, TextMapCarrier(dst))
+		require.NoError(t, err)
+		assert.NotContains(t, dst, traceDebugHeader)
+	})
+}
+
+func TestTextMapPropagatorSamplingPriority(t *testing.T) {
+	extract := func(t *testing.T, tracer *tracer, headers map[string]string) *spanContext {
+		headers[DefaultTraceIDHeader] = "1"
+		headers[DefaultParentIDHeader] = "1"
+		sctx, err := tracer.Extract(TextMapCarrier(headers))
+		require.NoError(t, err)
+		return sctx.(*spanContext)
+	}
+
+	t.Run("clamped", func(t *testing.T) {
+		tracer := newTracer()
+		defer tracer.Stop()
+		for in, want := range map[string]int{
+			"-5": ext.PriorityUserReject,
+			"-1": ext.PriorityUserReject,
+			"0":  ext.PriorityAutoReject,
+			"2":  ext.PriorityUserKeep,
+			"42": ext.PriorityUserKeep,
+		} {
+			p, ok := extract(t, tracer, map[string]string{DefaultPriorityHeader: in}).samplingPriority()
+			assert.True(t, ok, in)
+			assert.Equal(t, want, p, in)
+		}
+	})
+
+	t.Run("ignored", func(t *testing.T) {
+		tracer := newTracer(WithUpstreamPriorityIgnored(true))
+		defer tracer.Stop()
+		sctx := extract(t, tracer, map[string]string{
+			DefaultPriorityHeader: "2",
+			traceDebugHeader:      "1",
+			traceTagsHeader:       "_dd.p.dm=-4,_dd.p.team=a",
+		})
+		_, ok := sctx.samplingPriority()
+		assert.False(t, ok)
+		assert.Equal(t, map[string]string{"_dd.p.team": "a"}, sctx.trace.tags)
+
+		// the priority is decided locally
+		child := tracer.StartSpan("test", ChildOf(sctx)).(*span)
+		p, ok := child.context.samplingPriority()
+		assert.True(t, ok)
+		assert.Equal(t, ext.PriorityAutoKeep, p)
+	})
+
+	t.Run("ignored/env", func(t *testing.T) {
+		os.Setenv("DD_TRACE_IGNORE_UPSTREAM_PRIORITY", "true")
+		defer os.Unsetenv("DD_TRACE_IGNORE_UPSTREAM_PRIORITY")
+		tracer := newTracer()
+		defer tracer.Stop()
+		_, ok := extract(t, tracer, map[string]string{DefaultPriorityHeader: "1"}).samplingPriority()
+		assert.False(t, ok)
+	})
+}
+
 func TestExtractOriginSynthetics(t *testing.T) {
 	src := TextMapCarrier(map[string]string{
 		originHeader:          "synthetics",
@@ -212,7 +353,7 @@ func TestTextMapPropagatorInvalidTraceTagsHeader(t *testing.T) {
 	assert.Nil(t, err)
 	sctx, ok := ctx.(*spanContext)
 	assert.True(t, ok)
-	assert.Equal(t, map[string]string(nil), sctx.trace.tags)
+	assert.Equal(t, map[string]string{keyPropagationError: "decoding_error"}, sctx.trace.tags)
 }
 
 func TestTextMapPropagatorTraceTagsTooLong(t *testing.T) {
@@ -234,7 +375,7 @@ func TestTextMapPropagatorTraceTagsTooLong(t *testing.T) {
 	assert.True(t, ok)
 	child := tracer.StartSpan("test", ChildOf(sctx))
 	childSpanID := child.Context().(*spanContext).spanID
-	assert.Equal(t, 100, len(sctx.trace.tags))
+	assert.Equal(t, map[string]string{keyPropagationError: "extract_max_size"}, sctx.trace.tags)
 	dst := map[string]string{}
 	err = tracer.Inject(child.Context(), TextMapCarrier(dst))
 	assert.Nil(t, err)
@@ -245,6 +386,127 @@ func TestTextMapPropagatorTraceTagsTooLong(t *testing.T) {
 	}, dst)
 }
 
+func TestTextMapPropagatorTraceTags(t *testing.T) {
+	t.Run("propagated", func(t *testing.T) {
+		src := TextMapCarrier(map[string]string{
+			DefaultPriorityHeader: "2",
+			DefaultTraceIDHeader:  "1",
+			DefaultParentIDHeader: "1",
+			traceTagsHeader:       "_dd.p.dm=-4,_dd.p.upstream_services=abc|2|4|,other=ignored",
+		})
+		tracer := newTracer()
+		defer tracer.Stop()
+		sctx, err := tracer.Extract(src)
+		require.NoError(t, err)
+		child := tracer.StartSpan("test", ChildOf(sctx)).(*span)
+		// extracted decision makers are preserved by downstream decisions
+		child.context.setSamplingPriority("svc", ext.PriorityAutoKeep, samplernames.AgentRate, 1)
+		dst := map[string]string{}
+		err = tracer.Inject(child.Context(), TextMapCarrier(dst))
+		require.NoError(t, err)
+		assert.Equal(t, "_dd.p.dm=-4,_dd.p.upstream_services=abc|2|4|;c3Zj|1|1|1.0000", dst[traceTagsHeader])
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		src := TextMapCarrier(map[string]string{
+			DefaultPriorityHeader: "0",
+			DefaultTraceIDHeader:  "1",
+			DefaultParentIDHeader: "1",
+			traceTagsHeader:       "_dd.p.dm=-4",
+		})
+		tracer := newTracer()
+		defer tracer.Stop()
+		sctx, err := tracer.Extract(src)
+		require.NoError(t, err)
+		_, ok := sctx.(*spanContext).trace.tags[keyDecisionMaker]
+		assert.False(t, ok)
+	})
+
+	t.Run("any-order", func(t *testing.T) {
+		for _, tt := range []struct {
+			priority string
+			keep     bool
+		}{
+			{"0", false},
+			{"-1", false},
+			{"1", true},
+			{"2", true},
+		} {
+			headers := [][2]string{
+				{DefaultPriorityHeader, tt.priority},
+				{DefaultTraceIDHeader, "1"},
+				{DefaultParentIDHeader, "1"},
+				{traceTagsHeader, "_dd.p.dm=-4"},
+			}
+			permute(headers, func(headers [][2]string) {
+				sctx, err := NewPropagator(nil).Extract(orderedCarrier(headers))
+				require.NoError(t, err)
+				_, ok := sctx.(*spanContext).trace.tags[keyDecisionMaker]
+				assert.Equal(t, tt.keep, ok, "%v", headers)
+			})
+		}
+	})
+
+	t.Run("inject_max_size", func(t *testing.T) {
+		tracer := newTracer(WithPropagator(NewPropagator(&PropagatorConfig{MaxTagsHeaderLen: 10})))
+		defer tracer.Stop()
+		root := tracer.StartSpan("web.request", Tag(ext.SamplingPriority, ext.PriorityUserKeep)).(*span)
+		dst := map[string]string{}
+		err := tracer.Inject(root.Context(), TextMapCarrier(dst))
+		require.NoError(t, err)
+		assert.NotContains(t, dst, traceTagsHeader)
+		root.Finish()
+		assert.Equal(t, "inject_max_size", root.Meta[keyPropagationError])
+	})
+
+	t.Run("default_max_size", func(t *testing.T) {
+		tracer := newTracer(WithPropagator(NewPropagator(nil)))
+		defer tracer.Stop()
+		root := tracer.StartSpan("web.request", Tag(ext.SamplingPriority, ext.PriorityUserKeep)).(*span)
+		dst := map[string]string{}
+		err := tracer.Inject(root.Context(), TextMapCarrier(dst))
+		require.NoError(t, err)
+		assert.Contains(t, dst, traceTagsHeader)
+		assert.NotContains(t, root.context.trace.tags, keyPropagationError)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		tracer := newTracer(WithPropagator(NewPropagator(&PropagatorConfig{MaxTagsHeaderLen: -1})))
+		defer tracer.Stop()
+		root := tracer.StartSpan("web.request", Tag(ext.SamplingPriority, ext.PriorityUserKeep)).(*span)
+		dst := map[string]string{}
+		err := tracer.Inject(root.Context(), TextMapCarrier(dst))
+		require.NoError(t, err)
+		assert.NotContains(t, dst, traceTagsHeader)
+		assert.Equal(t, "disabled", root.context.trace.tags[keyPropagationError])
+	})
+
+	t.Run("disabled_env", func(t *testing.T) {
+		os.Setenv("DD_TRACE_TAGS_PROPAGATION_MAX_LENGTH", "0")
+		defer os.Unsetenv("DD_TRACE_TAGS_PROPAGATION_MAX_LENGTH")
+		tracer := newTracer()
+		defer tracer.Stop()
+		root := tracer.StartSpan("web.request", Tag(ext.SamplingPriority, ext.PriorityUserKeep)).(*span)
+		dst := map[string]string{}
+		err := tracer.Inject(root.Context(), TextMapCarrier(dst))
+		require.NoError(t, err)
+		assert.NotContains(t, dst, traceTagsHeader)
+		assert.Equal(t, "disabled", root.context.trace.tags[keyPropagationError])
+	})
+
+	t.Run("encoding_error", func(t *testing.T) {
+		tracer := newTracer()
+		defer tracer.Stop()
+		root := tracer.StartSpan("web.request", Tag(ext.SamplingPriority, ext.PriorityUserKeep)).(*span)
+		root.context.trace.setTag("_dd.p.invalid", "a,b")
+		dst := map[string]string{}
+		err := tracer.Inject(root.Context(), TextMapCarrier(dst))
+		require.NoError(t, err)
+		assert.NotContains(t, dst, traceTagsHeader)
+		assert.Equal(t, "encoding_error", root.context.trace.tags[keyPropagationError])
+	})
+}
+
 func TestTextMapPropagatorInjectExtract(t *testing.T) {
 	propagator := NewPropagator(&PropagatorConfig{
 		BaggagePrefix: "bg-",
@@ -467,6 +729,354 @@ func TestB3(t *testing.T) {
 	})
 }
 
+func TestExtractFallback(t *testing.T) {
+	headers := TextMapCarrier{
+		"Traceparent":  "00-00000000000000000000000000000001-0000000000000002-01",
+		"X-B3-Traceid": "0000000000000003",
+		"X-B3-Spanid":  "0000000000000004",
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		Start(withTransport(newDummyTransport()), withNoopStats())
+		defer Stop()
+		_, err := ExtractFallback(headers)
+		assert.Equal(t, ErrSpanContextNotFound, err)
+	})
+
+	t.Run("not-started", func(t *testing.T) {
+		os.Setenv(headerTracePropagationStyleExtractFallback, "tracecontext")
+		defer os.Unsetenv(headerTracePropagationStyleExtractFallback)
+		_, err := ExtractFallback(headers)
+		assert.Equal(t, ErrSpanContextNotFound, err)
+	})
+
+	t.Run("env", func(t *testing.T) {
+		os.Setenv(headerTracePropagationStyleExtractFallback, "tracecontext,b3")
+		defer os.Unsetenv(headerTracePropagationStyleExtractFallback)
+		Start(withTransport(newDummyTransport()), withNoopStats())
+		defer Stop()
+		_, err := Extract(headers)
+		assert.Equal(t, ErrSpanContextNotFound, err)
+		sctx, err := ExtractFallback(headers)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), sctx.TraceID())
+		assert.Equal(t, uint64(2), sctx.SpanID())
+		p, ok := sctx.(*spanContext).samplingPriority()
+		assert.True(t, ok)
+		assert.Equal(t, ext.PriorityAutoKeep, p)
+
+		sctx, err = ExtractFallback(TextMapCarrier{"X-B3-Traceid": "3", "X-B3-Spanid": "4"})
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), sctx.TraceID())
+		assert.Equal(t, uint64(4), sctx.SpanID())
+
+		_, err = ExtractFallback(TextMapCarrier{})
+		assert.Equal(t, ErrSpanContextNotFound, err)
+	})
+
+	t.Run("order", func(t *testing.T) {
+		os.Setenv(headerTracePropagationStyleExtractFallback, "b3,tracecontext")
+		defer os.Unsetenv(headerTracePropagationStyleExtractFallback)
+		Start(withTransport(newDummyTransport()), withNoopStats())
+		defer Stop()
+		sctx, err := ExtractFallback(headers)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), sctx.TraceID())
+	})
+
+	t.Run("config", func(t *testing.T) {
+		p := NewPropagator(&PropagatorConfig{ExtractFallbackStyles: []string{"b3"}}).(*chainedPropagator)
+		sctx, err := p.extractFallback(headers)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), sctx.TraceID())
+		_, err = p.Extract(headers)
+		assert.Equal(t, ErrSpanContextNotFound, err)
+
+		p = NewPropagator(&PropagatorConfig{ExtractStyles: []string{"none"}, ExtractFallbackStyles: []string{"b3"}}).(*chainedPropagator)
+		_, err = p.extractFallback(headers)
+		assert.Equal(t, ErrSpanContextNotFound, err)
+	})
+
+	t.Run("extract-none", func(t *testing.T) {
+		os.Setenv(headerTracePropagationStyleExtractFallback, "tracecontext")
+		defer os.Unsetenv(headerTracePropagationStyleExtractFallback)
+		os.Setenv(headerTracePropagationStyleExtract, "none")
+		defer os.Unsetenv(headerTracePropagationStyleExtract)
+		Start(withTransport(newDummyTransport()), withNoopStats())
+		defer Stop()
+		_, err := ExtractFallback(headers)
+		assert.Equal(t, ErrSpanContextNotFound, err)
+	})
+
+	t.Run("custom-propagator", func(t *testing.T) {
+		os.Setenv(headerTracePropagationStyleExtractFallback, "tracecontext")
+		defer os.Unsetenv(headerTracePropagationStyleExtractFallback)
+		Start(withTransport(newDummyTransport()), withNoopStats(),
+			WithPropagator(NewPropagator(&PropagatorConfig{ExtractFallbackStyles: []string{"b3"}})))
+		defer Stop()
+		_, err := ExtractFallback(headers)
+		assert.Equal(t, ErrSpanContextNotFound, err)
+	})
+
+	t.Run("upstream-priority-ignored", func(t *testing.T) {
+		os.Setenv(headerTracePropagationStyleExtractFallback, "tracecontext")
+		defer os.Unsetenv(headerTracePropagationStyleExtractFallback)
+		Start(withTransport(newDummyTransport()), withNoopStats(), WithUpstreamPriorityIgnored(true))
+		defer Stop()
+		sctx, err := ExtractFallback(headers)
+		require.NoError(t, err)
+		_, ok := sctx.(*spanContext).samplingPriority()
+		assert.False(t, ok)
+	})
+}
+
+func TestW3C(t *testing.T) {
+	t.Run("inject", func(t *testing.T) {
+		p := NewPropagator(&PropagatorConfig{InjectStyles: []string{"tracecontext"}})
+		ctx := &spanContext{traceID: 1412508178991881, spanID: 1842642739201064, origin: "synthetics"}
+		ctx.setSamplingPriority("", ext.PriorityUserKeep, samplernames.Manual, math.NaN())
+		ctx.trace.tracestate = "congo=t61rcWkgMzE"
+
+		headers := TextMapCarrier{}
+		require.NoError(t, p.Inject(ctx, headers))
+		assert.Equal(t, "00-0000000000000000000504ab30404b09-00068bdfb1eb0428-01", headers[traceparentHeader])
+		assert.Equal(t, "dd=s:2;o:synthetics;t.dm:-4;t.upstream_services:|2|4|,congo=t61rcWkgMzE", headers[tracestateHeader])
+		assert.NotContains(t, headers, DefaultTraceIDHeader)
+	})
+
+	t.Run("extract", func(t *testing.T) {
+		p := NewPropagator(&PropagatorConfig{ExtractStyles: []string{"tracecontext"}})
+		sctx, err := p.Extract(TextMapCarrier{
+			"Traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"Tracestate":  "dd=s:2;o:rum;t.dm:-4;t.usr.id:baz~~,congo=t61rcWkgMzE",
+		})
+		require.NoError(t, err)
+		ctx := sctx.(*spanContext)
+		assert.Equal(t, uint64(0xa3ce929d0e0e4736), ctx.traceID)
+		assert.Equal(t, uint64(0x00f067aa0ba902b7), ctx.spanID)
+		assert.Equal(t, "rum", ctx.origin)
+		p2, ok := ctx.samplingPriority()
+		assert.True(t, ok)
+		assert.Equal(t, ext.PriorityUserKeep, p2)
+		assert.Equal(t, "-4", ctx.trace.tags[keyDecisionMaker])
+		assert.Equal(t, "baz==", ctx.trace.tags["_dd.p.usr.id"])
+		assert.Equal(t, "congo=t61rcWkgMzE", ctx.trace.tracestate)
+		assert.Equal(t, "4bf92f3577b34da6", ctx.trace.tags[keyTraceID128])
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", ctx.TraceID128())
+	})
+
+	t.Run("extract-priority-mismatch", func(t *testing.T) {
+		p := NewPropagator(&PropagatorConfig{ExtractStyles: []string{"tracecontext"}})
+		sctx, err := p.Extract(TextMapCarrier{
+			traceparentHeader: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00",
+			tracestateHeader:  "dd=s:2;t.dm:-4",
+		})
+		require.NoError(t, err)
+		ctx := sctx.(*spanContext)
+		p2, ok := ctx.samplingPriority()
+		assert.True(t, ok)
+		assert.Equal(t, ext.PriorityAutoReject, p2)
+		assert.NotContains(t, ctx.trace.tags, keyDecisionMaker)
+	})
+
+	t.Run("extract-invalid", func(t *testing.T) {
+		p := NewPropagator(&PropagatorConfig{ExtractStyles: []string{"tracecontext"}})
+		for _, v := range []string{
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01-extra",
+			"ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+			"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01",
+			"00-4bf92f3577b34da6a3ce929d0e0e473x-00f067aa0ba902b7-01",
+		} {
+			_, err := p.Extract(TextMapCarrier{traceparentHeader: v})
+			assert.Equal(t, ErrSpanContextCorrupted, err, v)
+		}
+		_, err := p.Extract(TextMapCarrier{DefaultTraceIDHeader: "1", DefaultParentIDHeader: "1"})
+		assert.Equal(t, ErrSpanContextNotFound, err)
+	})
+
+	t.Run("inject-extract", func(t *testing.T) {
+		p := NewPropagator(&PropagatorConfig{
+			InjectStyles:  []string{"tracecontext"},
+			ExtractStyles: []string{"tracecontext"},
+		})
+		ctx := &spanContext{traceID: 1, spanID: 2}
+		ctx.setSamplingPriority("", ext.PriorityAutoReject, samplernames.AgentRate, 0)
+		headers := TextMapCarrier{}
+		require.NoError(t, p.Inject(ctx, headers))
+		sctx, err := p.Extract(headers)
+		require.NoError(t, err)
+		xctx := sctx.(*spanContext)
+		assert.Equal(t, uint64(1), xctx.traceID)
+		assert.Equal(t, uint64(2), xctx.spanID)
+		p2, _ := xctx.samplingPriority()
+		assert.Equal(t, ext.PriorityAutoReject, p2)
+	})
+
+	t.Run("inject-extract-128-bit", func(t *testing.T) {
+		const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+		tracer := newTracer(withTransport(newDummyTransport()), withNoopStats())
+		defer tracer.Stop()
+		for _, styles := range []string{"tracecontext", "datadog"} {
+			p := NewPropagator(&PropagatorConfig{
+				InjectStyles:  []string{styles},
+				ExtractStyles: []string{styles},
+			})
+			w3c := NewPropagator(&PropagatorConfig{ExtractStyles: []string{"tracecontext"}})
+			sctx, err := w3c.Extract(TextMapCarrier{traceparentHeader: traceparent})
+			require.NoError(t, err)
+
+			// the upper bits are propagated downstream by the child spans
+			child := tracer.StartSpan("child", ChildOf(sctx))
+			headers := TextMapCarrier{}
+			require.NoError(t, p.Inject(child.Context(), headers), styles)
+			if styles == "tracecontext" {
+				assert.Equal(t, fmt.Sprintf("00-4bf92f3577b34da6a3ce929d0e0e4736-%016x-01", child.Context().SpanID()), headers[traceparentHeader])
+				assert.NotContains(t, headers[tracestateHeader], "t.tid")
+			}
+			sctx, err = p.Extract(headers)
+			require.NoError(t, err, styles)
+			assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", sctx.(*spanContext).TraceID128(), styles)
+		}
+	})
+
+	t.Run("extract-tracestate-tid", func(t *testing.T) {
+		// the upper bits of the traceparent header take precedence
+		p := NewPropagator(&PropagatorConfig{ExtractStyles: []string{"tracecontext"}})
+		sctx, err := p.Extract(TextMapCarrier{
+			traceparentHeader: "00-00000000000000000000000000000001-0000000000000002-01",
+			tracestateHeader:  "dd=s:1;t.tid:4bf92f3577b34da6",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "00000000000000000000000000000001", sctx.(*spanContext).TraceID128())
+	})
+}
+
+func TestPropagationStyles(t *testing.T) {
+	ddHeaders := TextMapCarrier{
+		DefaultTraceIDHeader:  "1",
+		DefaultParentIDHeader: "1",
+		DefaultPriorityHeader: "1",
+	}
+	w3cHeaders := TextMapCarrier{
+		traceparentHeader: "00-00000000000000000000000000000002-0000000000000002-01",
+	}
+	bothHeaders := TextMapCarrier{}
+	for k, v := range ddHeaders {
+		bothHeaders[k] = v
+	}
+	for k, v := range w3cHeaders {
+		bothHeaders[k] = v
+	}
+
+	t.Run("inject-both", func(t *testing.T) {
+		os.Setenv("DD_TRACE_PROPAGATION_STYLE_INJECT", "datadog,tracecontext")
+		defer os.Unsetenv("DD_TRACE_PROPAGATION_STYLE_INJECT")
+		tracer := newTracer()
+		defer tracer.Stop()
+
+		root := tracer.StartSpan("web.request").(*span)
+		root.SetTag(ext.SamplingPriority, ext.PriorityUserKeep)
+		headers := TextMapCarrier{}
+		require.NoError(t, tracer.Inject(root.Context(), headers))
+		assert.Equal(t, strconv.FormatUint(root.TraceID, 10), headers[DefaultTraceIDHeader])
+		assert.Equal(t, fmt.Sprintf("00-%032x-%016x-01", root.TraceID, root.SpanID), headers[traceparentHeader])
+		assert.True(t, strings.HasPrefix(headers[tracestateHeader], "dd=s:2;"), headers[tracestateHeader])
+	})
+
+	t.Run("extract-order", func(t *testing.T) {
+		for styles, traceID := range map[string]uint64{
+			"datadog,tracecontext": 1,
+			"tracecontext,datadog": 2,
+			"tracecontext":         2,
+		} {
+			p := NewPropagator(&PropagatorConfig{ExtractStyles: strings.Split(styles, ",")})
+			sctx, err := p.Extract(bothHeaders)
+			require.NoError(t, err, styles)
+			assert.Equal(t, traceID, sctx.(*spanContext).traceID, styles)
+		}
+		// falls back to the next style
+		p := NewPropagator(&PropagatorConfig{ExtractStyles: []string{"tracecontext", "datadog"}})
+		sctx, err := p.Extract(ddHeaders)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), sctx.(*spanContext).traceID)
+	})
+
+	t.Run("env-precedence", func(t *testing.T) {
+		os.Setenv("DD_TRACE_PROPAGATION_STYLE", "tracecontext")
+		defer os.Unsetenv("DD_TRACE_PROPAGATION_STYLE")
+		p := NewPropagator(nil)
+		sctx, err := p.Extract(bothHeaders)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), sctx.(*spanContext).traceID)
+
+		os.Setenv("DD_PROPAGATION_STYLE_EXTRACT", "datadog")
+		defer os.Unsetenv("DD_PROPAGATION_STYLE_EXTRACT")
+		p = NewPropagator(nil)
+		sctx, err = p.Extract(bothHeaders)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), sctx.(*spanContext).traceID)
+
+		os.Setenv("DD_TRACE_PROPAGATION_STYLE_EXTRACT", "tracecontext")
+		defer os.Unsetenv("DD_TRACE_PROPAGATION_STYLE_EXTRACT")
+		p = NewPropagator(nil)
+		sctx, err = p.Extract(bothHeaders)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), sctx.(*spanContext).traceID)
+	})
+
+	t.Run("none", func(t *testing.T) {
+		p := NewPropagator(&PropagatorConfig{InjectStyles: []string{"none"}, ExtractStyles: []string{"none"}})
+		headers := TextMapCarrier{}
+		require.NoError(t, p.Inject(&spanContext{traceID: 1, spanID: 1}, headers))
+		assert.Empty(t, headers)
+		_, err := p.Extract(bothHeaders)
+		assert.Equal(t, ErrSpanContextNotFound, err)
+	})
+
+	t.Run("aliases", func(t *testing.T) {
+		p := NewPropagator(&PropagatorConfig{InjectStyles: []string{" B3multi", "b3", "TraceContext "}})
+		headers := TextMapCarrier{}
+		require.NoError(t, p.Inject(&spanContext{traceID: 1, spanID: 1}, headers))
+		assert.Equal(t, "0000000000000001", headers[b3TraceIDHeader])
+		assert.Contains(t, headers, traceparentHeader)
+		assert.Len(t, p.(*chainedPropagator).injectors, 2)
+	})
+}
+
 func assertTraceTags(t *testing.T, expected, actual string) {
 	assert.ElementsMatch(t, strings.Split(expected, ","), strings.Split(actual, ","))
 }
+
+// orderedCarrier is a TextMapReader reading its headers in order.
+type orderedCarrier [][2]string
+
+func (c orderedCarrier) ForeachKey(handler func(key, val string) error) error {
+	for _, h := range c {
+		if err := handler(h[0], h[1]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// permute calls fn with every permutation of headers, which it reorders in place.
+func permute(headers [][2]string, fn func([][2]string)) {
+	var rec func(n int)
+	rec = func(n int) {
+		if n <= 1 {
+			fn(headers)
+			return
+		}
+		for i := 0; i < n; i++ {
+			rec(n - 1)
+			j := 0
+			if n%2 == 0 {
+				j = i
+			}
+			headers[j], headers[n-1] = headers[n-1], headers[j]
+		}
+	}
+	rec(len(headers))
+}
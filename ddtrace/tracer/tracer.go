@@ -12,6 +12,7 @@ import (
 	rt "runtime/trace"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/codebrick-corp/dd-trace-go/ddtrace"
@@ -48,6 +49,10 @@ type tracer struct {
 	// out receives traces to be added to the payload.
 	out chan []*span
 
+	// exported receives the traces to be passed to the configured TraceExporters.
+	// It is nil when there are none.
+	exported chan []*span
+
 	// flush receives a channel onto which it will confirm after a flush has been
 	// triggered and completed.
 	flush chan chan<- struct{}
@@ -58,6 +63,9 @@ type tracer struct {
 	// stopOnce ensures the tracer is stopped exactly once.
 	stopOnce sync.Once
 
+	// stopped is closed once the tracer is stopped.
+	stopped chan struct{}
+
 	// wg waits for all goroutines to exit when stopping.
 	wg sync.WaitGroup
 
@@ -79,9 +87,29 @@ type tracer struct {
 	// or operation name.
 	rulesSampling *rulesSampler
 
+	// openSpans keeps track of unfinished spans. It is nil unless long running span
+	// metrics or span leak detection are enabled.
+	openSpans *openSpans
+
 	// obfuscator holds the obfuscator used to obfuscate resources in aggregated stats.
 	// obfuscator may be nil if disabled.
 	obfuscator *obfuscate.Obfuscator
+
+	// debug records the statistics reported by DebugHandler.
+	debug debugStats
+
+	// audit rate-limits the warnings logged by the propagation audit.
+	audit propagationAudit
+
+	// tagsAudit rate-limits the warnings logged for spans missing required tags.
+	tagsAudit propagationAudit
+
+	// components counts the spans created by each integration.
+	components componentSpans
+
+	// overhead measures the overhead of the tracer against its CPU budget. It is nil
+	// unless a budget is configured.
+	overhead *cpuBudget
 }
 
 const (
@@ -129,6 +157,22 @@ func Stop() {
 	log.Flush()
 }
 
+// StopContext stops the started tracer like Stop, flushing the buffered traces and stopping
+// its background workers, but returns ctx.Err() if ctx is done before the tracer is
+// stopped, in which case the tracer keeps shutting down in the background and the traces
+// not sent yet may be lost. Subsequent calls are valid but become no-op.
+func StopContext(ctx gocontext.Context) error {
+	old := internal.SwapGlobalTracer(&internal.NoopTracer{})
+	defer log.Flush()
+	if t, ok := old.(*tracer); ok {
+		return t.stopContext(ctx)
+	}
+	if !internal.Testing {
+		old.Stop()
+	}
+	return nil
+}
+
 // Span is an alias for ddtrace.Span. It is here to allow godoc to group methods returning
 // ddtrace.Span. It is recommended and is considered more correct to refer to this type as
 // ddtrace.Span instead.
@@ -147,6 +191,26 @@ func Extract(carrier interface{}) (ddtrace.SpanContext, error) {
 	return internal.GetGlobalTracer().Extract(carrier)
 }
 
+// ExtractFallback extracts a SpanContext from the carrier with the propagation styles
+// configured with DD_TRACE_PROPAGATION_STYLE_EXTRACT_FALLBACK, e.g. "tracecontext,b3", in
+// order of preference. It is meant for the carriers from which Extract can't extract any,
+// e.g. the requests of services instrumented with OpenTelemetry when the tracer only
+// extracts Datadog headers. The extracted sampling priorities are checked as configured
+// for the global tracer. ErrSpanContextNotFound is returned when no fallback styles are
+// configured, when the extraction is disabled, when the tracer was started with a custom
+// propagator, see WithPropagator, or when it isn't started.
+func ExtractFallback(carrier interface{}) (ddtrace.SpanContext, error) {
+	t, ok := internal.GetGlobalTracer().(*tracer)
+	if !ok {
+		return nil, ErrSpanContextNotFound
+	}
+	p, ok := t.config.propagator.(*chainedPropagator)
+	if !ok {
+		return nil, ErrSpanContextNotFound
+	}
+	return p.extractFallback(carrier)
+}
+
 // Inject injects the given SpanContext into the carrier. The carrier is
 // expected to implement TextMapWriter, otherwise an error is returned.
 // If the tracer is not started, calling this function is a no-op.
@@ -185,7 +249,9 @@ func newUnstartedTracer(opts ...StartOption) *tracer {
 	}
 	sampler := newPrioritySampler()
 	var writer traceWriter
-	if c.logToStdout {
+	if c.consoleExport {
+		writer = newConsoleTraceWriter()
+	} else if c.logToStdout {
 		writer = newLogTraceWriter(c)
 	} else {
 		writer = newAgentTraceWriter(c, sampler)
@@ -195,6 +261,7 @@ func newUnstartedTracer(opts ...StartOption) *tracer {
 		traceWriter:      writer,
 		out:              make(chan []*span, payloadQueueSize),
 		stop:             make(chan struct{}),
+		stopped:          make(chan struct{}),
 		flush:            make(chan chan<- struct{}),
 		rulesSampling:    newRulesSampler(c.samplingRules),
 		prioritySampling: sampler,
@@ -210,6 +277,13 @@ func newUnstartedTracer(opts ...StartOption) *tracer {
 			},
 		}),
 	}
+	if c.longRunningThreshold > 0 || c.spanLeakTimeout > 0 {
+		t.openSpans = newOpenSpans()
+	}
+	t.overhead = newCPUBudget(c.cpuOverheadBudget)
+	if len(c.traceExporters) > 0 {
+		t.exported = make(chan []*span, exportQueueSize)
+	}
 	return t
 }
 
@@ -241,6 +315,26 @@ func newTracer(opts ...StartOption) *tracer {
 		defer t.wg.Done()
 		t.reportHealthMetrics(statsInterval)
 	}()
+	if t.exported != nil {
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			t.exportWorker()
+		}()
+	}
+	if c.memory != nil {
+		t.wg.Add(1)
+		go func() {
+			defer t.wg.Done()
+			t.monitorMemory(memoryCheckInterval)
+		}()
+	}
+	if c.flushOnSIGTERM {
+		t.startFlushOnSIGTERM()
+	}
+	if c.crashTracking {
+		t.startCrashTracking()
+	}
 	t.stats.Start()
 	appsec.Start()
 	return t
@@ -262,6 +356,19 @@ func Flush() {
 	}
 }
 
+// SamplingRatesByService returns the sampling rates which the agent currently requires
+// the tracer to apply to the traces of each service and environment, keyed by
+// "service:<service>,env:<env>". The rate applied to the other services is keyed by
+// "service:,env:". The rates are updated from the responses of the agent to every
+// flush. Spans matching sampling rules are not affected by these rates. It returns
+// nil if the tracer is not started.
+func SamplingRatesByService() map[string]float64 {
+	if t, ok := internal.GetGlobalTracer().(*tracer); ok {
+		return t.prioritySampling.ratesByService()
+	}
+	return nil
+}
+
 // flushSync triggers a flush and waits for it to complete.
 func (t *tracer) flushSync() {
 	done := make(chan struct{})
@@ -275,14 +382,19 @@ func (t *tracer) worker(tick <-chan time.Time) {
 	for {
 		select {
 		case trace := <-t.out:
+			start := t.overhead.start()
 			t.traceWriter.add(trace)
+			t.overhead.stop(start)
 
 		case <-tick:
 			t.config.statsd.Incr("datadog.tracer.flush_triggered", []string{"reason:scheduled"}, 1)
+			start := t.overhead.start()
 			t.traceWriter.flush()
+			t.overhead.stop(start)
 
 		case done := <-t.flush:
 			t.config.statsd.Incr("datadog.tracer.flush_triggered", []string{"reason:invoked"}, 1)
+			t.drain()
 			t.traceWriter.flush()
 			// TODO(x): In reality, the traceWriter.flush() call is not synchronous
 			// when using the agent traceWriter. However, this functionnality is used
@@ -290,17 +402,21 @@ func (t *tracer) worker(tick <-chan time.Time) {
 			done <- struct{}{}
 
 		case <-t.stop:
-		loop:
-			// the loop ensures that the payload channel is fully drained
-			// before the final flush to ensure no traces are lost (see #526)
-			for {
-				select {
-				case trace := <-t.out:
-					t.traceWriter.add(trace)
-				default:
-					break loop
-				}
-			}
+			// the payload channel is fully drained before the final
+			// flush to ensure no traces are lost (see #526)
+			t.drain()
+			return
+		}
+	}
+}
+
+// drain adds the traces waiting in the payload channel to the trace writer.
+func (t *tracer) drain() {
+	for {
+		select {
+		case trace := <-t.out:
+			t.traceWriter.add(trace)
+		default:
 			return
 		}
 	}
@@ -315,6 +431,7 @@ func (t *tracer) pushTrace(trace []*span) {
 	select {
 	case t.out <- trace:
 	default:
+		atomic.AddUint64(&t.debug.tracesQueueFull, 1)
 		log.Error("payload queue full, dropping %d traces", len(trace))
 	}
 }
@@ -325,11 +442,19 @@ func (t *tracer) StartSpan(operationName string, options ...ddtrace.StartSpanOpt
 	for _, fn := range options {
 		fn(&opts)
 	}
-	var startTime int64
+	t.debug.recordOperation(operationName)
+	var (
+		startTime      int64
+		startMonotonic time.Time
+		corrected      string
+	)
 	if opts.StartTime.IsZero() {
 		startTime = now()
+		if t.config.clockCorrection {
+			startMonotonic = time.Now()
+		}
 	} else {
-		startTime = opts.StartTime.UnixNano()
+		startTime, corrected = checkStartTime(opts.StartTime)
 	}
 	var context *spanContext
 	// The default pprof context is taken from the start options and is
@@ -346,6 +471,7 @@ func (t *tracer) StartSpan(operationName string, options ...ddtrace.StartSpanOpt
 			}
 		}
 	}
+	context = limitChildren(context, t.config.maxChildSpans)
 	if pprofContext == nil {
 		// For root span's without context, there is no pprofContext, but we need
 		// one to avoid a panic() in pprof.WithLabels(). Using context.Background()
@@ -356,20 +482,27 @@ func (t *tracer) StartSpan(operationName string, options ...ddtrace.StartSpanOpt
 		// to properly document this for users.
 		pprofContext = gocontext.Background()
 	}
-	id := opts.SpanID
+	id, traceID := opts.SpanID, opts.SpanID
 	if id == 0 {
-		id = random.Uint64()
+		id, traceID = t.newIDs(context)
 	}
 	// span defaults
 	span := &span{
-		Name:         operationName,
-		Service:      t.config.serviceName,
-		Resource:     operationName,
-		SpanID:       id,
-		TraceID:      id,
-		Start:        startTime,
-		taskEnd:      startExecutionTracerTask(operationName),
-		noDebugStack: t.config.noDebugStack,
+		Name:           operationName,
+		Service:        t.config.serviceName,
+		Resource:       operationName,
+		SpanID:         id,
+		TraceID:        traceID,
+		Start:          startTime,
+		startMonotonic: startMonotonic,
+		taskEnd:        startExecutionTracerTask(operationName),
+		noDebugStack:   t.config.noDebugStack || t.config.memory.underPressure() || t.overhead.throttled(),
+	}
+	if t.config.attrLimits != (attributeLimits{}) {
+		span.attrLimits = &t.config.attrLimits
+	}
+	if corrected != "" {
+		span.setMeta(keyTimestampCorrected, corrected)
 	}
 	if t.config.hostname != "" {
 		span.setMeta(keyHostname, t.config.hostname)
@@ -404,6 +537,10 @@ func (t *tracer) StartSpan(operationName string, options ...ddtrace.StartSpanOpt
 			span.setMeta("language", "go")
 		}
 	}
+	if len(opts.SpanLinks) > 0 {
+		span.links = append([]ddtrace.SpanLink(nil), opts.SpanLinks...)
+		span.setMeta(keySpanLinks, encodeSpanLinks(span.links))
+	}
 	// add tags from options
 	for k, v := range opts.Tags {
 		span.SetTag(k, v)
@@ -412,6 +549,7 @@ func (t *tracer) StartSpan(operationName string, options ...ddtrace.StartSpanOpt
 	for k, v := range t.config.globalTags {
 		span.SetTag(k, v)
 	}
+	t.components.add(span)
 	if t.config.serviceMappings != nil {
 		if newSvc, ok := t.config.serviceMappings[span.Service]; ok {
 			span.Service = newSvc
@@ -422,12 +560,15 @@ func (t *tracer) StartSpan(operationName string, options ...ddtrace.StartSpanOpt
 		// all top level spans are measured. So the measured tag is redundant.
 		delete(span.Metrics, keyMeasured)
 	}
-	if t.config.version != "" {
-		if t.config.universalVersion || (!t.config.universalVersion && span.Service == t.config.serviceName) {
-			span.setMeta(ext.Version, t.config.version)
-		}
+	ownService := span.Service == t.config.serviceName
+	setVersion, setEnv := t.config.universalVersion || ownService, t.config.universalEnv || ownService
+	if opts.UnifiedServiceTags != nil {
+		setVersion, setEnv = *opts.UnifiedServiceTags, *opts.UnifiedServiceTags
+	}
+	if t.config.version != "" && setVersion {
+		span.setMeta(ext.Version, t.config.version)
 	}
-	if t.config.env != "" {
+	if t.config.env != "" && setEnv {
 		span.setMeta(ext.Environment, t.config.env)
 	}
 	if _, ok := span.context.samplingPriority(); !ok {
@@ -437,6 +578,12 @@ func (t *tracer) StartSpan(operationName string, options ...ddtrace.StartSpanOpt
 	if t.config.profilerHotspots || t.config.profilerEndpoints {
 		t.applyPPROFLabels(pprofContext, span)
 	}
+	if t.config.goroutineSpans {
+		goroutineSpans.push(span)
+	}
+	if t.openSpans != nil {
+		t.openSpans.add(span, t.config.spanLeakTimeout > 0 && !t.overhead.throttled(), 1)
+	}
 	if t.config.serviceMappings != nil {
 		if newSvc, ok := t.config.serviceMappings[span.Service]; ok {
 			span.Service = newSvc
@@ -450,6 +597,25 @@ func (t *tracer) StartSpan(operationName string, options ...ddtrace.StartSpanOpt
 	return span
 }
 
+// newIDs returns the ID of a new span, child of parent if it isn't nil, and the ID of
+// its trace, which is only used when the span has no parent.
+func (t *tracer) newIDs(parent *spanContext) (spanID, traceID uint64) {
+	g := t.config.idGenerator
+	if g == nil {
+		id := random.Uint64()
+		return id, id
+	}
+	if parent != nil {
+		traceID = parent.traceID
+	} else if traceID = g.TraceID(); traceID == 0 {
+		traceID = random.Uint64()
+	}
+	if spanID = g.SpanID(traceID); spanID == 0 {
+		spanID = random.Uint64()
+	}
+	return spanID, traceID
+}
+
 // applyPPROFLabels applies pprof labels for the profiler's code hotspots and
 // endpoint filtering feature to span. When span finishes, any pprof labels
 // found in ctx are restored.
@@ -482,14 +648,38 @@ func spanResourcePIISafe(s *span) bool {
 	return s.Type == ext.SpanTypeWeb || s.Type == ext.AppTypeRPC || s.Type == ""
 }
 
-// Stop stops the tracer.
+// Stop stops the tracer. Concurrent and subsequent calls wait for the tracer to be stopped.
 func (t *tracer) Stop() {
+	t.stopContext(gocontext.Background())
+}
+
+// stopContext stops the tracer, returning ctx.Err() if ctx is done before it is stopped.
+func (t *tracer) stopContext(ctx gocontext.Context) error {
 	t.stopOnce.Do(func() {
 		close(t.stop)
 		t.config.statsd.Incr("datadog.tracer.stopped", nil, 1)
+		go func() {
+			defer close(t.stopped)
+			t.shutdown()
+		}()
 	})
+	select {
+	case <-t.stopped:
+		return nil
+	case <-ctx.Done():
+		log.Error("the tracer didn't stop in time, traces may be lost: %v", ctx.Err())
+		return ctx.Err()
+	}
+}
+
+// shutdown stops the background workers, then flushes the traces to the agent.
+func (t *tracer) shutdown() {
 	t.stats.Stop()
 	t.wg.Wait()
+	if t.openSpans != nil {
+		// report spans abandoned since the last health metrics
+		t.reportOpenSpans(time.Unix(0, now()))
+	}
 	t.traceWriter.stop()
 	t.config.statsd.Close()
 	appsec.Stop()
@@ -525,6 +715,10 @@ func (t *tracer) sample(span *span) {
 	if t.rulesSampling.apply(span) {
 		return
 	}
+	if t.config.memory.underPressure() {
+		t.prioritySampling.applyRate(span, t.prioritySampling.getRate(span)*memoryPressureSampleRate)
+		return
+	}
 	t.prioritySampling.apply(span)
 }
 
@@ -16,6 +16,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -25,6 +26,7 @@ import (
 	maininternal "github.com/codebrick-corp/dd-trace-go/internal"
 	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
+	"github.com/codebrick-corp/dd-trace-go/internal/samplernames"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/tinylib/msgp/msgp"
@@ -152,6 +154,74 @@ func TestTracerCleanStop(t *testing.T) {
 	wg.Wait()
 }
 
+// blockingTransport is a transport whose sends block until unblock is closed.
+type blockingTransport struct {
+	*dummyTransport
+	unblock chan struct{}
+}
+
+func (t *blockingTransport) send(p *payload) (io.ReadCloser, error) {
+	<-t.unblock
+	return t.dummyTransport.send(p)
+}
+
+func TestStopContext(t *testing.T) {
+	t.Run("flushed", func(t *testing.T) {
+		transport := newDummyTransport()
+		Start(withTransport(transport), withNoopStats())
+		StartSpan("op").Finish()
+
+		assert.NoError(t, StopContext(context.Background()))
+		assert.Len(t, transport.Traces(), 1)
+		assert.IsType(t, &internal.NoopTracer{}, internal.GetGlobalTracer())
+		// subsequent calls are no-op
+		assert.NoError(t, StopContext(context.Background()))
+	})
+
+	t.Run("deadline", func(t *testing.T) {
+		transport := &blockingTransport{newDummyTransport(), make(chan struct{})}
+		tracer := newTracer(withTransport(transport), withNoopStats(), WithSendRetries(0))
+		internal.SetGlobalTracer(tracer)
+		tracer.StartSpan("op").Finish()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, context.DeadlineExceeded, StopContext(ctx))
+
+		// the tracer keeps stopping in the background
+		close(transport.unblock)
+		tracer.Stop()
+		assert.Len(t, transport.Traces(), 1)
+	})
+
+	t.Run("concurrent", func(t *testing.T) {
+		transport := &blockingTransport{newDummyTransport(), make(chan struct{})}
+		tracer := newTracer(withTransport(transport), withNoopStats())
+		internal.SetGlobalTracer(tracer)
+		defer internal.SetGlobalTracer(&internal.NoopTracer{})
+		tracer.StartSpan("op").Finish()
+
+		var (
+			wg      sync.WaitGroup
+			stopped int32
+		)
+		for i := 0; i < 3; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				tracer.Stop()
+				atomic.AddInt32(&stopped, 1)
+			}()
+		}
+		time.Sleep(10 * time.Millisecond)
+		// all the calls wait for the traces to be flushed
+		assert.Zero(t, atomic.LoadInt32(&stopped))
+		close(transport.unblock)
+		wg.Wait()
+		assert.Len(t, transport.Traces(), 1)
+	})
+}
+
 func TestTracerStart(t *testing.T) {
 	t.Run("normal", func(t *testing.T) {
 		Start()
@@ -255,6 +325,23 @@ func TestTracerStartSpan(t *testing.T) {
 		span := tracer.StartSpan("web.request", Tag(ext.SamplingPriority, ext.PriorityUserKeep)).(*span)
 		assert.Equal(t, float64(ext.PriorityUserKeep), span.Metrics[keySamplingPriority])
 		assert.Equal(t, "dHJhY2VyLnRlc3Q|2|4|", span.context.trace.tags[keyUpstreamServices])
+		assert.Equal(t, "-4", span.context.trace.tags[keyDecisionMaker])
+	})
+
+	t.Run("decision-maker", func(t *testing.T) {
+		tracer := newTracer()
+		defer tracer.Stop()
+		span := tracer.StartSpan("web.request", Tag(ext.SamplingPriority, ext.PriorityUserReject)).(*span)
+		_, ok := span.context.trace.tags[keyDecisionMaker]
+		assert.False(t, ok)
+		span.SetTag(ext.ManualKeep, true)
+		assert.Equal(t, "-4", span.context.trace.tags[keyDecisionMaker])
+		// the first decision to keep the trace is preserved
+		span.context.setSamplingPriority("", ext.PriorityAutoKeep, samplernames.AgentRate, 1)
+		assert.Equal(t, "-4", span.context.trace.tags[keyDecisionMaker])
+		span.SetTag(ext.ManualDrop, true)
+		_, ok = span.context.trace.tags[keyDecisionMaker]
+		assert.False(t, ok)
 	})
 
 	t.Run("name", func(t *testing.T) {
@@ -1453,6 +1540,76 @@ func TestEnvironment(t *testing.T) {
 		_, ok := sp.Meta[ext.Environment]
 		assert.False(ok)
 	})
+
+	t.Run("service", func(t *testing.T) {
+		tracer, _, _, stop := startTestTracer(t, WithEnv("test"), WithService("servenv"))
+		defer stop()
+
+		assert := assert.New(t)
+		sp := tracer.StartSpan("http.request", ServiceName("otherservenv")).(*span)
+		assert.Equal("test", sp.Meta[ext.Environment])
+	})
+
+	t.Run("service/not-universal", func(t *testing.T) {
+		tracer, _, _, stop := startTestTracer(t, WithEnv("test"), WithService("servenv"), WithUniversalEnv(false))
+		defer stop()
+
+		assert := assert.New(t)
+		sp := tracer.StartSpan("http.request", ServiceName("otherservenv")).(*span)
+		_, ok := sp.Meta[ext.Environment]
+		assert.False(ok)
+		sp = tracer.StartSpan("http.request").(*span)
+		assert.Equal("test", sp.Meta[ext.Environment])
+	})
+
+	t.Run("env/not-universal", func(t *testing.T) {
+		os.Setenv("DD_TRACE_UNIVERSAL_ENV", "false")
+		defer os.Unsetenv("DD_TRACE_UNIVERSAL_ENV")
+		tracer, _, _, stop := startTestTracer(t, WithEnv("test"), WithService("servenv"))
+		defer stop()
+
+		assert := assert.New(t)
+		sp := tracer.StartSpan("http.request", ServiceName("otherservenv")).(*span)
+		_, ok := sp.Meta[ext.Environment]
+		assert.False(ok)
+	})
+}
+
+func TestUnifiedServiceTags(t *testing.T) {
+	t.Run("env/universal-version", func(t *testing.T) {
+		os.Setenv("DD_VERSION", "4.5.6")
+		defer os.Unsetenv("DD_VERSION")
+		os.Setenv("DD_TRACE_UNIVERSAL_VERSION", "true")
+		defer os.Unsetenv("DD_TRACE_UNIVERSAL_VERSION")
+		tracer, _, _, stop := startTestTracer(t, WithService("servenv"))
+		defer stop()
+
+		sp := tracer.StartSpan("http.request", ServiceName("otherservenv")).(*span)
+		assert.Equal(t, "4.5.6", sp.Meta[ext.Version])
+	})
+
+	t.Run("override/enabled", func(t *testing.T) {
+		tracer, _, _, stop := startTestTracer(t, WithService("servenv"), WithServiceVersion("4.5.6"),
+			WithEnv("test"), WithUniversalEnv(false))
+		defer stop()
+
+		assert := assert.New(t)
+		sp := tracer.StartSpan("http.request", ServiceName("otherservenv"), WithUnifiedServiceTags(true)).(*span)
+		assert.Equal("4.5.6", sp.Meta[ext.Version])
+		assert.Equal("test", sp.Meta[ext.Environment])
+	})
+
+	t.Run("override/disabled", func(t *testing.T) {
+		tracer, _, _, stop := startTestTracer(t, WithService("servenv"), WithServiceVersion("4.5.6"), WithEnv("test"))
+		defer stop()
+
+		assert := assert.New(t)
+		sp := tracer.StartSpan("http.request", WithUnifiedServiceTags(false)).(*span)
+		_, ok := sp.Meta[ext.Version]
+		assert.False(ok)
+		_, ok = sp.Meta[ext.Environment]
+		assert.False(ok)
+	})
 }
 
 // BenchmarkConcurrentTracing tests the performance of spawning a lot of
@@ -1805,3 +1962,61 @@ func BenchmarkTracerStackFrames(b *testing.B) {
 		span.Finish(StackFrames(64, 0))
 	}
 }
+
+// prefixedIDGenerator generates IDs prefixed with a datacenter ID, for testing.
+type prefixedIDGenerator struct {
+	prefix uint64
+	n      uint64
+	zero   bool
+}
+
+func (g *prefixedIDGenerator) TraceID() uint64 {
+	if g.zero {
+		return 0
+	}
+	return g.prefix<<56 | atomic.AddUint64(&g.n, 1)
+}
+
+func (g *prefixedIDGenerator) SpanID(traceID uint64) uint64 {
+	if g.zero {
+		return 0
+	}
+	return g.prefix<<56 | atomic.AddUint64(&g.n, 1)
+}
+
+func TestIDGenerator(t *testing.T) {
+	t.Run("custom", func(t *testing.T) {
+		g := &prefixedIDGenerator{prefix: 7}
+		tracer := newTracer(WithIDGenerator(g))
+		defer tracer.Stop()
+
+		root := tracer.StartSpan("root").(*span)
+		child := tracer.StartSpan("child", ChildOf(root.Context())).(*span)
+		assert.Equal(t, uint64(7<<56|1), root.TraceID)
+		assert.Equal(t, uint64(7<<56|2), root.SpanID)
+		assert.Equal(t, root.TraceID, child.TraceID)
+		assert.Equal(t, uint64(7<<56|3), child.SpanID)
+		assert.Equal(t, root.SpanID, child.ParentID)
+
+		withID := tracer.StartSpan("root", WithSpanID(42)).(*span)
+		assert.Equal(t, uint64(42), withID.SpanID)
+		assert.Equal(t, uint64(42), withID.TraceID)
+	})
+
+	t.Run("zero", func(t *testing.T) {
+		tracer := newTracer(WithIDGenerator(&prefixedIDGenerator{zero: true}))
+		defer tracer.Stop()
+
+		root := tracer.StartSpan("root").(*span)
+		assert.NotZero(t, root.TraceID)
+		assert.NotZero(t, root.SpanID)
+	})
+
+	t.Run("default", func(t *testing.T) {
+		tracer := newTracer()
+		defer tracer.Stop()
+
+		root := tracer.StartSpan("root").(*span)
+		assert.Equal(t, root.SpanID, root.TraceID)
+	})
+}
@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"io"
+	"math"
+	"runtime"
+	"strings"
+
+	"github.com/codebrick-corp/dd-trace-go/internal"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+	"github.com/codebrick-corp/dd-trace-go/internal/version"
+
+	"github.com/tinylib/msgp/msgp"
+)
+
+// traceProtocol is a version of the trace API of the agent.
+type traceProtocol int
+
+const (
+	// traceProtocolV04 sends the traces as an array of span arrays to /v0.4/traces.
+	traceProtocolV04 traceProtocol = iota
+	// traceProtocolV07 sends the traces to /v0.7/traces as the chunks of a tracer payload,
+	// which holds the attributes shared by the traces, such as the runtime ID and env, and
+	// the chunks hold the attributes shared by their spans, such as the sampling priority
+	// and trace tags.
+	traceProtocolV07
+)
+
+// priorityNone is the priority of the chunks whose sampling decision is unknown.
+const priorityNone = math.MinInt8
+
+// traceProtocolFor returns the trace protocol used with the agent: v0.7 when the agent
+// supports it, unless the v0.4 protocol is required by the configuration.
+func traceProtocolFor(c *config) traceProtocol {
+	if c.agent.TracesV07 && c.traceProtocol != "0.4" {
+		return traceProtocolV07
+	}
+	return traceProtocolV04
+}
+
+// newChunkedPayload returns a ready to use payload for the v0.7 protocol, whose chunks are
+// preceded by prefix, as returned by tracerPayloadPrefix.
+func newChunkedPayload(prefix []byte) *payload {
+	p := newPayload()
+	p.protocol = traceProtocolV07
+	p.prefix = prefix
+	return p
+}
+
+// tracerPayloadPrefix returns the msgpack encoding of the fields of the v0.7 tracer payload
+// sent with the configuration c, followed by the key of its chunks, the last field.
+func tracerPayloadPrefix(c *config) []byte {
+	fields := [][2]string{
+		{"container_id", internal.ContainerID()},
+		{"language_name", "go"},
+		{"language_version", strings.TrimPrefix(runtime.Version(), "go")},
+		{"tracer_version", version.Tag},
+		{"runtime_id", globalconfig.RuntimeID()},
+		{"env", c.env},
+		{"hostname", c.hostname},
+		{"app_version", c.version},
+	}
+	b := msgp.AppendMapHeader(nil, uint32(len(fields)+1))
+	for _, f := range fields {
+		b = msgp.AppendString(b, f[0])
+		b = msgp.AppendString(b, f[1])
+	}
+	return msgp.AppendString(b, "chunks")
+}
+
+// encodeChunk writes the msgpack encoding of the trace chunk holding the spans of t to w.
+// The sampling priority, origin and trace tags are taken from the spans.
+func encodeChunk(w io.Writer, t spanList) error {
+	var (
+		priority int32 = priorityNone
+		origin   string
+		tags     = make(map[string]string)
+	)
+	for _, s := range t {
+		if p, ok := s.Metrics[keySamplingPriority]; ok {
+			priority = int32(p)
+		}
+	}
+	if len(t) > 0 {
+		// the trace tags are set on the first span of the chunk
+		origin = t[0].Meta[keyOrigin]
+		for k, v := range t[0].Meta {
+			if strings.HasPrefix(k, propagatingTagPrefix) {
+				tags[k] = v
+			}
+		}
+	}
+	mw := msgp.NewWriter(w)
+	mw.WriteMapHeader(5)
+	mw.WriteString("priority")
+	mw.WriteInt32(priority)
+	mw.WriteString("origin")
+	mw.WriteString(origin)
+	mw.WriteString("spans")
+	if err := t.EncodeMsg(mw); err != nil {
+		return err
+	}
+	mw.WriteString("tags")
+	mw.WriteMapHeader(uint32(len(tags)))
+	for k, v := range tags {
+		mw.WriteString(k)
+		mw.WriteString(v)
+	}
+	mw.WriteString("dropped_trace")
+	mw.WriteBool(false)
+	return mw.Flush()
+}
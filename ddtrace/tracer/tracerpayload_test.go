@@ -0,0 +1,166 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/tinylib/msgp/msgp"
+)
+
+// decodeTracerPayload decodes the v0.7 tracer payload b, returning its string fields
+// and the fields of its chunks.
+func decodeTracerPayload(t *testing.T, b []byte) (map[string]string, []map[string]interface{}) {
+	r := msgp.NewReader(bytes.NewReader(b))
+	n, err := r.ReadMapHeader()
+	assert.NoError(t, err)
+	fields := make(map[string]string)
+	var chunks []map[string]interface{}
+	for i := uint32(0); i < n; i++ {
+		k, err := r.ReadString()
+		assert.NoError(t, err)
+		if k != "chunks" {
+			fields[k], err = r.ReadString()
+			assert.NoError(t, err)
+			continue
+		}
+		m, err := r.ReadArrayHeader()
+		assert.NoError(t, err)
+		for j := uint32(0); j < m; j++ {
+			chunk := make(map[string]interface{})
+			nf, err := r.ReadMapHeader()
+			assert.NoError(t, err)
+			for f := uint32(0); f < nf; f++ {
+				name, err := r.ReadString()
+				assert.NoError(t, err)
+				switch name {
+				case "spans":
+					var spans spanList
+					assert.NoError(t, spans.DecodeMsg(r))
+					chunk[name] = spans
+				case "tags":
+					tags := make(map[string]string)
+					nt, err := r.ReadMapHeader()
+					assert.NoError(t, err)
+					for k := uint32(0); k < nt; k++ {
+						key, _ := r.ReadString()
+						tags[key], _ = r.ReadString()
+					}
+					chunk[name] = tags
+				default:
+					chunk[name], err = r.ReadIntf()
+					assert.NoError(t, err)
+				}
+			}
+			chunks = append(chunks, chunk)
+		}
+	}
+	return fields, chunks
+}
+
+func TestChunkedPayload(t *testing.T) {
+	c := newConfig(WithEnv("prod"), WithServiceVersion("1.2.3"))
+	p := newChunkedPayload(tracerPayloadPrefix(c))
+
+	root := newBasicSpan("root")
+	root.Metrics[keySamplingPriority] = ext.PriorityUserKeep
+	root.Meta[keyOrigin] = "synthetics"
+	root.Meta[keyDecisionMaker] = "-4"
+	root.Meta["http.method"] = "GET"
+	child := newBasicSpan("child")
+	assert.NoError(t, p.push(spanList{root, child}))
+	assert.NoError(t, p.push(spanList{newBasicSpan("other")}))
+	assert.Equal(t, 2, p.itemCount())
+
+	size := p.size()
+	b, err := ioutil.ReadAll(p)
+	assert.NoError(t, err)
+	assert.Equal(t, size, len(b))
+
+	fields, chunks := decodeTracerPayload(t, b)
+	assert.Equal(t, "go", fields["language_name"])
+	assert.Equal(t, "prod", fields["env"])
+	assert.Equal(t, "1.2.3", fields["app_version"])
+	assert.NotEmpty(t, fields["runtime_id"])
+	assert.NotEmpty(t, fields["tracer_version"])
+	assert.Len(t, chunks, 2)
+
+	assert.EqualValues(t, ext.PriorityUserKeep, chunks[0]["priority"])
+	assert.Equal(t, "synthetics", chunks[0]["origin"])
+	assert.Equal(t, map[string]string{keyDecisionMaker: "-4"}, chunks[0]["tags"])
+	assert.Equal(t, false, chunks[0]["dropped_trace"])
+	spans := chunks[0]["spans"].(spanList)
+	assert.Len(t, spans, 2)
+	assert.Equal(t, "root", spans[0].Name)
+	assert.Equal(t, "child", spans[1].Name)
+
+	assert.EqualValues(t, priorityNone, chunks[1]["priority"])
+	assert.Equal(t, "", chunks[1]["origin"])
+	assert.Empty(t, chunks[1]["tags"])
+}
+
+func TestTraceProtocol(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"endpoints":["/v0.4/traces","/v0.7/traces"]}`))
+	}))
+	defer srv.Close()
+	addr := strings.TrimPrefix(srv.URL, "http://")
+
+	t.Run("supported", func(t *testing.T) {
+		c := newConfig(WithAgentAddr(addr))
+		assert.True(t, c.agent.TracesV07)
+		assert.Equal(t, traceProtocolV07, traceProtocolFor(c))
+
+		w := newAgentTraceWriter(c, nil)
+		assert.Equal(t, traceProtocolV07, w.payload.protocol)
+		assert.NotEmpty(t, w.payload.prefix)
+	})
+
+	t.Run("unsupported", func(t *testing.T) {
+		c := newConfig(WithLambdaMode(true))
+		assert.False(t, c.agent.TracesV07)
+		assert.Equal(t, traceProtocolV04, traceProtocolFor(c))
+
+		w := newAgentTraceWriter(c, nil)
+		assert.Equal(t, traceProtocolV04, w.payload.protocol)
+		assert.Empty(t, w.payload.prefix)
+	})
+
+	t.Run("option", func(t *testing.T) {
+		c := newConfig(WithAgentAddr(addr), WithAgentProtocolVersion("0.4"))
+		assert.Equal(t, traceProtocolV04, traceProtocolFor(c))
+	})
+
+	t.Run("env", func(t *testing.T) {
+		os.Setenv("DD_TRACE_AGENT_PROTOCOL_VERSION", "0.4")
+		defer os.Unsetenv("DD_TRACE_AGENT_PROTOCOL_VERSION")
+		c := newConfig(WithAgentAddr(addr))
+		assert.Equal(t, traceProtocolV04, traceProtocolFor(c))
+	})
+
+	t.Run("transport", func(t *testing.T) {
+		var path string
+		agent := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+		}))
+		defer agent.Close()
+		trans := newHTTPTransport(strings.TrimPrefix(agent.URL, "http://"), defaultClient)
+		p := newChunkedPayload(tracerPayloadPrefix(newConfig(WithLambdaMode(true))))
+		assert.NoError(t, p.push(spanList{newBasicSpan("root")}))
+		_, err := trans.send(p)
+		assert.NoError(t, err)
+		assert.Equal(t, "/v0.7/traces", path)
+	})
+}
@@ -20,6 +20,7 @@ import (
 
 	traceinternal "github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
 	"github.com/codebrick-corp/dd-trace-go/internal"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
 	"github.com/codebrick-corp/dd-trace-go/internal/version"
 
 	"github.com/tinylib/msgp/msgp"
@@ -72,10 +73,14 @@ type transport interface {
 }
 
 type httpTransport struct {
-	traceURL string            // the delivery URL for traces
-	statsURL string            // the delivery URL for stats
-	client   *http.Client      // the HTTP client used in the POST
-	headers  map[string]string // the Transport headers
+	traceURL    string            // the delivery URL for traces
+	traceV07URL string            // the delivery URL for traces encoded with the v0.7 protocol
+	statsURL    string            // the delivery URL for stats
+	client      *http.Client      // the HTTP client used in the POST
+	headers     map[string]string // the Transport headers
+
+	// compressor compresses the trace payloads; nil if they aren't compressed.
+	compressor *compressor
 }
 
 // newTransport returns a new Transport implementation that sends traces to a
@@ -101,10 +106,11 @@ func newHTTPTransport(addr string, client *http.Client) *httpTransport {
 		defaultHeaders["Datadog-Container-ID"] = cid
 	}
 	return &httpTransport{
-		traceURL: fmt.Sprintf("http://%s/v0.4/traces", addr),
-		statsURL: fmt.Sprintf("http://%s/v0.6/stats", addr),
-		client:   client,
-		headers:  defaultHeaders,
+		traceURL:    fmt.Sprintf("http://%s/v0.4/traces", addr),
+		traceV07URL: fmt.Sprintf("http://%s/v0.7/traces", addr),
+		statsURL:    fmt.Sprintf("http://%s/v0.6/stats", addr),
+		client:      client,
+		headers:     defaultHeaders,
 	}
 }
 
@@ -137,15 +143,34 @@ func (t *httpTransport) sendStats(p *statsPayload) error {
 }
 
 func (t *httpTransport) send(p *payload) (body io.ReadCloser, err error) {
-	req, err := http.NewRequest("POST", t.traceURL, p)
+	var (
+		content    io.Reader = p
+		size                 = p.size()
+		compressed           = t.compressor.enabled()
+	)
+	if compressed {
+		buf, err := t.compressor.compress(p)
+		if err != nil {
+			return nil, fmt.Errorf("cannot compress payload: %v", err)
+		}
+		content, size = buf, buf.Len()
+	}
+	url := t.traceURL
+	if p.protocol == traceProtocolV07 {
+		url = t.traceV07URL
+	}
+	req, err := http.NewRequest("POST", url, content)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create http request: %v", err)
 	}
 	for header, value := range t.headers {
 		req.Header.Set(header, value)
 	}
+	if compressed {
+		req.Header.Set("Content-Encoding", t.compressor.encoding)
+	}
 	req.Header.Set(traceCountHeader, strconv.Itoa(p.itemCount()))
-	req.Header.Set("Content-Length", strconv.Itoa(p.size()))
+	req.Header.Set("Content-Length", strconv.Itoa(size))
 	req.Header.Set(headerComputedTopLevel, "yes")
 	if t, ok := traceinternal.GetGlobalTracer().(*tracer); ok {
 		if t.config.canComputeStats() {
@@ -165,6 +190,10 @@ func (t *httpTransport) send(p *payload) (body io.ReadCloser, err error) {
 		return nil, err
 	}
 	if code := response.StatusCode; code >= 400 {
+		if compressed && (code == http.StatusBadRequest || code == http.StatusUnsupportedMediaType) && t.compressor.reject() {
+			// the agent doesn't support compressed payloads
+			log.Warn("The agent rejected a %s compressed payload (Status: %s), sending uncompressed payloads from now on", t.compressor.encoding, http.StatusText(code))
+		}
 		// error, check the body for context information and
 		// return a nice error.
 		msg := make([]byte, 1000)
@@ -176,6 +205,9 @@ func (t *httpTransport) send(p *payload) (body io.ReadCloser, err error) {
 		}
 		return nil, fmt.Errorf("%s", txt)
 	}
+	if compressed {
+		t.compressor.accept()
+	}
 	return response.Body, nil
 }
 
@@ -0,0 +1,99 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import "sort"
+
+const (
+	// keyTruncatedSpans is the metric set on the roots of a truncated trace holding
+	// the number of spans which were dropped from it.
+	keyTruncatedSpans = "_dd.trace.truncated.spans"
+	// keyTruncatedBytes is the metric set on the roots of a truncated trace holding
+	// the estimated size in bytes of the spans which were dropped from it.
+	keyTruncatedBytes = "_dd.trace.truncated.bytes"
+)
+
+// maxTraceSize is the estimated size in bytes above which a trace is truncated so that
+// it fits in a payload accepted by the agent. It leaves room for the truncation metrics.
+var maxTraceSize = int(payloadMaxLimit) - 1024
+
+// traceSize returns an upper bound of the size in bytes of the encoded trace.
+func traceSize(trace []*span) int {
+	size := 0
+	for _, s := range trace {
+		size += s.Msgsize()
+	}
+	return size
+}
+
+// truncateTrace drops spans from the given trace, whose estimated size is size, until it
+// fits in limit bytes. The spans are kept in the following order: the roots of the trace,
+// meaning the spans whose parent is not part of it, then the spans with errors, then the
+// slowest spans, the span IDs breaking ties so that the same trace is always truncated
+// the same way. The spans which are kept stay in their original order and the roots are
+// tagged with the number and the size of the dropped spans. It returns the trace along
+// with the number of dropped spans.
+func truncateTrace(trace []*span, size, limit int) ([]*span, int) {
+	if size <= limit {
+		return trace, 0
+	}
+	ids := make(map[uint64]struct{}, len(trace))
+	for _, s := range trace {
+		ids[s.SpanID] = struct{}{}
+	}
+	isRoot := func(s *span) bool {
+		_, ok := ids[s.ParentID]
+		return s.ParentID == 0 || !ok
+	}
+	order := make([]int, len(trace))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := trace[order[i]], trace[order[j]]
+		if ra, rb := isRoot(a), isRoot(b); ra != rb {
+			return ra
+		}
+		if ea, eb := a.Error != 0, b.Error != 0; ea != eb {
+			return ea
+		}
+		if a.Duration != b.Duration {
+			return a.Duration > b.Duration
+		}
+		return a.SpanID < b.SpanID
+	})
+	keep := make([]bool, len(trace))
+	kept := 0
+	for _, i := range order {
+		s := trace[i]
+		n := s.Msgsize()
+		// the roots are always kept
+		if kept+n > limit && !isRoot(s) {
+			continue
+		}
+		keep[i] = true
+		kept += n
+	}
+	truncated := make([]*span, 0, len(trace))
+	var roots []*span
+	for i, s := range trace {
+		if !keep[i] {
+			continue
+		}
+		truncated = append(truncated, s)
+		if isRoot(s) {
+			roots = append(roots, s)
+		}
+	}
+	dropped := len(trace) - len(truncated)
+	for _, s := range roots {
+		s.Lock()
+		s.setMetric(keyTruncatedSpans, float64(dropped))
+		s.setMetric(keyTruncatedBytes, float64(size-kept))
+		s.Unlock()
+	}
+	return truncated, dropped
+}
@@ -0,0 +1,118 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTruncationTrace returns a trace made of a root and n children of the same size,
+// the i-th child lasting i nanoseconds.
+func newTruncationTrace(n int) []*span {
+	root := newSpan("root", "svc", "res", 1, 1, 0)
+	trace := []*span{root}
+	for i := 1; i <= n; i++ {
+		s := newSpan("child", "svc", "res", uint64(i+1), 1, 1)
+		s.Duration = int64(i)
+		s.Meta["payload"] = strings.Repeat("x", 100)
+		trace = append(trace, s)
+	}
+	return trace
+}
+
+func spanIDs(trace []*span) []uint64 {
+	ids := make([]uint64, len(trace))
+	for i, s := range trace {
+		ids[i] = s.SpanID
+	}
+	return ids
+}
+
+func TestTruncateTrace(t *testing.T) {
+	t.Run("fits", func(t *testing.T) {
+		trace := newTruncationTrace(3)
+		size := traceSize(trace)
+		truncated, dropped := truncateTrace(trace, size, size)
+		assert.Equal(t, trace, truncated)
+		assert.Zero(t, dropped)
+		assert.NotContains(t, trace[0].Metrics, keyTruncatedSpans)
+	})
+
+	t.Run("slowest", func(t *testing.T) {
+		trace := newTruncationTrace(5)
+		size := traceSize(trace)
+		limit := trace[0].Msgsize() + 2*trace[1].Msgsize()
+		truncated, dropped := truncateTrace(trace, size, limit)
+		assert.Equal(t, 3, dropped)
+		// the root and the two slowest children, in their original order
+		assert.Equal(t, []uint64{1, 5, 6}, spanIDs(truncated))
+		assert.Equal(t, 3., trace[0].Metrics[keyTruncatedSpans])
+		assert.Equal(t, float64(size-limit), trace[0].Metrics[keyTruncatedBytes])
+		assert.NotContains(t, trace[5].Metrics, keyTruncatedSpans)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		trace := newTruncationTrace(5)
+		trace[2].Error = 1
+		size := traceSize(trace)
+		limit := trace[0].Msgsize() + 2*trace[1].Msgsize()
+		truncated, dropped := truncateTrace(trace, size, limit)
+		assert.Equal(t, 3, dropped)
+		assert.Equal(t, []uint64{1, 3, 6}, spanIDs(truncated))
+	})
+
+	t.Run("deterministic", func(t *testing.T) {
+		trace := newTruncationTrace(5)
+		for _, s := range trace[1:] {
+			s.Duration = 10
+		}
+		size := traceSize(trace)
+		limit := trace[0].Msgsize() + 2*trace[1].Msgsize()
+		truncated, _ := truncateTrace(trace, size, limit)
+		// equally slow spans are kept by span ID
+		assert.Equal(t, []uint64{1, 2, 3}, spanIDs(truncated))
+	})
+
+	t.Run("roots", func(t *testing.T) {
+		// a partial flush of a trace whose root is not part of the chunk
+		trace := newTruncationTrace(4)[1:]
+		trace[3].ParentID = 4
+		size := traceSize(trace)
+		truncated, dropped := truncateTrace(trace, size, 1)
+		assert.Equal(t, 1, dropped)
+		// the spans whose parent is not part of the chunk are kept, even above the limit
+		assert.Equal(t, []uint64{2, 3, 4}, spanIDs(truncated))
+		for _, s := range truncated {
+			assert.Equal(t, 1., s.Metrics[keyTruncatedSpans])
+		}
+	})
+}
+
+func TestAgentWriterTruncation(t *testing.T) {
+	defer func(old int) { maxTraceSize = old }(maxTraceSize)
+	var tg testStatsdClient
+	tr := newDummyTransport()
+	h := newAgentTraceWriter(newConfig(withTransport(tr), withStatsdClient(&tg)), newPrioritySampler())
+
+	trace := newTruncationTrace(10)
+	maxTraceSize = trace[0].Msgsize() + 4*trace[1].Msgsize() + 256
+	h.add(trace)
+	h.add([]*span{newBasicSpan("small")})
+	h.flush()
+	h.wg.Wait()
+
+	traces := tr.Traces()
+	require.Len(t, traces, 2)
+	assert.Len(t, traces[0], 5)
+	assert.Equal(t, "root", traces[0][0].Name)
+	assert.Equal(t, 6., traces[0][0].Metrics[keyTruncatedSpans])
+	assert.Equal(t, "small", traces[1][0].Name)
+	assert.Equal(t, int64(6), tg.Counts()["datadog.tracer.spans_truncated"])
+}
@@ -0,0 +1,104 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/internal"
+	"github.com/codebrick-corp/dd-trace-go/internal/log"
+)
+
+// Count adds value to the count metric with the given name and tags, e.g.:
+//
+//	tracer.Count("checkout.items", int64(len(items)), "payment:card")
+//
+// Metrics are sent to the DogStatsD server through the client of the running tracer,
+// which also reports runtime and health metrics, and are tagged with the service,
+// environment and version configured for the tracer. Tags are "key:value" strings.
+// Metrics are discarded when the tracer is not started.
+func Count(name string, value int64, tags ...string) {
+	if t, ok := internal.GetGlobalTracer().(*tracer); ok {
+		tags = userMetricTags(t.config.serviceName, t.config.env, t.config.version, tags)
+		logStatsdError(name, t.config.userStatsd.Count(name, value, tags, 1))
+	}
+}
+
+// Gauge sets the gauge metric with the given name and tags to value. See Count.
+func Gauge(name string, value float64, tags ...string) {
+	if t, ok := internal.GetGlobalTracer().(*tracer); ok {
+		tags = userMetricTags(t.config.serviceName, t.config.env, t.config.version, tags)
+		logStatsdError(name, t.config.userStatsd.Gauge(name, value, tags, 1))
+	}
+}
+
+// Distribution adds value to the distribution metric with the given name and tags,
+// whose percentiles are computed over all the hosts reporting it. See Count.
+func Distribution(name string, value float64, tags ...string) {
+	if t, ok := internal.GetGlobalTracer().(*tracer); ok {
+		tags = userMetricTags(t.config.serviceName, t.config.env, t.config.version, tags)
+		logStatsdError(name, t.config.userStatsd.Distribution(name, value, tags, 1))
+	}
+}
+
+// SpanDistribution adds value to the distribution metric with the given name and tags,
+// like Distribution, with the service, environment, version and resource of s, e.g.:
+//
+//	span, ctx := tracer.StartSpanFromContext(ctx, "checkout", tracer.ResourceName("POST /cart"))
+//	defer span.Finish()
+//	tracer.SpanDistribution(span, "checkout.amount", amount, "currency:eur")
+//
+// This keeps the dimensions of the metric consistent with the ones of the span. The
+// resource should have a low cardinality, as each of its values makes a new context
+// for the metric.
+func SpanDistribution(s ddtrace.Span, name string, value float64, tags ...string) {
+	t, ok := internal.GetGlobalTracer().(*tracer)
+	if !ok {
+		return
+	}
+	sp, ok := s.(*span)
+	if !ok {
+		Distribution(name, value, tags...)
+		return
+	}
+	sp.RLock()
+	service, resource := sp.Service, sp.Resource
+	env, version := sp.Meta[ext.Environment], sp.Meta[ext.Version]
+	sp.RUnlock()
+	if env == "" {
+		env = t.config.env
+	}
+	if version == "" {
+		version = t.config.version
+	}
+	tags = userMetricTags(service, env, version, tags)
+	if resource != "" {
+		tags = append(tags, "resource:"+resource)
+	}
+	logStatsdError(name, t.config.userStatsd.Distribution(name, value, tags, 1))
+}
+
+// userMetricTags returns tags along with the tags of the given service, environment
+// and version, when they are set.
+func userMetricTags(service, env, version string, tags []string) []string {
+	tags = tags[:len(tags):len(tags)]
+	if service != "" {
+		tags = append(tags, "service:"+service)
+	}
+	if env != "" {
+		tags = append(tags, "env:"+env)
+	}
+	if version != "" {
+		tags = append(tags, "version:"+version)
+	}
+	return tags
+}
+
+func logStatsdError(name string, err error) {
+	if err != nil {
+		log.Debug("Failed to report metric %q: %v", name, err)
+	}
+}
@@ -0,0 +1,78 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package tracer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/internal/globalconfig"
+)
+
+func TestUserMetrics(t *testing.T) {
+	t.Run("started", func(t *testing.T) {
+		var tg testStatsdClient
+		_, _, _, stop := startTestTracer(t, withStatsdClient(&tg),
+			WithService("shop"), WithEnv("prod"), WithServiceVersion("1.2.3"))
+		defer stop()
+		defer globalconfig.SetServiceName("")
+
+		Count("checkout.items", 3, "payment:card")
+		Gauge("queue.size", 12)
+		Distribution("checkout.amount", 42.5, "currency:eur")
+
+		counts := tg.CountCalls()
+		assert.Len(t, counts, 1)
+		assert.Equal(t, "checkout.items", counts[0].name)
+		assert.Equal(t, int64(3), counts[0].intVal)
+		assert.Equal(t, []string{"payment:card", "service:shop", "env:prod", "version:1.2.3"}, counts[0].tags)
+
+		var gauge *testStatsdCall
+		for _, c := range tg.GaugeCalls() {
+			if c.name == "queue.size" {
+				c := c
+				gauge = &c
+			}
+		}
+		if assert.NotNil(t, gauge) {
+			assert.Equal(t, 12.0, gauge.floatVal)
+			assert.Equal(t, []string{"service:shop", "env:prod", "version:1.2.3"}, gauge.tags)
+		}
+
+		dists := tg.DistributionCalls()
+		assert.Len(t, dists, 1)
+		assert.Equal(t, "checkout.amount", dists[0].name)
+		assert.Equal(t, 42.5, dists[0].floatVal)
+		assert.Equal(t, []string{"currency:eur", "service:shop", "env:prod", "version:1.2.3"}, dists[0].tags)
+	})
+
+	t.Run("not-started", func(t *testing.T) {
+		// must not panic
+		Count("checkout.items", 3)
+		Gauge("queue.size", 12)
+		Distribution("checkout.amount", 42.5)
+		SpanDistribution(nil, "checkout.amount", 42.5)
+	})
+}
+
+func TestSpanDistribution(t *testing.T) {
+	var tg testStatsdClient
+	_, _, _, stop := startTestTracer(t, withStatsdClient(&tg), WithService("shop"), WithEnv("prod"))
+	defer stop()
+	defer globalconfig.SetServiceName("")
+
+	span := StartSpan("checkout", ServiceName("cart"), ResourceName("POST /cart"), Tag(ext.Version, "2.0.0"))
+	SpanDistribution(span, "checkout.amount", 42.5, "currency:eur")
+	span.Finish()
+
+	dists := tg.DistributionCalls()
+	assert.Len(t, dists, 1)
+	assert.Equal(t, "checkout.amount", dists[0].name)
+	assert.Equal(t, 42.5, dists[0].floatVal)
+	assert.Equal(t, []string{"currency:eur", "service:cart", "env:prod", "version:2.0.0", "resource:POST /cart"}, dists[0].tags)
+}
@@ -14,6 +14,7 @@ import (
 	"os"
 	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/codebrick-corp/dd-trace-go/internal/log"
@@ -37,6 +38,13 @@ type agentTraceWriter struct {
 	// payload encodes and buffers traces in msgpack format
 	payload *payload
 
+	// protocol specifies the version of the trace API the payloads are encoded for.
+	protocol traceProtocol
+
+	// prefix holds the fields of the tracer payloads preceding their chunks, when
+	// using the v0.7 protocol.
+	prefix []byte
+
 	// climit limits the number of concurrent outgoing connections
 	climit chan struct{}
 
@@ -46,32 +54,122 @@ type agentTraceWriter struct {
 	// prioritySampling is the prioritySampler into which agentTraceWriter will
 	// read sampling rates sent by the agent
 	prioritySampling *prioritySampler
+
+	// status records the state of the writer reported by DebugHandler
+	status writerStatus
+
+	// stopping is closed when the writer is stopped, interrupting the retries.
+	stopping chan struct{}
+
+	// breaker stops the encoding of traces while the agent is unreachable.
+	breaker *agentBreaker
+
+	// mu guards below fields
+	mu sync.Mutex
+	// retained holds the payloads which couldn't be sent, oldest first, to be sent
+	// again after the next successful flush.
+	retained []retainedPayload
+	// retainedSize is the total size in bytes of the retained payloads.
+	retainedSize int
 }
 
 func newAgentTraceWriter(c *config, s *prioritySampler) *agentTraceWriter {
-	return &agentTraceWriter{
+	h := &agentTraceWriter{
 		config:           c,
-		payload:          newPayload(),
+		protocol:         traceProtocolFor(c),
 		climit:           make(chan struct{}, concurrentConnectionLimit),
 		prioritySampling: s,
+		stopping:         make(chan struct{}),
+		breaker:          newAgentBreaker(c.agentUnreachableThreshold, c.agentProbeInterval),
+	}
+	if h.protocol == traceProtocolV07 {
+		h.prefix = tracerPayloadPrefix(c)
 	}
+	h.payload = h.newPayload()
+	return h
+}
+
+// newPayload returns a new payload for the protocol used by the writer.
+func (h *agentTraceWriter) newPayload() *payload {
+	if h.protocol == traceProtocolV07 {
+		return newChunkedPayload(h.prefix)
+	}
+	return newPayload()
 }
 
 func (h *agentTraceWriter) add(trace []*span) {
+	if !h.breaker.allow() {
+		// the agent is unreachable, don't bother encoding the trace
+		h.status.skipped()
+		h.config.statsd.Incr("datadog.tracer.traces_dropped", []string{"reason:agent_unreachable"}, 1)
+		return
+	}
+	// the size of the trace is only known once encoded: rather than estimating it beforehand,
+	// the size of the payload, which is kept as the traces are pushed, is checked afterwards
+	n := h.payload.buf.Len()
 	if err := h.payload.push(trace); err != nil {
 		h.config.statsd.Incr("datadog.tracer.traces_dropped", []string{"reason:encoding_error"}, 1)
 		log.Error("Error encoding msgpack: %v", err)
+		return
+	}
+	if h.payload.size() > maxTraceSize {
+		h.pushLarge(trace, h.payload.buf.Len()-n)
+	}
+	h.status.setBuffered(h.payload)
+	limit := int(payloadSizeLimit)
+	if h.config.memory.underPressure() {
+		limit /= memoryPressurePayloadDivisor
+	}
+	if h.payload.size() > limit {
+		h.config.statsd.Incr("datadog.tracer.flush_triggered", []string{"reason:size"}, 1)
+		h.flush()
 	}
-	if h.payload.size() > payloadSizeLimit {
+}
+
+// pushLarge pushes again the trace which was just pushed, encoded in n bytes, once the
+// payload exceeded maxTraceSize with it. The traces buffered before it are flushed first,
+// and the trace is truncated if it doesn't fit in a payload on its own.
+func (h *agentTraceWriter) pushLarge(trace []*span, n int) {
+	h.payload.pop(n)
+	if h.payload.itemCount() > 0 {
+		// the trace doesn't fit in the payload along with the buffered traces
 		h.config.statsd.Incr("datadog.tracer.flush_triggered", []string{"reason:size"}, 1)
 		h.flush()
 	}
+	if n > maxTraceSize {
+		var dropped int
+		trace, dropped = truncateTrace(trace, traceSize(trace), maxTraceSize)
+		if dropped > 0 {
+			h.config.statsd.Count("datadog.tracer.spans_truncated", int64(dropped), nil, 1)
+			log.Error("dropped %d spans of a trace too large to be sent to the agent", dropped)
+		}
+	}
+	if err := h.payload.push(trace); err != nil {
+		h.config.statsd.Incr("datadog.tracer.traces_dropped", []string{"reason:encoding_error"}, 1)
+		log.Error("Error encoding msgpack: %v", err)
+	}
 }
 
 func (h *agentTraceWriter) stop() {
+	select {
+	case <-h.stopping:
+		// already stopped
+	default:
+		close(h.stopping)
+	}
 	h.config.statsd.Incr("datadog.tracer.flush_triggered", []string{"reason:shutdown"}, 1)
 	h.flush()
 	h.wg.Wait()
+	// the payloads still retained can't be sent anymore
+	for {
+		r, ok := h.popRetained()
+		if !ok {
+			break
+		}
+		h.status.lost(r.count)
+		h.config.statsd.Count("datadog.tracer.traces_dropped", int64(r.count), []string{"reason:send_failed"}, 1)
+		log.Error("lost %d traces: the agent couldn't be reached before stopping", r.count)
+	}
 }
 
 // flush will push any currently buffered traces to the server.
@@ -82,27 +180,146 @@ func (h *agentTraceWriter) flush() {
 	h.wg.Add(1)
 	h.climit <- struct{}{}
 	oldp := h.payload
-	h.payload = newPayload()
+	h.payload = h.newPayload()
+	h.status.setBuffered(h.payload)
 	go func(p *payload) {
 		defer func(start time.Time) {
 			<-h.climit
 			h.wg.Done()
 			h.config.statsd.Timing("datadog.tracer.flush_duration", time.Since(start), nil, 1)
 		}(time.Now())
-		size, count := p.size(), p.itemCount()
+		if err := h.send(p); err != nil {
+			return
+		}
+		// the agent is reachable, send the payloads which couldn't be sent before
+		for {
+			r, ok := h.popRetained()
+			if !ok {
+				return
+			}
+			if err := h.send(r.payload()); err != nil {
+				return
+			}
+		}
+	}(oldp)
+}
+
+// send sends the payload p, retrying up to config.sendRetries times with an exponential
+// backoff. If all attempts fail, the payload is retained to be sent later when there is
+// room for it in the send buffer, and dropped otherwise.
+func (h *agentTraceWriter) send(p *payload) error {
+	size, count := p.size(), p.itemCount()
+	// the items of the payload are kept untouched by reading it
+	r := retainedPayload{items: p.buf.Bytes(), count: count, protocol: p.protocol, prefix: p.prefix}
+	backoff := sendRetryBackoff
+	for attempt := 0; ; attempt++ {
 		log.Debug("Sending payload: size: %d traces: %d\n", size, count)
 		rc, err := h.config.transport.send(p)
-		if err != nil {
-			h.config.statsd.Count("datadog.tracer.traces_dropped", int64(count), []string{"reason:send_failed"}, 1)
-			log.Error("lost %d traces: %v", count, err)
-		} else {
+		h.status.flushed(err)
+		if err == nil {
+			if h.breaker.success() {
+				h.config.statsd.Incr("datadog.tracer.agent_reachable", nil, 1)
+				log.Info("The agent is reachable again, resuming sending traces")
+			}
 			h.config.statsd.Count("datadog.tracer.flush_bytes", int64(size), nil, 1)
 			h.config.statsd.Count("datadog.tracer.flush_traces", int64(count), nil, 1)
 			if err := h.prioritySampling.readRatesJSON(rc); err != nil {
 				h.config.statsd.Incr("datadog.tracer.decode_error", nil, 1)
 			}
+			return nil
 		}
-	}(oldp)
+		if h.breaker.failure() {
+			h.config.statsd.Incr("datadog.tracer.agent_unreachable", nil, 1)
+			log.Warn("The agent has been unreachable for over %s, dropping traces until it is reachable again; probing it every %s: %v",
+				h.breaker.threshold, h.breaker.probeInterval, err)
+		}
+		// no retries while the agent is unreachable
+		if attempt >= h.config.sendRetries || h.breaker.isOpen() || !h.wait(backoff) {
+			if h.retain(r) {
+				h.config.statsd.Count("datadog.tracer.traces_retained", int64(count), nil, 1)
+				log.Warn("failed to send %d traces, retaining them to send them later: %v", count, err)
+				return err
+			}
+			h.status.lost(count)
+			h.config.statsd.Count("datadog.tracer.traces_dropped", int64(count), []string{"reason:send_failed"}, 1)
+			log.Error("lost %d traces: %v", count, err)
+			return err
+		}
+		h.config.statsd.Incr("datadog.tracer.flush_retries", nil, 1)
+		log.Debug("failed to send %d traces (attempt %d): %v", count, attempt+1, err)
+		if backoff *= 2; backoff > sendRetryMaxBackoff {
+			backoff = sendRetryMaxBackoff
+		}
+		p = r.payload()
+	}
+}
+
+// wait waits for d and reports whether the writer is still running.
+func (h *agentTraceWriter) wait(d time.Duration) bool {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-h.stopping:
+		return false
+	}
+}
+
+// retain adds r to the retained payloads and reports whether there was room for it.
+// Payloads are not retained under memory pressure.
+func (h *agentTraceWriter) retain(r retainedPayload) bool {
+	if h.config.memory.underPressure() {
+		return false
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.retainedSize+len(r.items) > h.config.sendBufferSize {
+		return false
+	}
+	h.retained = append(h.retained, r)
+	h.retainedSize += len(r.items)
+	return true
+}
+
+// popRetained removes and returns the oldest retained payload, if any.
+func (h *agentTraceWriter) popRetained() (retainedPayload, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.retained) == 0 {
+		return retainedPayload{}, false
+	}
+	r := h.retained[0]
+	h.retained[0] = retainedPayload{}
+	h.retained = h.retained[1:]
+	h.retainedSize -= len(r.items)
+	return r, true
+}
+
+var (
+	// sendRetryBackoff is the delay before the first retry of a payload which couldn't
+	// be sent; replaced in tests. It doubles with each retry, up to sendRetryMaxBackoff.
+	sendRetryBackoff = 100 * time.Millisecond
+	// sendRetryMaxBackoff is the maximum delay between two attempts to send a payload.
+	sendRetryMaxBackoff = 2 * time.Second
+)
+
+// retainedPayload holds the content of a payload which couldn't be sent.
+type retainedPayload struct {
+	items    []byte        // the msgpack-encoded traces, without the header of the array
+	count    int           // the number of traces
+	protocol traceProtocol // the protocol the traces are encoded for
+	prefix   []byte        // the fields of the tracer payload, with the v0.7 protocol
+}
+
+// payload returns a new payload holding the traces of r.
+func (r retainedPayload) payload() *payload {
+	p := newPayload()
+	p.protocol, p.prefix = r.protocol, r.prefix
+	p.buf = *bytes.NewBuffer(r.items)
+	atomic.StoreUint64(&p.count, uint64(r.count))
+	p.updateHeader()
+	return p
 }
 
 // logWriter specifies the output target of the logTraceWriter; replaced in tests.
@@ -8,10 +8,14 @@ package tracer
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"math"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -293,6 +297,103 @@ func TestLogWriterOverflow(t *testing.T) {
 	})
 }
 
+// flakyTransport is a transport failing to send the first fails payloads.
+type flakyTransport struct {
+	*dummyTransport
+	mu    sync.Mutex
+	fails int
+}
+
+func (t *flakyTransport) send(p *payload) (io.ReadCloser, error) {
+	t.mu.Lock()
+	if t.fails > 0 {
+		t.fails--
+		t.mu.Unlock()
+		return nil, errors.New("connection refused")
+	}
+	t.mu.Unlock()
+	return t.dummyTransport.send(p)
+}
+
+func TestAgentWriterRetries(t *testing.T) {
+	defer func(old time.Duration) { sendRetryBackoff = old }(sendRetryBackoff)
+	sendRetryBackoff = time.Millisecond
+
+	newWriter := func(fails int, opts ...StartOption) (*agentTraceWriter, *flakyTransport, *testStatsdClient) {
+		var tg testStatsdClient
+		tr := &flakyTransport{dummyTransport: newDummyTransport(), fails: fails}
+		c := newConfig(append(opts, withTransport(tr), withStatsdClient(&tg))...)
+		return newAgentTraceWriter(c, newPrioritySampler()), tr, &tg
+	}
+	sendTrace := func(h *agentTraceWriter, name string) {
+		s := newBasicSpan(name)
+		h.add([]*span{s})
+		h.flush()
+		h.wg.Wait()
+	}
+	names := func(tr *flakyTransport) []string {
+		var n []string
+		for _, trace := range tr.Traces() {
+			n = append(n, trace[0].Name)
+		}
+		return n
+	}
+
+	t.Run("retried", func(t *testing.T) {
+		h, tr, tg := newWriter(2)
+		sendTrace(h, "a")
+		assert.Equal(t, []string{"a"}, names(tr))
+		assert.Equal(t, 2, tg.CallsByName()["datadog.tracer.flush_retries"])
+		assert.Zero(t, tg.Counts()["datadog.tracer.traces_dropped"])
+		assert.Zero(t, atomic.LoadUint64(&h.status.tracesLost))
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		h, tr, tg := newWriter(2, WithSendRetries(1))
+		sendTrace(h, "a")
+		assert.Empty(t, names(tr))
+		assert.Equal(t, 1, tg.CallsByName()["datadog.tracer.flush_retries"])
+		assert.Equal(t, int64(1), tg.Counts()["datadog.tracer.traces_dropped"])
+		assert.Equal(t, uint64(1), atomic.LoadUint64(&h.status.tracesLost))
+	})
+
+	t.Run("retained", func(t *testing.T) {
+		h, tr, tg := newWriter(2, WithSendRetries(0), WithSendBuffer(1<<20))
+		sendTrace(h, "a")
+		sendTrace(h, "b")
+		assert.Empty(t, names(tr))
+		assert.Len(t, h.retained, 2)
+		assert.Equal(t, int64(2), tg.Counts()["datadog.tracer.traces_retained"])
+
+		// the retained payloads are sent oldest first once the agent is reachable
+		sendTrace(h, "c")
+		assert.Equal(t, []string{"c", "a", "b"}, names(tr))
+		assert.Empty(t, h.retained)
+		assert.Zero(t, h.retainedSize)
+		assert.Zero(t, tg.Counts()["datadog.tracer.traces_dropped"])
+	})
+
+	t.Run("buffer-full", func(t *testing.T) {
+		h, tr, tg := newWriter(1, WithSendRetries(0), WithSendBuffer(1))
+		sendTrace(h, "a")
+		sendTrace(h, "b")
+		assert.Equal(t, []string{"b"}, names(tr))
+		assert.Zero(t, tg.Counts()["datadog.tracer.traces_retained"])
+		assert.Equal(t, int64(1), tg.Counts()["datadog.tracer.traces_dropped"])
+	})
+
+	t.Run("stopped", func(t *testing.T) {
+		h, tr, tg := newWriter(10, WithSendBuffer(1<<20))
+		h.add([]*span{newBasicSpan("a")})
+		h.stop()
+		assert.Empty(t, names(tr))
+		// no retries once the writer is stopped
+		assert.Zero(t, tg.CallsByName()["datadog.tracer.flush_retries"])
+		assert.Equal(t, int64(1), tg.Counts()["datadog.tracer.traces_dropped"])
+		assert.Equal(t, uint64(1), atomic.LoadUint64(&h.status.tracesLost))
+	})
+}
+
 func BenchmarkJsonEncodeSpan(b *testing.B) {
 	s := makeSpan(10)
 	s.Metrics["nan"] = math.NaN()
@@ -0,0 +1,113 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package instrument provides the entry points targeted by compile-time instrumentation tools,
+// which rewrite source code to insert calls to them, e.g. replacing:
+//
+//	db, err := sql.Open("postgres", dsn)
+//
+// with:
+//
+//	db, err := instrument.OpenSQL("postgres", dsn)
+//
+// The signatures of the functions of this package are stable: they are never changed nor
+// removed, new behaviours being added through new functions instead, so that the generated
+// code keeps compiling across releases. The tools should not rely on any other package of
+// this module but the ddtrace package, whose interfaces are part of these signatures.
+//
+// Importing this package enables the automatic registration of the SQL drivers
+// (see AutoRegister in the contrib/database/sql package).
+package instrument // import "github.com/codebrick-corp/dd-trace-go/instrument"
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net/http"
+
+	sqltrace "github.com/codebrick-corp/dd-trace-go/contrib/database/sql"
+	httptrace "github.com/codebrick-corp/dd-trace-go/contrib/net/http"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+)
+
+func init() {
+	sqltrace.AutoRegister()
+}
+
+// StartSpan starts a span with the given operation name and tags, as a child of the span found
+// in ctx, if any. It returns the span along with a copy of ctx holding it, which is meant to
+// replace ctx in the instrumented function. A nil ctx is allowed. It is meant to be used along
+// with FinishSpan, e.g.:
+//
+//	span, ctx := instrument.StartSpan(ctx, "pkg.Func", nil)
+//	defer func() { instrument.FinishSpan(span, err) }()
+func StartSpan(ctx context.Context, operationName string, tags map[string]interface{}) (ddtrace.Span, context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	opts := make([]ddtrace.StartSpanOption, 0, len(tags))
+	for k, v := range tags {
+		opts = append(opts, tracer.Tag(k, v))
+	}
+	return tracer.StartSpanFromContext(ctx, operationName, opts...)
+}
+
+// FinishSpan finishes the span returned by StartSpan, marking it as failed when err is not nil.
+// A nil span is allowed.
+func FinishSpan(span ddtrace.Span, err error) {
+	if span == nil {
+		return
+	}
+	span.Finish(tracer.WithError(err))
+}
+
+// tracedHandler is an http.Handler returned by WrapHandler.
+type tracedHandler struct {
+	http.Handler
+}
+
+// WrapHandler returns a handler tracing the requests served by h. The requests served by an
+// http.ServeMux are named after the pattern which matched them on Go 1.23 and later. Handlers
+// returned by WrapHandler are returned unchanged, and a nil h is returned as is.
+func WrapHandler(h http.Handler) http.Handler {
+	if h == nil {
+		return nil
+	}
+	if _, ok := h.(*tracedHandler); ok {
+		return h
+	}
+	return &tracedHandler{httptrace.WrapHandler(h, "", "")}
+}
+
+// WrapRoundTripper returns a round tripper tracing the requests sent through rt, which
+// defaults to http.DefaultTransport when nil. Round trippers returned by WrapRoundTripper
+// are not traced twice.
+func WrapRoundTripper(rt http.RoundTripper) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	return httptrace.WrapRoundTripper(rt)
+}
+
+// WrapClient modifies the transport of c so that its requests are traced, and returns c.
+// A nil c is returned as is.
+func WrapClient(c *http.Client) *http.Client {
+	if c == nil {
+		return nil
+	}
+	c.Transport = WrapRoundTripper(c.Transport)
+	return c
+}
+
+// OpenSQL is the traced version of sql.Open.
+func OpenSQL(driverName, dataSourceName string) (*sql.DB, error) {
+	return sqltrace.Open(driverName, dataSourceName)
+}
+
+// OpenSQLDB is the traced version of sql.OpenDB.
+func OpenSQLDB(c driver.Connector) *sql.DB {
+	return sqltrace.OpenDB(c)
+}
@@ -0,0 +1,117 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package instrument
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/ext"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/mocktracer"
+	"github.com/codebrick-corp/dd-trace-go/ddtrace/tracer"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSpan(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	parent, ctx := StartSpan(nil, "parent", nil) //lint:ignore SA1012 nil contexts are allowed
+	span, ctx := StartSpan(ctx, "child", map[string]interface{}{"key": "value"})
+	got, ok := tracer.SpanFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, span, got)
+	FinishSpan(span, errors.New("failed"))
+	FinishSpan(parent, nil)
+	FinishSpan(nil, nil)
+
+	spans := mt.FinishedSpans()
+	assert.Len(t, spans, 2)
+	child := spans[0]
+	assert.Equal(t, "child", child.OperationName())
+	assert.Equal(t, "value", child.Tag("key"))
+	assert.Equal(t, spans[1].SpanID(), child.ParentID())
+	assert.NotNil(t, child.Tag(ext.Error))
+	assert.Nil(t, spans[1].Tag(ext.Error))
+}
+
+func TestHTTP(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	h := WrapHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	assert.Same(t, h, WrapHandler(h))
+	assert.Nil(t, WrapHandler(nil))
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	c := WrapClient(WrapClient(&http.Client{}))
+	assert.Nil(t, WrapClient(nil))
+	resp, err := c.Get(srv.URL + "/hello")
+	assert.NoError(t, err)
+	resp.Body.Close()
+
+	spans := mt.FinishedSpans()
+	assert.Len(t, spans, 2)
+	var server, client mocktracer.Span
+	for _, s := range spans {
+		switch s.Tag(ext.SpanKind) {
+		case ext.SpanKindServer:
+			server = s
+		case ext.SpanKindClient:
+			client = s
+		}
+	}
+	if assert.NotNil(t, server) && assert.NotNil(t, client) {
+		assert.Equal(t, client.SpanID(), server.ParentID())
+		assert.Equal(t, "418", server.Tag(ext.HTTPCode))
+	}
+}
+
+func init() {
+	sql.Register("instrument-fake", fakeDriver{})
+}
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return fakeConn{}, nil }
+
+type fakeConn struct{}
+
+func (fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+
+func (fakeConn) Close() error { return nil }
+
+func (fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (fakeConn) ExecContext(context.Context, string, []driver.NamedValue) (driver.Result, error) {
+	return driver.RowsAffected(1), nil
+}
+
+func TestOpenSQL(t *testing.T) {
+	mt := mocktracer.Start()
+	defer mt.Stop()
+
+	db, err := OpenSQL("instrument-fake", "dsn")
+	assert.NoError(t, err)
+	defer db.Close()
+	_, err = db.Exec("DELETE FROM t")
+	assert.NoError(t, err)
+
+	var found bool
+	for _, s := range mt.FinishedSpans() {
+		found = found || s.Tag(ext.ResourceName) == "DELETE FROM t"
+	}
+	assert.True(t, found)
+}
@@ -0,0 +1,114 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package dogstatsd provides the DogStatsD client shared by the metrics reported by
+// the tracer: runtime metrics, health metrics and the metrics of the user.
+package dogstatsd
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"github.com/DataDog/datadog-go/v5/statsd"
+)
+
+// DefaultPort is the default port of the DogStatsD server.
+const DefaultPort = "8125"
+
+// Client is a DogStatsD client.
+type Client interface {
+	Incr(name string, tags []string, rate float64) error
+	Count(name string, value int64, tags []string, rate float64) error
+	Gauge(name string, value float64, tags []string, rate float64) error
+	Timing(name string, value time.Duration, tags []string, rate float64) error
+	Distribution(name string, value float64, tags []string, rate float64) error
+	Close() error
+}
+
+// New returns a client sending metrics to the DogStatsD server at addr, which is either
+// a "host:port" UDP address or a "unix://" prefixed socket path. Counts, gauges and sets
+// are aggregated by the client before being sent. Components sharing the client add
+// their own tags with WithTags.
+func New(addr string) (Client, error) {
+	return statsd.New(addr,
+		statsd.WithMaxMessagesPerPayload(40),
+		statsd.WithClientSideAggregation(),
+	)
+}
+
+// NoOp returns a client discarding all metrics.
+func NoOp() Client {
+	return &statsd.NoOpClient{}
+}
+
+// AddrFromURL returns the address of the DogStatsD server at the given URL, such as
+// the value of DD_DOGSTATSD_URL, in the format expected by New. The URL is either
+// "udp://host[:port]" or "unix:///path/to/socket".
+func AddrFromURL(u string) (string, error) {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return "", err
+	}
+	switch parsed.Scheme {
+	case "udp":
+		if parsed.Host == "" {
+			return "", fmt.Errorf("invalid DogStatsD URL %q: missing host", u)
+		}
+		if parsed.Port() == "" {
+			return net.JoinHostPort(parsed.Hostname(), DefaultPort), nil
+		}
+		return parsed.Host, nil
+	case "unix":
+		if parsed.Path == "" {
+			return "", fmt.Errorf("invalid DogStatsD URL %q: missing socket path", u)
+		}
+		return statsd.UnixAddressPrefix + parsed.Path, nil
+	default:
+		return "", fmt.Errorf("invalid DogStatsD URL %q: unsupported scheme %q", u, parsed.Scheme)
+	}
+}
+
+// WithTags returns a client adding tags to all the metrics sent through c, before
+// their own tags.
+func WithTags(c Client, tags []string) Client {
+	if len(tags) == 0 {
+		return c
+	}
+	return &taggedClient{Client: c, tags: tags}
+}
+
+type taggedClient struct {
+	Client
+	tags []string
+}
+
+func (c *taggedClient) with(tags []string) []string {
+	if len(tags) == 0 {
+		return c.tags
+	}
+	return append(c.tags[:len(c.tags):len(c.tags)], tags...)
+}
+
+func (c *taggedClient) Incr(name string, tags []string, rate float64) error {
+	return c.Client.Incr(name, c.with(tags), rate)
+}
+
+func (c *taggedClient) Count(name string, value int64, tags []string, rate float64) error {
+	return c.Client.Count(name, value, c.with(tags), rate)
+}
+
+func (c *taggedClient) Gauge(name string, value float64, tags []string, rate float64) error {
+	return c.Client.Gauge(name, value, c.with(tags), rate)
+}
+
+func (c *taggedClient) Timing(name string, value time.Duration, tags []string, rate float64) error {
+	return c.Client.Timing(name, value, c.with(tags), rate)
+}
+
+func (c *taggedClient) Distribution(name string, value float64, tags []string, rate float64) error {
+	return c.Client.Distribution(name, value, c.with(tags), rate)
+}
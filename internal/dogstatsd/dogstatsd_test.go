@@ -0,0 +1,83 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package dogstatsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAddrFromURL(t *testing.T) {
+	for in, out := range map[string]string{
+		"udp://localhost:8125":     "localhost:8125",
+		"udp://10.0.0.1":           "10.0.0.1:8125",
+		"udp://[::1]:9125":         "[::1]:9125",
+		"unix:///var/run/dsd.sock": "unix:///var/run/dsd.sock",
+	} {
+		addr, err := AddrFromURL(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, out, addr, in)
+	}
+	for _, in := range []string{
+		"localhost:8125",
+		"tcp://localhost:8125",
+		"udp://",
+		"unix://",
+		"://",
+	} {
+		_, err := AddrFromURL(in)
+		assert.Error(t, err, in)
+	}
+}
+
+func TestNew(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	c, err := New(conn.LocalAddr().String())
+	require.NoError(t, err)
+	tagged := WithTags(c, []string{"env:test"})
+	tagged.Count("requests", 1, []string{"a:b"}, 1)
+	tagged.Count("requests", 2, []string{"a:b"}, 1)
+	require.NoError(t, c.Close())
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	// counts are aggregated by the client
+	assert.Equal(t, "requests:3|c|#env:test,a:b", strings.TrimSpace(string(buf[:n])))
+}
+
+type tagsClient struct {
+	Client
+	tags []string
+}
+
+func (c *tagsClient) Gauge(name string, value float64, tags []string, rate float64) error {
+	c.tags = tags
+	return nil
+}
+
+func TestWithTags(t *testing.T) {
+	var c tagsClient
+	assert.Equal(t, &c, WithTags(&c, nil))
+
+	tagged := WithTags(&c, []string{"lang:go"})
+	tagged.Gauge("gauge", 1, nil, 1)
+	assert.Equal(t, []string{"lang:go"}, c.tags)
+
+	tagged.Gauge("gauge", 1, []string{"a:b"}, 1)
+	assert.Equal(t, []string{"lang:go", "a:b"}, c.tags)
+	tagged.Gauge("gauge", 1, []string{"c:d"}, 1)
+	assert.Equal(t, []string{"lang:go", "c:d"}, c.tags)
+}
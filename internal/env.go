@@ -8,6 +8,7 @@ package internal
 import (
 	"os"
 	"strconv"
+	"time"
 )
 
 // BoolEnv returns the parsed boolean value of an environment variable, or
@@ -29,3 +30,13 @@ func IntEnv(key string, def int) int {
 	}
 	return v
 }
+
+// DurationEnv returns the parsed duration value of an environment variable, or
+// def otherwise.
+func DurationEnv(key string, def time.Duration) time.Duration {
+	v, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
@@ -9,6 +9,8 @@ package globalconfig
 
 import (
 	"math"
+	"net"
+	"net/http"
 	"sync"
 
 	"github.com/google/uuid"
@@ -20,10 +22,13 @@ var cfg = &config{
 }
 
 type config struct {
-	mu            sync.RWMutex
-	analyticsRate float64
-	serviceName   string
-	runtimeID     string
+	mu               sync.RWMutex
+	analyticsRate    float64
+	serviceName      string
+	runtimeID        string
+	clientIPHook     func(net.IP) map[string]string
+	clientIPResolver func(*http.Request) net.IP
+	headersAsTags    map[string]string
 }
 
 // AnalyticsRate returns the sampling rate at which events should be marked. It uses
@@ -62,3 +67,70 @@ func RuntimeID() string {
 	defer cfg.mu.RUnlock()
 	return cfg.runtimeID
 }
+
+// ClientIPHook returns the function called by the server integrations with the client IP
+// of each request, which returns the tags to set on the request span. It returns nil when
+// no hook was set.
+func ClientIPHook() func(net.IP) map[string]string {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	return cfg.clientIPHook
+}
+
+// SetClientIPHook sets the function called with the client IP of each request globally.
+func SetClientIPHook(fn func(net.IP) map[string]string) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.clientIPHook = fn
+}
+
+// ClientIPResolver returns the function resolving the client IP of the requests received by
+// the server integrations, in place of their default resolution. It returns nil when no
+// resolver was set.
+func ClientIPResolver() func(*http.Request) net.IP {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	return cfg.clientIPResolver
+}
+
+// SetClientIPResolver sets the function resolving the client IP of each request globally.
+func SetClientIPResolver(fn func(*http.Request) net.IP) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.clientIPResolver = fn
+}
+
+// SetHeaderTag sets the tag under which the values of the given HTTP header are recorded on
+// the spans of the HTTP integrations. An empty tag selects the default tags of the header,
+// http.request.headers.<header> and http.response.headers.<header>.
+func SetHeaderTag(header, tag string) {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	if cfg.headersAsTags == nil {
+		cfg.headersAsTags = make(map[string]string)
+	}
+	cfg.headersAsTags[http.CanonicalHeaderKey(header)] = tag
+}
+
+// ForEachHeaderTag calls fn with every HTTP header set with SetHeaderTag, along with its tag.
+func ForEachHeaderTag(fn func(header, tag string)) {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	for h, t := range cfg.headersAsTags {
+		fn(h, t)
+	}
+}
+
+// HeaderTagsLen returns the number of HTTP headers set with SetHeaderTag.
+func HeaderTagsLen() int {
+	cfg.mu.RLock()
+	defer cfg.mu.RUnlock()
+	return len(cfg.headersAsTags)
+}
+
+// ClearHeaderTags removes all the HTTP headers set with SetHeaderTag.
+func ClearHeaderTags() {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.headersAsTags = nil
+}
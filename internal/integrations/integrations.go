@@ -0,0 +1,145 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+// Package integrations holds the registry of the contrib packages imported by the
+// application, into which each of them registers when it is initialized.
+package integrations
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/telemetry"
+)
+
+// Integration describes a registered contrib package.
+type Integration struct {
+	// Name is the path of the contrib package under the contrib directory,
+	// e.g. "gorilla/mux".
+	Name string
+	// Library is the import path of the instrumented library, e.g.
+	// "github.com/gorilla/mux". It is empty for generic integrations.
+	Library string
+	// Version is the version of the instrumented library used by the application,
+	// or empty when it is unknown.
+	Version string
+}
+
+var (
+	mu       sync.Mutex // guards registry and versions
+	registry = make(map[string]string)
+	versions = make(map[string]string)
+
+	// readBuildInfo returns the build information of the application; replaced in tests.
+	readBuildInfo = debug.ReadBuildInfo
+)
+
+// Register records that the contrib package with the given name, instrumenting the
+// library with the given import path, is in use in the application. It is called by
+// the contrib packages when they are initialized.
+func Register(name, library string) {
+	mu.Lock()
+	defer mu.Unlock()
+	registry[name] = library
+}
+
+// List returns the registered integrations, sorted by name.
+func List() []Integration {
+	mu.Lock()
+	list := make([]Integration, 0, len(registry))
+	for name, library := range registry {
+		list = append(list, Integration{Name: name, Library: library})
+	}
+	mu.Unlock()
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	info, _ := readBuildInfo()
+	for i := range list {
+		list[i].Version = version(info, list[i].Library)
+	}
+	return list
+}
+
+// Version returns the version of the library instrumented by the registered integration
+// with the given name, or an empty string when it is unknown. It is meant to be called
+// for each span created by the integration, the versions being cached.
+func Version(name string) string {
+	mu.Lock()
+	defer mu.Unlock()
+	if v, ok := versions[name]; ok {
+		return v
+	}
+	library, ok := registry[name]
+	if !ok {
+		return ""
+	}
+	info, _ := readBuildInfo()
+	v := version(info, library)
+	versions[name] = v
+	return v
+}
+
+// Telemetry returns the registered integrations, as reported to the telemetry.
+func Telemetry() []telemetry.Integration {
+	list := List()
+	ti := make([]telemetry.Integration, len(list))
+	for i, in := range list {
+		ti[i] = telemetry.Integration{Name: in.Name, Version: in.Version, Enabled: true}
+	}
+	return ti
+}
+
+// version returns the version of the module providing the package with the import
+// path library, according to the build information info, which may be nil.
+func version(info *debug.BuildInfo, library string) string {
+	if library == "" {
+		return ""
+	}
+	if isStdlib(library) {
+		return runtime.Version()
+	}
+	if info == nil {
+		return ""
+	}
+	var mod *debug.Module
+	for _, dep := range info.Deps {
+		if library != dep.Path && !strings.HasPrefix(library, dep.Path+"/") {
+			continue
+		}
+		// the longest module path provides the package
+		if mod == nil || len(dep.Path) > len(mod.Path) {
+			mod = dep
+		}
+	}
+	if mod == nil {
+		// the integration supports several major versions of the library, whose
+		// module paths start with library, e.g. gopkg.in/olivere/elastic.v5
+		for _, dep := range info.Deps {
+			if strings.HasPrefix(dep.Path, library) {
+				mod = dep
+				break
+			}
+		}
+	}
+	if mod == nil {
+		return ""
+	}
+	if mod.Replace != nil && mod.Replace.Version != "" {
+		return mod.Replace.Version
+	}
+	return mod.Version
+}
+
+// isStdlib reports whether the package with the import path p belongs to the standard
+// library, whose first path element contains no dot.
+func isStdlib(p string) bool {
+	first := p
+	if i := strings.IndexByte(p, '/'); i >= 0 {
+		first = p[:i]
+	}
+	return !strings.Contains(first, ".")
+}
@@ -0,0 +1,86 @@
+// Unless explicitly stated otherwise all files in this repository are licensed
+// under the Apache License Version 2.0.
+// This product includes software developed at Datadog (https://www.datadoghq.com/).
+// Copyright 2016 Datadog, Inc.
+
+package integrations
+
+import (
+	"runtime"
+	"runtime/debug"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/codebrick-corp/dd-trace-go/internal/telemetry"
+)
+
+func TestList(t *testing.T) {
+	defer func(old func() (*debug.BuildInfo, bool)) { readBuildInfo = old }(readBuildInfo)
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		return &debug.BuildInfo{Deps: []*debug.Module{
+			{Path: "github.com/gorilla/mux", Version: "v1.8.0"},
+			{Path: "github.com/go-redis/redis", Version: "v6.15.9+incompatible"},
+			{Path: "github.com/go-redis/redis/v8", Version: "v8.11.5"},
+			{Path: "gopkg.in/olivere/elastic.v5", Version: "v5.0.84"},
+			{Path: "github.com/hashicorp/consul/api", Version: "v1.13.0"},
+			{Path: "github.com/hashicorp/consul", Version: "v1.12.0"},
+			{Path: "github.com/gin-gonic/gin", Version: "v1.7.0", Replace: &debug.Module{Version: "v1.7.7"}},
+		}}, true
+	}
+	defer func(old map[string]string) { registry = old }(registry)
+	registry = make(map[string]string)
+
+	for name, library := range map[string]string{
+		"net/http":           "net/http",
+		"gorilla/mux":        "github.com/gorilla/mux",
+		"go-redis/redis":     "github.com/go-redis/redis",
+		"go-redis/redis.v8":  "github.com/go-redis/redis/v8",
+		"olivere/elastic":    "gopkg.in/olivere/elastic",
+		"hashicorp/consul":   "github.com/hashicorp/consul/api",
+		"gin-gonic/gin":      "github.com/gin-gonic/gin",
+		"graphql/federation": "",
+		"gocql/gocql":        "github.com/gocql/gocql",
+	} {
+		Register(name, library)
+	}
+	// registered twice
+	Register("net/http", "net/http")
+
+	assert.Equal(t, []Integration{
+		{Name: "gin-gonic/gin", Library: "github.com/gin-gonic/gin", Version: "v1.7.7"},
+		{Name: "go-redis/redis", Library: "github.com/go-redis/redis", Version: "v6.15.9+incompatible"},
+		{Name: "go-redis/redis.v8", Library: "github.com/go-redis/redis/v8", Version: "v8.11.5"},
+		{Name: "gocql/gocql", Library: "github.com/gocql/gocql"},
+		{Name: "gorilla/mux", Library: "github.com/gorilla/mux", Version: "v1.8.0"},
+		{Name: "graphql/federation"},
+		{Name: "hashicorp/consul", Library: "github.com/hashicorp/consul/api", Version: "v1.13.0"},
+		{Name: "net/http", Library: "net/http", Version: runtime.Version()},
+		{Name: "olivere/elastic", Library: "gopkg.in/olivere/elastic", Version: "v5.0.84"},
+	}, List())
+
+	ti := Telemetry()
+	assert.Len(t, ti, 9)
+	assert.Equal(t, telemetry.Integration{Name: "gin-gonic/gin", Version: "v1.7.7", Enabled: true}, ti[0])
+}
+
+func TestVersion(t *testing.T) {
+	defer func(old func() (*debug.BuildInfo, bool)) { readBuildInfo = old }(readBuildInfo)
+	var reads int
+	readBuildInfo = func() (*debug.BuildInfo, bool) {
+		reads++
+		return &debug.BuildInfo{Deps: []*debug.Module{
+			{Path: "github.com/gorilla/mux", Version: "v1.8.0"},
+		}}, true
+	}
+	defer func(r, v map[string]string) { registry, versions = r, v }(registry, versions)
+	registry, versions = make(map[string]string), make(map[string]string)
+
+	Register("gorilla/mux", "github.com/gorilla/mux")
+	Register("gocql/gocql", "github.com/gocql/gocql")
+	assert.Equal(t, "v1.8.0", Version("gorilla/mux"))
+	assert.Equal(t, "v1.8.0", Version("gorilla/mux"))
+	assert.Equal(t, "", Version("gocql/gocql"))
+	assert.Equal(t, "", Version("unregistered"))
+	assert.Equal(t, 2, reads)
+}